@@ -46,6 +46,13 @@ type BenchmarkStatus = struct {
 
 	// Base filename of the
 	Filename string `json:"filename,omitempty"`
+
+	// Live contains statistics of the last interval by operation type
+	// when live statistics are enabled.
+	Live []bench.LiveInterval `json:"live,omitempty"`
+
+	// Paused is true while issuing new operations is paused.
+	Paused bool `json:"paused,omitempty"`
 }
 
 // Operations contains raw benchmark operations.
@@ -63,6 +70,13 @@ type Server struct {
 	server  *http.Server
 	cmdLine string
 
+	// Annotated windows and start of open windows by label.
+	annotations bench.Operations
+	openWindows map[string]time.Time
+
+	// Pauses operations when set.
+	pauser *bench.Pauser
+
 	// Shutting down
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -84,6 +98,20 @@ func (s *Server) OperationsReady(ops bench.Operations, filename, cmdLine string)
 	s.mu.Unlock()
 }
 
+// LiveStatsReady can be used to update the live statistics of the running benchmark.
+func (s *Server) LiveStatsReady(live []bench.LiveInterval) {
+	s.mu.Lock()
+	s.status.Live = live
+	s.mu.Unlock()
+}
+
+// SetPauser enables pausing and resuming the benchmark with p.
+func (s *Server) SetPauser(p *bench.Pauser) {
+	s.mu.Lock()
+	s.pauser = p
+	s.mu.Unlock()
+}
+
 // SetLnLoggers can be used to set upstream loggers.
 // When logging to the servers these will be called.
 func (s *Server) SetLnLoggers(info, err func(data ...interface{})) {
@@ -247,6 +275,103 @@ func (s *Server) handleDownloadJSON(w http.ResponseWriter, req *http.Request) {
 	enc.Encode(ops)
 }
 
+// StartAnnotation opens an annotated window with the label.
+// If a window with the label is already open, it is kept.
+func (s *Server) StartAnnotation(label string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.openWindows == nil {
+		s.openWindows = make(map[string]time.Time)
+	}
+	if _, ok := s.openWindows[label]; !ok {
+		s.openWindows[label] = time.Now()
+	}
+}
+
+// EndAnnotation closes the annotated window with the label.
+// If no window with the label is open, a zero length window is recorded.
+func (s *Server) EndAnnotation(label string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	start, ok := s.openWindows[label]
+	if !ok {
+		start = now
+	}
+	delete(s.openWindows, label)
+	s.annotations = append(s.annotations, bench.NewAnnotation(label, start, now))
+}
+
+// Annotations returns all annotated windows.
+// Windows that are still open are closed.
+func (s *Server) Annotations() bench.Operations {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for label, start := range s.openWindows {
+		s.annotations = append(s.annotations, bench.NewAnnotation(label, start, now))
+	}
+	s.openWindows = nil
+	return append(bench.Operations{}, s.annotations...)
+}
+
+// handleAnnotate handles POST `/v1/annotate` requests with "label" and "state" parameters.
+// State must be "start" or "end". Label defaults to "fault".
+func (s *Server) handleAnnotate(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	q := req.URL.Query()
+	label := q.Get("label")
+	if label == "" {
+		label = "fault"
+	}
+	switch q.Get("state") {
+	case "start":
+		s.StartAnnotation(label)
+	case "end":
+		s.EndAnnotation(label)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`state must be "start" or "end"`))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handlePause handles POST `/v1/pause` and `/v1/resume` requests.
+// Paused windows are recorded as annotations with bench.PauseLabel.
+func (s *Server) handlePause(pause bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		p := s.pauser
+		s.mu.Unlock()
+		if p == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`benchmark cannot be paused`))
+			return
+		}
+		if pause {
+			if p.Pause() {
+				s.StartAnnotation(bench.PauseLabel)
+				s.InfoLn("Benchmark paused")
+			}
+		} else if p.Resume() {
+			s.EndAnnotation(bench.PauseLabel)
+			s.InfoLn("Benchmark resumed")
+		}
+		s.mu.Lock()
+		s.status.Paused = pause
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
 // handleStop handles requests to `/v1/stop`, stops the service.
 func (s *Server) handleStop(w http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodDelete {
@@ -271,6 +396,9 @@ func NewBenchmarkMonitor(listenAddr string) *Server {
 	mux.HandleFunc("/v1/aggregated", s.handleAggregated)
 	mux.HandleFunc("/v1/operations/json", s.handleDownloadJSON)
 	mux.HandleFunc("/v1/operations", s.handleDownloadZst)
+	mux.HandleFunc("/v1/annotate", s.handleAnnotate)
+	mux.HandleFunc("/v1/pause", s.handlePause(true))
+	mux.HandleFunc("/v1/resume", s.handlePause(false))
 
 	s.server = &http.Server{
 		Addr:              listenAddr,
@@ -24,11 +24,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/fatih/color"
-	"github.com/klauspost/compress/zstd"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/pkg/console"
@@ -41,7 +39,7 @@ var analyzeFlags = []cli.Flag{
 	cli.StringFlag{
 		Name:  "analyze.dur",
 		Value: "",
-		Usage: "Split analysis into durations of this length. Can be '1s', '5s', '1m', etc.",
+		Usage: "Split analysis into durations of this length. Can be '100ms', '1s', '5s', '1m', etc.",
 	},
 	cli.StringFlag{
 		Name:  "analyze.out",
@@ -80,10 +78,35 @@ var analyzeFlags = []cli.Flag{
 		Name:  "analyze.v",
 		Usage: "Display additional analysis data.",
 	},
+	cli.StringFlag{
+		Name:  "analyze.slo",
+		Usage: "Report latency objective burn rate. Specify as OP:duration, comma separated, or a single duration for all operations. Eg: 'GET:100ms,PUT:1s'.",
+	},
+	cli.Float64Flag{
+		Name:  "analyze.slo.target",
+		Usage: "Percentage of operations that must complete within the analyze.slo latency.",
+		Value: 99.9,
+	},
+	cli.StringFlag{
+		Name:  "analyze.outliers",
+		Usage: "Write the slowest operations with key, size, host, timing and request ID to this CSV file. Request IDs are recorded with --latency.attribution.",
+	},
+	cli.IntFlag{
+		Name:  "analyze.outliers.n",
+		Usage: "Number of operations written to the analyze.outliers file.",
+		Value: 100,
+	},
+	cli.StringFlag{
+		Name:  "analyze.percentiles",
+		Usage: "Comma separated percentiles shown in reports and live output, for example '50,90,99,99.9,max'.",
+	},
 	cli.StringFlag{
 		Name:  serverFlagName,
 		Usage: "When running benchmarks open a webserver to fetch results remotely, eg: localhost:7762",
 	},
+	benchDataKeyFlag,
+	trendDBFlag,
+	trendLabelFlag,
 }
 
 var analyzeCmd = cli.Command{
@@ -116,8 +139,6 @@ func mainAnalyze(ctx *cli.Context) error {
 	if len(args) > 1 {
 		console.Fatal("Only one benchmark file can be given")
 	}
-	zstdDec, _ := zstd.NewReader(nil)
-	defer zstdDec.Close()
 	monitor := api.NewBenchmarkMonitor(ctx.String(serverFlagName))
 	defer monitor.Done()
 	log := console.Printf
@@ -134,13 +155,17 @@ func mainAnalyze(ctx *cli.Context) error {
 			defer f.Close()
 			input = f
 		}
-		err := zstdDec.Reset(input)
+		dec, err := newBenchDataReader(input, ctx.String(benchDataKeyFlag.Name))
 		fatalIf(probe.NewError(err), "Unable to read input")
-		ops, err := bench.OperationsFromCSV(zstdDec, true, ctx.Int("analyze.offset"), ctx.Int("analyze.limit"), log)
+		defer dec.Close()
+		// Keep object names when they are written with the slowest operations.
+		analyzeOnly := ctx.String("analyze.outliers") == ""
+		ops, comments, err := bench.OperationsFromCSVComments(dec, analyzeOnly, ctx.Int("analyze.offset"), ctx.Int("analyze.limit"), log)
 		fatalIf(probe.NewError(err), "Unable to parse input")
 
-		printAnalysis(ctx, ops)
-		monitor.OperationsReady(ops, strings.TrimSuffix(filepath.Base(arg), ".csv.zst"), commandLine(ctx))
+		printProvenance(comments)
+		printAnalysis(ctx, ops, trendBenchmark(comments))
+		monitor.OperationsReady(ops, benchDataBaseName(filepath.Base(arg)), commandLine(ctx))
 	}
 	return nil
 }
@@ -224,7 +249,7 @@ func printMixedOpAnalysis(ctx *cli.Context, aggr aggregate.Aggregated, details b
 	}
 }
 
-func printAnalysis(ctx *cli.Context, o bench.Operations) {
+func printAnalysis(ctx *cli.Context, o bench.Operations, benchmark string) {
 	details := ctx.Bool("analyze.v")
 	var wrSegs io.Writer
 	prefiltered := false
@@ -232,20 +257,22 @@ func printAnalysis(ctx *cli.Context, o bench.Operations) {
 		if fn == "-" {
 			wrSegs = os.Stdout
 		} else {
-			f, err := os.Create(fn)
+			f, err := createOutputFile(fn, ctx.String(benchDataKeyFlag.Name))
 			fatalIf(probe.NewError(err), "Unable to create create analysis output")
 			defer console.Println("Aggregated data saved to", fn)
 			defer f.Close()
 			wrSegs = f
 		}
 	}
+	o, annotations := o.SplitAnnotations()
+	o, annotations = excludePauses(o, annotations)
 	if onlyHost := ctx.String("analyze.host"); onlyHost != "" {
 		o2 := o.FilterByEndpoint(onlyHost)
 		if len(o2) == 0 {
 			hosts := o.Endpoints()
 			console.Println("Host not found, valid hosts are:")
 			for _, h := range hosts {
-				console.Println("\t* %s", h)
+				console.Printf("\t* %s\n", h)
 			}
 			return
 		}
@@ -257,6 +284,12 @@ func printAnalysis(ctx *cli.Context, o bench.Operations) {
 		prefiltered = prefiltered || o.IsMixed()
 		o = o.FilterByOp(wantOp)
 	}
+	writeOutliers(ctx, o)
+	defer printAnnotationAnalysis(o, annotations)
+	defer printListPageAnalysis(annotations)
+	defer printPlaneAnalysis(o)
+	defer printAttributionAnalysis(o)
+	defer printSizeHistogram(o)
 	durFn := func(total time.Duration) time.Duration {
 		if total <= 0 {
 			return 0
@@ -267,7 +300,12 @@ func printAnalysis(ctx *cli.Context, o bench.Operations) {
 		Prefiltered: prefiltered,
 		DurFunc:     durFn,
 		SkipDur:     ctx.Duration("analyze.skip"),
+		Percentiles: analysisPercentiles(ctx),
 	})
+	aggr.SLO = sloBurnRates(ctx, o)
+	setOpsExitStatus(o, aggr.SLO)
+	defer recordTrend(ctx, benchmark, aggr)
+	defer printSLO(aggr.SLO, details)
 	if wrSegs != nil {
 		for _, ops := range aggr.Operations {
 			writeSegs(ctx, wrSegs, o.FilterByOp(ops.Type), !(aggr.Mixed || prefiltered), details)
@@ -454,9 +492,7 @@ func printRequestAnalysis(ctx *cli.Context, ops aggregate.Operation, details boo
 
 		console.Print(
 			" * Avg: ", time.Duration(reqs.DurAvgMillis)*time.Millisecond,
-			", 50%: ", time.Duration(reqs.DurMedianMillis)*time.Millisecond,
-			", 90%: ", time.Duration(reqs.Dur90Millis)*time.Millisecond,
-			", 99%: ", time.Duration(reqs.Dur99Millis)*time.Millisecond,
+			", ", reqs.PercentilesString(),
 			", Fastest: ", time.Duration(reqs.FastestMillis)*time.Millisecond,
 			", Slowest: ", time.Duration(reqs.SlowestMillis)*time.Millisecond,
 			"\n")
@@ -469,9 +505,7 @@ func printRequestAnalysis(ctx *cli.Context, ops aggregate.Operation, details boo
 			reqs := reqs.FirstAccess
 			console.Print(
 				" * First Access: Avg: ", time.Duration(reqs.DurAvgMillis)*time.Millisecond,
-				", 50%: ", time.Duration(reqs.DurMedianMillis)*time.Millisecond,
-				", 90%: ", time.Duration(reqs.Dur90Millis)*time.Millisecond,
-				", 99%: ", time.Duration(reqs.Dur99Millis)*time.Millisecond,
+				", ", reqs.PercentilesString(),
 				", Fastest: ", time.Duration(reqs.FastestMillis)*time.Millisecond,
 				", Slowest: ", time.Duration(reqs.SlowestMillis)*time.Millisecond,
 				"\n")
@@ -483,9 +517,7 @@ func printRequestAnalysis(ctx *cli.Context, ops aggregate.Operation, details boo
 			reqs := reqs.LastAccess
 			console.Print(
 				" * Last Access: Avg: ", time.Duration(reqs.DurAvgMillis)*time.Millisecond,
-				", 50%: ", time.Duration(reqs.DurMedianMillis)*time.Millisecond,
-				", 90%: ", time.Duration(reqs.Dur90Millis)*time.Millisecond,
-				", 99%: ", time.Duration(reqs.Dur99Millis)*time.Millisecond,
+				", ", reqs.PercentilesString(),
 				", Fastest: ", time.Duration(reqs.FastestMillis)*time.Millisecond,
 				", Slowest: ", time.Duration(reqs.SlowestMillis)*time.Millisecond,
 				"\n")
@@ -504,12 +536,20 @@ func printRequestAnalysis(ctx *cli.Context, ops aggregate.Operation, details boo
 					continue
 				}
 				console.SetColor("Print", color.New(color.FgWhite))
-				console.Println(" *", ep, "-", reqs.Requests, "requests:",
-					"\n\t- Avg:", time.Duration(reqs.DurAvgMillis)*time.Millisecond,
-					"Fastest:", time.Duration(reqs.FastestMillis)*time.Millisecond,
-					"Slowest:", time.Duration(reqs.SlowestMillis)*time.Millisecond,
-					"50%:", time.Duration(reqs.DurMedianMillis)*time.Millisecond,
-					"90%:", time.Duration(reqs.Dur90Millis)*time.Millisecond)
+				if len(reqs.Percentiles) > 0 {
+					console.Println(" *", ep, "-", reqs.Requests, "requests:",
+						"\n\t- Avg:", time.Duration(reqs.DurAvgMillis)*time.Millisecond,
+						"Fastest:", time.Duration(reqs.FastestMillis)*time.Millisecond,
+						"Slowest:", time.Duration(reqs.SlowestMillis)*time.Millisecond,
+						reqs.PercentilesString())
+				} else {
+					console.Println(" *", ep, "-", reqs.Requests, "requests:",
+						"\n\t- Avg:", time.Duration(reqs.DurAvgMillis)*time.Millisecond,
+						"Fastest:", time.Duration(reqs.FastestMillis)*time.Millisecond,
+						"Slowest:", time.Duration(reqs.SlowestMillis)*time.Millisecond,
+						"50%:", time.Duration(reqs.DurMedianMillis)*time.Millisecond,
+						"90%:", time.Duration(reqs.Dur90Millis)*time.Millisecond)
+				}
 				if reqs.FirstByte != nil {
 					console.Println("\t- First Byte:", reqs.FirstByte)
 				}
@@ -539,9 +579,7 @@ func printRequestAnalysis(ctx *cli.Context, ops aggregate.Operation, details boo
 
 		console.Print(""+
 			" * Throughput: Average: ", bench.Throughput(s.BpsAverage),
-			", 50%: ", bench.Throughput(s.BpsMedian),
-			", 90%: ", bench.Throughput(s.Bps90),
-			", 99%: ", bench.Throughput(s.Bps99),
+			", ", s.PercentilesString(),
 			", Fastest: ", bench.Throughput(s.BpsFastest),
 			", Slowest: ", bench.Throughput(s.BpsSlowest),
 			"\n")
@@ -554,9 +592,7 @@ func printRequestAnalysis(ctx *cli.Context, ops aggregate.Operation, details boo
 			s := s.FirstAccess
 			console.Print(""+
 				" * First Access: Average: ", bench.Throughput(s.BpsAverage),
-				", 50%: ", bench.Throughput(s.BpsMedian),
-				", 90%: ", bench.Throughput(s.Bps90),
-				", 99%: ", bench.Throughput(s.Bps99),
+				", ", s.PercentilesString(),
 				", Fastest: ", bench.Throughput(s.BpsFastest),
 				", Slowest: ", bench.Throughput(s.BpsSlowest),
 				"\n")
@@ -576,12 +612,20 @@ func printRequestAnalysis(ctx *cli.Context, ops aggregate.Operation, details boo
 				continue
 			}
 			console.SetColor("Print", color.New(color.FgWhite))
-			console.Println(" *", ep, "-", s.Requests, "requests:",
-				"\n\t- Avg:", bench.Throughput(s.BpsAverage),
-				"Fastest:", bench.Throughput(s.BpsFastest),
-				"Slowest:", bench.Throughput(s.BpsSlowest),
-				"50%:", bench.Throughput(s.BpsMedian),
-				"90%:", bench.Throughput(s.Bps90))
+			if len(s.Percentiles) > 0 {
+				console.Println(" *", ep, "-", s.Requests, "requests:",
+					"\n\t- Avg:", bench.Throughput(s.BpsAverage),
+					"Fastest:", bench.Throughput(s.BpsFastest),
+					"Slowest:", bench.Throughput(s.BpsSlowest),
+					s.PercentilesString())
+			} else {
+				console.Println(" *", ep, "-", s.Requests, "requests:",
+					"\n\t- Avg:", bench.Throughput(s.BpsAverage),
+					"Fastest:", bench.Throughput(s.BpsFastest),
+					"Slowest:", bench.Throughput(s.BpsSlowest),
+					"50%:", bench.Throughput(s.BpsMedian),
+					"90%:", bench.Throughput(s.Bps90))
+			}
 			if s.FirstByte != nil {
 				console.Println(" * TTFB:", s.FirstByte)
 			}
@@ -614,9 +658,26 @@ func analysisDur(ctx *cli.Context, total time.Duration) time.Duration {
 	return d
 }
 
+// analysisPercentiles returns the percentiles selected for reports.
+// If none are selected nil is returned.
+func analysisPercentiles(ctx *cli.Context) bench.Percentiles {
+	if ctx.String("analyze.percentiles") == "" {
+		return nil
+	}
+	p, err := bench.ParsePercentiles(ctx.String("analyze.percentiles"))
+	fatalIf(probe.NewError(err), "Invalid -analyze.percentiles value")
+	return p
+}
+
 func checkAnalyze(ctx *cli.Context) {
-	if analysisDur(ctx, time.Minute) == 0 {
+	if d := analysisDur(ctx, time.Minute); d == 0 {
 		err := errors.New("-analyze.dur cannot be 0")
 		fatal(probe.NewError(err), "Invalid -analyze.dur value")
+	} else if d < time.Millisecond {
+		err := errors.New("-analyze.dur must be at least 1ms")
+		fatal(probe.NewError(err), "Invalid -analyze.dur value")
 	}
+	analysisPercentiles(ctx)
+	checkSLO(ctx)
+	checkTrend(ctx)
 }
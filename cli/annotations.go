@@ -0,0 +1,169 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// annotatedTime returns the total time covered by windows with the label,
+// clipped to the range from start to end.
+func annotatedTime(annotations bench.Operations, label string, start, end time.Time) time.Duration {
+	var windows bench.Operations
+	for _, a := range annotations {
+		if a.File != label {
+			continue
+		}
+		if a.Start.Before(start) {
+			a.Start = start
+		}
+		if a.End.After(end) {
+			a.End = end
+		}
+		if a.End.After(a.Start) {
+			windows = append(windows, a)
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start.Before(windows[j].Start) })
+	var total time.Duration
+	var covered time.Time
+	for _, w := range windows {
+		if w.Start.Before(covered) {
+			w.Start = covered
+		}
+		if w.End.After(w.Start) {
+			total += w.End.Sub(w.Start)
+			covered = w.End
+		}
+	}
+	return total
+}
+
+// summarizeOps returns a one line summary of operations running for the duration.
+func summarizeOps(ops bench.Operations, dur time.Duration) string {
+	if len(ops) == 0 || dur <= 0 {
+		return "No operations"
+	}
+	var bytes int64
+	var objs int
+	errs := 0
+	ok := make(bench.Operations, 0, len(ops))
+	for _, op := range ops {
+		if op.Err != "" {
+			errs++
+			continue
+		}
+		bytes += op.Size
+		objs += op.ObjPerOp
+		ok = append(ok, op)
+	}
+	secs := dur.Seconds()
+	res := fmt.Sprintf("%v, %.2f obj/s", bench.Throughput(float64(bytes)/secs), float64(objs)/secs)
+	if len(ok) > 0 {
+		ok.SortByDuration()
+		res += fmt.Sprintf(", Latency avg: %v, 50%%: %v, 90%%: %v, 99%%: %v",
+			ok.AvgDuration().Round(time.Millisecond/10),
+			ok.Median(0.5).Duration().Round(time.Millisecond/10),
+			ok.Median(0.9).Duration().Round(time.Millisecond/10),
+			ok.Median(0.99).Duration().Round(time.Millisecond/10))
+	}
+	if errs > 0 {
+		res += fmt.Sprintf(", Errors: %d", errs)
+	}
+	return res
+}
+
+// excludePauses removes the time the benchmark was paused from the operations and the other annotations.
+// The time paused is printed.
+func excludePauses(o, annotations bench.Operations) (bench.Operations, bench.Operations) {
+	paused, n := annotations.PausedTime()
+	if n == 0 {
+		return o, annotations
+	}
+	var other bench.Operations
+	for _, a := range annotations {
+		if a.File != bench.PauseLabel {
+			other = append(other, a)
+		}
+	}
+	if !globalJSON {
+		times := "once"
+		if n > 1 {
+			times = fmt.Sprintf("%d times", n)
+		}
+		console.Printf("Benchmark was paused %s for %v. Paused time is excluded.\n", times, paused.Round(time.Second))
+	}
+	return o.ExcludePauses(annotations), other.ExcludePauses(annotations)
+}
+
+// printAnnotationAnalysis prints performance inside and outside annotated windows.
+func printAnnotationAnalysis(o, annotations bench.Operations) {
+	if globalJSON || len(annotations) == 0 || len(o) == 0 {
+		return
+	}
+	start, end := o.TimeRange()
+	for _, label := range annotations.AnnotationLabels() {
+		if label == bench.ListPageLabel {
+			// Pages are printed by printListPageAnalysis.
+			continue
+		}
+		inDur := annotatedTime(annotations, label, start, end)
+		console.Println("\n----------------------------------------")
+		console.SetColor("Print", color.New(color.FgHiWhite))
+		console.Printf("Annotation: %s. Inside %v, outside %v.\n", label, inDur.Round(time.Second), (end.Sub(start) - inDur).Round(time.Second))
+		for _, typ := range o.OpTypes() {
+			inside, outside := o.FilterByOp(typ).SplitByAnnotation(annotations, label)
+			console.SetColor("Print", color.New(color.FgHiWhite))
+			console.Println("\nOperation:", typ)
+			console.SetColor("Print", color.New(color.FgWhite))
+			console.Println(" * Inside:", summarizeOps(inside, inDur))
+			console.Println(" * Outside:", summarizeOps(outside, end.Sub(start)-inDur))
+		}
+	}
+}
+
+// printListPageAnalysis prints the latency of the pages of list operations.
+func printListPageAnalysis(annotations bench.Operations) {
+	if globalJSON {
+		return
+	}
+	p := annotations.ListPages()
+	if p.Pages == 0 {
+		return
+	}
+	console.Println("\n----------------------------------------")
+	console.SetColor("Print", color.New(color.FgHiWhite))
+	console.Printf("List pages: %d, %.0f entries per page.\n", p.Pages, p.EntriesPerPage())
+	console.SetColor("Print", color.New(color.FgWhite))
+	if p.Pages > p.Errors {
+		console.Printf(" * Latency avg: %v, 50%%: %v, 90%%: %v, 99%%: %v, Slowest: %v\n",
+			p.Avg.Round(time.Millisecond/10), p.Median.Round(time.Millisecond/10), p.P90.Round(time.Millisecond/10),
+			p.P99.Round(time.Millisecond/10), p.Slowest.Round(time.Millisecond/10))
+	}
+	if p.Errors > 0 {
+		console.SetColor("Print", color.New(color.FgHiRed))
+		console.Println(" * Errors:", p.Errors)
+		console.SetColor("Print", color.New(color.FgWhite))
+	}
+}
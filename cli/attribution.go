@@ -0,0 +1,61 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// printAttributionAnalysis prints where the time of operations was spent
+// when recorded with --latency.attribution.
+func printAttributionAnalysis(o bench.Operations) {
+	if globalJSON || len(o) == 0 {
+		return
+	}
+	header := false
+	estimated := false
+	for _, typ := range o.OpTypes() {
+		a := o.FilterByOp(typ).Attribution()
+		if a.Operations == 0 {
+			continue
+		}
+		if !header {
+			console.Println("\n----------------------------------------")
+			console.SetColor("Print", color.New(color.FgHiWhite))
+			console.Println("Latency attribution, average per operation:")
+			console.SetColor("Print", color.New(color.FgWhite))
+			header = true
+		}
+		server := "Server"
+		if !a.ServerTiming {
+			server = "Server*"
+			estimated = true
+		}
+		console.Printf(" * %s: Client: %v (%.0f%%), Network: %v (%.0f%%), %s: %v (%.0f%%). Operations: %d.\n", typ,
+			a.Client.Round(time.Microsecond), a.Share(a.Client),
+			a.Network.Round(time.Microsecond), a.Share(a.Network),
+			server, a.Server.Round(time.Microsecond), a.Share(a.Server), a.Operations)
+	}
+	if estimated {
+		console.Println("* Server time includes a network round trip. The server did not send Server-Timing headers.")
+	}
+}
@@ -0,0 +1,313 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+var auditFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "inventory",
+		Usage: "Compare against this S3 Inventory CSV file (optionally gzipped) instead of listing the bucket.",
+	},
+	cli.StringFlag{
+		Name:  "inventory.fields",
+		Value: "Bucket, Key, Size",
+		Usage: "Comma separated field names of the inventory file, as given by the 'fileSchema' of the inventory manifest.",
+	},
+	cli.StringFlag{
+		Name:  "audit.out",
+		Usage: "Write all discrepancies to this file as CSV.",
+	},
+	benchDataKeyFlag,
+}
+
+var auditCmd = cli.Command{
+	Name:   "audit",
+	Usage:  "compare bucket content against objects uploaded by a benchmark",
+	Action: mainAudit,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, auditFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] benchmark-data-file
+  -> see https://github.com/minio/warp#audit
+
+Use - as input to read from stdin.
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// auditDiscrepancy is a difference between expected and actual bucket content.
+type auditDiscrepancy struct {
+	Key      string
+	Kind     string
+	Expected int64
+	Actual   int64
+}
+
+const (
+	auditMissing    = "missing"
+	auditSize       = "size-mismatch"
+	auditUnexpected = "unexpected"
+)
+
+// mainAudit is the entry point for audit command.
+func mainAudit(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 1 {
+		console.Fatal("One benchmark data file must be supplied")
+	}
+	var input io.Reader
+	if args[0] == "-" {
+		input = os.Stdin
+	} else {
+		f, err := os.Open(args[0])
+		fatalIf(probe.NewError(err), "Unable to open input file")
+		defer f.Close()
+		input = f
+	}
+	dec, err := newBenchDataReader(input, ctx.String(benchDataKeyFlag.Name))
+	fatalIf(probe.NewError(err), "Unable to read input")
+	defer dec.Close()
+	log := console.Printf
+	if globalQuiet {
+		log = nil
+	}
+	ops, err := bench.OperationsFromCSV(dec, false, 0, 0, log)
+	fatalIf(probe.NewError(err), "Unable to parse input")
+
+	expected := expectedObjects(ops)
+	if len(expected) == 0 {
+		console.Fatal("No uploaded objects found in benchmark data")
+	}
+	prefixes := objectPrefixes(expected)
+	setFatalExitCode(exitError)
+
+	var actual map[string]int64
+	if inv := ctx.String("inventory"); inv != "" {
+		actual, err = readInventory(inv, ctx.String("bucket"), ctx.String("inventory.fields"))
+		fatalIf(probe.NewError(err), "Unable to read inventory")
+	} else {
+		actual, err = listObjects(ctx, prefixes)
+		fatalIf(probe.NewError(err), "Unable to list bucket")
+	}
+
+	diffs := diffObjects(expected, actual, prefixes)
+	console.Printf("Expected objects: %d, found objects: %d.\n", len(expected), len(actual))
+	counts := make(map[string]int, 3)
+	for _, d := range diffs {
+		counts[d.Kind]++
+	}
+	for _, kind := range []string{auditMissing, auditSize, auditUnexpected} {
+		console.Printf(" * %s: %d\n", kind, counts[kind])
+	}
+	if fn := ctx.String("audit.out"); fn != "" {
+		f, err := createOutputFile(fn, ctx.String(benchDataKeyFlag.Name))
+		fatalIf(probe.NewError(err), "Unable to create audit output")
+		w := csv.NewWriter(f)
+		w.Write([]string{"key", "discrepancy", "expected_size", "actual_size"})
+		for _, d := range diffs {
+			w.Write([]string{d.Key, d.Kind, strconv.FormatInt(d.Expected, 10), strconv.FormatInt(d.Actual, 10)})
+		}
+		w.Flush()
+		fatalIf(probe.NewError(w.Error()), "Unable to write audit output")
+		fatalIf(probe.NewError(f.Close()), "Unable to write audit output")
+		console.Println("Discrepancies saved to", fn)
+	}
+	if len(diffs) > 0 {
+		setFatalExitCode(exitVerification)
+		console.Fatal("Bucket content does not match benchmark data")
+	}
+	console.Println("Bucket content matches benchmark data.")
+	return nil
+}
+
+// expectedObjects returns the objects and sizes that should exist after the operations.
+func expectedObjects(ops bench.Operations) map[string]int64 {
+	ops.SortByEndTime()
+	res := make(map[string]int64)
+	for _, op := range ops {
+		if op.Err != "" || op.File == "" {
+			continue
+		}
+		switch op.OpType {
+		case http.MethodPut:
+			res[op.File] = op.Size
+		case http.MethodDelete:
+			if op.ObjPerOp == 1 {
+				delete(res, op.File)
+			}
+		}
+	}
+	return res
+}
+
+// objectPrefixes returns the prefixes of the object keys.
+func objectPrefixes(objs map[string]int64) []string {
+	m := make(map[string]struct{})
+	for k := range objs {
+		prefix := ""
+		if idx := strings.LastIndexByte(k, '/'); idx >= 0 {
+			prefix = k[:idx+1]
+		}
+		m[prefix] = struct{}{}
+	}
+	res := make([]string, 0, len(m))
+	for p := range m {
+		res = append(res, p)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// listObjects lists all objects with the given prefixes.
+func listObjects(ctx *cli.Context, prefixes []string) (map[string]int64, error) {
+	cl, done := newClient(ctx)()
+	defer done()
+	res := make(map[string]int64)
+	for _, prefix := range prefixes {
+		console.Eraseline()
+		console.Infof("\rListing %q...", prefix)
+		for obj := range cl.ListObjects(context.Background(), ctx.String("bucket"), minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+			if obj.Err != nil {
+				return nil, obj.Err
+			}
+			res[obj.Key] = obj.Size
+		}
+	}
+	console.Eraseline()
+	return res, nil
+}
+
+// readInventory reads the objects of the bucket from an S3 Inventory CSV file.
+func readInventory(fn, bucket, fields string) (map[string]int64, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	bucketIdx, keyIdx, sizeIdx := -1, -1, -1
+	for i, field := range strings.Split(fields, ",") {
+		switch strings.TrimSpace(field) {
+		case "Bucket":
+			bucketIdx = i
+		case "Key":
+			keyIdx = i
+		case "Size":
+			sizeIdx = i
+		}
+	}
+	if keyIdx < 0 || sizeIdx < 0 {
+		return nil, errors.New("inventory fields must contain Key and Size")
+	}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	res := make(map[string]int64)
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if keyIdx >= len(rec) || sizeIdx >= len(rec) {
+			return nil, fmt.Errorf("inventory record has %d fields", len(rec))
+		}
+		if bucketIdx >= 0 && bucketIdx < len(rec) && rec[bucketIdx] != bucket {
+			continue
+		}
+		// Inventory keys are URL encoded.
+		key, err := url.QueryUnescape(rec[keyIdx])
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.ParseInt(rec[sizeIdx], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		res[key] = size
+	}
+	return res, nil
+}
+
+// diffObjects returns the differences between expected and actual objects.
+// Only unexpected objects within the prefixes are reported.
+func diffObjects(expected, actual map[string]int64, prefixes []string) []auditDiscrepancy {
+	var res []auditDiscrepancy
+	for k, size := range expected {
+		got, ok := actual[k]
+		switch {
+		case !ok:
+			res = append(res, auditDiscrepancy{Key: k, Kind: auditMissing, Expected: size, Actual: -1})
+		case got != size:
+			res = append(res, auditDiscrepancy{Key: k, Kind: auditSize, Expected: size, Actual: got})
+		}
+	}
+	for k, size := range actual {
+		if _, ok := expected[k]; ok {
+			continue
+		}
+		for _, p := range prefixes {
+			if strings.HasPrefix(k, p) && !strings.Contains(k[len(p):], "/") {
+				res = append(res, auditDiscrepancy{Key: k, Kind: auditUnexpected, Expected: -1, Actual: size})
+				break
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Key < res[j].Key
+	})
+	return res
+}
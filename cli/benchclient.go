@@ -28,7 +28,6 @@ import (
 
 	"github.com/gorilla/websocket"
 	"github.com/minio/cli"
-	"github.com/minio/pkg/console"
 	"github.com/minio/warp/pkg/bench"
 )
 
@@ -52,7 +51,12 @@ type clientReply struct {
 		Finished bool              `json:"finished"`
 		Progress float64           `json:"progress"`
 		Custom   map[string]string `json:"custom,omitempty"`
+		// Prepare is sent when the prepare stage has finished.
+		Prepare *bench.PrepareStats `json:"prepare,omitempty"`
 	} `json:"stage_info"`
+	// Logs are the warnings and errors logged since the last reply.
+	Logs        []logEntry `json:"logs,omitempty"`
+	LogsDropped int        `json:"logs_dropped,omitempty"`
 }
 
 // executeBenchmark will execute the benchmark and return any error.
@@ -83,10 +87,10 @@ func (s serverRequest) executeBenchmark(ctx context.Context) (*clientBenchmark,
 	activeBenchmark = &cb
 	activeBenchmarkMu.Unlock()
 
-	console.Infoln("Executing", cmd.Name, "benchmark.")
+	logInfo("Executing benchmark", "command", cmd.Name)
 	if globalDebug {
 		// params have secret, so disable by default.
-		console.Infoln("Params:", s.Benchmark.Flags, ctx2.Args())
+		logDebug("Benchmark parameters", "flags", s.Benchmark.Flags, "args", ctx2.Args())
 	}
 	go func() {
 		err := runCommand(ctx2, cmd)
@@ -115,18 +119,18 @@ var wsUpgrader = websocket.Upgrader{
 func serveWs(w http.ResponseWriter, r *http.Request) {
 	ws, err := wsUpgrader.Upgrade(w, r, nil)
 	if err != nil {
-		console.Error("upgrade:", err.Error())
+		logError("Upgrading connection", "err", err)
 		return
 	}
 
 	defer func() {
 		ws.Close()
-		console.Infoln("Closing connection")
+		logInfo("Closing connection")
 	}()
 	var s serverInfo
 	err = ws.ReadJSON(&s)
 	if err != nil {
-		console.Error("Error reading server info:", err.Error())
+		logError("Reading server info", "err", err)
 		return
 	}
 	if err = s.validate(); err != nil {
@@ -148,7 +152,7 @@ func serveWs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	console.Infoln("Accepting connection from server:", s.ID)
+	logInfo("Accepting connection from server", "server", s.ID)
 	defer func() {
 		// When we return, reset connection info.
 		connectedMu.Lock()
@@ -160,23 +164,21 @@ func serveWs(w http.ResponseWriter, r *http.Request) {
 	// Confirm the connection
 	err = ws.WriteJSON(clientReply{Time: time.Now()})
 	if err != nil {
-		console.Error("Writing response:", err)
+		logError("Writing response", "err", err)
 		return
 	}
 	for {
 		var req serverRequest
 		err := ws.ReadJSON(&req)
 		if err != nil {
-			console.Error("Reading server message:", err.Error())
+			logError("Reading server message", "err", err)
 			return
 		}
-		if globalDebug {
-			console.Infof("Request: %v\n", req.Operation)
-		}
+		logDebug("Request", "op", req.Operation)
 		var resp clientReply
 		switch req.Operation {
 		case serverReqDisconnect:
-			console.Infoln("Received Disconnect")
+			logInfo("Received disconnect")
 			activeBenchmarkMu.Lock()
 			ab := activeBenchmark
 			activeBenchmarkMu.Unlock()
@@ -197,7 +199,7 @@ func serveWs(w http.ResponseWriter, r *http.Request) {
 			_, err := req.executeBenchmark(context.Background())
 			resp.Type = clientRespBenchmarkStarted
 			if err != nil {
-				console.Errorln("Starting benchmark:", err)
+				logError("Starting benchmark", "err", err)
 				resp.Err = err.Error()
 			}
 		case serverReqStartStage:
@@ -209,9 +211,8 @@ func serveWs(w http.ResponseWriter, r *http.Request) {
 				break
 			}
 			ab.Lock()
-			stageInfo := ab.info
+			info, ok := ab.info[req.Stage]
 			ab.Unlock()
-			info, ok := stageInfo[req.Stage]
 			if !ok {
 				resp.Err = "stage not found"
 				break
@@ -229,7 +230,7 @@ func serveWs(w http.ResponseWriter, r *http.Request) {
 			if wait < 0 {
 				wait = 0
 			}
-			console.Infoln("Starting stage", req.Stage, "in", wait)
+			logInfo("Starting stage", "stage", req.Stage, "in", wait)
 			go func() {
 				time.Sleep(wait)
 				close(info.start)
@@ -246,13 +247,13 @@ func serveWs(w http.ResponseWriter, r *http.Request) {
 			resp.Type = clientRespStatus
 			ab.Lock()
 			err := ab.err
-			stageInfo := ab.info
+			info, ok := ab.info[req.Stage]
+			prepared := ab.prepared
 			ab.Unlock()
 			if err != nil {
 				resp.Err = err.Error()
 				break
 			}
-			info, ok := stageInfo[req.Stage]
 			if !ok {
 				resp.Err = "stage not found"
 				break
@@ -262,10 +263,14 @@ func serveWs(w http.ResponseWriter, r *http.Request) {
 				resp.StageInfo.Started = true
 			default:
 			}
+			resp.StageInfo.Progress = info.progress
 			select {
 			case <-info.done:
 				resp.StageInfo.Finished = true
 				resp.StageInfo.Custom = info.custom
+				if req.Stage == stagePrepare {
+					resp.StageInfo.Prepare = prepared
+				}
 			default:
 			}
 		case serverReqSendOps:
@@ -284,12 +289,13 @@ func serveWs(w http.ResponseWriter, r *http.Request) {
 			resp.Err = "unknown command"
 		}
 		resp.Time = time.Now()
-		if globalDebug {
-			console.Infof("Sending %v\n", resp.Type)
+		if globalLogShipper != nil {
+			resp.Logs, resp.LogsDropped = globalLogShipper.take()
 		}
+		logDebug("Sending reply", "type", resp.Type)
 		err = ws.WriteJSON(resp)
 		if err != nil {
-			console.Error("Writing response:", err)
+			logError("Writing response", "err", err)
 			return
 		}
 	}
@@ -0,0 +1,441 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/minio/cli"
+	"github.com/secure-io/sio-go"
+	"golang.org/x/crypto/scrypt"
+)
+
+var benchDataKeyFlag = cli.StringFlag{
+	Name:   "benchdata.key",
+	Usage:  "Encrypt benchmark data and analysis output with this passphrase. Also used to read encrypted files.",
+	EnvVar: appNameUC + "_BENCHDATA_KEY",
+}
+
+// Encrypted files start with encMagic, followed by the salt,
+// the key verifier and the nonce of the AES-GCM stream.
+var encMagic = []byte("warpenc\x01")
+
+// Appendable encrypted files start with encFrameMagic, followed by the salt and the key verifier.
+// Each write is stored as frames of the ciphertext length, a random nonce and the ciphertext,
+// which are encrypted separately with AES-GCM, so frames can be appended and read back
+// if the file was not closed.
+var encFrameMagic = []byte("warpenc\x02")
+
+const (
+	encSaltSize     = 16
+	encVerifierSize = 32
+
+	// encFrameMaxSize is the maximum plaintext size of a frame.
+	encFrameMaxSize = 1 << 20
+)
+
+// deriveEncKey derives the encryption key and a verifier of the key from the passphrase.
+func deriveEncKey(passphrase string, salt []byte) (key, verifier []byte, err error) {
+	k, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32+encVerifierSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	return k[:32], k[32:], nil
+}
+
+// newEncryptWriter returns a writer that encrypts everything written to w with the passphrase.
+// Close must be called to write the final data. w is closed if it is an io.Closer.
+func newEncryptWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	salt := make([]byte, encSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	key, verifier, err := deriveEncKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := sio.AES_256_GCM.Stream(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, stream.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	header := make([]byte, 0, len(encMagic)+len(salt)+len(verifier)+len(nonce))
+	header = append(header, encMagic...)
+	header = append(header, salt...)
+	header = append(header, verifier...)
+	header = append(header, nonce...)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return stream.EncryptWriter(w, nonce, header), nil
+}
+
+// newDecryptReader returns a reader that decrypts r if it is encrypted.
+// Unencrypted input is returned as is.
+func newDecryptReader(r io.Reader, passphrase string) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(encMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if bytes.Equal(magic, encFrameMagic) {
+		return newFrameReader(br, passphrase)
+	}
+	if !bytes.Equal(magic, encMagic) {
+		return br, nil
+	}
+	key, header, err := readEncHeader(br, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := sio.AES_256_GCM.Stream(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, stream.NonceSize())
+	if _, err := io.ReadFull(br, nonce); err != nil {
+		return nil, err
+	}
+	return stream.DecryptReader(br, nonce, append(header, nonce...)), nil
+}
+
+// readEncHeader reads the magic, salt and key verifier of an encrypted file
+// and returns the key and the header read.
+func readEncHeader(r io.Reader, passphrase string) (key, header []byte, err error) {
+	if passphrase == "" {
+		return nil, nil, errors.New("input is encrypted, specify the key with --benchdata.key")
+	}
+	header = make([]byte, len(encMagic)+encSaltSize+encVerifierSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, err
+	}
+	key, verifier, err := deriveEncKey(passphrase, header[len(encMagic):len(encMagic)+encSaltSize])
+	if err != nil {
+		return nil, nil, err
+	}
+	if subtle.ConstantTimeCompare(verifier, header[len(encMagic)+encSaltSize:]) != 1 {
+		return nil, nil, errors.New("incorrect key for encrypted input")
+	}
+	return key, header, nil
+}
+
+// frameCipher encrypts and decrypts the frames of an appendable encrypted file.
+// The header and the index of each frame are authenticated with the frame,
+// so frames cannot be moved between files or reordered.
+type frameCipher struct {
+	aead   cipher.AEAD
+	header []byte
+	// n is the index of the next frame.
+	n uint64
+}
+
+func newFrameCipher(key, header []byte) (*frameCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &frameCipher{aead: aead, header: header}, nil
+}
+
+// additionalData returns the authenticated data of the next frame.
+func (c *frameCipher) additionalData() []byte {
+	ad := make([]byte, len(c.header)+8)
+	copy(ad, c.header)
+	binary.BigEndian.PutUint64(ad[len(c.header):], c.n)
+	return ad
+}
+
+// frameReader decrypts the frames of an appendable encrypted file.
+// A frame cut short by an interrupted write ends the file.
+type frameReader struct {
+	c   *frameCipher
+	r   io.Reader
+	buf []byte
+	// off is the end of the last complete frame.
+	off int64
+}
+
+// newFrameReader reads the header of the appendable encrypted file in r.
+func newFrameReader(r io.Reader, passphrase string) (*frameReader, error) {
+	key, header, err := readEncHeader(r, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newFrameCipher(key, header)
+	if err != nil {
+		return nil, err
+	}
+	return &frameReader{c: c, r: r, off: int64(len(header))}, nil
+}
+
+func (f *frameReader) Read(p []byte) (int, error) {
+	for len(f.buf) == 0 {
+		if err := f.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, f.buf)
+	f.buf = f.buf[n:]
+	return n, nil
+}
+
+// next reads and decrypts the next frame.
+func (f *frameReader) next() error {
+	var size [4]byte
+	if _, err := io.ReadFull(f.r, size[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return io.EOF
+		}
+		return err
+	}
+	n := int(binary.BigEndian.Uint32(size[:]))
+	if n < f.c.aead.Overhead() || n > encFrameMaxSize+f.c.aead.Overhead() {
+		return errors.New("invalid frame in encrypted input")
+	}
+	frame := make([]byte, f.c.aead.NonceSize()+n)
+	if _, err := io.ReadFull(f.r, frame); err != nil {
+		if err == io.ErrUnexpectedEOF || err == io.EOF {
+			return io.EOF
+		}
+		return err
+	}
+	nonce := frame[:f.c.aead.NonceSize()]
+	plain, err := f.c.aead.Open(frame[len(nonce):len(nonce)], nonce, frame[len(nonce):], f.c.additionalData())
+	if err != nil {
+		return fmt.Errorf("frame %d of encrypted input: %w", f.c.n, err)
+	}
+	f.c.n++
+	f.off += int64(len(size) + len(frame))
+	f.buf = plain
+	return nil
+}
+
+// frameWriter appends encrypted frames to a file.
+// Each write is synced to disk before it returns.
+type frameWriter struct {
+	c *frameCipher
+	f *os.File
+}
+
+func (w *frameWriter) Write(p []byte) (int, error) {
+	ns, overhead := w.c.aead.NonceSize(), w.c.aead.Overhead()
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > encFrameMaxSize {
+			chunk = chunk[:encFrameMaxSize]
+		}
+		frame := make([]byte, 4+ns, 4+ns+len(chunk)+overhead)
+		binary.BigEndian.PutUint32(frame, uint32(len(chunk)+overhead))
+		if _, err := io.ReadFull(rand.Reader, frame[4:]); err != nil {
+			return written, err
+		}
+		frame = w.c.aead.Seal(frame, frame[4:], chunk, w.c.additionalData())
+		if _, err := w.f.Write(frame); err != nil {
+			return written, err
+		}
+		w.c.n++
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, w.f.Sync()
+}
+
+func (w *frameWriter) Close() error {
+	return w.f.Close()
+}
+
+// appendFramedFile returns a writer appending encrypted frames to the file.
+// Everything written is on disk when the write returns,
+// so content survives if the writer is not closed.
+// A frame cut short by an interrupted write is removed.
+// Files that are not appendable are converted first.
+// existing reports whether the file had any content.
+func appendFramedFile(fn, passphrase string) (w io.WriteCloser, existing bool, err error) {
+	f, err := os.OpenFile(fn, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, false, err
+	}
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(len(encFrameMagic))
+	switch {
+	case err == io.EOF && len(magic) == 0:
+		// New file.
+		salt := make([]byte, encSaltSize)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			f.Close()
+			return nil, false, err
+		}
+		key, verifier, err := deriveEncKey(passphrase, salt)
+		if err != nil {
+			f.Close()
+			return nil, false, err
+		}
+		header := append(append(append([]byte{}, encFrameMagic...), salt...), verifier...)
+		c, err := newFrameCipher(key, header)
+		if err == nil {
+			_, err = f.Write(header)
+		}
+		if err == nil {
+			err = f.Sync()
+		}
+		if err != nil {
+			f.Close()
+			return nil, false, err
+		}
+		return &frameWriter{c: c, f: f}, false, nil
+	case err != nil && err != io.EOF:
+		f.Close()
+		return nil, false, err
+	case !bytes.Equal(magic, encFrameMagic):
+		f.Close()
+		if err := convertFramedFile(fn, passphrase); err != nil {
+			return nil, false, err
+		}
+		return appendFramedFile(fn, passphrase)
+	}
+	fr, err := newFrameReader(br, passphrase)
+	if err == nil {
+		_, err = io.Copy(io.Discard, fr)
+	}
+	if err == nil {
+		// Remove a frame cut short and append after the last complete frame.
+		err = f.Truncate(fr.off)
+	}
+	if err == nil {
+		_, err = f.Seek(fr.off, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, false, fmt.Errorf("%s: %w", fn, err)
+	}
+	return &frameWriter{c: fr.c, f: f}, fr.c.n > 0, nil
+}
+
+// convertFramedFile rewrites an unencrypted file, or a file encrypted as a stream,
+// as an appendable encrypted file.
+func convertFramedFile(fn, passphrase string) error {
+	f, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	r, err := newDecryptReader(f, passphrase)
+	var content []byte
+	if err == nil {
+		content, err = io.ReadAll(r)
+	}
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	tmp := fn + ".tmp"
+	os.Remove(tmp)
+	w, _, err := appendFramedFile(tmp, passphrase)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(content); err != nil {
+		w.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := w.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, fn)
+}
+
+// createOutputFile creates a file, which is encrypted if a passphrase is given.
+func createOutputFile(fn, passphrase string) (io.WriteCloser, error) {
+	f, err := os.Create(fn)
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return f, nil
+	}
+	enc, err := newEncryptWriter(f, passphrase)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return enc, nil
+}
+
+// appendEncryptedFile returns a writer appending to the file, encrypted with the passphrase.
+// Encrypted files cannot be appended to, so the decrypted content is written again
+// to a temporary file, which replaces the file when the writer is closed.
+// existing reports whether the file had any content.
+func appendEncryptedFile(fn, passphrase string) (w io.WriteCloser, existing bool, err error) {
+	var content []byte
+	if f, err := os.Open(fn); err == nil {
+		r, err := newDecryptReader(f, passphrase)
+		if err == nil {
+			content, err = io.ReadAll(r)
+		}
+		f.Close()
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %w", fn, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, false, err
+	}
+	tmp := fn + ".tmp"
+	f, err := createOutputFile(tmp, passphrase)
+	if err != nil {
+		return nil, false, err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return nil, false, err
+	}
+	return replaceOnClose{WriteCloser: f, tmp: tmp, fn: fn}, len(content) > 0, nil
+}
+
+// replaceOnClose replaces fn with the temporary file when closed.
+type replaceOnClose struct {
+	io.WriteCloser
+	tmp, fn string
+}
+
+func (r replaceOnClose) Close() error {
+	if err := r.WriteCloser.Close(); err != nil {
+		os.Remove(r.tmp)
+		return err
+	}
+	return os.Rename(r.tmp, r.fn)
+}
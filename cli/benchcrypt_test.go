@@ -0,0 +1,144 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minio/warp/pkg/bench"
+)
+
+// readRegistryFile reads the entries of an encrypted registry.
+func readRegistryFile(t *testing.T, fn, key string) []bench.ManifestEntry {
+	t.Helper()
+	f, err := os.Open(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := newDecryptReader(f, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := bench.ReadManifest(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func registryEntry(i int) bench.ManifestEntry {
+	return bench.ManifestEntry{Key: fmt.Sprintf("obj-%d", i), Size: int64(i), SHA256: fmt.Sprint("sha-", i), ETag: fmt.Sprint("etag-", i)}
+}
+
+func TestEncryptedRegistryCrash(t *testing.T) {
+	const key = "secret"
+	fn := filepath.Join(t.TempDir(), "registry.csv")
+
+	// A run that is killed: the registry is never closed.
+	r, err := openRegistry(fn, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	for i := 0; i < 3; i++ {
+		if err := r.Add(registryEntry(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The last write was cut short.
+	f, err := os.OpenFile(fn, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0, 100, 1, 2, 3, 4, 5, 6, 7, 8})
+	f.Close()
+
+	if got := readRegistryFile(t, fn, key); len(got) != 3 {
+		t.Fatalf("got %d entries after crash, want 3", len(got))
+	}
+	f, err = os.Open(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newDecryptReader(f, "wrong"); err == nil {
+		t.Error("registry opened with wrong key")
+	}
+	f.Close()
+
+	// The next run appends after the last complete entry.
+	r2, err := openRegistry(fn, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r2.Add(registryEntry(3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r2.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got := readRegistryFile(t, fn, key)
+	if len(got) != 4 {
+		t.Fatalf("got %d entries, want 4", len(got))
+	}
+	for i, e := range got {
+		if e != registryEntry(i) {
+			t.Errorf("entry %d: got %v, want %v", i, e, registryEntry(i))
+		}
+	}
+}
+
+func TestEncryptedRegistryConvert(t *testing.T) {
+	const key = "secret"
+	fn := filepath.Join(t.TempDir(), "registry.csv")
+
+	// Registries were encrypted as a single stream before.
+	f, err := createOutputFile(fn, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m bench.Manifest
+	m.Add(registryEntry(0))
+	if err := m.WriteCSV(f); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := openRegistry(fn, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(registryEntry(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got := readRegistryFile(t, fn, key)
+	if len(got) != 2 || got[0] != registryEntry(0) || got[1] != registryEntry(1) {
+		t.Fatalf("got %v", got)
+	}
+	if _, err := os.Stat(fn + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left: %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/minio/cli"
+	"github.com/minio/warp/pkg/bench"
+)
+
+var benchDataCompressFlag = cli.StringFlag{
+	Name:  "benchdata.compress",
+	Value: "zstd",
+	Usage: "Compression of benchmark data. Can be 'zstd', 'zstd:fastest', 'zstd:default', 'zstd:better', 'zstd:best', 'snappy' or 'none'",
+}
+
+// benchDataFlushInterval is how often the compressed stream is flushed to disk,
+// so data is written if warp is stopped. Compressors write full blocks as they go.
+const benchDataFlushInterval = 10 * time.Second
+
+var (
+	zstdMagic   = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	snappyMagic = []byte("\xff\x06\x00\x00sNaPpY")
+)
+
+// benchDataCompression describes how benchmark data is stored.
+type benchDataCompression struct {
+	name  string
+	ext   string
+	level zstd.EncoderLevel
+}
+
+// parseBenchDataCompression parses a compression specification.
+func parseBenchDataCompression(s string) (benchDataCompression, error) {
+	switch s {
+	case "", "zstd":
+		return benchDataCompression{name: "zstd", ext: ".csv.zst", level: zstd.SpeedBetterCompression}, nil
+	case "snappy":
+		return benchDataCompression{name: s, ext: ".csv.sz"}, nil
+	case "none":
+		return benchDataCompression{name: s, ext: ".csv"}, nil
+	}
+	if lvl := strings.TrimPrefix(s, "zstd:"); lvl != s {
+		ok, level := zstd.EncoderLevelFromString(lvl)
+		if !ok {
+			return benchDataCompression{}, errors.New("unknown zstd level: " + lvl)
+		}
+		return benchDataCompression{name: "zstd", ext: ".csv.zst", level: level}, nil
+	}
+	return benchDataCompression{}, errors.New("unknown benchmark data compression: " + s)
+}
+
+// benchDataFile is an open benchmark data output file.
+type benchDataFile struct {
+	// Name is the full file name, including extension.
+	Name string
+
+	mu      sync.Mutex
+	f       io.WriteCloser
+	comp    io.WriteCloser
+	flusher interface{ Flush() error }
+	w       io.Writer
+	// dirty is set when data was written since the last flush.
+	dirty bool
+	err   error
+	stop  chan struct{}
+}
+
+// createBenchData creates the benchmark data output for the base name given.
+// The file is created at once, so errors can be reported before the benchmark is run.
+func createBenchData(ctx *cli.Context, baseName string) (*benchDataFile, error) {
+	comp, err := parseBenchDataCompression(ctx.String(benchDataCompressFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+	name := baseName + comp.ext
+	key := ctx.String(benchDataKeyFlag.Name)
+	if key != "" {
+		name += ".enc"
+	}
+	f, err := createOutputFile(name, key)
+	if err != nil {
+		return nil, err
+	}
+	b := &benchDataFile{Name: name, f: f, w: f}
+	switch comp.name {
+	case "zstd":
+		enc, err := zstd.NewWriter(f, zstd.WithEncoderLevel(comp.level), zstd.WithLowerEncoderMem(true))
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		b.comp, b.flusher, b.w = enc, enc, enc
+	case "snappy":
+		enc := snappy.NewBufferedWriter(f)
+		b.comp, b.flusher, b.w = enc, enc, enc
+	}
+	if b.flusher != nil {
+		b.stop = make(chan struct{})
+		go b.flushEvery(benchDataFlushInterval)
+	}
+	return b, nil
+}
+
+// flushEvery flushes the compressed stream at the interval until the file is closed.
+func (b *benchDataFile) flushEvery(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-t.C:
+		}
+		b.mu.Lock()
+		if b.dirty && b.err == nil {
+			b.err = b.flusher.Flush()
+			b.dirty = false
+		}
+		b.mu.Unlock()
+	}
+}
+
+// Write writes uncompressed data.
+func (b *benchDataFile) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return 0, b.err
+	}
+	n, err := b.w.Write(p)
+	b.dirty = true
+	b.err = err
+	return n, err
+}
+
+// Close will flush all data and close the file.
+func (b *benchDataFile) Close() error {
+	if b.stop != nil {
+		close(b.stop)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.err
+	if b.comp != nil {
+		if cerr := b.comp.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if cerr := b.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// benchDataStream writes operations to the benchmark data as they are recorded,
+// with the client ID, threads and redaction applied when the data was written after the run.
+type benchDataStream struct {
+	ctx      *cli.Context
+	w        *bench.CSVWriter
+	clientID string
+	pipeline int
+	redact   bool
+}
+
+// newBenchDataStream writes the CSV header to out and returns a stream of operations to it.
+func newBenchDataStream(ctx *cli.Context, out io.Writer, clientID string) (*benchDataStream, error) {
+	w, err := bench.NewCSVWriter(out)
+	if err != nil {
+		return nil, err
+	}
+	return &benchDataStream{
+		ctx:      ctx,
+		w:        w,
+		clientID: clientID,
+		pipeline: ctx.Int("pipeline"),
+		redact:   ctx.Bool(redactFlag.Name),
+	}, nil
+}
+
+// Add an operation. Write errors are returned by Close.
+func (s *benchDataStream) Add(op bench.Operation) {
+	op.ClientID = s.clientID
+	if s.pipeline > 1 {
+		op.Thread = uint16(int(op.Thread) / s.pipeline)
+	}
+	if s.redact {
+		ops := bench.Operations{op}
+		redactOps(s.ctx, ops)
+		op = ops[0]
+	}
+	s.w.Write(op)
+}
+
+// Close writes the annotations and the comment after the operations.
+func (s *benchDataStream) Close(annotations bench.Operations, comment string) error {
+	for _, a := range annotations {
+		s.w.Write(a)
+	}
+	return s.w.Close(comment)
+}
+
+// readBenchData reads the operations and comments of a benchmark data file.
+func readBenchData(ctx *cli.Context, fn string) (bench.Operations, []string, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	dec, err := newBenchDataReader(f, ctx.String(benchDataKeyFlag.Name))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer dec.Close()
+	// Keep object names when they are written with the slowest operations.
+	analyzeOnly := ctx.String("analyze.outliers") == ""
+	return bench.OperationsFromCSVComments(dec, analyzeOnly, 0, 0, nil)
+}
+
+// newBenchDataReader returns a reader that will decrypt and decompress benchmark data.
+// The compression and encryption is detected from the content.
+func newBenchDataReader(r io.Reader, key string) (io.ReadCloser, error) {
+	r, err := newDecryptReader(r, key)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(snappyMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, zstdMagic):
+		dec, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	case bytes.HasPrefix(magic, snappyMagic):
+		return io.NopCloser(snappy.NewReader(br)), nil
+	}
+	return io.NopCloser(br), nil
+}
+
+// benchDataBaseName returns the file name with any benchmark data extension removed.
+func benchDataBaseName(name string) string {
+	name = strings.TrimSuffix(name, ".enc")
+	for _, ext := range []string{".csv.zst", ".csv.sz", ".csv"} {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return name
+}
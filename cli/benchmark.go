@@ -24,13 +24,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"os/signal"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/cheggaaa/pb"
-	"github.com/klauspost/compress/zstd"
 	"github.com/minio/cli"
 	"github.com/minio/madmin-go"
 	"github.com/minio/mc/pkg/probe"
@@ -45,16 +46,134 @@ var benchFlags = []cli.Flag{
 		Value: "",
 		Usage: "Output benchmark+profile data to this file. By default unique filename is generated.",
 	},
+	benchDataCompressFlag,
+	redactFlag,
 	cli.StringFlag{
 		Name:  "serverprof",
 		Usage: "Run MinIO server profiling during benchmark; possible values are 'cpu', 'mem', 'block', 'mutex' and 'trace'.",
 		Value: "",
 	},
+	cli.DurationFlag{
+		Name:  "heal.after",
+		Usage: "Start healing the benchmark bucket on the MinIO server this long after the benchmark has started. 0 disables healing.",
+	},
+	cli.StringFlag{
+		Name:  "heal.scan",
+		Usage: "Heal scan mode. Can be 'normal' or 'deep'.",
+		Value: "normal",
+	},
+	cli.StringFlag{
+		Name:  "restart.hook",
+		Usage: "Restart servers one by one during the benchmark by calling this script or POSTing to this URL with each host.",
+	},
+	cli.StringFlag{
+		Name:  "restart.hosts",
+		Usage: "Hosts to restart, in order. Defaults to --host.",
+	},
+	cli.DurationFlag{
+		Name:  "restart.after",
+		Usage: "Time after the benchmark has started to restart the first host.",
+		Value: 30 * time.Second,
+	},
+	cli.DurationFlag{
+		Name:  "restart.interval",
+		Usage: "Time to wait between restarting hosts.",
+		Value: 30 * time.Second,
+	},
+	cli.StringFlag{
+		Name:  "hook.pre",
+		Usage: "Run this command or POST to this URL before preparing. The benchmark is stopped if it fails.",
+	},
+	cli.StringFlag{
+		Name:  "hook.stage",
+		Usage: "Run this command or POST to this URL at the start and end of the prepare, benchmark and cleanup stages.",
+	},
+	cli.StringFlag{
+		Name:  "hook.post",
+		Usage: "Run this command or POST to this URL when the benchmark has finished.",
+	},
+	cli.DurationFlag{
+		Name:  "hook.timeout",
+		Usage: "Maximum time a hook may run.",
+		Value: 5 * time.Minute,
+	},
+	cli.StringFlag{
+		Name:  "capabilities",
+		Usage: "Probe the server for features used by the benchmark. 'fail' stops if a feature is unsupported, 'auto' disables optional features and 'off' skips probing.",
+		Value: capModeFail,
+	},
+	cli.StringFlag{
+		Name:  "preflight",
+		Usage: "Check open file limit, ephemeral ports and connection tracking against the concurrency. 'warn' prints problems, 'fail' stops the benchmark and 'off' skips the check.",
+		Value: preflightWarn,
+	},
+	cli.BoolFlag{
+		Name:  "latency.attribution",
+		Usage: "Record the client, network and server time of GET, PUT, STAT and DELETE operations. Servers can report their processing time in a Server-Timing header.",
+	},
+	cli.Float64Flag{
+		Name:  "rate",
+		Usage: "Limit the number of operations started per second by each warp instance. 0 is unlimited.",
+	},
+	cli.IntFlag{
+		Name:  "rate.burst",
+		Usage: "Number of operations that can be started at once when rate limited.",
+		Value: 1,
+	},
+	cli.StringFlag{
+		Name:  "rate.arrival",
+		Usage: "Arrival of operations when rate limited. Can be 'fixed' or 'poisson'.",
+		Value: bench.ArrivalFixed,
+	},
+	cli.IntFlag{
+		Name:  "pipeline",
+		Usage: "Number of operations each concurrent worker keeps in flight on one HTTP/2 connection.",
+		Value: 1,
+	},
+	cli.BoolFlag{
+		Name:  "debug.deterministic",
+		Usage: "Run operations on a single thread in a fixed order with fixed seeds, so a run can be reproduced exactly. Throughput is not representative.",
+	},
+	cli.Int64Flag{
+		Name:  "debug.seed",
+		Usage: "Seed of all random choices when debug.deterministic is set.",
+		Value: 1,
+	},
+	cli.StringFlag{
+		Name:  "budget.bytes",
+		Usage: "Refuse to run if the benchmark is estimated to upload and download more than this amount of data. Example: 100GiB",
+	},
+	cli.Int64Flag{
+		Name:  "budget.requests",
+		Usage: "Refuse to run if the benchmark is estimated to send more than this number of requests.",
+	},
+	cli.Float64Flag{
+		Name:  "budget.cost",
+		Usage: "Refuse to run if the benchmark is estimated to cost more than this, using --budget.price.requests and --budget.price.gb.",
+	},
+	cli.Float64Flag{
+		Name:  "budget.price.requests",
+		Usage: "Price of 1000 requests, used with --budget.cost.",
+		Value: 0.005,
+	},
+	cli.Float64Flag{
+		Name:  "budget.price.gb",
+		Usage: "Price of transferring 1GB, used with --budget.cost.",
+		Value: 0.09,
+	},
 	cli.DurationFlag{
 		Name:  "duration",
 		Usage: "Duration to run the benchmark. Use 's' and 'm' to specify seconds and minutes.",
 		Value: 5 * time.Minute,
 	},
+	cli.DurationFlag{
+		Name:  "op.timeout",
+		Usage: "Maximum duration of each operation. Operations taking longer are canceled and recorded as errors. 0 disables.",
+	},
+	cli.DurationFlag{
+		Name:  "live",
+		Usage: "Print throughput and 50/99 percentile request times of each operation type at this interval while the benchmark runs. 0 disables.",
+	},
 	cli.BoolFlag{
 		Name:  "autoterm",
 		Usage: "Auto terminate when benchmark is considered stable.",
@@ -73,6 +192,26 @@ var benchFlags = []cli.Flag{
 		Name:  "noclear",
 		Usage: "Do not clear bucket before or after running benchmarks. Use when running multiple clients.",
 	},
+	cli.BoolFlag{
+		Name:  "i-know-what-im-doing",
+		Usage: "Clear the bucket even if it contains data not created by warp.",
+	},
+	cli.StringFlag{
+		Name:  "bucket.allow",
+		Usage: "Comma separated bucket name patterns that may be cleared without checking for data not created by warp.",
+	},
+	cli.StringFlag{
+		Name:  "cleanup.journal",
+		Usage: "Record every object created by warp to this CSV file and only delete unchanged objects recorded in it when clearing or cleaning up. The bucket check is skipped.",
+	},
+	cli.StringFlag{
+		Name:  "manifest",
+		Usage: "Write a manifest of objects uploaded while preparing, or by inject, to this file. JSON if the name ends with .json, otherwise CSV.",
+	},
+	cli.StringFlag{
+		Name:  "registry",
+		Usage: "Append the key and checksum of objects uploaded while preparing, or by inject, to this CSV file as they are uploaded. Can be verified like a manifest.",
+	},
 	cli.BoolFlag{
 		Name:   "keep-data",
 		Usage:  "Leave benchmark data. Do not run cleanup after benchmark. Bucket will still be cleaned prior to benchmark",
@@ -89,6 +228,21 @@ var benchFlags = []cli.Flag{
 		EnvVar: "",
 		Value:  "",
 	},
+	cli.BoolFlag{
+		Name:   "multi.sync",
+		Usage:  "Wait for the start time from the multi command.",
+		Hidden: true,
+	},
+	cli.StringFlag{
+		Name:   "template.time",
+		Usage:  "Time used for template variables. Sent to warp clients, so all clients expand the same time.",
+		Hidden: true,
+	},
+	cli.IntFlag{
+		Name:  "procs",
+		Usage: "Run the benchmark in this many warp processes on this machine and merge the results.",
+		Value: 1,
+	},
 }
 
 // runBench will run the supplied benchmark and save/print the analysis.
@@ -97,11 +251,50 @@ func runBench(ctx *cli.Context, b bench.Benchmark) error {
 	ab := activeBenchmark
 	activeBenchmarkMu.Unlock()
 	b.GetCommon().Error = printError
+	b.GetCommon().LatencyTrace = ctx.Bool("latency.attribution")
+	b.GetCommon().OpTimeout = ctx.Duration("op.timeout")
+	if ctx.Bool("backoff") {
+		b.GetCommon().Backoff = backoff(ctx)
+	}
+	if c := b.GetCommon(); c.MetaClient == nil {
+		c.MetaClient = newMetaClient(ctx)
+	}
+	if c := b.GetCommon(); c.KeyClient == nil {
+		c.KeyClient = newKeyClient(ctx)
+	}
+	var modeNotes []string
+	if ctx.Bool("debug.deterministic") {
+		c := b.GetCommon()
+		c.Concurrency = 1
+		c.Deterministic = true
+		c.Seed = ctx.Int64("debug.seed")
+		modeNotes = append(modeNotes, fmt.Sprintf("Deterministic mode with seed %d on a single thread, throughput is not representative.", c.Seed))
+	}
+	if l := newRateLimiter(ctx); l != nil {
+		modeNotes = append(modeNotes, fmt.Sprintf("Operations rate limited to %v per client.", l))
+	}
+	if enc := ctx.String("obj.content.encoding"); enc != "" {
+		modeNotes = append(modeNotes, fmt.Sprintf("Objects uploaded with %s Content-Encoding, PUT and GET sizes are before compression.", enc))
+	}
+	if k := ctx.Int("pipeline"); k > 1 {
+		b.GetCommon().Concurrency *= k
+	}
+	limitNotes := checkSystemLimits(ctx, b.GetCommon().Concurrency)
+	b.GetCommon().Journal = journal(ctx)
 	if ab != nil {
 		b.GetCommon().ClientIdx = ab.clientIdx
 		return runClientBenchmark(ctx, b, ab)
 	}
-	if done, err := runServerBenchmark(ctx, b); done || err != nil {
+	stopClients := startLocalClients(ctx)
+	defer stopClients()
+	notes := append(append(limitNotes, modeNotes...), checkCapabilities(ctx, b)...)
+	checkBucketSafety(ctx, b)
+	checkBudget(ctx, b)
+	// Arguments have been checked, later fatal errors are not configuration errors.
+	setFatalExitCode(exitError)
+	hk := newHooks(ctx, b)
+	hk.runPre()
+	if done, err := runServerBenchmark(ctx, b, hk, notes); done || err != nil {
 		fatalIf(probe.NewError(err), "Error running remote benchmark")
 		return nil
 	}
@@ -110,6 +303,7 @@ func runBench(ctx *cli.Context, b bench.Benchmark) error {
 	monitor.SetLnLoggers(printInfo, printError)
 	defer monitor.Done()
 
+	servers := probeServers(ctx)
 	monitor.InfoLn("Preparing server.")
 	pgDone := make(chan struct{})
 	c := b.GetCommon()
@@ -160,7 +354,32 @@ func runBench(ctx *cli.Context, b bench.Benchmark) error {
 		close(pgDone)
 	}
 
-	err := b.Prepare(context.Background())
+	fileName := ctx.String("benchdata")
+	cID := pRandASCII(4)
+	if fileName == "" {
+		fileName = fmt.Sprintf("%s-%s-%s-%s", appName, ctx.Command.Name, time.Now().Format("2006-01-02[150405]"), cID)
+	}
+	// Operations are written as they are recorded, including those of preparing.
+	out, err := createBenchData(ctx, fileName)
+	fatalIf(probe.NewError(err), "Unable to create benchmark data file")
+	stream, err := newBenchDataStream(ctx, out, cID)
+	fatalIf(probe.NewError(err), "Unable to write benchmark output")
+	c.Output = stream.Add
+
+	if ctx.String("manifest") != "" {
+		c.Manifest = &bench.Manifest{}
+	}
+	if fn := ctx.String("registry"); fn != "" {
+		var err error
+		c.Registry, err = openRegistry(fn, ctx.String(benchDataKeyFlag.Name))
+		fatalIf(probe.NewError(err), "Unable to open registry")
+	}
+	if ctx.Duration("live") > 0 {
+		c.Live = bench.NewLiveStats()
+		c.Live.Percentiles = analysisPercentiles(ctx)
+	}
+	hk.startStage(stagePrepare)
+	err = b.Prepare(context.Background())
 	fatalIf(probe.NewError(err), "Error preparing server")
 	if c.PrepareProgress != nil {
 		close(c.PrepareProgress)
@@ -171,6 +390,14 @@ func runBench(ctx *cli.Context, b bench.Benchmark) error {
 		err := ap.AfterPrepare(context.Background())
 		fatalIf(probe.NewError(err), "Error preparing server")
 	}
+	manifestLen := 0
+	if c.Manifest != nil {
+		fatalIf(probe.NewError(writeManifest(ctx.String("manifest"), ctx.String(benchDataKeyFlag.Name), c.Manifest)), "Unable to write manifest")
+		manifestLen = c.Manifest.Len()
+		monitor.InfoLn("Manifest written to ", ctx.String("manifest"))
+	}
+	hk.endStage(stagePrepare)
+	hk.startStage(stageBenchmark)
 
 	// Start after waiting a second or until we reached the start time.
 	tStart := time.Now().Add(time.Second * 3)
@@ -184,10 +411,29 @@ func runBench(ctx *cli.Context, b bench.Benchmark) error {
 			tStart = startTime
 		}
 	}
+	if ctx.Bool("multi.sync") {
+		tStart = waitMultiStart()
+	}
 
 	benchDur := ctx.Duration("duration")
 	ctx2, cancel := context.WithDeadline(context.Background(), tStart.Add(benchDur))
 	defer cancel()
+	// Operations are limited after preparing.
+	c.Limiter = newRateLimiter(ctx)
+	if c.Limiter != nil {
+		monitor.InfoLn("Rate limit: ", c.Limiter)
+	}
+	if ctx.String("serve") != "" {
+		p := bench.NewPauser()
+		c.Client = p.Pausable(ctx2, c.Client)
+		if c.MetaClient != nil {
+			c.MetaClient = p.Pausable(ctx2, c.MetaClient)
+		}
+		if c.KeyClient != nil {
+			c.KeyClient = p.PausableKey(ctx2, c.KeyClient)
+		}
+		monitor.SetPauser(p)
+	}
 	start := make(chan struct{})
 	go func() {
 		<-time.After(time.Until(tStart))
@@ -195,14 +441,11 @@ func runBench(ctx *cli.Context, b bench.Benchmark) error {
 		close(start)
 	}()
 
-	fileName := ctx.String("benchdata")
-	cID := pRandASCII(4)
-	if fileName == "" {
-		fileName = fmt.Sprintf("%s-%s-%s-%s", appName, ctx.Command.Name, time.Now().Format("2006-01-02[150405]"), cID)
-	}
-
 	prof, err := startProfiling(ctx2, ctx)
 	fatalIf(probe.NewError(err), "Unable to start profile.")
+	startHealing(ctx2, ctx, tStart, monitor)
+	startRollingRestart(ctx2, ctx, tStart, monitor)
+	startLiveStats(ctx2, c.Live, ctx.Duration("live"), tStart, monitor)
 	monitor.InfoLn("Starting benchmark in ", time.Until(tStart).Round(time.Second), "...")
 	pgDone = make(chan struct{})
 	if !globalQuiet && !globalJSON {
@@ -233,43 +476,110 @@ func runBench(ctx *cli.Context, b bench.Benchmark) error {
 	} else {
 		close(pgDone)
 	}
-	ops, _ := b.Start(ctx2, start)
+	limits := measureClientLimits(c)
+	resetBackoffStats()
+	cpuBefore, cpuLimited := readCgroupCPUStat(cgroupRoot)
+	// An interrupt aborts the benchmark, canceling operations in progress.
+	// The operations so far are saved and analyzed.
+	abort, abortCancel := context.WithCancel(context.Background())
+	c.Abort = abort
+	stopInterrupt := abortOnInterrupt(func() {
+		abortCancel()
+		cancel()
+	})
+	// Operations have been written to the benchmark data.
+	b.Start(ctx2, start)
+	stopInterrupt()
+	abortCancel()
+	registry := c.Registry != nil
+	fatalIf(probe.NewError(closeManifest(ctx, c, manifestLen)), "Unable to write manifest")
+	if registry {
+		monitor.InfoLn("Registry written to ", ctx.String("registry"))
+	}
+	limitWarnings := limits.Warnings(c.Concurrency, time.Since(tStart))
+	if cpuAfter, _ := readCgroupCPUStat(cgroupRoot); cpuLimited {
+		if w := containerThrottleWarning(cpuBefore, cpuAfter); w != "" {
+			limitWarnings = append(limitWarnings, w)
+		}
+	}
+	notes = append(notes, limitWarnings...)
+	sessionNote := ""
+	if st, ok := sessionStats(); ok {
+		sessionNote = st.String()
+		notes = append(notes, sessionNote)
+	}
+	backoffNote := ""
+	if st, ok := backoffStats(); ok {
+		backoffNote = st.String()
+		notes = append(notes, backoffNote)
+	}
 	cancel()
 	<-pgDone
+	hk.endStage(stageBenchmark)
 
 	// Previous context is canceled, create a new...
 	monitor.InfoLn("Saving benchmark data...")
 	ctx2 = context.Background()
-	ops.SortByStartTime()
-	ops.SetClientID(cID)
 	prof.stop(ctx2, ctx, fileName+".profiles.zip")
 
-	f, err := os.Create(fileName + ".csv.zst")
-	if err != nil {
-		monitor.Errorln("Unable to write benchmark data:", err)
-	} else {
-		func() {
-			defer f.Close()
-			enc, err := zstd.NewWriter(f, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
-			fatalIf(probe.NewError(err), "Unable to compress benchmark output")
+	err = stream.Close(monitor.Annotations(), benchProvenance(ctx, servers, notes))
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	fatalIf(probe.NewError(err), "Unable to write benchmark output")
+	monitor.InfoLn(fmt.Sprintf("Benchmark data written to %q\n", out.Name))
 
-			defer enc.Close()
-			err = ops.CSV(enc, commandLine(ctx))
-			fatalIf(probe.NewError(err), "Unable to write benchmark output")
+	// The operations are read back for the analysis, so they are not kept in memory while running.
+	ops, _, err := readBenchData(ctx, out.Name)
+	fatalIf(probe.NewError(err), "Unable to read benchmark data")
 
-			monitor.InfoLn(fmt.Sprintf("Benchmark data written to %q\n", fileName+".csv.zst"))
-		}()
-	}
 	monitor.OperationsReady(ops, fileName, commandLine(ctx))
-	printAnalysis(ctx, ops)
+	printAnalysis(ctx, ops, ctx.Command.Name)
+	printClientLimits(limitWarnings)
+	if sessionNote != "" && !globalJSON {
+		console.Println("\n" + sessionNote)
+	}
+	if backoffNote != "" && !globalJSON {
+		console.Println("\n" + backoffNote)
+	}
 	if !ctx.Bool("keep-data") && !ctx.Bool("noclear") {
 		monitor.InfoLn("Starting cleanup...")
+		hk.startStage(stageCleanup)
 		b.Cleanup(context.Background())
+		hk.endStage(stageCleanup)
 	}
+	fatalIf(probe.NewError(journalErr()), "Unable to write cleanup journal")
 	monitor.InfoLn("Cleanup Done.")
+	hk.runPost(out.Name)
 	return nil
 }
 
+// abortOnInterrupt calls abort when the process is interrupted, until stop is called.
+// A second interrupt exits immediately.
+func abortOnInterrupt(abort func()) (stop func()) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sig:
+		case <-done:
+			return
+		}
+		printInfo("Interrupted. Aborting benchmark, interrupt again to exit.")
+		abort()
+		select {
+		case <-sig:
+			os.Exit(exitError)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
+
 var (
 	activeBenchmarkMu sync.Mutex
 	activeBenchmark   *clientBenchmark
@@ -284,6 +594,8 @@ type clientBenchmark struct {
 	stage     benchmarkStage
 	info      map[benchmarkStage]stageInfo
 	clientIdx int
+	// prepared contains the objects uploaded while preparing.
+	prepared *bench.PrepareStats
 }
 
 type stageInfo struct {
@@ -291,11 +603,14 @@ type stageInfo struct {
 	start          chan struct{}
 	done           chan struct{}
 	custom         map[string]string
+	// progress of the stage from 0 to 1, if reported.
+	progress float64
 }
 
 func (c *clientBenchmark) init(ctx context.Context) {
 	c.results = nil
 	c.err = nil
+	c.prepared = nil
 	c.stage = stageNotStarted
 	c.info = make(map[benchmarkStage]stageInfo, len(benchmarkStages))
 	c.ctx, c.cancel = context.WithCancel(ctx)
@@ -327,9 +642,9 @@ func (c *clientBenchmark) waitForStage(s benchmarkStage) error {
 
 // waitForStage waits for the stage to be ready and updates the stage when it is
 func (c *clientBenchmark) stageDone(s benchmarkStage, err error, custom map[string]string) {
-	console.Infoln(s, "done...")
+	logInfo("Stage done", "stage", s)
 	if err != nil {
-		console.Errorln(err.Error())
+		logError("Stage failed", "stage", s, "err", err)
 	}
 	c.Lock()
 	info := c.info[s]
@@ -344,6 +659,15 @@ func (c *clientBenchmark) stageDone(s benchmarkStage, err error, custom map[stri
 	c.Unlock()
 }
 
+// setProgress updates the progress of the stage.
+func (c *clientBenchmark) setProgress(s benchmarkStage, progress float64) {
+	c.Lock()
+	info := c.info[s]
+	info.progress = progress
+	c.info[s] = info
+	c.Unlock()
+}
+
 func (c *clientBenchmark) setStage(s benchmarkStage) {
 	c.Lock()
 	c.stage = s
@@ -374,13 +698,54 @@ func runClientBenchmark(ctx *cli.Context, b bench.Benchmark, cb *clientBenchmark
 	start := cb.info[stageBenchmark].start
 	ctx2, cancel := context.WithCancel(cb.ctx)
 	defer cancel()
+	// Operations in progress are canceled when the server aborts the benchmark.
+	common.Abort = cb.ctx
 	cb.Unlock()
+	if ctx.String("manifest") != "" {
+		common.Manifest = &bench.Manifest{}
+	}
+	if fn := ctx.String("registry"); fn != "" {
+		common.Registry, err = openRegistry(fn, ctx.String(benchDataKeyFlag.Name))
+		if err != nil {
+			cb.stageDone(stagePrepare, err, common.Custom)
+			return err
+		}
+	}
+	// Progress is reported to the server with the stage status.
+	common.PrepareProgress = make(chan float64, 1)
+	pgDone := make(chan struct{})
+	go func() {
+		defer close(pgDone)
+		for pct := range common.PrepareProgress {
+			cb.setProgress(stagePrepare, pct)
+		}
+	}()
+	prepStart := time.Now()
 	err = b.Prepare(ctx2)
+	close(common.PrepareProgress)
+	<-pgDone
+	common.PrepareProgress = nil
+	prepared := common.PrepareStats()
+	prepared.Duration = time.Since(prepStart)
+	cb.Lock()
+	cb.prepared = &prepared
+	cb.Unlock()
+	manifestLen := 0
+	if err == nil && common.Manifest != nil {
+		err = writeManifest(ctx.String("manifest"), ctx.String(benchDataKeyFlag.Name), common.Manifest)
+		manifestLen = common.Manifest.Len()
+	}
+	if err != nil && common.Registry != nil {
+		common.Registry.Close()
+		common.Registry = nil
+	}
 
 	cb.stageDone(stagePrepare, err, common.Custom)
 	if err != nil {
 		return err
 	}
+	// Operations are limited after preparing.
+	common.Limiter = newRateLimiter(ctx)
 
 	// Start after waiting a second or until we reached the start time.
 	benchDur := ctx.Duration("duration")
@@ -412,7 +777,21 @@ func runClientBenchmark(ctx *cli.Context, b bench.Benchmark, cb *clientBenchmark
 		fileName = fmt.Sprintf("%s-%s-%s-%s", appName, ctx.Command.Name, time.Now().Format("2006-01-02[150405]"), cID)
 	}
 
+	out, err := createBenchData(ctx, fileName)
+	if err != nil {
+		console.Error("Unable to write benchmark data:", err)
+	}
+
+	limits := measureClientLimits(common)
+	resetBackoffStats()
 	ops, err := b.Start(ctx2, start)
+	if merr := closeManifest(ctx, common, manifestLen); err == nil {
+		err = merr
+	}
+	returnClientLimits(common, limits.Warnings(common.Concurrency, benchDur))
+	returnSessionStats(common)
+	returnBackoffStats(common)
+	ops.PipelineThreads(ctx.Int("pipeline"))
 	cb.Lock()
 	cb.results = ops
 	cb.Unlock()
@@ -423,21 +802,12 @@ func runClientBenchmark(ctx *cli.Context, b bench.Benchmark, cb *clientBenchmark
 	ops.SetClientID(cID)
 	ops.SortByStartTime()
 
-	f, err := os.Create(fileName + ".csv.zst")
-	if err != nil {
-		console.Error("Unable to write benchmark data:", err)
-	} else {
-		func() {
-			defer f.Close()
-			enc, err := zstd.NewWriter(f, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
-			fatalIf(probe.NewError(err), "Unable to compress benchmark output")
-
-			defer enc.Close()
-			err = ops.CSV(enc, commandLine(ctx))
-			fatalIf(probe.NewError(err), "Unable to write benchmark output")
-
-			console.Infof("Benchmark data written to %q\n", fileName+".csv.zst")
-		}()
+	if out != nil {
+		err = ops.CSV(out, benchProvenance(ctx, nil, nil))
+		fatalIf(probe.NewError(err), "Unable to write benchmark output")
+		err = out.Close()
+		fatalIf(probe.NewError(err), "Unable to write benchmark output")
+		console.Infof("Benchmark data written to %q\n", out.Name)
 	}
 
 	err = cb.waitForStage(stageCleanup)
@@ -453,6 +823,62 @@ func runClientBenchmark(ctx *cli.Context, b bench.Benchmark, cb *clientBenchmark
 	return nil
 }
 
+// writeManifest writes the manifest to the file, encrypted if a key is given.
+// JSON is written if the file name ends with .json, otherwise CSV.
+func writeManifest(fn, key string, m *bench.Manifest) error {
+	f, err := createOutputFile(fn, key)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(strings.ToLower(fn), ".json") {
+		err = m.WriteJSON(f)
+	} else {
+		err = m.WriteCSV(f)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// closeManifest closes the registry and writes the manifest again
+// if objects were uploaded after it was written when preparing had finished.
+func closeManifest(ctx *cli.Context, c *bench.Common, written int) error {
+	var err error
+	if c.Registry != nil {
+		err = c.Registry.Close()
+		c.Registry = nil
+	}
+	if err == nil && c.Manifest != nil && c.Manifest.Len() > written {
+		err = writeManifest(ctx.String("manifest"), ctx.String(benchDataKeyFlag.Name), c.Manifest)
+	}
+	return err
+}
+
+// openRegistry opens the registry file for appending, encrypted if a key is given.
+// Encrypted entries are written as separate frames, so they survive if warp is stopped.
+func openRegistry(fn, key string) (*bench.Registry, error) {
+	if key == "" {
+		return bench.OpenRegistry(fn)
+	}
+	f, existing, err := appendFramedFile(fn, key)
+	if err != nil {
+		return nil, err
+	}
+	return bench.NewRegistry(f, !existing)
+}
+
+// newRateLimiter returns the rate limiter specified by the rate flags
+// or nil if operations are not rate limited.
+func newRateLimiter(ctx *cli.Context) *bench.RateLimiter {
+	rate := ctx.Float64("rate")
+	if rate <= 0 {
+		return nil
+	}
+	return bench.NewRateLimiter(rate, ctx.Int("rate.burst"), ctx.String("rate.arrival"))
+}
+
 type runningProfiles struct {
 	client *madmin.AdminClient
 }
@@ -527,12 +953,81 @@ func checkBenchmark(ctx *cli.Context) {
 			fatalIf(errDummy(), "Profiler type %s unrecognized. Possible values are: %v.", profilerType, profilerTypes)
 		}
 	}
+	if ctx.Duration("heal.after") < 0 {
+		fatalIf(errDummy(), "heal.after cannot be negative")
+	}
+	switch ctx.String("heal.scan") {
+	case "normal", "deep":
+	default:
+		fatalIf(errDummy(), "Unknown heal scan mode: %q", ctx.String("heal.scan"))
+	}
+	switch ctx.String("host.hash") {
+	case "key", "prefix":
+	default:
+		fatalIf(errDummy(), "Unknown host.hash value: %q", ctx.String("host.hash"))
+	}
+	switch ctx.String("capabilities") {
+	case capModeFail, capModeAuto, capModeOff:
+	default:
+		fatalIf(errDummy(), "Unknown capabilities mode: %q", ctx.String("capabilities"))
+	}
+	switch ctx.String("preflight") {
+	case preflightWarn, preflightFail, preflightOff:
+	default:
+		fatalIf(errDummy(), "Unknown preflight mode: %q", ctx.String("preflight"))
+	}
+	if ctx.Duration("live") < 0 {
+		fatalIf(errDummy(), "live cannot be negative")
+	}
+	if ctx.Float64("rate") < 0 {
+		fatalIf(errDummy(), "rate cannot be negative")
+	}
+	if ctx.Int("rate.burst") < 1 {
+		fatalIf(errDummy(), "rate.burst must be at least 1")
+	}
+	switch ctx.String("rate.arrival") {
+	case bench.ArrivalFixed, bench.ArrivalPoisson:
+	default:
+		fatalIf(errDummy(), "Unknown rate arrival: %q", ctx.String("rate.arrival"))
+	}
+	if ctx.Duration("restart.after") < 0 || ctx.Duration("restart.interval") < 0 {
+		fatalIf(errDummy(), "restart.after and restart.interval cannot be negative")
+	}
 	if st := ctx.String("syncstart"); st != "" {
 		t := parseLocalTime(st)
 		if t.Before(time.Now()) {
 			fatalIf(errDummy(), "syncstart is in the past: %v", t)
 		}
 	}
+	if n := ctx.Int("procs"); n < 1 {
+		fatalIf(errDummy(), "procs must be at least 1")
+	} else if n > 1 && ctx.String("warp-client") != "" {
+		fatalIf(errDummy(), "procs cannot be used with warp-client")
+	}
+	if ctx.Duration("live") > 0 {
+		// Clients do not report live statistics to the server.
+		switch {
+		case ctx.String("warp-client") != "":
+			fatalIf(errDummy(), "live cannot be used with warp-client")
+		case ctx.Int("procs") > 1:
+			fatalIf(errDummy(), "live cannot be used with procs")
+		}
+	}
+	if ctx.Bool("debug.deterministic") {
+		switch {
+		case ctx.String("warp-client") != "":
+			fatalIf(errDummy(), "debug.deterministic cannot be used with warp-client")
+		case ctx.Int("procs") > 1:
+			fatalIf(errDummy(), "debug.deterministic cannot be used with procs")
+		case ctx.Int("pipeline") > 1:
+			fatalIf(errDummy(), "debug.deterministic cannot be used with pipeline")
+		}
+	}
+	if k := ctx.Int("pipeline"); k < 1 {
+		fatalIf(errDummy(), "pipeline must be at least 1")
+	} else if getConcurrency(ctx)*k > math.MaxUint16+1 {
+		fatalIf(errDummy(), "concurrent * pipeline cannot be more than %d", math.MaxUint16+1)
+	}
 	if ctx.Bool("autoterm") {
 		// TODO: autoterm cannot be used when in client/server mode
 		if ctx.Duration("autoterm.dur") <= 0 {
@@ -22,16 +22,15 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"os"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
-	"github.com/klauspost/compress/zstd"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
 	"github.com/minio/warp/api"
 	"github.com/minio/warp/pkg/bench"
 )
@@ -87,7 +86,8 @@ type serverRequest struct {
 
 // runServerBenchmark will run a benchmark server if requested.
 // Returns a bool whether clients were specified.
-func runServerBenchmark(ctx *cli.Context, b bench.Benchmark) (bool, error) {
+// Notes are stored with the benchmark data.
+func runServerBenchmark(ctx *cli.Context, b bench.Benchmark, hk *hooks, notes []string) (bool, error) {
 	if ctx.String("warp-client") == "" {
 		return false, nil
 	}
@@ -111,11 +111,25 @@ func runServerBenchmark(ctx *cli.Context, b bench.Benchmark) (bool, error) {
 	excludeFlags := map[string]struct{}{
 		"warp-client":        {},
 		"warp-client-server": {},
+		"procs":              {},
 		"serverprof":         {},
+		"heal.after":         {},
+		"heal.scan":          {},
+		"restart.hook":       {},
+		"restart.hosts":      {},
+		"restart.after":      {},
+		"restart.interval":   {},
+		"hook.pre":           {},
+		"hook.stage":         {},
+		"hook.post":          {},
+		"hook.timeout":       {},
 		"autocompletion":     {},
 		"help":               {},
 		"syncstart":          {},
+		"multi.sync":         {},
 		"analyze.out":        {},
+		"benchdata.key":      {},
+		"redact":             {},
 	}
 	req := serverRequest{
 		Operation: serverReqBenchmark,
@@ -136,6 +150,12 @@ func runServerBenchmark(ctx *cli.Context, b bench.Benchmark) (bool, error) {
 			}
 		}
 	}
+	// Clients expand templates themselves.
+	for k, v := range flagTemplates {
+		if _, ok := req.Benchmark.Flags[k]; ok {
+			req.Benchmark.Flags[k] = v
+		}
+	}
 	for k, v := range b.GetCommon().ExtraFlags {
 		req.Benchmark.Flags[k] = v
 	}
@@ -151,8 +171,10 @@ func runServerBenchmark(ctx *cli.Context, b bench.Benchmark) (bool, error) {
 		// Assume ok.
 	}
 	infoLn("All clients connected...")
+	servers := probeServers(ctx)
 
 	common := b.GetCommon()
+	hk.startStage(stagePrepare)
 	_ = conns.startStageAll(stagePrepare, time.Now().Add(time.Second), true)
 	err := conns.waitForStage(stagePrepare, true, common)
 	if err != nil {
@@ -164,6 +186,12 @@ func runServerBenchmark(ctx *cli.Context, b bench.Benchmark) (bool, error) {
 	}
 
 	infoLn("All clients prepared...")
+	for _, note := range conns.prepareNotes(ctx) {
+		infoLn(note)
+		notes = append(notes, note)
+	}
+	hk.endStage(stagePrepare)
+	hk.startStage(stageBenchmark)
 
 	const benchmarkWait = 3 * time.Second
 
@@ -171,7 +199,14 @@ func runServerBenchmark(ctx *cli.Context, b bench.Benchmark) (bool, error) {
 	if err != nil {
 		return true, err
 	}
-	err = conns.startStageAll(stageBenchmark, time.Now().Add(benchmarkWait), false)
+	tStart := time.Now().Add(benchmarkWait)
+	if ctx.Bool("multi.sync") {
+		tStart = waitMultiStart()
+	}
+	hookCtx, hookCancel := context.WithCancel(context.Background())
+	startHealing(hookCtx, ctx, tStart, monitor)
+	startRollingRestart(hookCtx, ctx, tStart, monitor)
+	err = conns.startStageAll(stageBenchmark, tStart, false)
 	if err != nil {
 		errorLn("Failed to start all clients", err)
 	}
@@ -180,6 +215,20 @@ func runServerBenchmark(ctx *cli.Context, b bench.Benchmark) (bool, error) {
 	if err != nil {
 		errorLn("Failed to keep connection to all clients", err)
 	}
+	hookCancel()
+	hk.endStage(stageBenchmark)
+	limitWarnings := returnedClientLimits(common.Custom)
+	notes = append(notes, limitWarnings...)
+	sessionNote := ""
+	if st, ok := returnedSessionStats(common.Custom); ok {
+		sessionNote = st.String()
+		notes = append(notes, sessionNote)
+	}
+	backoffNote := ""
+	if st, ok := returnedBackoffStats(common.Custom); ok {
+		backoffNote = st.String()
+		notes = append(notes, backoffNote)
+	}
 
 	fileName := ctx.String("benchdata")
 	if fileName == "" {
@@ -201,26 +250,30 @@ func runServerBenchmark(ctx *cli.Context, b bench.Benchmark) (bool, error) {
 		}
 	}
 
+	allOps = append(allOps, monitor.Annotations()...)
 	allOps.SortByStartTime()
-	f, err := os.Create(fileName + ".csv.zst")
+	redactOps(ctx, allOps)
+	out, err := createBenchData(ctx, fileName)
 	if err != nil {
 		errorLn("Unable to write benchmark data:", err)
 	} else {
-		func() {
-			defer f.Close()
-			enc, err := zstd.NewWriter(f, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
-			fatalIf(probe.NewError(err), "Unable to compress benchmark output")
-
-			defer enc.Close()
-			err = allOps.CSV(enc, commandLine(ctx))
-			fatalIf(probe.NewError(err), "Unable to write benchmark output")
-
-			infoLn(fmt.Sprintf("Benchmark data written to %q\n", fileName+".csv.zst"))
-		}()
+		err = allOps.CSV(out, benchProvenance(ctx, servers, notes))
+		fatalIf(probe.NewError(err), "Unable to write benchmark output")
+		err = out.Close()
+		fatalIf(probe.NewError(err), "Unable to write benchmark output")
+		infoLn(fmt.Sprintf("Benchmark data written to %q\n", out.Name))
 	}
 	monitor.OperationsReady(allOps, fileName, commandLine(ctx))
-	printAnalysis(ctx, allOps)
+	printAnalysis(ctx, allOps, ctx.Command.Name)
+	printClientLimits(limitWarnings)
+	if sessionNote != "" && !globalJSON {
+		console.Println("\n" + sessionNote)
+	}
+	if backoffNote != "" && !globalJSON {
+		console.Println("\n" + backoffNote)
+	}
 
+	hk.startStage(stageCleanup)
 	err = conns.startStageAll(stageCleanup, time.Now(), false)
 	if err != nil {
 		errorLn("Failed to clean up all clients", err)
@@ -229,7 +282,13 @@ func runServerBenchmark(ctx *cli.Context, b bench.Benchmark) (bool, error) {
 	if err != nil {
 		errorLn("Failed to keep connection to all clients", err)
 	}
+	hk.endStage(stageCleanup)
 	infoLn("Cleanup done.\n")
+	benchData := ""
+	if out != nil {
+		benchData = out.Name
+	}
+	hk.runPost(benchData)
 
 	return true, nil
 }
@@ -241,6 +300,11 @@ type connections struct {
 	si    serverInfo
 	info  func(data ...interface{})
 	errLn func(data ...interface{})
+
+	// progress of the current stage and prepare stats of each client.
+	mu       sync.Mutex
+	progress []float64
+	prepared []*bench.PrepareStats
 }
 
 // newConnections creates connections (but does not connect) to clients.
@@ -253,6 +317,8 @@ func newConnections(hosts []string) *connections {
 	}
 	c.hosts = hosts
 	c.ws = make([]*websocket.Conn, len(hosts))
+	c.progress = make([]float64, len(hosts))
+	c.prepared = make([]*bench.PrepareStats, len(hosts))
 	return &c
 }
 
@@ -318,6 +384,7 @@ func (c *connections) roundTrip(i int, req serverRequest) (*clientReply, error)
 			}
 			return nil, err
 		}
+		printClientLogs(c.hostName(i), resp.Logs, resp.LogsDropped)
 		return &resp, nil
 	}
 }
@@ -470,6 +537,16 @@ func (c *connections) downloadOps() []bench.Operations {
 func (c *connections) waitForStage(stage benchmarkStage, failOnErr bool, common *bench.Common) error {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
+	c.mu.Lock()
+	for i := range c.progress {
+		c.progress[i] = 0
+	}
+	c.mu.Unlock()
+	if stage == stagePrepare {
+		done := make(chan struct{})
+		defer close(done)
+		go c.printProgress(stage, done)
+	}
 	for i, conn := range c.ws {
 		if conn == nil {
 			// log?
@@ -500,6 +577,12 @@ func (c *connections) waitForStage(stage benchmarkStage, failOnErr bool, common
 					c.errorF("Client %v returned error: %v\n", c.hostName(i), resp.Err)
 					return
 				}
+				c.mu.Lock()
+				c.progress[i] = resp.StageInfo.Progress
+				if resp.StageInfo.Prepare != nil {
+					c.prepared[i] = resp.StageInfo.Prepare
+				}
+				c.mu.Unlock()
 				if resp.StageInfo.Finished {
 					// Merge custom
 					if len(resp.StageInfo.Custom) > 0 {
@@ -523,6 +606,61 @@ func (c *connections) waitForStage(stage benchmarkStage, failOnErr bool, common
 	return nil
 }
 
+// printProgress prints the progress of each client every few seconds until done is closed.
+// Nothing is printed until a client reports progress.
+func (c *connections) printProgress(stage benchmarkStage, done <-chan struct{}) {
+	t := time.NewTicker(5 * time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+		}
+		c.mu.Lock()
+		reported := false
+		parts := make([]string, len(c.hosts))
+		for i, p := range c.progress {
+			reported = reported || p > 0
+			parts[i] = fmt.Sprintf("%s %.0f%%", c.hosts[i], 100*p)
+		}
+		c.mu.Unlock()
+		if reported {
+			c.info("Progress of ", stage, ": ", strings.Join(parts, ", "))
+		}
+	}
+}
+
+// prepareNotes returns the objects uploaded by each client while preparing,
+// and whether the slowest client delayed the start of the benchmark noticeably.
+func (c *connections) prepareNotes(ctx *cli.Context) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var notes []string
+	fastest, slowest := -1, -1
+	for i, p := range c.prepared {
+		if p == nil {
+			continue
+		}
+		notes = append(notes, fmt.Sprintf("Prepare on %s: %s.", redactHost(ctx, c.hosts[i]), p))
+		if fastest < 0 || p.Duration < c.prepared[fastest].Duration {
+			fastest = i
+		}
+		if slowest < 0 || p.Duration > c.prepared[slowest].Duration {
+			slowest = i
+		}
+	}
+	if fastest < 0 || fastest == slowest {
+		return notes
+	}
+	fast, slow := c.prepared[fastest].Duration, c.prepared[slowest].Duration
+	if slow-fast >= time.Second && float64(slow) > 1.25*float64(fast) {
+		notes = append(notes, fmt.Sprintf("Prepare imbalance: %s took %v longer than %s, delaying the start of the benchmark.",
+			redactHost(ctx, c.hosts[slowest]), (slow-fast).Round(time.Millisecond), redactHost(ctx, c.hosts[fastest])))
+	}
+	return notes
+}
+
 // flagToJSON converts a flag to a representation that can be reversed into the flag.
 func flagToJSON(ctx *cli.Context, flag cli.Flag) (string, error) {
 	switch flag.(type) {
@@ -0,0 +1,150 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// budgetEstimate is an estimate of the resources used by a benchmark.
+// It is not an upper bound, since requests sent more than once are not counted.
+type budgetEstimate struct {
+	requests int64
+	bytes    int64
+	// bounded is false if the number of requests while benchmarking is unlimited.
+	bounded bool
+}
+
+// cost returns the estimated cost using the prices given.
+func (e budgetEstimate) cost(perKiloRequest, perGB float64) float64 {
+	return float64(e.requests)/1000*perKiloRequest + float64(e.bytes)/1e9*perGB
+}
+
+// prepareObjects returns the number of objects uploaded by each instance while preparing.
+func prepareObjects(b bench.Benchmark) int64 {
+	switch b := b.(type) {
+	case *bench.Get:
+		return int64(b.CreateObjects * b.Versions)
+	case *bench.Stat:
+		return int64(b.CreateObjects * b.Versions)
+	case *bench.Retention:
+		return int64(b.CreateObjects * b.Versions)
+	case *bench.CompGet:
+		return int64(b.CreateObjects * 2)
+	case *bench.Multipart:
+		return int64(b.CreateParts)
+	case *bench.List:
+		return int64(b.CreateObjects)
+	case *bench.Delete:
+		return int64(b.CreateObjects)
+	case *bench.Select:
+		return int64(b.CreateObjects)
+	case *bench.Mixed:
+		return int64(b.CreateObjects)
+	case *bench.Versioned:
+		return int64(b.CreateObjects)
+	case *bench.ReadModifyWrite:
+		return int64(b.CreateObjects)
+	case *bench.Mirror:
+		return int64(b.CreateObjects)
+	case *bench.Chain:
+		return int64(b.CreateObjects)
+	case *bench.FirstByte:
+		return int64(b.CreateObjects)
+	}
+	return 0
+}
+
+// budgetObjectSize returns the largest object size of the benchmark.
+func budgetObjectSize(ctx *cli.Context, b bench.Benchmark) int64 {
+	if ctx.String("obj.dist") != "" {
+		var size int64
+		for _, s := range parseDisrtibutionSizes(ctx) {
+			if s > size {
+				size = s
+			}
+		}
+		return size
+	}
+	field := "obj.size"
+	if _, ok := b.(*bench.Multipart); ok {
+		field = "part.size"
+	}
+	if ctx.String(field) == "" {
+		return 0
+	}
+	size, err := toSize(ctx.String(field))
+	fatalIf(probe.NewError(err), "Invalid %s specified", field)
+	return int64(size)
+}
+
+// estimateBudget returns an estimate of the requests and data of the benchmark,
+// assuming every operation sends a single request that transfers the largest object.
+// Retried requests, multipart uploads and operations sending several requests,
+// like listings of many pages, are not counted.
+func estimateBudget(ctx *cli.Context, b bench.Benchmark) budgetEstimate {
+	instances := int64(1)
+	if ctx.String("warp-client") != "" {
+		instances = int64(len(parseHosts(ctx.String("warp-client"))))
+	}
+	size := budgetObjectSize(ctx, b)
+	e := budgetEstimate{requests: prepareObjects(b)}
+	if rate := ctx.Float64("rate"); rate > 0 {
+		e.requests += int64(rate*ctx.Duration("duration").Seconds()) + int64(ctx.Int("rate.burst"))
+		e.bounded = true
+	}
+	e.requests *= instances
+	e.bytes = e.requests * size
+	return e
+}
+
+// checkBudget stops the benchmark if it may exceed the budget given.
+func checkBudget(ctx *cli.Context, b bench.Benchmark) {
+	var maxBytes uint64
+	if ctx.String("budget.bytes") != "" {
+		var err error
+		maxBytes, err = toSize(ctx.String("budget.bytes"))
+		fatalIf(probe.NewError(err), "Invalid budget.bytes specified")
+	}
+	maxRequests := ctx.Int64("budget.requests")
+	maxCost := ctx.Float64("budget.cost")
+	if maxBytes == 0 && maxRequests == 0 && maxCost == 0 {
+		return
+	}
+	e := estimateBudget(ctx, b)
+	if !e.bounded {
+		fatalIf(errDummy(), "The number of requests of an unlimited benchmark cannot be estimated. Use --rate to limit it to the budget")
+	}
+	cost := e.cost(ctx.Float64("budget.price.requests"), ctx.Float64("budget.price.gb"))
+	estimate := fmt.Sprintf("about %d requests, %s of data and a cost of %.2f", e.requests, humanize.IBytes(uint64(e.bytes)), cost)
+	switch {
+	case maxRequests > 0 && e.requests > maxRequests:
+		fatalIf(errDummy(), "Benchmark is estimated to exceed request budget of %d: %s", maxRequests, estimate)
+	case maxBytes > 0 && uint64(e.bytes) > maxBytes:
+		fatalIf(errDummy(), "Benchmark is estimated to exceed data budget of %s: %s", humanize.IBytes(maxBytes), estimate)
+	case maxCost > 0 && cost > maxCost:
+		fatalIf(errDummy(), "Benchmark is estimated to exceed cost budget of %.2f: %s", maxCost, estimate)
+	}
+	console.Infof("Estimated benchmark budget: %s.\n", estimate)
+}
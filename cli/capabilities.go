@@ -0,0 +1,238 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// Capability probing modes.
+const (
+	capModeFail = "fail"
+	capModeAuto = "auto"
+	capModeOff  = "off"
+)
+
+// Server capabilities used by benchmarks.
+const (
+	capVersioning = "versioning"
+	capObjectLock = "object-lock"
+	capSelect     = "select"
+	capChecksum   = "checksum"
+)
+
+// capabilityProbeKey is the object requested when probing.
+// It is not expected to exist.
+const capabilityProbeKey = "warp-capability-probe"
+
+// capabilityUse is a server capability used by a benchmark.
+type capabilityUse struct {
+	name string
+	// disable stops the benchmark from using the capability.
+	// If nil the capability is required.
+	disable func()
+}
+
+// benchCapabilities returns the capabilities used by the benchmark.
+func benchCapabilities(b bench.Benchmark) []capabilityUse {
+	c := b.GetCommon()
+	disableVersions := func(versions *int) func() {
+		return func() {
+			*versions = 1
+			setExtraFlag(c, "versions", "1")
+		}
+	}
+	var res []capabilityUse
+	switch b := b.(type) {
+	case *bench.Get:
+		if b.Versions > 1 {
+			res = append(res, capabilityUse{name: capVersioning, disable: disableVersions(&b.Versions)})
+		}
+	case *bench.Stat:
+		if b.Versions > 1 {
+			res = append(res, capabilityUse{name: capVersioning, disable: disableVersions(&b.Versions)})
+		}
+	case *bench.List:
+		if b.Versions > 1 {
+			res = append(res, capabilityUse{name: capVersioning, disable: disableVersions(&b.Versions)})
+		}
+	case *bench.ReadModifyWrite:
+		if b.Versions {
+			res = append(res, capabilityUse{name: capVersioning, disable: func() {
+				b.Versions = false
+				setExtraFlag(c, "rmw.versioned", "false")
+			}})
+		}
+	case *bench.Versioned, *bench.Retention:
+		res = append(res, capabilityUse{name: capVersioning})
+	case *bench.Select:
+		res = append(res, capabilityUse{name: capSelect})
+	}
+	if c.Locking {
+		res = append(res, capabilityUse{name: capObjectLock})
+	}
+	return res
+}
+
+// setExtraFlag sets a flag sent to remote clients.
+func setExtraFlag(c *bench.Common, name, value string) {
+	if c.ExtraFlags == nil {
+		c.ExtraFlags = make(map[string]string)
+	}
+	c.ExtraFlags[name] = value
+}
+
+// probeCapability returns whether the server supports the capability.
+// If support cannot be determined, it is assumed to be supported.
+func probeCapability(ctx context.Context, cl *minio.Client, b bench.Benchmark, name string) (bool, error) {
+	bucket := b.GetCommon().Bucket
+	var err error
+	switch name {
+	case capVersioning:
+		_, err = cl.GetBucketVersioning(ctx, bucket)
+	case capObjectLock:
+		_, _, _, err = cl.GetBucketObjectLockConfig(ctx, bucket)
+	case capChecksum:
+		return probeChecksum(ctx, cl, bucket)
+	case capSelect:
+		opts := minio.SelectObjectOptions{}
+		if s, ok := b.(*bench.Select); ok {
+			opts = s.SelectOpts
+		}
+		var res *minio.SelectResults
+		res, err = cl.SelectObjectContent(ctx, bucket, capabilityProbeKey, opts)
+		if err == nil {
+			res.Close()
+		}
+	}
+	if err == nil {
+		return true, nil
+	}
+	resp := minio.ToErrorResponse(err)
+	if resp.Code == "NotImplemented" || resp.StatusCode == http.StatusNotImplemented {
+		return false, err
+	}
+	// Errors like missing bucket, key or configuration do not show lack of support.
+	return true, nil
+}
+
+// probeChecksum returns whether the server returns the CRC32C checksum sent with an upload on HEAD.
+// The probe object is removed again.
+// If the upload fails for other reasons than lack of support, checksums are assumed to be supported.
+func probeChecksum(ctx context.Context, cl *minio.Client, bucket string) (bool, error) {
+	data := []byte(capabilityProbeKey)
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)))
+	want := base64.StdEncoding.EncodeToString(sum[:])
+	_, err := cl.PutObject(ctx, bucket, capabilityProbeKey, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		UserMetadata: map[string]string{"X-Amz-Checksum-Crc32c": want},
+	})
+	if err != nil {
+		resp := minio.ToErrorResponse(err)
+		if resp.Code == "NotImplemented" || resp.StatusCode == http.StatusNotImplemented {
+			return false, err
+		}
+		return true, nil
+	}
+	defer cl.RemoveObject(ctx, bucket, capabilityProbeKey, minio.RemoveObjectOptions{})
+	st, err := cl.StatObject(ctx, bucket, capabilityProbeKey, minio.StatObjectOptions{Checksum: true})
+	if err != nil {
+		return true, nil
+	}
+	if st.ChecksumCRC32C != want {
+		return false, fmt.Errorf("CRC32C checksum %q sent with upload, got %q on HEAD", want, st.ChecksumCRC32C)
+	}
+	return true, nil
+}
+
+// probeProtocol returns the HTTP protocol used with the first host.
+func probeProtocol(ctx *cli.Context) string {
+	scheme := "http://"
+	if ctx.Bool("tls") {
+		scheme = "https://"
+	}
+	bg, cancel := context.WithTimeout(context.Background(), serverProbeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(bg, http.MethodHead, scheme+parseHosts(ctx.String("host"))[0], nil)
+	if err != nil {
+		return ""
+	}
+	resp, err := (&http.Client{Transport: clientTransport(ctx)}).Do(req)
+	if err != nil {
+		return ""
+	}
+	resp.Body.Close()
+	return resp.Proto
+}
+
+// checkCapabilities probes the server for the capabilities used by the benchmark.
+// Depending on --capabilities unsupported optional capabilities are disabled or the benchmark is stopped.
+// Notes to store with the benchmark data are returned.
+func checkCapabilities(ctx *cli.Context, b bench.Benchmark) []string {
+	mode := ctx.String("capabilities")
+	if mode == capModeOff {
+		return nil
+	}
+	var notes []string
+	if ctx.Bool("tls") && !ctx.Bool("loopback") {
+		if proto := probeProtocol(ctx); proto != "" && proto != "HTTP/2.0" {
+			notes = append(notes, fmt.Sprintf("HTTP/2 not supported by server, using %s", proto))
+		}
+	}
+	cl, done := newClient(ctx)()
+	defer done()
+	bg, cancel := context.WithTimeout(context.Background(), serverProbeTimeout)
+	defer cancel()
+	// Checksums are not used by benchmarks, but support is noted
+	// since clients sending checksums will see different performance.
+	if ok, err := probeCapability(bg, cl, b, capChecksum); !ok {
+		note := fmt.Sprintf("%s not supported by server: %v", capChecksum, err)
+		console.Infoln(note)
+		notes = append(notes, note)
+	}
+	for _, use := range benchCapabilities(b) {
+		ok, err := probeCapability(bg, cl, b, use.name)
+		if ok {
+			continue
+		}
+		if mode == capModeAuto && use.disable != nil {
+			use.disable()
+			note := fmt.Sprintf("%s not supported by server, disabled", use.name)
+			console.Infoln(note)
+			notes = append(notes, note)
+			continue
+		}
+		if use.disable != nil {
+			fatalIf(probe.NewError(err), "Server does not support %s. Use --capabilities=auto to run without it", use.name)
+		}
+		fatalIf(probe.NewError(err), "Server does not support %s, which is required by the benchmark", use.name)
+	}
+	return notes
+}
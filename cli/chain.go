@@ -0,0 +1,104 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+var chainFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "objects",
+		Value: 2500,
+		Usage: "Number of objects to upload.",
+	},
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "1MiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.StringFlag{
+		Name:  "chain.mode",
+		Value: bench.ChainListGet,
+		Usage: "Operations to chain. Can be 'list-get' or 'stat-get'.",
+	},
+	cli.IntFlag{
+		Name:  "chain.sample",
+		Value: 5,
+		Usage: "Number of listed objects to download in 'list-get' mode.",
+	},
+}
+
+var chainCmd = cli.Command{
+	Name:   "chain",
+	Usage:  "benchmark chained operations",
+	Action: mainChain,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, chainFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+  -> see https://github.com/minio/warp#chain
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainChain is the entry point for chain command.
+func mainChain(ctx *cli.Context) error {
+	checkChainSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	b := bench.Chain{
+		Common: bench.Common{
+			Client:      newClient(ctx),
+			Concurrency: getConcurrency(ctx),
+			Source:      src,
+			Bucket:      ctx.String("bucket"),
+			Location:    "",
+			PutOpts:     putOpts(ctx),
+		},
+		CreateObjects: ctx.Int("objects"),
+		Mode:          ctx.String("chain.mode"),
+		Sample:        ctx.Int("chain.sample"),
+	}
+	return runBench(ctx, &b)
+}
+
+func checkChainSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	switch ctx.String("chain.mode") {
+	case bench.ChainListGet, bench.ChainStatGet:
+	default:
+		console.Fatal("--chain.mode must be 'list-get' or 'stat-get'")
+	}
+	if ctx.Int("chain.sample") < 1 {
+		console.Fatal("--chain.sample must be at least 1")
+	}
+	if ctx.Int("objects") < 1 {
+		console.Fatal("At least one object must be used")
+	}
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
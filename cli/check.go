@@ -0,0 +1,320 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+)
+
+// checkPartSize is the part size of the multipart check, the minimum allowed by S3.
+const checkPartSize = 5 << 20
+
+var checkFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "1KiB",
+		Usage: "Size of the uploaded object. The multipart object is this size larger than one part.",
+	},
+	cli.DurationFlag{
+		Name:  "timeout",
+		Value: time.Minute,
+		Usage: "Maximum time of each check.",
+	},
+}
+
+var checkCmd = cli.Command{
+	Name:   "check",
+	Usage:  "run a quick check of all operation types against a server",
+	Action: mainCheck,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, checkFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+  -> see https://github.com/minio/warp#checking-servers
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// Results of checks.
+const (
+	checkPass = "PASS"
+	checkFail = "FAIL"
+	checkSkip = "SKIP"
+)
+
+// checker runs checks and prints their results.
+type checker struct {
+	cl      *minio.Client
+	bucket  string
+	prefix  string
+	timeout time.Duration
+	failed  int
+	// versioned is set if versioning is enabled on the bucket.
+	versioned bool
+}
+
+// run runs a check and prints the result and time of it.
+// The check returns information printed after the result.
+func (c *checker) run(name string, check func(ctx context.Context) (string, error)) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	start := time.Now()
+	info, err := check(ctx)
+	took := time.Since(start).Round(time.Microsecond)
+	if err != nil {
+		c.failed++
+		console.Println(fmt.Sprintf(" %s  %-10s %8v  %v", checkFail, name, took, err))
+		return false
+	}
+	console.Println(fmt.Sprintf(" %s  %-10s %8v  %s", checkPass, name, took, info))
+	return true
+}
+
+// skip prints a check that was not run.
+func (c *checker) skip(name, reason string) {
+	console.Println(fmt.Sprintf(" %s  %-10s %8s  %s", checkSkip, name, "", reason))
+}
+
+// put uploads data and checks the size stored.
+func (c *checker) put(ctx context.Context, key string, data []byte, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+	info, err := c.cl.PutObject(ctx, c.bucket, key, bytes.NewReader(data), int64(len(data)), opts)
+	if err == nil && info.Size != int64(len(data)) {
+		err = fmt.Errorf("uploaded %d bytes, server reported %d", len(data), info.Size)
+	}
+	return info, err
+}
+
+// get downloads an object and compares it to data.
+func (c *checker) get(ctx context.Context, key string, data []byte, opts minio.GetObjectOptions) error {
+	obj, err := c.cl.GetObject(ctx, c.bucket, key, opts)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	got, err := io.ReadAll(obj)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, data) {
+		return fmt.Errorf("downloaded %d bytes that do not match the %d bytes uploaded", len(got), len(data))
+	}
+	return nil
+}
+
+// mainCheck is the entry point for the check command.
+// Each operation type is run once against the server,
+// so problems show up before large benchmarks are started.
+func mainCheck(ctx *cli.Context) error {
+	size, err := toSize(ctx.String("obj.size"))
+	fatalIf(probe.NewError(err), "Invalid obj.size specified")
+	if size == 0 {
+		fatalIf(errDummy(), "obj.size must be > 0")
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	cl, done := newClient(ctx)()
+	defer done()
+	c := checker{
+		cl:      cl,
+		bucket:  ctx.String("bucket"),
+		prefix:  path.Join(ctx.String("prefix"), fmt.Sprintf("warp-check-%08x", rng.Uint32())) + "/",
+		timeout: ctx.Duration("timeout"),
+	}
+	region := ctx.String("region")
+	setFatalExitCode(exitError)
+	console.Println(fmt.Sprintf("Checking %s, bucket %q, prefix %q", ctx.String("host"), c.bucket, c.prefix))
+	start := time.Now()
+
+	data := make([]byte, size)
+	rng.Read(data)
+	key := c.prefix + "object"
+
+	createdBucket := false
+	if !c.run("bucket", func(ctx context.Context) (string, error) {
+		exists, err := cl.BucketExists(ctx, c.bucket)
+		if err != nil || exists {
+			return "exists", err
+		}
+		err = cl.MakeBucket(ctx, c.bucket, minio.MakeBucketOptions{Region: region})
+		createdBucket = err == nil
+		return "created", err
+	}) {
+		setFatalExitCode(exitConnectivity)
+		console.Fatal("Unable to access bucket, remaining checks skipped")
+	}
+
+	uploaded := c.run("put", func(ctx context.Context) (string, error) {
+		_, err := c.put(ctx, key, data, minio.PutObjectOptions{DisableMultipart: true})
+		return humanize.IBytes(size), err
+	})
+	needsObject := func(name string, check func(ctx context.Context) (string, error)) {
+		if !uploaded {
+			c.skip(name, "put failed")
+			return
+		}
+		c.run(name, check)
+	}
+	needsObject("get", func(ctx context.Context) (string, error) {
+		return humanize.IBytes(size), c.get(ctx, key, data, minio.GetObjectOptions{})
+	})
+	needsObject("stat", func(ctx context.Context) (string, error) {
+		st, err := cl.StatObject(ctx, c.bucket, key, minio.StatObjectOptions{})
+		if err == nil && st.Size != int64(size) {
+			err = fmt.Errorf("size is %d, want %d", st.Size, size)
+		}
+		return "etag " + strings.Trim(st.ETag, `"`), err
+	})
+	needsObject("list", func(ctx context.Context) (string, error) {
+		n := 0
+		for obj := range cl.ListObjects(ctx, c.bucket, minio.ListObjectsOptions{Prefix: c.prefix, Recursive: true}) {
+			if obj.Err != nil {
+				return "", obj.Err
+			}
+			if obj.Key == key {
+				n++
+			}
+		}
+		if n != 1 {
+			return "", fmt.Errorf("uploaded object listed %d times", n)
+		}
+		return "object listed", nil
+	})
+
+	mpKey := c.prefix + "multipart"
+	mpData := make([]byte, checkPartSize+size)
+	rng.Read(mpData)
+	c.run("multipart", func(ctx context.Context) (string, error) {
+		info, err := c.put(ctx, mpKey, mpData, minio.PutObjectOptions{PartSize: checkPartSize})
+		if err != nil {
+			return "", err
+		}
+		if err := c.get(ctx, mpKey, mpData, minio.GetObjectOptions{}); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("2 parts, %s, etag %s", humanize.IBytes(uint64(len(mpData))), strings.Trim(info.ETag, `"`)), nil
+	})
+
+	c.checkVersioned(rng)
+
+	needsObject("delete", func(ctx context.Context) (string, error) {
+		if err := cl.RemoveObject(ctx, c.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+			return "", err
+		}
+		_, err := cl.StatObject(ctx, c.bucket, key, minio.StatObjectOptions{})
+		if err == nil {
+			return "", errors.New("object still exists after delete")
+		}
+		if code := minio.ToErrorResponse(err).Code; code != "NoSuchKey" {
+			return "", fmt.Errorf("stat after delete: %w", err)
+		}
+		return "object removed", nil
+	})
+
+	c.cleanup(createdBucket)
+	took := time.Since(start).Round(time.Millisecond)
+	if c.failed > 0 {
+		console.Fatal(fmt.Sprintf("%d checks failed in %v.", c.failed, took))
+	}
+	console.Println(fmt.Sprintf("All checks passed in %v.", took))
+	return nil
+}
+
+// checkVersioned checks versions of an object if versioning is enabled on the bucket.
+func (c *checker) checkVersioned(rng *rand.Rand) {
+	const name = "versioned"
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	cfg, err := c.cl.GetBucketVersioning(ctx, c.bucket)
+	cancel()
+	if err != nil || !cfg.Enabled() {
+		c.skip(name, "versioning not enabled on bucket")
+		return
+	}
+	c.versioned = true
+	key := c.prefix + "versioned"
+	c.run(name, func(ctx context.Context) (string, error) {
+		v1 := make([]byte, 1024)
+		v2 := make([]byte, 1024)
+		rng.Read(v1)
+		rng.Read(v2)
+		first, err := c.put(ctx, key, v1, minio.PutObjectOptions{})
+		if err != nil {
+			return "", err
+		}
+		if _, err := c.put(ctx, key, v2, minio.PutObjectOptions{}); err != nil {
+			return "", err
+		}
+		if first.VersionID == "" {
+			return "", errors.New("no version ID returned")
+		}
+		if err := c.get(ctx, key, v1, minio.GetObjectOptions{VersionID: first.VersionID}); err != nil {
+			return "", fmt.Errorf("get first version: %w", err)
+		}
+		n := 0
+		for obj := range c.cl.ListObjects(ctx, c.bucket, minio.ListObjectsOptions{Prefix: key, WithVersions: true}) {
+			if obj.Err != nil {
+				return "", obj.Err
+			}
+			if obj.Key == key {
+				n++
+			}
+		}
+		if n != 2 {
+			return "", fmt.Errorf("listed %d versions, want 2", n)
+		}
+		if err := c.cl.RemoveObject(ctx, c.bucket, key, minio.RemoveObjectOptions{VersionID: first.VersionID}); err != nil {
+			return "", fmt.Errorf("delete first version: %w", err)
+		}
+		return "2 versions", nil
+	})
+}
+
+// cleanup removes objects below the prefix, all versions if the bucket is versioned,
+// and the bucket if it was created by the check.
+func (c *checker) cleanup(removeBucket bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	for obj := range c.cl.ListObjects(ctx, c.bucket, minio.ListObjectsOptions{Prefix: c.prefix, Recursive: true, WithVersions: c.versioned}) {
+		if obj.Err != nil {
+			errorIf(probe.NewError(obj.Err), "Unable to list objects to clean up")
+			return
+		}
+		err := c.cl.RemoveObject(ctx, c.bucket, obj.Key, minio.RemoveObjectOptions{VersionID: obj.VersionID})
+		errorIf(probe.NewError(err), "Unable to remove "+obj.Key)
+	}
+	if removeBucket {
+		errorIf(probe.NewError(c.cl.RemoveBucket(ctx, c.bucket)), "Unable to remove bucket")
+	}
+}
@@ -0,0 +1,311 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+)
+
+var cleanupFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "cleanup.buckets",
+		Value: appName + "-",
+		Usage: "Comma separated prefixes of the buckets to clean if --bucket is not given. The default matches the default benchmark bucket.",
+	},
+	cli.StringFlag{
+		Name:  "cleanup.journal",
+		Usage: "Only remove unchanged objects recorded in this journal, written by benchmarks with --cleanup.journal.",
+	},
+	cli.StringFlag{
+		Name:  "manifest",
+		Usage: "Only remove objects in this manifest or registry, written by benchmarks with --manifest or --registry. Requires --bucket.",
+	},
+	benchDataKeyFlag,
+	cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Only list what would be removed.",
+	},
+	cli.BoolFlag{
+		Name:  "i-know-what-im-doing",
+		Usage: "Also remove objects and uploads with names not created by warp, and their buckets.",
+	},
+}
+
+var cleanupCmd = cli.Command{
+	Name:   "cleanup",
+	Usage:  "remove benchmark data left by crashed runs",
+	Action: mainCleanup,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, cleanupFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+  -> see https://github.com/minio/warp#cleaning-up
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// cleanupStats is what was found in a bucket, and removed unless it is a dry run.
+type cleanupStats struct {
+	objects int
+	bytes   int64
+	uploads int
+	// kept is the number of objects and uploads not created by warp.
+	kept          int
+	errors        int
+	removedBucket bool
+}
+
+func (s *cleanupStats) add(other cleanupStats) {
+	s.objects += other.objects
+	s.bytes += other.bytes
+	s.uploads += other.uploads
+	s.kept += other.kept
+	s.errors += other.errors
+}
+
+// String returns a summary of the stats, in the past tense unless it is a dry run.
+func (s cleanupStats) String(dryRun bool) string {
+	verb := "removed"
+	if dryRun {
+		verb = "would remove"
+	}
+	res := fmt.Sprintf("%s %d objects (%s), %d incomplete uploads", verb, s.objects, humanize.IBytes(uint64(s.bytes)), s.uploads)
+	if s.kept > 0 {
+		res += fmt.Sprintf(", kept %d not created by warp", s.kept)
+	}
+	if s.errors > 0 {
+		res += fmt.Sprintf(", %d errors", s.errors)
+	}
+	return res
+}
+
+// bucketCleaner removes benchmark data from buckets of a host.
+type bucketCleaner struct {
+	cl     *minio.Client
+	host   string
+	prefix string
+	dryRun bool
+	// force removes data with names not created by warp.
+	force bool
+	// created objects, if they are recorded.
+	created *warpObjects
+}
+
+// removable returns whether the object was created by warp, or force is set.
+func (c *bucketCleaner) removable(bucket string, obj minio.ObjectInfo) bool {
+	return c.force || c.created.object(bucket, obj)
+}
+
+// uploadRemovable returns whether the upload is of a key created by warp, or force is set.
+func (c *bucketCleaner) uploadRemovable(bucket, key string) bool {
+	return c.force || c.created.key(bucket, key)
+}
+
+// clean removes all versions of objects created by warp and their incomplete uploads from the bucket.
+// The bucket is removed if nothing else was found in it, unless only a prefix is cleaned.
+func (c *bucketCleaner) clean(ctx context.Context, bucket string) cleanupStats {
+	// listed is only updated by the lister until objectsCh is closed.
+	var st, listed cleanupStats
+	objectsCh := make(chan minio.ObjectInfo)
+	go func() {
+		defer close(objectsCh)
+		for obj := range c.cl.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: c.prefix, Recursive: true, WithVersions: true}) {
+			if obj.Err != nil {
+				errorIf(probe.NewError(obj.Err), "Unable to list objects in %s/%s", c.host, bucket)
+				listed.errors++
+				return
+			}
+			if !c.removable(bucket, obj) {
+				listed.kept++
+				continue
+			}
+			listed.objects++
+			listed.bytes += obj.Size
+			if !c.dryRun {
+				objectsCh <- obj
+			}
+		}
+	}()
+	for err := range c.cl.RemoveObjects(ctx, bucket, objectsCh, minio.RemoveObjectsOptions{GovernanceBypass: true}) {
+		if err.Err != nil {
+			errorIf(probe.NewError(err.Err), "Unable to remove %s/%s/%s", c.host, bucket, err.ObjectName)
+			st.errors++
+		}
+	}
+	st.add(listed)
+
+	core := minio.Core{Client: c.cl}
+	for up := range c.cl.ListIncompleteUploads(ctx, bucket, c.prefix, true) {
+		if up.Err != nil {
+			errorIf(probe.NewError(up.Err), "Unable to list incomplete uploads in %s/%s", c.host, bucket)
+			st.errors++
+			break
+		}
+		if !c.uploadRemovable(bucket, up.Key) {
+			st.kept++
+			continue
+		}
+		st.uploads++
+		if c.dryRun {
+			continue
+		}
+		if err := core.AbortMultipartUpload(ctx, bucket, up.Key, up.UploadID); err != nil {
+			errorIf(probe.NewError(err), "Unable to abort upload of %s/%s/%s", c.host, bucket, up.Key)
+			st.errors++
+		}
+	}
+
+	if st.kept > 0 || st.errors > 0 || c.prefix != "" {
+		return st
+	}
+	if !c.dryRun {
+		if err := c.cl.RemoveBucket(ctx, bucket); err != nil {
+			errorIf(probe.NewError(err), "Unable to remove bucket %s/%s", c.host, bucket)
+			st.errors++
+			return st
+		}
+	}
+	st.removedBucket = true
+	return st
+}
+
+// cleanupBuckets returns the bucket if it exists, or the buckets matching if no bucket is given.
+func cleanupBuckets(ctx context.Context, cl *minio.Client, bucket string, match func(bucket string) bool) ([]string, error) {
+	if bucket != "" {
+		ok, err := cl.BucketExists(ctx, bucket)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return []string{bucket}, nil
+	}
+	list, err := cl.ListBuckets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, b := range list {
+		if match(b.Name) {
+			names = append(names, b.Name)
+		}
+	}
+	return names, nil
+}
+
+// mainCleanup is the entry point for the cleanup command.
+// The bucket given, or buckets starting with the cleanup prefixes, are searched on every host,
+// and the objects, versions and incomplete uploads created by warp are removed,
+// and the buckets if nothing else is left in them.
+func mainCleanup(ctx *cli.Context) error {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if ctx.String("manifest") != "" && !ctx.IsSet("bucket") {
+		console.Fatal("--manifest requires --bucket")
+	}
+	var match func(bucket string) bool
+	var what string
+	bucket := ""
+	if ctx.IsSet("bucket") {
+		bucket = ctx.String("bucket")
+		what = fmt.Sprintf("bucket %q", bucket)
+	} else {
+		var prefixes []string
+		for _, p := range strings.Split(ctx.String("cleanup.buckets"), ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				prefixes = append(prefixes, p)
+			}
+		}
+		if len(prefixes) == 0 {
+			console.Fatal("No bucket prefixes given with --cleanup.buckets")
+		}
+		match = func(b string) bool {
+			for _, p := range prefixes {
+				if strings.HasPrefix(b, p) {
+					return true
+				}
+			}
+			return false
+		}
+		what = fmt.Sprintf("buckets starting with %q", strings.Join(prefixes, `", "`))
+	}
+	created, err := recordedWarpObjects(ctx, ctx.String("cleanup.journal"), ctx.String("manifest"), ctx.String("bucket"))
+	fatalIf(probe.NewError(err), "Unable to read created objects")
+	defer created.close()
+
+	dryRun := ctx.Bool("dry-run")
+	hosts := parseHosts(ctx.String("host"))
+	setFatalExitCode(exitError)
+	if dryRun {
+		console.Println(fmt.Sprintf("Dry run. Listing %s on %s.", what, strings.Join(hosts, ", ")))
+	}
+	bg := context.Background()
+	var total cleanupStats
+	buckets, removed := 0, 0
+	for _, host := range hosts {
+		cl, err := getClient(ctx, host)
+		fatalIf(probe.NewError(err), "Unable to create MinIO client")
+		names, err := cleanupBuckets(bg, cl, bucket, match)
+		if err != nil {
+			errorIf(probe.NewError(err), "Unable to list buckets on %s", host)
+			total.errors++
+			continue
+		}
+		c := bucketCleaner{cl: cl, host: host, prefix: ctx.String("prefix"), dryRun: dryRun, force: ctx.Bool("i-know-what-im-doing"), created: created}
+		for _, name := range names {
+			buckets++
+			st := c.clean(bg, name)
+			total.add(st)
+			msg := fmt.Sprintf("%s/%s: %s", host, name, st.String(dryRun))
+			if st.removedBucket {
+				removed++
+				if dryRun {
+					msg += ", would remove bucket"
+				} else {
+					msg += ", removed bucket"
+				}
+			}
+			console.Println(msg)
+		}
+	}
+	if buckets == 0 {
+		console.Println(fmt.Sprintf("No %s found.", what))
+	} else {
+		verb := "removed"
+		if dryRun {
+			verb = "would remove"
+		}
+		console.Println(fmt.Sprintf("Total of %d buckets: %s, %s %d buckets.", buckets, total.String(dryRun), verb, removed))
+	}
+	if total.errors > 0 {
+		console.Fatal(fmt.Sprintf("Cleanup had %d errors.", total.errors))
+	}
+	return nil
+}
@@ -25,6 +25,7 @@ import (
 	"runtime"
 	"sort"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	mprofile "github.com/bygui86/multi-profile/v2"
@@ -56,6 +57,7 @@ const (
 func Main(args []string) {
 	// Set system max resources as needed.
 	setMaxResources()
+	applyContainerLimits()
 
 	if len(args) > 1 {
 		switch args[1] {
@@ -81,9 +83,14 @@ func Main(args []string) {
 	// Set the warp app name.
 	appName := filepath.Base(args[0])
 
+	installFatalExitCodes()
+
 	// Run the app - exit on error.
 	if err := registerApp(appName, appCmds).Run(args); err != nil {
-		os.Exit(1)
+		os.Exit(exitConfig)
+	}
+	if code := atomic.LoadInt32(&exitStatus); code != 0 {
+		os.Exit(int(code))
 	}
 }
 
@@ -100,14 +107,36 @@ func init() {
 		retentionCmd,
 		multipartCmd,
 		zipCmd,
+		injectCmd,
+		sigCmpCmd,
+		compGetCmd,
+		mirrorCmd,
+		rmwCmd,
+		queueCmd,
+		ttlCmd,
+		growCmd,
+		chainCmd,
+		replayCmd,
+		ttfbCmd,
 	}
 	b := []cli.Command{
 		analyzeCmd,
 		cmpCmd,
 		mergeCmd,
+		auditCmd,
+		verifyCmd,
 		clientCmd,
+		multiCmd,
+		trendCmd,
+		validateCmd,
+		genBenchCmd,
+		checkCmd,
+		cleanupCmd,
 	}
 	appCmds = append(a, b...)
+	for i := range appCmds {
+		appCmds[i].CustomHelpTemplate += exitCodesHelp
+	}
 	benchCmds = a
 }
 
@@ -212,6 +241,7 @@ func registerApp(name string, appCmds []cli.Command) *cli.App {
 	}
 
 	app.HideHelpCommand = true
+	app.CustomAppHelpTemplate = cli.AppHelpTemplate + exitCodesHelp
 	app.Usage = "Benchmark tool for S3 compatible object storage systems.\n\tFor usage details see https://github.com/minio/warp"
 	app.Commands = commands
 	app.Author = "MinIO, Inc."
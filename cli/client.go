@@ -20,12 +20,16 @@ package cli
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"errors"
+	"hash/fnv"
 	"log"
 	"math"
 	"math/rand"
 	"net"
 	"net/http"
+	"os"
+	"path"
 	"strings"
 	"sync"
 	"time"
@@ -40,6 +44,7 @@ import (
 	"github.com/minio/pkg/console"
 	"github.com/minio/pkg/ellipses"
 	"github.com/minio/warp/pkg"
+	"github.com/minio/warp/pkg/bench"
 	"golang.org/x/net/http2"
 )
 
@@ -47,11 +52,26 @@ type hostSelectType string
 
 const (
 	hostSelectTypeRoundrobin hostSelectType = "roundrobin"
+	hostSelectTypeHash       hostSelectType = "hash"
 	hostSelectTypeWeighed    hostSelectType = "weighed"
 )
 
 func newClient(ctx *cli.Context) func() (cl *minio.Client, done func()) {
-	hosts := parseHosts(ctx.String("host"))
+	return newHostsClient(ctx, ctx.String("host"))
+}
+
+// newMetaClient returns clients for bucket and metadata operations,
+// or nil if the same hosts as other operations should be used.
+func newMetaClient(ctx *cli.Context) func() (cl *minio.Client, done func()) {
+	if ctx.String("host.meta") == "" {
+		return nil
+	}
+	return newHostsClient(ctx, ctx.String("host.meta"))
+}
+
+// newHostsClient returns clients for the hosts given, selected as specified by host-select.
+func newHostsClient(ctx *cli.Context, host string) func() (cl *minio.Client, done func()) {
+	hosts := parseHosts(host)
 	switch len(hosts) {
 	case 0:
 		fatalIf(probe.NewError(errors.New("no host defined")), "Unable to create MinIO client")
@@ -65,8 +85,9 @@ func newClient(ctx *cli.Context) func() (cl *minio.Client, done func()) {
 	}
 	hostSelect := hostSelectType(ctx.String("host-select"))
 	switch hostSelect {
-	case hostSelectTypeRoundrobin:
+	case hostSelectTypeRoundrobin, hostSelectTypeHash:
 		// Do round-robin.
+		// With hash selection this is used for requests without an object key.
 		var current int
 		var mu sync.Mutex
 		clients := make([]*minio.Client, len(hosts))
@@ -97,7 +118,7 @@ func newClient(ctx *cli.Context) func() (cl *minio.Client, done func()) {
 		{
 			// Start with a random host
 			now := time.Now()
-			off := rand.New(rand.NewSource(time.Now().UnixNano())).Intn(len(hosts))
+			off := rand.Intn(len(hosts))
 			for i := range lastFinished {
 				t := now
 				t.Add(time.Duration(i + off%len(hosts)))
@@ -144,27 +165,97 @@ func newClient(ctx *cli.Context) func() (cl *minio.Client, done func()) {
 	return nil
 }
 
+// newKeyClient returns clients for object operations selected by the hash of the object key,
+// or nil if hosts are not selected by hash.
+func newKeyClient(ctx *cli.Context) func(key string) (cl *minio.Client, done func()) {
+	if hostSelectType(ctx.String("host-select")) != hostSelectTypeHash {
+		return nil
+	}
+	hosts := parseHosts(ctx.String("host"))
+	if len(hosts) == 0 {
+		fatalIf(probe.NewError(errors.New("no host defined")), "Unable to create MinIO client")
+	}
+	clients := make([]*minio.Client, len(hosts))
+	for i := range hosts {
+		cl, err := getClient(ctx, hosts[i])
+		fatalIf(probe.NewError(err), "Unable to create MinIO client")
+		clients[i] = cl
+	}
+	byPrefix := ctx.String("host.hash") == "prefix"
+	return func(key string) (*minio.Client, func()) {
+		if byPrefix {
+			key = path.Dir(key)
+		}
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		return clients[h.Sum32()%uint32(len(clients))], func() {}
+	}
+}
+
+// newClientWithKeys returns round-robin clients for the hosts given, using the specified keys.
+func newClientWithKeys(ctx *cli.Context, host, accessKey, secretKey string) func() (*minio.Client, func()) {
+	hosts := parseHosts(host)
+	if len(hosts) == 0 {
+		fatalIf(probe.NewError(errors.New("no host defined")), "Unable to create MinIO client")
+	}
+	clients := make([]*minio.Client, len(hosts))
+	for i := range hosts {
+		cl, err := getClientWithKeys(ctx, hosts[i], accessKey, secretKey)
+		fatalIf(probe.NewError(err), "Unable to create MinIO client")
+		clients[i] = cl
+	}
+	var current int
+	var mu sync.Mutex
+	return func() (*minio.Client, func()) {
+		mu.Lock()
+		now := current % len(clients)
+		current++
+		mu.Unlock()
+		return clients[now], func() {}
+	}
+}
+
 // getClient creates a client with the specified host and the options set in the context.
 func getClient(ctx *cli.Context, host string) (*minio.Client, error) {
+	return getClientWithKeys(ctx, host, ctx.String("access-key"), ctx.String("secret-key"))
+}
+
+// getClientWithKeys creates a client with the specified host and keys and the options set in the context.
+func getClientWithKeys(ctx *cli.Context, host, accessKey, secretKey string) (*minio.Client, error) {
 	var creds *credentials.Credentials
 	switch strings.ToUpper(ctx.String("signature")) {
 	case "S3V4":
 		// if Signature version '4' use NewV4 directly.
-		creds = credentials.NewStaticV4(ctx.String("access-key"), ctx.String("secret-key"), "")
+		creds = credentials.NewStaticV4(accessKey, secretKey, "")
 	case "S3V2":
 		// if Signature version '2' use NewV2 directly.
-		creds = credentials.NewStaticV2(ctx.String("access-key"), ctx.String("secret-key"), "")
+		creds = credentials.NewStaticV2(accessKey, secretKey, "")
 	default:
 		fatal(probe.NewError(errors.New("unknown signature method. S3V2 and S3V4 is available")), strings.ToUpper(ctx.String("signature")))
 	}
 
+	tr := clientTransport(ctx)
+	if ctx.Bool("session") {
+		// Requests are signed by the session transport.
+		creds = credentials.NewStaticV4("", "", "")
+		tr = sessionAuth(ctx, accessKey, secretKey).Transport(tr, host)
+	}
+	if ctx.Bool("backoff") {
+		tr = backoff(ctx).Transport(tr, host)
+	}
+	if j := journal(ctx); j != nil {
+		tr = j.Transport(tr, host)
+	}
+	if ctx.Bool("latency.attribution") {
+		tr = bench.NewLatencyTransport(tr)
+	}
 	cl, err := minio.New(host, &minio.Options{
 		Creds:        creds,
 		Secure:       ctx.Bool("tls"),
 		Region:       ctx.String("region"),
 		BucketLookup: minio.BucketLookupAuto,
 		CustomMD5:    md5simd.NewServer().NewHash,
-		Transport:    clientTransport(ctx),
+		Transport:    tr,
 	})
 	if err != nil {
 		return nil, err
@@ -173,14 +264,213 @@ func getClient(ctx *cli.Context, host string) (*minio.Client, error) {
 	return cl, nil
 }
 
+var (
+	sessionAuthsMu sync.Mutex
+	sessionAuths   = map[string]*bench.SessionAuth{}
+)
+
+// sessionAuth returns the session authentication shared by clients with the keys.
+func sessionAuth(ctx *cli.Context, accessKey, secretKey string) *bench.SessionAuth {
+	sessionAuthsMu.Lock()
+	defer sessionAuthsMu.Unlock()
+	key := accessKey + "\x00" + secretKey
+	if s := sessionAuths[key]; s != nil {
+		return s
+	}
+	s := bench.NewSessionAuth(accessKey, secretKey, ctx.String("region"))
+	s.Service = ctx.String("session.service")
+	sessionAuths[key] = s
+	return s
+}
+
+// sessionStats returns the combined overhead of session authentication.
+// False is returned if session authentication is not used.
+func sessionStats() (bench.SessionStats, bool) {
+	sessionAuthsMu.Lock()
+	defer sessionAuthsMu.Unlock()
+	var total bench.SessionStats
+	for _, s := range sessionAuths {
+		total.Add(s.Stats())
+	}
+	return total, len(sessionAuths) > 0
+}
+
+// sessionStatsPrefix prefixes custom values with session stats returned by clients.
+const sessionStatsPrefix = "session-stats."
+
+// returnSessionStats adds the session stats to the custom values returned to the server.
+func returnSessionStats(c *bench.Common) {
+	st, ok := sessionStats()
+	if !ok {
+		return
+	}
+	b, err := json.Marshal(st)
+	if err != nil {
+		return
+	}
+	host, _ := os.Hostname()
+	if c.Custom == nil {
+		c.Custom = make(map[string]string, 1)
+	}
+	c.Custom[sessionStatsPrefix+host] = string(b)
+}
+
+// returnedSessionStats returns the combined session stats returned by clients.
+// False is returned if no client used session authentication.
+func returnedSessionStats(custom map[string]string) (bench.SessionStats, bool) {
+	var total bench.SessionStats
+	found := false
+	for k, v := range custom {
+		if !strings.HasPrefix(k, sessionStatsPrefix) {
+			continue
+		}
+		var st bench.SessionStats
+		if json.Unmarshal([]byte(v), &st) == nil {
+			total.Add(st)
+			found = true
+		}
+	}
+	return total, found
+}
+
+var (
+	backoffMu    sync.Mutex
+	backoffState *bench.Backoff
+)
+
+// backoff returns the backoff shared by all clients,
+// so throttled prefixes are delayed for all requests.
+func backoff(ctx *cli.Context) *bench.Backoff {
+	backoffMu.Lock()
+	defer backoffMu.Unlock()
+	if backoffState == nil {
+		backoffState = bench.NewBackoff()
+		backoffState.Max = ctx.Duration("backoff.max")
+		if backoffState.Max < backoffState.Initial {
+			backoffState.Max = backoffState.Initial
+		}
+	}
+	return backoffState
+}
+
+// backoffStats returns the throttling seen by clients.
+// False is returned if backoff is not used.
+func backoffStats() (bench.BackoffStats, bool) {
+	backoffMu.Lock()
+	defer backoffMu.Unlock()
+	if backoffState == nil {
+		return bench.BackoffStats{}, false
+	}
+	return backoffState.Stats(), true
+}
+
+// backoffStatsPrefix prefixes custom values with throttling returned by clients.
+const backoffStatsPrefix = "backoff-stats."
+
+// returnBackoffStats adds the throttling seen to the custom values returned to the server.
+func returnBackoffStats(c *bench.Common) {
+	st, ok := backoffStats()
+	if !ok {
+		return
+	}
+	b, err := json.Marshal(st)
+	if err != nil {
+		return
+	}
+	host, _ := os.Hostname()
+	if c.Custom == nil {
+		c.Custom = make(map[string]string, 1)
+	}
+	c.Custom[backoffStatsPrefix+host] = string(b)
+}
+
+// returnedBackoffStats returns the combined throttling returned by clients.
+// False is returned if no client used backoff.
+func returnedBackoffStats(custom map[string]string) (bench.BackoffStats, bool) {
+	var total bench.BackoffStats
+	found := false
+	for k, v := range custom {
+		if !strings.HasPrefix(k, backoffStatsPrefix) {
+			continue
+		}
+		var st bench.BackoffStats
+		if json.Unmarshal([]byte(v), &st) == nil {
+			total.Add(st)
+			found = true
+		}
+	}
+	return total, found
+}
+
+// resetBackoffStats clears the throttling seen before the benchmark starts.
+func resetBackoffStats() {
+	backoffMu.Lock()
+	defer backoffMu.Unlock()
+	if backoffState != nil {
+		backoffState.ResetStats()
+	}
+}
+
+var (
+	journalMu    sync.Mutex
+	journalState *bench.Journal
+)
+
+// journal returns the journal of objects created by all clients.
+// Nil is returned if no journal is kept.
+func journal(ctx *cli.Context) *bench.Journal {
+	fn := ctx.String("cleanup.journal")
+	if fn == "" {
+		return nil
+	}
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	if journalState == nil {
+		var err error
+		journalState, err = bench.OpenJournal(fn)
+		fatalIf(probe.NewError(err), "Unable to open cleanup journal")
+	}
+	return journalState
+}
+
+// journalErr returns the first error writing the journal, if any.
+func journalErr() error {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	if journalState == nil {
+		return nil
+	}
+	return journalState.Err()
+}
+
+// rawTransport returns the transport of requests that are signed and sent without a client.
+func rawTransport(ctx *cli.Context) http.RoundTripper {
+	tr := clientTransport(ctx)
+	if ctx.Bool("session") {
+		tr = sessionAuth(ctx, ctx.String("access-key"), ctx.String("secret-key")).Transport(tr, "")
+	}
+	return tr
+}
+
 func clientTransport(ctx *cli.Context) http.RoundTripper {
+	if ctx.Bool("loopback") {
+		return loopbackTransport(ctx)
+	}
+	if k := ctx.Int("pipeline"); k > 1 {
+		// Each worker keeps k requests in flight on one HTTP/2 connection.
+		var tlsConfig *tls.Config
+		if ctx.Bool("tls") {
+			tlsConfig = clientTLSConfig(ctx)
+		}
+		return bench.NewPipelineTransport(getConcurrency(ctx), k, tlsConfig)
+	}
 	tr := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
 		DialContext: (&net.Dialer{
 			Timeout:   10 * time.Second,
 			KeepAlive: 10 * time.Second,
 		}).DialContext,
-		MaxIdleConnsPerHost:   ctx.Int("concurrent"),
+		MaxIdleConnsPerHost:   getConcurrency(ctx),
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   15 * time.Second,
 		ExpectContinueTimeout: 10 * time.Second,
@@ -195,18 +485,7 @@ func clientTransport(ctx *cli.Context) http.RoundTripper {
 		DisableKeepAlives:  ctx.Bool("disable-http-keepalive"),
 	}
 	if ctx.Bool("tls") {
-		// Keep TLS config.
-		tlsConfig := &tls.Config{
-			RootCAs: mustGetSystemCertPool(),
-			// Can't use SSLv3 because of POODLE and BEAST
-			// Can't use TLSv1.0 because of POODLE and BEAST using CBC cipher
-			// Can't use TLSv1.1 because of RC4 cipher usage
-			MinVersion: tls.VersionTLS12,
-		}
-		if ctx.Bool("insecure") {
-			tlsConfig.InsecureSkipVerify = true
-		}
-		tr.TLSClientConfig = tlsConfig
+		tr.TLSClientConfig = clientTLSConfig(ctx)
 
 		// Because we create a custom TLSClientConfig, we have to opt-in to HTTP/2.
 		// See https://github.com/golang/go/issues/14275
@@ -215,6 +494,21 @@ func clientTransport(ctx *cli.Context) http.RoundTripper {
 	return tr
 }
 
+// clientTLSConfig returns the TLS config of connections to the hosts.
+func clientTLSConfig(ctx *cli.Context) *tls.Config {
+	tlsConfig := &tls.Config{
+		RootCAs: mustGetSystemCertPool(),
+		// Can't use SSLv3 because of POODLE and BEAST
+		// Can't use TLSv1.0 because of POODLE and BEAST using CBC cipher
+		// Can't use TLSv1.1 because of RC4 cipher usage
+		MinVersion: tls.VersionTLS12,
+	}
+	if ctx.Bool("insecure") {
+		tlsConfig.InsecureSkipVerify = true
+	}
+	return tlsConfig
+}
+
 // parseHosts will parse the host parameter given.
 func parseHosts(h string) []string {
 	hosts := strings.Split(h, ",")
@@ -18,16 +18,24 @@
 package cli
 
 import (
+	"io"
+	"io/ioutil"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/probe"
-	"github.com/minio/pkg/console"
 )
 
-var clientFlags = []cli.Flag{}
+var clientFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:   "exit.stdin",
+		Usage:  "Exit when standard input is closed. Used for clients started with --procs.",
+		Hidden: true,
+	},
+}
 
 // Put command.
 var clientCmd = cli.Command{
@@ -69,8 +77,16 @@ func mainClient(ctx *cli.Context) error {
 	default:
 		fatal(errInvalidArgument(), "Too many parameters")
 	}
+	if ctx.Bool("exit.stdin") {
+		go func() {
+			io.Copy(ioutil.Discard, os.Stdin)
+			os.Exit(0)
+		}()
+	}
+	// Warnings and errors are also printed by the coordinator.
+	globalLogShipper = &logShipper{}
 	http.HandleFunc("/ws", serveWs)
-	console.Infoln("Listening on", addr)
+	logInfo("Listening", "addr", addr)
 	fatalIf(probe.NewError(http.ListenAndServe(addr, nil)), "Unable to start client")
 	return nil
 }
@@ -23,7 +23,6 @@ import (
 	"time"
 
 	"github.com/fatih/color"
-	"github.com/klauspost/compress/zstd"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/pkg/console"
@@ -55,23 +54,27 @@ func mainCmp(ctx *cli.Context) error {
 	checkAnalyze(ctx)
 	checkCmp(ctx)
 	args := ctx.Args()
-	zstdDec, _ := zstd.NewReader(nil)
-	defer zstdDec.Close()
 	log := console.Printf
 	if globalQuiet {
 		log = nil
 	}
-	readOps := func(s string) bench.Operations {
+	readOps := func(s string) (bench.Operations, []string) {
 		f, err := os.Open(s)
 		fatalIf(probe.NewError(err), "Unable to open input file")
 		defer f.Close()
-		err = zstdDec.Reset(f)
+		input, err := newBenchDataReader(f, ctx.String(benchDataKeyFlag.Name))
 		fatalIf(probe.NewError(err), "Unable to read input")
-		ops, err := bench.OperationsFromCSV(zstdDec, true, ctx.Int("analyze.offset"), ctx.Int("analyze.limit"), log)
+		defer input.Close()
+		ops, comments, err := bench.OperationsFromCSVComments(input, true, ctx.Int("analyze.offset"), ctx.Int("analyze.limit"), log)
 		fatalIf(probe.NewError(err), "Unable to parse input")
-		return ops
+		ops, annotations := ops.SplitAnnotations()
+		ops, _ = excludePauses(ops, annotations)
+		return ops, comments
 	}
-	printCompare(ctx, readOps(args[0]), readOps(args[1]))
+	before, beforeRun := readOps(args[0])
+	after, afterRun := readOps(args[1])
+	printProvenanceDiff(beforeRun, afterRun)
+	printCompare(ctx, before, after)
 	return nil
 }
 
@@ -82,7 +85,7 @@ func printCompare(ctx *cli.Context, before, after bench.Operations) {
 		if fn == "-" {
 			wrSegs = os.Stdout
 		} else {
-			f, err := os.Create(fn)
+			f, err := createOutputFile(fn, ctx.String(benchDataKeyFlag.Name))
 			fatalIf(probe.NewError(err), "Unable to create create analysis output")
 			defer console.Println("Aggregated data saved to", fn)
 			defer f.Close()
@@ -117,6 +120,7 @@ func printCompare(ctx *cli.Context, before, after bench.Operations) {
 			console.Println(err)
 			continue
 		}
+		cmp.Reqs.ComparePercentiles(before, after, analysisPercentiles(ctx))
 
 		if len(before) != len(after) {
 			console.Println("Operations:", len(before), "->", len(after))
@@ -136,7 +140,7 @@ func printCompare(ctx *cli.Context, before, after bench.Operations) {
 			console.Println("Duration:", timeDur(before), "->", timeDur(after))
 		}
 		if cmp.Reqs.Before.AvgObjSize != cmp.Reqs.After.AvgObjSize {
-			console.Println("Object size: %d->%d, ", cmp.Reqs.Before.AvgObjSize, cmp.Reqs.After.AvgObjSize)
+			console.Printf("Object size: %d->%d\n", cmp.Reqs.Before.AvgObjSize, cmp.Reqs.After.AvgObjSize)
 		}
 		console.Println("* Average:", cmp.Average)
 		console.Println("* Requests:", cmp.Reqs.String())
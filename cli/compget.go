@@ -0,0 +1,133 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+	"github.com/minio/warp/pkg/generator"
+)
+
+var compGetFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "objects",
+		Value: 2500,
+		Usage: "Number of objects of each type to upload.",
+	},
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "10MiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.IntFlag{
+		Name:  "compget.ratio",
+		Value: 4,
+		Usage: "Compression ratio of the compressible objects.",
+	},
+}
+
+var compGetCmd = cli.Command{
+	Name:   "compget",
+	Usage:  "benchmark get of compressible vs incompressible objects",
+	Action: mainCompGet,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, compGetFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+  -> see https://github.com/minio/warp#compget
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainCompGet is the entry point for compget command.
+func mainCompGet(ctx *cli.Context) error {
+	checkCompGetSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	sse := newSSE(ctx)
+	b := bench.CompGet{
+		Common: bench.Common{
+			Client:      newClient(ctx),
+			Concurrency: getConcurrency(ctx),
+			Source:      src,
+			Bucket:      ctx.String("bucket"),
+			Location:    "",
+			PutOpts:     putOpts(ctx),
+		},
+		CompSource:    newCompressibleGenSource(ctx),
+		CreateObjects: ctx.Int("objects"),
+		GetOpts:       minio.GetObjectOptions{ServerSideEncryption: sse},
+	}
+	return runBench(ctx, &b)
+}
+
+// newCompressibleGenSource returns a text generator with the same sizes as newGenSource,
+// generating data with the compression ratio of 'compget.ratio'.
+func newCompressibleGenSource(ctx *cli.Context) func() generator.Source {
+	prefixSize := 8
+	if ctx.Bool("noprefix") {
+		prefixSize = 0
+	}
+	size, err := toSize(ctx.String("obj.size"))
+	fatalIf(probe.NewError(err), "Invalid obj.size specified")
+	compRatio := ctx.Int("compget.ratio")
+	compWindow := getCompWindow(ctx)
+	opts := []generator.Option{
+		generator.WithTextData().Apply(),
+		generator.WithCustomPrefix(ctx.String("prefix")),
+		generator.WithPrefixSize(prefixSize),
+		generator.WithCompression(compRatio),
+		generator.WithCompressionWindow(int64(compWindow)),
+	}
+	if ctx.String("obj.dist") != "" {
+		sizesArr := parseDisrtibutionSizes(ctx)
+		validateCompParams(compRatio, getMinObjSize(sizesArr), compWindow)
+		opts = append(opts, generator.WithSizeDistribution(sizesArr))
+	} else {
+		if ctx.Bool("obj.randsize") {
+			validateCompParams(compRatio, generator.MIN_RAND_SIZE, compWindow)
+		} else {
+			validateCompParams(compRatio, int64(size), compWindow)
+		}
+		opts = append(opts, generator.WithSize(int64(size)), generator.WithRandomSize(ctx.Bool("obj.randsize")))
+	}
+	src, err := generator.NewFn(opts...)
+	fatalIf(probe.NewError(err), "Unable to create data generator")
+	return src
+}
+
+func checkCompGetSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if ctx.String("obj.generator") != "random" {
+		console.Fatal("compget requires '--obj.generator random' for incompressible objects")
+	}
+	if ctx.Int("compget.ratio") < 2 {
+		console.Fatal("--compget.ratio must be at least 2")
+	}
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
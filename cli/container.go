@@ -0,0 +1,225 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+)
+
+// cgroupRoot is where the cgroup of the container is mounted.
+var cgroupRoot = "/sys/fs/cgroup"
+
+const (
+	// containerConcurrencyPerCPU is the default concurrency for each CPU in a container,
+	// up to the default of the concurrent flag.
+	containerConcurrencyPerCPU = 8
+
+	// containerGCPercent is the GC target used when memory is limited.
+	containerGCPercent = 50
+
+	// containerThrottled is the fraction of CPU periods throttled that is reported.
+	containerThrottled = 0.1
+)
+
+// containerLimits contains the CPU and memory limits of the container warp runs in.
+// Zero values are unlimited or unknown.
+type containerLimits struct {
+	cpus   float64
+	memory uint64
+}
+
+func (c containerLimits) String() string {
+	var s []string
+	if c.cpus > 0 {
+		s = append(s, fmt.Sprintf("%.1f CPUs", c.cpus))
+	}
+	if c.memory > 0 {
+		s = append(s, humanize.IBytes(c.memory)+" memory")
+	}
+	return strings.Join(s, ", ")
+}
+
+// globalContainer contains the limits detected at startup.
+var globalContainer containerLimits
+
+// globalContainerConcurrency is the default concurrency lowered for the container CPUs.
+// Zero if the default is used.
+var globalContainerConcurrency int
+
+// readContainerLimits returns the limits of the cgroup, v2 or v1.
+func readContainerLimits(root string) containerLimits {
+	var c containerLimits
+	if b, err := os.ReadFile(filepath.Join(root, "cpu.max")); err == nil {
+		// "max 100000" or "quota period"
+		if f := strings.Fields(string(b)); len(f) == 2 {
+			quota, err1 := strconv.ParseFloat(f[0], 64)
+			period, err2 := strconv.ParseFloat(f[1], 64)
+			if err1 == nil && err2 == nil && quota > 0 && period > 0 {
+				c.cpus = quota / period
+			}
+		}
+	} else {
+		quota := readCgroupInt(filepath.Join(root, "cpu", "cpu.cfs_quota_us"))
+		period := readCgroupInt(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+		if quota > 0 && period > 0 {
+			c.cpus = float64(quota) / float64(period)
+		}
+	}
+	mem := readCgroupInt(filepath.Join(root, "memory.max"))
+	if mem < 0 {
+		mem = readCgroupInt(filepath.Join(root, "memory", "memory.limit_in_bytes"))
+	}
+	// cgroup v1 reports no limit as a huge number.
+	if mem > 0 && mem < 1<<60 {
+		c.memory = uint64(mem)
+	}
+	return c
+}
+
+// readCgroupInt returns the integer in the file.
+// -1 is returned if the file cannot be read or is "max".
+func readCgroupInt(fn string) int64 {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return -1
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return v
+}
+
+// applyContainerLimits detects the limits of the container and sizes the runtime for them,
+// unless GOMAXPROCS or GOGC are set.
+func applyContainerLimits() {
+	globalContainer = readContainerLimits(cgroupRoot)
+	if cpus := int(math.Ceil(globalContainer.cpus)); cpus > 0 && cpus < runtime.NumCPU() && os.Getenv("GOMAXPROCS") == "" {
+		runtime.GOMAXPROCS(cpus)
+	}
+	if globalContainer.memory > 0 && os.Getenv("GOGC") == "" {
+		debug.SetGCPercent(containerGCPercent)
+	}
+}
+
+// setContainerConcurrency lowers the default concurrency to what the CPUs of the container can drive.
+func setContainerConcurrency(ctx *cli.Context) {
+	globalContainerConcurrency = 0
+	if globalContainer.cpus <= 0 {
+		return
+	}
+	def := ctx.Int("concurrent")
+	n := int(math.Ceil(globalContainer.cpus * containerConcurrencyPerCPU))
+	if def <= 0 || n >= def {
+		return
+	}
+	globalContainerConcurrency = n
+}
+
+// getConcurrency returns the concurrency to use.
+// A concurrency given on the command line is kept, otherwise the default may be lowered for the container.
+func getConcurrency(ctx *cli.Context) int {
+	if globalContainerConcurrency > 0 && !ctx.IsSet("concurrent") {
+		return globalContainerConcurrency
+	}
+	return ctx.Int("concurrent")
+}
+
+// checkContainerLimits returns problems driving the concurrency within the limits of the container.
+func checkContainerLimits(ctx *cli.Context, concurrency int) []string {
+	c := globalContainer
+	if c.cpus <= 0 && c.memory <= 0 {
+		return nil
+	}
+	var problems []string
+	if c.cpus > 0 && c.cpus < 1 {
+		problems = append(problems, fmt.Sprintf("container is limited to %.2f CPUs, so the benchmark is likely limited by the client", c.cpus))
+	}
+	if c.memory > 0 {
+		size, err := toSize(ctx.String("obj.size"))
+		// Each concurrent operation keeps a buffer of the object size when uploading.
+		if need := uint64(concurrency) * size; err == nil && need > c.memory/2 {
+			problems = append(problems, fmt.Sprintf("container memory is limited to %s, but %d concurrent operations of %s objects may need %s. Lower --concurrent or --obj.size",
+				humanize.IBytes(c.memory), concurrency, humanize.IBytes(size), humanize.IBytes(need)))
+		}
+	}
+	return problems
+}
+
+// cgroupCPUStat contains the CPU throttling of the container.
+type cgroupCPUStat struct {
+	periods, throttled int64
+	throttledTime      time.Duration
+}
+
+// readCgroupCPUStat returns the CPU throttling of the cgroup.
+// False is returned if the container has no CPU limit.
+func readCgroupCPUStat(root string) (cgroupCPUStat, bool) {
+	var st cgroupCPUStat
+	b, err := os.ReadFile(filepath.Join(root, "cpu.stat"))
+	if err != nil {
+		b, err = os.ReadFile(filepath.Join(root, "cpu", "cpu.stat"))
+	}
+	if err != nil {
+		return st, false
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		f := strings.Fields(line)
+		if len(f) != 2 {
+			continue
+		}
+		v, err := strconv.ParseInt(f[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch f[0] {
+		case "nr_periods":
+			st.periods = v
+		case "nr_throttled":
+			st.throttled = v
+		case "throttled_usec":
+			st.throttledTime = time.Duration(v) * time.Microsecond
+		case "throttled_time":
+			st.throttledTime = time.Duration(v)
+		}
+	}
+	return st, st.periods > 0
+}
+
+// containerThrottleWarning returns a warning if the container CPU was throttled
+// in a significant part of the periods between the stats.
+func containerThrottleWarning(before, after cgroupCPUStat) string {
+	periods := after.periods - before.periods
+	throttled := after.throttled - before.throttled
+	if periods <= 0 || float64(throttled) < containerThrottled*float64(periods) {
+		return ""
+	}
+	return fmt.Sprintf("Container CPU limit: throttled in %.0f%% of periods for %v in total. The client may be limiting throughput.",
+		100*float64(throttled)/float64(periods), (after.throttledTime - before.throttledTime).Round(time.Millisecond))
+}
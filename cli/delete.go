@@ -68,7 +68,7 @@ func mainDelete(ctx *cli.Context) error {
 	b := bench.Delete{
 		Common: bench.Common{
 			Client:      newClient(ctx),
-			Concurrency: ctx.Int("concurrent"),
+			Concurrency: getConcurrency(ctx),
 			Source:      src,
 			Bucket:      ctx.String("bucket"),
 			Location:    "",
@@ -89,7 +89,7 @@ func checkDeleteSyntax(ctx *cli.Context) {
 	if ctx.Int("batch") < 1 {
 		console.Fatal("batch size much be 1 or bigger")
 	}
-	wantO := ctx.Int("batch") * ctx.Int("concurrent") * 4
+	wantO := ctx.Int("batch") * getConcurrency(ctx) * 4
 	if ctx.Int("objects") < wantO {
 		console.Fatalf("Too few objects: With current --batch  and --concurrent settings, at least %d objects should be used for a valid benchmark. Use --objects=%d", wantO, wantO)
 	}
@@ -0,0 +1,138 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/fatih/color"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/aggregate"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// Exit codes of warp.
+// If several conditions apply, the lowest code is used.
+const (
+	// exitError is an error that is not classified.
+	exitError = 1
+	// exitConfig is an invalid command line or input file, or a system or server not able to run the benchmark.
+	exitConfig = 2
+	// exitConnectivity is a failure to connect to the servers or warp clients.
+	exitConnectivity = 3
+	// exitVerification is data that does not match what was uploaded.
+	exitVerification = 4
+	// exitSLABreach is a latency objective that was not met.
+	exitSLABreach = 5
+	// exitPartial is a benchmark that completed with failed operations.
+	exitPartial = 6
+)
+
+// exitCodesHelp documents the exit codes in the help of all commands.
+const exitCodesHelp = `
+EXIT CODES:
+  0  Success.
+  1  Unclassified error.
+  2  Invalid configuration: Command line, input files, system limits or missing server capabilities.
+  3  Unable to connect to the servers or warp clients.
+  4  Verification failed: Downloaded or stored data does not match what was uploaded.
+  5  SLA breach: A latency objective of --analyze.slo was not met.
+  6  Partial completion: The benchmark completed, but some operations failed.
+  If several conditions apply, the lowest code is used.
+`
+
+var (
+	// fatalExitCode is the exit code of fatal errors.
+	// Fatal errors are configuration errors until the arguments have been checked.
+	fatalExitCode int32 = exitConfig
+
+	// exitStatus is the exit code when the command completes.
+	exitStatus int32
+)
+
+// setFatalExitCode sets the exit code of later fatal errors.
+func setFatalExitCode(code int) {
+	atomic.StoreInt32(&fatalExitCode, int32(code))
+}
+
+// setExitStatus sets the exit code when the command completes,
+// unless a lower code has already been set.
+func setExitStatus(code int) {
+	for {
+		old := atomic.LoadInt32(&exitStatus)
+		if old != 0 && old <= int32(code) {
+			return
+		}
+		if atomic.CompareAndSwapInt32(&exitStatus, old, int32(code)) {
+			return
+		}
+	}
+}
+
+// installFatalExitCodes makes the console fatal functions exit with fatalExitCode.
+// Fatal messages are printed like errors, with the fatal color.
+func installFatalExitCodes() {
+	fatalColor := color.New(color.FgRed, color.Italic, color.Bold)
+	exit := func() {
+		os.Exit(int(atomic.LoadInt32(&fatalExitCode)))
+	}
+	console.Fatal = func(data ...interface{}) {
+		console.SetColor("Error", fatalColor)
+		console.Error(data...)
+		exit()
+	}
+	console.Fatalf = func(format string, data ...interface{}) {
+		console.SetColor("Error", fatalColor)
+		console.Errorf(format, data...)
+		exit()
+	}
+	console.Fatalln = func(data ...interface{}) {
+		console.SetColor("Error", fatalColor)
+		console.Errorln(data...)
+		exit()
+	}
+}
+
+// connectivityError returns whether the error is a network error.
+func connectivityError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// setOpsExitStatus sets the exit status from the result of a benchmark.
+func setOpsExitStatus(ops bench.Operations, slos []aggregate.SLO) {
+	for _, op := range ops {
+		if op.Err == "" {
+			continue
+		}
+		if strings.HasPrefix(op.Err, bench.ErrContentMismatch) {
+			setExitStatus(exitVerification)
+			break
+		}
+		setExitStatus(exitPartial)
+	}
+	for _, slo := range slos {
+		if slo.BurnRate > 1 {
+			setExitStatus(exitSLABreach)
+		}
+	}
+}
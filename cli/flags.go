@@ -19,10 +19,14 @@ package cli
 
 import (
 	"fmt"
+	"math/rand"
 	"os"
+	"strings"
 
 	"github.com/minio/cli"
 	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+	"github.com/minio/warp/pkg/loopback"
 )
 
 // Collection of warp flags currently supported
@@ -49,6 +53,15 @@ var globalFlags = []cli.Flag{
 		Name:  "insecure",
 		Usage: "disable TLS certificate verification",
 	},
+	cli.StringFlag{
+		Name:  "log.level",
+		Value: "info",
+		Usage: "lowest level of log messages printed. Supported: debug, info, warn, error",
+	},
+	cli.BoolFlag{
+		Name:  "log.json",
+		Usage: "print log messages as JSON objects on stderr",
+	},
 	cli.BoolFlag{
 		Name:  "autocompletion",
 		Usage: "install auto-completion for your shell",
@@ -97,11 +110,27 @@ var profileFlags = []cli.Flag{
 
 // Set global states. NOTE: It is deliberately kept monolithic to ensure we dont miss out any flags.
 func setGlobalsFromContext(ctx *cli.Context) error {
+	expandFlagTemplates(ctx)
 	quiet := ctx.IsSet("quiet")
 	debug := ctx.IsSet("debug")
 	json := ctx.IsSet("json")
 	noColor := ctx.IsSet("no-color")
 	setGlobals(quiet, debug, json, noColor)
+	if ctx.IsSet("log.level") {
+		level, err := parseLogLevel(ctx.String("log.level"))
+		if err != nil {
+			return err
+		}
+		globalLogLevel = level
+	} else if debug {
+		globalLogLevel = logLevelDebug
+	}
+	globalLogJSON = globalLogJSON || ctx.IsSet("log.json")
+	setContainerConcurrency(ctx)
+	if ctx.Bool("debug.deterministic") {
+		// Generators and other random choices outside benchmark threads use the global source.
+		rand.Seed(ctx.Int64("debug.seed"))
+	}
 	return nil
 }
 
@@ -126,16 +155,35 @@ func commandLine(ctx *cli.Context) string {
 		if err != nil || val == "" {
 			continue
 		}
-		name := flag.GetName()
-		switch name {
-		case "access-key", "secret-key":
-			val = "*REDACTED*"
-		}
-		s += " --" + flag.GetName() + "=" + val
+		s += " --" + flag.GetName() + "=" + redactFlagValue(ctx, flag.GetName(), val)
 	}
 	return s
 }
 
+// redactFlagValue returns the flag value as it can be stored with benchmark data.
+// Secrets are removed, and names and hosts are hashed if redaction is requested.
+func redactFlagValue(ctx *cli.Context, name, val string) string {
+	switch name {
+	case "access-key", "secret-key", "mirror.access-key", "mirror.secret-key", benchDataKeyFlag.Name, "cse.key":
+		if val != "" {
+			return "*REDACTED*"
+		}
+	case "bucket", "prefix", "mirror.bucket":
+		if ctx.Bool(redactFlag.Name) {
+			return bench.RedactName(redactSalt, val)
+		}
+	case "host", "host.meta", "mirror.host", "restart.hosts", "warp-client":
+		if ctx.Bool(redactFlag.Name) && val != "" {
+			hosts := strings.Split(val, ",")
+			for i, h := range hosts {
+				hosts[i] = bench.RedactName(redactSalt, h)
+			}
+			return strings.Join(hosts, ",")
+		}
+	}
+	return val
+}
+
 // Flags common across all I/O commands such as cp, mirror, stat, pipe etc.
 var ioFlags = []cli.Flag{
 	cli.StringFlag{
@@ -166,6 +214,24 @@ var ioFlags = []cli.Flag{
 		Usage:  "Specify a custom region",
 		EnvVar: appNameUC + "_REGION",
 	},
+	cli.BoolFlag{
+		Name:  "session",
+		Usage: "Authenticate with bucket scoped session credentials from CreateSession, like S3 Express One Zone. Sessions are refreshed before they expire",
+	},
+	cli.StringFlag{
+		Name:  "session.service",
+		Value: bench.SessionService,
+		Usage: "Service name used when signing requests with session credentials",
+	},
+	cli.BoolFlag{
+		Name:  "backoff",
+		Usage: "Delay requests to prefixes throttled with 503 SlowDown, doubling the delay for each throttled request",
+	},
+	cli.DurationFlag{
+		Name:  "backoff.max",
+		Value: bench.BackoffMax,
+		Usage: "Maximum delay of requests to a throttled prefix",
+	},
 	cli.StringFlag{
 		Name:   "signature",
 		Usage:  "Specify a signature method. Available values are S3V2, S3V4",
@@ -181,10 +247,20 @@ var ioFlags = []cli.Flag{
 		Value: appName + "-benchmark-bucket",
 		Usage: "Bucket to use for benchmark data. ALL DATA WILL BE DELETED IN BUCKET!",
 	},
+	cli.StringFlag{
+		Name:   "host.meta",
+		Usage:  "host for bucket and metadata (LIST, STAT) operations. Multiple hosts can be specified as a comma separated list. Defaults to --host.",
+		EnvVar: appNameUC + "_HOST_META",
+	},
 	cli.StringFlag{
 		Name:  "host-select",
 		Value: string(hostSelectTypeWeighed),
-		Usage: fmt.Sprintf("Host selection algorithm. Can be %q or %q", hostSelectTypeWeighed, hostSelectTypeRoundrobin),
+		Usage: fmt.Sprintf("Host selection algorithm. Can be %q, %q or %q", hostSelectTypeWeighed, hostSelectTypeRoundrobin, hostSelectTypeHash),
+	},
+	cli.StringFlag{
+		Name:  "host.hash",
+		Value: "key",
+		Usage: "Part of the object name hashed to select the host with hash host selection. Can be 'key' or 'prefix'.",
 	},
 	cli.IntFlag{
 		Name:  "concurrent",
@@ -217,4 +293,32 @@ var ioFlags = []cli.Flag{
 		Usage:  "Disable HTTP Keep-Alive",
 		Hidden: true,
 	},
+	cli.BoolFlag{
+		Name:  "loopback",
+		Usage: "Run against an in-memory server instead of --host to measure the client overhead. No requests are sent.",
+	},
+	cli.StringFlag{
+		Name:  "loopback.stored",
+		Value: "1GiB",
+		Usage: "Maximum object content kept by the in-memory server. Content beyond this is discarded and read back as zeros.",
+	},
+	cli.DurationFlag{
+		Name:  "loopback.latency",
+		Usage: "Average time the in-memory server waits before each response, to emulate server think time.",
+	},
+	cli.StringFlag{
+		Name:  "loopback.latency.dist",
+		Value: loopback.LatencyFixed,
+		Usage: "Distribution of the in-memory server latency: fixed, uniform, exponential or lognormal.",
+	},
+	cli.Float64Flag{
+		Name:  "loopback.errors",
+		Usage: "Percentage of requests the in-memory server answers with an error.",
+	},
+	cli.StringFlag{
+		Name:  "loopback.errors.codes",
+		Value: "InternalError",
+		Usage: "Weighted list of S3 error codes returned by the in-memory server: InternalError, SlowDown, ServiceUnavailable, AccessDenied or RequestTimeout." +
+			"\n\tExample: --loopback.errors.codes '80% SlowDown, 20% AccessDenied'",
+	},
 }
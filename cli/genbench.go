@@ -0,0 +1,149 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var genBenchFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "10MiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.IntFlag{
+		Name:  "concurrent",
+		Usage: "Generate objects on this many threads. Default is the number of usable CPU cores.",
+	},
+	cli.DurationFlag{
+		Name:  "duration",
+		Value: 10 * time.Second,
+		Usage: "Duration to generate objects.",
+	},
+}
+
+var genBenchCmd = cli.Command{
+	Name:   "generator-bench",
+	Usage:  "measure the speed of the data generator without a server",
+	Action: mainGenBench,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, genBenchFlags, genFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+  -> see https://github.com/minio/warp#generator-speed
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// genBenchResult is the data generated by one thread.
+type genBenchResult struct {
+	objects, bytes int64
+}
+
+// mainGenBench is the entry point for the generator-bench command.
+// Objects are generated and read on each thread until the duration has passed,
+// so the throughput is the upper limit of uploads with the generator options.
+func mainGenBench(ctx *cli.Context) error {
+	threads := getConcurrency(ctx)
+	if threads <= 0 {
+		threads = runtime.GOMAXPROCS(0)
+	}
+	dur := ctx.Duration("duration")
+	if dur <= 0 {
+		fatalIf(errDummy(), "duration must be > 0")
+	}
+	newSrc := newGenSource(ctx, "obj.size")
+
+	setupStart := time.Now()
+	first := newSrc()
+	setup := time.Since(setupStart)
+	console.Println("Generator:", first.String())
+	console.Println("Setup:", setup.Round(time.Microsecond))
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	results := make([]genBenchResult, threads)
+	start := time.Now()
+	deadline := start.Add(dur)
+	var wg sync.WaitGroup
+	for i := range results {
+		src := first
+		if i > 0 {
+			src = newSrc()
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res := &results[i]
+			for time.Now().Before(deadline) {
+				obj := src.Object()
+				if obj == nil {
+					// Sources with a limited number of objects are done.
+					return
+				}
+				n, err := io.Copy(ioutil.Discard, obj.Reader)
+				fatalIf(probe.NewError(err), "Unable to read generated object")
+				res.objects++
+				res.bytes += n
+			}
+		}(i)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	var total genBenchResult
+	for _, r := range results {
+		total.objects += r.objects
+		total.bytes += r.bytes
+	}
+	if total.objects == 0 {
+		fatalIf(errDummy(), "No objects generated")
+	}
+	cores := threads
+	if procs := runtime.GOMAXPROCS(0); procs < cores {
+		cores = procs
+	}
+	secs := elapsed.Seconds()
+	console.Println(fmt.Sprintf("Threads: %d, usable cores: %d", threads, runtime.GOMAXPROCS(0)))
+	console.Println(fmt.Sprintf("Objects: %d, %s in %v", total.objects, humanize.IBytes(uint64(total.bytes)), elapsed.Round(time.Millisecond)))
+	console.Println(fmt.Sprintf("Throughput: %s/s, %.2f obj/s", humanize.IBytes(uint64(float64(total.bytes)/secs)), float64(total.objects)/secs))
+	console.Println(fmt.Sprintf("Per core: %s/s, %.2f obj/s", humanize.IBytes(uint64(float64(total.bytes)/secs/float64(cores))), float64(total.objects)/secs/float64(cores)))
+	console.Println(fmt.Sprintf("Allocations: %s and %d allocations per object, %d GC cycles.",
+		humanize.IBytes((after.TotalAlloc-before.TotalAlloc)/uint64(total.objects)), (after.Mallocs-before.Mallocs)/uint64(total.objects), after.NumGC-before.NumGC))
+	console.Println(fmt.Sprintf("PUT results close to %s/s on this machine are limited by the generator, not the server.",
+		humanize.IBytes(uint64(float64(total.bytes)/secs))))
+	return nil
+}
@@ -18,7 +18,10 @@
 package cli
 
 import (
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
@@ -33,7 +36,109 @@ var genFlags = []cli.Flag{
 	cli.StringFlag{
 		Name:  "obj.generator",
 		Value: "random",
-		Usage: "Use specific data generator. Options: text, random, csv",
+		Usage: "Use specific data generator. Options: text, random, csv, json, parquet, orc, dedup, named, corpus, prose, image, dicom, avro, protobuf, ndjson, logs, genomics, xml, tar, zero, pattern or a source registered by the program",
+	},
+	cli.StringFlag{
+		Name:  "obj.generator.args",
+		Usage: "Configuration passed to a registered data source. Only used with '--obj.generator' set to a registered source",
+	},
+	cli.StringFlag{
+		Name: "obj.key.template",
+		Usage: "Template of object names. Placeholders: {prefix}, {date:layout}, {uuid}, {counter:format}, {rand:length}, {ext}, {name}, {partition} and {tree}." +
+			"\n\tExample: --obj.key.template '{prefix}/{date:2006/01/02}/{uuid}.{ext}'",
+	},
+	cli.IntFlag{
+		Name:  "obj.partitions",
+		Usage: "Spread object names over this many hourly partitions like 'year=2024/month=01/day=02/hour=03', ending at the current hour",
+	},
+	cli.Float64Flag{
+		Name:  "obj.partitions.skew",
+		Value: 1,
+		Usage: "Skew of object names toward recent partitions. A partition h hours old is picked with weight 1/(h+1)^skew. 0 spreads names evenly",
+	},
+	cli.IntFlag{
+		Name:  "obj.tree.depth",
+		Usage: "Place object names in a folder hierarchy this many levels deep, like 'd03/d11/d07'",
+	},
+	cli.IntFlag{
+		Name:  "obj.tree.fanout",
+		Value: 16,
+		Usage: "Number of subfolders of each folder when '--obj.tree.depth' is set",
+	},
+	cli.IntFlag{
+		Name:  "obj.hot.prefixes",
+		Usage: "Place a percentage of object names below this many hot prefixes like 'hot-0003', shared by all threads",
+	},
+	cli.Float64Flag{
+		Name:  "obj.hot.percent",
+		Value: 80,
+		Usage: "Percentage of object names placed below hot prefixes",
+	},
+	cli.Float64Flag{
+		Name:  "obj.hot.skew",
+		Value: 1,
+		Usage: "Skew of object names toward the first hot prefixes. Hot prefix i is picked with weight 1/(i+1)^skew. 0 spreads names evenly",
+	},
+	cli.StringFlag{
+		Name: "obj.extensions",
+		Usage: "Set the extension of object names from a weighted list instead of the extension of the generator. The content is not changed." +
+			"\n\tExample: --obj.extensions '50% .log, 30% .parquet, 20% .jpg'",
+	},
+	cli.StringFlag{
+		Name: "obj.content.types",
+		Usage: "Set the content type of objects from a weighted list instead of the content type of the generator. 'ext' sets it from the extension of object names." +
+			"\n\tExample: --obj.content.types '60% image/jpeg, 30% application/pdf, 10% video/mp4'",
+	},
+	cli.StringFlag{
+		Name:   "cse.key",
+		Usage:  "Encrypt objects on the client with AES-256-GCM with this key of 64 hex digits before upload. The get benchmark decrypts downloaded objects",
+		EnvVar: appNameUC + "_CSE_KEY",
+	},
+	cli.BoolFlag{
+		Name:  "cse.perobject",
+		Usage: "Encrypt each object with a random key, stored in the object encrypted with --cse.key",
+	},
+	cli.StringFlag{
+		Name:  "obj.content.encoding",
+		Usage: "Compress objects with 'gzip' or 'zstd' before upload and set the Content-Encoding. PUT operations are recorded with the size before compression",
+	},
+	cli.IntFlag{
+		Name:  "obj.metadata",
+		Usage: "Add this many x-amz-meta-* user metadata entries to each uploaded object",
+	},
+	cli.IntFlag{
+		Name:  "obj.metadata.keylen",
+		Value: 16,
+		Usage: "Length of user metadata keys",
+	},
+	cli.IntFlag{
+		Name:  "obj.metadata.valuelen",
+		Value: 32,
+		Usage: "Length of user metadata values",
+	},
+	cli.IntFlag{
+		Name:  "obj.metadata.cardinality",
+		Value: 100,
+		Usage: "Number of distinct values of each user metadata key. 0 gives random values for each object",
+	},
+	cli.IntFlag{
+		Name:  "obj.tags",
+		Usage: "Add this many tags to each uploaded object, at most 10",
+	},
+	cli.IntFlag{
+		Name:  "obj.tags.keylen",
+		Value: 16,
+		Usage: "Length of tag keys",
+	},
+	cli.IntFlag{
+		Name:  "obj.tags.valuelen",
+		Value: 32,
+		Usage: "Length of tag values",
+	},
+	cli.IntFlag{
+		Name:  "obj.tags.cardinality",
+		Value: 100,
+		Usage: "Number of distinct values of each tag key. 0 gives random values for each object",
 	},
 	cli.BoolFlag{
 		Name:  "obj.randsize",
@@ -42,14 +147,45 @@ var genFlags = []cli.Flag{
 	cli.StringFlag{
 		Name: "obj.dist",
 		Usage: "Specify a CSV string containing object size distributions such that all percentages add up to 100." +
-			"\n\tFormat: size1:percent1,size2:percent2,etc." +
+			"\n\tFormat: size1:percent1,size2:percent2,etc. or 'percent1% size1, percent2% size2, etc.'" +
 			"\n\tExample: --obj.dist 1KiB:10,4KiB:15,8KiB:15,16KiB:15,32KiB:15,64KiB:10,128KiB:5,256KiB:10,1MiB:5",
 	},
+	cli.Float64Flag{
+		Name:  "obj.zipf",
+		Usage: "Pick object sizes from a Zipf distribution with this exponent, which must be > 1. Sizes are multiples of --obj.zipf.min up to the object size",
+	},
+	cli.StringFlag{
+		Name:  "obj.zipf.min",
+		Value: "4KiB",
+		Usage: "Smallest and most common object size when using --obj.zipf",
+	},
+	cli.StringFlag{
+		Name:  "obj.lognormal.mean",
+		Usage: "Pick object sizes from a log-normal distribution with this mean size, up to the object size",
+	},
+	cli.StringFlag{
+		Name:  "obj.lognormal.stddev",
+		Usage: "Standard deviation of object sizes when using --obj.lognormal.mean. Defaults to the mean",
+	},
+	cli.StringFlag{
+		Name:  "obj.lognormal.min",
+		Value: "1B",
+		Usage: "Smallest object size when using --obj.lognormal.mean",
+	},
 	cli.StringFlag{
 		Name: "obj.comp",
 		Usage: "Integer value for the compression ratio desired on the generated data." +
 			"\n\tExample: A value of 2 will generate data that is 50% compressible.",
 	},
+	cli.StringFlag{
+		Name: "obj.comp.target",
+		Usage: "Compression ratio of the generated data with a specific algorithm, checked by compressing samples. Only used with '--obj.generator text'" +
+			"\n\tExample: 'zstd:2.5'. Supported algorithms: " + strings.Join(generator.CompressionAlgorithms(), ", "),
+	},
+	cli.BoolFlag{
+		Name:  "obj.stream",
+		Usage: "Generate text data while uploading instead of building each object in memory first. Random data is always generated while uploading",
+	},
 	cli.StringFlag{
 		Name:  "obj.comp.window",
 		Usage: "Window size to be used for compression data generation. Default: 256KiB",
@@ -59,6 +195,242 @@ var genFlags = []cli.Flag{
 		Usage: "Adjust compression window size appropriate to a specific algorithm." +
 			"\n\tSupported algorithms: zstd, zlib, brotli, lz4, snappy, gzip",
 	},
+	cli.IntFlag{
+		Name:  "obj.csv.rows",
+		Value: 1000,
+		Usage: "Number of distinct rows in CSV data, repeated until the object size is reached. Only used with '--obj.generator csv'",
+	},
+	cli.IntFlag{
+		Name:  "obj.csv.cols",
+		Value: 25,
+		Usage: "Number of columns in CSV data. Only used with '--obj.generator csv'",
+	},
+	cli.StringFlag{
+		Name:  "obj.csv.delimiter",
+		Value: ",",
+		Usage: "Single character delimiter between CSV fields. Only used with '--obj.generator csv'",
+	},
+	cli.IntFlag{
+		Name:  "obj.json.fields",
+		Value: 10,
+		Usage: "Number of fields in each object of a JSON document. Only used with '--obj.generator json'",
+	},
+	cli.IntFlag{
+		Name:  "obj.json.depth",
+		Value: 3,
+		Usage: "Nesting depth of JSON documents. Only used with '--obj.generator json'",
+	},
+	cli.IntFlag{
+		Name:  "obj.json.cardinality",
+		Value: 1000,
+		Usage: "Number of distinct values of each JSON field, 0 for all different. Only used with '--obj.generator json'",
+	},
+	cli.IntFlag{
+		Name:  "obj.xml.depth",
+		Value: 3,
+		Usage: "Nesting depth of elements in each XML record. Only used with '--obj.generator xml'",
+	},
+	cli.IntFlag{
+		Name:  "obj.xml.children",
+		Value: 3,
+		Usage: "Number of child elements of each XML element above the deepest level. Only used with '--obj.generator xml'",
+	},
+	cli.Float64Flag{
+		Name:  "obj.xml.attributes",
+		Value: 1.5,
+		Usage: "Average number of attributes of each XML element. Only used with '--obj.generator xml'",
+	},
+	cli.IntFlag{
+		Name:  "obj.parquet.columns",
+		Value: 8,
+		Usage: "Number of columns in Parquet files. Only used with '--obj.generator parquet'",
+	},
+	cli.StringFlag{
+		Name:  "obj.parquet.types",
+		Value: "int64,string,double,boolean",
+		Usage: "Comma separated Parquet column types, repeated for all columns. Supported: boolean, int32, int64, float, double, string",
+	},
+	cli.IntFlag{
+		Name:  "obj.parquet.rowgroup",
+		Value: 10000,
+		Usage: "Maximum number of rows in each Parquet row group",
+	},
+	cli.StringFlag{
+		Name:  "obj.parquet.codec",
+		Value: "snappy",
+		Usage: "Compression codec of Parquet pages. Supported: none, snappy, gzip, zstd",
+	},
+	cli.IntFlag{
+		Name:  "obj.orc.columns",
+		Value: 8,
+		Usage: "Number of columns in ORC files. Only used with '--obj.generator orc'",
+	},
+	cli.StringFlag{
+		Name:  "obj.orc.types",
+		Value: "bigint,string,double,boolean",
+		Usage: "Comma separated ORC column types, repeated for all columns. Supported: boolean, int, bigint, float, double, string, timestamp",
+	},
+	cli.StringFlag{
+		Name:  "obj.orc.stripe",
+		Value: "64MiB",
+		Usage: "Approximate uncompressed size of each ORC stripe",
+	},
+	cli.StringFlag{
+		Name:  "obj.orc.codec",
+		Value: "zlib",
+		Usage: "Compression codec of ORC streams. Supported: none, zlib, snappy, zstd",
+	},
+	cli.Float64Flag{
+		Name:  "obj.dedup.ratio",
+		Value: 4,
+		Usage: "Deduplication ratio across objects, for example 4 for 4:1. Only used with '--obj.generator dedup'",
+	},
+	cli.StringFlag{
+		Name:  "obj.dedup.chunk",
+		Value: "64KiB",
+		Usage: "Size of the chunks reused across objects. Only used with '--obj.generator dedup'",
+	},
+	cli.Int64Flag{
+		Name:  "obj.seed",
+		Usage: "Seed the object content is derived from together with the object name and size. Only used with '--obj.generator named'",
+	},
+	cli.IntFlag{
+		Name:  "obj.prose.vocab",
+		Value: 10000,
+		Usage: "Number of distinct words in the text. Only used with '--obj.generator prose'",
+	},
+	cli.StringFlag{
+		Name:  "obj.image.format",
+		Value: generator.ImageJPEG,
+		Usage: "Image format. Supported: jpeg, png. Only used with '--obj.generator image'",
+	},
+	cli.StringFlag{
+		Name:  "obj.image.size",
+		Value: "1024x768",
+		Usage: "Image resolution as WIDTHxHEIGHT pixels. Only used with '--obj.generator image'",
+	},
+	cli.IntFlag{
+		Name:  "obj.image.quality",
+		Value: 85,
+		Usage: "JPEG quality between 1 and 100. Only used with '--obj.generator image'",
+	},
+	cli.StringFlag{
+		Name:  "obj.dicom.size",
+		Value: "512x512",
+		Usage: "DICOM image resolution as COLUMNSxROWS pixels. Only used with '--obj.generator dicom'",
+	},
+	cli.IntFlag{
+		Name:  "obj.dicom.bits",
+		Value: 16,
+		Usage: "Bits allocated for each DICOM pixel. Supported: 8, 16. Only used with '--obj.generator dicom'",
+	},
+	cli.StringFlag{
+		Name:  "obj.avro.schema",
+		Usage: "File with the Avro schema of records as JSON. Only used with '--obj.generator avro'. Default is a log event record",
+	},
+	cli.IntFlag{
+		Name:  "obj.avro.records",
+		Value: 1000,
+		Usage: "Number of records in each Avro file. Only used with '--obj.generator avro'",
+	},
+	cli.StringFlag{
+		Name:  "obj.avro.codec",
+		Value: generator.AvroNull,
+		Usage: "Compression codec of Avro blocks. Supported: null, deflate, snappy, zstandard",
+	},
+	cli.IntFlag{
+		Name:  "obj.ndjson.records",
+		Value: 1000,
+		Usage: "Number of records in each NDJSON object. Only used with '--obj.generator ndjson'",
+	},
+	cli.IntFlag{
+		Name:  "obj.ndjson.fields",
+		Value: 10,
+		Usage: "Number of fields in each NDJSON record. Only used with '--obj.generator ndjson'",
+	},
+	cli.StringFlag{
+		Name:  "obj.ndjson.length",
+		Value: "4-32",
+		Usage: "Length of string values in NDJSON records as MIN-MAX or a single length. Only used with '--obj.generator ndjson'",
+	},
+	cli.StringFlag{
+		Name:  "obj.ndjson.length.dist",
+		Value: generator.NDJSONLengthUniform,
+		Usage: "Distribution of string value lengths. Supported: uniform, exp, normal. Only used with '--obj.generator ndjson'",
+	},
+	cli.StringFlag{
+		Name:  "obj.protobuf.descriptor",
+		Usage: "File with a FileDescriptorSet written by 'protoc --descriptor_set_out --include_imports'. Only used with '--obj.generator protobuf'. Default is a log event message",
+	},
+	cli.StringFlag{
+		Name:  "obj.protobuf.message",
+		Usage: "Full name of the message type in '--obj.protobuf.descriptor'. Default is the first message of the last file",
+	},
+	cli.IntFlag{
+		Name:  "obj.protobuf.messages",
+		Value: 1000,
+		Usage: "Number of messages in each Protobuf object. Only used with '--obj.generator protobuf'",
+	},
+	cli.StringFlag{
+		Name:  "obj.protobuf.size",
+		Value: "64B-1KiB",
+		Usage: "Size of Protobuf messages as MIN-MAX or a single size. Only used with '--obj.generator protobuf'",
+	},
+	cli.StringFlag{
+		Name:  "obj.protobuf.size.dist",
+		Value: generator.ProtobufSizeUniform,
+		Usage: "Distribution of message sizes. Supported: uniform, exp, normal. Only used with '--obj.generator protobuf'",
+	},
+	cli.StringFlag{
+		Name:  "obj.logs.format",
+		Value: generator.LogFormatNginx,
+		Usage: "Format of log lines. Supported: nginx, apache, json. Only used with '--obj.generator logs'",
+	},
+	cli.IntFlag{
+		Name:  "obj.logs.lines",
+		Value: 10000,
+		Usage: "Number of lines in each log object. Only used with '--obj.generator logs'",
+	},
+	cli.StringFlag{
+		Name:  "obj.genomics.format",
+		Value: generator.GenomicsFASTQ,
+		Usage: "Format of genomics objects. Supported: fastq, fasta. Only used with '--obj.generator genomics'",
+	},
+	cli.StringFlag{
+		Name:  "obj.genomics.readlen",
+		Value: "150",
+		Usage: "Length of reads in bases as MIN-MAX or a single length. Only used with '--obj.generator genomics'",
+	},
+	cli.IntFlag{
+		Name:  "obj.tar.files",
+		Value: 100,
+		Usage: "Number of member files in each TAR archive. Only used with '--obj.generator tar'",
+	},
+	cli.StringFlag{
+		Name:  "obj.tar.size",
+		Value: "1KiB-64KiB",
+		Usage: "Size of member files in TAR archives as MIN-MAX or a single size. Only used with '--obj.generator tar'",
+	},
+	cli.BoolFlag{
+		Name:  "obj.tar.extract",
+		Usage: "Ask the server to extract uploaded TAR archives, like MinIO snowball uploads. Only used with '--obj.generator tar'",
+	},
+	cli.StringFlag{
+		Name:  "obj.pattern",
+		Usage: "Bytes repeated in objects, as hex like '0xdeadbeef' or as text. Only used with '--obj.generator pattern'",
+	},
+	cli.StringFlag{
+		Name:  "obj.corpus.dir",
+		Usage: "Local directory with files to upload. Relative paths are used as object names. Only used with '--obj.generator corpus'",
+	},
+	cli.BoolFlag{
+		Name:  "obj.corpus.shuffle",
+		Usage: "Upload corpus files in random order instead of sorted by path",
+	},
+	cli.BoolFlag{
+		Name:  "obj.corpus.loop",
+		Usage: "Upload corpus files again when all have been uploaded. Required by other benchmarks than 'put'",
+	},
 }
 
 func newGenSourceCSV(ctx *cli.Context) func() generator.Source {
@@ -67,7 +439,7 @@ func newGenSourceCSV(ctx *cli.Context) func() generator.Source {
 		prefixSize = 0
 	}
 
-	g := generator.WithCSV().Size(25, 1000)
+	g := generator.WithCSVData().Size(25, 1000)
 
 	size, err := toSize(ctx.String("obj.size"))
 	fatalIf(probe.NewError(err), "Invalid obj.size specified")
@@ -92,11 +464,151 @@ func newGenSource(ctx *cli.Context, sizeField string) func() generator.Source {
 	case "random":
 		g = generator.WithRandomData()
 	case "csv":
-		g = generator.WithCSV().Size(25, 1000)
+		delim := ctx.String("obj.csv.delimiter")
+		if delim == `\t` {
+			delim = "\t"
+		}
+		if len(delim) != 1 {
+			fatalIf(errDummy(), "obj.csv.delimiter must be a single character")
+		}
+		g = generator.WithCSVData().
+			Size(ctx.Int("obj.csv.cols"), ctx.Int("obj.csv.rows")).
+			Comma(delim[0])
 	case "text":
 		g = generator.WithTextData()
+	case "json":
+		g = generator.WithJSONData().
+			Fields(ctx.Int("obj.json.fields")).
+			Depth(ctx.Int("obj.json.depth")).
+			Cardinality(ctx.Int("obj.json.cardinality"))
+	case "xml":
+		g = generator.WithXMLData().
+			Depth(ctx.Int("obj.xml.depth")).
+			Children(ctx.Int("obj.xml.children")).
+			Attributes(ctx.Float64("obj.xml.attributes"))
+	case "parquet":
+		g = generator.WithParquetData().
+			Columns(ctx.Int("obj.parquet.columns")).
+			Types(strings.Split(ctx.String("obj.parquet.types"), ",")...).
+			RowGroupRows(ctx.Int("obj.parquet.rowgroup")).
+			Codec(ctx.String("obj.parquet.codec"))
+	case "orc":
+		stripe, err := toSize(ctx.String("obj.orc.stripe"))
+		fatalIf(probe.NewError(err), "Invalid obj.orc.stripe specified")
+		g = generator.WithORCData().
+			Columns(ctx.Int("obj.orc.columns")).
+			Types(strings.Split(ctx.String("obj.orc.types"), ",")...).
+			StripeSize(int64(stripe)).
+			Codec(ctx.String("obj.orc.codec"))
+	case "dedup":
+		chunk, err := toSize(ctx.String("obj.dedup.chunk"))
+		fatalIf(probe.NewError(err), "Invalid obj.dedup.chunk specified")
+		g = generator.WithDedupData().
+			Ratio(ctx.Float64("obj.dedup.ratio")).
+			ChunkSize(int(chunk))
+	case "named":
+		g = generator.WithNamedData().Seed(ctx.Int64("obj.seed"))
+	case "prose":
+		g = generator.WithProseData().Vocabulary(ctx.Int("obj.prose.vocab"))
+	case "image":
+		var width, height int
+		if _, err := fmt.Sscanf(ctx.String("obj.image.size"), "%dx%d", &width, &height); err != nil {
+			fatalIf(probe.NewError(err), "Invalid obj.image.size specified")
+		}
+		g = generator.WithImageData().
+			Format(strings.ToLower(ctx.String("obj.image.format"))).
+			Resolution(width, height).
+			Quality(ctx.Int("obj.image.quality"))
+	case "dicom":
+		var width, height int
+		if _, err := fmt.Sscanf(ctx.String("obj.dicom.size"), "%dx%d", &width, &height); err != nil {
+			fatalIf(probe.NewError(err), "Invalid obj.dicom.size specified")
+		}
+		g = generator.WithDICOMData().
+			Resolution(width, height).
+			Bits(ctx.Int("obj.dicom.bits"))
+	case "avro":
+		avro := generator.WithAvroData().
+			Records(ctx.Int("obj.avro.records")).
+			Codec(ctx.String("obj.avro.codec"))
+		if fn := ctx.String("obj.avro.schema"); fn != "" {
+			schema, err := os.ReadFile(fn)
+			fatalIf(probe.NewError(err), "Unable to read obj.avro.schema")
+			avro = avro.Schema(string(schema))
+		}
+		g = avro
+	case "ndjson":
+		var minLen, maxLen int
+		if _, err := fmt.Sscanf(ctx.String("obj.ndjson.length"), "%d-%d", &minLen, &maxLen); err != nil {
+			if _, err := fmt.Sscanf(ctx.String("obj.ndjson.length"), "%d", &minLen); err != nil {
+				fatalIf(probe.NewError(err), "Invalid obj.ndjson.length specified")
+			}
+			maxLen = minLen
+		}
+		g = generator.WithNDJSONData().
+			Records(ctx.Int("obj.ndjson.records")).
+			Fields(ctx.Int("obj.ndjson.fields")).
+			FieldLength(minLen, maxLen).
+			FieldLengthDist(strings.ToLower(ctx.String("obj.ndjson.length.dist")))
+	case "protobuf":
+		minSize, maxSize, err := parseSizeRange(ctx.String("obj.protobuf.size"))
+		fatalIf(probe.NewError(err), "Invalid obj.protobuf.size specified")
+		protobuf := generator.WithProtobufData().
+			Messages(ctx.Int("obj.protobuf.messages")).
+			MessageSize(int(minSize), int(maxSize)).
+			MessageSizeDist(strings.ToLower(ctx.String("obj.protobuf.size.dist")))
+		if fn := ctx.String("obj.protobuf.descriptor"); fn != "" {
+			set, err := os.ReadFile(fn)
+			fatalIf(probe.NewError(err), "Unable to read obj.protobuf.descriptor")
+			protobuf = protobuf.Descriptor(set, ctx.String("obj.protobuf.message"))
+		}
+		g = protobuf
+	case "logs":
+		g = generator.WithLogData().
+			Format(strings.ToLower(ctx.String("obj.logs.format"))).
+			Lines(ctx.Int("obj.logs.lines"))
+	case "genomics":
+		var minLen, maxLen int
+		if _, err := fmt.Sscanf(ctx.String("obj.genomics.readlen"), "%d-%d", &minLen, &maxLen); err != nil {
+			if _, err := fmt.Sscanf(ctx.String("obj.genomics.readlen"), "%d", &minLen); err != nil {
+				fatalIf(probe.NewError(err), "Invalid obj.genomics.readlen specified")
+			}
+			maxLen = minLen
+		}
+		g = generator.WithGenomicsData().
+			Format(strings.ToLower(ctx.String("obj.genomics.format"))).
+			ReadLength(minLen, maxLen)
+	case "tar":
+		minSize, maxSize, err := parseSizeRange(ctx.String("obj.tar.size"))
+		fatalIf(probe.NewError(err), "Invalid obj.tar.size specified")
+		g = generator.WithTarData().
+			Files(ctx.Int("obj.tar.files")).
+			FileSize(minSize, maxSize)
+	case "zero":
+		g = generator.WithPatternData()
+	case "pattern":
+		if ctx.String("obj.pattern") == "" {
+			fatalIf(errDummy(), "obj.pattern must be set with '--obj.generator pattern'")
+		}
+		pattern, err := generator.ParsePattern(ctx.String("obj.pattern"))
+		fatalIf(probe.NewError(err), "Invalid obj.pattern specified")
+		g = generator.WithPatternData().Pattern(pattern)
+	case "corpus":
+		g = generator.WithCorpusData(ctx.String("obj.corpus.dir")).
+			Shuffle(ctx.Bool("obj.corpus.shuffle")).
+			Loop(ctx.Bool("obj.corpus.loop"))
 	default:
-		err := errors.New("unknown generator type:" + ctx.String("obj.generator"))
+		name := ctx.String("obj.generator")
+		for _, registered := range generator.RegisteredSources() {
+			if name == registered {
+				g = generator.WithSource(name).Args(ctx.String("obj.generator.args"))
+				break
+			}
+		}
+		if g != nil {
+			break
+		}
+		err := errors.New("unknown generator type:" + name)
 		fatal(probe.NewError(err), "Invalid -generator parameter")
 		return nil
 	}
@@ -112,11 +624,43 @@ func newGenSource(ctx *cli.Context, sizeField string) func() generator.Source {
 	return src
 }
 
+// clientEncryption returns the client side encryption set in the context, or nil.
+func clientEncryption(ctx *cli.Context) *generator.ClientEncryption {
+	if ctx.String("cse.key") == "" {
+		return nil
+	}
+	key, err := hex.DecodeString(ctx.String("cse.key"))
+	fatalIf(probe.NewError(err), "Invalid cse.key specified")
+	cse, err := generator.NewClientEncryption(key, ctx.Bool("cse.perobject"))
+	fatalIf(probe.NewError(err), "Invalid cse.key specified")
+	return cse
+}
+
 // toSize converts a size indication to bytes.
 func toSize(size string) (uint64, error) {
 	return humanize.ParseBytes(size)
 }
 
+// parseSizeRange parses a size range given as MIN-MAX or a single size.
+func parseSizeRange(s string) (minSize, maxSize int64, err error) {
+	lo, hi, ok := strings.Cut(s, "-")
+	if !ok {
+		hi = lo
+	}
+	minV, err := toSize(strings.TrimSpace(lo))
+	if err != nil {
+		return 0, 0, err
+	}
+	maxV, err := toSize(strings.TrimSpace(hi))
+	if err != nil {
+		return 0, 0, err
+	}
+	if maxV < minV {
+		return 0, 0, fmt.Errorf("maximum size %d is below minimum %d", maxV, minV)
+	}
+	return int64(minV), int64(maxV), nil
+}
+
 // validates whether generator flags are compatible.
 func validateGeneratorFlags(ctx *cli.Context) {
 	if ctx.Bool("obj.randsize") && ctx.String("obj.dist") != "" {
@@ -124,8 +668,95 @@ func validateGeneratorFlags(ctx *cli.Context) {
 		fatalIf(probe.NewError(err), "Incompatible generator parameters.")
 	}
 
-	if ctx.String("obj.comp") != "" && ctx.String("obj.generator") != "text" {
-		err := errors.New("compression is only applicable to generator type 'text'. Specify the option: '--obj.generator text'")
+	sizeModels := 0
+	for _, set := range []bool{ctx.Bool("obj.randsize"), ctx.String("obj.dist") != "", ctx.Float64("obj.zipf") != 0, ctx.String("obj.lognormal.mean") != ""} {
+		if set {
+			sizeModels++
+		}
+	}
+	if sizeModels > 1 {
+		err := errors.New("specify only one of 'obj.randsize', 'obj.dist', 'obj.zipf' and 'obj.lognormal.mean' options")
+		fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+	}
+
+	if ctx.String("obj.generator") == "image" && sizeModels > 0 {
+		err := errors.New("object sizes of 'image' generator are the encoded image sizes; size options cannot be used. Use '--obj.image.size'")
+		fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+	}
+
+	if ctx.String("obj.generator") == "dicom" && sizeModels > 0 {
+		err := errors.New("object sizes of 'dicom' generator depend on the image size; size options cannot be used. Use '--obj.dicom.size' and '--obj.dicom.bits'")
+		fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+	}
+
+	if ctx.String("obj.generator") == "avro" && sizeModels > 0 {
+		err := errors.New("object sizes of 'avro' generator depend on the number of records; size options cannot be used. Use '--obj.avro.records'")
+		fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+	}
+
+	if ctx.String("obj.generator") == "ndjson" && sizeModels > 0 {
+		err := errors.New("object sizes of 'ndjson' generator depend on the number of records; size options cannot be used. Use '--obj.ndjson.records'")
+		fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+	}
+
+	if ctx.String("obj.generator") == "protobuf" && sizeModels > 0 {
+		err := errors.New("object sizes of 'protobuf' generator depend on the number of messages; size options cannot be used. Use '--obj.protobuf.messages' and '--obj.protobuf.size'")
+		fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+	}
+
+	if ctx.String("obj.generator") == "logs" && sizeModels > 0 {
+		err := errors.New("object sizes of 'logs' generator depend on the number of lines; size options cannot be used. Use '--obj.logs.lines'")
+		fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+	}
+
+	if ctx.String("obj.generator") == "tar" && sizeModels > 0 {
+		err := errors.New("object sizes of 'tar' generator depend on the member files; size options cannot be used. Use '--obj.tar.files' and '--obj.tar.size'")
+		fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+	}
+
+	if ctx.Bool("obj.tar.extract") && ctx.String("obj.generator") != "tar" {
+		err := errors.New("'--obj.tar.extract' requires '--obj.generator tar'")
+		fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+	}
+
+	if ctx.String("obj.generator") == "corpus" {
+		if ctx.String("obj.corpus.dir") == "" {
+			err := errors.New("'--obj.generator corpus' requires '--obj.corpus.dir'")
+			fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+		}
+		if sizeModels > 0 {
+			err := errors.New("object sizes of 'corpus' generator are the file sizes; size options cannot be used")
+			fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+		}
+		if !ctx.Bool("obj.corpus.loop") && ctx.Command.Name != "put" {
+			err := errors.New("only 'put' can upload corpus files once; specify '--obj.corpus.loop'")
+			fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+		}
+	}
+
+	if gen := ctx.String("obj.generator"); ctx.String("obj.comp") != "" && gen != "text" && gen != "json" && gen != "xml" && gen != "dedup" {
+		err := errors.New("compression is only applicable to generator types 'text', 'json', 'xml' and 'dedup'. Specify one of them, for example: '--obj.generator json'")
+		fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+	}
+
+	if ctx.String("obj.comp.target") != "" {
+		if ctx.String("obj.comp") != "" {
+			err := errors.New("specify either 'obj.comp' or 'obj.comp.target' options, not both")
+			fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+		}
+		if ctx.String("obj.generator") != "text" {
+			err := errors.New("compression targets are only applicable to generator type 'text'. Specify the option: '--obj.generator text'")
+			fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+		}
+	}
+
+	if gen := ctx.String("obj.generator"); ctx.Bool("obj.stream") && gen != "text" && gen != "random" {
+		err := errors.New("streaming is only applicable to generator types 'text' and 'random'")
+		fatalIf(probe.NewError(err), "Incompatible generator parameters.")
+	}
+
+	if ctx.String("cse.key") != "" && ctx.String("obj.content.encoding") != "" {
+		err := errors.New("specify either 'cse.key' or 'obj.content.encoding' options, not both. Encrypted objects cannot be decoded by the server")
 		fatalIf(probe.NewError(err), "Incompatible generator parameters.")
 	}
 
@@ -146,6 +777,12 @@ func applyGenerators(g generator.OptionApplier, ctx *cli.Context, prefixSize int
 
 	compWindow := getCompWindow(ctx)
 
+	var compTarget generator.CompressionTarget
+	if ctx.String("obj.comp.target") != "" {
+		compTarget, err = generator.ParseCompressionTarget(ctx.String("obj.comp.target"))
+		fatalIf(probe.NewError(err), "Invalid obj.comp.target specified")
+	}
+
 	if ctx.String("obj.dist") != "" {
 		sizesArr := parseDisrtibutionSizes(ctx)
 
@@ -158,6 +795,77 @@ func applyGenerators(g generator.OptionApplier, ctx *cli.Context, prefixSize int
 			generator.WithSizeDistribution(sizesArr),
 			generator.WithCompression(compRatio),
 			generator.WithCompressionWindow(int64(compWindow)),
+			generator.WithCompressionTarget(compTarget),
+			generator.WithStreaming(ctx.Bool("obj.stream")),
+			generator.WithKeyTemplate(ctx.String("obj.key.template")),
+			generator.WithPartitions(ctx.Int("obj.partitions"), ctx.Float64("obj.partitions.skew")),
+			generator.WithPrefixTree(ctx.Int("obj.tree.depth"), ctx.Int("obj.tree.fanout")),
+			generator.WithHotPrefixes(ctx.Int("obj.hot.prefixes"), ctx.Float64("obj.hot.percent"), ctx.Float64("obj.hot.skew")),
+			generator.WithMetadata(ctx.Int("obj.metadata"), ctx.Int("obj.metadata.keylen"), ctx.Int("obj.metadata.valuelen"), ctx.Int("obj.metadata.cardinality")),
+			generator.WithTags(ctx.Int("obj.tags"), ctx.Int("obj.tags.keylen"), ctx.Int("obj.tags.valuelen"), ctx.Int("obj.tags.cardinality")),
+			generator.WithContentTypes(ctx.String("obj.content.types")),
+			generator.WithExtensions(ctx.String("obj.extensions")),
+			generator.WithContentEncoding(ctx.String("obj.content.encoding")),
+			generator.WithClientEncryption(clientEncryption(ctx)),
+		)
+		return src, err
+	} else if alpha := ctx.Float64("obj.zipf"); alpha != 0 {
+		minSize, err := toSize(ctx.String("obj.zipf.min"))
+		fatalIf(probe.NewError(err), "Invalid obj.zipf.min specified")
+
+		validateCompParams(compRatio, int64(minSize), compWindow)
+
+		src, err := generator.NewFn(g.Apply(),
+			generator.WithCustomPrefix(ctx.String("prefix")),
+			generator.WithPrefixSize(prefixSize),
+			generator.WithZipfSize(alpha, int64(minSize), int64(size)),
+			generator.WithCompression(compRatio),
+			generator.WithCompressionWindow(int64(compWindow)),
+			generator.WithCompressionTarget(compTarget),
+			generator.WithStreaming(ctx.Bool("obj.stream")),
+			generator.WithKeyTemplate(ctx.String("obj.key.template")),
+			generator.WithPartitions(ctx.Int("obj.partitions"), ctx.Float64("obj.partitions.skew")),
+			generator.WithPrefixTree(ctx.Int("obj.tree.depth"), ctx.Int("obj.tree.fanout")),
+			generator.WithHotPrefixes(ctx.Int("obj.hot.prefixes"), ctx.Float64("obj.hot.percent"), ctx.Float64("obj.hot.skew")),
+			generator.WithMetadata(ctx.Int("obj.metadata"), ctx.Int("obj.metadata.keylen"), ctx.Int("obj.metadata.valuelen"), ctx.Int("obj.metadata.cardinality")),
+			generator.WithTags(ctx.Int("obj.tags"), ctx.Int("obj.tags.keylen"), ctx.Int("obj.tags.valuelen"), ctx.Int("obj.tags.cardinality")),
+			generator.WithContentTypes(ctx.String("obj.content.types")),
+			generator.WithExtensions(ctx.String("obj.extensions")),
+			generator.WithContentEncoding(ctx.String("obj.content.encoding")),
+			generator.WithClientEncryption(clientEncryption(ctx)),
+		)
+		return src, err
+	} else if ctx.String("obj.lognormal.mean") != "" {
+		mean, err := toSize(ctx.String("obj.lognormal.mean"))
+		fatalIf(probe.NewError(err), "Invalid obj.lognormal.mean specified")
+		stddev := mean
+		if ctx.String("obj.lognormal.stddev") != "" {
+			stddev, err = toSize(ctx.String("obj.lognormal.stddev"))
+			fatalIf(probe.NewError(err), "Invalid obj.lognormal.stddev specified")
+		}
+		minSize, err := toSize(ctx.String("obj.lognormal.min"))
+		fatalIf(probe.NewError(err), "Invalid obj.lognormal.min specified")
+
+		validateCompParams(compRatio, int64(minSize), compWindow)
+
+		src, err := generator.NewFn(g.Apply(),
+			generator.WithCustomPrefix(ctx.String("prefix")),
+			generator.WithPrefixSize(prefixSize),
+			generator.WithLogNormalSize(int64(mean), int64(stddev), int64(minSize), int64(size)),
+			generator.WithCompression(compRatio),
+			generator.WithCompressionWindow(int64(compWindow)),
+			generator.WithCompressionTarget(compTarget),
+			generator.WithStreaming(ctx.Bool("obj.stream")),
+			generator.WithKeyTemplate(ctx.String("obj.key.template")),
+			generator.WithPartitions(ctx.Int("obj.partitions"), ctx.Float64("obj.partitions.skew")),
+			generator.WithPrefixTree(ctx.Int("obj.tree.depth"), ctx.Int("obj.tree.fanout")),
+			generator.WithHotPrefixes(ctx.Int("obj.hot.prefixes"), ctx.Float64("obj.hot.percent"), ctx.Float64("obj.hot.skew")),
+			generator.WithMetadata(ctx.Int("obj.metadata"), ctx.Int("obj.metadata.keylen"), ctx.Int("obj.metadata.valuelen"), ctx.Int("obj.metadata.cardinality")),
+			generator.WithTags(ctx.Int("obj.tags"), ctx.Int("obj.tags.keylen"), ctx.Int("obj.tags.valuelen"), ctx.Int("obj.tags.cardinality")),
+			generator.WithContentTypes(ctx.String("obj.content.types")),
+			generator.WithExtensions(ctx.String("obj.extensions")),
+			generator.WithContentEncoding(ctx.String("obj.content.encoding")),
+			generator.WithClientEncryption(clientEncryption(ctx)),
 		)
 		return src, err
 	} else {
@@ -174,6 +882,18 @@ func applyGenerators(g generator.OptionApplier, ctx *cli.Context, prefixSize int
 			generator.WithRandomSize(ctx.Bool("obj.randsize")),
 			generator.WithCompression(compRatio),
 			generator.WithCompressionWindow(int64(compWindow)),
+			generator.WithCompressionTarget(compTarget),
+			generator.WithStreaming(ctx.Bool("obj.stream")),
+			generator.WithKeyTemplate(ctx.String("obj.key.template")),
+			generator.WithPartitions(ctx.Int("obj.partitions"), ctx.Float64("obj.partitions.skew")),
+			generator.WithPrefixTree(ctx.Int("obj.tree.depth"), ctx.Int("obj.tree.fanout")),
+			generator.WithHotPrefixes(ctx.Int("obj.hot.prefixes"), ctx.Float64("obj.hot.percent"), ctx.Float64("obj.hot.skew")),
+			generator.WithMetadata(ctx.Int("obj.metadata"), ctx.Int("obj.metadata.keylen"), ctx.Int("obj.metadata.valuelen"), ctx.Int("obj.metadata.cardinality")),
+			generator.WithTags(ctx.Int("obj.tags"), ctx.Int("obj.tags.keylen"), ctx.Int("obj.tags.valuelen"), ctx.Int("obj.tags.cardinality")),
+			generator.WithContentTypes(ctx.String("obj.content.types")),
+			generator.WithExtensions(ctx.String("obj.extensions")),
+			generator.WithContentEncoding(ctx.String("obj.content.encoding")),
+			generator.WithClientEncryption(clientEncryption(ctx)),
 		)
 		return src, err
 	}
@@ -261,37 +981,52 @@ sample:
 	]
 */
 func parseDisrtibutionSizes(ctx *cli.Context) []int64 {
+	sizesArr, err := parseSizeDistribution(ctx.String("obj.dist"))
+	fatalIf(probe.NewError(err), "Invalid size distribution.")
+	return sizesArr
+}
+
+// parseSizeDistribution returns 100 sizes based on the distribution percentages provided.
+// Each comma separated element is either 'size:percent' or 'percent% size',
+// so histograms like '30% 4KiB, 50% 1MiB, 20% 64MiB' can be given.
+func parseSizeDistribution(dist string) ([]int64, error) {
 	sizesArr := []int64{}
 
-	distArr := strings.Split(ctx.String("obj.dist"), ",")
+	distArr := strings.Split(dist, ",")
 	for i := 0; i < len(distArr); i++ {
-		distElement := strings.Split(distArr[i], ":")
-
-		if len(distElement) != 2 {
-			err := errors.New("distribution should be of the format 'size:percent' (Ex: 4KiB:10). Received: " + distArr[i])
-			fatalIf(probe.NewError(err), "Invalid size distribution.")
+		var size, percent string
+		if fields := strings.Fields(distArr[i]); len(fields) == 2 && strings.HasSuffix(fields[0], "%") {
+			size, percent = fields[1], strings.TrimSuffix(fields[0], "%")
+		} else {
+			distElement := strings.Split(strings.TrimSpace(distArr[i]), ":")
+			if len(distElement) != 2 {
+				return nil, errors.New("distribution should be of the format 'size:percent' or 'percent% size' (Ex: 4KiB:10). Received: " + distArr[i])
+			}
+			size, percent = distElement[0], distElement[1]
 		}
 
-		percentInt, err := strconv.Atoi(distElement[1])
-		fatalIf(probe.NewError(err), "Failed to convert distribution percentage to an integer. Received: "+distElement[1])
+		percentInt, err := strconv.Atoi(percent)
+		if err != nil {
+			return nil, errors.New("failed to convert distribution percentage to an integer. Received: " + percent)
+		}
 
-		if int(percentInt) <= 0 || int(percentInt) >= 100 {
-			err := errors.New("distribution percentage should be an integer value greater than 0 and less than 100. Received: " + distElement[1])
-			fatalIf(probe.NewError(err), "Invalid distribution percentage.")
+		if percentInt <= 0 || percentInt >= 100 {
+			return nil, errors.New("distribution percentage should be an integer value greater than 0 and less than 100. Received: " + percent)
 		}
 
-		sizeInBytes, err := toSize(distElement[0])
-		fatalIf(probe.NewError(err), "Failed to convert human readable size to bytes.")
+		sizeInBytes, err := toSize(size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert human readable size to bytes: %w", err)
+		}
 
-		for j := 0; j < int(percentInt); j++ {
+		for j := 0; j < percentInt; j++ {
 			sizesArr = append(sizesArr, int64(sizeInBytes))
 		}
 	}
 
 	if len(sizesArr) != 100 {
-		err := errors.New("distribution percentages should add up to 100. Received: " + strconv.Itoa(len(sizesArr)))
-		fatalIf(probe.NewError(err), "Invalid distribution percentage.")
+		return nil, errors.New("distribution percentages should add up to 100. Received: " + strconv.Itoa(len(sizesArr)))
 	}
 
-	return sizesArr
+	return sizesArr, nil
 }
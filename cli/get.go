@@ -19,6 +19,7 @@ package cli
 
 import (
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/pkg/console"
 	"github.com/minio/warp/pkg/bench"
@@ -39,11 +40,28 @@ var getFlags = []cli.Flag{
 		Name:  "range",
 		Usage: "Do ranged get operations. Will request with random offset and length.",
 	},
+	cli.StringFlag{
+		Name:  "range.pattern",
+		Value: bench.RangeRandom,
+		Usage: "Offsets of ranged get operations. Options: random, sequential, strided. Only used with --range",
+	},
+	cli.StringFlag{
+		Name:  "range.size",
+		Usage: "Size of ranges, or a distribution like '80% 8KiB, 20% 1MiB-4MiB'. Random up to the object size if not set. Only used with --range",
+	},
+	cli.StringFlag{
+		Name:  "range.stride",
+		Usage: "Distance between the start of ranges with '--range.pattern=strided'",
+	},
 	cli.IntFlag{
 		Name:  "versions",
 		Value: 1,
 		Usage: "Number of versions to upload. If more than 1, versioned listing will be benchmarked",
 	},
+	cli.BoolFlag{
+		Name:  "verify",
+		Usage: "Verify the content of downloaded objects. Requires '--obj.generator named'",
+	},
 }
 
 var getCmd = cli.Command{
@@ -72,7 +90,7 @@ func mainGet(ctx *cli.Context) error {
 	b := bench.Get{
 		Common: bench.Common{
 			Client:      newClient(ctx),
-			Concurrency: ctx.Int("concurrent"),
+			Concurrency: getConcurrency(ctx),
 			Source:      src,
 			Bucket:      ctx.String("bucket"),
 			Location:    "",
@@ -82,7 +100,13 @@ func mainGet(ctx *cli.Context) error {
 		RandomRanges:  ctx.Bool("range"),
 		CreateObjects: ctx.Int("objects"),
 		GetOpts:       minio.GetObjectOptions{ServerSideEncryption: sse},
+		Ranges:        rangeOptions(ctx),
 	}
+	if ctx.Bool("verify") {
+		seed := ctx.Int64("obj.seed")
+		b.VerifySeed = &seed
+	}
+	b.Decrypt = clientEncryption(ctx)
 	return runBench(ctx, &b)
 }
 
@@ -93,6 +117,44 @@ func checkGetSyntax(ctx *cli.Context) {
 	if ctx.Int("versions") < 1 {
 		console.Fatal("At least one version must be tested")
 	}
+	if !ctx.Bool("range") && (ctx.String("range.pattern") != bench.RangeRandom || ctx.String("range.size") != "" || ctx.String("range.stride") != "") {
+		fatalIf(errDummy(), "--range.pattern, --range.size and --range.stride require --range")
+	}
+	if ctx.Bool("verify") {
+		if ctx.String("obj.generator") != "named" {
+			fatalIf(errDummy(), "--verify requires '--obj.generator named'")
+		}
+		if ctx.Int("versions") > 1 {
+			fatalIf(errDummy(), "--verify cannot be used with more than one version")
+		}
+		if ctx.Bool("range") && ctx.String("cse.key") != "" {
+			fatalIf(errDummy(), "--verify cannot be used with --range and --cse.key")
+		}
+		if ctx.String("obj.content.encoding") != "" {
+			fatalIf(errDummy(), "--verify cannot be used with --obj.content.encoding")
+		}
+	}
+	err := rangeOptions(ctx).Validate()
+	fatalIf(probe.NewError(err), "Invalid range options")
 	checkAnalyze(ctx)
 	checkBenchmark(ctx)
 }
+
+// rangeOptions returns the options of ranged get operations.
+func rangeOptions(ctx *cli.Context) bench.RangeOptions {
+	r := bench.RangeOptions{Pattern: ctx.String("range.pattern")}
+	if s := ctx.String("range.size"); s != "" {
+		if size, err := toSize(s); err == nil {
+			r.Size = int64(size)
+		} else {
+			r.Sizes, err = parseSizeDistribution(s)
+			fatalIf(probe.NewError(err), "Invalid range.size specified")
+		}
+	}
+	if s := ctx.String("range.stride"); s != "" {
+		stride, err := toSize(s)
+		fatalIf(probe.NewError(err), "Invalid range.stride specified")
+		r.Stride = int64(stride)
+	}
+	return r
+}
@@ -0,0 +1,121 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+var growFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "1KiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.StringFlag{
+		Name:  "grow.checkpoints",
+		Value: "1M,10M,100M",
+		Usage: "Comma separated object counts where listing and stat latency is measured. Can be a number or 10K/M/G.",
+	},
+	cli.IntFlag{
+		Name:  "grow.stats",
+		Value: 1000,
+		Usage: "Number of stat operations at each checkpoint.",
+	},
+	cli.IntFlag{
+		Name:  "grow.lists",
+		Value: 100,
+		Usage: "Number of list operations at each checkpoint.",
+	},
+}
+
+var growCmd = cli.Command{
+	Name:   "grow",
+	Usage:  "benchmark metadata performance as the namespace grows",
+	Action: mainGrow,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, growFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+  -> see https://github.com/minio/warp#grow
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainGrow is the entry point for grow command.
+func mainGrow(ctx *cli.Context) error {
+	checkGrowSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	b := bench.Grow{
+		Common: bench.Common{
+			Client:      newClient(ctx),
+			Concurrency: getConcurrency(ctx),
+			Source:      src,
+			Bucket:      ctx.String("bucket"),
+			Location:    "",
+			PutOpts:     putOpts(ctx),
+		},
+		Checkpoints: parseGrowCheckpoints(ctx.String("grow.checkpoints")),
+		StatSamples: ctx.Int("grow.stats"),
+		ListSamples: ctx.Int("grow.lists"),
+	}
+	return runBench(ctx, &b)
+}
+
+// parseGrowCheckpoints parses a comma separated list of increasing object counts.
+func parseGrowCheckpoints(s string) []bench.GrowCheckpoint {
+	var res []bench.GrowCheckpoint
+	for _, cp := range strings.Split(s, ",") {
+		cp = strings.TrimSpace(cp)
+		if cp == "" {
+			continue
+		}
+		n, err := humanize.ParseBytes(cp)
+		fatalIf(probe.NewError(err), "Invalid grow.checkpoints value: "+cp)
+		if len(res) > 0 && int64(n) <= res[len(res)-1].Objects {
+			console.Fatal("--grow.checkpoints must be increasing")
+		}
+		res = append(res, bench.GrowCheckpoint{Objects: int64(n), Label: cp})
+	}
+	return res
+}
+
+func checkGrowSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if len(parseGrowCheckpoints(ctx.String("grow.checkpoints"))) == 0 {
+		console.Fatal("At least one checkpoint must be given")
+	}
+	if ctx.Int("grow.stats") < 0 || ctx.Int("grow.lists") < 0 {
+		console.Fatal("--grow.stats and --grow.lists cannot be negative")
+	}
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
@@ -0,0 +1,227 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/warp/pkg/bench"
+	"github.com/minio/warp/pkg/generator"
+)
+
+// guardListLimit is the number of existing objects inspected before a benchmark.
+const guardListLimit = 1000
+
+// warpObjectNames match the base names of objects written by warp.
+var warpObjectNames = regexp.MustCompile(`^(\d+\.[a-zA-Z0-9()]{16}\.[a-zA-Z0-9.]+|[a-zA-Z0-9()]{16}\.csv|\d+\.zip|warp-.+)$`)
+
+// warpObjectName returns whether the object name looks like one written by warp.
+func warpObjectName(name string) bool {
+	return warpObjectNames.MatchString(path.Base(name))
+}
+
+// warpObjects identifies objects created by warp.
+// Objects are looked up in a cleanup journal or a manifest if one is given,
+// otherwise they are recognized by their names.
+type warpObjects struct {
+	journal *bench.Journal
+	// manifest entries of the bucket by key.
+	manifest map[string][]bench.ManifestEntry
+	bucket   string
+}
+
+// recordedWarpObjects returns the objects recorded in the journal, or in the manifest of the bucket.
+// The files must exist if given.
+func recordedWarpObjects(ctx *cli.Context, journalFile, manifestFile, bucket string) (*warpObjects, error) {
+	w := warpObjects{bucket: bucket}
+	if journalFile != "" {
+		// Opening creates missing journals.
+		if _, err := os.Stat(journalFile); err != nil {
+			return nil, err
+		}
+		j, err := bench.OpenJournal(journalFile)
+		if err != nil {
+			return nil, err
+		}
+		w.journal = j
+	}
+	if manifestFile != "" {
+		f, err := os.Open(manifestFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r, err := newDecryptReader(f, ctx.String(benchDataKeyFlag.Name))
+		if err != nil {
+			return nil, err
+		}
+		entries, err := bench.ReadManifest(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", manifestFile, err)
+		}
+		w.manifest = make(map[string][]bench.ManifestEntry, len(entries))
+		for _, e := range entries {
+			w.manifest[e.Key] = append(w.manifest[e.Key], e)
+		}
+	}
+	return &w, nil
+}
+
+// object returns whether the listed object was created by warp.
+func (w *warpObjects) object(bucket string, obj minio.ObjectInfo) bool {
+	switch {
+	case w.journal != nil:
+		return w.journal.Created(bucket, obj)
+	case w.manifest != nil:
+		if bucket != w.bucket {
+			return false
+		}
+		for _, e := range w.manifest[obj.Key] {
+			if (e.VersionID == "" || e.VersionID == obj.VersionID) && strings.Trim(e.ETag, `"`) == strings.Trim(obj.ETag, `"`) {
+				return true
+			}
+		}
+		return false
+	}
+	return warpObjectName(obj.Key)
+}
+
+// key returns whether the key was created by warp, in any version.
+func (w *warpObjects) key(bucket, key string) bool {
+	switch {
+	case w.journal != nil:
+		return w.journal.Recorded(bucket, key)
+	case w.manifest != nil:
+		return bucket == w.bucket && len(w.manifest[key]) > 0
+	}
+	return warpObjectName(key)
+}
+
+// close releases the journal, if any.
+func (w *warpObjects) close() {
+	if w.journal != nil {
+		w.journal.Close()
+	}
+}
+
+// generatedNames returns a function reporting whether an object name can be created
+// by the generator options of the benchmark, which the name patterns of warp do not match.
+// These are names created with --obj.key.template and the files of the corpus generator.
+func generatedNames(ctx *cli.Context) func(name string) bool {
+	var matchers []func(string) bool
+	if tmpl := ctx.String("obj.key.template"); tmpl != "" {
+		m, err := generator.KeyTemplateMatcher(tmpl)
+		fatalIf(probe.NewError(err), "Invalid --obj.key.template specified")
+		matchers = append(matchers, m)
+	}
+	if ctx.String("obj.generator") == "corpus" {
+		names, err := generator.CorpusNames(ctx.String("obj.corpus.dir"))
+		fatalIf(probe.NewError(err), "Unable to read corpus")
+		corpus := make(map[string]struct{}, len(names))
+		for _, n := range names {
+			corpus[n] = struct{}{}
+		}
+		// Corpus files are uploaded below a prefix.
+		matchers = append(matchers, func(name string) bool {
+			for {
+				if _, ok := corpus[name]; ok {
+					return true
+				}
+				var found bool
+				if _, name, found = strings.Cut(name, "/"); !found {
+					return false
+				}
+			}
+		})
+	}
+	return func(name string) bool {
+		for _, m := range matchers {
+			if m(name) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// bucketAllowed returns whether the bucket matches one of the comma separated patterns.
+func bucketAllowed(bucket, patterns string) bool {
+	for _, p := range strings.Split(patterns, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if ok, err := path.Match(p, bucket); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBucketSafety stops the benchmark if it would delete or overwrite
+// objects in a bucket that contains data not created by warp.
+// Objects created by warp match the names of warp or of the generator options,
+// or are listed in the --manifest file written by an earlier run.
+// Benchmarks run with --noclear only remove the objects they created,
+// and benchmarks with a cleanup journal only remove objects recorded in it.
+func checkBucketSafety(ctx *cli.Context, b bench.Benchmark) {
+	if ctx.Bool("noclear") || ctx.Bool("i-know-what-im-doing") || b.GetCommon().Journal != nil {
+		return
+	}
+	bucket := b.GetCommon().Bucket
+	if bucketAllowed(bucket, ctx.String("bucket.allow")) {
+		return
+	}
+	generated := generatedNames(ctx)
+	var manifest *warpObjects
+	if fn := ctx.String("manifest"); fn != "" {
+		if _, err := os.Stat(fn); err == nil {
+			manifest, err = recordedWarpObjects(ctx, "", fn, bucket)
+			fatalIf(probe.NewError(err), "Unable to read manifest %q", fn)
+		}
+	}
+	cl, done := newClient(ctx)()
+	defer done()
+	bg, cancel := context.WithTimeout(context.Background(), serverProbeTimeout)
+	defer cancel()
+	n := 0
+	for obj := range cl.ListObjects(bg, bucket, minio.ListObjectsOptions{Recursive: true, MaxKeys: guardListLimit}) {
+		if obj.Err != nil {
+			// A missing bucket has no data to protect.
+			if minio.ToErrorResponse(obj.Err).Code == "NoSuchBucket" {
+				return
+			}
+			fatalIf(probe.NewError(obj.Err), "Unable to list bucket %q to check for existing data. Use --i-know-what-im-doing to skip the check", bucket)
+		}
+		if !warpObjectName(obj.Key) && !generated(obj.Key) && (manifest == nil || !manifest.object(bucket, obj)) {
+			fatalIf(errDummy(), "Bucket %q contains data not created by warp (%q), which would be deleted. Use --noclear, --bucket.allow or --i-know-what-im-doing to run anyway", bucket, obj.Key)
+		}
+		n++
+		if n >= guardListLimit {
+			return
+		}
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/madmin-go"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/api"
+)
+
+// healAnnotation is the annotation label of the time the server is healing.
+const healAnnotation = "heal"
+
+// startHealing will start healing the benchmark bucket when the configured time
+// after the benchmark start has passed.
+// The time the server is healing is annotated as "heal" in the monitor.
+func startHealing(ctx2 context.Context, ctx *cli.Context, tStart time.Time, monitor *api.Server) {
+	after := ctx.Duration("heal.after")
+	if after <= 0 {
+		return
+	}
+	opts := madmin.HealOpts{Recursive: true, ScanMode: madmin.HealNormalScan}
+	if ctx.String("heal.scan") == "deep" {
+		opts.ScanMode = madmin.HealDeepScan
+	}
+	bucket := ctx.String("bucket")
+	client := newAdminClient(ctx)
+	go func() {
+		select {
+		case <-ctx2.Done():
+			return
+		case <-time.After(time.Until(tStart.Add(after))):
+		}
+		started, _, err := client.Heal(ctx2, bucket, "", opts, "", true, false)
+		if err != nil {
+			errorIf(probe.NewError(err), "Unable to start healing")
+			return
+		}
+		monitor.StartAnnotation(healAnnotation)
+		monitor.InfoLn("Healing started.")
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx2.Done():
+				// Annotation is closed when the benchmark ends.
+				return
+			case <-ticker.C:
+			}
+			_, status, err := client.Heal(ctx2, bucket, "", opts, started.ClientToken, false, false)
+			if err != nil {
+				if ctx2.Err() == nil {
+					errorIf(probe.NewError(err), "Unable to get healing status")
+				}
+				continue
+			}
+			switch status.Summary {
+			case "finished", "stopped":
+				monitor.EndAnnotation(healAnnotation)
+				if status.FailureDetail != "" {
+					console.Errorln("Healing stopped:", status.FailureDetail)
+				}
+				monitor.InfoLn("Healing ", status.Summary, ".")
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,176 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// Hook events.
+const (
+	hookPre        = "pre"
+	hookStageStart = "stage_start"
+	hookStageEnd   = "stage_end"
+	hookPost       = "post"
+)
+
+// hookContext is the run context given to hooks
+// as JSON and in WARP_ environment variables.
+type hookContext struct {
+	Event     string    `json:"event"`
+	Stage     string    `json:"stage,omitempty"`
+	Benchmark string    `json:"benchmark"`
+	Bucket    string    `json:"bucket"`
+	Hosts     []string  `json:"hosts"`
+	Clients   []string  `json:"clients,omitempty"`
+	BenchData string    `json:"benchdata,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// env returns the context as environment variables.
+func (h hookContext) env() []string {
+	return []string{
+		"WARP_HOOK_EVENT=" + h.Event,
+		"WARP_STAGE=" + h.Stage,
+		"WARP_BENCHMARK=" + h.Benchmark,
+		"WARP_BUCKET=" + h.Bucket,
+		"WARP_HOSTS=" + strings.Join(h.Hosts, ","),
+		"WARP_CLIENTS=" + strings.Join(h.Clients, ","),
+		"WARP_BENCHDATA=" + h.BenchData,
+	}
+}
+
+// hooks calls the hooks of a benchmark run.
+type hooks struct {
+	pre, stage, post string
+	timeout          time.Duration
+	base             hookContext
+}
+
+// newHooks returns the hooks set in the context.
+func newHooks(ctx *cli.Context, b bench.Benchmark) *hooks {
+	h := hooks{
+		pre:     ctx.String("hook.pre"),
+		stage:   ctx.String("hook.stage"),
+		post:    ctx.String("hook.post"),
+		timeout: ctx.Duration("hook.timeout"),
+		base: hookContext{
+			Benchmark: ctx.Command.Name,
+			Bucket:    b.GetCommon().Bucket,
+			Hosts:     parseHosts(ctx.String("host")),
+		},
+	}
+	if clients := ctx.String("warp-client"); clients != "" {
+		h.base.Clients = parseHosts(clients)
+	}
+	return &h
+}
+
+// runPre calls the pre hook. The benchmark is stopped if it fails.
+func (h *hooks) runPre() {
+	if h.pre == "" {
+		return
+	}
+	fatalIf(probe.NewError(h.call(h.pre, hookPre, "", "")), "Pre hook failed")
+}
+
+// startStage calls the stage hook before a stage starts.
+func (h *hooks) startStage(stage benchmarkStage) {
+	if h.stage == "" {
+		return
+	}
+	errorIf(probe.NewError(h.call(h.stage, hookStageStart, stage, "")), "Stage hook failed")
+}
+
+// endStage calls the stage hook when a stage has ended.
+func (h *hooks) endStage(stage benchmarkStage) {
+	if h.stage == "" {
+		return
+	}
+	errorIf(probe.NewError(h.call(h.stage, hookStageEnd, stage, "")), "Stage hook failed")
+}
+
+// runPost calls the post hook with the benchmark data written.
+func (h *hooks) runPost(benchData string) {
+	if h.post == "" {
+		return
+	}
+	errorIf(probe.NewError(h.call(h.post, hookPost, "", benchData)), "Post hook failed")
+}
+
+// call runs the hook with the run context.
+// URL hooks receive a POST request with the context as JSON,
+// commands are run by the shell with the context as JSON on stdin and in environment variables.
+func (h *hooks) call(hook, event string, stage benchmarkStage, benchData string) error {
+	hc := h.base
+	hc.Event = event
+	hc.Stage = string(stage)
+	hc.BenchData = benchData
+	hc.Time = time.Now()
+	body, err := json.Marshal(hc)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+	if strings.HasPrefix(hook, "http://") || strings.HasPrefix(hook, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(ioutil.Discard, resp.Body)
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("%s hook returned status %s", event, resp.Status)
+		}
+		return nil
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", hook)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", hook)
+	}
+	cmd.Env = append(os.Environ(), hc.env()...)
+	cmd.Stdin = bytes.NewReader(body)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s hook: %w: %s", event, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
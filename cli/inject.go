@@ -0,0 +1,127 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+var injectFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "1MiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.Float64Flag{
+		Name:  "inject.rate",
+		Value: 0.1,
+		Usage: "Fraction of requests that are malformed. Must be between 0 and 1.",
+	},
+	cli.StringFlag{
+		Name:  "inject.types",
+		Value: strings.Join(bench.InjectKinds, ","),
+		Usage: "Comma separated malformed request types to send. Can be " + strings.Join(bench.InjectKinds, ", "),
+	},
+}
+
+var injectCmd = cli.Command{
+	Name:   "inject",
+	Usage:  "benchmark put objects while sending malformed requests",
+	Action: mainInject,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, injectFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+  -> see https://github.com/minio/warp#inject
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainInject is the entry point for inject command.
+func mainInject(ctx *cli.Context) error {
+	checkInjectSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	b := bench.Inject{
+		Common: bench.Common{
+			Client:      newClient(ctx),
+			Concurrency: getConcurrency(ctx),
+			Source:      src,
+			Bucket:      ctx.String("bucket"),
+			Location:    "",
+			PutOpts:     putOpts(ctx),
+		},
+		Rate:      ctx.Float64("inject.rate"),
+		Kinds:     parseInjectKinds(ctx.String("inject.types")),
+		BadClient: newBadSignatureClient(ctx),
+		AccessKey: ctx.String("access-key"),
+		SecretKey: ctx.String("secret-key"),
+		Region:    ctx.String("region"),
+		Transport: rawTransport(ctx),
+	}
+	return runBench(ctx, &b)
+}
+
+// newBadSignatureClient returns clients for all hosts, using an invalid secret key.
+func newBadSignatureClient(ctx *cli.Context) func() (*minio.Client, func()) {
+	return newClientWithKeys(ctx, ctx.String("host"), ctx.String("access-key"), ctx.String("secret-key")+"-invalid")
+}
+
+// parseInjectKinds returns the malformed request types in s.
+func parseInjectKinds(s string) []string {
+	var kinds []string
+	for _, kind := range strings.Split(s, ",") {
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if kind == "" {
+			continue
+		}
+		found := false
+		for _, k := range bench.InjectKinds {
+			found = found || k == kind
+		}
+		if !found {
+			console.Fatalf("unknown inject type %q. Can be %s\n", kind, strings.Join(bench.InjectKinds, ", "))
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+func checkInjectSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if r := ctx.Float64("inject.rate"); r < 0 || r > 1 {
+		console.Fatal("--inject.rate must be between 0 and 1")
+	}
+	if len(parseInjectKinds(ctx.String("inject.types"))) == 0 {
+		console.Fatal("At least one inject type must be given")
+	}
+
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
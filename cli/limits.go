@@ -0,0 +1,86 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// clientLimitPrefix prefixes custom values with client limit warnings returned by clients.
+const clientLimitPrefix = "client-limit."
+
+// measureClientLimits makes the benchmark measure payload generation and result recording.
+// Must be called after preparing, so only the benchmark itself is measured.
+func measureClientLimits(c *bench.Common) *bench.ClientLimits {
+	l := bench.NewClientLimits()
+	c.Limits = l
+	if c.Source != nil {
+		c.Source = l.Source(c.Source)
+	}
+	return l
+}
+
+// returnClientLimits adds warnings to the custom values returned to the server.
+func returnClientLimits(c *bench.Common, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	host, _ := os.Hostname()
+	if c.Custom == nil {
+		c.Custom = make(map[string]string, len(warnings))
+	}
+	for i, w := range warnings {
+		c.Custom[fmt.Sprintf("%s%s.%d", clientLimitPrefix, host, i)] = fmt.Sprintf("Client %s: %s", host, w)
+	}
+}
+
+// returnedClientLimits returns the warnings returned by clients.
+func returnedClientLimits(custom map[string]string) []string {
+	var keys []string
+	for k := range custom {
+		if strings.HasPrefix(k, clientLimitPrefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	warnings := make([]string, 0, len(keys))
+	for _, k := range keys {
+		warnings = append(warnings, custom[k])
+	}
+	return warnings
+}
+
+// printClientLimits prints warnings about client limits reached during the benchmark.
+func printClientLimits(warnings []string) {
+	if globalJSON || len(warnings) == 0 {
+		return
+	}
+	console.SetColor("Print", color.New(color.FgHiRed))
+	console.Println("\nClient limits reached:")
+	for _, w := range warnings {
+		console.Println(" * " + w)
+	}
+	console.SetColor("Print", color.New(color.FgWhite))
+}
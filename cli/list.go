@@ -43,6 +43,10 @@ var listFlags = []cli.Flag{
 		Name:  "metadata",
 		Usage: "Enable extended MinIO ListObjects with metadata, by default this benchmarking uses ListObjectsV2 API.",
 	},
+	cli.IntFlag{
+		Name:  "maxkeys",
+		Usage: "Number of entries requested per page. Default is the server default, usually 1000.",
+	},
 }
 
 var listCmd = cli.Command{
@@ -71,7 +75,7 @@ func mainList(ctx *cli.Context) error {
 	b := bench.List{
 		Common: bench.Common{
 			Client:      newClient(ctx),
-			Concurrency: ctx.Int("concurrent"),
+			Concurrency: getConcurrency(ctx),
 			Source:      src,
 			Bucket:      ctx.String("bucket"),
 			Location:    "",
@@ -80,7 +84,13 @@ func mainList(ctx *cli.Context) error {
 		Versions:      ctx.Int("versions"),
 		Metadata:      ctx.Bool("metadata"),
 		CreateObjects: ctx.Int("objects"),
-		NoPrefix:      ctx.Bool("noprefix"),
+		MaxKeys:       ctx.Int("maxkeys"),
+		AccessKey:     ctx.String("access-key"),
+		SecretKey:     ctx.String("secret-key"),
+		Region:        ctx.String("region"),
+		Transport:     rawTransport(ctx),
+		// Hot prefixes are shared, so all threads list all objects.
+		NoPrefix: ctx.Bool("noprefix") || ctx.Int("obj.hot.prefixes") > 0,
 	}
 	return runBench(ctx, &b)
 }
@@ -92,6 +102,9 @@ func checkListSyntax(ctx *cli.Context) {
 	if ctx.Int("versions") < 1 {
 		console.Fatal("At least one version must be tested")
 	}
+	if n := ctx.Int("maxkeys"); n < 0 || n > 1000 {
+		console.Fatal("--maxkeys must be 0 to 1000")
+	}
 
 	checkAnalyze(ctx)
 	checkBenchmark(ctx)
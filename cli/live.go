@@ -0,0 +1,68 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/api"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// startLiveStats prints the throughput and request time percentiles of each
+// operation type every --live interval after the benchmark has started.
+// The statistics are also available in the status of the benchmark monitor.
+func startLiveStats(ctx2 context.Context, live *bench.LiveStats, interval time.Duration, tStart time.Time, monitor *api.Server) {
+	if live == nil {
+		return
+	}
+	go func() {
+		select {
+		case <-ctx2.Done():
+			return
+		case <-time.After(time.Until(tStart)):
+		}
+		// Discard operations from preparing.
+		live.Interval()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx2.Done():
+				return
+			case t := <-ticker.C:
+				stats := live.Interval()
+				monitor.LiveStatsReady(stats)
+				if globalQuiet || globalJSON {
+					continue
+				}
+				console.Eraseline()
+				console.Printf("\r%v:", t.Sub(tStart).Round(time.Second))
+				if len(stats) == 0 {
+					console.Print(" No operations finished.")
+				}
+				for _, s := range stats {
+					console.Print(" ", s, ".")
+				}
+				console.Print("\n")
+			}
+		}
+	}()
+}
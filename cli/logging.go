@@ -0,0 +1,224 @@
+/*
+ * Warp (C) 2019-2020 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb"
+	"github.com/minio/pkg/console"
+)
+
+// logLevel is the severity of a log message.
+type logLevel int
+
+// Levels of log messages.
+const (
+	logLevelDebug logLevel = iota
+	logLevelInfo
+	logLevelWarn
+	logLevelError
+)
+
+var logLevelNames = []string{"debug", "info", "warn", "error"}
+
+func (l logLevel) String() string {
+	if l < 0 || int(l) >= len(logLevelNames) {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return logLevelNames[l]
+}
+
+// parseLogLevel returns the level with the name.
+func parseLogLevel(s string) (logLevel, error) {
+	for i, name := range logLevelNames {
+		if strings.EqualFold(s, name) {
+			return logLevel(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q; supported: %s", s, strings.Join(logLevelNames, ", "))
+}
+
+var (
+	// globalLogLevel is the lowest level of messages printed.
+	globalLogLevel = logLevelInfo
+	// globalLogJSON prints messages as JSON objects on stderr.
+	globalLogJSON = false
+	// globalLogShipper collects messages for the coordinator when running as a warp client.
+	globalLogShipper *logShipper
+)
+
+// logEntry is a structured log message.
+type logEntry struct {
+	Time  time.Time `json:"time"`
+	Level string    `json:"level"`
+	Msg   string    `json:"msg"`
+	// Client is the warp client that logged the message, when printed by the coordinator.
+	Client string            `json:"client,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// newLogEntry returns an entry with the message and fields given as key/value pairs.
+func newLogEntry(level logLevel, msg string, kv []interface{}) logEntry {
+	e := logEntry{Time: time.Now(), Level: level.String(), Msg: msg}
+	for i := 0; i < len(kv); i += 2 {
+		if e.Fields == nil {
+			e.Fields = make(map[string]string, len(kv)/2)
+		}
+		key := fmt.Sprint(kv[i])
+		if i+1 == len(kv) {
+			e.Fields[key] = ""
+			break
+		}
+		e.Fields[key] = fmt.Sprint(kv[i+1])
+	}
+	return e
+}
+
+// text returns the message followed by the fields in key order.
+func (e logEntry) text() string {
+	var sb strings.Builder
+	if e.Client != "" {
+		sb.WriteString("Client " + e.Client + ": ")
+	}
+	sb.WriteString(e.Msg)
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, " %s=%q", k, e.Fields[k])
+	}
+	return sb.String()
+}
+
+// logDebug logs a message with fields given as key/value pairs, if debug messages are enabled.
+func logDebug(msg string, kv ...interface{}) {
+	logMsg(logLevelDebug, msg, kv...)
+}
+
+// logInfo logs a message with fields given as key/value pairs.
+func logInfo(msg string, kv ...interface{}) {
+	logMsg(logLevelInfo, msg, kv...)
+}
+
+// logWarn logs a warning with fields given as key/value pairs.
+func logWarn(msg string, kv ...interface{}) {
+	logMsg(logLevelWarn, msg, kv...)
+}
+
+// logError logs an error with fields given as key/value pairs.
+func logError(msg string, kv ...interface{}) {
+	logMsg(logLevelError, msg, kv...)
+}
+
+func logMsg(level logLevel, msg string, kv ...interface{}) {
+	if level < globalLogLevel {
+		return
+	}
+	logPrint(level, newLogEntry(level, msg, kv))
+}
+
+// logPrint prints the entry and ships warnings and errors to the coordinator in client mode.
+func logPrint(level logLevel, e logEntry) {
+	if s := globalLogShipper; s != nil && level >= logLevelWarn && e.Client == "" {
+		s.add(e)
+	}
+	printMu.Lock()
+	defer printMu.Unlock()
+	if globalLogJSON {
+		// Messages printed with printInfo may end with a newline.
+		e.Msg = strings.TrimRight(e.Msg, "\n")
+		b, err := json.Marshal(e)
+		if err == nil {
+			os.Stderr.Write(append(b, '\n'))
+		}
+		return
+	}
+	// Clear the progress bar.
+	w, _ := pb.GetTerminalWidth()
+	if w > 0 {
+		fmt.Print("\r", strings.Repeat(" ", w), "\r")
+	}
+	switch {
+	case level >= logLevelWarn:
+		console.Errorln(e.text())
+	case w > 0:
+		// Info messages are overwritten by the next message or the progress bar.
+		console.Info(e.text())
+	default:
+		console.Infoln(e.text())
+	}
+}
+
+// logShipMax is the number of messages a warp client keeps until the coordinator collects them.
+const logShipMax = 1000
+
+// logShipper collects the messages of a warp client until the coordinator collects them.
+type logShipper struct {
+	mu      sync.Mutex
+	entries []logEntry
+	dropped int
+}
+
+// add adds the entry, or counts it as dropped if the buffer is full.
+func (s *logShipper) add(e logEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) >= logShipMax {
+		s.dropped++
+		return
+	}
+	s.entries = append(s.entries, e)
+}
+
+// take returns and removes the collected entries and the number of dropped entries.
+func (s *logShipper) take() ([]logEntry, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, dropped := s.entries, s.dropped
+	s.entries, s.dropped = nil, 0
+	return entries, dropped
+}
+
+// printClientLogs prints the messages shipped by a warp client.
+func printClientLogs(client string, entries []logEntry, dropped int) {
+	for _, e := range entries {
+		level, err := parseLogLevel(e.Level)
+		if err != nil {
+			level = logLevelError
+		}
+		if level < globalLogLevel {
+			continue
+		}
+		e.Client = client
+		logPrint(level, e)
+	}
+	if dropped > 0 {
+		e := newLogEntry(logLevelWarn, fmt.Sprintf("%d messages dropped", dropped), nil)
+		e.Client = client
+		logPrint(logLevelWarn, e)
+	}
+}
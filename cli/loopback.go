@@ -0,0 +1,53 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"sync"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/warp/pkg/loopback"
+)
+
+var (
+	loopbackOnce   sync.Once
+	loopbackServer *loopback.Server
+)
+
+// loopbackTransport returns the in-memory server shared by all clients.
+func loopbackTransport(ctx *cli.Context) *loopback.Server {
+	loopbackOnce.Do(func() {
+		size, err := toSize(ctx.String("loopback.stored"))
+		fatalIf(probe.NewError(err), "Invalid loopback.stored size")
+		loopbackServer = loopback.New(int64(size))
+		err = loopbackServer.SetFaults(loopbackFaults(ctx))
+		fatalIf(probe.NewError(err), "Invalid loopback fault options")
+	})
+	return loopbackServer
+}
+
+// loopbackFaults returns the latency and errors added by the in-memory server.
+func loopbackFaults(ctx *cli.Context) loopback.Faults {
+	return loopback.Faults{
+		Latency:   ctx.Duration("loopback.latency"),
+		Dist:      ctx.String("loopback.latency.dist"),
+		ErrorRate: ctx.Float64("loopback.errors") / 100,
+		Errors:    ctx.String("loopback.errors.codes"),
+	}
+}
@@ -21,9 +21,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
-	"github.com/klauspost/compress/zstd"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/pkg/console"
@@ -36,6 +36,9 @@ var mergeFlags = []cli.Flag{
 		Value: "",
 		Usage: "Output combined data to this file. By default unique filename is generated.",
 	},
+	benchDataCompressFlag,
+	benchDataKeyFlag,
+	redactFlag,
 }
 
 var mergeCmd = cli.Command{
@@ -63,9 +66,11 @@ func mainMerge(ctx *cli.Context) error {
 	if len(args) <= 1 {
 		console.Fatal("Two or more benchmark data files must be supplied")
 	}
-	zstdDec, _ := zstd.NewReader(nil)
-	defer zstdDec.Close()
 	var allOps bench.Operations
+	// Keep the provenance of the inputs, unless they should be redacted.
+	comments := []string{commandLine(ctx)}
+	seen := make(map[string]struct{})
+	var buckets []string
 	threads := uint16(0)
 	log := console.Printf
 	if globalQuiet {
@@ -75,10 +80,18 @@ func mainMerge(ctx *cli.Context) error {
 		f, err := os.Open(arg)
 		fatalIf(probe.NewError(err), "Unable to open input file")
 		defer f.Close()
-		err = zstdDec.Reset(f)
+		input, err := newBenchDataReader(f, ctx.String(benchDataKeyFlag.Name))
 		fatalIf(probe.NewError(err), "Unable to decompress input")
-		ops, err := bench.OperationsFromCSV(zstdDec, false, ctx.Int("analyze.offset"), ctx.Int("analyze.limit"), log)
+		defer input.Close()
+		ops, cmts, err := bench.OperationsFromCSVComments(input, false, ctx.Int("analyze.offset"), ctx.Int("analyze.limit"), log)
 		fatalIf(probe.NewError(err), "Unable to parse input")
+		buckets = append(buckets, provenanceBuckets(cmts)...)
+		for _, c := range cmts {
+			if _, ok := seen[c]; !ok && !ctx.Bool(redactFlag.Name) {
+				seen[c] = struct{}{}
+				comments = append(comments, c)
+			}
+		}
 
 		threads = ops.OffsetThreads(threads)
 		allOps = append(allOps, ops...)
@@ -91,23 +104,19 @@ func mainMerge(ctx *cli.Context) error {
 		fileName = fmt.Sprintf("%s-%s-%s", appName, ctx.Command.Name, time.Now().Format("2006-01-02[150405]"))
 	}
 	allOps.SortByStartTime()
-	f, err := os.Create(fileName + ".csv.zst")
+	redactOps(ctx, allOps, buckets...)
+	out, err := createBenchData(ctx, fileName)
 	if err != nil {
 		console.Error("Unable to write benchmark data:", err)
 	} else {
-		func() {
-			defer f.Close()
-			enc, err := zstd.NewWriter(f, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
-			fatalIf(probe.NewError(err), "Unable to compress benchmark output")
-
-			defer enc.Close()
-			err = allOps.CSV(enc, commandLine(ctx))
-			fatalIf(probe.NewError(err), "Unable to write benchmark output")
-
-			console.Infof("Benchmark data written to %q\n", fileName+".csv.zst")
-		}()
+		err = allOps.CSV(out, strings.Join(comments, "\n"))
+		fatalIf(probe.NewError(err), "Unable to write benchmark output")
+		err = out.Close()
+		fatalIf(probe.NewError(err), "Unable to write benchmark output")
+		console.Infof("Benchmark data written to %q\n", out.Name)
 	}
-	for typ, ops := range allOps.ByOp() {
+	o, _ := allOps.SplitAnnotations()
+	for typ, ops := range o.ByOp() {
 		start, end := ops.ActiveTimeRange(true)
 		if !start.Before(end) {
 			console.Errorf("Type %v contains no overlapping items", typ)
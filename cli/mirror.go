@@ -0,0 +1,128 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"github.com/minio/cli"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+var mirrorFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "objects",
+		Value: 2500,
+		Usage: "Number of objects to upload to the source.",
+	},
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "10MiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.StringFlag{
+		Name:   "mirror.host",
+		Usage:  "Destination host. Multiple hosts can be specified as a comma separated list. Defaults to --host.",
+		EnvVar: appNameUC + "_MIRROR_HOST",
+	},
+	cli.StringFlag{
+		Name:   "mirror.access-key",
+		Usage:  "Destination access key. Defaults to --access-key.",
+		EnvVar: appNameUC + "_MIRROR_ACCESS_KEY",
+	},
+	cli.StringFlag{
+		Name:   "mirror.secret-key",
+		Usage:  "Destination secret key. Defaults to --secret-key.",
+		EnvVar: appNameUC + "_MIRROR_SECRET_KEY",
+	},
+	cli.StringFlag{
+		Name:  "mirror.bucket",
+		Usage: "Destination bucket. Defaults to --bucket.",
+	},
+}
+
+var mirrorCmd = cli.Command{
+	Name:   "mirror",
+	Usage:  "benchmark copying objects from one endpoint to another",
+	Action: mainMirror,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, mirrorFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+  -> see https://github.com/minio/warp#mirror
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainMirror is the entry point for mirror command.
+func mainMirror(ctx *cli.Context) error {
+	checkMirrorSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	sse := newSSE(ctx)
+	b := bench.Mirror{
+		Common: bench.Common{
+			Client:      newClient(ctx),
+			Concurrency: getConcurrency(ctx),
+			Source:      src,
+			Bucket:      ctx.String("bucket"),
+			Location:    "",
+			PutOpts:     putOpts(ctx),
+		},
+		DestClient:    newClientWithKeys(ctx, mirrorString(ctx, "host"), mirrorString(ctx, "access-key"), mirrorString(ctx, "secret-key")),
+		DestBucket:    mirrorString(ctx, "bucket"),
+		CreateObjects: ctx.Int("objects"),
+		GetOpts:       minio.GetObjectOptions{ServerSideEncryption: sse},
+	}
+	err := runBench(ctx, &b)
+	if err != nil || b.TransferTime <= 0 || globalJSON {
+		return err
+	}
+	readPct := 100 * float64(b.ReadWait) / float64(b.TransferTime)
+	console.Println("\n----------------------------------------")
+	console.Printf("Time waiting for source: %.01f%%, time waiting for destination: %.01f%%.\n", readPct, 100-readPct)
+	if readPct > 50 {
+		console.Println("The source is the bottleneck.")
+	} else {
+		console.Println("The destination is the bottleneck.")
+	}
+	return nil
+}
+
+// mirrorString returns the destination value of a flag, or the source value if not set.
+func mirrorString(ctx *cli.Context, name string) string {
+	if v := ctx.String("mirror." + name); v != "" {
+		return v
+	}
+	return ctx.String(name)
+}
+
+func checkMirrorSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if mirrorString(ctx, "host") == ctx.String("host") && mirrorString(ctx, "bucket") == ctx.String("bucket") {
+		console.Fatal("Source and destination cannot be the same. Specify --mirror.host or --mirror.bucket")
+	}
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
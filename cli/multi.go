@@ -0,0 +1,442 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// multiSyncMarker is printed by workloads of the multi command when they are prepared.
+// The start time is then read from standard input.
+const multiSyncMarker = "warp-multi: prepared"
+
+var multiFlags = []cli.Flag{
+	cli.DurationFlag{
+		Name:  "multi.wait",
+		Value: 3 * time.Second,
+		Usage: "Time to wait after all workloads are prepared before they start.",
+	},
+	cli.StringFlag{
+		Name:  "multi.buckets",
+		Usage: "Run a single benchmark once for each bucket in this comma separated list, in the region detected for each bucket.",
+	},
+	benchDataKeyFlag,
+}
+
+var multiCmd = cli.Command{
+	Name:   "multi",
+	Usage:  "run several benchmarks with a synchronized start",
+	Action: mainMulti,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, multiFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] "benchmark [FLAGS]" "benchmark [FLAGS]" ...
+  -> see https://github.com/minio/warp#multiple-workloads
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// multiWorkload is a benchmark run by the multi command.
+type multiWorkload struct {
+	idx  int
+	name string
+	args []string
+	cmd  *exec.Cmd
+
+	stdin io.WriteCloser
+	out   io.Reader
+
+	// output after the workload started.
+	output strings.Builder
+
+	// Set when running for each bucket of --multi.buckets.
+	bucket, region, benchData string
+}
+
+func (w *multiWorkload) String() string {
+	if w.bucket != "" {
+		return fmt.Sprintf("Workload %d (%s, bucket %s in %s)", w.idx+1, w.name, w.bucket, w.region)
+	}
+	return fmt.Sprintf("Workload %d (%s)", w.idx+1, w.name)
+}
+
+// mainMulti is the entry point for multi command.
+func mainMulti(ctx *cli.Context) error {
+	workloads := parseMultiWorkloads(ctx)
+	exe, err := os.Executable()
+	fatalIf(probe.NewError(err), "Unable to find warp executable")
+	setFatalExitCode(exitError)
+
+	for _, w := range workloads {
+		w.cmd = exec.Command(exe, w.args...)
+		// Credentials are passed in the environment, so they are not visible in the process list.
+		w.cmd.Env = append(os.Environ(), multiCredentialEnv(ctx)...)
+		w.stdin, err = w.cmd.StdinPipe()
+		if err == nil {
+			w.out, err = w.cmd.StdoutPipe()
+		}
+		if err == nil {
+			w.cmd.Stderr = w.cmd.Stdout
+			err = w.cmd.Start()
+		}
+		if err != nil {
+			for _, w := range workloads {
+				if w.stdin != nil {
+					w.stdin.Close()
+				}
+			}
+			fatalIf(probe.NewError(err), "Unable to start %v", w)
+		}
+		printInfo("Started ", w, ": ", redactArgs(ctx, w.args))
+	}
+
+	var printMu sync.Mutex
+	var wg sync.WaitGroup
+	prepared := make(chan *multiWorkload, len(workloads))
+	exited := make(chan *multiWorkload, len(workloads))
+	for _, w := range workloads {
+		wg.Add(1)
+		go func(w *multiWorkload) {
+			defer wg.Done()
+			started := false
+			sc := bufio.NewScanner(w.out)
+			for sc.Scan() {
+				line := sc.Text()
+				if !started && strings.HasSuffix(line, multiSyncMarker) {
+					started = true
+					line = strings.TrimSuffix(line, multiSyncMarker)
+					prepared <- w
+				}
+				switch {
+				case started:
+					w.output.WriteString(line + "\n")
+				case strings.TrimSpace(line) != "":
+					// Show preparation progress as it happens.
+					printMu.Lock()
+					console.Printf("%v: %s\n", w, strings.TrimSpace(line))
+					printMu.Unlock()
+				}
+			}
+			err := w.cmd.Wait()
+			code := 0
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				code = exitErr.ExitCode()
+			} else if err != nil {
+				code = exitError
+			}
+			if code != 0 {
+				setExitStatus(code)
+			}
+			printMu.Lock()
+			console.Println()
+			if code != 0 {
+				console.Errorf("%v exited with code %d:\n", w, code)
+			} else {
+				printInfo(w, " completed:")
+			}
+			console.Print(w.output.String())
+			printMu.Unlock()
+			if !started {
+				exited <- w
+			}
+		}(w)
+	}
+
+	// Start all workloads at the same time, when they are all prepared.
+	abort := func() {
+		for _, w := range workloads {
+			w.stdin.Close()
+		}
+		wg.Wait()
+	}
+	for n := 0; n < len(workloads); n++ {
+		select {
+		case <-prepared:
+		case w := <-exited:
+			abort()
+			fatalIf(errDummy(), "%v exited before it was prepared", w)
+		}
+	}
+	tStart := time.Now().Add(ctx.Duration("multi.wait"))
+	printMu.Lock()
+	printInfo("All workloads prepared. Starting benchmarks in ", ctx.Duration("multi.wait"), "...")
+	printMu.Unlock()
+	for _, w := range workloads {
+		fmt.Fprintln(w.stdin, tStart.Format(time.RFC3339Nano))
+	}
+	wg.Wait()
+	if ctx.String("multi.buckets") != "" {
+		printRegionSummary(ctx, workloads)
+	}
+	return nil
+}
+
+// parseMultiWorkloads parses the benchmarks given as arguments to the multi command.
+// Flags set on the multi command are added before the flags of each benchmark.
+func parseMultiWorkloads(ctx *cli.Context) []*multiWorkload {
+	args := []string(ctx.Args())
+	var buckets []string
+	if s := ctx.String("multi.buckets"); s != "" {
+		if len(args) != 1 {
+			fatalIf(errDummy(), "A single benchmark must be given with --multi.buckets. Example: warp multi --multi.buckets=bench-us,bench-eu \"get --duration=5m\"")
+		}
+		buckets = strings.Split(s, ",")
+		for i := 1; i < len(buckets); i++ {
+			args = append(args, args[0])
+		}
+	} else if len(args) < 2 {
+		fatalIf(errDummy(), "At least two benchmarks must be given. Example: warp multi \"put --bucket=backup\" \"get --bucket=interactive\"")
+	}
+	var inherited []string
+	for _, flag := range ctx.Command.Flags {
+		name := flag.GetName()
+		if strings.HasPrefix(name, "multi.") || multiCredentialFlags[name] != "" || !ctx.IsSet(name) {
+			continue
+		}
+		v, err := flagToJSON(ctx, flag)
+		fatalIf(probe.NewError(err), "Unable to forward flag %s", name)
+		inherited = append(inherited, "--"+name+"="+v)
+	}
+
+	usedBuckets := make(map[string]int)
+	workloads := make([]*multiWorkload, 0, len(args))
+	for i, arg := range args {
+		fields := strings.Fields(arg)
+		if len(fields) == 0 {
+			fatalIf(errDummy(), "Benchmark %d is empty", i+1)
+		}
+		var cmd *cli.Command
+		for j := range benchCmds {
+			if benchCmds[j].HasName(fields[0]) {
+				cmd = &benchCmds[j]
+				break
+			}
+		}
+		if cmd == nil {
+			fatalIf(errDummy(), "Benchmark %d: unknown benchmark %q", i+1, fields[0])
+		}
+		w := &multiWorkload{idx: i, name: cmd.Name}
+		w.args = append([]string{cmd.Name}, inherited...)
+		w.args = append(w.args, fields[1:]...)
+		w.args = append(w.args, "--multi.sync")
+		if buckets != nil {
+			w.bucket = strings.TrimSpace(buckets[i])
+			if w.bucket == "" {
+				fatalIf(errDummy(), "Empty bucket name in --multi.buckets")
+			}
+			region, err := detectBucketRegion(ctx, w.bucket)
+			fatalIf(probe.NewError(err), "Unable to detect the region of bucket %q. The bucket must exist", w.bucket)
+			w.region = region
+			w.args = append(w.args, "--bucket="+w.bucket, "--region="+region)
+		}
+
+		set := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+		set.SetOutput(io.Discard)
+		for _, f := range cmd.Flags {
+			f.Apply(set)
+		}
+		if err := set.Parse(w.args[1:]); err != nil {
+			fatalIf(probe.NewError(err), "Benchmark %d: invalid flags", i+1)
+		}
+		if set.NArg() > 0 {
+			fatalIf(errDummy(), "Benchmark %d: unexpected arguments: %v", i+1, set.Args())
+		}
+		if set.Lookup("syncstart").Value.String() != "" {
+			fatalIf(errDummy(), "Benchmark %d: --syncstart cannot be used with multi", i+1)
+		}
+		bucket := set.Lookup("bucket").Value.String()
+		if prev, ok := usedBuckets[bucket]; ok {
+			fatalIf(errDummy(), "Benchmarks %d and %d use the same bucket %q. Use --bucket to give each benchmark its own bucket", prev+1, i+1, bucket)
+		}
+		usedBuckets[bucket] = i
+		switch f := set.Lookup("benchdata"); {
+		case f == nil || f.Value.String() == f.DefValue:
+			w.benchData = fmt.Sprintf("warp-multi-%s-%d-%s", time.Now().Format("2006-01-02[150405]"), i+1, cmd.Name)
+			w.args = append(w.args, "--benchdata="+w.benchData)
+		case w.bucket != "":
+			// Each bucket needs its own file.
+			w.benchData = f.Value.String() + "-" + w.bucket
+			w.args = append(w.args, "--benchdata="+w.benchData)
+		}
+		workloads = append(workloads, w)
+	}
+	return workloads
+}
+
+// multiCredentialFlags are the flags passed to benchmarks in the environment variable instead of as arguments.
+var multiCredentialFlags = map[string]string{
+	"access-key":          appNameUC + "_ACCESS_KEY",
+	"secret-key":          appNameUC + "_SECRET_KEY",
+	benchDataKeyFlag.Name: benchDataKeyFlag.EnvVar,
+}
+
+// multiCredentialEnv returns the environment passing the credentials set on the multi command to benchmarks.
+func multiCredentialEnv(ctx *cli.Context) []string {
+	var env []string
+	for name, envVar := range multiCredentialFlags {
+		if ctx.IsSet(name) {
+			env = append(env, envVar+"="+ctx.String(name))
+		}
+	}
+	return env
+}
+
+// redactArgs returns the arguments as they can be printed,
+// with flag values redacted like in benchmark data.
+func redactArgs(ctx *cli.Context, args []string) string {
+	res := make([]string, len(args))
+	// Name of a flag given without value, which is the next argument.
+	var prev string
+	for i, arg := range args {
+		res[i] = arg
+		if !strings.HasPrefix(arg, "-") {
+			if prev != "" {
+				res[i] = redactFlagValue(ctx, prev, arg)
+			}
+			prev = ""
+			continue
+		}
+		name, val, ok := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		prev = ""
+		if !ok {
+			prev = name
+			continue
+		}
+		res[i] = "--" + name + "=" + redactFlagValue(ctx, name, val)
+	}
+	return strings.Join(res, " ")
+}
+
+// waitMultiStart tells the multi command the benchmark is prepared
+// and returns the start time it sends back.
+func waitMultiStart() time.Time {
+	fmt.Println()
+	fmt.Println(multiSyncMarker)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		fatalIf(probe.NewError(err), "Unable to read start time")
+	}
+	tStart, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(line))
+	fatalIf(probe.NewError(err), "Unable to parse start time")
+	return tStart
+}
+
+// detectBucketRegion returns the region of the bucket from the first host.
+// The client has no region set, since the client would return it instead of asking the server.
+func detectBucketRegion(ctx *cli.Context, bucket string) (string, error) {
+	cl, err := minio.New(parseHosts(ctx.String("host"))[0], &minio.Options{
+		Creds:     credentials.NewStaticV4(ctx.String("access-key"), ctx.String("secret-key"), ""),
+		Secure:    ctx.Bool("tls"),
+		Transport: clientTransport(ctx),
+	})
+	if err != nil {
+		return "", err
+	}
+	return cl.GetBucketLocation(context.Background(), bucket)
+}
+
+// printRegionSummary prints the combined performance of workloads in each region.
+// The benchmark data of the workloads is read from their files.
+func printRegionSummary(ctx *cli.Context, workloads []*multiWorkload) {
+	var regions []string
+	byRegion := make(map[string][]*multiWorkload)
+	for _, w := range workloads {
+		if len(byRegion[w.region]) == 0 {
+			regions = append(regions, w.region)
+		}
+		byRegion[w.region] = append(byRegion[w.region], w)
+	}
+	sort.Strings(regions)
+	console.Println("\n----------------------------------------")
+	console.SetColor("Print", color.New(color.FgHiWhite))
+	console.Println("Performance by region:")
+	for _, region := range regions {
+		var ops bench.Operations
+		var buckets []string
+		for _, w := range byRegion[region] {
+			buckets = append(buckets, w.bucket)
+			wOps, err := readMultiBenchData(ctx, w)
+			if err != nil {
+				console.Errorf("%v: unable to read benchmark data: %v\n", w, err)
+				continue
+			}
+			ops = append(ops, wOps...)
+		}
+		console.SetColor("Print", color.New(color.FgHiWhite))
+		console.Printf("\nRegion %s, buckets %s:\n", region, strings.Join(buckets, ", "))
+		console.SetColor("Print", color.New(color.FgWhite))
+		for _, typ := range ops.OpTypes() {
+			typOps := ops.FilterByOp(typ)
+			start, end := typOps.TimeRange()
+			console.Printf(" * %s: %s\n", typ, summarizeOps(typOps, end.Sub(start)))
+		}
+	}
+}
+
+// readMultiBenchData reads the operations in the benchmark data file of the workload.
+func readMultiBenchData(ctx *cli.Context, w *multiWorkload) (bench.Operations, error) {
+	var f *os.File
+	var err error
+	// The extension depends on the compression and encryption of the workload.
+	for _, ext := range []string{".csv.zst", ".csv.sz", ".csv", ".csv.zst.enc", ".csv.sz.enc", ".csv.enc"} {
+		f, err = os.Open(w.benchData + ext)
+		if !os.IsNotExist(err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec, err := newBenchDataReader(f, ctx.String(benchDataKeyFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+	defer dec.Close()
+	ops, err := bench.OperationsFromCSV(dec, true, 0, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	ops, _ = ops.SplitAnnotations()
+	return ops, nil
+}
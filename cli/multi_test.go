@@ -0,0 +1,111 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"flag"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// multiContext returns the context of the multi command with the arguments given.
+func multiContext(t *testing.T, args ...string) *cli.Context {
+	t.Helper()
+	set := flag.NewFlagSet(multiCmd.Name, flag.ContinueOnError)
+	for _, f := range multiCmd.Flags {
+		f.Apply(set)
+	}
+	if err := set.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	ctx := cli.NewContext(cli.NewApp(), set, nil)
+	ctx.Command = multiCmd
+	return ctx
+}
+
+func TestMultiEncryptedBenchData(t *testing.T) {
+	t.Setenv(benchDataKeyFlag.EnvVar, "")
+	ctx := multiContext(t, "--benchdata.key=secret", "get --bucket=a", "put --bucket=b")
+
+	workloads := parseMultiWorkloads(ctx)
+	for _, w := range workloads {
+		if strings.Contains(strings.Join(w.args, " "), "secret") {
+			t.Errorf("%v: key passed as argument: %v", w, w.args)
+		}
+	}
+	var found bool
+	for _, env := range multiCredentialEnv(ctx) {
+		found = found || env == benchDataKeyFlag.EnvVar+"=secret"
+	}
+	if !found {
+		t.Errorf("key not passed in the environment: %v", multiCredentialEnv(ctx))
+	}
+
+	// Write the benchmark data like the workload.
+	w := workloads[0]
+	w.benchData = filepath.Join(t.TempDir(), "workload")
+	f, err := createBenchData(ctx, w.benchData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(f.Name, ".enc") {
+		t.Fatalf("benchmark data %s not encrypted", f.Name)
+	}
+	start := time.Now().Truncate(time.Millisecond)
+	var ops bench.Operations
+	for i := 0; i < 10; i++ {
+		ops = append(ops, bench.Operation{
+			OpType:   "GET",
+			ObjPerOp: 1,
+			Start:    start.Add(time.Duration(i) * time.Millisecond),
+			End:      start.Add(time.Duration(i+1) * time.Millisecond),
+			Size:     1024,
+			File:     "obj",
+			Thread:   uint16(i % 2),
+			Endpoint: "localhost:9000",
+		})
+	}
+	if err := ops.CSV(f, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readMultiBenchData(ctx, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(ops) {
+		t.Fatalf("read %d operations, want %d", len(got), len(ops))
+	}
+	for i := range got {
+		if got[i].OpType != ops[i].OpType || got[i].Size != ops[i].Size || !got[i].End.Equal(ops[i].End) {
+			t.Errorf("operation %d: got %+v, want %+v", i, got[i], ops[i])
+		}
+	}
+
+	if _, err := readMultiBenchData(multiContext(t, "get", "put"), w); err == nil {
+		t.Error("encrypted benchmark data read without key")
+	}
+}
@@ -82,7 +82,7 @@ func mainMultipart(ctx *cli.Context) error {
 	b := bench.Multipart{
 		Common: bench.Common{
 			Client:      newClient(ctx),
-			Concurrency: ctx.Int("concurrent"),
+			Concurrency: getConcurrency(ctx),
 			Source:      src,
 			Bucket:      ctx.String("bucket"),
 			Location:    "",
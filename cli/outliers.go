@@ -0,0 +1,86 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// outlierHeader is the header of the slowest operations file.
+var outlierHeader = []string{"rank", "op", "key", "size", "endpoint", "client_id", "thread", "start", "duration", "first_byte", "client", "network", "server", "server_timing", "request_id", "error"}
+
+// writeOutliers writes the slowest operations to the --analyze.outliers file.
+func writeOutliers(ctx *cli.Context, o bench.Operations) {
+	fn := ctx.String("analyze.outliers")
+	if fn == "" {
+		return
+	}
+	n := ctx.Int("analyze.outliers.n")
+	if n <= 0 {
+		fatalIf(errDummy(), "analyze.outliers.n must be > 0")
+	}
+	f, err := createOutputFile(fn, ctx.String(benchDataKeyFlag.Name))
+	fatalIf(probe.NewError(err), "Unable to create outliers output")
+	slowest := o.Slowest(n)
+	err = writeOutlierCSV(f, slowest)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	fatalIf(probe.NewError(err), "Unable to write outliers output")
+	console.Infof("%d slowest operations saved to %s\n", len(slowest), fn)
+}
+
+// writeOutlierCSV writes the operations with all recorded details as CSV.
+func writeOutlierCSV(w io.Writer, ops bench.Operations) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(outlierHeader); err != nil {
+		return err
+	}
+	for i, op := range ops {
+		var ttfb, client, network, server, timed, reqID string
+		if op.FirstByte != nil {
+			ttfb = op.FirstByte.Sub(op.Start).String()
+		}
+		if op.Latency != nil {
+			client = op.ClientLatency().String()
+			network = op.Latency.Network.String()
+			server = op.Latency.Server.String()
+			timed = strconv.FormatBool(op.Latency.ServerTiming)
+			reqID = op.Latency.RequestID
+		}
+		err := cw.Write([]string{
+			strconv.Itoa(i + 1), op.OpType, op.File, strconv.FormatInt(op.Size, 10),
+			op.Endpoint, op.ClientID, strconv.Itoa(int(op.Thread)),
+			op.Start.Format(time.RFC3339Nano), op.Duration().String(), ttfb,
+			client, network, server, timed, reqID, op.Err,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,59 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// printPlaneAnalysis prints statistics of control and data plane operations
+// when they were sent to separate hosts.
+func printPlaneAnalysis(o bench.Operations) {
+	if globalJSON || len(o) == 0 {
+		return
+	}
+	planes := o.ByPlane()
+	control, data := planes[bench.PlaneControl], planes[bench.PlaneData]
+	if len(control) == 0 || len(data) == 0 {
+		return
+	}
+	dataHosts := make(map[string]struct{})
+	for _, ep := range data.Endpoints() {
+		dataHosts[ep] = struct{}{}
+	}
+	for _, ep := range control.Endpoints() {
+		if _, ok := dataHosts[ep]; ok {
+			// Planes share hosts.
+			return
+		}
+	}
+	dur := o.Duration()
+	console.Println("\n----------------------------------------")
+	for _, plane := range []string{bench.PlaneControl, bench.PlaneData} {
+		ops := planes[plane]
+		console.SetColor("Print", color.New(color.FgHiWhite))
+		console.Printf("%s%s plane: %s.\n", strings.ToUpper(plane[:1]), plane[1:], strings.Join(ops.OpTypes(), ", "))
+		console.SetColor("Print", color.New(color.FgWhite))
+		console.Println(" * Hosts:", strings.Join(ops.Endpoints(), ", "))
+		console.Println(" * Average:", summarizeOps(ops, dur))
+	}
+}
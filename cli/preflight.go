@@ -0,0 +1,163 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+	"github.com/minio/pkg/sys"
+)
+
+// Preflight modes.
+const (
+	preflightWarn = "warn"
+	preflightFail = "fail"
+	preflightOff  = "off"
+)
+
+// preflightFiles is the number of files needed besides connections,
+// for benchmark data, profiles and the runtime.
+const preflightFiles = 128
+
+// Linux kernel settings checked before a benchmark.
+const (
+	procPortRange     = "/proc/sys/net/ipv4/ip_local_port_range"
+	procConntrackMax  = "/proc/sys/net/netfilter/nf_conntrack_max"
+	procConntrackUsed = "/proc/sys/net/netfilter/nf_conntrack_count"
+)
+
+// preflightLimits contains the system limits checked before a benchmark.
+// Zero values are unknown.
+type preflightLimits struct {
+	openFiles, openFilesMax uint64
+	ephemeralPorts          uint64
+	conntrackMax            uint64
+	conntrackUsed           uint64
+}
+
+// checkSystemLimits checks that the open file limit, ephemeral ports and connection tracking
+// can handle the connections used with the concurrency, and that the concurrency
+// can be driven within the limits of the container.
+// The open file limit is raised if permitted.
+// Problems are returned as notes for the benchmark data, and are fatal with --preflight=fail.
+func checkSystemLimits(ctx *cli.Context, concurrency int) []string {
+	mode := ctx.String("preflight")
+	if mode == preflightOff {
+		return nil
+	}
+	if globalContainer.cpus > 0 || globalContainer.memory > 0 {
+		console.Infoln("Container limits: " + globalContainer.String())
+	}
+	problems := checkContainerLimits(ctx, concurrency)
+	if !ctx.Bool("loopback") {
+		problems = append(problems, checkHostLimits(ctx, concurrency)...)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	for _, p := range problems {
+		console.Errorln(p)
+	}
+	if mode == preflightFail {
+		fatalIf(errDummy(), "System limits are too low for the benchmark. Use --preflight=warn to run anyway")
+	}
+	return problems
+}
+
+// checkHostLimits returns the open file, ephemeral port and connection tracking limits
+// that are too low for the concurrency.
+func checkHostLimits(ctx *cli.Context, concurrency int) []string {
+	hosts := len(parseHosts(ctx.String("host")))
+	if meta := ctx.String("host.meta"); meta != "" {
+		hosts += len(parseHosts(meta))
+	}
+	if hosts < 1 {
+		hosts = 1
+	}
+	var l preflightLimits
+	l.openFiles, l.openFilesMax, _ = sys.GetMaxOpenFileLimit()
+	l.ephemeralPorts = readPortRange(procPortRange)
+	l.conntrackMax = readProcUint(procConntrackMax)
+	l.conntrackUsed = readProcUint(procConntrackUsed)
+
+	return l.check(concurrency, hosts, ctx.Bool("disable-http-keepalive"))
+}
+
+// check returns the limits that are too low for the concurrency against the number of hosts.
+// Each concurrent operation can keep an idle connection to every host,
+// so up to concurrency*hosts connections can be open.
+func (l *preflightLimits) check(concurrency, hosts int, noKeepAlive bool) []string {
+	var problems []string
+	conns := uint64(concurrency) * uint64(hosts)
+	if need := conns + preflightFiles; l.openFiles > 0 && l.openFiles < need {
+		if need <= l.openFilesMax && sys.SetMaxOpenFileLimit(need, l.openFilesMax) == nil {
+			l.openFiles = need
+		} else {
+			problems = append(problems, fmt.Sprintf("open file limit is %d, but %d concurrent operations with %d host(s) may need %d open files. Raise the limit with 'ulimit -n %d'", l.openFiles, concurrency, hosts, need, need))
+		}
+	}
+	if l.ephemeralPorts > 0 {
+		if uint64(concurrency) > l.ephemeralPorts {
+			problems = append(problems, fmt.Sprintf("%d ephemeral ports are available for connections to each host, but %d concurrent operations are used. Widen %s", l.ephemeralPorts, concurrency, procPortRange))
+		} else if noKeepAlive {
+			console.Infoln(fmt.Sprintf("Without keep-alive, ports of closed connections are reused after about 60s, so at most %d connections per second can be opened to each host.", l.ephemeralPorts/60))
+		}
+	}
+	if l.conntrackMax > 0 && l.conntrackUsed+conns > l.conntrackMax {
+		problems = append(problems, fmt.Sprintf("connection tracking has room for %d connections, but %d may be opened. Raise %s", l.conntrackMax-l.conntrackUsed, conns, procConntrackMax))
+	}
+	return problems
+}
+
+// readPortRange returns the number of ports in a port range file.
+// 0 is returned if the file cannot be read.
+func readPortRange(fn string) uint64 {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return 0
+	}
+	f := strings.Fields(string(b))
+	if len(f) != 2 {
+		return 0
+	}
+	lo, err1 := strconv.ParseUint(f[0], 10, 64)
+	hi, err2 := strconv.ParseUint(f[1], 10, 64)
+	if err1 != nil || err2 != nil || hi < lo {
+		return 0
+	}
+	return hi - lo + 1
+}
+
+// readProcUint returns the number in a file.
+// 0 is returned if the file cannot be read.
+func readProcUint(fn string) uint64 {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return 0
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
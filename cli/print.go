@@ -24,7 +24,6 @@ import (
 	"sync"
 	"unicode"
 
-	"github.com/cheggaaa/pb"
 	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/pkg/console"
 )
@@ -46,28 +45,14 @@ type errorMessage struct {
 
 var printMu sync.Mutex
 
+// printInfo logs the operands formatted like fmt.Print as an info message.
 func printInfo(data ...interface{}) {
-	printMu.Lock()
-	defer printMu.Unlock()
-	w, _ := pb.GetTerminalWidth()
-	if w > 0 {
-		fmt.Print("\r", strings.Repeat(" ", w), "\r")
-	} else {
-		data = append(data, "\n")
-	}
-	console.Info(data...)
+	logInfo(fmt.Sprint(data...))
 }
 
+// printError logs the operands formatted like fmt.Println as an error.
 func printError(data ...interface{}) {
-	printMu.Lock()
-	defer printMu.Unlock()
-	w, _ := pb.GetTerminalWidth()
-	if w > 0 {
-		fmt.Print("\r", strings.Repeat(" ", w), "\r")
-	} else {
-		data = append(data, "\n")
-	}
-	console.Errorln(data...)
+	logError(strings.TrimSuffix(fmt.Sprintln(data...), "\n"))
 }
 
 // fatalIf wrapper function which takes error and selectively prints stack frames if available on debug
@@ -79,6 +64,9 @@ func fatalIf(err *probe.Error, msg string, data ...interface{}) {
 }
 
 func fatal(err *probe.Error, msg string, data ...interface{}) {
+	if connectivityError(err.ToGoError()) {
+		setFatalExitCode(exitConnectivity)
+	}
 	if globalJSON {
 		errorMsg := errorMessage{
 			Message: msg,
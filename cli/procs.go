@@ -0,0 +1,95 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// startLocalClients starts --procs warp clients on this machine and sets --warp-client to use them.
+// Each client gets an equal share of the CPUs.
+// The returned function stops the clients.
+func startLocalClients(ctx *cli.Context) (stop func()) {
+	n := ctx.Int("procs")
+	if n <= 1 {
+		return func() {}
+	}
+	exe, err := os.Executable()
+	fatalIf(probe.NewError(err), "Unable to find warp executable")
+	cpus := runtime.NumCPU() / n
+	if cpus < 1 {
+		cpus = 1
+	}
+
+	cmds := make([]*exec.Cmd, 0, n)
+	// Clients exit when their standard input is closed,
+	// so they are also stopped if this process exits without calling stop.
+	stdins := make([]io.WriteCloser, 0, n)
+	stop = func() {
+		for _, stdin := range stdins {
+			stdin.Close()
+		}
+		for _, cmd := range cmds {
+			cmd.Wait()
+		}
+	}
+	hosts := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		addr, err := freeLocalAddr()
+		if err != nil {
+			stop()
+			fatalIf(probe.NewError(err), "Unable to find a free port for local client")
+		}
+		cmd := exec.Command(exe, "client", "--exit.stdin", addr)
+		cmd.Env = append(os.Environ(), "GOMAXPROCS="+strconv.Itoa(cpus))
+		cmd.Stderr = os.Stderr
+		stdin, err := cmd.StdinPipe()
+		if err == nil {
+			err = cmd.Start()
+		}
+		if err != nil {
+			stop()
+			fatalIf(probe.NewError(err), "Unable to start local client")
+		}
+		cmds = append(cmds, cmd)
+		stdins = append(stdins, stdin)
+		hosts = append(hosts, addr)
+	}
+	printInfo("Started ", n, " local clients with ", cpus, " CPUs each.")
+	ctx.Set("warp-client", strings.Join(hosts, ","))
+	return stop
+}
+
+// freeLocalAddr returns a loopback address with a port that is not in use.
+func freeLocalAddr() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	return l.Addr().String(), nil
+}
@@ -0,0 +1,182 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// Prefixes of provenance lines stored in benchmark data.
+const (
+	provWarp   = "Warp: "
+	provClient = "Client: "
+	provServer = "Server: "
+	provConfig = "Config: "
+	provNote   = "Note: "
+)
+
+// serverProbeTimeout is the maximum time to wait for server information.
+const serverProbeTimeout = 5 * time.Second
+
+// probeServers returns a description of each server of the first host.
+// Server information requires admin credentials, otherwise the error is returned.
+// With --loopback the in-memory server is described.
+func probeServers(ctx *cli.Context) []string {
+	if ctx.Bool("loopback") {
+		if f := loopbackFaults(ctx).String(); f != "" {
+			return []string{"in-memory loopback with " + f + ", no requests sent"}
+		}
+		return []string{"in-memory loopback, no requests sent"}
+	}
+	bg, cancel := context.WithTimeout(context.Background(), serverProbeTimeout)
+	defer cancel()
+	info, err := newAdminClient(ctx).ServerInfo(bg)
+	if err != nil {
+		return []string{fmt.Sprintf("%s unknown (%v)", redactHost(ctx, parseHosts(ctx.String("host"))[0]), err)}
+	}
+	res := make([]string, 0, len(info.Servers))
+	for _, s := range info.Servers {
+		res = append(res, fmt.Sprintf("%s %s, version %s, commit %s", redactHost(ctx, s.Endpoint), s.State, s.Version, s.CommitID))
+	}
+	return res
+}
+
+// redactHost returns the host, hashed if redaction is requested.
+func redactHost(ctx *cli.Context, host string) string {
+	if !ctx.Bool(redactFlag.Name) {
+		return host
+	}
+	return bench.RedactName(redactSalt, host)
+}
+
+// effectiveConfig returns all flags of the command with their current values,
+// including defaults.
+func effectiveConfig(ctx *cli.Context) string {
+	var res []string
+	for _, flag := range ctx.Command.Flags {
+		name := flag.GetName()
+		var val string
+		switch flag.(type) {
+		case cli.StringFlag:
+			val = ctx.String(name)
+		case cli.BoolFlag:
+			val = fmt.Sprint(ctx.Bool(name))
+		case cli.Int64Flag:
+			val = fmt.Sprint(ctx.Int64(name))
+		case cli.IntFlag:
+			val = fmt.Sprint(ctx.Int(name))
+		case cli.DurationFlag:
+			val = ctx.Duration(name).String()
+		case cli.UintFlag:
+			val = fmt.Sprint(ctx.Uint(name))
+		case cli.Uint64Flag:
+			val = fmt.Sprint(ctx.Uint64(name))
+		case cli.Float64Flag:
+			val = fmt.Sprint(ctx.Float64(name))
+		default:
+			continue
+		}
+		res = append(res, "--"+name+"="+redactFlagValue(ctx, name, val))
+	}
+	return strings.Join(res, " ")
+}
+
+// benchProvenance returns the description of the benchmark run stored with benchmark data.
+// The first line is the command line.
+func benchProvenance(ctx *cli.Context, servers, notes []string) string {
+	lines := []string{
+		commandLine(ctx),
+		fmt.Sprintf("%sversion %s, commit %s, %s/%s, %s", provWarp, pkg.Version, pkg.CommitID, runtime.GOOS, runtime.GOARCH, runtime.Version()),
+	}
+	if host, err := os.Hostname(); err == nil {
+		lines = append(lines, provClient+redactHost(ctx, host))
+	}
+	for _, s := range servers {
+		lines = append(lines, provServer+s)
+	}
+	for _, n := range notes {
+		lines = append(lines, provNote+n)
+	}
+	lines = append(lines, provConfig+effectiveConfig(ctx))
+	return strings.Join(lines, "\n")
+}
+
+// printProvenance prints the provenance stored in benchmark data.
+func printProvenance(comments []string) {
+	if globalJSON || len(comments) == 0 {
+		return
+	}
+	console.SetColor("Print", color.New(color.FgHiWhite))
+	console.Println("Benchmark run:")
+	console.SetColor("Print", color.New(color.FgWhite))
+	for _, c := range comments {
+		if strings.HasPrefix(c, provConfig) {
+			// Too long to be useful in the output.
+			continue
+		}
+		console.Println(" * " + c)
+	}
+	console.Println("")
+}
+
+// printProvenanceDiff prints the differences between the provenance of two benchmark runs.
+func printProvenanceDiff(before, after []string) {
+	if globalJSON {
+		return
+	}
+	contains := func(lines []string, s string) bool {
+		for _, l := range lines {
+			if l == s {
+				return true
+			}
+		}
+		return false
+	}
+	var diff []string
+	for _, c := range before {
+		if !strings.HasPrefix(c, provConfig) && !contains(after, c) {
+			diff = append(diff, " * Before: "+c)
+		}
+	}
+	for _, c := range after {
+		if !strings.HasPrefix(c, provConfig) && !contains(before, c) {
+			diff = append(diff, " * After: "+c)
+		}
+	}
+	if len(diff) == 0 {
+		return
+	}
+	console.SetColor("Print", color.New(color.FgHiWhite))
+	console.Println("Benchmark run differences:")
+	console.SetColor("Print", color.New(color.FgWhite))
+	for _, d := range diff {
+		console.Println(d)
+	}
+	console.Println("")
+}
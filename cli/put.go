@@ -36,6 +36,14 @@ var putFlags = []cli.Flag{
 		Usage:  "Multipart part size. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
 		Hidden: true,
 	},
+	cli.BoolFlag{
+		Name:  "put.head",
+		Usage: "Check if each object exists with a HEAD request before uploading it.",
+	},
+	cli.Float64Flag{
+		Name:  "put.overwrite",
+		Usage: "Fraction of uploads that overwrite a previously uploaded object. Must be between 0 and 1.",
+	},
 }
 
 // Put command.
@@ -64,12 +72,14 @@ func mainPut(ctx *cli.Context) error {
 	b := bench.Put{
 		Common: bench.Common{
 			Client:      newClient(ctx),
-			Concurrency: ctx.Int("concurrent"),
+			Concurrency: getConcurrency(ctx),
 			Source:      src,
 			Bucket:      ctx.String("bucket"),
 			Location:    "",
 			PutOpts:     putOpts(ctx),
 		},
+		HeadFirst: ctx.Bool("put.head"),
+		Overwrite: ctx.Float64("put.overwrite"),
 	}
 	return runBench(ctx, &b)
 }
@@ -77,13 +87,18 @@ func mainPut(ctx *cli.Context) error {
 // putOpts retrieves put options from the context.
 func putOpts(ctx *cli.Context) minio.PutObjectOptions {
 	pSize, _ := toSize(ctx.String("part.size"))
-	return minio.PutObjectOptions{
+	opts := minio.PutObjectOptions{
 		ServerSideEncryption: newSSE(ctx),
 		DisableMultipart:     ctx.Bool("disable-multipart"),
 		SendContentMd5:       ctx.Bool("md5"),
 		StorageClass:         ctx.String("storage-class"),
 		PartSize:             pSize,
 	}
+	if ctx.Bool("obj.tar.extract") {
+		// MinIO extracts archives uploaded with this header.
+		opts.UserMetadata = map[string]string{"X-Amz-Meta-Snowball-Auto-Extract": "true"}
+	}
+	return opts
 }
 
 func checkPutSyntax(ctx *cli.Context) {
@@ -91,6 +106,10 @@ func checkPutSyntax(ctx *cli.Context) {
 		console.Fatal("Command takes no arguments")
 	}
 
+	if r := ctx.Float64("put.overwrite"); r < 0 || r > 1 {
+		console.Fatal("--put.overwrite must be between 0 and 1")
+	}
+
 	checkAnalyze(ctx)
 	checkBenchmark(ctx)
 }
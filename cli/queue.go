@@ -0,0 +1,92 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+var queueFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "4KiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.IntFlag{
+		Name:  "queue.consumers",
+		Value: 1,
+		Usage: "Number of consumers. The remaining concurrency is used for producers.",
+	},
+	cli.DurationFlag{
+		Name:  "queue.poll",
+		Value: 10 * time.Millisecond,
+		Usage: "Time for consumers to wait before listing again when the queue is empty.",
+	},
+}
+
+var queueCmd = cli.Command{
+	Name:   "queue",
+	Usage:  "benchmark using a prefix as a queue",
+	Action: mainQueue,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, queueFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+  -> see https://github.com/minio/warp#queue
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainQueue is the entry point for queue command.
+func mainQueue(ctx *cli.Context) error {
+	checkQueueSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	b := bench.Queue{
+		Common: bench.Common{
+			Client:      newClient(ctx),
+			Concurrency: getConcurrency(ctx),
+			Source:      src,
+			Bucket:      ctx.String("bucket"),
+			Location:    "",
+			PutOpts:     putOpts(ctx),
+		},
+		Consumers: ctx.Int("queue.consumers"),
+		Poll:      ctx.Duration("queue.poll"),
+	}
+	return runBench(ctx, &b)
+}
+
+func checkQueueSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if n := ctx.Int("queue.consumers"); n < 1 || n >= getConcurrency(ctx) {
+		console.Fatal("--queue.consumers must be at least 1 and less than --concurrent")
+	}
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
@@ -0,0 +1,58 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/warp/pkg/bench"
+)
+
+var redactFlag = cli.BoolFlag{
+	Name:  "redact",
+	Usage: "Replace bucket names, prefixes and object keys with hashes in the benchmark data.",
+}
+
+// redactSalt is used for all hashes in a run, so equal names have equal hashes.
+var redactSalt = pRandASCII(16)
+
+// redactOps redacts object and bucket names in the operations if requested.
+// Bucket names are taken from the flags, in addition to the given buckets.
+func redactOps(ctx *cli.Context, ops bench.Operations, buckets ...string) {
+	if ctx.Bool(redactFlag.Name) {
+		ops.Redact(redactSalt, append(buckets, ctx.String("bucket"), ctx.String("mirror.bucket"))...)
+	}
+}
+
+// provenanceBuckets returns the bucket names of the configuration stored in benchmark data.
+func provenanceBuckets(comments []string) []string {
+	var res []string
+	for _, c := range comments {
+		if !strings.HasPrefix(c, provConfig) {
+			continue
+		}
+		for _, f := range strings.Split(strings.TrimPrefix(c, provConfig), " ") {
+			name, val, ok := strings.Cut(strings.TrimPrefix(f, "--"), "=")
+			if ok && val != "" && (name == "bucket" || name == "mirror.bucket") {
+				res = append(res, val)
+			}
+		}
+	}
+	return res
+}
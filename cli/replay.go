@@ -0,0 +1,123 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"io"
+	"os"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+	"github.com/minio/warp/pkg/generator"
+)
+
+var replayFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "replay.timing",
+		Usage: "Start each operation at the same time offset as in the original run. Otherwise operations are started as soon as the previous on the same thread is done.",
+	},
+}
+
+var replayCmd = cli.Command{
+	Name:   "replay",
+	Usage:  "replay the operations of a previous benchmark run",
+	Action: mainReplay,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, replayFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] benchmark-data-file
+  -> see https://github.com/minio/warp#replay
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainReplay is the entry point for replay command.
+func mainReplay(ctx *cli.Context) error {
+	checkReplaySyntax(ctx)
+	var input io.Reader
+	if arg := ctx.Args().First(); arg == "-" {
+		input = os.Stdin
+	} else {
+		f, err := os.Open(arg)
+		fatalIf(probe.NewError(err), "Unable to open input file")
+		defer f.Close()
+		input = f
+	}
+	dec, err := newBenchDataReader(input, ctx.String(benchDataKeyFlag.Name))
+	fatalIf(probe.NewError(err), "Unable to read input")
+	log := console.Printf
+	if globalQuiet {
+		log = nil
+	}
+	ops, err := bench.OperationsFromCSV(dec, false, 0, 0, log)
+	dec.Close()
+	fatalIf(probe.NewError(err), "Unable to parse input")
+
+	// Generate data up to the largest object.
+	var maxSize int64 = 1
+	skipped := make(map[string]int)
+	for _, op := range ops {
+		if !bench.ReplaySupported(op) {
+			skipped[op.OpType]++
+			continue
+		}
+		if op.Size > maxSize {
+			maxSize = op.Size
+		}
+	}
+	for typ, n := range skipped {
+		console.Infof("Skipping %d %s operations that cannot be replayed\n", n, typ)
+	}
+	src, err := generator.NewFn(generator.WithRandomData().Apply(), generator.WithSize(maxSize))
+	fatalIf(probe.NewError(err), "Unable to create data generator")
+
+	b := bench.Replay{
+		Common: bench.Common{
+			Client:      newClient(ctx),
+			Concurrency: bench.ReplayThreads(ops),
+			Source:      src,
+			Bucket:      ctx.String("bucket"),
+			Location:    "",
+			PutOpts:     putOpts(ctx),
+		},
+		Ops:    ops,
+		Timing: ctx.Bool("replay.timing"),
+	}
+	if b.Concurrency == 0 {
+		console.Fatal("No operations in benchmark data can be replayed")
+	}
+	return runBench(ctx, &b)
+}
+
+func checkReplaySyntax(ctx *cli.Context) {
+	if ctx.NArg() != 1 {
+		console.Fatal("One benchmark data file must be supplied")
+	}
+	if ctx.String("warp-client") != "" {
+		console.Fatal("Replay cannot be run on remote clients")
+	}
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
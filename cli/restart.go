@@ -0,0 +1,120 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/warp/api"
+)
+
+// restartAnnotation is the annotation label of the time a server is restarting.
+const restartAnnotation = "restart"
+
+// restartNode calls the restart hook for a single host.
+// URL hooks receive a POST request with the host as "host" parameter,
+// other hooks are executed with the host as argument.
+func restartNode(ctx context.Context, hook, host string) error {
+	if strings.HasPrefix(hook, "http://") || strings.HasPrefix(hook, "https://") {
+		u, err := url.Parse(hook)
+		if err != nil {
+			return err
+		}
+		q := u.Query()
+		q.Set("host", host)
+		u.RawQuery = q.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(ioutil.Discard, resp.Body)
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("restart hook returned status %s", resp.Status)
+		}
+		return nil
+	}
+	cmd := exec.CommandContext(ctx, hook, host)
+	cmd.Env = append(os.Environ(), "WARP_RESTART_HOST="+host)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("restart hook: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// startRollingRestart will restart all hosts one by one using the restart hook,
+// starting when the configured time after the benchmark start has passed.
+// Each restart is annotated as "restart" in the monitor.
+func startRollingRestart(ctx2 context.Context, ctx *cli.Context, tStart time.Time, monitor *api.Server) {
+	hook := ctx.String("restart.hook")
+	if hook == "" {
+		return
+	}
+	hosts := parseHosts(ctx.String("restart.hosts"))
+	if len(hosts) == 0 {
+		hosts = parseHosts(ctx.String("host"))
+	}
+	after := ctx.Duration("restart.after")
+	interval := ctx.Duration("restart.interval")
+	go func() {
+		select {
+		case <-ctx2.Done():
+			return
+		case <-time.After(time.Until(tStart.Add(after))):
+		}
+		for i, host := range hosts {
+			if i > 0 {
+				select {
+				case <-ctx2.Done():
+					return
+				case <-time.After(interval):
+				}
+			}
+			monitor.InfoLn("Restarting ", host, "...")
+			monitor.StartAnnotation(restartAnnotation)
+			start := time.Now()
+			err := restartNode(ctx2, hook, host)
+			if ctx2.Err() != nil {
+				// Annotation is closed when the benchmark ends.
+				return
+			}
+			monitor.EndAnnotation(restartAnnotation)
+			if err != nil {
+				errorIf(probe.NewError(err), "Unable to restart %s", host)
+				continue
+			}
+			monitor.InfoLn("Restarted ", host, " in ", time.Since(start).Round(time.Millisecond))
+		}
+	}()
+}
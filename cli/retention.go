@@ -66,7 +66,7 @@ func mainRetention(ctx *cli.Context) error {
 	b := bench.Retention{
 		Common: bench.Common{
 			Client:      newClient(ctx),
-			Concurrency: ctx.Int("concurrent"),
+			Concurrency: getConcurrency(ctx),
 			Source:      src,
 			Bucket:      ctx.String("bucket"),
 			Location:    "",
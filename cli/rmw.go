@@ -0,0 +1,106 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+var rmwFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "objects",
+		Value: 1000,
+		Usage: "Number of objects to upload.",
+	},
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "1MiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.Float64Flag{
+		Name:  "rmw.mutate",
+		Value: 10,
+		Usage: "Percentage of bytes to modify before uploading the object again.",
+	},
+	cli.BoolFlag{
+		Name:  "rmw.versioned",
+		Usage: "Enable versioning, so each write creates a new version.",
+	},
+}
+
+var rmwCmd = cli.Command{
+	Name:   "rmw",
+	Usage:  "benchmark read-modify-write of objects",
+	Action: mainRMW,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, rmwFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+  -> see https://github.com/minio/warp#rmw
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainRMW is the entry point for rmw command.
+func mainRMW(ctx *cli.Context) error {
+	checkRMWSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	b := bench.ReadModifyWrite{
+		Common: bench.Common{
+			Client:      newClient(ctx),
+			Concurrency: getConcurrency(ctx),
+			Source:      src,
+			Bucket:      ctx.String("bucket"),
+			Location:    "",
+			PutOpts:     putOpts(ctx),
+		},
+		CreateObjects: ctx.Int("objects"),
+		Versions:      ctx.Bool("rmw.versioned"),
+		Mutate:        ctx.Float64("rmw.mutate") / 100,
+	}
+	err := runBench(ctx, &b)
+	if err != nil || globalJSON {
+		return err
+	}
+	if total := b.Conflicts + b.Updates; total > 0 {
+		console.Println("\n----------------------------------------")
+		console.Printf("Writes: %d, conflicts: %d (%.02f%% of read-modify-write cycles).\n", b.Updates, b.Conflicts, 100*float64(b.Conflicts)/float64(total))
+	}
+	return nil
+}
+
+func checkRMWSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if m := ctx.Float64("rmw.mutate"); m < 0 || m > 100 {
+		console.Fatal("--rmw.mutate must be between 0 and 100")
+	}
+	if ctx.Int("objects") < 1 {
+		console.Fatal("At least one object must be used")
+	}
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
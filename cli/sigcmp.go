@@ -0,0 +1,97 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+var sigCmpFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "1MiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+}
+
+var sigCmpCmd = cli.Command{
+	Name:   "sigcmp",
+	Usage:  "compare put performance of payload signing modes",
+	Action: mainSigCmp,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, sigCmpFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+  -> see https://github.com/minio/warp#sigcmp
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainSigCmp is the entry point for sigcmp command.
+func mainSigCmp(ctx *cli.Context) error {
+	checkSigCmpSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	b := bench.SigCompare{
+		Common: bench.Common{
+			Client:      newClient(ctx),
+			Concurrency: getConcurrency(ctx),
+			Source:      src,
+			Bucket:      ctx.String("bucket"),
+			Location:    "",
+			PutOpts:     putOpts(ctx),
+		},
+		PhaseDur: ctx.Duration("duration") / time.Duration(len(bench.SignModes)),
+	}
+	err := runBench(ctx, &b)
+	if err != nil || len(b.CPU) == 0 || globalJSON {
+		return err
+	}
+	console.Println("\n----------------------------------------")
+	console.Println("Client CPU time per signing mode:")
+	for _, mode := range bench.SignModes {
+		if cpu, ok := b.CPU[mode]; ok {
+			console.Printf(" * %s: %v (%.02f cores)\n", bench.SigCompareOpType(mode), cpu.Round(time.Millisecond), cpu.Seconds()/b.PhaseDur.Seconds())
+		}
+	}
+	return nil
+}
+
+func checkSigCmpSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if !strings.EqualFold(ctx.String("signature"), "S3V4") {
+		console.Fatal("sigcmp requires --signature=S3V4")
+	}
+	if ctx.Bool("tls") {
+		console.Infoln("Note: chunked signing is not used on TLS connections.")
+	}
+
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
@@ -0,0 +1,64 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// sizeHistogramWidth is the width of the largest bar of the histogram.
+const sizeHistogramWidth = 40
+
+// printSizeHistogram prints the realized object sizes
+// of operation types with multiple sizes.
+func printSizeHistogram(o bench.Operations) {
+	if globalJSON || len(o) == 0 {
+		return
+	}
+	for _, typ := range o.OpTypes() {
+		ops := o.FilterByOp(typ)
+		if !ops.MultipleSizes() {
+			continue
+		}
+		hist := ops.SizeHistogram()
+		total, most := 0, 0
+		for _, b := range hist {
+			total += b.Count
+			if b.Count > most {
+				most = b.Count
+			}
+		}
+		if most == 0 {
+			continue
+		}
+		console.Println("\n----------------------------------------")
+		console.SetColor("Print", color.New(color.FgHiWhite))
+		console.Printf("Object sizes of %s operations:\n", typ)
+		console.SetColor("Print", color.New(color.FgWhite))
+		for _, b := range hist {
+			bar := strings.Repeat("#", (b.Count*sizeHistogramWidth+most-1)/most)
+			console.Printf(" * %9s -> %9s: %6.2f%% %s\n", humanize.IBytes(uint64(b.Min)), humanize.IBytes(uint64(b.Max)),
+				100*float64(b.Count)/float64(total), bar)
+		}
+	}
+}
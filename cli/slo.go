@@ -0,0 +1,145 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/aggregate"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// parseSLO parses latency thresholds as a comma separated list of OP:duration.
+// A duration without an operation type applies to all other operation types
+// and is returned with an empty key.
+func parseSLO(s string) (map[string]time.Duration, error) {
+	res := make(map[string]time.Duration)
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		var typ string
+		if idx := strings.LastIndexByte(v, ':'); idx >= 0 {
+			typ, v = strings.ToUpper(v[:idx]), v[idx+1:]
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 {
+			return nil, errors.New("latency threshold must be positive")
+		}
+		res[typ] = d
+	}
+	return res, nil
+}
+
+func checkSLO(ctx *cli.Context) {
+	_, err := parseSLO(ctx.String("analyze.slo"))
+	fatalIf(probe.NewError(err), "Invalid -analyze.slo value")
+	if t := ctx.Float64("analyze.slo.target"); t <= 0 || t >= 100 {
+		fatal(probe.NewError(errors.New("must be above 0 and below 100")), "Invalid -analyze.slo.target value")
+	}
+}
+
+// sloBurnRates returns the latency objective compliance of each operation type
+// with a threshold.
+func sloBurnRates(ctx *cli.Context, o bench.Operations) []aggregate.SLO {
+	thresholds, err := parseSLO(ctx.String("analyze.slo"))
+	if err != nil || len(thresholds) == 0 || len(o) == 0 {
+		return nil
+	}
+	target := ctx.Float64("analyze.slo.target") / 100
+	interval := analysisDur(ctx, o.Duration())
+	var res []aggregate.SLO
+	for _, typ := range o.OpTypes() {
+		threshold, ok := thresholds[typ]
+		if !ok {
+			threshold, ok = thresholds[""]
+		}
+		if !ok {
+			continue
+		}
+		res = append(res, aggregate.SLOBurnRate(o.FilterByOp(typ), threshold, target, interval))
+	}
+	return res
+}
+
+// printSLO prints the latency objective compliance.
+// Intervals are only printed with details.
+func printSLO(slos []aggregate.SLO, details bool) {
+	if globalJSON {
+		return
+	}
+	for _, slo := range slos {
+		console.Println("\n----------------------------------------")
+		console.SetColor("Print", color.New(color.FgHiWhite))
+		threshold := time.Duration(slo.ThresholdMillis * float64(time.Millisecond))
+		console.Printf("SLO: %s within %v, target %g%%.\n", slo.Type, threshold, slo.Target*100)
+		console.SetColor("Print", color.New(color.FgWhite))
+		console.Printf(" * Exceeded: %d of %d (%.3f%%). Burn rate: %.2f.\n", slo.Exceeded, slo.N, 100*float64(slo.Exceeded)/float64(slo.N), slo.BurnRate)
+		burning := 0
+		var worst aggregate.SLOInterval
+		for _, iv := range slo.Intervals {
+			if iv.BurnRate > 1 {
+				burning++
+			}
+			if iv.BurnRate > worst.BurnRate {
+				worst = iv
+			}
+		}
+		if slo.Exceeded > 0 {
+			console.Printf(" * Misses:")
+			prev := 1.0
+			for _, m := range slo.Misses {
+				if m.MaxFactor == 0 {
+					console.Printf(" >%gx: %d,", prev, m.N)
+					continue
+				}
+				console.Printf(" %g-%gx: %d,", prev, m.MaxFactor, m.N)
+				prev = m.MaxFactor
+			}
+			console.Printf(" errors: %d.\n", slo.Errors)
+		}
+		dur := time.Duration(slo.IntervalMillis) * time.Millisecond
+		console.Printf(" * Intervals above budget: %d of %d x %v.\n", burning, len(slo.Intervals), dur)
+		if worst.N > 0 {
+			console.Printf(" * Worst interval: Burn rate %.2f, starting %s.\n", worst.BurnRate, worst.Start.Format(bench.SegmentTimeLayout(dur)))
+		}
+		if !details {
+			continue
+		}
+		console.SetColor("Print", color.New(color.FgHiWhite))
+		console.Println("\nBurn rate, split into", len(slo.Intervals), "x", dur.String()+":")
+		for _, iv := range slo.Intervals {
+			if iv.BurnRate > 1 {
+				console.SetColor("Print", color.New(color.FgHiRed))
+			} else {
+				console.SetColor("Print", color.New(color.FgWhite))
+			}
+			console.Printf(" * %s: %.2f (%d of %d)\n", iv.Start.Format(bench.SegmentTimeLayout(dur)), iv.BurnRate, iv.Exceeded, iv.N)
+		}
+	}
+}
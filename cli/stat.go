@@ -40,6 +40,10 @@ var statFlags = []cli.Flag{
 		Value: 1,
 		Usage: "Number of versions to upload. If more than 1, versioned listing will be benchmarked",
 	},
+	cli.Float64Flag{
+		Name:  "stat.miss",
+		Usage: "Fraction of requests for objects that do not exist. Must be between 0 and 1.",
+	},
 }
 
 var statCmd = cli.Command{
@@ -69,7 +73,7 @@ func mainStat(ctx *cli.Context) error {
 	b := bench.Stat{
 		Common: bench.Common{
 			Client:      newClient(ctx),
-			Concurrency: ctx.Int("concurrent"),
+			Concurrency: getConcurrency(ctx),
 			Source:      src,
 			Bucket:      ctx.String("bucket"),
 			Location:    "",
@@ -77,6 +81,7 @@ func mainStat(ctx *cli.Context) error {
 		},
 		Versions:      ctx.Int("versions"),
 		CreateObjects: ctx.Int("objects"),
+		MissRate:      ctx.Float64("stat.miss"),
 		StatOpts: minio.StatObjectOptions{
 			ServerSideEncryption: sse,
 		},
@@ -91,6 +96,9 @@ func checkStatSyntax(ctx *cli.Context) {
 	if ctx.Int("versions") < 1 {
 		console.Fatal("At least one version must be tested")
 	}
+	if r := ctx.Float64("stat.miss"); r < 0 || r > 1 {
+		console.Fatal("--stat.miss must be between 0 and 1")
+	}
 	checkAnalyze(ctx)
 	checkBenchmark(ctx)
 }
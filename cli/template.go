@@ -0,0 +1,106 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// templateFlags are the flags that can contain template variables.
+var templateFlags = []string{"bucket", "prefix", "benchdata"}
+
+// flagTemplates contains the values of flags before templates were expanded.
+// Distributed benchmarks send these to the clients, so each client expands them.
+var flagTemplates = map[string]string{}
+
+// expandFlagTemplates expands template variables in the values of templateFlags.
+// Supported are {{.date}}, {{.time}}, {{.clientID}}, {{.hostname}}, {{.command}}
+// and {{env "NAME"}}, which fails if the environment variable is not set.
+// The time is sent to distributed clients, so all clients expand the same time.
+func expandFlagTemplates(ctx *cli.Context) {
+	var vars map[string]string
+	for _, name := range templateFlags {
+		v := ctx.String(name)
+		if !strings.Contains(v, "{{") {
+			continue
+		}
+		if vars == nil {
+			vars = templateVars(ctx)
+		}
+		expanded, err := expandTemplate(v, vars)
+		fatalIf(probe.NewError(err), "Invalid template in --%s", name)
+		fatalIf(probe.NewError(ctx.Set(name, expanded)), "Unable to set --%s", name)
+		flagTemplates[name] = v
+	}
+}
+
+// templateVars returns the template variables of the benchmark.
+func templateVars(ctx *cli.Context) map[string]string {
+	t := time.Now()
+	if s := ctx.String("template.time"); s != "" {
+		var err error
+		t, err = time.Parse(time.RFC3339, s)
+		fatalIf(probe.NewError(err), "Invalid --template.time")
+	} else {
+		// Commands without the flag ignore it.
+		ctx.Set("template.time", t.Format(time.RFC3339))
+	}
+	clientIdx := 0
+	activeBenchmarkMu.Lock()
+	if activeBenchmark != nil {
+		clientIdx = activeBenchmark.clientIdx
+	}
+	activeBenchmarkMu.Unlock()
+	hostname, _ := os.Hostname()
+	return map[string]string{
+		"date":     t.Format("2006-01-02"),
+		"time":     t.Format("150405"),
+		"clientID": strconv.Itoa(clientIdx),
+		"hostname": hostname,
+		"command":  ctx.Command.Name,
+	}
+}
+
+// expandTemplate expands the variables in s.
+func expandTemplate(s string, vars map[string]string) (string, error) {
+	t, err := template.New("").Option("missingkey=error").Funcs(template.FuncMap{
+		"env": func(name string) (string, error) {
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				return "", fmt.Errorf("environment variable %s is not set", name)
+			}
+			return v, nil
+		},
+	}).Parse(s)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, vars); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,419 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/aggregate"
+)
+
+var trendDBFlag = cli.StringFlag{
+	Name:   "trend.db",
+	Usage:  "Trend database file. The headline metrics of each analysis are appended to it.",
+	EnvVar: appNameUC + "_TREND_DB",
+}
+
+var trendLabelFlag = cli.StringFlag{
+	Name:  "trend.label",
+	Usage: "Comma separated key=value labels of the run, stored in the trend database. When charting trends only runs with these labels are included. Eg: 'cluster=lab,drives=nvme'.",
+}
+
+var trendFlags = []cli.Flag{
+	trendDBFlag,
+	trendLabelFlag,
+	benchDataKeyFlag,
+	cli.StringFlag{
+		Name:  "trend.op",
+		Usage: "Only chart this operation type. Can be GET/PUT/DELETE, etc.",
+	},
+	cli.StringFlag{
+		Name:  "trend.benchmark",
+		Usage: "Only chart runs of this benchmark, for instance 'get' or 'mixed'.",
+	},
+	cli.StringFlag{
+		Name:  "trend.metric",
+		Usage: "Metric to chart. Can be " + strings.Join(trendMetricNames(), ", ") + ".",
+		Value: "throughput",
+	},
+	cli.DurationFlag{
+		Name:  "trend.since",
+		Usage: "Only chart runs within this duration before now.",
+	},
+}
+
+var trendCmd = cli.Command{
+	Name:   "trend",
+	Usage:  "chart a metric of analyzed runs over time",
+	Action: mainTrend,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, trendFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} --trend.db=file [FLAGS]
+  -> see https://github.com/minio/warp#trends
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// trendRecord is the headline metrics of an operation type of an analyzed run.
+// The trend database contains a JSON record on each line.
+type trendRecord struct {
+	Time        time.Time         `json:"time"`
+	Benchmark   string            `json:"benchmark,omitempty"`
+	Op          string            `json:"op"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Concurrency int               `json:"concurrency"`
+	Hosts       int               `json:"hosts"`
+	Clients     int               `json:"clients"`
+	Requests    int               `json:"requests"`
+	Errors      int               `json:"errors"`
+	BPS         float64           `json:"bps"`
+	OPS         float64           `json:"ops"`
+	// Request times in milliseconds. Percentiles are only recorded
+	// when all requests have the same size.
+	LatAvgMillis float64 `json:"lat_avg_millis,omitempty"`
+	LatP50Millis float64 `json:"lat_p50_millis,omitempty"`
+	LatP90Millis float64 `json:"lat_p90_millis,omitempty"`
+	LatP99Millis float64 `json:"lat_p99_millis,omitempty"`
+}
+
+// trendMetric is a metric that can be charted.
+type trendMetric struct {
+	name  string
+	value func(r trendRecord) float64
+	fmt   func(v float64) string
+}
+
+func fmtTrendMillis(v float64) string {
+	return time.Duration(v * float64(time.Millisecond)).Round(time.Microsecond * 100).String()
+}
+
+var trendMetrics = []trendMetric{
+	{name: "throughput", value: func(r trendRecord) float64 { return r.BPS }, fmt: func(v float64) string { return humanize.IBytes(uint64(v)) + "/s" }},
+	{name: "ops", value: func(r trendRecord) float64 { return r.OPS }, fmt: func(v float64) string { return fmt.Sprintf("%.2f obj/s", v) }},
+	{name: "errors", value: func(r trendRecord) float64 { return float64(r.Errors) }, fmt: func(v float64) string { return fmt.Sprint(v) }},
+	{name: "latency.avg", value: func(r trendRecord) float64 { return r.LatAvgMillis }, fmt: fmtTrendMillis},
+	{name: "latency.p50", value: func(r trendRecord) float64 { return r.LatP50Millis }, fmt: fmtTrendMillis},
+	{name: "latency.p90", value: func(r trendRecord) float64 { return r.LatP90Millis }, fmt: fmtTrendMillis},
+	{name: "latency.p99", value: func(r trendRecord) float64 { return r.LatP99Millis }, fmt: fmtTrendMillis},
+}
+
+func trendMetricNames() []string {
+	res := make([]string, 0, len(trendMetrics))
+	for _, m := range trendMetrics {
+		res = append(res, m.name)
+	}
+	return res
+}
+
+// parseTrendLabels parses comma separated key=value labels.
+func parseTrendLabels(s string) (map[string]string, error) {
+	res := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(kv, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("label %q: want key=value", kv)
+		}
+		res[k] = strings.TrimSpace(v)
+	}
+	return res, nil
+}
+
+func checkTrend(ctx *cli.Context) {
+	_, err := parseTrendLabels(ctx.String(trendLabelFlag.Name))
+	fatalIf(probe.NewError(err), "Invalid -trend.label value")
+}
+
+// recordTrend appends the headline metrics of each operation type to the trend database.
+func recordTrend(ctx *cli.Context, benchmark string, aggr aggregate.Aggregated) {
+	fn := ctx.String(trendDBFlag.Name)
+	if fn == "" || len(aggr.Operations) == 0 {
+		return
+	}
+	labels, err := parseTrendLabels(ctx.String(trendLabelFlag.Name))
+	fatalIf(probe.NewError(err), "Invalid -trend.label value")
+	if len(labels) == 0 {
+		labels = nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, ops := range aggr.Operations {
+		if ops.Skipped {
+			continue
+		}
+		r := trendRecord{
+			Time:        ops.StartTime,
+			Benchmark:   benchmark,
+			Op:          ops.Type,
+			Labels:      labels,
+			Concurrency: ops.Concurrency,
+			Hosts:       ops.Hosts,
+			Clients:     ops.Clients,
+			Requests:    ops.N,
+			Errors:      ops.Errors,
+			BPS:         ops.Throughput.AverageBPS,
+			OPS:         ops.Throughput.AverageOPS,
+		}
+		switch {
+		case ops.SingleSizedRequests != nil && !ops.SingleSizedRequests.Skipped:
+			req := ops.SingleSizedRequests
+			r.LatAvgMillis = float64(req.DurAvgMillis)
+			r.LatP50Millis = float64(req.DurMedianMillis)
+			r.LatP90Millis = float64(req.Dur90Millis)
+			r.LatP99Millis = float64(req.Dur99Millis)
+		case ops.MultiSizedRequests != nil && !ops.MultiSizedRequests.Skipped:
+			var total float64
+			var n int
+			for _, s := range ops.MultiSizedRequests.BySize {
+				total += float64(s.AvgDurationMillis) * float64(s.Requests)
+				n += s.Requests
+			}
+			if n > 0 {
+				r.LatAvgMillis = total / float64(n)
+			}
+		}
+		fatalIf(probe.NewError(enc.Encode(r)), "Unable to write trend database")
+	}
+	fatalIf(probe.NewError(appendTrends(fn, ctx.String(benchDataKeyFlag.Name), buf.Bytes())), "Unable to write trend database")
+	if !globalJSON {
+		console.Println("Trend metrics appended to", fn)
+	}
+}
+
+// appendTrends appends encoded records to the trend database, encrypted if a key is given.
+func appendTrends(fn, key string, records []byte) error {
+	var f io.WriteCloser
+	var err error
+	if key == "" {
+		f, err = os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o666)
+	} else {
+		f, _, err = appendEncryptedFile(fn, key)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(records); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readTrends reads all records of the trend database, decrypting it with the key if it is encrypted.
+func readTrends(fn, key string) ([]trendRecord, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := newDecryptReader(f, key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	res, err := decodeTrends(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	return res, nil
+}
+
+// decodeTrends decodes the records of a trend database.
+func decodeTrends(r io.Reader) ([]trendRecord, error) {
+	var res []trendRecord
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), 16<<20)
+	line := 0
+	for sc.Scan() {
+		line++
+		if len(strings.TrimSpace(sc.Text())) == 0 {
+			continue
+		}
+		var r trendRecord
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if r.Op == "" || r.Time.IsZero() {
+			return nil, fmt.Errorf("line %d: time or op missing", line)
+		}
+		res = append(res, r)
+	}
+	return res, sc.Err()
+}
+
+// mainTrend is the entry point for trend command.
+func mainTrend(ctx *cli.Context) error {
+	checkTrend(ctx)
+	fn := ctx.String(trendDBFlag.Name)
+	if fn == "" {
+		fatalIf(errDummy(), "No trend database given. Use --trend.db")
+	}
+	var metric *trendMetric
+	for i := range trendMetrics {
+		if trendMetrics[i].name == ctx.String("trend.metric") {
+			metric = &trendMetrics[i]
+		}
+	}
+	if metric == nil {
+		fatalIf(errDummy(), "Unknown trend metric %q. Can be %s", ctx.String("trend.metric"), strings.Join(trendMetricNames(), ", "))
+	}
+	records, err := readTrends(fn, ctx.String(benchDataKeyFlag.Name))
+	fatalIf(probe.NewError(err), "Unable to read trend database")
+	setFatalExitCode(exitError)
+
+	labels, _ := parseTrendLabels(ctx.String(trendLabelFlag.Name))
+	wantOp := strings.ToUpper(ctx.String("trend.op"))
+	wantBench := ctx.String("trend.benchmark")
+	var since time.Time
+	if d := ctx.Duration("trend.since"); d > 0 {
+		since = time.Now().Add(-d)
+	}
+	filtered := records[:0]
+	for _, r := range records {
+		if wantOp != "" && r.Op != wantOp {
+			continue
+		}
+		if wantBench != "" && r.Benchmark != wantBench {
+			continue
+		}
+		if r.Time.Before(since) {
+			continue
+		}
+		match := true
+		for k, v := range labels {
+			if r.Labels[k] != v {
+				match = false
+				break
+			}
+		}
+		if match {
+			filtered = append(filtered, r)
+		}
+	}
+	records = filtered
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+
+	if globalJSON {
+		b, err := json.MarshalIndent(records, "", "  ")
+		fatalIf(probe.NewError(err), "Unable to marshal data.")
+		os.Stdout.Write(b)
+		return nil
+	}
+	if len(records) == 0 {
+		console.Println("No runs found.")
+		return nil
+	}
+	printTrend(records, *metric)
+	return nil
+}
+
+// printTrend prints a bar chart of the metric of each operation type.
+func printTrend(records []trendRecord, metric trendMetric) {
+	var ops []string
+	byOp := make(map[string][]trendRecord)
+	for _, r := range records {
+		if _, ok := byOp[r.Op]; !ok {
+			ops = append(ops, r.Op)
+		}
+		byOp[r.Op] = append(byOp[r.Op], r)
+	}
+	const barWidth = 40
+	for _, op := range ops {
+		recs := byOp[op]
+		var maxV float64
+		for _, r := range recs {
+			if v := metric.value(r); v > maxV {
+				maxV = v
+			}
+		}
+		console.SetColor("Print", color.New(color.FgHiWhite))
+		console.Printf("\nOperation: %s, %s\n", op, metric.name)
+		console.SetColor("Print", color.New(color.FgWhite))
+		var first float64
+		for i, r := range recs {
+			v := metric.value(r)
+			bar := 0
+			if maxV > 0 {
+				bar = int(v / maxV * barWidth)
+			}
+			change := ""
+			if i == 0 {
+				first = v
+			} else if first > 0 {
+				change = fmt.Sprintf(" (%+.1f%%)", (v-first)/first*100)
+			}
+			console.Printf(" * %s %-*s %s%s%s\n", r.Time.Local().Format("2006-01-02 15:04"), barWidth, strings.Repeat("#", bar), metric.fmt(v), change, trendDescription(r))
+		}
+	}
+}
+
+// trendDescription returns the benchmark and labels of a run.
+func trendDescription(r trendRecord) string {
+	var desc []string
+	if r.Benchmark != "" {
+		desc = append(desc, r.Benchmark)
+	}
+	keys := make([]string, 0, len(r.Labels))
+	for k := range r.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		desc = append(desc, k+"="+r.Labels[k])
+	}
+	if len(desc) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(desc, ", ") + "]"
+}
+
+// trendBenchmark returns the benchmark of analyzed benchmark data from its provenance.
+func trendBenchmark(comments []string) string {
+	if len(comments) == 0 {
+		return ""
+	}
+	fields := strings.Fields(comments[0])
+	if len(fields) < 2 || strings.HasSuffix(fields[0], ":") {
+		return ""
+	}
+	return fields[1]
+}
@@ -0,0 +1,93 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"github.com/minio/cli"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+var ttfbFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "objects",
+		Value: 10000,
+		Usage: "Number of objects to upload.",
+	},
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "1KiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.BoolFlag{
+		Name:  "ttfb.nohead",
+		Usage: "Only send first byte GETs, without a HEAD request before each.",
+	},
+}
+
+var ttfbCmd = cli.Command{
+	Name:   "ttfb",
+	Usage:  "benchmark metadata and first byte latency",
+	Action: mainTTFB,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, ttfbFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+  -> see https://github.com/minio/warp#ttfb
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainTTFB is the entry point for ttfb command.
+func mainTTFB(ctx *cli.Context) error {
+	checkTTFBSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	sse := newSSE(ctx)
+	b := bench.FirstByte{
+		Common: bench.Common{
+			Client:      newClient(ctx),
+			Concurrency: getConcurrency(ctx),
+			Source:      src,
+			Bucket:      ctx.String("bucket"),
+			Location:    "",
+			PutOpts:     putOpts(ctx),
+		},
+		CreateObjects: ctx.Int("objects"),
+		NoHead:        ctx.Bool("ttfb.nohead"),
+		StatOpts:      minio.StatObjectOptions{ServerSideEncryption: sse},
+		GetOpts:       minio.GetObjectOptions{ServerSideEncryption: sse},
+	}
+	return runBench(ctx, &b)
+}
+
+func checkTTFBSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if ctx.Int("objects") < 1 {
+		console.Fatal("At least one object must be used")
+	}
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
@@ -0,0 +1,97 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+)
+
+var ttlFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "64KiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.DurationFlag{
+		Name:  "ttl",
+		Value: 30 * time.Second,
+		Usage: "Mean time for each object to live before it is deleted.",
+	},
+	cli.StringFlag{
+		Name:  "ttl.dist",
+		Value: bench.TTLExp,
+		Usage: "Distribution of object TTLs. Can be 'fixed', 'uniform' (0 to 2x ttl) or 'exp' (exponential)",
+	},
+}
+
+var ttlCmd = cli.Command{
+	Name:   "ttl",
+	Usage:  "benchmark uploading objects that are deleted after a time to live",
+	Action: mainTTL,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, ttlFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+  -> see https://github.com/minio/warp#ttl
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainTTL is the entry point for ttl command.
+func mainTTL(ctx *cli.Context) error {
+	checkTTLSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	b := bench.TTL{
+		Common: bench.Common{
+			Client:      newClient(ctx),
+			Concurrency: getConcurrency(ctx),
+			Source:      src,
+			Bucket:      ctx.String("bucket"),
+			Location:    "",
+			PutOpts:     putOpts(ctx),
+		},
+		TTL:  ctx.Duration("ttl"),
+		Dist: ctx.String("ttl.dist"),
+	}
+	return runBench(ctx, &b)
+}
+
+func checkTTLSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if ctx.Duration("ttl") <= 0 {
+		console.Fatal("--ttl must be positive")
+	}
+	switch ctx.String("ttl.dist") {
+	case bench.TTLFixed, bench.TTLUniform, bench.TTLExp:
+	default:
+		console.Fatal("unknown --ttl.dist: ", ctx.String("ttl.dist"))
+	}
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
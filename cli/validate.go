@@ -0,0 +1,194 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+	"github.com/minio/warp/pkg/generator"
+)
+
+// File types that can be validated.
+const (
+	validateBenchData = "benchdata"
+	validateManifest  = "manifest"
+	validateTrend     = "trend"
+	validateAvro      = "avro"
+)
+
+var validateFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "validate.type",
+		Usage: "Type of the files. Can be benchdata, manifest, trend or avro. By default the type is detected from the content",
+	},
+	cli.IntFlag{
+		Name:  "validate.errors",
+		Value: 20,
+		Usage: "Maximum number of errors shown for each file.",
+	},
+	benchDataKeyFlag,
+}
+
+var validateCmd = cli.Command{
+	Name:   "validate",
+	Usage:  "check benchmark data and other input files for errors",
+	Action: mainValidate,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, validateFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] file [file...]
+  -> see https://github.com/minio/warp#validating-files
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainValidate is the entry point for validate command.
+func mainValidate(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) == 0 {
+		console.Fatal("No files supplied")
+	}
+	switch typ := ctx.String("validate.type"); typ {
+	case "", validateBenchData, validateManifest, validateTrend, validateAvro:
+	default:
+		fatalIf(errDummy(), "Unknown validate.type %q. Can be %s, %s, %s or %s", typ, validateBenchData, validateManifest, validateTrend, validateAvro)
+	}
+	maxErrors := ctx.Int("validate.errors")
+	if maxErrors < 1 {
+		maxErrors = 1
+	}
+	invalid := 0
+	for _, fn := range args {
+		typ, errs, info := validateFile(ctx, fn, maxErrors)
+		desc := fn
+		if typ != "" {
+			desc += " (" + typ + ")"
+		}
+		if len(errs) == 0 {
+			console.Println(desc + ": OK. " + info)
+			continue
+		}
+		invalid++
+		console.Errorln(desc + ": invalid. " + info)
+		for _, err := range errs {
+			console.Println(" * " + err)
+		}
+	}
+	if invalid > 0 {
+		fatalIf(errDummy(), "%d of %d files are invalid", invalid, len(args))
+	}
+	return nil
+}
+
+// validateFile validates a single file.
+// The detected type, the errors found and a summary of the content are returned.
+func validateFile(ctx *cli.Context, fn string, maxErrors int) (typ string, errs []string, info string) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return "", []string{err.Error()}, ""
+	}
+	defer f.Close()
+	dec, err := newBenchDataReader(f, ctx.String(benchDataKeyFlag.Name))
+	if err != nil {
+		return "", []string{err.Error()}, ""
+	}
+	defer dec.Close()
+	br := bufio.NewReader(dec)
+	typ = ctx.String("validate.type")
+	if typ == "" {
+		typ = detectFileType(fn, br)
+	}
+
+	switch typ {
+	case validateBenchData:
+		v := bench.ValidateCSV(br, maxErrors)
+		for _, e := range v.Errors {
+			errs = append(errs, e.Error())
+		}
+		if more := v.TotalErrors - len(v.Errors); more > 0 {
+			errs = append(errs, fmt.Sprintf("... and %d more errors", more))
+		}
+		info = fmt.Sprintf("%d operations.", v.Operations)
+		if len(v.Comments) == 0 && v.TotalErrors == 0 {
+			info += " No provenance found; written by an older version?"
+		} else if len(v.Comments) > 0 {
+			info += " " + v.Comments[0]
+		}
+	case validateManifest:
+		entries, err := bench.ReadManifest(br)
+		if err != nil {
+			return typ, []string{err.Error()}, ""
+		}
+		info = fmt.Sprintf("%d entries, %d objects.", len(entries), len(bench.LatestManifestEntries(entries)))
+		for i, e := range entries {
+			if e.Key == "" || e.Size < 0 || e.SHA256 == "" {
+				errs = append(errs, fmt.Sprintf("entry %d: key, size or checksum missing", i+1))
+			}
+			if len(errs) >= maxErrors {
+				break
+			}
+		}
+	case validateTrend:
+		records, err := decodeTrends(br)
+		if err != nil {
+			return typ, []string{err.Error()}, ""
+		}
+		info = fmt.Sprintf("%d records.", len(records))
+	case validateAvro:
+		schema, err := io.ReadAll(br)
+		if err == nil {
+			err = generator.ValidateAvroSchema(string(schema))
+		}
+		if err != nil {
+			return typ, []string{err.Error()}, ""
+		}
+		info = "Schema can be used with --obj.avro.schema."
+	}
+	return typ, errs, info
+}
+
+// detectFileType returns the type of file from its name and content.
+func detectFileType(fn string, br *bufio.Reader) string {
+	if strings.HasSuffix(fn, ".avsc") {
+		return validateAvro
+	}
+	start, _ := br.Peek(512)
+	start = bytes.TrimLeft(start, " \t\r\n")
+	switch {
+	case bytes.HasPrefix(start, []byte("[")):
+		return validateManifest
+	case bytes.HasPrefix(start, []byte("{")):
+		return validateTrend
+	case bytes.HasPrefix(start, []byte("key,")):
+		return validateManifest
+	}
+	return validateBenchData
+}
@@ -0,0 +1,251 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/bench"
+	"github.com/minio/warp/pkg/generator"
+)
+
+var verifyFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "manifest",
+		Usage: "Manifest written by a benchmark with --manifest or --registry.",
+	},
+	cli.BoolFlag{
+		Name:  "named",
+		Usage: "Verify all objects in the bucket and --prefix against the content of '--obj.generator named' instead of a manifest.",
+	},
+	cli.Int64Flag{
+		Name:  "obj.seed",
+		Usage: "Seed of the object content with --named.",
+	},
+	benchDataKeyFlag,
+}
+
+var verifyCmd = cli.Command{
+	Name:   "verify",
+	Usage:  "verify objects against a manifest or generated content",
+	Action: mainVerify,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, verifyFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} --manifest=file [FLAGS]
+  {{.HelpName}} --named [--obj.seed=n] [FLAGS]
+  -> see https://github.com/minio/warp#verify
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+const (
+	verifyMissing  = "missing"
+	verifySize     = "size-mismatch"
+	verifyChecksum = "checksum-mismatch"
+	verifyETag     = "etag-mismatch"
+	verifyError    = "error"
+)
+
+// verifyResult is the result of verifying a manifest entry.
+// Kind is empty if the object matched.
+type verifyResult struct {
+	Key  string
+	Kind string
+	Info string
+}
+
+// mainVerify is the entry point for verify command.
+func mainVerify(ctx *cli.Context) error {
+	named := ctx.Bool("named")
+	var entries []bench.ManifestEntry
+	switch {
+	case named && ctx.String("manifest") != "":
+		console.Fatal("Specify either --manifest or --named, not both")
+	case named:
+		entries = listNamedEntries(ctx)
+		if len(entries) == 0 {
+			console.Fatal("No objects found in bucket")
+		}
+	default:
+		entries = readManifestEntries(ctx)
+	}
+	setFatalExitCode(exitError)
+	var seed *int64
+	against := "manifest"
+	if named {
+		s := ctx.Int64("obj.seed")
+		seed = &s
+		against = "generated content"
+	}
+
+	concurrency := getConcurrency(ctx)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	client := newClient(ctx)
+	opts := minio.GetObjectOptions{ServerSideEncryption: newSSE(ctx)}
+	bucket := ctx.String("bucket")
+	jobs := make(chan bench.ManifestEntry)
+	results := make(chan verifyResult)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for e := range jobs {
+				results <- verifyObject(client, bucket, e, opts, seed)
+			}
+		}()
+	}
+	go func() {
+		for _, e := range entries {
+			jobs <- e
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	var failed []verifyResult
+	counts := make(map[string]int, 5)
+	done := 0
+	for res := range results {
+		done++
+		if !globalQuiet && !globalJSON && done%100 == 0 {
+			console.Eraseline()
+			console.Infof("\rVerified %d of %d objects...", done, len(entries))
+		}
+		if res.Kind == "" {
+			continue
+		}
+		counts[res.Kind]++
+		failed = append(failed, res)
+	}
+	console.Eraseline()
+	sort.Slice(failed, func(i, j int) bool {
+		return failed[i].Key < failed[j].Key
+	})
+	for _, res := range failed {
+		console.Errorf("%s: %s (%s)\n", res.Key, res.Kind, res.Info)
+	}
+	console.Printf("Verified objects: %d, mismatched objects: %d.\n", len(entries), len(failed))
+	for _, kind := range []string{verifyMissing, verifySize, verifyChecksum, verifyETag, verifyError} {
+		console.Printf(" * %s: %d\n", kind, counts[kind])
+	}
+	if len(failed) > 0 {
+		setFatalExitCode(exitVerification)
+		console.Fatal("Objects do not match ", against)
+	}
+	console.Println("All objects match " + against + ".")
+	return nil
+}
+
+// readManifestEntries returns the latest entries of the manifest given.
+func readManifestEntries(ctx *cli.Context) []bench.ManifestEntry {
+	fn := ctx.String("manifest")
+	if fn == "" {
+		console.Fatal("A manifest must be supplied with --manifest")
+	}
+	f, err := os.Open(fn)
+	fatalIf(probe.NewError(err), "Unable to open manifest")
+	r, err := newDecryptReader(f, ctx.String(benchDataKeyFlag.Name))
+	fatalIf(probe.NewError(err), "Unable to read manifest")
+	entries, err := bench.ReadManifest(r)
+	f.Close()
+	fatalIf(probe.NewError(err), "Unable to read manifest")
+	entries = bench.LatestManifestEntries(entries)
+	if len(entries) == 0 {
+		console.Fatal("No objects found in manifest")
+	}
+	return entries
+}
+
+// listNamedEntries lists the objects in the bucket and prefix.
+// Objects are expected to have the content of the named generator.
+func listNamedEntries(ctx *cli.Context) []bench.ManifestEntry {
+	cl, done := newClient(ctx)()
+	defer done()
+	var entries []bench.ManifestEntry
+	for obj := range cl.ListObjects(context.Background(), ctx.String("bucket"), minio.ListObjectsOptions{Prefix: ctx.String("prefix"), Recursive: true}) {
+		fatalIf(probe.NewError(obj.Err), "Unable to list objects")
+		entries = append(entries, bench.ManifestEntry{Key: obj.Key, Size: obj.Size})
+	}
+	return entries
+}
+
+// verifyObject downloads the object of the entry and compares it to the manifest.
+// If seed is set the checksum is calculated from the named generator content.
+func verifyObject(client func() (*minio.Client, func()), bucket string, e bench.ManifestEntry, opts minio.GetObjectOptions, seed *int64) verifyResult {
+	res := verifyResult{Key: e.Key}
+	if seed != nil {
+		h := sha256.New()
+		io.Copy(h, generator.NamedContent(*seed, e.Key, e.Size))
+		e.SHA256 = hex.EncodeToString(h.Sum(nil))
+	}
+	cl, done := client()
+	defer done()
+	opts.VersionID = e.VersionID
+	obj, err := cl.GetObject(context.Background(), bucket, e.Key, opts)
+	if err != nil {
+		res.Kind, res.Info = verifyError, err.Error()
+		return res
+	}
+	defer obj.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, obj)
+	if err != nil {
+		switch minio.ToErrorResponse(err).Code {
+		case "NoSuchKey", "NoSuchVersion":
+			res.Kind = verifyMissing
+		default:
+			res.Kind = verifyError
+		}
+		res.Info = err.Error()
+		return res
+	}
+	if n != e.Size {
+		res.Kind, res.Info = verifySize, fmt.Sprintf("expected %d bytes, got %d", e.Size, n)
+		return res
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != e.SHA256 {
+		res.Kind, res.Info = verifyChecksum, fmt.Sprintf("expected %s, got %s", e.SHA256, sum)
+		return res
+	}
+	if st, err := obj.Stat(); err == nil && e.ETag != "" && strings.Trim(st.ETag, `"`) != strings.Trim(e.ETag, `"`) {
+		res.Kind, res.Info = verifyETag, fmt.Sprintf("expected %s, got %s", e.ETag, st.ETag)
+	}
+	return res
+}
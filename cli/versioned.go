@@ -91,12 +91,16 @@ func mainVersioned(ctx *cli.Context) error {
 			http.MethodDelete: ctx.Float64("delete-distrib"),
 		},
 	}
+	if ctx.Bool("debug.deterministic") {
+		dist.Deterministic = true
+		dist.Seed = ctx.Int64("debug.seed")
+	}
 	err := dist.Generate(ctx.Int("objects") * 2)
 	fatalIf(probe.NewError(err), "Invalid distribution")
 	b := bench.Versioned{
 		Common: bench.Common{
 			Client:      newClient(ctx),
-			Concurrency: ctx.Int("concurrent"),
+			Concurrency: getConcurrency(ctx),
 			Source:      src,
 			Bucket:      ctx.String("bucket"),
 			Location:    "",
@@ -71,7 +71,7 @@ func mainZip(ctx *cli.Context) error {
 	b := bench.S3Zip{
 		Common: bench.Common{
 			Client:      newClient(ctx),
-			Concurrency: ctx.Int("concurrent"),
+			Concurrency: getConcurrency(ctx),
 			Source:      src,
 			Bucket:      ctx.String("bucket"),
 			Location:    "",
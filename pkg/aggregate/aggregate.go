@@ -33,6 +33,8 @@ type Aggregated struct {
 	// MixedServerStats and MixedThroughputByHost is populated only when data is mixed.
 	MixedServerStats      *Throughput           `json:"mixed_server_stats,omitempty"`
 	MixedThroughputByHost map[string]Throughput `json:"mixed_throughput_by_host,omitempty"`
+	// SLO is populated when latency objectives are specified.
+	SLO []SLO `json:"slo,omitempty"`
 }
 
 // Operation returns statistics for a single operation type.
@@ -78,10 +80,13 @@ type Options struct {
 	Prefiltered bool
 	DurFunc     SegmentDurFn
 	SkipDur     time.Duration
+	// Percentiles are included in request statistics if set.
+	Percentiles bench.Percentiles
 }
 
 // Aggregate returns statistics when only a single operation was running concurrently.
 func Aggregate(o bench.Operations, opts Options) Aggregated {
+	o, _ = o.SplitAnnotations()
 	o.SortByStartTime()
 	types := o.OpTypes()
 	a := Aggregated{
@@ -225,9 +230,9 @@ func Aggregate(o bench.Operations, opts Options) Aggregated {
 			a.HostNames = ops.Endpoints()
 
 			if !ops.MultipleSizes() {
-				a.SingleSizedRequests = RequestAnalysisSingleSized(ops, !opts.Prefiltered)
+				a.SingleSizedRequests = RequestAnalysisSingleSized(ops, !opts.Prefiltered, opts.Percentiles)
 			} else {
-				a.MultiSizedRequests = RequestAnalysisMultiSized(ops, !opts.Prefiltered)
+				a.MultiSizedRequests = RequestAnalysisMultiSized(ops, !opts.Prefiltered, opts.Percentiles)
 			}
 
 			eps := ops.Endpoints()
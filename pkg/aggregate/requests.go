@@ -18,6 +18,8 @@
 package aggregate
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -46,6 +48,8 @@ type SingleSizedRequests struct {
 	SlowestMillis int `json:"slowest_millis"`
 	// DurPct is duration percentiles.
 	DurPct [101]int `json:"dur_percentiles_millis"`
+	// Percentiles contains the request durations at the percentiles selected for reports.
+	Percentiles []bench.PercentileDuration `json:"percentiles,omitempty"`
 	// Time to first byte if applicable.
 	FirstByte *TTFB `json:"first_byte,omitempty"`
 	// FirstAccess is filled if the same object is accessed multiple times.
@@ -58,7 +62,7 @@ type SingleSizedRequests struct {
 	ByHost map[string]SingleSizedRequests `json:"by_host,omitempty"`
 }
 
-func (a *SingleSizedRequests) fill(ops bench.Operations) {
+func (a *SingleSizedRequests) fill(ops bench.Operations, pcts bench.Percentiles) {
 	start, end := ops.TimeRange()
 	ops.SortByDuration()
 	a.Requests = len(ops)
@@ -73,18 +77,35 @@ func (a *SingleSizedRequests) fill(ops bench.Operations) {
 	for i := range a.DurPct[:] {
 		a.DurPct[i] = durToMillis(ops.Median(float64(i) / 100).Duration())
 	}
+	a.Percentiles = ops.DurationPercentiles(pcts)
 }
 
-func (a *SingleSizedRequests) fillFirstLast(ops bench.Operations) {
+// PercentilesString returns the request durations at the percentiles selected for reports,
+// or at 50, 90 and 99 percent if none were selected.
+func (a SingleSizedRequests) PercentilesString() string {
+	if len(a.Percentiles) == 0 {
+		return fmt.Sprintf("50%%: %v, 90%%: %v, 99%%: %v",
+			time.Duration(a.DurMedianMillis)*time.Millisecond,
+			time.Duration(a.Dur90Millis)*time.Millisecond,
+			time.Duration(a.Dur99Millis)*time.Millisecond)
+	}
+	res := make([]string, len(a.Percentiles))
+	for i, p := range a.Percentiles {
+		res[i] = p.String()
+	}
+	return strings.Join(res, ", ")
+}
+
+func (a *SingleSizedRequests) fillFirstLast(ops bench.Operations, pcts bench.Percentiles) {
 	if !ops.IsMultiTouch() {
 		return
 	}
 	var first, last SingleSizedRequests
 	o := ops.FilterFirst()
-	first.fill(o)
+	first.fill(o, pcts)
 	a.FirstAccess = &first
 	o = ops.FilterLast()
-	last.fill(o)
+	last.fill(o, pcts)
 	a.LastAccess = &last
 }
 
@@ -111,6 +132,9 @@ type RequestSizeRange struct {
 
 	// BpsPct is BPS percentiles.
 	BpsPct [101]float64 `json:"bps_percentiles"`
+	// Percentiles contains the request throughput at the percentiles selected for reports.
+	// Higher percentiles are slower.
+	Percentiles []bench.PercentileThroughput `json:"percentiles,omitempty"`
 
 	// FirstAccess is filled if the same object is accessed multiple times.
 	// This records the first touch of the object.
@@ -120,7 +144,7 @@ type RequestSizeRange struct {
 	FirstByte *TTFB `json:"first_byte,omitempty"`
 }
 
-func (r *RequestSizeRange) fill(s bench.SizeSegment) {
+func (r *RequestSizeRange) fill(s bench.SizeSegment, pcts bench.Percentiles) {
 	r.Requests = len(s.Ops)
 	r.MinSize = int(s.Smallest)
 	r.MaxSize = int(s.Biggest)
@@ -137,15 +161,30 @@ func (r *RequestSizeRange) fill(s bench.SizeSegment) {
 	for i := range r.BpsPct[:] {
 		r.BpsPct[i] = s.Ops.Median(float64(i) / 100).BytesPerSec().Float()
 	}
+	r.Percentiles = s.Ops.ThroughputPercentiles(pcts)
+}
+
+// PercentilesString returns the request throughput at the percentiles selected for reports,
+// or at 50, 90 and 99 percent if none were selected.
+func (r RequestSizeRange) PercentilesString() string {
+	if len(r.Percentiles) == 0 {
+		return fmt.Sprintf("50%%: %v, 90%%: %v, 99%%: %v",
+			bench.Throughput(r.BpsMedian), bench.Throughput(r.Bps90), bench.Throughput(r.Bps99))
+	}
+	res := make([]string, len(r.Percentiles))
+	for i, p := range r.Percentiles {
+		res[i] = p.String()
+	}
+	return strings.Join(res, ", ")
 }
 
-func (r *RequestSizeRange) fillFirst(s bench.SizeSegment) {
+func (r *RequestSizeRange) fillFirst(s bench.SizeSegment, pcts bench.Percentiles) {
 	if !s.Ops.IsMultiTouch() {
 		return
 	}
 	s.Ops = s.Ops.FilterFirst()
 	a := RequestSizeRange{}
-	a.fill(s)
+	a.fill(s, pcts)
 	a.FirstByte = TtfbFromBench(s.Ops.TTFB(s.Ops.TimeRange()))
 
 	r.FirstAccess = &a
@@ -170,7 +209,7 @@ type MultiSizedRequests struct {
 	ByHost map[string]RequestSizeRange `json:"by_host,omitempty"`
 }
 
-func (a *MultiSizedRequests) fill(ops bench.Operations) {
+func (a *MultiSizedRequests) fill(ops bench.Operations, pcts bench.Percentiles) {
 	start, end := ops.TimeRange()
 	a.Requests = len(ops)
 	if len(ops) == 0 {
@@ -187,8 +226,8 @@ func (a *MultiSizedRequests) fill(ops bench.Operations) {
 			defer wg.Done()
 			s := sizes[i]
 			var r RequestSizeRange
-			r.fill(s)
-			r.fillFirst(s)
+			r.fill(s, pcts)
+			r.fillFirst(s, pcts)
 			r.FirstByte = TtfbFromBench(s.Ops.TTFB(start, end))
 			// Store
 			a.BySize[i] = r
@@ -198,7 +237,8 @@ func (a *MultiSizedRequests) fill(ops bench.Operations) {
 }
 
 // RequestAnalysisSingleSized performs analysis where all objects have equal size.
-func RequestAnalysisSingleSized(o bench.Operations, allThreads bool) *SingleSizedRequests {
+// Durations at the percentiles given are included.
+func RequestAnalysisSingleSized(o bench.Operations, allThreads bool, pcts bench.Percentiles) *SingleSizedRequests {
 	var res SingleSizedRequests
 
 	// Single type, require one operation per thread.
@@ -209,16 +249,16 @@ func RequestAnalysisSingleSized(o bench.Operations, allThreads bool) *SingleSize
 		res.Skipped = true
 		return &res
 	}
-	res.fill(active)
-	res.fillFirstLast(o)
+	res.fill(active, pcts)
+	res.fillFirstLast(o, pcts)
 	res.HostNames = o.Endpoints()
-	res.ByHost = RequestAnalysisHostsSingleSized(o)
+	res.ByHost = RequestAnalysisHostsSingleSized(o, pcts)
 
 	return &res
 }
 
 // RequestAnalysisHostsSingleSized performs host analysis where all objects have equal size.
-func RequestAnalysisHostsSingleSized(o bench.Operations) map[string]SingleSizedRequests {
+func RequestAnalysisHostsSingleSized(o bench.Operations, pcts bench.Percentiles) map[string]SingleSizedRequests {
 	eps := o.Endpoints()
 	res := make(map[string]SingleSizedRequests, len(eps))
 	var wg sync.WaitGroup
@@ -232,7 +272,7 @@ func RequestAnalysisHostsSingleSized(o bench.Operations) map[string]SingleSizedR
 				return
 			}
 			a := SingleSizedRequests{}
-			a.fill(filtered)
+			a.fill(filtered, pcts)
 			mu.Lock()
 			res[ep] = a
 			mu.Unlock()
@@ -243,7 +283,8 @@ func RequestAnalysisHostsSingleSized(o bench.Operations) map[string]SingleSizedR
 }
 
 // RequestAnalysisMultiSized performs analysis where objects have different sizes.
-func RequestAnalysisMultiSized(o bench.Operations, allThreads bool) *MultiSizedRequests {
+// Throughput at the percentiles given is included.
+func RequestAnalysisMultiSized(o bench.Operations, allThreads bool, pcts bench.Percentiles) *MultiSizedRequests {
 	var res MultiSizedRequests
 	// Single type, require one operation per thread.
 	start, end := o.ActiveTimeRange(allThreads)
@@ -254,14 +295,14 @@ func RequestAnalysisMultiSized(o bench.Operations, allThreads bool) *MultiSizedR
 		res.Skipped = true
 		return &res
 	}
-	res.fill(active)
-	res.ByHost = RequestAnalysisHostsMultiSized(active)
+	res.fill(active, pcts)
+	res.ByHost = RequestAnalysisHostsMultiSized(active, pcts)
 	res.HostNames = active.Endpoints()
 	return &res
 }
 
 // RequestAnalysisHostsMultiSized performs host analysis where objects have different sizes.
-func RequestAnalysisHostsMultiSized(o bench.Operations) map[string]RequestSizeRange {
+func RequestAnalysisHostsMultiSized(o bench.Operations, pcts bench.Percentiles) map[string]RequestSizeRange {
 	eps := o.Endpoints()
 	res := make(map[string]RequestSizeRange, len(eps))
 	start, end := o.TimeRange()
@@ -276,7 +317,7 @@ func RequestAnalysisHostsMultiSized(o bench.Operations) map[string]RequestSizeRa
 				return
 			}
 			a := RequestSizeRange{}
-			a.fill(filtered.SingleSizeSegment())
+			a.fill(filtered.SingleSizeSegment(), pcts)
 			a.FirstByte = TtfbFromBench(filtered.TTFB(start, end))
 			mu.Lock()
 			res[ep] = a
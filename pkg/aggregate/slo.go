@@ -0,0 +1,148 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package aggregate
+
+import (
+	"time"
+
+	"github.com/minio/warp/pkg/bench"
+)
+
+// SLO contains latency objective compliance for a single operation type.
+// Operations that fail or take longer than the threshold are counted as exceeding.
+type SLO struct {
+	// Operation type
+	Type string `json:"type"`
+	// Latency threshold.
+	ThresholdMillis float64 `json:"threshold_millis"`
+	// Target fraction of operations within the threshold.
+	Target float64 `json:"target"`
+	// N is the number of operations.
+	N int `json:"n"`
+	// Exceeded is the number of operations exceeding the threshold.
+	Exceeded int `json:"exceeded"`
+	// BurnRate is the fraction exceeding divided by the error budget.
+	// A burn rate of 1 uses exactly the error budget.
+	BurnRate float64 `json:"burn_rate"`
+	// Intervals contains the burn rate over time.
+	Intervals []SLOInterval `json:"intervals"`
+	// IntervalMillis is the duration of each interval.
+	IntervalMillis int `json:"interval_millis"`
+	// Misses is a histogram of how far operations exceeded the threshold.
+	Misses []SLOMisses `json:"misses,omitempty"`
+	// Errors is the number of failed operations.
+	Errors int `json:"errors"`
+}
+
+// SLOMisses contains the number of successful operations exceeding the threshold
+// with a latency up to MaxFactor times the threshold.
+// The last bucket has a MaxFactor of 0 and contains all slower operations.
+type SLOMisses struct {
+	MaxFactor float64 `json:"max_factor"`
+	N         int     `json:"n"`
+}
+
+// sloMissFactors are the upper bounds of the deadline miss histogram buckets.
+var sloMissFactors = []float64{1.5, 2, 4, 8, 16}
+
+// SLOInterval contains latency objective compliance of operations starting in an interval.
+type SLOInterval struct {
+	Start    time.Time `json:"start"`
+	N        int       `json:"n"`
+	Exceeded int       `json:"exceeded"`
+	BurnRate float64   `json:"burn_rate"`
+}
+
+// burnRate returns the burn rate of exceeded out of n with the target.
+// The target must be less than 1.
+func burnRate(exceeded, n int, target float64) float64 {
+	if n == 0 {
+		return 0
+	}
+	return float64(exceeded) / float64(n) / (1 - target)
+}
+
+// SLOBurnRate returns the latency objective compliance of o.
+// All operations should be of the same type and the target must be less than 1.
+func SLOBurnRate(o bench.Operations, threshold time.Duration, target float64, interval time.Duration) SLO {
+	res := SLO{
+		Type:            o.FirstOpType(),
+		ThresholdMillis: float64(threshold) / float64(time.Millisecond),
+		Target:          target,
+		N:               len(o),
+		IntervalMillis:  durToMillis(interval),
+	}
+	if len(o) == 0 {
+		return res
+	}
+	res.Misses = make([]SLOMisses, len(sloMissFactors)+1)
+	for i, f := range sloMissFactors {
+		res.Misses[i].MaxFactor = f
+	}
+	start, _ := o.TimeRange()
+	if interval > 0 {
+		var last time.Time
+		for _, op := range o {
+			if op.Start.After(last) {
+				last = op.Start
+			}
+		}
+		res.Intervals = make([]SLOInterval, int(last.Sub(start)/interval)+1)
+		for i := range res.Intervals {
+			res.Intervals[i].Start = start.Add(time.Duration(i) * interval)
+		}
+	}
+	for _, op := range o {
+		exceeded := op.Err != "" || op.Duration() > threshold
+		switch {
+		case op.Err != "":
+			res.Errors++
+		case exceeded:
+			res.Misses[sloMissBucket(op.Duration(), threshold)].N++
+		}
+		if exceeded {
+			res.Exceeded++
+		}
+		if interval <= 0 {
+			continue
+		}
+		iv := &res.Intervals[int(op.Start.Sub(start)/interval)]
+		iv.N++
+		if exceeded {
+			iv.Exceeded++
+		}
+	}
+	res.BurnRate = burnRate(res.Exceeded, res.N, target)
+	for i := range res.Intervals {
+		iv := &res.Intervals[i]
+		iv.BurnRate = burnRate(iv.Exceeded, iv.N, target)
+	}
+	return res
+}
+
+// sloMissBucket returns the index of the deadline miss histogram bucket of an operation
+// taking d with the threshold.
+func sloMissBucket(d, threshold time.Duration) int {
+	factor := float64(d) / float64(threshold)
+	for i, f := range sloMissFactors {
+		if factor <= f {
+			return i
+		}
+	}
+	return len(sloMissFactors)
+}
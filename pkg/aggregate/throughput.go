@@ -156,7 +156,7 @@ func (s SegmentSmall) StringLong(d time.Duration, details bool) string {
 	}
 	detail := ""
 	if details {
-		detail = fmt.Sprintf(" (%v, starting %v)", d, s.Start.Format("15:04:05 MST"))
+		detail = fmt.Sprintf(" (%v, starting %v)", d, s.Start.Format(bench.SegmentTimeLayout(d)))
 	}
 	return fmt.Sprintf("%s%.02f obj/s%s",
 		speed, s.OPS, detail)
@@ -159,6 +159,9 @@ func (o Operations) Segment(so SegmentOptions) Segments {
 	if e := o.Endpoints(); len(e) == 1 {
 		host = e[0]
 	}
+	// Index of the first operation that may be in the segment.
+	// Segments are in time order, so the search continues from the previous segment.
+	first := 0
 	for segStart.Before(end.Add(-so.PerSegDuration)) {
 		s := Segment{
 			OpType:     o.FirstOpType(),
@@ -178,9 +181,8 @@ func (o Operations) Segment(so SegmentOptions) Segments {
 			s.ObjsPerOp = 0
 		}
 		// Search for the first entry
-		first := 0
-		for i, op := range o {
-			if op.End.After(s.Start) {
+		for i := first; i < len(o); i++ {
+			if o[i].End.After(s.Start) {
 				break
 			}
 			first = i
@@ -279,6 +281,15 @@ func (s Segment) CSV(w *csv.Writer, idx int) error {
 	})
 }
 
+// SegmentTimeLayout returns the layout of segment start times.
+// Sub-second segments include milliseconds.
+func SegmentTimeLayout(d time.Duration) string {
+	if d < time.Second {
+		return "15:04:05.000 MST"
+	}
+	return "15:04:05 MST"
+}
+
 // String returns a string representation of the segment
 func (s Segment) Duration() time.Duration {
 	return s.EndsBefore.Sub(s.Start)
@@ -292,7 +303,7 @@ func (s Segment) String() string {
 		speed = fmt.Sprintf("%.02f MiB/s, ", mib)
 	}
 	return fmt.Sprintf("%s%.02f obj/s (%v, starting %v)",
-		speed, objs, s.EndsBefore.Sub(s.Start).Round(time.Millisecond), s.Start.Format("15:04:05 MST"))
+		speed, objs, s.EndsBefore.Sub(s.Start).Round(time.Millisecond), s.Start.Format(SegmentTimeLayout(s.Duration())))
 }
 
 // ShortString returns a string representation of the segment without ops ended/s.
@@ -20,6 +20,7 @@ package bench
 import (
 	"bytes"
 	"io/ioutil"
+	"strings"
 	"testing"
 	"time"
 
@@ -70,3 +71,83 @@ func TestOperations_Segment(t *testing.T) {
 		t.Log(buf.String())
 	}
 }
+
+func TestOperations_SegmentSubSecond(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ops Operations
+	// 2 threads, each doing 10ms operations for 2 seconds.
+	for thread := uint16(0); thread < 2; thread++ {
+		for i := 0; i < 200; i++ {
+			opStart := start.Add(time.Duration(i) * 10 * time.Millisecond)
+			ops = append(ops, Operation{OpType: "PUT", Thread: thread, Start: opStart, End: opStart.Add(10 * time.Millisecond), Size: 1000, ObjPerOp: 1, Endpoint: "host"})
+		}
+	}
+	segs := ops.Segment(SegmentOptions{
+		From:           time.Time{},
+		PerSegDuration: 100 * time.Millisecond,
+		AllThreads:     true,
+	})
+	if len(segs) < 15 {
+		t.Fatalf("got %d segments, want at least 15", len(segs))
+	}
+	for i, s := range segs {
+		// Each segment contains 10 operations per thread.
+		if s.OpsEnded < 19 || s.OpsEnded > 21 {
+			t.Errorf("segment %d: got %d operations ended, want 20", i, s.OpsEnded)
+		}
+		if _, ops, _ := s.SpeedPerSec(); ops < 190 || ops > 210 {
+			t.Errorf("segment %d: got %.1f ops/s, want 200", i, ops)
+		}
+	}
+	if got := segs[0].String(); !strings.Contains(got, ".010 UTC") {
+		t.Errorf("sub-second start time not included: %s", got)
+	}
+}
+
+func TestOperations_SizeHistogram(t *testing.T) {
+	ops := Operations{
+		{OpType: "PUT", Size: 1000},
+		{OpType: "PUT", Size: 1024},
+		{OpType: "PUT", Size: 1500},
+		{OpType: "PUT", Size: 5000},
+		{OpType: "PUT", Size: 1 << 20, Err: "failed"},
+	}
+	want := []SizeBucket{
+		{Min: 512, Max: 1024, Count: 1},
+		{Min: 1024, Max: 2048, Count: 2},
+		{Min: 2048, Max: 4096, Count: 0},
+		{Min: 4096, Max: 8192, Count: 1},
+	}
+	got := ops.SizeHistogram()
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("bucket %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+	if got := (Operations{}).SizeHistogram(); len(got) != 0 {
+		t.Errorf("got %v for no operations", got)
+	}
+}
+
+func TestOperations_Slowest(t *testing.T) {
+	start := time.Now()
+	ops := Operations{
+		{OpType: "GET", File: "a", Start: start, End: start.Add(2 * time.Second)},
+		{OpType: OpAnnotation, File: "restart", Start: start, End: start.Add(time.Hour)},
+		{OpType: "PUT", File: "b", Start: start, End: start.Add(3 * time.Second)},
+		{OpType: "GET", File: "c", Start: start, End: start.Add(time.Second)},
+	}
+	got := ops.Slowest(2)
+	if len(got) != 2 || got[0].File != "b" || got[1].File != "a" {
+		t.Fatalf("unexpected slowest operations: %v", got)
+	}
+	if ops[0].File != "a" {
+		t.Error("operations were modified")
+	}
+	if got := ops.Slowest(10); len(got) != 3 {
+		t.Errorf("got %d operations, want 3", len(got))
+	}
+}
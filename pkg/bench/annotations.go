@@ -0,0 +1,93 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"sort"
+	"time"
+)
+
+// OpAnnotation is the operation type of annotated time windows.
+// Annotations are stored with the operations, with the label in the File field.
+const OpAnnotation = "ANNOTATION"
+
+// NewAnnotation returns an annotation of the time window between start and end.
+func NewAnnotation(label string, start, end time.Time) Operation {
+	return Operation{
+		OpType: OpAnnotation,
+		File:   label,
+		Start:  start,
+		End:    end,
+	}
+}
+
+// SplitAnnotations returns the operations without annotations and the annotations.
+func (o Operations) SplitAnnotations() (ops, annotations Operations) {
+	ops = make(Operations, 0, len(o))
+	for _, op := range o {
+		if op.OpType == OpAnnotation {
+			annotations = append(annotations, op)
+			continue
+		}
+		ops = append(ops, op)
+	}
+	return ops, annotations
+}
+
+// AnnotationLabels returns the labels of the annotations in alphabetical order.
+func (o Operations) AnnotationLabels() []string {
+	seen := make(map[string]struct{})
+	var labels []string
+	for _, op := range o {
+		if op.OpType != OpAnnotation {
+			continue
+		}
+		if _, ok := seen[op.File]; !ok {
+			seen[op.File] = struct{}{}
+			labels = append(labels, op.File)
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// SplitByAnnotation splits operations into operations starting inside
+// and outside windows of annotations with the label.
+func (o Operations) SplitByAnnotation(annotations Operations, label string) (inside, outside Operations) {
+	var windows Operations
+	for _, a := range annotations {
+		if a.OpType == OpAnnotation && a.File == label {
+			windows = append(windows, a)
+		}
+	}
+	for _, op := range o {
+		in := false
+		for _, w := range windows {
+			if !op.Start.Before(w.Start) && !op.Start.After(w.End) {
+				in = true
+				break
+			}
+		}
+		if in {
+			inside = append(inside, op)
+		} else {
+			outside = append(outside, op)
+		}
+	}
+	return inside, outside
+}
@@ -0,0 +1,194 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/minio/minio-go/v7/pkg/s3utils"
+)
+
+// Backend is the storage operations used by benchmarks.
+// Options and results use the minio-go types, also for other providers.
+// Benchmarks of S3 specific features, like select, retention and
+// signature comparisons, use the S3 client directly.
+type Backend interface {
+	// EndpointURL returns the URL of the endpoint requests are sent to.
+	EndpointURL() *url.URL
+
+	MakeBucket(ctx context.Context, bucket string, opts minio.MakeBucketOptions) error
+	BucketExists(ctx context.Context, bucket string) (bool, error)
+	RemoveBucket(ctx context.Context, bucket string) error
+	EnableVersioning(ctx context.Context, bucket string) error
+	GetBucketVersioning(ctx context.Context, bucket string) (minio.BucketVersioningConfiguration, error)
+
+	PutObject(ctx context.Context, bucket, object string, r io.Reader, size int64, opts minio.PutObjectOptions) (minio.UploadInfo, error)
+	// GetObject returns the object content.
+	// Errors may be returned when reading or calling Stat on the result.
+	GetObject(ctx context.Context, bucket, object string, opts minio.GetObjectOptions) (ObjectReader, error)
+	StatObject(ctx context.Context, bucket, object string, opts minio.StatObjectOptions) (minio.ObjectInfo, error)
+	// ListObjects lists objects. Errors are returned as objects with Err set.
+	ListObjects(ctx context.Context, bucket string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo
+	RemoveObject(ctx context.Context, bucket, object string, opts minio.RemoveObjectOptions) error
+	// RemoveObjects removes the objects sent on the channel.
+	// Objects that could not be removed are returned.
+	RemoveObjects(ctx context.Context, bucket string, objects <-chan minio.ObjectInfo, opts minio.RemoveObjectsOptions) <-chan minio.RemoveObjectError
+
+	NewMultipartUpload(ctx context.Context, bucket, object string, opts minio.PutObjectOptions) (uploadID string, err error)
+	PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, r io.Reader, size int64, sse encrypt.ServerSide) (minio.ObjectPart, error)
+	// CompleteMultipartUpload completes the upload and returns the ETag of the object.
+	CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, parts []minio.CompletePart, opts minio.PutObjectOptions) (string, error)
+	AbortMultipartUpload(ctx context.Context, bucket, object, uploadID string) error
+}
+
+// ListPager is implemented by backends that can list objects one page at a time,
+// so the latency of each page can be measured.
+type ListPager interface {
+	// ListObjectsPage returns up to maxKeys objects below the prefix, recursively,
+	// starting at the continuation token. 0 uses the default page size of the backend.
+	// The next token is empty when there are no more objects.
+	ListObjectsPage(ctx context.Context, bucket, prefix, token string, maxKeys int) (objects []minio.ObjectInfo, next string, err error)
+}
+
+// ObjectReader is the content of an object.
+type ObjectReader interface {
+	io.ReadCloser
+	// Stat returns information about the object.
+	Stat() (minio.ObjectInfo, error)
+}
+
+// S3Backend sends operations to an S3 server.
+type S3Backend struct {
+	*minio.Client
+}
+
+// NewS3Backend returns a backend using the S3 client.
+func NewS3Backend(client *minio.Client) Backend {
+	return S3Backend{Client: client}
+}
+
+// GetObject returns the object content.
+func (b S3Backend) GetObject(ctx context.Context, bucket, object string, opts minio.GetObjectOptions) (ObjectReader, error) {
+	o, err := b.Client.GetObject(ctx, bucket, object, opts)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// NewMultipartUpload starts a multipart upload and returns the upload ID.
+func (b S3Backend) NewMultipartUpload(ctx context.Context, bucket, object string, opts minio.PutObjectOptions) (string, error) {
+	return minio.Core{Client: b.Client}.NewMultipartUpload(ctx, bucket, object, opts)
+}
+
+// PutObjectPart uploads a part of a multipart upload.
+func (b S3Backend) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, r io.Reader, size int64, sse encrypt.ServerSide) (minio.ObjectPart, error) {
+	return minio.Core{Client: b.Client}.PutObjectPart(ctx, bucket, object, uploadID, partID, r, size, "", "", sse)
+}
+
+// CompleteMultipartUpload completes a multipart upload.
+func (b S3Backend) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, parts []minio.CompletePart, opts minio.PutObjectOptions) (string, error) {
+	return minio.Core{Client: b.Client}.CompleteMultipartUpload(ctx, bucket, object, uploadID, parts, opts)
+}
+
+// AbortMultipartUpload aborts a multipart upload.
+func (b S3Backend) AbortMultipartUpload(ctx context.Context, bucket, object, uploadID string) error {
+	return minio.Core{Client: b.Client}.AbortMultipartUpload(ctx, bucket, object, uploadID)
+}
+
+// S3Pager lists objects of the S3 API one page at a time with ListObjectsV2.
+// The client cannot cancel a page request, so the requests are signed and sent without it.
+type S3Pager struct {
+	// Client is the client whose host is listed.
+	Client *minio.Client
+	// AccessKey, SecretKey and Region sign the requests.
+	// They are sent with Transport.
+	AccessKey, SecretKey, Region string
+	Transport                    http.RoundTripper
+}
+
+// ListObjectsPage lists a page of objects with ListObjectsV2.
+func (p S3Pager) ListObjectsPage(ctx context.Context, bucket, prefix, token string, maxKeys int) ([]minio.ObjectInfo, string, error) {
+	query := url.Values{}
+	query.Set("list-type", "2")
+	query.Set("encoding-type", "url")
+	query.Set("prefix", prefix)
+	if token != "" {
+		query.Set("continuation-token", token)
+	}
+	if maxKeys > 0 {
+		query.Set("max-keys", strconv.Itoa(maxKeys))
+	}
+	target := *p.Client.EndpointURL()
+	target.Path = "/" + bucket + "/"
+	target.RawPath = s3utils.EncodePath(target.Path)
+	target.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	region := p.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	signV4(req, p.AccessKey, p.SecretKey, region, "s3", time.Now())
+	resp, err := p.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		errResp := minio.ErrorResponse{StatusCode: resp.StatusCode}
+		if err := xml.NewDecoder(resp.Body).Decode(&errResp); err != nil || errResp.Code == "" {
+			return nil, "", fmt.Errorf("list objects: %s", resp.Status)
+		}
+		return nil, "", errResp
+	}
+	var res minio.ListBucketV2Result
+	if err := xml.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, "", err
+	}
+	for i := range res.Contents {
+		obj := &res.Contents[i]
+		if res.EncodingType == "url" {
+			if obj.Key, err = url.QueryUnescape(obj.Key); err != nil {
+				return nil, "", err
+			}
+		}
+		obj.ETag = strings.Trim(obj.ETag, "\"")
+	}
+	if !res.IsTruncated {
+		return res.Contents, "", nil
+	}
+	if res.NextContinuationToken == "" {
+		return nil, "", errors.New("list objects: truncated without continuation token")
+	}
+	return res.Contents, res.NextContinuationToken, nil
+}
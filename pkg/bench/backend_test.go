@@ -0,0 +1,139 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/warp/pkg/bench"
+	"github.com/minio/warp/pkg/bench/backendtest"
+)
+
+// TestS3Backend runs the conformance tests against the S3 server in WARP_TEST_HOST.
+// The bucket warp-backend-test is created and removed.
+func TestS3Backend(t *testing.T) {
+	host := os.Getenv("WARP_TEST_HOST")
+	if host == "" {
+		t.Skip("WARP_TEST_HOST not set")
+	}
+	cl, err := minio.New(host, &minio.Options{
+		Creds:  credentials.NewStaticV4(os.Getenv("WARP_TEST_ACCESS_KEY"), os.Getenv("WARP_TEST_SECRET_KEY"), ""),
+		Secure: os.Getenv("WARP_TEST_TLS") != "",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	backendtest.Run(t, bench.NewS3Backend(cl), "warp-backend-test", backendtest.Options{
+		Versioning: true,
+		Multipart:  true,
+	})
+}
+
+func TestS3Pager(t *testing.T) {
+	keys := []string{"p/a b", "p/b", "p/c", "p/d", "p/e", "p/f", "p/g"}
+	canceled := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") || q.Get("list-type") != "2" {
+			t.Errorf("unexpected request %s %v", r.URL, r.Header)
+		}
+		switch r.URL.Path {
+		case "/missing/":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchBucket</Code><Message>The specified bucket does not exist</Message></Error>`)
+			return
+		case "/slow/":
+			<-r.Context().Done()
+			close(canceled)
+			return
+		}
+		start, _ := strconv.Atoi(q.Get("continuation-token"))
+		n, _ := strconv.Atoi(q.Get("max-keys"))
+		end := start + n
+		if end > len(keys) {
+			end = len(keys)
+		}
+		fmt.Fprint(w, `<ListBucketResult><EncodingType>url</EncodingType>`)
+		for _, k := range keys[start:end] {
+			fmt.Fprintf(w, `<Contents><Key>%s</Key><ETag>"etag"</ETag><Size>1</Size></Contents>`, url.QueryEscape(k))
+		}
+		if end < len(keys) {
+			fmt.Fprintf(w, `<IsTruncated>true</IsTruncated><NextContinuationToken>%d</NextContinuationToken>`, end)
+		}
+		fmt.Fprint(w, `</ListBucketResult>`)
+	}))
+	defer srv.Close()
+	cl, err := minio.New(strings.TrimPrefix(srv.URL, "http://"), &minio.Options{Creds: credentials.NewStaticV4("key", "secret", "")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pager := bench.S3Pager{Client: cl, AccessKey: "key", SecretKey: "secret", Transport: http.DefaultTransport}
+
+	var got []string
+	pages := 0
+	token := ""
+	for {
+		objs, next, err := pager.ListObjectsPage(context.Background(), "bucket", "p/", token, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages++
+		for _, obj := range objs {
+			if obj.ETag != "etag" {
+				t.Errorf("%s: etag %q", obj.Key, obj.ETag)
+			}
+			got = append(got, obj.Key)
+		}
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	if fmt.Sprint(got) != fmt.Sprint(keys) || pages != 3 {
+		t.Errorf("got %q in %d pages, want %q in 3", got, pages, keys)
+	}
+
+	_, _, err = pager.ListObjectsPage(context.Background(), "missing", "", "", 0)
+	if minio.ToErrorResponse(err).Code != "NoSuchBucket" {
+		t.Errorf("got %v, want NoSuchBucket", err)
+	}
+
+	// Canceling a page stops the request.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, _, err := pager.ListObjectsPage(ctx, "slow", "", "", 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want deadline exceeded", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Error("request not canceled")
+	}
+}
@@ -0,0 +1,412 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package backendtest contains conformance tests for benchmark backends.
+package backendtest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/warp/pkg/bench"
+)
+
+// Options controls which parts of the backend are tested.
+type Options struct {
+	// Versioning tests object versions.
+	Versioning bool
+	// Multipart tests multipart uploads.
+	Multipart bool
+	// PartSize is the size of multipart upload parts except the last.
+	// Defaults to 5 MiB, the minimum allowed by S3.
+	PartSize int64
+}
+
+// Run tests the backend conforms to the behavior expected by benchmarks.
+// The bucket is created if it doesn't exist and is removed when done.
+// All content of the bucket is deleted.
+func Run(t *testing.T, b bench.Backend, bucket string, opts Options) {
+	t.Helper()
+	ctx := context.Background()
+	exists, err := b.BucketExists(ctx, bucket)
+	if err != nil {
+		t.Fatal("bucket exists:", err)
+	}
+	if !exists {
+		if err := b.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}); err != nil {
+			t.Fatal("make bucket:", err)
+		}
+	}
+	exists, err = b.BucketExists(ctx, bucket)
+	if err != nil {
+		t.Fatal("bucket exists:", err)
+	}
+	if !exists {
+		t.Fatal("bucket does not exist after creation")
+	}
+	if b.EndpointURL() == nil {
+		t.Error("no endpoint URL")
+	}
+	clearBucket(t, b, bucket)
+	t.Cleanup(func() {
+		clearBucket(t, b, bucket)
+		if err := b.RemoveBucket(ctx, bucket); err != nil {
+			t.Error("remove bucket:", err)
+		}
+	})
+
+	t.Run("PutGet", func(t *testing.T) { testPutGet(t, b, bucket) })
+	t.Run("Stat", func(t *testing.T) { testStat(t, b, bucket) })
+	t.Run("List", func(t *testing.T) { testList(t, b, bucket) })
+	t.Run("Remove", func(t *testing.T) { testRemove(t, b, bucket) })
+	if opts.Multipart {
+		t.Run("Multipart", func(t *testing.T) { testMultipart(t, b, bucket, opts.PartSize) })
+	}
+	if opts.Versioning {
+		t.Run("Versioning", func(t *testing.T) { testVersioning(t, b, bucket) })
+	}
+}
+
+// clearBucket removes all objects and versions in the bucket.
+func clearBucket(t *testing.T, b bench.Backend, bucket string) {
+	t.Helper()
+	ctx := context.Background()
+	objects := make(chan minio.ObjectInfo)
+	go func() {
+		defer close(objects)
+		for obj := range b.ListObjects(ctx, bucket, minio.ListObjectsOptions{Recursive: true, WithVersions: true}) {
+			if obj.Err != nil {
+				t.Error("list:", obj.Err)
+				return
+			}
+			objects <- obj
+		}
+	}()
+	for err := range b.RemoveObjects(ctx, bucket, objects, minio.RemoveObjectsOptions{}) {
+		t.Errorf("remove %s: %v", err.ObjectName, err.Err)
+	}
+}
+
+// put uploads the content as object name.
+func put(t *testing.T, b bench.Backend, bucket, name string, content []byte) minio.UploadInfo {
+	t.Helper()
+	info, err := b.PutObject(context.Background(), bucket, name, bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{})
+	if err != nil {
+		t.Fatalf("put %s: %v", name, err)
+	}
+	if info.Size != int64(len(content)) {
+		t.Errorf("put %s: size %d, want %d", name, info.Size, len(content))
+	}
+	return info
+}
+
+// get returns the content of object name.
+func get(t *testing.T, b bench.Backend, bucket, name string, opts minio.GetObjectOptions) []byte {
+	t.Helper()
+	o, err := b.GetObject(context.Background(), bucket, name, opts)
+	if err != nil {
+		t.Fatalf("get %s: %v", name, err)
+	}
+	defer o.Close()
+	got, err := io.ReadAll(o)
+	if err != nil {
+		t.Fatalf("get %s: %v", name, err)
+	}
+	return got
+}
+
+// isNotFound returns whether the error is a missing object.
+func isNotFound(err error) bool {
+	resp := minio.ToErrorResponse(err)
+	return resp.Code == "NoSuchKey" || resp.StatusCode == 404
+}
+
+func testPutGet(t *testing.T, b bench.Backend, bucket string) {
+	content := bytes.Repeat([]byte("warp"), 1000)
+	put(t, b, bucket, "putget/object", content)
+	if got := get(t, b, bucket, "putget/object", minio.GetObjectOptions{}); !bytes.Equal(got, content) {
+		t.Errorf("got %d bytes, want %d matching bytes", len(got), len(content))
+	}
+
+	// Ranged get.
+	var opts minio.GetObjectOptions
+	if err := opts.SetRange(10, 19); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(t, b, bucket, "putget/object", opts); !bytes.Equal(got, content[10:20]) {
+		t.Errorf("range: got %q, want %q", got, content[10:20])
+	}
+
+	// Overwrite.
+	put(t, b, bucket, "putget/object", []byte("new"))
+	if got := get(t, b, bucket, "putget/object", minio.GetObjectOptions{}); string(got) != "new" {
+		t.Errorf("overwrite: got %q, want %q", got, "new")
+	}
+
+	// Empty object.
+	put(t, b, bucket, "putget/empty", nil)
+	if got := get(t, b, bucket, "putget/empty", minio.GetObjectOptions{}); len(got) != 0 {
+		t.Errorf("empty: got %d bytes", len(got))
+	}
+
+	// Missing objects must return an error when read.
+	o, err := b.GetObject(context.Background(), bucket, "putget/missing", minio.GetObjectOptions{})
+	if err == nil {
+		_, err = io.ReadAll(o)
+		o.Close()
+	}
+	if !isNotFound(err) {
+		t.Errorf("missing: got error %v, want not found", err)
+	}
+}
+
+func testStat(t *testing.T, b bench.Backend, bucket string) {
+	content := []byte("stat content")
+	info := put(t, b, bucket, "stat/object", content)
+	st, err := b.StatObject(context.Background(), bucket, "stat/object", minio.StatObjectOptions{})
+	if err != nil {
+		t.Fatal("stat:", err)
+	}
+	if st.Key != "stat/object" {
+		t.Errorf("key: got %q", st.Key)
+	}
+	if st.Size != int64(len(content)) {
+		t.Errorf("size: got %d, want %d", st.Size, len(content))
+	}
+	if info.ETag != "" && st.ETag != info.ETag {
+		t.Errorf("etag: got %q, want %q", st.ETag, info.ETag)
+	}
+
+	o, err := b.GetObject(context.Background(), bucket, "stat/object", minio.GetObjectOptions{})
+	if err != nil {
+		t.Fatal("get:", err)
+	}
+	defer o.Close()
+	st, err = o.Stat()
+	if err != nil {
+		t.Fatal("get stat:", err)
+	}
+	if st.Size != int64(len(content)) {
+		t.Errorf("get stat size: got %d, want %d", st.Size, len(content))
+	}
+
+	_, err = b.StatObject(context.Background(), bucket, "stat/missing", minio.StatObjectOptions{})
+	if !isNotFound(err) {
+		t.Errorf("missing: got error %v, want not found", err)
+	}
+}
+
+func testList(t *testing.T, b bench.Backend, bucket string) {
+	var want []string
+	for i := 0; i < 25; i++ {
+		name := fmt.Sprintf("list/%d/object-%02d", i%2, i)
+		put(t, b, bucket, name, []byte(name))
+		want = append(want, name)
+	}
+	sort.Strings(want)
+
+	list := func(opts minio.ListObjectsOptions) []string {
+		t.Helper()
+		var res []string
+		for obj := range b.ListObjects(context.Background(), bucket, opts) {
+			if obj.Err != nil {
+				t.Fatal("list:", obj.Err)
+			}
+			res = append(res, obj.Key)
+		}
+		return res
+	}
+
+	got := list(minio.ListObjectsOptions{Prefix: "list/", Recursive: true})
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("recursive: got %v, want %v", got, want)
+	}
+	got = list(minio.ListObjectsOptions{Prefix: "list/", Recursive: true, MaxKeys: 10})
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("paged: got %v, want %v", got, want)
+	}
+	if pager, ok := b.(bench.ListPager); ok {
+		got = nil
+		pages := 0
+		token := ""
+		for {
+			objs, next, err := pager.ListObjectsPage(context.Background(), bucket, "list/", token, 10)
+			if err != nil {
+				t.Fatal("list page:", err)
+			}
+			pages++
+			for _, obj := range objs {
+				got = append(got, obj.Key)
+			}
+			if next == "" {
+				break
+			}
+			token = next
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) || pages != 3 {
+			t.Errorf("pages: got %v in %d pages, want %v in 3", got, pages, want)
+		}
+	}
+	got = list(minio.ListObjectsOptions{Prefix: "list/1/", Recursive: true})
+	if len(got) != 12 {
+		t.Errorf("prefix: got %d objects, want 12", len(got))
+	}
+	got = list(minio.ListObjectsOptions{Prefix: "list/"})
+	if fmt.Sprint(got) != fmt.Sprint([]string{"list/0/", "list/1/"}) {
+		t.Errorf("delimited: got %v", got)
+	}
+}
+
+func testRemove(t *testing.T, b bench.Backend, bucket string) {
+	ctx := context.Background()
+	put(t, b, bucket, "remove/single", []byte("x"))
+	if err := b.RemoveObject(ctx, bucket, "remove/single", minio.RemoveObjectOptions{}); err != nil {
+		t.Fatal("remove:", err)
+	}
+	if _, err := b.StatObject(ctx, bucket, "remove/single", minio.StatObjectOptions{}); !isNotFound(err) {
+		t.Errorf("removed: got error %v, want not found", err)
+	}
+
+	objects := make(chan minio.ObjectInfo, 10)
+	for i := 0; i < cap(objects); i++ {
+		name := fmt.Sprintf("remove/multi/%d", i)
+		put(t, b, bucket, name, []byte(name))
+		objects <- minio.ObjectInfo{Key: name}
+	}
+	close(objects)
+	for err := range b.RemoveObjects(ctx, bucket, objects, minio.RemoveObjectsOptions{}) {
+		t.Errorf("remove %s: %v", err.ObjectName, err.Err)
+	}
+	for obj := range b.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: "remove/", Recursive: true}) {
+		if obj.Err != nil {
+			t.Fatal("list:", obj.Err)
+		}
+		t.Errorf("object %q not removed", obj.Key)
+	}
+}
+
+func testMultipart(t *testing.T, b bench.Backend, bucket string, partSize int64) {
+	ctx := context.Background()
+	if partSize <= 0 {
+		partSize = 5 << 20
+	}
+	const name = "multipart/object"
+	uploadID, err := b.NewMultipartUpload(ctx, bucket, name, minio.PutObjectOptions{})
+	if err != nil {
+		t.Fatal("new upload:", err)
+	}
+	var want []byte
+	var parts []minio.CompletePart
+	for i := 1; i <= 3; i++ {
+		size := partSize
+		if i == 3 {
+			size = 100
+		}
+		content := bytes.Repeat([]byte{byte('a' + i)}, int(size))
+		want = append(want, content...)
+		part, err := b.PutObjectPart(ctx, bucket, name, uploadID, i, bytes.NewReader(content), size, nil)
+		if err != nil {
+			t.Fatalf("put part %d: %v", i, err)
+		}
+		parts = append(parts, minio.CompletePart{PartNumber: i, ETag: part.ETag})
+	}
+	if _, err := b.CompleteMultipartUpload(ctx, bucket, name, uploadID, parts, minio.PutObjectOptions{}); err != nil {
+		t.Fatal("complete:", err)
+	}
+	if got := get(t, b, bucket, name, minio.GetObjectOptions{}); !bytes.Equal(got, want) {
+		t.Errorf("got %d bytes, want %d matching bytes", len(got), len(want))
+	}
+	var opts minio.GetObjectOptions
+	opts.PartNumber = 2
+	if got := get(t, b, bucket, name, opts); !bytes.Equal(got, want[partSize:2*partSize]) {
+		t.Errorf("part 2: got %d bytes, want %d matching bytes", len(got), partSize)
+	}
+
+	uploadID, err = b.NewMultipartUpload(ctx, bucket, "multipart/aborted", minio.PutObjectOptions{})
+	if err != nil {
+		t.Fatal("new upload:", err)
+	}
+	if _, err := b.PutObjectPart(ctx, bucket, "multipart/aborted", uploadID, 1, bytes.NewReader([]byte("x")), 1, nil); err != nil {
+		t.Fatal("put part:", err)
+	}
+	if err := b.AbortMultipartUpload(ctx, bucket, "multipart/aborted", uploadID); err != nil {
+		t.Fatal("abort:", err)
+	}
+	if _, err := b.StatObject(ctx, bucket, "multipart/aborted", minio.StatObjectOptions{}); !isNotFound(err) {
+		t.Errorf("aborted: got error %v, want not found", err)
+	}
+}
+
+func testVersioning(t *testing.T, b bench.Backend, bucket string) {
+	ctx := context.Background()
+	if err := b.EnableVersioning(ctx, bucket); err != nil {
+		t.Fatal("enable versioning:", err)
+	}
+	cfg, err := b.GetBucketVersioning(ctx, bucket)
+	if err != nil {
+		t.Fatal("get versioning:", err)
+	}
+	if cfg.Status != "Enabled" {
+		t.Fatalf("versioning status %q, want Enabled", cfg.Status)
+	}
+
+	const name = "versions/object"
+	v1 := put(t, b, bucket, name, []byte("v1"))
+	v2 := put(t, b, bucket, name, []byte("v2"))
+	if v1.VersionID == "" || v1.VersionID == v2.VersionID {
+		t.Fatalf("version IDs %q and %q", v1.VersionID, v2.VersionID)
+	}
+	if got := get(t, b, bucket, name, minio.GetObjectOptions{VersionID: v1.VersionID}); string(got) != "v1" {
+		t.Errorf("version 1: got %q", got)
+	}
+	if got := get(t, b, bucket, name, minio.GetObjectOptions{}); string(got) != "v2" {
+		t.Errorf("latest: got %q", got)
+	}
+	st, err := b.StatObject(ctx, bucket, name, minio.StatObjectOptions{VersionID: v1.VersionID})
+	if err != nil {
+		t.Fatal("stat version:", err)
+	}
+	if st.VersionID != v1.VersionID {
+		t.Errorf("stat version: got %q, want %q", st.VersionID, v1.VersionID)
+	}
+
+	var versions int
+	for obj := range b.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: "versions/", Recursive: true, WithVersions: true}) {
+		if obj.Err != nil {
+			t.Fatal("list:", obj.Err)
+		}
+		versions++
+	}
+	if versions != 2 {
+		t.Errorf("listed %d versions, want 2", versions)
+	}
+
+	if err := b.RemoveObject(ctx, bucket, name, minio.RemoveObjectOptions{VersionID: v1.VersionID}); err != nil {
+		t.Fatal("remove version:", err)
+	}
+	if _, err := b.StatObject(ctx, bucket, name, minio.StatObjectOptions{VersionID: v1.VersionID}); err == nil {
+		t.Error("removed version still exists")
+	}
+	if got := get(t, b, bucket, name, minio.GetObjectOptions{}); string(got) != "v2" {
+		t.Errorf("latest after remove: got %q", got)
+	}
+}
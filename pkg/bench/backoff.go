@@ -0,0 +1,305 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// BackoffInitial is the default delay after the first throttled request to a prefix.
+	BackoffInitial = 100 * time.Millisecond
+	// BackoffMax is the default maximum delay of requests to a prefix.
+	BackoffMax = 30 * time.Second
+
+	// backoffMaxBody is the maximum size of 503 response bodies checked for the SlowDown code.
+	backoffMaxBody = 64 << 10
+)
+
+// Backoff delays requests to prefixes the server has throttled with
+// 503 SlowDown responses. The delay doubles with each throttled request
+// to the prefix up to Max and is reduced again by successful requests.
+// A Retry-After header is respected if it asks for a longer delay.
+// Throttled responses are returned to the client, which retries them.
+// The prefix of a request is its bucket and key up to the last slash.
+// Operations should call Wait before they are timed,
+// so the delay is not included in their duration.
+type Backoff struct {
+	// Initial delay after the first throttled request.
+	Initial time.Duration
+	// Max is the maximum delay.
+	Max time.Duration
+
+	mu       sync.Mutex
+	prefixes map[string]*backoffPrefix
+	stats    BackoffStats
+	rng      *rand.Rand
+	// gen is incremented when stats are reset.
+	gen int
+}
+
+// BackoffStats contains throttling seen by requests.
+type BackoffStats struct {
+	// Requests sent.
+	Requests int64
+	// Throttled is the number of 503 SlowDown responses.
+	Throttled int64
+	// Prefixes that were throttled.
+	Prefixes int
+	// Waited is the total time requests were delayed.
+	Waited time.Duration
+	// MaxDelay is the longest delay of a prefix.
+	MaxDelay time.Duration
+	// First and Last request sent.
+	First, Last time.Time
+}
+
+// String returns a summary of the throttling and the achieved request rate.
+func (s BackoffStats) String() string {
+	if s.Throttled == 0 {
+		return fmt.Sprintf("Throttling: None of %d requests throttled.", s.Requests)
+	}
+	res := fmt.Sprintf("Throttling: %d of %d requests (%.2f%%) got 503 SlowDown on %d prefixes. Requests waited %v in total, max delay %v.",
+		s.Throttled, s.Requests, 100*float64(s.Throttled)/float64(s.Requests), s.Prefixes, s.Waited.Round(time.Millisecond), s.MaxDelay.Round(time.Millisecond))
+	if secs := s.Last.Sub(s.First).Seconds(); secs > 0 {
+		res += fmt.Sprintf(" Effective rate %.1f req/s of %.1f req/s sent.", float64(s.Requests-s.Throttled)/secs, float64(s.Requests)/secs)
+	}
+	return res
+}
+
+type backoffPrefix struct {
+	// attempt is the number of throttled requests not offset by successful requests.
+	attempt int
+	until   time.Time
+	// gen is the stats generation the prefix was last counted in.
+	gen int
+}
+
+// NewBackoff returns a backoff with the default delays.
+func NewBackoff() *Backoff {
+	return &Backoff{
+		Initial:  BackoffInitial,
+		Max:      BackoffMax,
+		prefixes: make(map[string]*backoffPrefix),
+		rng:      rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Stats returns the throttling seen so far.
+func (b *Backoff) Stats() BackoffStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+// ResetStats clears the stats, for example when a benchmark starts after preparing.
+// Delays of throttled prefixes are kept.
+func (b *Backoff) ResetStats() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stats = BackoffStats{}
+	b.gen++
+}
+
+// Add adds the throttling seen by other clients.
+func (s *BackoffStats) Add(other BackoffStats) {
+	s.Requests += other.Requests
+	s.Throttled += other.Throttled
+	s.Prefixes += other.Prefixes
+	s.Waited += other.Waited
+	if other.MaxDelay > s.MaxDelay {
+		s.MaxDelay = other.MaxDelay
+	}
+	if !other.First.IsZero() && (s.First.IsZero() || other.First.Before(s.First)) {
+		s.First = other.First
+	}
+	if other.Last.After(s.Last) {
+		s.Last = other.Last
+	}
+}
+
+// Wait blocks while requests to the object in the bucket are delayed
+// or until the context is canceled.
+func (b *Backoff) Wait(ctx context.Context, bucket, object string) error {
+	return sleepContext(ctx, b.delay(backoffKeyPrefix(bucket, object), false))
+}
+
+// Transport returns a transport that delays requests to throttled prefixes before sending them with tr.
+// Requests that were not delayed by Wait, like retries of throttled requests, are delayed here.
+// host is the endpoint of the client, used to find the bucket of virtual host style requests.
+func (b *Backoff) Transport(tr http.RoundTripper, host string) http.RoundTripper {
+	return backoffTransport{b: b, tr: tr, host: host}
+}
+
+type backoffTransport struct {
+	b    *Backoff
+	tr   http.RoundTripper
+	host string
+}
+
+func (t backoffTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b := t.b
+	prefix := backoffKeyPrefix(t.objectOf(req))
+	if err := sleepContext(req.Context(), b.delay(prefix, true)); err != nil {
+		return nil, err
+	}
+	resp, err := t.tr.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	throttled, err := isSlowDown(resp)
+	if err != nil {
+		return nil, err
+	}
+	b.done(prefix, throttled, retryAfter(resp))
+	return resp, nil
+}
+
+// objectOf returns the bucket and key of the request.
+func (t backoffTransport) objectOf(req *http.Request) (bucket, key string) {
+	p := strings.TrimPrefix(req.URL.Path, "/")
+	if t.host != "" && req.URL.Host != t.host {
+		// Virtual host style. Bucket names with dots always use path style.
+		bucket, _, _ = strings.Cut(req.URL.Host, ".")
+		return bucket, p
+	}
+	bucket, key, _ = strings.Cut(p, "/")
+	return bucket, key
+}
+
+// sleepContext sleeps for d or until the context is canceled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// delay returns the time to wait before sending a request to the prefix.
+// request is false when waiting before an operation starts.
+func (b *Backoff) delay(prefix string, request bool) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if request {
+		if b.stats.First.IsZero() {
+			b.stats.First = now
+		}
+		b.stats.Requests++
+	}
+	p := b.prefixes[prefix]
+	if p == nil || !p.until.After(now) {
+		return 0
+	}
+	wait := p.until.Sub(now)
+	b.stats.Waited += wait
+	return wait
+}
+
+// done updates the delay of the prefix after a request.
+func (b *Backoff) done(prefix string, throttled bool, minDelay time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.stats.Last = now
+	p := b.prefixes[prefix]
+	if !throttled {
+		if p != nil && p.attempt > 0 {
+			p.attempt--
+		}
+		return
+	}
+	b.stats.Throttled++
+	if p == nil {
+		p = &backoffPrefix{gen: -1}
+		b.prefixes[prefix] = p
+	}
+	if p.gen != b.gen {
+		p.gen = b.gen
+		b.stats.Prefixes++
+	}
+	p.attempt++
+	d := b.Max
+	if p.attempt < 32 && b.Initial<<(p.attempt-1) < b.Max {
+		d = b.Initial << (p.attempt - 1)
+	}
+	// Equal jitter, so requests from several clients are spread out.
+	d = d/2 + time.Duration(b.rng.Int63n(int64(d/2)+1))
+	if minDelay > d {
+		d = minDelay
+	}
+	if d > b.stats.MaxDelay {
+		b.stats.MaxDelay = d
+	}
+	if until := now.Add(d); until.After(p.until) {
+		p.until = until
+	}
+}
+
+// backoffKeyPrefix returns the prefix of the key in the bucket.
+func backoffKeyPrefix(bucket, key string) string {
+	p := bucket + "/" + key
+	return p[:strings.LastIndexByte(p, '/')+1]
+}
+
+// isSlowDown returns whether the response is a 503 SlowDown.
+// 503 responses without a body, like for HEAD requests, are assumed to be SlowDown.
+// The body is read and replaced so it can still be read by the client.
+func isSlowDown(resp *http.Response) (bool, error) {
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		return false, nil
+	}
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return true, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, backoffMaxBody))
+	if err != nil {
+		resp.Body.Close()
+		return false, err
+	}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+	return len(body) == 0 || bytes.Contains(body, []byte("<Code>SlowDown</Code>")), nil
+}
+
+// retryAfter returns the delay of the Retry-After header in seconds, if any.
+func retryAfter(resp *http.Response) time.Duration {
+	s, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || s < 0 {
+		return 0
+	}
+	return time.Duration(s) * time.Second
+}
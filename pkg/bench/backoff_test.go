@@ -0,0 +1,116 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	var hot int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/bucket/hot/") && atomic.AddInt32(&hot, 1) <= 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			io.WriteString(w, "<Error><Code>SlowDown</Code><Message>Please reduce your request rate.</Message></Error>")
+			return
+		}
+		if r.URL.Path == "/bucket/unavailable" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			io.WriteString(w, "<Error><Code>ServiceUnavailable</Code></Error>")
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	b := NewBackoff()
+	b.Initial = 40 * time.Millisecond
+	b.Max = 100 * time.Millisecond
+	cl := http.Client{Transport: b.Transport(http.DefaultTransport, "")}
+	get := func(path string) (int, string, time.Duration) {
+		start := time.Now()
+		resp, err := cl.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(body), time.Since(start)
+	}
+
+	for i := 0; i < 3; i++ {
+		status, body, _ := get("/bucket/hot/obj")
+		if status != http.StatusServiceUnavailable || !strings.Contains(body, "SlowDown") {
+			t.Fatalf("request %d: got %d %q", i, status, body)
+		}
+	}
+	// Other prefixes are not delayed.
+	if status, _, took := get("/bucket/cold/obj"); status != http.StatusOK || took > 30*time.Millisecond {
+		t.Fatalf("cold prefix: got %d after %v", status, took)
+	}
+	// The third throttled request gives at least half of 160ms, capped to 100ms.
+	if status, _, took := get("/bucket/hot/other"); status != http.StatusOK || took < 40*time.Millisecond {
+		t.Fatalf("hot prefix: got %d after %v", status, took)
+	}
+	if status, _, _ := get("/bucket/unavailable"); status != http.StatusServiceUnavailable {
+		t.Fatalf("got %d", status)
+	}
+
+	st := b.Stats()
+	if st.Requests != 6 || st.Throttled != 3 || st.Prefixes != 1 || st.Waited < 40*time.Millisecond || st.MaxDelay > b.Max {
+		t.Fatalf("got stats %+v", st)
+	}
+	if s := st.String(); !strings.Contains(s, "3 of 6 requests") || !strings.Contains(s, "Effective rate") {
+		t.Errorf("got %q", s)
+	}
+	// Operations wait before they are timed, without sending requests.
+	atomic.StoreInt32(&hot, 2)
+	if status, _, _ := get("/bucket/hot/obj"); status != http.StatusServiceUnavailable {
+		t.Fatalf("got %d", status)
+	}
+	start := time.Now()
+	if err := b.Wait(context.Background(), "bucket", "hot/obj"); err != nil || time.Since(start) < 20*time.Millisecond {
+		t.Fatalf("wait: %v after %v", err, time.Since(start))
+	}
+	if status, _, took := get("/bucket/hot/obj"); status != http.StatusOK || took > 30*time.Millisecond {
+		t.Fatalf("after wait: got %d after %v", status, took)
+	}
+	if got := b.Stats().Requests; got != 8 {
+		t.Fatalf("got %d requests, want 8", got)
+	}
+
+	total := b.Stats()
+	total.Add(st)
+	if total.Requests != 14 || total.Throttled != 7 || total.MaxDelay < st.MaxDelay || !total.First.Equal(st.First) {
+		t.Fatalf("got stats %+v after add", total)
+	}
+	b.ResetStats()
+	if st := b.Stats(); st.Requests != 0 || st.Prefixes != 0 {
+		t.Fatalf("got stats %+v after reset", st)
+	}
+}
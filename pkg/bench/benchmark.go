@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/rand"
 	"strings"
 	"time"
 
@@ -47,8 +48,32 @@ type Benchmark interface {
 
 // Common contains common benchmark parameters.
 type Common struct {
+	// prepObjects and prepBytes count objects uploaded while preparing.
+	// First in the struct for 64-bit alignment of atomic operations.
+	prepObjects, prepBytes int64
+
 	Client func() (cl *minio.Client, done func())
 
+	// MetaClient is used for bucket and metadata operations if set.
+	MetaClient func() (cl *minio.Client, done func())
+
+	// KeyClient is used for object operations if set.
+	KeyClient func(key string) (cl *minio.Client, done func())
+
+	// NewBackend returns the backend used for operations with a client.
+	// If nil the S3 API of the client is used.
+	NewBackend func(cl *minio.Client) Backend
+
+	// Manifest records objects uploaded while preparing if set.
+	Manifest *Manifest
+
+	// Registry records objects uploaded while preparing to a file if set.
+	Registry *Registry
+
+	// Journal restricts cleanup to objects created by warp if set.
+	// Clients must use a transport returned by Journal.Transport.
+	Journal *Journal
+
 	Concurrency int
 	Source      func() generator.Source
 	Bucket      string
@@ -70,6 +95,11 @@ type Common struct {
 	// Default Put options.
 	PutOpts minio.PutObjectOptions
 
+	// Deterministic seeds the random choices of threads with Seed,
+	// so runs with a single thread make the same operations in the same order.
+	Deterministic bool
+	Seed          int64
+
 	// Custom is returned to server if set by clients.
 	Custom map[string]string
 
@@ -80,6 +110,36 @@ type Common struct {
 	// Will be 0 if single client.
 	ClientIdx int
 
+	// LatencyTrace records where the time of operations is spent.
+	// Clients must use a transport returned by NewLatencyTransport.
+	LatencyTrace bool
+
+	// Abort is done when the benchmark is aborted, if set.
+	// Operations in progress are canceled when it is done,
+	// while they can finish when the context passed to Start is done.
+	Abort context.Context
+
+	// OpTimeout is the maximum duration of each operation if > 0.
+	// Operations that take longer fail with a timeout error.
+	OpTimeout time.Duration
+
+	// Limiter limits the rate operations are started if set.
+	Limiter *RateLimiter
+
+	// Backoff delays operations on prefixes throttled by the server if set.
+	// Clients must use a transport returned by Backoff.Transport.
+	Backoff *Backoff
+
+	// Live receives operations as they finish if set.
+	Live *LiveStats
+
+	// Limits measures recording of operations if set.
+	Limits *ClientLimits
+
+	// Output receives operations as they are recorded if set.
+	// Operations are then not kept in memory, unless needed for auto termination.
+	Output func(Operation)
+
 	// ExtraFlags contains extra flags to add to remote clients.
 	ExtraFlags map[string]string
 }
@@ -103,10 +163,110 @@ func (c *Common) ErrorF(format string, data ...interface{}) {
 	c.Error(fmt.Sprintf(format, data...))
 }
 
+// collector returns a collector for the operations of the benchmark.
+func (c *Common) collector() *Collector {
+	return newCollector(c.Live, c.Limits, c.Output)
+}
+
+// backend returns the backend for the client.
+func (c *Common) backend(cl *minio.Client) Backend {
+	if c.NewBackend != nil {
+		return c.NewBackend(cl)
+	}
+	return NewS3Backend(cl)
+}
+
+// metaClient returns a backend for bucket and metadata operations.
+func (c *Common) metaClient() (Backend, func()) {
+	cl, done := c.s3MetaClient()
+	return c.backend(cl), done
+}
+
+// clientFor returns a backend for operations on the object key.
+func (c *Common) clientFor(key string) (Backend, func()) {
+	cl, done := c.s3ClientFor(key)
+	return c.backend(cl), done
+}
+
+// anyClient returns a backend for operations on any host.
+func (c *Common) anyClient() (Backend, func()) {
+	cl, done := c.Client()
+	return c.backend(cl), done
+}
+
+// s3MetaClient returns an S3 client for bucket and metadata operations.
+func (c *Common) s3MetaClient() (*minio.Client, func()) {
+	if c.MetaClient != nil {
+		return c.MetaClient()
+	}
+	return c.Client()
+}
+
+// s3ClientFor returns an S3 client for operations on the object key.
+// Only use for S3 specific operations.
+func (c *Common) s3ClientFor(key string) (*minio.Client, func()) {
+	if c.KeyClient != nil {
+		return c.KeyClient(key)
+	}
+	return c.Client()
+}
+
+// setObjectOpts sets the content type, user metadata and tags of the object in opts.
+// User metadata already in opts is kept unless the object has the same keys.
+func setObjectOpts(opts *minio.PutObjectOptions, obj *generator.Object) {
+	opts.ContentType = obj.ContentType
+	opts.ContentEncoding = obj.ContentEncoding
+	if len(obj.UserMetadata) > 0 {
+		if len(opts.UserMetadata) == 0 {
+			opts.UserMetadata = obj.UserMetadata
+		} else {
+			meta := make(map[string]string, len(opts.UserMetadata)+len(obj.UserMetadata))
+			for k, v := range opts.UserMetadata {
+				meta[k] = v
+			}
+			for k, v := range obj.UserMetadata {
+				meta[k] = v
+			}
+			opts.UserMetadata = meta
+		}
+	}
+	if obj.UserTags != nil {
+		opts.UserTags = obj.UserTags
+	}
+}
+
+// uploadedSize returns the size of an uploaded object recorded in operations.
+// Compressed objects are recorded with their size before compression,
+// so throughput is comparable to uploads of the same data without compression.
+func uploadedSize(obj *generator.Object, size int64) int64 {
+	if obj.DecodedSize > 0 && size == obj.Size {
+		return obj.DecodedSize
+	}
+	return size
+}
+
+// threadRng returns the random source of a benchmark thread.
+// It is seeded from the time unless the benchmark is deterministic.
+func (c *Common) threadRng(i int) *rand.Rand {
+	if c.Deterministic {
+		return rand.New(rand.NewSource(c.Seed + int64(i)))
+	}
+	return rand.New(rand.NewSource(time.Now().UnixNano() + int64(i)))
+}
+
+// indexRng returns the random source of a benchmark thread
+// that is seeded with the thread index unless the benchmark is deterministic.
+func (c *Common) indexRng(i int) *rand.Rand {
+	if c.Deterministic {
+		return c.threadRng(i)
+	}
+	return rand.New(rand.NewSource(int64(i)))
+}
+
 // createEmptyBucket will create an empty bucket
 // or delete all content if it already exists.
 func (c *Common) createEmptyBucket(ctx context.Context) error {
-	cl, done := c.Client()
+	cl, done := c.metaClient()
 	defer done()
 	x, err := cl.BucketExists(ctx, c.Bucket)
 	if err != nil {
@@ -114,7 +274,10 @@ func (c *Common) createEmptyBucket(ctx context.Context) error {
 	}
 
 	if x && c.Locking {
-		_, _, _, err := cl.GetBucketObjectLockConfig(ctx, c.Bucket)
+		// Object locking is S3 specific.
+		s3cl, s3done := c.s3MetaClient()
+		_, _, _, err := s3cl.GetBucketObjectLockConfig(ctx, c.Bucket)
+		s3done()
 		if err != nil {
 			if !c.Clear {
 				return errors.New("not allowed to clear bucket to re-create bucket with locking")
@@ -169,15 +332,25 @@ func (c *Common) createEmptyBucket(ctx context.Context) error {
 
 // deleteAllInBucket will delete all content in a bucket.
 // If no prefixes are specified everything in bucket is deleted.
+// With a journal only unchanged objects created by warp are deleted.
 func (c *Common) deleteAllInBucket(ctx context.Context, prefixes ...string) {
 	if len(prefixes) == 0 {
 		prefixes = []string{""}
 	}
+	if c.Journal != nil {
+		kept := c.Journal.Kept()
+		defer func() {
+			if n := c.Journal.Kept() - kept; n > 0 {
+				console.Eraseline()
+				console.Infof("\rKept %d objects in %q not created by warp.\n", n, c.Bucket)
+			}
+		}()
+	}
 
 	doneCh := make(chan struct{})
 	defer close(doneCh)
 
-	cl, done := c.Client()
+	cl, done := c.metaClient()
 	defer done()
 
 	objectsCh := make(chan minio.ObjectInfo)
@@ -197,6 +370,9 @@ func (c *Common) deleteAllInBucket(ctx context.Context, prefixes ...string) {
 					c.Error(object.Err)
 					return
 				}
+				if c.Journal != nil && !c.Journal.Created(c.Bucket, object) {
+					continue
+				}
 				objectsCh <- object
 			}
 			console.Eraseline()
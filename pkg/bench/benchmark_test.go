@@ -0,0 +1,87 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/minio/warp/pkg/generator"
+)
+
+func TestThreadRngDeterministic(t *testing.T) {
+	c := Common{Deterministic: true, Seed: 42}
+	a, b := c.threadRng(0), c.threadRng(0)
+	for i := 0; i < 10; i++ {
+		if x, y := a.Int63(), b.Int63(); x != y {
+			t.Fatalf("step %d: got %d and %d", i, x, y)
+		}
+	}
+	if c.threadRng(0).Int63() == c.threadRng(1).Int63() {
+		t.Error("threads share the same sequence")
+	}
+}
+
+func TestIndexRng(t *testing.T) {
+	c := Common{}
+	if got, want := c.indexRng(3).Int63(), rand.New(rand.NewSource(3)).Int63(); got != want {
+		t.Errorf("got %d, want the sequence of the thread index %d", got, want)
+	}
+	c = Common{Deterministic: true, Seed: 42}
+	if got, want := c.indexRng(3).Int63(), c.threadRng(3).Int63(); got != want {
+		t.Errorf("got %d, want the sequence of the seed %d", got, want)
+	}
+}
+
+func TestMixedDistributionDeterministic(t *testing.T) {
+	// picks returns the objects picked after adding objects in the order given.
+	picks := func(order []int) []string {
+		m := MixedDistribution{
+			Distribution:  map[string]float64{"GET": 1},
+			Deterministic: true,
+			Seed:          42,
+		}
+		if err := m.Generate(len(order)); err != nil {
+			t.Fatal(err)
+		}
+		for _, i := range order {
+			m.addObj(generator.Object{Name: fmt.Sprint("obj-", i)})
+		}
+		var res []string
+		for i := 0; i < 20; i++ {
+			o, done := m.randomObj()
+			res = append(res, o.Name)
+			if i%3 == 0 {
+				res = append(res, m.deleteRandomObj().Name)
+			}
+			done()
+		}
+		if len(m.keys) != len(m.objects) {
+			t.Fatalf("%d keys for %d objects", len(m.keys), len(m.objects))
+		}
+		return res
+	}
+	order := rand.Perm(20)
+	a := picks(order)
+	rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	b := picks(order)
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Errorf("picks depend on the order objects were added:\n%v\n%v", a, b)
+	}
+}
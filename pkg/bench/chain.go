@@ -0,0 +1,311 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/generator"
+)
+
+// Chain modes.
+const (
+	// ChainListGet lists a prefix and downloads a sample of the listed objects.
+	ChainListGet = "list-get"
+	// ChainStatGet stats an object and downloads it if the ETag matches.
+	ChainStatGet = "stat-get"
+)
+
+// Chained operation types, from the start of the first request until the last has completed.
+const (
+	OpListGet = "LIST+GET"
+	OpStatGet = "STAT+GET"
+)
+
+// Chain benchmarks operations that depend on the result of a previous operation.
+// Each step is recorded as a separate operation as well as the full chain.
+type Chain struct {
+	CreateObjects int
+	Collector     *Collector
+	objects       generator.Objects
+
+	// Mode is ChainListGet or ChainStatGet.
+	Mode string
+
+	// Sample is the number of listed objects to download.
+	Sample int
+
+	Common
+}
+
+// OpType returns the operation type of the full chain.
+func (g *Chain) OpType() string {
+	if g.Mode == ChainStatGet {
+		return OpStatGet
+	}
+	return OpListGet
+}
+
+// Prepare will create an empty bucket or delete any content already there
+// and upload a number of objects.
+func (g *Chain) Prepare(ctx context.Context) error {
+	if err := g.createEmptyBucket(ctx); err != nil {
+		return err
+	}
+	console.Eraseline()
+	console.Info("\rUploading ", g.CreateObjects, " objects")
+
+	var wg sync.WaitGroup
+	wg.Add(g.Concurrency)
+	g.Collector = g.collector()
+	obj := make(chan struct{}, g.CreateObjects)
+	for i := 0; i < g.CreateObjects; i++ {
+		obj <- struct{}{}
+	}
+	rcv := g.Collector.rcv
+	close(obj)
+	var groupErr error
+	var mu sync.Mutex
+
+	for i := 0; i < g.Concurrency; i++ {
+		go func(i int) {
+			defer wg.Done()
+			src := g.Source()
+			opts := g.PutOpts
+
+			for range obj {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				obj := src.Object()
+				if obj == nil {
+					return
+				}
+				client, cldone := g.clientFor(obj.Name)
+				op := Operation{
+					OpType:   http.MethodPut,
+					Thread:   uint16(i),
+					Size:     obj.Size,
+					File:     obj.Name,
+					ObjPerOp: 1,
+					Endpoint: client.EndpointURL().String(),
+				}
+				setObjectOpts(&opts, obj)
+				track := g.trackManifest(obj)
+				g.waitOp(ctx, g.Bucket, obj.Name)
+				op.Start = time.Now()
+				res, err := client.PutObject(ctx, g.Bucket, obj.Name, obj.Reader, obj.Size, opts)
+				op.End = time.Now()
+				if err == nil && res.Size != obj.Size {
+					err = fmt.Errorf("short upload. want: %d, got %d", obj.Size, res.Size)
+				}
+				if err != nil {
+					err := fmt.Errorf("upload error: %w", err)
+					g.Error(err)
+					mu.Lock()
+					if groupErr == nil {
+						groupErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				cldone()
+				mu.Lock()
+				track(res)
+				obj.Reader = nil
+				g.objects = append(g.objects, *obj)
+				g.prepareProgress(float64(len(g.objects)) / float64(g.CreateObjects))
+				mu.Unlock()
+				rcv <- op
+			}
+		}(i)
+	}
+	wg.Wait()
+	return groupErr
+}
+
+// get downloads an object and returns the operation.
+func (g *Chain) get(ctx context.Context, client Backend, thread uint16, name string, opts minio.GetObjectOptions) Operation {
+	op := Operation{
+		OpType:   http.MethodGet,
+		Thread:   thread,
+		File:     name,
+		ObjPerOp: 1,
+		Endpoint: client.EndpointURL().String(),
+	}
+	g.waitOp(ctx, g.Bucket, name)
+	op.Start = time.Now()
+	fbr := firstByteRecorder{}
+	o, err := client.GetObject(ctx, g.Bucket, name, opts)
+	if err == nil {
+		fbr.r = o
+		op.Size, err = io.Copy(ioutil.Discard, &fbr)
+		o.Close()
+	}
+	op.FirstByte = fbr.t
+	op.End = time.Now()
+	if err != nil {
+		g.Error("download error: ", err)
+		op.Err = err.Error()
+	}
+	return op
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+func (g *Chain) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(g.Concurrency)
+	c := g.Collector
+	if g.AutoTermDur > 0 {
+		ctx = c.AutoTerm(ctx, g.OpType(), g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
+	}
+	prefixes := g.objects.Prefixes()
+
+	// Operations in progress when ctx is done can finish.
+	nonTerm := g.opsContext(ctx)
+
+	for i := 0; i < g.Concurrency; i++ {
+		go func(i int) {
+			rng := g.threadRng(i)
+			rcv := c.Receiver()
+			defer wg.Done()
+			opc := g.opContexts(nonTerm)
+			defer opc.stop()
+			done := ctx.Done()
+			getOpts := minio.GetObjectOptions{ServerSideEncryption: g.PutOpts.ServerSideEncryption}
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				meta, metaDone := g.metaClient()
+				client, cldone := g.anyClient()
+				chain := Operation{
+					OpType:   g.OpType(),
+					Thread:   uint16(i),
+					Endpoint: client.EndpointURL().String(),
+				}
+				g.waitOp(ctx, g.Bucket, "")
+				chain.Start = time.Now()
+				switch g.Mode {
+				case ChainStatGet:
+					obj := g.objects[rng.Intn(len(g.objects))]
+					chain.File = obj.Name
+					stat := Operation{
+						OpType:   "STAT",
+						Thread:   uint16(i),
+						File:     obj.Name,
+						ObjPerOp: 1,
+						Endpoint: meta.EndpointURL().String(),
+						Start:    chain.Start,
+					}
+					st, err := meta.StatObject(opc.next(), g.Bucket, obj.Name, minio.StatObjectOptions{ServerSideEncryption: g.PutOpts.ServerSideEncryption})
+					stat.End = time.Now()
+					if err != nil {
+						g.Error("stat error: ", err)
+						stat.Err = err.Error()
+						chain.Err = stat.Err
+						rcv <- stat
+						break
+					}
+					rcv <- stat
+					opts := getOpts
+					opts.SetMatchETag(st.ETag)
+					get := g.get(opc.next(), client, uint16(i), obj.Name, opts)
+					rcv <- get
+					chain.Size = get.Size
+					chain.ObjPerOp = 1
+					chain.Err = get.Err
+				default:
+					prefix := prefixes[rng.Intn(len(prefixes))]
+					if prefix != "" {
+						prefix += "/"
+					}
+					chain.File = prefix
+					list := Operation{
+						OpType:   "LIST",
+						Thread:   uint16(i),
+						File:     prefix,
+						Endpoint: meta.EndpointURL().String(),
+						Start:    chain.Start,
+					}
+					var keys []string
+					listCtx, cancel := context.WithCancel(opc.next())
+					for obj := range meta.ListObjects(listCtx, g.Bucket, minio.ListObjectsOptions{Prefix: prefix, MaxKeys: 1000}) {
+						if obj.Err != nil {
+							g.Error("list error: ", obj.Err)
+							list.Err = obj.Err.Error()
+							break
+						}
+						list.ObjPerOp++
+						keys = append(keys, obj.Key)
+						if list.ObjPerOp >= 1000 {
+							break
+						}
+					}
+					cancel()
+					list.End = time.Now()
+					rcv <- list
+					if list.Err != "" {
+						chain.Err = list.Err
+						break
+					}
+					rng.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+					if len(keys) > g.Sample {
+						keys = keys[:g.Sample]
+					}
+					for _, key := range keys {
+						get := g.get(opc.next(), client, uint16(i), key, getOpts)
+						rcv <- get
+						chain.Size += get.Size
+						chain.ObjPerOp++
+						if get.Err != "" {
+							chain.Err = get.Err
+							break
+						}
+					}
+				}
+				chain.End = time.Now()
+				metaDone()
+				cldone()
+				rcv <- chain
+			}
+		}(i)
+	}
+	wg.Wait()
+	return c.Close(), nil
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (g *Chain) Cleanup(ctx context.Context) {
+	g.deleteAllInBucket(ctx, g.objects.Prefixes()...)
+}
@@ -48,6 +48,8 @@ type CmpSegment struct {
 type CmpReqs struct {
 	CmpRequests
 	Before, After CmpRequests
+	// Percentiles replace P50 and P99 if set.
+	Percentiles []PercentileCmp
 }
 
 func (c *CmpReqs) Compare(before, after Operations) {
@@ -99,6 +101,26 @@ func (c *CmpReqs) String() string {
 	if c == nil {
 		return ""
 	}
+	if len(c.Percentiles) > 0 {
+		res := fmt.Sprintf("Avg: %s%v (%s%.f%%)",
+			plusPositiveD(c.Average),
+			c.Average.Round(time.Millisecond/20),
+			plusPositiveD(c.Average),
+			100*(float64(c.After.Average)-float64(c.Before.Average))/float64(c.Before.Average))
+		for _, p := range c.Percentiles {
+			res += ", " + p.String()
+		}
+		return res + fmt.Sprintf(", Best: %s%v (%s%.f%%), Worst: %s%v (%s%.f%%)",
+			plusPositiveD(c.Best),
+			c.Best,
+			plusPositiveD(c.Best),
+			100*(float64(c.After.Best)-float64(c.Before.Best))/float64(c.Before.Best),
+			plusPositiveD(c.Worst),
+			c.Worst,
+			plusPositiveD(c.Worst),
+			100*(float64(c.After.Worst)-float64(c.Before.Worst))/float64(c.Before.Worst),
+		)
+	}
 	return fmt.Sprintf("Avg: %s%v (%s%.f%%), P50: %s%v (%s%.f%%), P99: %s%v (%s%.f%%), Best: %s%v (%s%.f%%), Worst: %s%v (%s%.f%%)",
 		plusPositiveD(c.Average),
 		c.Average.Round(time.Millisecond/20),
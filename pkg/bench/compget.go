@@ -0,0 +1,236 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/generator"
+)
+
+// Operation types of CompGet downloads.
+const (
+	OpGetCompressible   = "GET-COMPRESSIBLE"
+	OpGetIncompressible = "GET-INCOMPRESSIBLE"
+)
+
+// CompGet benchmarks download speed of compressible vs incompressible objects.
+// Incompressible objects are created using Source,
+// compressible objects are created using CompSource.
+type CompGet struct {
+	CreateObjects int
+	CompSource    func() generator.Source
+	Collector     *Collector
+	objects       generator.Objects
+	compObjects   generator.Objects
+
+	// Default Get options.
+	GetOpts minio.GetObjectOptions
+	Common
+}
+
+// Prepare will create an empty bucket or delete any content already there
+// and upload CreateObjects of each type.
+func (g *CompGet) Prepare(ctx context.Context) error {
+	if err := g.createEmptyBucket(ctx); err != nil {
+		return err
+	}
+	console.Eraseline()
+	console.Info("\rUploading ", g.CreateObjects, " compressible and ", g.CreateObjects, " incompressible objects")
+
+	var wg sync.WaitGroup
+	wg.Add(g.Concurrency)
+	g.Collector = g.collector()
+	obj := make(chan bool, g.CreateObjects*2)
+	for i := 0; i < g.CreateObjects; i++ {
+		obj <- true
+		obj <- false
+	}
+	rcv := g.Collector.rcv
+	close(obj)
+	var groupErr error
+	var mu sync.Mutex
+
+	for i := 0; i < g.Concurrency; i++ {
+		go func(i int) {
+			defer wg.Done()
+			src := g.Source()
+			compSrc := g.CompSource()
+			opts := g.PutOpts
+
+			for compressible := range obj {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				obj := src.Object()
+				if obj == nil {
+					return
+				}
+				if compressible {
+					obj = compSrc.Object()
+					if obj == nil {
+						return
+					}
+				}
+				client, cldone := g.clientFor(obj.Name)
+				op := Operation{
+					OpType:   http.MethodPut,
+					Thread:   uint16(i),
+					Size:     obj.Size,
+					File:     obj.Name,
+					ObjPerOp: 1,
+					Endpoint: client.EndpointURL().String(),
+				}
+				// Use the same content type for both, so servers apply the same compression rules.
+				opts.ContentType = "text/plain"
+				track := g.trackManifest(obj)
+				g.waitOp(ctx, g.Bucket, obj.Name)
+				op.Start = time.Now()
+				res, err := client.PutObject(ctx, g.Bucket, obj.Name, obj.Reader, obj.Size, opts)
+				op.End = time.Now()
+				if err != nil {
+					err := fmt.Errorf("upload error: %w", err)
+					g.Error(err)
+					mu.Lock()
+					if groupErr == nil {
+						groupErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				if res.Size != obj.Size {
+					err := fmt.Errorf("short upload. want: %d, got %d", obj.Size, res.Size)
+					g.Error(err)
+					mu.Lock()
+					if groupErr == nil {
+						groupErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				cldone()
+				mu.Lock()
+				track(res)
+				obj.Reader = nil
+				if compressible {
+					g.compObjects = append(g.compObjects, *obj)
+				} else {
+					g.objects = append(g.objects, *obj)
+				}
+				g.prepareProgress(float64(len(g.objects)+len(g.compObjects)) / float64(g.CreateObjects*2))
+				mu.Unlock()
+				rcv <- op
+			}
+		}(i)
+	}
+	wg.Wait()
+	return groupErr
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+func (g *CompGet) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(g.Concurrency)
+	c := g.Collector
+	if g.AutoTermDur > 0 {
+		ctx = c.AutoTerm(ctx, OpGetCompressible, g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
+	}
+
+	// Operations in progress when ctx is done can finish.
+	nonTerm := g.opsContext(ctx)
+
+	for i := 0; i < g.Concurrency; i++ {
+		go func(i int) {
+			rng := g.indexRng(i)
+			rcv := c.Receiver()
+			defer wg.Done()
+			opc := g.opContexts(nonTerm)
+			defer opc.stop()
+			opts := g.GetOpts
+			done := ctx.Done()
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				fbr := firstByteRecorder{}
+				objs, opType := g.objects, OpGetIncompressible
+				if rng.Intn(2) == 0 {
+					objs, opType = g.compObjects, OpGetCompressible
+				}
+				obj := objs[rng.Intn(len(objs))]
+				client, cldone := g.clientFor(obj.Name)
+				op := Operation{
+					OpType:   opType,
+					Thread:   uint16(i),
+					Size:     obj.Size,
+					File:     obj.Name,
+					ObjPerOp: 1,
+					Endpoint: client.EndpointURL().String(),
+				}
+				g.waitOp(ctx, g.Bucket, obj.Name)
+				op.Start = time.Now()
+				o, err := client.GetObject(opc.next(), g.Bucket, obj.Name, opts)
+				if err != nil {
+					g.Error("download error:", err)
+					op.Err = err.Error()
+					op.End = time.Now()
+					rcv <- op
+					cldone()
+					continue
+				}
+				fbr.r = o
+				n, err := io.Copy(ioutil.Discard, &fbr)
+				if err != nil {
+					g.Error("download error:", err)
+					op.Err = err.Error()
+				}
+				op.FirstByte = fbr.t
+				op.End = time.Now()
+				if n != op.Size && op.Err == "" {
+					op.Err = fmt.Sprint("unexpected download size. want:", op.Size, ", got:", n)
+					g.Error(op.Err)
+				}
+				rcv <- op
+				cldone()
+				o.Close()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return c.Close(), nil
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (g *CompGet) Cleanup(ctx context.Context) {
+	g.deleteAllInBucket(ctx, generator.MergeObjectPrefixes([]generator.Objects{g.objects, g.compObjects})...)
+}
@@ -51,7 +51,7 @@ func (d *Delete) Prepare(ctx context.Context) error {
 	console.Info("\rUploading ", d.CreateObjects, " objects of ", src.String())
 	var wg sync.WaitGroup
 	wg.Add(d.Concurrency)
-	d.Collector = NewCollector()
+	d.Collector = d.collector()
 	obj := make(chan struct{}, d.CreateObjects)
 	for i := 0; i < d.CreateObjects; i++ {
 		obj <- struct{}{}
@@ -74,7 +74,10 @@ func (d *Delete) Prepare(ctx context.Context) error {
 				default:
 				}
 				obj := src.Object()
-				client, cldone := d.Client()
+				if obj == nil {
+					return
+				}
+				client, cldone := d.clientFor(obj.Name)
 				op := Operation{
 					OpType:   http.MethodPut,
 					Thread:   uint16(i),
@@ -83,7 +86,9 @@ func (d *Delete) Prepare(ctx context.Context) error {
 					ObjPerOp: 1,
 					Endpoint: client.EndpointURL().String(),
 				}
-				opts.ContentType = obj.ContentType
+				setObjectOpts(&opts, obj)
+				track := d.trackManifest(obj)
+				d.waitOp(ctx, d.Bucket, obj.Name)
 				op.Start = time.Now()
 				res, err := client.PutObject(ctx, d.Bucket, obj.Name, obj.Reader, obj.Size, opts)
 				op.End = time.Now()
@@ -111,6 +116,7 @@ func (d *Delete) Prepare(ctx context.Context) error {
 				}
 				cldone()
 				mu.Lock()
+				track(res)
 				obj.Reader = nil
 				d.objects = append(d.objects, *obj)
 				d.prepareProgress(float64(len(d.objects)) / float64(d.CreateObjects))
@@ -139,14 +145,16 @@ func (d *Delete) Start(ctx context.Context, wait chan struct{}) (Operations, err
 	if d.AutoTermDur > 0 {
 		ctx = c.AutoTerm(ctx, http.MethodDelete, d.AutoTermScale, autoTermCheck, autoTermSamples, d.AutoTermDur)
 	}
-	// Non-terminating context.
-	nonTerm := context.Background()
+	// Operations in progress when ctx is done can finish.
+	nonTerm := d.opsContext(ctx)
 
 	var mu sync.Mutex
 	for i := 0; i < d.Concurrency; i++ {
 		go func(i int) {
 			rcv := c.Receiver()
 			defer wg.Done()
+			opc := d.opContexts(nonTerm)
+			defer opc.stop()
 			done := ctx.Done()
 
 			<-wait
@@ -177,7 +185,7 @@ func (d *Delete) Start(ctx context.Context, wait chan struct{}) (Operations, err
 				}
 				close(objects)
 
-				client, cldone := d.Client()
+				client, cldone := d.anyClient()
 				op := Operation{
 					OpType:   http.MethodDelete,
 					Thread:   uint16(i),
@@ -186,9 +194,11 @@ func (d *Delete) Start(ctx context.Context, wait chan struct{}) (Operations, err
 					ObjPerOp: len(objs),
 					Endpoint: client.EndpointURL().String(),
 				}
+				tctx, lt := d.traceLatency(opc.next())
+				d.waitOp(ctx, d.Bucket, "")
 				op.Start = time.Now()
 				// RemoveObjectsWithContext will split any batches > 1000 into separate requests.
-				errCh := client.RemoveObjects(nonTerm, d.Bucket, objects, minio.RemoveObjectsOptions{})
+				errCh := client.RemoveObjects(tctx, d.Bucket, objects, minio.RemoveObjectsOptions{})
 
 				// Wait for errCh to close.
 				for {
@@ -202,6 +212,7 @@ func (d *Delete) Start(ctx context.Context, wait chan struct{}) (Operations, err
 					}
 				}
 				op.End = time.Now()
+				lt.record(&op)
 				cldone()
 				rcv <- op
 			}
@@ -22,7 +22,6 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"math/rand"
 	"net/http"
 	"sync"
 	"time"
@@ -42,6 +41,13 @@ type Get struct {
 
 	// Default Get options.
 	GetOpts minio.GetObjectOptions
+	// Ranges control the ranges requested with RandomRanges.
+	Ranges RangeOptions
+	// VerifySeed will compare downloaded content to generator.NamedContent with this seed, if set.
+	VerifySeed *int64
+	// Decrypt will decrypt downloaded objects encrypted on the client, if set.
+	// Ranges are downloaded without decryption.
+	Decrypt *generator.ClientEncryption
 	Common
 }
 
@@ -52,7 +58,7 @@ func (g *Get) Prepare(ctx context.Context) error {
 		return err
 	}
 	if g.Versions > 1 {
-		cl, done := g.Client()
+		cl, done := g.metaClient()
 		if !g.Versioned {
 			err := cl.EnableVersioning(ctx, g.Bucket)
 			if err != nil {
@@ -71,7 +77,7 @@ func (g *Get) Prepare(ctx context.Context) error {
 
 	var wg sync.WaitGroup
 	wg.Add(g.Concurrency)
-	g.Collector = NewCollector()
+	g.Collector = g.collector()
 	obj := make(chan struct{}, g.CreateObjects)
 	for i := 0; i < g.CreateObjects; i++ {
 		obj <- struct{}{}
@@ -94,13 +100,22 @@ func (g *Get) Prepare(ctx context.Context) error {
 				default:
 				}
 				obj := src.Object()
+				if obj == nil {
+					return
+				}
 
 				name := obj.Name
 				for ver := 0; ver < g.Versions; ver++ {
-					// New input for each version
-					obj := src.Object()
-					obj.Name = name
-					client, cldone := g.Client()
+					obj := obj
+					if ver > 0 {
+						// New input for each version
+						obj = src.Object()
+						if obj == nil {
+							return
+						}
+						obj.Name = name
+					}
+					client, cldone := g.clientFor(obj.Name)
 					op := Operation{
 						OpType:   http.MethodPut,
 						Thread:   uint16(i),
@@ -109,7 +124,9 @@ func (g *Get) Prepare(ctx context.Context) error {
 						ObjPerOp: 1,
 						Endpoint: client.EndpointURL().String(),
 					}
-					opts.ContentType = obj.ContentType
+					setObjectOpts(&opts, obj)
+					track := g.trackManifest(obj)
+					g.waitOp(ctx, g.Bucket, obj.Name)
 					op.Start = time.Now()
 					res, err := client.PutObject(ctx, g.Bucket, obj.Name, obj.Reader, obj.Size, opts)
 					op.End = time.Now()
@@ -134,8 +151,10 @@ func (g *Get) Prepare(ctx context.Context) error {
 						mu.Unlock()
 						return
 					}
+					op.Size = uploadedSize(obj, res.Size)
 					cldone()
 					mu.Lock()
+					track(res)
 					obj.Reader = nil
 					g.objects = append(g.objects, *obj)
 					g.prepareProgress(float64(len(g.objects)) / float64(g.CreateObjects*g.Versions))
@@ -167,6 +186,66 @@ func (f *firstByteRecorder) Read(p []byte) (n int, err error) {
 	return n, err
 }
 
+// ErrContentMismatch starts the error of downloads with content that does not match the expected content.
+const ErrContentMismatch = "content mismatch"
+
+// contentVerifier compares written data to the expected content.
+type contentVerifier struct {
+	want   io.Reader
+	buf    []byte
+	offset int64
+	// mismatch is the offset of the first difference, or -1.
+	mismatch int64
+}
+
+func newContentVerifier(want io.ReadSeeker, offset int64) (*contentVerifier, error) {
+	if _, err := want.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return &contentVerifier{want: want, offset: offset, mismatch: -1}, nil
+}
+
+func (v *contentVerifier) Write(p []byte) (int, error) {
+	if v.mismatch < 0 {
+		if cap(v.buf) < len(p) {
+			v.buf = make([]byte, len(p))
+		}
+		want := v.buf[:len(p)]
+		n, _ := io.ReadFull(v.want, want)
+		for i := range p {
+			if i >= n || p[i] != want[i] {
+				v.mismatch = v.offset + int64(i)
+				break
+			}
+		}
+	}
+	v.offset += int64(len(p))
+	return len(p), nil
+}
+
+// decrypt writes the decrypted content of the object read from r to dst.
+// The number of bytes read from r is returned.
+func (g *Get) decrypt(dst io.Writer, r io.Reader) (int64, error) {
+	cr := countingReader{r: r}
+	dec, err := g.Decrypt.Decrypt(&cr)
+	if err == nil {
+		_, err = io.Copy(dst, dec)
+	}
+	return cr.n, err
+}
+
+// countingReader counts the bytes read.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
 // Start will execute the main benchmark.
 // Operations should begin executing when the start channel is closed.
 func (g *Get) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
@@ -177,16 +256,19 @@ func (g *Get) Start(ctx context.Context, wait chan struct{}) (Operations, error)
 		ctx = c.AutoTerm(ctx, http.MethodGet, g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
 	}
 
-	// Non-terminating context.
-	nonTerm := context.Background()
+	// Operations in progress when ctx is done can finish.
+	nonTerm := g.opsContext(ctx)
 
 	for i := 0; i < g.Concurrency; i++ {
 		go func(i int) {
-			rng := rand.New(rand.NewSource(int64(i)))
+			rng := g.indexRng(i)
 			rcv := c.Receiver()
 			defer wg.Done()
+			opc := g.opContexts(nonTerm)
+			defer opc.stop()
 			opts := g.GetOpts
 			done := ctx.Done()
+			var cursor rangeCursor
 
 			<-wait
 			for {
@@ -196,8 +278,17 @@ func (g *Get) Start(ctx context.Context, wait chan struct{}) (Operations, error)
 				default:
 				}
 				fbr := firstByteRecorder{}
-				obj := g.objects[rng.Intn(len(g.objects))]
-				client, cldone := g.Client()
+				var obj generator.Object
+				var start, end int64
+				ranged := false
+				if g.RandomRanges {
+					var idx int
+					idx, start, end, ranged = g.Ranges.next(rng, &cursor, g.objects)
+					obj = g.objects[idx]
+				} else {
+					obj = g.objects[rng.Intn(len(g.objects))]
+				}
+				client, cldone := g.clientFor(obj.Name)
 				op := Operation{
 					OpType:   http.MethodGet,
 					Thread:   uint16(i),
@@ -206,40 +297,65 @@ func (g *Get) Start(ctx context.Context, wait chan struct{}) (Operations, error)
 					ObjPerOp: 1,
 					Endpoint: client.EndpointURL().String(),
 				}
-				if g.RandomRanges && op.Size > 2 {
-					// Randomize length similar to --obj.randsize
-					size := generator.GetExpRandSize(rng, op.Size-2)
-					start := rng.Int63n(op.Size - size)
-					end := start + size
+				if ranged {
 					op.Size = end - start + 1
 					opts.SetRange(start, end)
 				}
+				g.waitOp(ctx, g.Bucket, obj.Name)
 				op.Start = time.Now()
 				var err error
 				if g.Versions > 1 {
 					opts.VersionID = obj.VersionID
 				}
-				o, err := client.GetObject(nonTerm, g.Bucket, obj.Name, opts)
+				tctx, lt := g.traceLatency(opc.next())
+				o, err := client.GetObject(tctx, g.Bucket, obj.Name, opts)
 				if err != nil {
 					g.Error("download error:", err)
 					op.Err = err.Error()
 					op.End = time.Now()
+					lt.record(&op)
 					rcv <- op
 					cldone()
 					continue
 				}
 				fbr.r = o
-				n, err := io.Copy(ioutil.Discard, &fbr)
+				var dst io.Writer = ioutil.Discard
+				var verifier *contentVerifier
+				if g.VerifySeed != nil {
+					size := obj.Size
+					if g.Decrypt != nil {
+						size = g.Decrypt.PlainSize(size)
+					}
+					verifier, err = newContentVerifier(generator.NamedContent(*g.VerifySeed, obj.Name, size), start)
+					if err == nil {
+						dst = verifier
+					}
+				}
+				var n int64
+				if g.Decrypt != nil && !ranged {
+					n, err = g.decrypt(dst, &fbr)
+				} else {
+					n, err = io.Copy(dst, &fbr)
+				}
 				if err != nil {
 					g.Error("download error:", err)
 					op.Err = err.Error()
 				}
 				op.FirstByte = fbr.t
 				op.End = time.Now()
+				lt.record(&op)
 				if n != op.Size && op.Err == "" {
 					op.Err = fmt.Sprint("unexpected download size. want:", op.Size, ", got:", n)
 					g.Error(op.Err)
 				}
+				if !ranged {
+					// Count encoded objects with their size before compression, like uploads.
+					op.Size = uploadedSize(&obj, op.Size)
+				}
+				if verifier != nil && verifier.mismatch >= 0 && op.Err == "" {
+					op.Err = fmt.Sprint(ErrContentMismatch+" at offset ", verifier.mismatch)
+					g.Error(op.Err)
+				}
 				rcv <- op
 				cldone()
 				o.Close()
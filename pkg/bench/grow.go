@@ -0,0 +1,233 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// GrowCheckpoint is a namespace size where metadata performance is measured.
+type GrowCheckpoint struct {
+	// Objects is the number of objects uploaded before measuring.
+	Objects int64
+	// Label is used for operation types.
+	Label string
+}
+
+// Grow benchmarks metadata performance as the namespace grows.
+// Objects are only added, and listing and stat latency is measured
+// when the number of objects reaches each checkpoint.
+type Grow struct {
+	// Checkpoints in increasing order.
+	Checkpoints []GrowCheckpoint
+
+	// StatSamples and ListSamples are the number of operations at each checkpoint.
+	StatSamples, ListSamples int
+
+	prefixes map[string]struct{}
+	Common
+}
+
+// growSampleSize is the number of object names kept for stat operations.
+const growSampleSize = 10000
+
+// Prepare will create an empty bucket ot delete any content already there.
+func (g *Grow) Prepare(ctx context.Context) error {
+	return g.createEmptyBucket(ctx)
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+// The benchmark stops when the last checkpoint has been measured.
+func (g *Grow) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(g.Concurrency)
+	c := g.collector()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	g.prefixes = make(map[string]struct{}, g.Concurrency)
+	var prefixes []string
+
+	// Operations in progress when ctx is done can finish.
+	nonTerm := g.opsContext(ctx)
+
+	var uploaded int64
+	var nextCheckpoint int32
+	var mu sync.Mutex
+	names := make([]string, 0, growSampleSize)
+	sampleRng := g.threadRng(-1)
+
+	measure := func(cp GrowCheckpoint, thread uint16, rng *rand.Rand) {
+		rcv := c.Receiver()
+		opc := g.opContexts(nonTerm)
+		defer opc.stop()
+		for n := 0; n < g.StatSamples; n++ {
+			mu.Lock()
+			name := names[rng.Intn(len(names))]
+			mu.Unlock()
+			client, cldone := g.metaClient()
+			op := Operation{
+				OpType:   "STAT@" + cp.Label,
+				Thread:   thread,
+				File:     name,
+				ObjPerOp: 1,
+				Endpoint: client.EndpointURL().String(),
+			}
+			g.waitOp(ctx, g.Bucket, name)
+			op.Start = time.Now()
+			_, err := client.StatObject(opc.next(), g.Bucket, name, minio.StatObjectOptions{ServerSideEncryption: g.PutOpts.ServerSideEncryption})
+			op.End = time.Now()
+			if err != nil {
+				g.Error("stat error: ", err)
+				op.Err = err.Error()
+			}
+			cldone()
+			rcv <- op
+		}
+		for n := 0; n < g.ListSamples; n++ {
+			prefix := prefixes[rng.Intn(len(prefixes))]
+			if prefix != "" {
+				prefix += "/"
+			}
+			client, cldone := g.metaClient()
+			op := Operation{
+				OpType:   "LIST@" + cp.Label,
+				Thread:   thread,
+				File:     prefix,
+				Endpoint: client.EndpointURL().String(),
+			}
+			listCtx, listCancel := context.WithCancel(opc.next())
+			g.waitOp(ctx, g.Bucket, "")
+			op.Start = time.Now()
+			for obj := range client.ListObjects(listCtx, g.Bucket, minio.ListObjectsOptions{Prefix: prefix, MaxKeys: 1000}) {
+				if obj.Err != nil {
+					g.Error("list error: ", obj.Err)
+					op.Err = obj.Err.Error()
+					break
+				}
+				op.ObjPerOp++
+				if op.ObjPerOp >= 1000 {
+					break
+				}
+			}
+			op.End = time.Now()
+			listCancel()
+			cldone()
+			rcv <- op
+		}
+	}
+
+	for i := 0; i < g.Concurrency; i++ {
+		src := g.Source()
+		g.prefixes[src.Prefix()] = struct{}{}
+		prefixes = append(prefixes, src.Prefix())
+		go func(i int) {
+			rng := g.threadRng(i)
+			rcv := c.Receiver()
+			defer wg.Done()
+			opc := g.opContexts(nonTerm)
+			defer opc.stop()
+			opts := g.PutOpts
+			done := ctx.Done()
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				obj := src.Object()
+				if obj == nil {
+					return
+				}
+				setObjectOpts(&opts, obj)
+				client, cldone := g.clientFor(obj.Name)
+				op := Operation{
+					OpType:   http.MethodPut,
+					Thread:   uint16(i),
+					Size:     obj.Size,
+					File:     obj.Name,
+					ObjPerOp: 1,
+					Endpoint: client.EndpointURL().String(),
+				}
+				g.waitOp(ctx, g.Bucket, obj.Name)
+				op.Start = time.Now()
+				res, err := client.PutObject(opc.next(), g.Bucket, obj.Name, obj.Reader, obj.Size, opts)
+				op.End = time.Now()
+				if err != nil {
+					g.Error("upload error: ", err)
+					op.Err = err.Error()
+				}
+				if res.Size != obj.Size && op.Err == "" {
+					err := fmt.Sprint("short upload. want:", obj.Size, ", got:", res.Size)
+					op.Err = err
+					g.Error(err)
+				}
+				op.Size = uploadedSize(obj, res.Size)
+				cldone()
+				rcv <- op
+				if op.Err != "" {
+					continue
+				}
+
+				// Keep a uniform sample of uploaded names.
+				n := atomic.AddInt64(&uploaded, 1)
+				mu.Lock()
+				if len(names) < growSampleSize {
+					names = append(names, obj.Name)
+				} else if idx := sampleRng.Int63n(n); idx < growSampleSize {
+					names[idx] = obj.Name
+				}
+				mu.Unlock()
+
+				cpIdx := int(atomic.LoadInt32(&nextCheckpoint))
+				if cpIdx >= len(g.Checkpoints) || n < g.Checkpoints[cpIdx].Objects {
+					continue
+				}
+				// Only the writer reaching the checkpoint measures.
+				if !atomic.CompareAndSwapInt32(&nextCheckpoint, int32(cpIdx), int32(cpIdx+1)) {
+					continue
+				}
+				measure(g.Checkpoints[cpIdx], uint16(i), rng)
+				if cpIdx == len(g.Checkpoints)-1 {
+					cancel()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	return c.Close(), nil
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (g *Grow) Cleanup(ctx context.Context) {
+	var pf []string
+	for p := range g.prefixes {
+		pf = append(pf, p)
+	}
+	g.deleteAllInBucket(ctx, pf...)
+}
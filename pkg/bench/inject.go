@@ -0,0 +1,291 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/s3utils"
+	"github.com/minio/warp/pkg/generator"
+)
+
+// Malformed request types that can be injected.
+const (
+	// InjectSignature sends uploads signed with invalid credentials.
+	InjectSignature = "signature"
+	// InjectPartNumber uploads multipart parts with part numbers outside 1-10000.
+	InjectPartNumber = "partnumber"
+	// InjectLongKey uploads objects with keys longer than 1024 bytes.
+	InjectLongKey = "longkey"
+)
+
+// InjectKinds contains all malformed request types.
+var InjectKinds = []string{InjectSignature, InjectPartNumber, InjectLongKey}
+
+// injectOpPrefix is the operation type prefix of malformed requests.
+const injectOpPrefix = "INJECT-"
+
+// Inject benchmarks uploads while sending malformed requests.
+// Malformed requests are expected to fail,
+// so an error is only recorded if the server accepts them.
+type Inject struct {
+	Common
+
+	// Rate is the fraction of requests that are malformed.
+	Rate float64
+
+	// Kinds of malformed requests to send.
+	Kinds []string
+
+	// BadClient returns a client with invalid credentials.
+	// Must be set if InjectSignature is requested.
+	BadClient func() (cl *minio.Client, done func())
+
+	// AccessKey, SecretKey and Region sign the malformed requests the client refuses to send.
+	// They are sent with Transport to the host of the client.
+	// Must be set if InjectPartNumber or InjectLongKey is requested.
+	AccessKey, SecretKey, Region string
+	Transport                    http.RoundTripper
+
+	prefixes map[string]struct{}
+}
+
+// Prepare will create an empty bucket ot delete any content already there.
+func (u *Inject) Prepare(ctx context.Context) error {
+	return u.createEmptyBucket(ctx)
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+func (u *Inject) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(u.Concurrency)
+	c := u.collector()
+	if u.AutoTermDur > 0 {
+		ctx = c.AutoTerm(ctx, http.MethodPut, u.AutoTermScale, autoTermCheck, autoTermSamples, u.AutoTermDur)
+	}
+	u.prefixes = make(map[string]struct{}, u.Concurrency)
+
+	// Operations in progress when ctx is done can finish.
+	nonTerm := u.opsContext(ctx)
+
+	for i := 0; i < u.Concurrency; i++ {
+		src := u.Source()
+		u.prefixes[src.Prefix()] = struct{}{}
+		go func(i int) {
+			rng := u.threadRng(i)
+			rcv := c.Receiver()
+			defer wg.Done()
+			opc := u.opContexts(nonTerm)
+			defer opc.stop()
+			opts := u.PutOpts
+			done := ctx.Done()
+
+			// Upload used for invalid part numbers.
+			var uploadID, uploadName string
+			defer func() {
+				if uploadID == "" {
+					return
+				}
+				client, cldone := u.s3ClientFor(uploadName)
+				defer cldone()
+				core := minio.Core{Client: client}
+				err := core.AbortMultipartUpload(nonTerm, u.Bucket, uploadName, uploadID)
+				if err != nil {
+					u.Error("abort multipart upload error: ", err)
+				}
+			}()
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				obj := src.Object()
+				if obj == nil {
+					return
+				}
+				setObjectOpts(&opts, obj)
+				kind := ""
+				if len(u.Kinds) > 0 && rng.Float64() < u.Rate {
+					kind = u.Kinds[rng.Intn(len(u.Kinds))]
+				}
+				if kind == "" {
+					client, cldone := u.s3ClientFor(obj.Name)
+					op := Operation{
+						OpType:   http.MethodPut,
+						Thread:   uint16(i),
+						Size:     obj.Size,
+						File:     obj.Name,
+						ObjPerOp: 1,
+						Endpoint: client.EndpointURL().String(),
+					}
+					// Uploads are made while benchmarking, so they are not counted as prepared.
+					record := u.hashManifest(obj)
+					u.waitOp(ctx, u.Bucket, obj.Name)
+					op.Start = time.Now()
+					res, err := client.PutObject(opc.next(), u.Bucket, obj.Name, obj.Reader, obj.Size, opts)
+					op.End = time.Now()
+					if err != nil {
+						u.Error("upload error: ", err)
+						op.Err = err.Error()
+					}
+					if res.Size != obj.Size && op.Err == "" {
+						err := fmt.Sprint("short upload. want:", obj.Size, ", got:", res.Size)
+						op.Err = err
+						u.Error(err)
+					}
+					if op.Err == "" {
+						record(res)
+					}
+					op.Size = uploadedSize(obj, res.Size)
+					cldone()
+					rcv <- op
+					continue
+				}
+
+				getClient := u.Client
+				if kind == InjectSignature {
+					getClient = u.BadClient
+				}
+				client, cldone := getClient()
+				op := Operation{
+					OpType:   injectOpPrefix + strings.ToUpper(kind),
+					Thread:   uint16(i),
+					Size:     obj.Size,
+					File:     obj.Name,
+					ObjPerOp: 1,
+					Endpoint: client.EndpointURL().String(),
+				}
+				var err error
+				switch kind {
+				case InjectSignature:
+					u.waitOp(ctx, u.Bucket, obj.Name)
+					op.Start = time.Now()
+					_, err = client.PutObject(opc.next(), u.Bucket, obj.Name, obj.Reader, obj.Size, opts)
+					op.End = time.Now()
+				case InjectLongKey:
+					// Keep the prefix, so cleanup will catch accepted objects.
+					op.File = obj.Name + "." + strings.Repeat("k", 1025)
+					u.waitOp(ctx, u.Bucket, op.File)
+					op.Start = time.Now()
+					err = u.rawPut(opc.next(), client, op.File, nil, obj)
+					op.End = time.Now()
+				case InjectPartNumber:
+					core := minio.Core{Client: client}
+					if uploadID == "" {
+						uploadName = obj.Name
+						uploadID, err = core.NewMultipartUpload(opc.next(), u.Bucket, uploadName, opts)
+						if err != nil {
+							u.Error("new multipart upload error: ", err)
+							uploadID = ""
+							cldone()
+							continue
+						}
+					}
+					partN := 0
+					if rng.Intn(2) == 0 {
+						partN = 10001
+					}
+					op.File = uploadName
+					q := url.Values{"partNumber": {strconv.Itoa(partN)}, "uploadId": {uploadID}}
+					u.waitOp(ctx, u.Bucket, uploadName)
+					op.Start = time.Now()
+					err = u.rawPut(opc.next(), client, uploadName, q, obj)
+					op.End = time.Now()
+				}
+				cldone()
+				var rerr rawRequestError
+				switch {
+				case errors.As(err, &rerr):
+					u.Error("malformed request error: ", err)
+					op.Err = err.Error()
+				case err == nil:
+					op.Err = fmt.Sprintf("server accepted malformed request (%s)", kind)
+					u.Error(op.Err)
+				}
+				rcv <- op
+			}
+		}(i)
+	}
+	wg.Wait()
+	return c.Close(), nil
+}
+
+// rawRequestError is an error sending a malformed request,
+// as opposed to the server rejecting it.
+type rawRequestError struct {
+	err error
+}
+
+func (e rawRequestError) Error() string {
+	return e.err.Error()
+}
+
+// rawPut uploads obj to key with the query to the host of the client.
+// The request is signed and sent without the client,
+// since the client refuses to send requests it considers invalid.
+// An error is returned if the server rejects the request.
+func (u *Inject) rawPut(ctx context.Context, client *minio.Client, key string, query url.Values, obj *generator.Object) error {
+	p := "/" + u.Bucket + "/" + key
+	target := *client.EndpointURL()
+	target.Path, target.RawPath = p, s3utils.EncodePath(p)
+	target.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), obj.Reader)
+	if err != nil {
+		return rawRequestError{err: err}
+	}
+	req.ContentLength = obj.Size
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	region := u.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	signV4(req, u.AccessKey, u.SecretKey, region, "s3", time.Now())
+	resp, err := u.Transport.RoundTrip(req)
+	if err != nil {
+		return rawRequestError{err: err}
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (u *Inject) Cleanup(ctx context.Context) {
+	var pf []string
+	for p := range u.prefixes {
+		pf = append(pf, p)
+	}
+	u.deleteAllInBucket(ctx, pf...)
+}
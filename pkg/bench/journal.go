@@ -0,0 +1,302 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// journalMaxBody is the maximum size of response bodies read for object details.
+const journalMaxBody = 16 << 20
+
+// journalCSVHeader is the header of journal files.
+var journalCSVHeader = []string{"bucket", "key", "version_id", "etag"}
+
+// Journal records every object created by warp to a file,
+// so cleanup can be restricted to exactly those objects.
+// Objects are recorded by the transport returned by Transport
+// when the server confirms they were created, including delete markers.
+// Entries of earlier runs are loaded when the journal is opened.
+type Journal struct {
+	mu      sync.Mutex
+	f       *os.File
+	w       *csv.Writer
+	objects map[journalID]string
+	kept    int
+	err     error
+}
+
+type journalID struct {
+	bucket, key, version string
+}
+
+// OpenJournal reads the journal file and opens it for appending, creating it if needed.
+func OpenJournal(fn string) (*Journal, error) {
+	j := Journal{objects: make(map[journalID]string)}
+	if f, err := os.Open(fn); err == nil {
+		err = j.read(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	f, err := os.OpenFile(fn, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	j.f = f
+	j.w = csv.NewWriter(f)
+	if st, err := f.Stat(); err != nil || st.Size() == 0 {
+		if err := j.write(journalCSVHeader); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return &j, nil
+}
+
+// read entries written by earlier runs.
+func (j *Journal) read(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(journalCSVHeader)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if header[0] != journalCSVHeader[0] || header[1] != journalCSVHeader[1] {
+		return errors.New("unknown journal header")
+	}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		j.objects[journalID{bucket: rec[0], key: rec[1], version: rec[2]}] = rec[3]
+	}
+}
+
+// write a record and flush it to the file.
+func (j *Journal) write(rec []string) error {
+	if err := j.w.Write(rec); err != nil {
+		return err
+	}
+	j.w.Flush()
+	return j.w.Error()
+}
+
+// Add an object created in the bucket.
+// An empty etag matches any content.
+func (j *Journal) Add(bucket, key, version, etag string) {
+	if version == "null" {
+		version = ""
+	}
+	etag = strings.Trim(etag, `"`)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.objects[journalID{bucket: bucket, key: key, version: version}] = etag
+	if err := j.write([]string{bucket, key, version, etag}); err != nil && j.err == nil {
+		j.err = err
+	}
+}
+
+// Created returns whether the listed object was created by warp and is unchanged.
+// Objects that were not are counted as kept.
+func (j *Journal) Created(bucket string, obj minio.ObjectInfo) bool {
+	version := obj.VersionID
+	if version == "null" {
+		version = ""
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	etag, ok := j.objects[journalID{bucket: bucket, key: obj.Key, version: version}]
+	if ok && (etag == "" || etag == strings.Trim(obj.ETag, `"`)) {
+		return true
+	}
+	j.kept++
+	return false
+}
+
+// Recorded returns whether any version of the key was recorded in the bucket.
+func (j *Journal) Recorded(bucket, key string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for id := range j.objects {
+		if id.bucket == bucket && id.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Kept returns the number of listed objects that were not deleted,
+// because they were not created by warp or have been changed since.
+func (j *Journal) Kept() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.kept
+}
+
+// Len returns the number of objects recorded.
+func (j *Journal) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.objects)
+}
+
+// Err returns the first error writing entries, if any.
+func (j *Journal) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+// Close the journal file.
+// The first error writing entries is returned.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	err := j.f.Close()
+	if j.err != nil {
+		return j.err
+	}
+	return err
+}
+
+// Transport returns a transport that records objects created by requests sent with tr.
+// host is the endpoint of the client, used to find the bucket of virtual host style requests.
+func (j *Journal) Transport(tr http.RoundTripper, host string) http.RoundTripper {
+	return journalTransport{j: j, tr: tr, host: host}
+}
+
+type journalTransport struct {
+	j    *Journal
+	tr   http.RoundTripper
+	host string
+}
+
+func (t journalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.tr.RoundTrip(req)
+	if err != nil || resp.StatusCode/100 != 2 {
+		return resp, err
+	}
+	bucket, key := t.objectOf(req)
+	if bucket == "" {
+		return resp, nil
+	}
+	q := req.URL.Query()
+	version := resp.Header.Get("X-Amz-Version-Id")
+	switch {
+	case req.Method == http.MethodPut && key != "" && len(q) == 0:
+		// Uploads and copies.
+		etag := resp.Header.Get("ETag")
+		if etag == "" && req.Header.Get("X-Amz-Copy-Source") != "" {
+			var res struct {
+				ETag string `xml:"ETag"`
+			}
+			if err := readJournalBody(resp, &res); err != nil {
+				return nil, err
+			}
+			etag = res.ETag
+		}
+		t.j.Add(bucket, key, version, etag)
+	case req.Method == http.MethodPost && key != "" && q.Has("uploadId"):
+		// Completed multipart uploads. Errors can be returned with status 200.
+		var res struct {
+			XMLName xml.Name
+			ETag    string `xml:"ETag"`
+		}
+		if err := readJournalBody(resp, &res); err != nil {
+			return nil, err
+		}
+		if res.XMLName.Local == "CompleteMultipartUploadResult" {
+			t.j.Add(bucket, key, version, res.ETag)
+		}
+	case req.Method == http.MethodDelete && key != "" && len(q) == 0:
+		if resp.Header.Get("X-Amz-Delete-Marker") == "true" && version != "" {
+			t.j.Add(bucket, key, version, "")
+		}
+	case req.Method == http.MethodPost && key == "" && q.Has("delete"):
+		var res struct {
+			Deleted []struct {
+				Key                   string `xml:"Key"`
+				DeleteMarker          bool   `xml:"DeleteMarker"`
+				DeleteMarkerVersionID string `xml:"DeleteMarkerVersionId"`
+			} `xml:"Deleted"`
+		}
+		if err := readJournalBody(resp, &res); err != nil {
+			return nil, err
+		}
+		for _, d := range res.Deleted {
+			if d.DeleteMarker && d.DeleteMarkerVersionID != "" {
+				t.j.Add(bucket, d.Key, d.DeleteMarkerVersionID, "")
+			}
+		}
+	}
+	return resp, nil
+}
+
+// objectOf returns the bucket and key of the request.
+func (t journalTransport) objectOf(req *http.Request) (bucket, key string) {
+	p := strings.TrimPrefix(req.URL.Path, "/")
+	if req.URL.Host != t.host {
+		// Virtual host style. Bucket names with dots always use path style.
+		bucket, _, _ = strings.Cut(req.URL.Host, ".")
+		return bucket, p
+	}
+	bucket, key, _ = strings.Cut(p, "/")
+	return bucket, key
+}
+
+// readJournalBody decodes the XML response body into v.
+// The body is read and replaced so it can still be read by the client.
+// Bodies that cannot be decoded leave v unchanged.
+func readJournalBody(resp *http.Response, v interface{}) error {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, journalMaxBody))
+	if err != nil {
+		resp.Body.Close()
+		return err
+	}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+	_ = xml.Unmarshal(body, v)
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+)
+
+func TestJournal(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/bucket/failed":
+			w.WriteHeader(http.StatusForbidden)
+		case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+			io.WriteString(w, "<CopyObjectResult><ETag>&#34;copied&#34;</ETag></CopyObjectResult>")
+		case r.Method == http.MethodPut:
+			w.Header().Set("ETag", `"etag-`+strings.TrimPrefix(r.URL.Path, "/bucket/")+`"`)
+			w.Header().Set("X-Amz-Version-Id", "v1")
+		case r.Method == http.MethodPost && r.URL.Query().Has("uploadId"):
+			io.WriteString(w, "<CompleteMultipartUploadResult><Key>multi</Key><ETag>&#34;multi-2&#34;</ETag></CompleteMultipartUploadResult>")
+		case r.Method == http.MethodPost && r.URL.Query().Has("delete"):
+			io.WriteString(w, "<DeleteResult><Deleted><Key>a</Key><DeleteMarker>true</DeleteMarker><DeleteMarkerVersionId>dm1</DeleteMarkerVersionId></Deleted><Deleted><Key>b</Key></Deleted></DeleteResult>")
+		}
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fn := filepath.Join(t.TempDir(), "journal.csv")
+	j, err := OpenJournal(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl := http.Client{Transport: j.Transport(http.DefaultTransport, u.Host)}
+	do := func(method, path string, hdr ...string) {
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i+1 < len(hdr); i += 2 {
+			req.Header.Set(hdr[i], hdr[i+1])
+		}
+		resp, err := cl.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The body must still be readable by the client.
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if strings.HasPrefix(path, "/bucket/copy") && !strings.Contains(string(body), "copied") {
+			t.Errorf("copy response body lost: %q", body)
+		}
+	}
+	do(http.MethodPut, "/bucket")
+	do(http.MethodPut, "/bucket/a")
+	do(http.MethodPut, "/bucket/failed")
+	do(http.MethodPut, "/bucket/a?tagging")
+	do(http.MethodPut, "/bucket/copy", "X-Amz-Copy-Source", "/bucket/a")
+	do(http.MethodPost, "/bucket/multi?uploadId=1")
+	do(http.MethodPost, "/bucket?delete")
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries are read back when reopened.
+	j, err = OpenJournal(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if got := j.Len(); got != 4 {
+		t.Errorf("got %d objects, want 4", got)
+	}
+	tests := []struct {
+		bucket string
+		obj    minio.ObjectInfo
+		want   bool
+	}{
+		{bucket: "bucket", obj: minio.ObjectInfo{Key: "a", VersionID: "v1", ETag: "etag-a"}, want: true},
+		{bucket: "bucket", obj: minio.ObjectInfo{Key: "a", VersionID: "dm1", IsDeleteMarker: true}, want: true},
+		{bucket: "bucket", obj: minio.ObjectInfo{Key: "copy", VersionID: "null", ETag: "copied"}, want: true},
+		{bucket: "bucket", obj: minio.ObjectInfo{Key: "multi", ETag: `"multi-2"`}, want: true},
+		// Changed since created.
+		{bucket: "bucket", obj: minio.ObjectInfo{Key: "a", VersionID: "v1", ETag: "other"}},
+		// Not created by warp.
+		{bucket: "bucket", obj: minio.ObjectInfo{Key: "a", VersionID: "v0", ETag: "etag-a"}},
+		{bucket: "bucket", obj: minio.ObjectInfo{Key: "failed"}},
+		{bucket: "bucket", obj: minio.ObjectInfo{Key: "b"}},
+		{bucket: "other", obj: minio.ObjectInfo{Key: "a", VersionID: "v1", ETag: "etag-a"}},
+	}
+	for _, tt := range tests {
+		if got := j.Created(tt.bucket, tt.obj); got != tt.want {
+			t.Errorf("%s/%s (%s): got %v", tt.bucket, tt.obj.Key, tt.obj.VersionID, got)
+		}
+	}
+	if got := j.Kept(); got != 5 {
+		t.Errorf("got %d kept, want 5", got)
+	}
+	if !j.Recorded("bucket", "multi") || j.Recorded("bucket", "failed") || j.Recorded("other", "a") {
+		t.Error("wrong recorded keys")
+	}
+}
@@ -0,0 +1,308 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Latency attributes the time of an operation to the network and the server.
+// The remaining time of the operation was spent in the client.
+// Times are summed over all requests of the operation.
+type Latency struct {
+	// Network is the time spent resolving names, connecting,
+	// sending requests and receiving responses.
+	Network time.Duration `json:"network"`
+	// Server is the time from a request was sent until the response started.
+	// Unless ServerTiming is set this includes a network round trip.
+	Server time.Duration `json:"server"`
+	// ServerTiming is set if every response reported the server processing time
+	// in a Server-Timing header.
+	ServerTiming bool `json:"server_timing"`
+	// RequestID is the request ID of the last response.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ClientLatency returns the time of the operation not spent on the network or in the server.
+// This includes waiting for a free connection.
+// Returns 0 if no latency was recorded.
+func (o Operation) ClientLatency() time.Duration {
+	if o.Latency == nil {
+		return 0
+	}
+	d := o.End.Sub(o.Start) - o.Latency.Network - o.Latency.Server
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Attribution is the average latency of operations split by where the time was spent.
+type Attribution struct {
+	// Operations with latency recorded.
+	Operations int
+	Client     time.Duration
+	Network    time.Duration
+	Server     time.Duration
+	// ServerTiming is set if the server time of all operations was reported by the server.
+	ServerTiming bool
+}
+
+// Attribution returns the average latency attribution of successful operations.
+func (o Operations) Attribution() Attribution {
+	var res Attribution
+	var client, network, server time.Duration
+	timed := 0
+	for _, op := range o {
+		if op.Latency == nil || op.Err != "" {
+			continue
+		}
+		res.Operations++
+		client += op.ClientLatency()
+		network += op.Latency.Network
+		server += op.Latency.Server
+		if op.Latency.ServerTiming {
+			timed++
+		}
+	}
+	if res.Operations == 0 {
+		return res
+	}
+	n := time.Duration(res.Operations)
+	res.Client = client / n
+	res.Network = network / n
+	res.Server = server / n
+	res.ServerTiming = timed == res.Operations
+	return res
+}
+
+// Share returns the share of the total time of d in percent.
+func (a Attribution) Share(d time.Duration) float64 {
+	total := a.Client + a.Network + a.Server
+	if total <= 0 {
+		return 0
+	}
+	return 100 * float64(d) / float64(total)
+}
+
+type latencyTraceKey struct{}
+
+// latencyTrace records the request phases of an operation.
+type latencyTrace struct {
+	mu       sync.Mutex
+	lat      Latency
+	requests int
+	timed    int
+
+	phaseStart time.Time
+	gotConn    time.Time
+	wrote      time.Time
+	firstByte  time.Time
+}
+
+// traceLatency returns a context that records where the time of requests
+// is spent when LatencyTrace is set.
+// The trace is nil otherwise.
+func (c *Common) traceLatency(ctx context.Context) (context.Context, *latencyTrace) {
+	if !c.LatencyTrace {
+		return ctx, nil
+	}
+	t := &latencyTrace{}
+	return context.WithValue(ctx, latencyTraceKey{}, t), t
+}
+
+// record sets the latency of the operation from the requests traced.
+func (t *latencyTrace) record(op *Operation) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.requests == 0 {
+		return
+	}
+	lat := t.lat
+	lat.ServerTiming = t.timed == t.requests
+	op.Latency = &lat
+}
+
+// startPhase marks the start of network activity.
+func (t *latencyTrace) startPhase() {
+	t.mu.Lock()
+	t.phaseStart = time.Now()
+	t.mu.Unlock()
+}
+
+// endPhase adds the time since startPhase to the network time.
+func (t *latencyTrace) endPhase() {
+	t.mu.Lock()
+	if !t.phaseStart.IsZero() {
+		t.lat.Network += time.Since(t.phaseStart)
+		t.phaseStart = time.Time{}
+	}
+	t.mu.Unlock()
+}
+
+func (t *latencyTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { t.startPhase() },
+		DNSDone:           func(httptrace.DNSDoneInfo) { t.endPhase() },
+		ConnectStart:      func(string, string) { t.startPhase() },
+		ConnectDone:       func(string, string, error) { t.endPhase() },
+		TLSHandshakeStart: t.startPhase,
+		GotConn: func(httptrace.GotConnInfo) {
+			t.endPhase()
+			t.mu.Lock()
+			t.gotConn = time.Now()
+			t.mu.Unlock()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			t.wrote = time.Now()
+			if !t.gotConn.IsZero() {
+				t.lat.Network += t.wrote.Sub(t.gotConn)
+			}
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			t.firstByte = time.Now()
+			t.mu.Unlock()
+		},
+	}
+}
+
+// response attributes the wait for the response to the server,
+// or to the server and network if the server timing is reported.
+func (t *latencyTrace) response(h http.Header) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.requests++
+	if id := h.Get("x-amz-request-id"); id != "" {
+		t.lat.RequestID = id
+	}
+	if t.wrote.IsZero() || t.firstByte.IsZero() {
+		return
+	}
+	wait := t.firstByte.Sub(t.wrote)
+	if d, ok := serverTiming(h); ok {
+		t.timed++
+		if d > wait {
+			d = wait
+		}
+		t.lat.Server += d
+		t.lat.Network += wait - d
+	} else {
+		t.lat.Server += wait
+	}
+}
+
+// bodyDone adds the time receiving the response body to the network time.
+func (t *latencyTrace) bodyDone() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.firstByte.IsZero() {
+		t.lat.Network += time.Since(t.firstByte)
+	}
+	t.gotConn, t.wrote, t.firstByte = time.Time{}, time.Time{}, time.Time{}
+}
+
+// serverTiming returns the server processing time from a Server-Timing header.
+// The "total" metric is used if present, otherwise the longest duration.
+func serverTiming(h http.Header) (time.Duration, bool) {
+	var longest float64
+	found := false
+	for _, v := range h.Values("Server-Timing") {
+		for _, metric := range strings.Split(v, ",") {
+			params := strings.Split(metric, ";")
+			name := strings.TrimSpace(params[0])
+			for _, p := range params[1:] {
+				p = strings.TrimSpace(p)
+				if !strings.HasPrefix(p, "dur=") {
+					continue
+				}
+				ms, err := strconv.ParseFloat(strings.TrimPrefix(p, "dur="), 64)
+				if err != nil || ms < 0 {
+					continue
+				}
+				if strings.EqualFold(name, "total") {
+					return time.Duration(ms * float64(time.Millisecond)), true
+				}
+				if ms > longest {
+					longest = ms
+				}
+				found = true
+			}
+		}
+	}
+	return time.Duration(longest * float64(time.Millisecond)), found
+}
+
+// NewLatencyTransport returns a transport recording where the time of requests
+// is spent for operations traced when Common.LatencyTrace is set.
+func NewLatencyTransport(rt http.RoundTripper) http.RoundTripper {
+	return latencyTransport{rt: rt}
+}
+
+type latencyTransport struct {
+	rt http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (l latencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t, ok := req.Context().Value(latencyTraceKey{}).(*latencyTrace)
+	if !ok {
+		return l.rt.RoundTrip(req)
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), t.clientTrace()))
+	resp, err := l.rt.RoundTrip(req)
+	if err != nil {
+		t.bodyDone()
+		return resp, err
+	}
+	t.response(resp.Header)
+	resp.Body = &latencyBody{ReadCloser: resp.Body, t: t}
+	return resp, nil
+}
+
+// latencyBody records when the response body has been read.
+type latencyBody struct {
+	io.ReadCloser
+	t    *latencyTrace
+	once sync.Once
+}
+
+func (b *latencyBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.once.Do(b.t.bodyDone)
+	}
+	return n, err
+}
+
+func (b *latencyBody) Close() error {
+	b.once.Do(b.t.bodyDone)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,110 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServerTiming(t *testing.T) {
+	tests := []struct {
+		header []string
+		want   time.Duration
+		ok     bool
+	}{
+		{header: nil},
+		{header: []string{"cache;desc=hit"}},
+		{header: []string{`db;dur=5, total;dur=12.5;desc="all"`}, want: 12500 * time.Microsecond, ok: true},
+		{header: []string{"db;dur=5", "disk;dur=7"}, want: 7 * time.Millisecond, ok: true},
+		{header: []string{"db;dur=x"}},
+	}
+	for _, tt := range tests {
+		h := http.Header{"Server-Timing": tt.header}
+		got, ok := serverTiming(h)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%q: got %v, %v, want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLatencyTransport(t *testing.T) {
+	const wait = 50 * time.Millisecond
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(wait)
+		if r.URL.Path == "/timed" {
+			w.Header().Set("Server-Timing", "total;dur=10")
+		}
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	c := Common{LatencyTrace: true}
+	cl := http.Client{Transport: NewLatencyTransport(http.DefaultTransport)}
+	get := func(path string) Operation {
+		ctx, lt := c.traceLatency(context.Background())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		op := Operation{Start: time.Now()}
+		resp, err := cl.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		op.End = time.Now()
+		lt.record(&op)
+		if op.Latency == nil {
+			t.Fatal("no latency recorded")
+		}
+		return op
+	}
+
+	op := get("/")
+	if op.Latency.ServerTiming || op.Latency.Server < wait {
+		t.Errorf("want untimed server time >= %v, got %+v", wait, *op.Latency)
+	}
+	op = get("/timed")
+	if !op.Latency.ServerTiming || op.Latency.Server != 10*time.Millisecond || op.Latency.Network < wait-10*time.Millisecond {
+		t.Errorf("want timed server time of 10ms, got %+v", *op.Latency)
+	}
+
+	// Latency must survive a CSV round trip.
+	var buf bytes.Buffer
+	if err := (Operations{op}).CSV(&buf, ""); err != nil {
+		t.Fatal(err)
+	}
+	ops, err := OperationsFromCSV(&buf, false, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 1 || ops[0].Latency == nil || *ops[0].Latency != *op.Latency {
+		t.Errorf("latency not restored from CSV, got %+v", ops)
+	}
+	if a := ops.Attribution(); a.Operations != 1 || !a.ServerTiming || a.Client != ops[0].ClientLatency() {
+		t.Errorf("unexpected attribution %+v", a)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/warp/pkg/generator"
+)
+
+const (
+	// limitGenShare is the share of worker time spent generating payloads
+	// above which generation is reported as limiting.
+	limitGenShare = 0.2
+
+	// limitRecShare is the share of operations recorded with a nearly full queue
+	// above which recording is reported as limiting.
+	limitRecShare = 0.01
+
+	// limitRecBusy is the share of the benchmark time spent recording
+	// required for recording to be reported as limiting.
+	// Below this the queue only fills in short bursts.
+	limitRecBusy = 0.5
+)
+
+// ClientLimits measures work done by the client while a benchmark is running,
+// so throughput limited by the client is not reported as server performance.
+// Time spent generating payloads is measured for sources returned by Source.
+// Recording is measured by collectors of benchmarks with ClientLimits set in Common.
+type ClientLimits struct {
+	genNanos, genBytes, genObjects int64
+	recNanos, recOps, recBehind    int64
+}
+
+// NewClientLimits returns an empty measurement.
+func NewClientLimits() *ClientLimits {
+	return &ClientLimits{}
+}
+
+// Source returns a source function that measures the time spent
+// creating objects and reading their content.
+func (l *ClientLimits) Source(fn func() generator.Source) func() generator.Source {
+	return func() generator.Source {
+		return &limitSource{Source: fn(), l: l}
+	}
+}
+
+// Warnings returns a description of each client side limit that was reached
+// by a benchmark with the given concurrency that ran for dur.
+func (l *ClientLimits) Warnings(concurrency int, dur time.Duration) []string {
+	if concurrency <= 0 || dur <= 0 {
+		return nil
+	}
+	var res []string
+	genNanos := atomic.LoadInt64(&l.genNanos)
+	if share := float64(genNanos) / float64(concurrency) / float64(dur); genNanos > 0 && share >= limitGenShare {
+		perSec := float64(concurrency) * float64(time.Second) / float64(genNanos)
+		speed := fmt.Sprintf("%.1f obj/s", float64(atomic.LoadInt64(&l.genObjects))*perSec)
+		if b := atomic.LoadInt64(&l.genBytes); b > 0 {
+			speed = fmt.Sprintf("%v, %s", Throughput(float64(b)*perSec), speed)
+		}
+		res = append(res, fmt.Sprintf("Payload generation used %.0f%% of worker time. Generator limit with %d workers: %s. Throughput may reflect the client, not the server.", 100*share, concurrency, speed))
+	}
+	recOps := atomic.LoadInt64(&l.recOps)
+	recNanos := atomic.LoadInt64(&l.recNanos)
+	behind := atomic.LoadInt64(&l.recBehind)
+	if recOps > 0 && float64(behind)/float64(recOps) >= limitRecShare && float64(recNanos)/float64(dur) >= limitRecBusy {
+		perSec := float64(recOps) * float64(time.Second) / float64(recNanos)
+		res = append(res, fmt.Sprintf("Result recording was behind for %.0f%% of operations. Recording limit: %.0f ops/s. Throughput may reflect the client, not the server.", 100*float64(behind)/float64(recOps), perSec))
+	}
+	return res
+}
+
+// recorded adds an operation that took d to record.
+// behind should be set if the queue of operations was nearly full.
+func (l *ClientLimits) recorded(d time.Duration, behind bool) {
+	atomic.AddInt64(&l.recNanos, int64(d))
+	atomic.AddInt64(&l.recOps, 1)
+	if behind {
+		atomic.AddInt64(&l.recBehind, 1)
+	}
+}
+
+// limitSource measures the time spent by a source.
+// Measurements of reads are added when the next object is requested.
+type limitSource struct {
+	generator.Source
+	l *ClientLimits
+	r limitReader
+}
+
+func (s *limitSource) Object() *generator.Object {
+	t := time.Now()
+	obj := s.Source.Object()
+	nanos := int64(time.Since(t)) + s.r.nanos
+	atomic.AddInt64(&s.l.genNanos, nanos)
+	atomic.AddInt64(&s.l.genObjects, 1)
+	if s.r.bytes > 0 {
+		atomic.AddInt64(&s.l.genBytes, s.r.bytes)
+	}
+	s.r.nanos, s.r.bytes = 0, 0
+	// Sources may return the same reader for several objects.
+	if obj != nil && obj.Reader != nil && obj.Reader != io.ReadSeeker(&s.r) {
+		s.r.r = obj.Reader
+		obj.Reader = &s.r
+	}
+	return obj
+}
+
+// limitReader measures the time spent reading.
+type limitReader struct {
+	r     io.ReadSeeker
+	nanos int64
+	bytes int64
+}
+
+func (r *limitReader) Read(p []byte) (int, error) {
+	t := time.Now()
+	n, err := r.r.Read(p)
+	r.nanos += int64(time.Since(t))
+	r.bytes += int64(n)
+	return n, err
+}
+
+func (r *limitReader) Seek(offset int64, whence int) (int64, error) {
+	return r.r.Seek(offset, whence)
+}
@@ -0,0 +1,71 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/warp/pkg/generator"
+)
+
+func TestClientLimits(t *testing.T) {
+	l := NewClientLimits()
+	newSrc := l.Source(func() generator.Source {
+		src, err := generator.New(generator.WithRandomData().Apply(), generator.WithSize(1<<20))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return src
+	})
+	src := newSrc()
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		obj := src.Object()
+		n, err := io.Copy(io.Discard, obj.Reader)
+		if err != nil || n != 1<<20 {
+			t.Fatalf("read %d bytes: %v", n, err)
+		}
+	}
+	src.Object()
+	if l.genObjects != 11 || l.genBytes != 10<<20 {
+		t.Fatalf("got %d objects, %d bytes", l.genObjects, l.genBytes)
+	}
+	// All time was spent generating.
+	w := l.Warnings(1, time.Since(start))
+	if len(w) != 1 || !strings.Contains(w[0], "Payload generation") {
+		t.Fatalf("want generator warning, got %v", w)
+	}
+	if w := l.Warnings(1, time.Hour); len(w) != 0 {
+		t.Fatalf("want no warnings, got %v", w)
+	}
+
+	for i := 0; i < 100; i++ {
+		l.recorded(time.Microsecond, i < 5)
+	}
+	// Queue bursts without the recorder being busy are not a limit.
+	if w := l.Warnings(1, time.Hour); len(w) != 0 {
+		t.Fatalf("want no warnings, got %v", w)
+	}
+	w = l.Warnings(1, 150*time.Microsecond)
+	if len(w) != 2 || !strings.Contains(w[1], "1000000 ops/s") {
+		t.Fatalf("want recording warning, got %v", w)
+	}
+}
@@ -28,7 +28,6 @@ import (
 	"github.com/minio/minio-go/v7"
 
 	"github.com/minio/pkg/console"
-	"github.com/minio/warp/pkg/generator"
 )
 
 // List benchmarks listing speed.
@@ -38,11 +37,28 @@ type List struct {
 	Collector     *Collector
 	Metadata      bool
 	Versions      int
-	objects       []generator.Objects
+	// MaxKeys is the number of entries requested per page. 0 uses the server default.
+	MaxKeys int
+
+	// AccessKey, SecretKey and Region sign the page requests of the S3 API.
+	// They are sent with Transport to the host of the client.
+	// If Transport is nil, pages are only listed with backends that implement ListPager.
+	AccessKey, SecretKey, Region string
+	Transport                    http.RoundTripper
+
+	// prefixes contains the prefix and the number of objects of each thread.
+	// Only the prefixes are kept, so the memory used does not grow with the objects.
+	prefixes []listPrefix
 
 	Common
 }
 
+// listPrefix is a prefix listed by a thread and the number of objects uploaded below it.
+type listPrefix struct {
+	prefix  string
+	objects int
+}
+
 // Prepare will create an empty bucket or delete any content already there
 // and upload a number of objects.
 func (d *List) Prepare(ctx context.Context) error {
@@ -50,7 +66,7 @@ func (d *List) Prepare(ctx context.Context) error {
 		return err
 	}
 	if d.Versions > 1 {
-		cl, done := d.Client()
+		cl, done := d.metaClient()
 		if !d.Versioned {
 			err := cl.EnableVersioning(ctx, d.Bucket)
 			if err != nil {
@@ -74,8 +90,8 @@ func (d *List) Prepare(ctx context.Context) error {
 	}
 	var wg sync.WaitGroup
 	wg.Add(d.Concurrency)
-	d.Collector = NewCollector()
-	d.objects = make([]generator.Objects, d.Concurrency)
+	d.Collector = d.collector()
+	d.prefixes = make([]listPrefix, d.Concurrency)
 	var mu sync.Mutex
 	objsCreated := 0
 	var groupErr error
@@ -95,10 +111,16 @@ func (d *List) Prepare(ctx context.Context) error {
 				default:
 				}
 				obj := src.Object()
+				if obj == nil {
+					return
+				}
 				// Assure we don't have duplicates
 				for {
 					if _, ok := exists[obj.Name]; ok {
 						obj = src.Object()
+						if obj == nil {
+							return
+						}
 						continue
 					}
 					break
@@ -108,8 +130,11 @@ func (d *List) Prepare(ctx context.Context) error {
 				for ver := 0; ver < d.Versions; ver++ {
 					// New input for each version
 					obj := src.Object()
+					if obj == nil {
+						return
+					}
 					obj.Name = name
-					client, cldone := d.Client()
+					client, cldone := d.clientFor(obj.Name)
 					op := Operation{
 						OpType:   http.MethodPut,
 						Thread:   uint16(i),
@@ -118,7 +143,9 @@ func (d *List) Prepare(ctx context.Context) error {
 						ObjPerOp: 1,
 						Endpoint: client.EndpointURL().String(),
 					}
-					opts.ContentType = obj.ContentType
+					setObjectOpts(&opts, obj)
+					track := d.trackManifest(obj)
+					d.waitOp(ctx, d.Bucket, obj.Name)
 					op.Start = time.Now()
 					res, err := client.PutObject(ctx, d.Bucket, obj.Name, obj.Reader, obj.Size, opts)
 					op.End = time.Now()
@@ -145,8 +172,9 @@ func (d *List) Prepare(ctx context.Context) error {
 					}
 					cldone()
 					mu.Lock()
-					obj.Reader = nil
-					d.objects[i] = append(d.objects[i], *obj)
+					track(res)
+					d.prefixes[i].prefix = obj.Prefix
+					d.prefixes[i].objects++
 					objsCreated++
 					d.prepareProgress(float64(objsCreated) / float64(objPerPrefix*d.Concurrency*d.Versions))
 					mu.Unlock()
@@ -157,9 +185,9 @@ func (d *List) Prepare(ctx context.Context) error {
 	}
 	wg.Wait()
 
-	// Shuffle objects.
+	// Shuffle prefixes.
 	// Benchmark will pick from slice in order.
-	a := d.objects
+	a := d.prefixes
 	rand.Shuffle(len(a), func(i, j int) {
 		a[i], a[j] = a[j], a[i]
 	})
@@ -175,16 +203,18 @@ func (d *List) Start(ctx context.Context, wait chan struct{}) (Operations, error
 	if d.AutoTermDur > 0 {
 		ctx = c.AutoTerm(ctx, "LIST", d.AutoTermScale, autoTermCheck, autoTermSamples, d.AutoTermDur)
 	}
-	// Non-terminating context.
-	nonTerm := context.Background()
+	// Operations in progress when ctx is done can finish.
+	nonTerm := d.opsContext(ctx)
 
 	for i := 0; i < d.Concurrency; i++ {
 		go func(i int) {
 			rcv := c.Receiver()
 			defer wg.Done()
+			opc := d.opContexts(nonTerm)
+			defer opc.stop()
 			done := ctx.Done()
-			objs := d.objects[i]
-			wantN := len(objs)
+			prefix := d.prefixes[i].prefix
+			wantN := d.prefixes[i].objects
 			if d.NoPrefix {
 				wantN *= d.Concurrency
 			}
@@ -197,8 +227,7 @@ func (d *List) Start(ctx context.Context, wait chan struct{}) (Operations, error
 				default:
 				}
 
-				prefix := objs[0].Prefix
-				client, cldone := d.Client()
+				client, cldone := d.metaClient()
 				op := Operation{
 					File:     prefix,
 					OpType:   "LIST",
@@ -206,31 +235,12 @@ func (d *List) Start(ctx context.Context, wait chan struct{}) (Operations, error
 					Size:     0,
 					Endpoint: client.EndpointURL().String(),
 				}
+				d.waitOp(ctx, d.Bucket, "")
 				op.Start = time.Now()
-
-				// List all objects with prefix
-				listCh := client.ListObjects(nonTerm, d.Bucket, minio.ListObjectsOptions{
-					WithMetadata: d.Metadata,
-					Prefix:       objs[0].Prefix,
-					Recursive:    true,
-					WithVersions: d.Versions > 1,
-				})
-
-				// Wait for errCh to close.
-				for {
-					err, ok := <-listCh
-					if !ok {
-						break
-					}
-					if err.Err != nil {
-						d.Error(err.Err)
-						op.Err = err.Err.Error()
-					}
-					op.ObjPerOp++
-					if op.FirstByte == nil {
-						now := time.Now()
-						op.FirstByte = &now
-					}
+				if pager, ok := d.pager(client); ok && !d.Metadata && d.Versions <= 1 {
+					d.listPages(opc.next(), pager, &op, rcv)
+				} else {
+					d.listObjects(opc.next(), client, &op)
 				}
 				if op.ObjPerOp != wantN {
 					if op.Err == "" {
@@ -247,7 +257,80 @@ func (d *List) Start(ctx context.Context, wait chan struct{}) (Operations, error
 	return c.Close(), nil
 }
 
+// pager returns the pager of the backend, if pages can be listed.
+func (d *List) pager(client Backend) (ListPager, bool) {
+	if s3, ok := client.(S3Backend); ok {
+		if d.Transport == nil {
+			return nil, false
+		}
+		return S3Pager{Client: s3.Client, AccessKey: d.AccessKey, SecretKey: d.SecretKey, Region: d.Region, Transport: d.Transport}, true
+	}
+	pager, ok := client.(ListPager)
+	return pager, ok
+}
+
+// listPages lists the prefix of the operation one page at a time.
+// Entries are only counted, and each page is sent to rcv as an annotation with its latency.
+func (d *List) listPages(ctx context.Context, pager ListPager, op *Operation, rcv chan<- Operation) {
+	token := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			op.Err = err.Error()
+			return
+		}
+		start := time.Now()
+		objs, next, err := pager.ListObjectsPage(ctx, d.Bucket, op.File, token, d.MaxKeys)
+		end := time.Now()
+		rcv <- newListPage(op.Thread, op.Endpoint, start, end, len(objs), err)
+		if err != nil {
+			d.Error(err)
+			op.Err = err.Error()
+			return
+		}
+		if op.FirstByte == nil && len(objs) > 0 {
+			op.FirstByte = &end
+		}
+		op.ObjPerOp += len(objs)
+		if next == "" {
+			return
+		}
+		token = next
+	}
+}
+
+// listObjects lists the prefix of the operation with metadata or versions,
+// or with backends that cannot list a page at a time.
+// Entries are only counted. Pages are not visible, so no page latency is recorded.
+func (d *List) listObjects(ctx context.Context, client Backend, op *Operation) {
+	listCh := client.ListObjects(ctx, d.Bucket, minio.ListObjectsOptions{
+		WithMetadata: d.Metadata,
+		Prefix:       op.File,
+		Recursive:    true,
+		WithVersions: d.Versions > 1,
+		MaxKeys:      d.MaxKeys,
+	})
+	for obj := range listCh {
+		if obj.Err != nil {
+			d.Error(obj.Err)
+			op.Err = obj.Err.Error()
+		}
+		op.ObjPerOp++
+		if op.FirstByte == nil {
+			now := time.Now()
+			op.FirstByte = &now
+		}
+	}
+}
+
 // Cleanup deletes everything uploaded to the bucket.
 func (d *List) Cleanup(ctx context.Context) {
-	d.deleteAllInBucket(ctx, generator.MergeObjectPrefixes(d.objects)...)
+	prefixes := make(map[string]struct{}, len(d.prefixes))
+	for _, p := range d.prefixes {
+		prefixes[p.prefix] = struct{}{}
+	}
+	res := make([]string, 0, len(prefixes))
+	for p := range prefixes {
+		res = append(res, p)
+	}
+	d.deleteAllInBucket(ctx, res...)
 }
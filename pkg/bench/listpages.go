@@ -0,0 +1,84 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"time"
+)
+
+// ListPageLabel is the label of annotations of the pages of list operations.
+// The number of entries of the page is stored in ObjPerOp.
+const ListPageLabel = "list-page"
+
+// newListPage returns an annotation of a page of n entries requested at start and received at end.
+func newListPage(thread uint16, endpoint string, start, end time.Time, n int, err error) Operation {
+	op := NewAnnotation(ListPageLabel, start, end)
+	op.Thread = thread
+	op.Endpoint = endpoint
+	op.ObjPerOp = n
+	if err != nil {
+		op.Err = err.Error()
+	}
+	return op
+}
+
+// ListPageStats summarizes the pages of list operations.
+type ListPageStats struct {
+	Pages   int
+	Entries int
+	Errors  int
+	// Latency of successful pages.
+	Avg, Median, P90, P99, Slowest time.Duration
+}
+
+// ListPages returns statistics of the list pages in the annotations.
+// The number of pages is 0 if there are none.
+func (o Operations) ListPages() ListPageStats {
+	var res ListPageStats
+	ok := make(Operations, 0, len(o))
+	for _, op := range o {
+		if op.OpType != OpAnnotation || op.File != ListPageLabel {
+			continue
+		}
+		res.Pages++
+		if op.Err != "" {
+			res.Errors++
+			continue
+		}
+		res.Entries += op.ObjPerOp
+		ok = append(ok, op)
+	}
+	if len(ok) == 0 {
+		return res
+	}
+	ok.SortByDuration()
+	res.Avg = ok.AvgDuration()
+	res.Median = ok.Median(0.5).Duration()
+	res.P90 = ok.Median(0.9).Duration()
+	res.P99 = ok.Median(0.99).Duration()
+	res.Slowest = ok[len(ok)-1].Duration()
+	return res
+}
+
+// EntriesPerPage returns the average number of entries of successful pages.
+func (s ListPageStats) EntriesPerPage() float64 {
+	if s.Pages == s.Errors {
+		return 0
+	}
+	return float64(s.Entries) / float64(s.Pages-s.Errors)
+}
@@ -0,0 +1,57 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestListPages(t *testing.T) {
+	start := time.Now()
+	ops := Operations{
+		{OpType: "LIST", ObjPerOp: 2500, Start: start, End: start.Add(time.Second)},
+		NewAnnotation("other", start, start.Add(time.Hour)),
+	}
+	for i := 0; i < 100; i++ {
+		ops = append(ops, newListPage(0, "host", start, start.Add(time.Duration(i+1)*time.Millisecond), 1000, nil))
+	}
+	ops = append(ops, newListPage(1, "host", start, start.Add(time.Hour), 0, errors.New("failed")))
+
+	rest, annotations := ops.SplitAnnotations()
+	if len(rest) != 1 {
+		t.Fatalf("got %d operations, want the list operation", len(rest))
+	}
+	if got := rest.ListPages(); got.Pages != 0 {
+		t.Errorf("got %d pages in operations", got.Pages)
+	}
+	p := annotations.ListPages()
+	if p.Pages != 101 || p.Errors != 1 || p.Entries != 100000 {
+		t.Errorf("got %d pages, %d errors, %d entries", p.Pages, p.Errors, p.Entries)
+	}
+	if p.EntriesPerPage() != 1000 {
+		t.Errorf("got %v entries per page, want 1000", p.EntriesPerPage())
+	}
+	if p.Slowest != 100*time.Millisecond || p.Median < 49*time.Millisecond || p.Median > 52*time.Millisecond {
+		t.Errorf("got median %v, slowest %v", p.Median, p.Slowest)
+	}
+	if p.P99 < p.P90 || p.P90 < p.Median {
+		t.Errorf("percentiles not increasing: %v, %v, %v", p.Median, p.P90, p.P99)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LiveStats keeps statistics of the operations finished
+// since the last interval while a benchmark is running.
+type LiveStats struct {
+	// Percentiles replace the 50% and 99% durations if set.
+	// Must be set before use.
+	Percentiles Percentiles
+
+	mu    sync.Mutex
+	start time.Time
+	ops   map[string]*liveOps
+}
+
+type liveOps struct {
+	durs   []time.Duration
+	bytes  int64
+	objs   int
+	errors int
+}
+
+// LiveInterval contains statistics of one operation type in an interval.
+type LiveInterval struct {
+	OpType     string        `json:"op"`
+	Duration   time.Duration `json:"duration"`
+	Operations int           `json:"operations"`
+	Errors     int           `json:"errors"`
+	BPS        float64       `json:"bytes_per_sec"`
+	OPS        float64       `json:"objects_per_sec"`
+	P50        time.Duration `json:"p50"`
+	P99        time.Duration `json:"p99"`
+
+	Percentiles []PercentileDuration `json:"percentiles,omitempty"`
+}
+
+// String returns a human readable version of the interval.
+func (l LiveInterval) String() string {
+	speed := fmt.Sprintf("%.2f obj/s", l.OPS)
+	if l.BPS > 0 {
+		speed = fmt.Sprintf("%v, %s", Throughput(l.BPS), speed)
+	}
+	s := fmt.Sprintf("%s: %s, 50%%: %v, 99%%: %v", l.OpType, speed,
+		l.P50.Round(time.Microsecond), l.P99.Round(time.Microsecond))
+	if len(l.Percentiles) > 0 {
+		s = fmt.Sprintf("%s: %s", l.OpType, speed)
+		for _, p := range l.Percentiles {
+			s += fmt.Sprintf(", %s: %v", PercentileLabel(p.Percentile), p.Duration.Round(time.Microsecond))
+		}
+	}
+	if l.Errors > 0 {
+		s += fmt.Sprintf(", Errors: %d", l.Errors)
+	}
+	return s
+}
+
+// NewLiveStats returns live statistics starting now.
+func NewLiveStats() *LiveStats {
+	return &LiveStats{start: time.Now(), ops: make(map[string]*liveOps)}
+}
+
+// add an operation to the current interval.
+func (l *LiveStats) add(op Operation) {
+	if op.OpType == OpAnnotation {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	o := l.ops[op.OpType]
+	if o == nil {
+		o = &liveOps{}
+		l.ops[op.OpType] = o
+	}
+	if op.Err != "" {
+		o.errors++
+		return
+	}
+	o.durs = append(o.durs, op.Duration())
+	o.bytes += op.Size
+	o.objs += op.ObjPerOp
+}
+
+// Interval returns the statistics of operations finished since the previous call,
+// sorted by operation type, and starts a new interval.
+func (l *LiveStats) Interval() []LiveInterval {
+	l.mu.Lock()
+	ops := l.ops
+	now := time.Now()
+	dur := now.Sub(l.start)
+	l.ops = make(map[string]*liveOps, len(ops))
+	l.start = now
+	l.mu.Unlock()
+
+	res := make([]LiveInterval, 0, len(ops))
+	for typ, o := range ops {
+		iv := LiveInterval{
+			OpType:     typ,
+			Duration:   dur,
+			Operations: len(o.durs),
+			Errors:     o.errors,
+		}
+		if dur > 0 {
+			iv.BPS = float64(o.bytes) / dur.Seconds()
+			iv.OPS = float64(o.objs) / dur.Seconds()
+		}
+		if len(o.durs) > 0 {
+			sort.Slice(o.durs, func(i, j int) bool { return o.durs[i] < o.durs[j] })
+			iv.P50 = o.durs[len(o.durs)/2]
+			iv.P99 = o.durs[len(o.durs)*99/100]
+			for _, p := range l.Percentiles {
+				iv.Percentiles = append(iv.Percentiles, PercentileDuration{Percentile: p, Duration: o.durs[percentileIndex(len(o.durs), p)]})
+			}
+		}
+		res = append(res, iv)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].OpType < res[j].OpType })
+	return res
+}
@@ -0,0 +1,56 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLiveStats(t *testing.T) {
+	live := NewLiveStats()
+	c := newCollector(live, nil, nil)
+	rcv := c.Receiver()
+	start := time.Now()
+	for i := 1; i <= 100; i++ {
+		rcv <- Operation{OpType: http.MethodGet, ObjPerOp: 1, Size: 10, Start: start, End: start.Add(time.Duration(i) * time.Millisecond)}
+	}
+	rcv <- Operation{OpType: http.MethodPut, ObjPerOp: 1, Err: "failed", Start: start, End: start}
+	rcv <- Operation{OpType: OpAnnotation, Start: start, End: start}
+	c.Close()
+
+	got := live.Interval()
+	if len(got) != 2 {
+		t.Fatalf("want 2 operation types, got %+v", got)
+	}
+	get, put := got[0], got[1]
+	if get.OpType != http.MethodGet || get.Operations != 100 || get.P50 != 51*time.Millisecond || get.P99 != 100*time.Millisecond {
+		t.Errorf("unexpected GET stats %+v", get)
+	}
+	if get.OPS <= 0 || math.Abs(get.BPS-get.OPS*10) > get.BPS/1e6 {
+		t.Errorf("unexpected GET throughput %+v", get)
+	}
+	if put.OpType != http.MethodPut || put.Operations != 0 || put.Errors != 1 {
+		t.Errorf("unexpected PUT stats %+v", put)
+	}
+	if got := live.Interval(); len(got) != 0 {
+		t.Errorf("want empty interval, got %+v", got)
+	}
+}
@@ -0,0 +1,270 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/warp/pkg/generator"
+)
+
+// ManifestEntry describes an uploaded object.
+type ManifestEntry struct {
+	Key       string `json:"key"`
+	VersionID string `json:"version_id,omitempty"`
+	Size      int64  `json:"size"`
+	SHA256    string `json:"sha256"`
+	ETag      string `json:"etag"`
+}
+
+// Manifest records objects uploaded by a benchmark.
+type Manifest struct {
+	mu      sync.Mutex
+	entries []ManifestEntry
+}
+
+// Add an entry to the manifest.
+func (m *Manifest) Add(e ManifestEntry) {
+	m.mu.Lock()
+	m.entries = append(m.entries, e)
+	m.mu.Unlock()
+}
+
+// Len returns the number of entries.
+func (m *Manifest) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+// Entries returns the entries sorted by key.
+func (m *Manifest) Entries() []ManifestEntry {
+	m.mu.Lock()
+	res := append([]ManifestEntry{}, m.entries...)
+	m.mu.Unlock()
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Key < res[j].Key })
+	return res
+}
+
+// manifestCSVHeader is the header of CSV manifests.
+var manifestCSVHeader = []string{"key", "version_id", "size", "sha256", "etag"}
+
+// WriteCSV writes the manifest as CSV.
+func (m *Manifest) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(manifestCSVHeader); err != nil {
+		return err
+	}
+	for _, e := range m.Entries() {
+		err := cw.Write([]string{e.Key, e.VersionID, strconv.FormatInt(e.Size, 10), e.SHA256, e.ETag})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the manifest as a JSON array.
+func (m *Manifest) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m.Entries())
+}
+
+// ReadManifest reads a manifest written as CSV or JSON.
+func ReadManifest(r io.Reader) ([]ManifestEntry, error) {
+	br := bufio.NewReader(r)
+	start, err := br.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(start, []byte("[")) {
+		var res []ManifestEntry
+		err := json.NewDecoder(br).Decode(&res)
+		return res, err
+	}
+	cr := csv.NewReader(br)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(header) != len(manifestCSVHeader) || header[0] != manifestCSVHeader[0] {
+		return nil, errors.New("unknown manifest header")
+	}
+	var res []ManifestEntry
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.ParseInt(rec[2], 10, 64)
+		if err != nil {
+			line, _ := cr.FieldPos(2)
+			return nil, fmt.Errorf("line %d: invalid size %q: %w", line, rec[2], err)
+		}
+		res = append(res, ManifestEntry{Key: rec[0], VersionID: rec[1], Size: size, SHA256: rec[3], ETag: rec[4]})
+	}
+}
+
+// Registry appends manifest entries to a CSV file as objects are uploaded.
+// Entries survive restarts of warp, and a registry can be extended by later runs.
+type Registry struct {
+	mu sync.Mutex
+	f  io.WriteCloser
+	w  *csv.Writer
+}
+
+// OpenRegistry opens the registry file for appending, creating it if needed.
+func OpenRegistry(fn string) (*Registry, error) {
+	f, err := os.OpenFile(fn, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	st, err := f.Stat()
+	return NewRegistry(f, err != nil || st.Size() == 0)
+}
+
+// NewRegistry returns a registry appending entries to w, which is closed with the registry.
+// The CSV header is written first if header is set.
+func NewRegistry(w io.WriteCloser, header bool) (*Registry, error) {
+	r := Registry{f: w, w: csv.NewWriter(w)}
+	if header {
+		if err := r.write(manifestCSVHeader); err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+	return &r, nil
+}
+
+// write a record and flush it to the file.
+func (r *Registry) write(rec []string) error {
+	if err := r.w.Write(rec); err != nil {
+		return err
+	}
+	r.w.Flush()
+	return r.w.Error()
+}
+
+// Add an entry to the registry.
+func (r *Registry) Add(e ManifestEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.write([]string{e.Key, e.VersionID, strconv.FormatInt(e.Size, 10), e.SHA256, e.ETag})
+}
+
+// Close the registry writer.
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+// LatestManifestEntries returns the last entry of each key and version,
+// sorted by key, so objects overwritten by later runs are only included once.
+func LatestManifestEntries(entries []ManifestEntry) []ManifestEntry {
+	type id struct{ key, version string }
+	idx := make(map[id]int, len(entries))
+	res := make([]ManifestEntry, 0, len(entries))
+	for _, e := range entries {
+		k := id{key: e.Key, version: e.VersionID}
+		if i, ok := idx[k]; ok {
+			res[i] = e
+			continue
+		}
+		idx[k] = len(res)
+		res = append(res, e)
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Key < res[j].Key })
+	return res
+}
+
+// trackManifest hashes the object when a manifest or registry is recorded.
+// The returned function must be called with the result of a successful upload.
+// Uploads are counted in the prepare stats.
+func (c *Common) trackManifest(obj *generator.Object) func(res minio.UploadInfo) {
+	record := c.hashManifest(obj)
+	return func(res minio.UploadInfo) {
+		c.countPrepared(res.Size)
+		record(res)
+	}
+}
+
+// hashManifest hashes the object when a manifest or registry is recorded.
+// The returned function must be called with the result of a successful upload.
+func (c *Common) hashManifest(obj *generator.Object) func(res minio.UploadInfo) {
+	if !c.recordsManifest() {
+		return func(minio.UploadInfo) {}
+	}
+	h := sha256.New()
+	_, err := io.Copy(h, obj.Reader)
+	if err == nil {
+		_, err = obj.Reader.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		c.Error("manifest hash error: ", err)
+		return func(minio.UploadInfo) {}
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	name := obj.Name
+	return func(res minio.UploadInfo) {
+		c.addManifest(name, sum, res)
+	}
+}
+
+// recordsManifest returns whether uploaded objects are recorded in a manifest or registry.
+func (c *Common) recordsManifest() bool {
+	return c.Manifest != nil || c.Registry != nil
+}
+
+// addManifest adds an uploaded object with the hex encoded SHA-256 checksum
+// to the manifest and registry.
+func (c *Common) addManifest(name, sum string, res minio.UploadInfo) {
+	e := ManifestEntry{
+		Key:       name,
+		VersionID: res.VersionID,
+		Size:      res.Size,
+		SHA256:    sum,
+		ETag:      res.ETag,
+	}
+	if c.Manifest != nil {
+		c.Manifest.Add(e)
+	}
+	if c.Registry != nil {
+		if err := c.Registry.Add(e); err != nil {
+			c.Error("registry write error: ", err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "registry.csv")
+	runs := [][]ManifestEntry{
+		{
+			{Key: "b", Size: 1, SHA256: "b1", ETag: "e1"},
+			{Key: "a", Size: 2, SHA256: "a1", ETag: "e2"},
+		},
+		{
+			// Overwritten by a later run.
+			{Key: "b", Size: 3, SHA256: "b2", ETag: "e3"},
+			{Key: "b", VersionID: "v1", Size: 4, SHA256: "b3", ETag: "e4"},
+		},
+	}
+	for _, entries := range runs {
+		r, err := OpenRegistry(fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			if err := r.Add(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.Open(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := ReadManifest(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4", len(entries))
+	}
+	got := LatestManifestEntries(entries)
+	want := []ManifestEntry{runs[0][1], runs[1][0], runs[1][1]}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
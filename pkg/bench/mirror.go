@@ -0,0 +1,258 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/generator"
+)
+
+// OpMirror is the operation type of a mirrored object.
+const OpMirror = "MIRROR"
+
+// Mirror benchmarks copying objects from one endpoint to another.
+// Objects are read from Client and Bucket and streamed to DestClient and DestBucket.
+type Mirror struct {
+	CreateObjects int
+	Collector     *Collector
+	objects       generator.Objects
+
+	// DestClient returns clients for the destination.
+	DestClient func() (cl *minio.Client, done func())
+	DestBucket string
+
+	// GetOpts are the source download options.
+	GetOpts minio.GetObjectOptions
+
+	// ReadWait is the total time spent waiting for source data.
+	// TransferTime is the total time spent transferring objects.
+	// Populated when Start returns.
+	ReadWait, TransferTime time.Duration
+
+	Common
+}
+
+// dest returns the common parameters of the destination.
+func (m *Mirror) dest() *Common {
+	c := m.Common
+	c.Client = m.DestClient
+	c.Bucket = m.DestBucket
+	c.Locking = false
+	return &c
+}
+
+// Prepare will create empty source and destination buckets
+// and upload a number of objects to the source.
+func (m *Mirror) Prepare(ctx context.Context) error {
+	if err := m.dest().createEmptyBucket(ctx); err != nil {
+		return err
+	}
+	if err := m.createEmptyBucket(ctx); err != nil {
+		return err
+	}
+	console.Eraseline()
+	console.Info("\rUploading ", m.CreateObjects, " objects to source")
+
+	var wg sync.WaitGroup
+	wg.Add(m.Concurrency)
+	m.Collector = m.collector()
+	obj := make(chan struct{}, m.CreateObjects)
+	for i := 0; i < m.CreateObjects; i++ {
+		obj <- struct{}{}
+	}
+	rcv := m.Collector.rcv
+	close(obj)
+	var groupErr error
+	var mu sync.Mutex
+
+	for i := 0; i < m.Concurrency; i++ {
+		go func(i int) {
+			defer wg.Done()
+			src := m.Source()
+			opts := m.PutOpts
+
+			for range obj {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				obj := src.Object()
+				if obj == nil {
+					return
+				}
+				client, cldone := m.clientFor(obj.Name)
+				op := Operation{
+					OpType:   http.MethodPut,
+					Thread:   uint16(i),
+					Size:     obj.Size,
+					File:     obj.Name,
+					ObjPerOp: 1,
+					Endpoint: client.EndpointURL().String(),
+				}
+				setObjectOpts(&opts, obj)
+				track := m.trackManifest(obj)
+				m.waitOp(ctx, m.Bucket, obj.Name)
+				op.Start = time.Now()
+				res, err := client.PutObject(ctx, m.Bucket, obj.Name, obj.Reader, obj.Size, opts)
+				op.End = time.Now()
+				if err == nil && res.Size != obj.Size {
+					err = fmt.Errorf("short upload. want: %d, got %d", obj.Size, res.Size)
+				}
+				if err != nil {
+					err := fmt.Errorf("upload error: %w", err)
+					m.Error(err)
+					mu.Lock()
+					if groupErr == nil {
+						groupErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				cldone()
+				mu.Lock()
+				track(res)
+				obj.Reader = nil
+				m.objects = append(m.objects, *obj)
+				m.prepareProgress(float64(len(m.objects)) / float64(m.CreateObjects))
+				mu.Unlock()
+				rcv <- op
+			}
+		}(i)
+	}
+	wg.Wait()
+	return groupErr
+}
+
+// waitReader records the time spent waiting for reads.
+type waitReader struct {
+	r         io.Reader
+	wait      time.Duration
+	firstByte *time.Time
+}
+
+func (w *waitReader) Read(p []byte) (n int, err error) {
+	t := time.Now()
+	n, err = w.r.Read(p)
+	now := time.Now()
+	w.wait += now.Sub(t)
+	if n > 0 && w.firstByte == nil {
+		w.firstByte = &now
+	}
+	return n, err
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+func (m *Mirror) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(m.Concurrency)
+	c := m.Collector
+	if m.AutoTermDur > 0 {
+		ctx = c.AutoTerm(ctx, OpMirror, m.AutoTermScale, autoTermCheck, autoTermSamples, m.AutoTermDur)
+	}
+	var readWait, transfer int64
+
+	// Operations in progress when ctx is done can finish.
+	nonTerm := m.opsContext(ctx)
+
+	for i := 0; i < m.Concurrency; i++ {
+		go func(i int) {
+			rng := m.indexRng(i)
+			rcv := c.Receiver()
+			defer wg.Done()
+			opc := m.opContexts(nonTerm)
+			defer opc.stop()
+			putOpts := m.PutOpts
+			done := ctx.Done()
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				obj := m.objects[rng.Intn(len(m.objects))]
+				client, cldone := m.clientFor(obj.Name)
+				dst, dstdone := m.DestClient()
+				op := Operation{
+					OpType:   OpMirror,
+					Thread:   uint16(i),
+					Size:     obj.Size,
+					File:     obj.Name,
+					ObjPerOp: 1,
+					Endpoint: client.EndpointURL().String(),
+				}
+				m.waitOp(ctx, m.Bucket, obj.Name)
+				op.Start = time.Now()
+				// The download and upload are a single operation.
+				opCtx := opc.next()
+				o, err := client.GetObject(opCtx, m.Bucket, obj.Name, m.GetOpts)
+				if err != nil {
+					m.Error("download error:", err)
+					op.Err = err.Error()
+					op.End = time.Now()
+					rcv <- op
+					cldone()
+					dstdone()
+					continue
+				}
+				wr := waitReader{r: o}
+				setObjectOpts(&putOpts, &obj)
+				res, err := m.backend(dst).PutObject(opCtx, m.DestBucket, obj.Name, &wr, obj.Size, putOpts)
+				op.End = time.Now()
+				op.FirstByte = wr.firstByte
+				if err != nil {
+					m.Error("mirror error:", err)
+					op.Err = err.Error()
+				}
+				if res.Size != obj.Size && op.Err == "" {
+					op.Err = fmt.Sprint("short upload. want:", obj.Size, ", got:", res.Size)
+					m.Error(op.Err)
+				}
+				atomic.AddInt64(&readWait, int64(wr.wait))
+				atomic.AddInt64(&transfer, int64(op.End.Sub(op.Start)))
+				rcv <- op
+				cldone()
+				dstdone()
+				o.Close()
+			}
+		}(i)
+	}
+	wg.Wait()
+	m.ReadWait = time.Duration(atomic.LoadInt64(&readWait))
+	m.TransferTime = time.Duration(atomic.LoadInt64(&transfer))
+	return c.Close(), nil
+}
+
+// Cleanup deletes everything uploaded to the source and destination buckets.
+func (m *Mirror) Cleanup(ctx context.Context) {
+	prefixes := m.objects.Prefixes()
+	m.deleteAllInBucket(ctx, prefixes...)
+	m.dest().deleteAllInBucket(ctx, prefixes...)
+}
@@ -25,6 +25,7 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"net/http"
+	"sort"
 	"sync"
 	"time"
 
@@ -49,9 +50,15 @@ type Mixed struct {
 type MixedDistribution struct {
 	// Operation -> distribution.
 	Distribution map[string]float64
-	ops          []string
-	objects      map[string]generator.Object
-	rng          *rand.Rand
+	// Deterministic seeds the operation order and object choices with Seed,
+	// otherwise a fixed order and random objects are used.
+	Deterministic bool
+	Seed          int64
+	ops           []string
+	objects       map[string]generator.Object
+	// keys contains the names of objects in deterministic mode.
+	keys sortedKeys
+	rng  *rand.Rand
 
 	current int
 	mu      sync.Mutex
@@ -70,13 +77,23 @@ func (m *MixedDistribution) Generate(allocObjs int) error {
 
 	const genOps = 1000
 	m.ops = make([]string, 0, genOps)
-	for op, dist := range m.Distribution {
-		add := int(0.5 + dist*genOps)
+	// Operations are added in sorted order, so the order only depends on the seed.
+	names := make([]string, 0, len(m.Distribution))
+	for op := range m.Distribution {
+		names = append(names, op)
+	}
+	sort.Strings(names)
+	for _, op := range names {
+		add := int(0.5 + m.Distribution[op]*genOps)
 		for i := 0; i < add; i++ {
 			m.ops = append(m.ops, op)
 		}
 	}
-	m.rng = rand.New(rand.NewSource(0xabad1dea))
+	seed := int64(0xabad1dea)
+	if m.Deterministic {
+		seed = m.Seed
+	}
+	m.rng = rand.New(rand.NewSource(seed))
 	m.rng.Shuffle(len(m.ops), func(i, j int) {
 		m.ops[i], m.ops[j] = m.ops[j], m.ops[i]
 	})
@@ -108,38 +125,94 @@ func (m *MixedDistribution) normalize() error {
 	return nil
 }
 
+// randomKey returns the name of a random object.
+// The lock must be held.
+func (m *MixedDistribution) randomKey() string {
+	if m.Deterministic {
+		// Map order differs between runs, so pick from the sorted names.
+		if len(m.keys) == 0 {
+			panic("ran out of objects")
+		}
+		return m.keys[m.rng.Intn(len(m.keys))]
+	}
+	// Use map randomness to select.
+	for k := range m.objects {
+		return k
+	}
+	panic("ran out of objects")
+}
+
 func (m *MixedDistribution) randomObj() (obj generator.Object, done func()) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	// Use map randomness to select.
-	for k, o := range m.objects {
-		delete(m.objects, k)
-		return o, func() {
-			m.mu.Lock()
-			m.objects[k] = obj
-			m.mu.Unlock()
-		}
+	k := m.randomKey()
+	o := m.objects[k]
+	m.delete(k)
+	return o, func() {
+		m.mu.Lock()
+		m.add(k, obj)
+		m.mu.Unlock()
 	}
-	panic("ran out of objects")
 }
 
 func (m *MixedDistribution) deleteRandomObj() generator.Object {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	// Use map randomness to select.
-	for k, o := range m.objects {
-		delete(m.objects, k)
-		return o
-	}
-	panic("ran out of objects")
+	k := m.randomKey()
+	o := m.objects[k]
+	m.delete(k)
+	return o
 }
 
 func (m *MixedDistribution) addObj(o generator.Object) {
 	m.mu.Lock()
-	m.objects[o.Name] = o
+	m.add(o.Name, o)
 	m.mu.Unlock()
 }
 
+// add adds the object with name k.
+// The lock must be held.
+func (m *MixedDistribution) add(k string, o generator.Object) {
+	m.objects[k] = o
+	if m.Deterministic {
+		m.keys.add(k)
+	}
+}
+
+// delete removes the object with name k.
+// The lock must be held.
+func (m *MixedDistribution) delete(k string) {
+	delete(m.objects, k)
+	if m.Deterministic {
+		m.keys.remove(k)
+	}
+}
+
+// sortedKeys is a sorted set of object names.
+type sortedKeys []string
+
+// add inserts k if it is not in the set.
+func (s *sortedKeys) add(k string) {
+	keys := *s
+	i := sort.SearchStrings(keys, k)
+	if i < len(keys) && keys[i] == k {
+		return
+	}
+	keys = append(keys, "")
+	copy(keys[i+1:], keys[i:])
+	keys[i] = k
+	*s = keys
+}
+
+// remove removes k if it is in the set.
+func (s *sortedKeys) remove(k string) {
+	keys := *s
+	i := sort.SearchStrings(keys, k)
+	if i < len(keys) && keys[i] == k {
+		*s = append(keys[:i], keys[i+1:]...)
+	}
+}
+
 func (m *MixedDistribution) getOp() string {
 	m.mu.Lock()
 	op := m.ops[m.current]
@@ -162,7 +235,7 @@ func (g *Mixed) Prepare(ctx context.Context) error {
 	console.Info("\rUploading ", g.CreateObjects, " objects of ", src.String())
 	var wg sync.WaitGroup
 	wg.Add(g.Concurrency)
-	g.Collector = NewCollector()
+	g.Collector = g.collector()
 	obj := make(chan struct{}, g.CreateObjects)
 	for i := 0; i < g.CreateObjects; i++ {
 		obj <- struct{}{}
@@ -184,8 +257,12 @@ func (g *Mixed) Prepare(ctx context.Context) error {
 				default:
 				}
 				obj := src.Object()
-				client, clDone := g.Client()
-				opts.ContentType = obj.ContentType
+				if obj == nil {
+					return
+				}
+				client, clDone := g.clientFor(obj.Name)
+				setObjectOpts(&opts, obj)
+				track := g.trackManifest(obj)
 				res, err := client.PutObject(ctx, g.Bucket, obj.Name, obj.Reader, obj.Size, opts)
 				if err != nil {
 					err := fmt.Errorf("upload error: %w", err)
@@ -209,6 +286,7 @@ func (g *Mixed) Prepare(ctx context.Context) error {
 					return
 				}
 				clDone()
+				track(res)
 				obj.Reader = nil
 				g.Dist.addObj(*obj)
 				g.prepareProgress(float64(len(g.Dist.objects)) / float64(g.CreateObjects))
@@ -228,13 +306,15 @@ func (g *Mixed) Start(ctx context.Context, wait chan struct{}) (Operations, erro
 	if g.AutoTermDur > 0 {
 		ctx = c.AutoTerm(ctx, "", g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
 	}
-	// Non-terminating context.
-	nonTerm := context.Background()
+	// Operations in progress when ctx is done can finish.
+	nonTerm := g.opsContext(ctx)
 
 	for i := 0; i < g.Concurrency; i++ {
 		go func(i int) {
 			rcv := c.Receiver()
 			defer wg.Done()
+			opc := g.opContexts(nonTerm)
+			defer opc.stop()
 			done := ctx.Done()
 			src := g.Source()
 			putOpts := g.PutOpts
@@ -253,7 +333,7 @@ func (g *Mixed) Start(ctx context.Context, wait chan struct{}) (Operations, erro
 				case http.MethodGet:
 					fbr := firstByteRecorder{}
 					obj, objDone := g.Dist.randomObj()
-					client, clDone := g.Client()
+					client, clDone := g.clientFor(obj.Name)
 					op := Operation{
 						OpType:   operation,
 						Thread:   uint16(i),
@@ -262,10 +342,11 @@ func (g *Mixed) Start(ctx context.Context, wait chan struct{}) (Operations, erro
 						ObjPerOp: 1,
 						Endpoint: client.EndpointURL().String(),
 					}
+					g.waitOp(ctx, g.Bucket, obj.Name)
 					op.Start = time.Now()
 					var err error
 					getOpts.VersionID = obj.VersionID
-					o, err := client.GetObject(nonTerm, g.Bucket, obj.Name, getOpts)
+					o, err := client.GetObject(opc.next(), g.Bucket, obj.Name, getOpts)
 					fbr.r = o
 					if err != nil {
 						g.Error("download error:", err)
@@ -294,8 +375,11 @@ func (g *Mixed) Start(ctx context.Context, wait chan struct{}) (Operations, erro
 
 				case http.MethodPut:
 					obj := src.Object()
-					putOpts.ContentType = obj.ContentType
-					client, clDone := g.Client()
+					if obj == nil {
+						continue
+					}
+					setObjectOpts(&putOpts, obj)
+					client, clDone := g.clientFor(obj.Name)
 					op := Operation{
 						OpType:   operation,
 						Thread:   uint16(i),
@@ -304,8 +388,9 @@ func (g *Mixed) Start(ctx context.Context, wait chan struct{}) (Operations, erro
 						ObjPerOp: 1,
 						Endpoint: client.EndpointURL().String(),
 					}
+					g.waitOp(ctx, g.Bucket, obj.Name)
 					op.Start = time.Now()
-					res, err := client.PutObject(nonTerm, g.Bucket, obj.Name, obj.Reader, obj.Size, putOpts)
+					res, err := client.PutObject(opc.next(), g.Bucket, obj.Name, obj.Reader, obj.Size, putOpts)
 					op.End = time.Now()
 					if err != nil {
 						g.Error("upload error:", err)
@@ -320,14 +405,15 @@ func (g *Mixed) Start(ctx context.Context, wait chan struct{}) (Operations, erro
 						}
 						g.Error(err)
 					}
+					op.Size = uploadedSize(obj, obj.Size)
 					clDone()
 					if op.Err == "" {
 						g.Dist.addObj(*obj)
 					}
 					rcv <- op
 				case http.MethodDelete:
-					client, clDone := g.Client()
 					obj := g.Dist.deleteRandomObj()
+					client, clDone := g.clientFor(obj.Name)
 					op := Operation{
 						OpType:   operation,
 						Thread:   uint16(i),
@@ -336,8 +422,9 @@ func (g *Mixed) Start(ctx context.Context, wait chan struct{}) (Operations, erro
 						ObjPerOp: 1,
 						Endpoint: client.EndpointURL().String(),
 					}
+					g.waitOp(ctx, g.Bucket, obj.Name)
 					op.Start = time.Now()
-					err := client.RemoveObject(nonTerm, g.Bucket, obj.Name, minio.RemoveObjectOptions{VersionID: obj.VersionID})
+					err := client.RemoveObject(opc.next(), g.Bucket, obj.Name, minio.RemoveObjectOptions{VersionID: obj.VersionID})
 					op.End = time.Now()
 					clDone()
 					if err != nil {
@@ -347,7 +434,7 @@ func (g *Mixed) Start(ctx context.Context, wait chan struct{}) (Operations, erro
 					rcv <- op
 				case "STAT":
 					obj, objDone := g.Dist.randomObj()
-					client, clDone := g.Client()
+					client, clDone := g.metaClient()
 					op := Operation{
 						OpType:   operation,
 						Thread:   uint16(i),
@@ -356,9 +443,10 @@ func (g *Mixed) Start(ctx context.Context, wait chan struct{}) (Operations, erro
 						ObjPerOp: 1,
 						Endpoint: client.EndpointURL().String(),
 					}
+					g.waitOp(ctx, g.Bucket, obj.Name)
 					op.Start = time.Now()
 					var err error
-					objI, err := client.StatObject(nonTerm, g.Bucket, obj.Name, statOpts)
+					objI, err := client.StatObject(opc.next(), g.Bucket, obj.Name, statOpts)
 					if err != nil {
 						g.Error("stat error: ", err)
 						op.Err = err.Error()
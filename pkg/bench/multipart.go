@@ -19,11 +19,12 @@ package bench
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"math/rand"
 	"net/http"
 	"strconv"
 	"sync"
@@ -56,10 +57,9 @@ func (g *Multipart) InitOnce(ctx context.Context) error {
 	console.Eraseline()
 	console.Info("\rCreating Object...")
 
-	cl, done := g.Client()
-	c := minio.Core{Client: cl}
+	cl, done := g.clientFor(g.ObjName)
 	defer done()
-	uploadID, err := c.NewMultipartUpload(ctx, g.Bucket, g.ObjName, g.PutOpts)
+	uploadID, err := cl.NewMultipartUpload(ctx, g.Bucket, g.ObjName, g.PutOpts)
 	if err != nil {
 		return err
 	}
@@ -80,7 +80,7 @@ func (g *Multipart) Prepare(ctx context.Context) error {
 
 	var wg sync.WaitGroup
 	wg.Add(g.Concurrency)
-	g.Collector = NewCollector()
+	g.Collector = g.collector()
 	obj := make(chan int, g.CreateParts)
 	for i := 0; i < g.CreateParts; i++ {
 		obj <- i + g.PartStart
@@ -108,9 +108,11 @@ func (g *Multipart) Prepare(ctx context.Context) error {
 				name := g.ObjName
 				// New input for each version
 				obj := src.Object()
+				if obj == nil {
+					return
+				}
 				obj.Name = name
-				client, cldone := g.Client()
-				core := minio.Core{Client: client}
+				client, cldone := g.clientFor(obj.Name)
 				op := Operation{
 					OpType:   http.MethodPut,
 					Thread:   uint16(i),
@@ -119,9 +121,10 @@ func (g *Multipart) Prepare(ctx context.Context) error {
 					ObjPerOp: 1,
 					Endpoint: client.EndpointURL().String(),
 				}
-				opts.ContentType = obj.ContentType
+				setObjectOpts(&opts, obj)
+				g.waitOp(ctx, g.Bucket, obj.Name)
 				op.Start = time.Now()
-				res, err := core.PutObjectPart(ctx, g.Bucket, obj.Name, g.UploadID, partN, obj.Reader, obj.Size, "", "", g.Common.PutOpts.ServerSideEncryption)
+				res, err := client.PutObjectPart(ctx, g.Bucket, obj.Name, g.UploadID, partN, obj.Reader, obj.Size, g.Common.PutOpts.ServerSideEncryption)
 				op.End = time.Now()
 				if err != nil {
 					err := fmt.Errorf("upload error: %w", err)
@@ -145,6 +148,7 @@ func (g *Multipart) Prepare(ctx context.Context) error {
 					return
 				}
 				cldone()
+				g.countPrepared(res.Size)
 				mu.Lock()
 				obj.Reader = nil
 				g.objects = append(g.objects, *obj)
@@ -160,8 +164,7 @@ func (g *Multipart) Prepare(ctx context.Context) error {
 }
 
 func (g *Multipart) AfterPrepare(ctx context.Context) error {
-	cl, done := g.Client()
-	c := minio.Core{Client: cl}
+	cl, done := g.clientFor(g.ObjName)
 	defer done()
 	var parts []minio.CompletePart
 	i := 1
@@ -175,8 +178,23 @@ func (g *Multipart) AfterPrepare(ctx context.Context) error {
 	}
 	console.Eraseline()
 	console.Infof("\rCompleting Object with %d parts...", len(parts))
-	_, err := c.CompleteMultipartUpload(ctx, g.Bucket, g.ObjName, g.UploadID, parts, g.PutOpts)
-	return err
+	etag, err := cl.CompleteMultipartUpload(ctx, g.Bucket, g.ObjName, g.UploadID, parts, g.PutOpts)
+	if err != nil || !g.recordsManifest() {
+		return err
+	}
+	// The parts are uploaded concurrently, so the object is downloaded to hash it.
+	o, err := cl.GetObject(ctx, g.Bucket, g.ObjName, g.GetOpts)
+	if err != nil {
+		return err
+	}
+	defer o.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, o)
+	if err != nil {
+		return err
+	}
+	g.addManifest(g.ObjName, hex.EncodeToString(h.Sum(nil)), minio.UploadInfo{Key: g.ObjName, ETag: etag, Size: n})
+	return nil
 }
 
 // Start will execute the main benchmark.
@@ -189,14 +207,16 @@ func (g *Multipart) Start(ctx context.Context, wait chan struct{}) (Operations,
 		ctx = c.AutoTerm(ctx, http.MethodGet, g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
 	}
 
-	// Non-terminating context.
-	nonTerm := context.Background()
+	// Operations in progress when ctx is done can finish.
+	nonTerm := g.opsContext(ctx)
 
 	for i := 0; i < g.Concurrency; i++ {
 		go func(i int) {
-			rng := rand.New(rand.NewSource(int64(i)))
+			rng := g.indexRng(i)
 			rcv := c.Receiver()
 			defer wg.Done()
+			opc := g.opContexts(nonTerm)
+			defer opc.stop()
 			opts := g.GetOpts
 			done := ctx.Done()
 
@@ -211,7 +231,7 @@ func (g *Multipart) Start(ctx context.Context, wait chan struct{}) (Operations,
 				part := rng.Intn(len(g.objects))
 				obj := g.objects[part]
 				part += g.PartStart
-				client, cldone := g.Client()
+				client, cldone := g.clientFor(obj.Name)
 				op := Operation{
 					OpType:   http.MethodGet,
 					Thread:   uint16(i),
@@ -220,9 +240,10 @@ func (g *Multipart) Start(ctx context.Context, wait chan struct{}) (Operations,
 					ObjPerOp: 1,
 					Endpoint: client.EndpointURL().String(),
 				}
+				g.waitOp(ctx, g.Bucket, obj.Name)
 				op.Start = time.Now()
 				opts.PartNumber = part
-				o, err := client.GetObject(nonTerm, g.Bucket, obj.Name, opts)
+				o, err := client.GetObject(opc.next(), g.Bucket, obj.Name, opts)
 				if err != nil {
 					g.Error("download error:", err)
 					op.Err = err.Error()
@@ -0,0 +1,101 @@
+/*
+ * Warp (C) 2019-2020 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"time"
+)
+
+// opsContext returns the context of operations of a run with the context ctx.
+// It has the values of ctx, but is only done when the benchmark is aborted,
+// so operations in progress when ctx is done can finish and be recorded.
+func (c *Common) opsContext(ctx context.Context) context.Context {
+	abort := c.Abort
+	if abort == nil {
+		abort = context.Background()
+	}
+	return abortContext{Context: ctx, abort: abort}
+}
+
+// abortContext has the values of the embedded context,
+// and the deadline and cancellation of abort.
+type abortContext struct {
+	context.Context
+	abort context.Context
+}
+
+func (a abortContext) Deadline() (time.Time, bool) {
+	return a.abort.Deadline()
+}
+
+func (a abortContext) Done() <-chan struct{} {
+	return a.abort.Done()
+}
+
+func (a abortContext) Err() error {
+	return a.abort.Err()
+}
+
+// waitOp waits before an operation on the object in the bucket is timed.
+// Operations wait for a token of the rate limiter,
+// and operations on prefixes throttled by the server are delayed,
+// so the waiting is not included in the duration of the operation.
+func (c *Common) waitOp(ctx context.Context, bucket, object string) {
+	if c.Limiter != nil {
+		c.Limiter.Wait(ctx)
+	}
+	if c.Backoff != nil {
+		c.Backoff.Wait(ctx, bucket, object)
+	}
+}
+
+// opContexts returns the contexts of operations of a thread.
+// Operations have the timeout of the benchmark, if any.
+// Call stop when the thread is done.
+func (c *Common) opContexts(ops context.Context) *opContexts {
+	return &opContexts{base: ops, timeout: c.OpTimeout}
+}
+
+// opContexts gives each operation of a thread a context with a timeout.
+type opContexts struct {
+	base    context.Context
+	timeout time.Duration
+	cancel  context.CancelFunc
+}
+
+// next returns the context of the next operation of the thread.
+// The context of the previous operation is canceled,
+// so it must not be used after next is called again.
+func (o *opContexts) next() context.Context {
+	o.stop()
+	if o.timeout <= 0 {
+		return o.base
+	}
+	var ctx context.Context
+	ctx, o.cancel = context.WithTimeout(o.base, o.timeout)
+	return ctx
+}
+
+// stop cancels the context of the last operation.
+func (o *opContexts) stop() {
+	if o.cancel != nil {
+		o.cancel()
+		o.cancel = nil
+	}
+}
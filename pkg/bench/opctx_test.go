@@ -0,0 +1,87 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type opctxKey struct{}
+
+func TestOpsContext(t *testing.T) {
+	abort, doAbort := context.WithCancel(context.Background())
+	c := Common{Abort: abort}
+	run, stop := context.WithCancel(context.WithValue(context.Background(), opctxKey{}, "v"))
+	ops := c.opsContext(run)
+	if ops.Value(opctxKey{}) != "v" {
+		t.Fatal("values of the run context missing")
+	}
+	stop()
+	if ops.Err() != nil {
+		t.Fatal("operations canceled when the run ended")
+	}
+	doAbort()
+	select {
+	case <-ops.Done():
+	case <-time.After(time.Second):
+		t.Fatal("operations not canceled on abort")
+	}
+	if !errors.Is(ops.Err(), context.Canceled) {
+		t.Fatalf("got %v, want canceled", ops.Err())
+	}
+
+	// Without an abort context operations are never canceled.
+	if (&Common{}).opsContext(run).Done() != nil {
+		t.Fatal("want no cancellation")
+	}
+}
+
+func TestOpContexts(t *testing.T) {
+	c := Common{OpTimeout: 20 * time.Millisecond}
+	opc := c.opContexts(context.Background())
+	first := opc.next()
+	if _, ok := first.Deadline(); !ok {
+		t.Fatal("no deadline")
+	}
+	second := opc.next()
+	if first.Err() == nil {
+		t.Fatal("previous operation not canceled")
+	}
+	select {
+	case <-second.Done():
+	case <-time.After(time.Second):
+		t.Fatal("operation did not time out")
+	}
+	if !errors.Is(second.Err(), context.DeadlineExceeded) {
+		t.Fatalf("got %v, want deadline exceeded", second.Err())
+	}
+	third := opc.next()
+	opc.stop()
+	if third.Err() == nil {
+		t.Fatal("last operation not canceled on stop")
+	}
+
+	// Without a timeout the base context is used.
+	base := context.Background()
+	if (&Common{}).opContexts(base).next() != base {
+		t.Fatal("want base context")
+	}
+}
@@ -19,11 +19,14 @@ package bench
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"math"
+	"math/bits"
 	"sort"
 	"strconv"
 	"strings"
@@ -48,11 +51,14 @@ type Operation struct {
 	Thread    uint16     `json:"thread"`
 	ClientID  string     `json:"client_id"`
 	Endpoint  string     `json:"endpoint"`
+	Latency   *Latency   `json:"latency,omitempty"`
 }
 
 type Collector struct {
 	ops Operations
-	// The mutex protects the ops above.
+	// keep is set if operations are kept in ops.
+	keep bool
+	// The mutex protects the ops and keep above.
 	// Once ops have been added, they should no longer be modified.
 	opsMu sync.Mutex
 	rcv   chan Operation
@@ -60,17 +66,43 @@ type Collector struct {
 }
 
 func NewCollector() *Collector {
+	return newCollector(nil, nil, nil)
+}
+
+// newCollector returns a collector that also adds operations to live if not nil.
+// The time spent recording is added to limits if not nil.
+// If output is not nil operations are sent to it instead of being kept,
+// unless they are needed for auto termination.
+func newCollector(live *LiveStats, limits *ClientLimits, output func(Operation)) *Collector {
 	r := &Collector{
-		ops: make(Operations, 0, 10000),
-		rcv: make(chan Operation, 1000),
+		rcv:  make(chan Operation, 1000),
+		keep: output == nil,
+	}
+	if r.keep {
+		r.ops = make(Operations, 0, 10000)
 	}
 	r.rcvWg.Add(1)
 	go func() {
 		defer r.rcvWg.Done()
 		for op := range r.rcv {
+			var t time.Time
+			if limits != nil {
+				t = time.Now()
+			}
 			r.opsMu.Lock()
-			r.ops = append(r.ops, op)
+			if r.keep {
+				r.ops = append(r.ops, op)
+			}
 			r.opsMu.Unlock()
+			if output != nil {
+				output(op)
+			}
+			if live != nil {
+				live.add(op)
+			}
+			if limits != nil {
+				limits.recorded(time.Since(t), len(r.rcv) >= cap(r.rcv)*9/10)
+			}
 		}
 	}()
 	return r
@@ -87,6 +119,10 @@ func (c *Collector) AutoTerm(ctx context.Context, op string, threshold float64,
 	if splitInto == 0 {
 		panic("splitInto == 0 ")
 	}
+	// Operations from now on are needed for the check.
+	c.opsMu.Lock()
+	c.keep = true
+	c.opsMu.Unlock()
 	ctx, cancel := context.WithCancel(ctx)
 	go func() {
 		defer cancel()
@@ -326,6 +362,24 @@ func (o Operations) SortByDuration() {
 	})
 }
 
+// Slowest returns up to n operations with the longest duration, slowest first.
+// Annotations are not included. The operations are not modified.
+func (o Operations) Slowest(n int) Operations {
+	res := make(Operations, 0, len(o))
+	for _, op := range o {
+		if op.OpType != OpAnnotation {
+			res = append(res, op)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].End.Sub(res[i].Start) > res[j].End.Sub(res[j].Start)
+	})
+	if len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
 // SortByThroughput will sort the operations by throughput.
 // Fastest operations first.
 func (o Operations) SortByThroughput() {
@@ -565,6 +619,44 @@ func (o Operations) MinMaxSize() (min, max int64) {
 	return min, max
 }
 
+// SizeBucket contains the number of operations with a size in a range.
+type SizeBucket struct {
+	// Min is the smallest size in the bucket.
+	Min int64 `json:"min"`
+	// Max is the smallest size above the bucket.
+	Max   int64 `json:"max"`
+	Count int   `json:"count"`
+}
+
+// SizeHistogram returns the number of successful operations of each size,
+// in power of two sized buckets from the smallest to the biggest size.
+func (o Operations) SizeHistogram() []SizeBucket {
+	var counts [64]int
+	first, last := len(counts), -1
+	for _, op := range o {
+		if op.Err != "" || op.OpType == OpAnnotation {
+			continue
+		}
+		idx := bits.Len64(uint64(op.Size))
+		counts[idx]++
+		if idx < first {
+			first = idx
+		}
+		if idx > last {
+			last = idx
+		}
+	}
+	var res []SizeBucket
+	for i := first; i <= last; i++ {
+		b := SizeBucket{Max: 1 << i, Count: counts[i]}
+		if i > 0 {
+			b.Min = 1 << (i - 1)
+		}
+		res = append(res, b)
+	}
+	return res
+}
+
 // AvgSize returns the average operation size.
 func (o Operations) AvgSize() int64 {
 	if len(o) == 0 {
@@ -830,6 +922,17 @@ func (o Operations) OffsetThreads(n uint16) uint16 {
 	return maxT + 1
 }
 
+// PipelineThreads assigns the operations of every n consecutive threads to one thread,
+// so operations kept in flight by a pipelined worker are reported as the worker.
+func (o Operations) PipelineThreads(n int) {
+	if n <= 1 {
+		return
+	}
+	for i := range o {
+		o[i].Thread = uint16(int(o[i].Thread) / n)
+	}
+}
+
 // Hosts returns the number of servers.
 func (o Operations) Hosts() int {
 	if len(o) == 0 {
@@ -976,43 +1079,168 @@ func (o Operations) FilterErrors() Operations {
 // The comment, if any, is written at the end of the file, each line prefixed with '# '.
 func (o Operations) CSV(w io.Writer, comment string) error {
 	bw := bufio.NewWriter(w)
-	_, err := bw.WriteString("idx\tthread\top\tclient_id\tn_objects\tbytes\tendpoint\tfile\terror\tstart\tfirst_byte\tend\tduration_ns\n")
-	if err != nil {
+	if _, err := bw.WriteString(csvHeader); err != nil {
 		return err
 	}
 	for i, op := range o {
-		var ttfb string
-		if op.FirstByte != nil {
-			ttfb = op.FirstByte.Format(time.RFC3339Nano)
+		if err := writeCSVOp(bw, i, op); err != nil {
+			return err
 		}
-		_, err := fmt.Fprintf(bw, "%d\t%d\t%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n", i, op.Thread, op.OpType, op.ClientID, op.ObjPerOp, op.Size, csvEscapeString(op.Endpoint), op.File, csvEscapeString(op.Err), op.Start.Format(time.RFC3339Nano), ttfb, op.End.Format(time.RFC3339Nano), op.End.Sub(op.Start)/time.Nanosecond)
-		if err != nil {
+	}
+	if err := writeCSVComment(bw, comment); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+const csvHeader = "idx\tthread\top\tclient_id\tn_objects\tbytes\tendpoint\tfile\terror\tstart\tfirst_byte\tend\tduration_ns\tnetwork_ns\tserver_ns\tserver_timing\trequest_id\n"
+
+// writeCSVOp writes the operation as a CSV line with index i.
+func writeCSVOp(w io.Writer, i int, op Operation) error {
+	var ttfb string
+	if op.FirstByte != nil {
+		ttfb = op.FirstByte.Format(time.RFC3339Nano)
+	}
+	var network, server, timed, reqID string
+	if op.Latency != nil {
+		network = strconv.FormatInt(int64(op.Latency.Network), 10)
+		server = strconv.FormatInt(int64(op.Latency.Server), 10)
+		if op.Latency.ServerTiming {
+			timed = "1"
+		}
+		reqID = csvEscapeString(op.Latency.RequestID)
+	}
+	_, err := fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n", i, op.Thread, op.OpType, op.ClientID, op.ObjPerOp, op.Size, csvEscapeString(op.Endpoint), op.File, csvEscapeString(op.Err), op.Start.Format(time.RFC3339Nano), ttfb, op.End.Format(time.RFC3339Nano), op.End.Sub(op.Start)/time.Nanosecond, network, server, timed, reqID)
+	return err
+}
+
+// writeCSVComment writes each line of the comment as a CSV comment.
+func writeCSVComment(w io.Writer, comment string) error {
+	if len(comment) == 0 {
+		return nil
+	}
+	for _, txt := range strings.Split(comment, "\n") {
+		if _, err := io.WriteString(w, "# "+txt+"\n"); err != nil {
 			return err
 		}
 	}
-	if len(comment) > 0 {
-		lines := strings.Split(comment, "\n")
-		for _, txt := range lines {
-			_, err := bw.WriteString("# " + txt + "\n")
-			if err != nil {
-				return err
-			}
+	return nil
+}
+
+// CSVWriter writes operations as CSV as they are added,
+// so they do not have to be kept in memory.
+// Operations are written in the order they are added.
+type CSVWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf bytes.Buffer
+	n   int
+	err error
+}
+
+// NewCSVWriter writes the CSV header to w and returns a writer for operations.
+func NewCSVWriter(w io.Writer) (*CSVWriter, error) {
+	if _, err := io.WriteString(w, csvHeader); err != nil {
+		return nil, err
+	}
+	return &CSVWriter{w: w}, nil
+}
+
+// Write an operation.
+// The first error is returned for this and all later writes.
+func (c *CSVWriter) Write(op Operation) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	c.buf.Reset()
+	writeCSVOp(&c.buf, c.n, op)
+	c.n++
+	_, c.err = c.w.Write(c.buf.Bytes())
+	return c.err
+}
+
+// Close writes the comment after the operations.
+// The underlying writer is not closed.
+func (c *CSVWriter) Close(comment string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	c.err = writeCSVComment(c.w, comment)
+	if c.err == nil {
+		// Writes after close fail.
+		c.err = errors.New("csv writer closed")
+		return nil
+	}
+	return c.err
+}
+
+// csvCommentReader passes data through and records the comments
+// written at the end of benchmark data.
+type csvCommentReader struct {
+	r         io.Reader
+	lastNL    bool
+	inTrailer bool
+	trailer   []byte
+}
+
+func (c *csvCommentReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	b := p[:n]
+	if !c.inTrailer && n > 0 {
+		idx := -1
+		if c.lastNL && b[0] == '#' {
+			idx = 0
+		} else if i := bytes.Index(b, []byte("\n#")); i >= 0 {
+			idx = i + 1
+		}
+		c.lastNL = b[n-1] == '\n'
+		if idx < 0 {
+			return n, err
 		}
+		c.inTrailer = true
+		b = b[idx:]
 	}
+	if c.inTrailer {
+		c.trailer = append(c.trailer, b...)
+	}
+	return n, err
+}
 
-	return bw.Flush()
+// comments returns the comment lines read without the '# ' prefix.
+func (c *csvCommentReader) comments() []string {
+	var res []string
+	for _, line := range strings.Split(string(c.trailer), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "#") {
+			res = append(res, strings.TrimPrefix(line[1:], " "))
+		}
+	}
+	return res
 }
 
 // OperationsFromCSV will load operations from CSV.
 func OperationsFromCSV(r io.Reader, analyzeOnly bool, offset, limit int, log func(msg string, v ...interface{})) (Operations, error) {
+	ops, _, err := OperationsFromCSVComments(r, analyzeOnly, offset, limit, log)
+	return ops, err
+}
+
+// OperationsFromCSVComments will load operations from CSV
+// and return the comments at the end of the data.
+// If a limit is given comments may not be read.
+func OperationsFromCSVComments(r io.Reader, analyzeOnly bool, offset, limit int, log func(msg string, v ...interface{})) (Operations, []string, error) {
 	var ops Operations
-	cr := csv.NewReader(r)
+	comments := &csvCommentReader{r: r}
+	cr := csv.NewReader(comments)
 	cr.Comma = '\t'
 	cr.ReuseRecord = true
 	cr.Comment = '#'
 	header, err := cr.Read()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	fieldIdx := make(map[string]int)
 	for i, s := range header {
@@ -1053,7 +1281,7 @@ func OperationsFromCSV(r io.Reader, analyzeOnly bool, offset, limit int, log fun
 			break
 		}
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if len(values) == 0 {
 			continue
@@ -1064,31 +1292,31 @@ func OperationsFromCSV(r io.Reader, analyzeOnly bool, offset, limit int, log fun
 		}
 		start, err := time.Parse(time.RFC3339Nano, values[fieldIdx["start"]])
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		var ttfb *time.Time
 		if fb := values[fieldIdx["first_byte"]]; fb != "" {
 			t, err := time.Parse(time.RFC3339Nano, fb)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			ttfb = &t
 		}
 		end, err := time.Parse(time.RFC3339Nano, values[fieldIdx["end"]])
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		size, err := strconv.ParseInt(values[fieldIdx["bytes"]], 10, 64)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		thread, err := strconv.ParseUint(values[fieldIdx["thread"]], 10, 16)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		objs, err := strconv.ParseInt(values[fieldIdx["n_objects"]], 10, 64)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		var endpoint, clientID string
 		if idx, ok := fieldIdx["endpoint"]; ok {
@@ -1097,7 +1325,30 @@ func OperationsFromCSV(r io.Reader, analyzeOnly bool, offset, limit int, log fun
 		if idx, ok := fieldIdx["client_id"]; ok {
 			clientID = values[idx]
 		}
-		file := fileMap(values[fieldIdx["file"]])
+		var latency *Latency
+		if idx, ok := fieldIdx["network_ns"]; ok && values[idx] != "" {
+			network, err := strconv.ParseInt(values[idx], 10, 64)
+			if err != nil {
+				return nil, nil, err
+			}
+			server, err := strconv.ParseInt(values[fieldIdx["server_ns"]], 10, 64)
+			if err != nil {
+				return nil, nil, err
+			}
+			latency = &Latency{
+				Network:      time.Duration(network),
+				Server:       time.Duration(server),
+				ServerTiming: values[fieldIdx["server_timing"]] == "1",
+			}
+			if idx, ok := fieldIdx["request_id"]; ok {
+				latency.RequestID = values[idx]
+			}
+		}
+		file := values[fieldIdx["file"]]
+		// Annotations keep their label.
+		if values[fieldIdx["op"]] != OpAnnotation {
+			file = fileMap(file)
+		}
 
 		ops = append(ops, Operation{
 			OpType:    values[fieldIdx["op"]],
@@ -1111,6 +1362,7 @@ func OperationsFromCSV(r io.Reader, analyzeOnly bool, offset, limit int, log fun
 			Thread:    uint16(thread),
 			Endpoint:  endpoint,
 			ClientID:  getClient(clientID),
+			Latency:   latency,
 		})
 		if log != nil && len(ops)%1000000 == 0 {
 			console.Eraseline()
@@ -1124,5 +1376,35 @@ func OperationsFromCSV(r io.Reader, analyzeOnly bool, offset, limit int, log fun
 		console.Eraseline()
 		log("\r%d operations loaded... Done!\n", len(ops))
 	}
-	return ops, nil
+	// Operations written while the benchmark was running are in the order they finished.
+	ops.SortByStartTime()
+	return ops, comments.comments(), nil
+}
+
+// Operation planes.
+const (
+	// PlaneControl contains bucket and metadata operations.
+	PlaneControl = "control"
+	// PlaneData contains object data operations.
+	PlaneData = "data"
+)
+
+// OpPlane returns the plane of the operation type.
+func OpPlane(opType string) string {
+	switch {
+	case opType == "LIST", opType == "STAT",
+		strings.HasPrefix(opType, "LIST@"), strings.HasPrefix(opType, "STAT@"):
+		return PlaneControl
+	}
+	return PlaneData
+}
+
+// ByPlane returns the operations split by plane.
+func (o Operations) ByPlane() map[string]Operations {
+	res := make(map[string]Operations, 2)
+	for _, op := range o {
+		p := OpPlane(op.OpType)
+		res[p] = append(res[p], op)
+	}
+	return res
 }
@@ -0,0 +1,173 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// PauseLabel is the label of annotations of windows where the benchmark was paused.
+const PauseLabel = "paused"
+
+// Pauser stops operations from starting while a benchmark is paused.
+// Operations that have started when pausing are finished.
+type Pauser struct {
+	mu sync.Mutex
+	// resume is closed when resuming. nil when not paused.
+	resume chan struct{}
+}
+
+// NewPauser returns a pauser that is not paused.
+func NewPauser() *Pauser {
+	return &Pauser{}
+}
+
+// Pause stops new operations from starting.
+// False is returned if already paused.
+func (p *Pauser) Pause() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resume != nil {
+		return false
+	}
+	p.resume = make(chan struct{})
+	return true
+}
+
+// Resume lets waiting and new operations start.
+// False is returned if not paused.
+func (p *Pauser) Resume() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resume == nil {
+		return false
+	}
+	close(p.resume)
+	p.resume = nil
+	return true
+}
+
+// Wait blocks while paused or until the context is canceled.
+func (p *Pauser) Wait(ctx context.Context) error {
+	p.mu.Lock()
+	resume := p.resume
+	p.mu.Unlock()
+	if resume == nil {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-resume:
+		return nil
+	}
+}
+
+// Pausable returns a client function that waits while paused before returning a client.
+// When ctx is canceled clients are returned without waiting.
+func (p *Pauser) Pausable(ctx context.Context, client func() (*minio.Client, func())) func() (*minio.Client, func()) {
+	return func() (*minio.Client, func()) {
+		p.Wait(ctx)
+		return client()
+	}
+}
+
+// PausableKey returns a client function that waits while paused before returning a client for the key.
+// When ctx is canceled clients are returned without waiting.
+func (p *Pauser) PausableKey(ctx context.Context, client func(key string) (*minio.Client, func())) func(key string) (*minio.Client, func()) {
+	return func(key string) (*minio.Client, func()) {
+		p.Wait(ctx)
+		return client(key)
+	}
+}
+
+// ExcludePauses returns the operations moved back in time by the time paused before they started,
+// so the time paused is excluded from throughput and time series.
+// Annotations with PauseLabel are the windows paused.
+// The durations of operations are kept. The operations are not modified.
+func (o Operations) ExcludePauses(annotations Operations) Operations {
+	var windows Operations
+	for _, a := range annotations {
+		if a.OpType == OpAnnotation && a.File == PauseLabel && a.End.After(a.Start) {
+			windows = append(windows, a)
+		}
+	}
+	if len(windows) == 0 {
+		return o
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start.Before(windows[j].Start) })
+	// Merge overlapping windows.
+	merged := windows[:1]
+	for _, w := range windows[1:] {
+		last := &merged[len(merged)-1]
+		if !w.Start.After(last.End) {
+			if w.End.After(last.End) {
+				last.End = w.End
+			}
+			continue
+		}
+		merged = append(merged, w)
+	}
+	// before[i] is the time paused before window i.
+	before := make([]time.Duration, len(merged))
+	for i := 1; i < len(merged); i++ {
+		before[i] = before[i-1] + merged[i-1].End.Sub(merged[i-1].Start)
+	}
+
+	res := make(Operations, len(o))
+	for i, op := range o {
+		// Index of the first window starting after the operation.
+		idx := sort.Search(len(merged), func(i int) bool { return merged[i].Start.After(op.Start) })
+		var shift time.Duration
+		if idx > 0 {
+			w := merged[idx-1]
+			shift = before[idx-1]
+			if op.Start.After(w.End) {
+				shift += w.End.Sub(w.Start)
+			} else {
+				shift += op.Start.Sub(w.Start)
+			}
+		}
+		if shift > 0 {
+			op.Start = op.Start.Add(-shift)
+			op.End = op.End.Add(-shift)
+			if op.FirstByte != nil {
+				fb := op.FirstByte.Add(-shift)
+				op.FirstByte = &fb
+			}
+		}
+		res[i] = op
+	}
+	return res
+}
+
+// PausedTime returns the total time of the windows with PauseLabel and the number of windows.
+func (o Operations) PausedTime() (total time.Duration, n int) {
+	for _, a := range o {
+		if a.OpType == OpAnnotation && a.File == PauseLabel {
+			total += a.End.Sub(a.Start)
+			n++
+		}
+	}
+	return total, n
+}
@@ -0,0 +1,99 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPauser(t *testing.T) {
+	p := NewPauser()
+	if err := p.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Pause() || p.Pause() {
+		t.Fatal("want first pause only")
+	}
+	done := make(chan error, 1)
+	go func() { done <- p.Wait(context.Background()) }()
+	select {
+	case <-done:
+		t.Fatal("wait returned while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if !p.Resume() || p.Resume() {
+		t.Fatal("want first resume only")
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	p.Pause()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.Wait(ctx); err == nil {
+		t.Fatal("want error when canceled")
+	}
+}
+
+func TestOperationsExcludePauses(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+	var ops Operations
+	for s := 0; s < 30; s++ {
+		fb := at(s).Add(time.Millisecond)
+		ops = append(ops, Operation{OpType: "GET", Start: at(s), FirstByte: &fb, End: at(s).Add(2 * time.Millisecond)})
+	}
+	pauses := Operations{
+		{OpType: OpAnnotation, File: PauseLabel, Start: at(10), End: at(15)},
+		{OpType: OpAnnotation, File: PauseLabel, Start: at(12), End: at(17)},
+		{OpType: OpAnnotation, File: "other", Start: at(20), End: at(25)},
+		{OpType: OpAnnotation, File: PauseLabel, Start: at(20), End: at(22)},
+	}
+	if d, n := pauses.PausedTime(); d != 12*time.Second || n != 3 {
+		t.Fatalf("paused %v, %d times", d, n)
+	}
+	got := ops.ExcludePauses(pauses)
+	for i, op := range got {
+		var shift int
+		switch {
+		case i >= 22:
+			shift = 9
+		case i >= 20:
+			shift = 7 + i - 20
+		case i >= 17:
+			shift = 7
+		case i >= 10:
+			shift = i - 10
+		}
+		if want := at(i - shift); !op.Start.Equal(want) {
+			t.Errorf("op %d: start %v, want %v", i, op.Start.Sub(start), want.Sub(start))
+		}
+		if op.Duration() != 2*time.Millisecond || op.FirstByte.Sub(op.Start) != time.Millisecond {
+			t.Errorf("op %d: duration changed", i)
+		}
+	}
+	if !ops[29].Start.Equal(at(29)) {
+		t.Error("operations modified")
+	}
+	if got := ops.ExcludePauses(nil); &got[0] != &ops[0] {
+		t.Error("want operations returned without pauses")
+	}
+}
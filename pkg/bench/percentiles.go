@@ -0,0 +1,158 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Percentiles is a set of percentiles from 0 to 100 included in reports.
+type Percentiles []float64
+
+// ParsePercentiles parses comma separated percentiles.
+// 'min' and 'max' can be used for 0 and 100.
+// Example: "50,90,99,99.9,max".
+func ParsePercentiles(s string) (Percentiles, error) {
+	var res Percentiles
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSuffix(strings.TrimSpace(v), "%")
+		switch strings.ToLower(v) {
+		case "min":
+			res = append(res, 0)
+			continue
+		case "max":
+			res = append(res, 100)
+			continue
+		}
+		p, err := strconv.ParseFloat(v, 64)
+		if err != nil || p < 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile %q", v)
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+// PercentileLabel returns the label of a percentile, "min" for 0 and "max" for 100.
+func PercentileLabel(p float64) string {
+	switch p {
+	case 0:
+		return "min"
+	case 100:
+		return "max"
+	}
+	return strconv.FormatFloat(p, 'f', -1, 64) + "%"
+}
+
+// PercentileDuration is the duration of operations at a percentile.
+type PercentileDuration struct {
+	Percentile float64       `json:"percentile"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// String returns a human readable version of the percentile.
+func (p PercentileDuration) String() string {
+	return fmt.Sprintf("%s: %v", PercentileLabel(p.Percentile), p.Duration.Round(time.Microsecond))
+}
+
+// DurationPercentiles returns the operation durations at the percentiles.
+// The operations are not modified.
+func (o Operations) DurationPercentiles(p Percentiles) []PercentileDuration {
+	if len(p) == 0 || len(o) == 0 {
+		return nil
+	}
+	durs := make([]time.Duration, len(o))
+	for i, op := range o {
+		durs[i] = op.Duration()
+	}
+	sort.Slice(durs, func(i, j int) bool { return durs[i] < durs[j] })
+	res := make([]PercentileDuration, len(p))
+	for i, pct := range p {
+		res[i] = PercentileDuration{Percentile: pct, Duration: durs[percentileIndex(len(durs), pct)]}
+	}
+	return res
+}
+
+// PercentileThroughput is the throughput of operations at a percentile,
+// where higher percentiles are slower, like durations.
+type PercentileThroughput struct {
+	Percentile float64 `json:"percentile"`
+	BPS        float64 `json:"bps"`
+}
+
+// String returns a human readable version of the percentile.
+func (p PercentileThroughput) String() string {
+	return fmt.Sprintf("%s: %v", PercentileLabel(p.Percentile), Throughput(p.BPS))
+}
+
+// ThroughputPercentiles returns the operation throughput at the percentiles.
+// The operations are not modified.
+func (o Operations) ThroughputPercentiles(p Percentiles) []PercentileThroughput {
+	if len(p) == 0 || len(o) == 0 {
+		return nil
+	}
+	bps := make([]float64, len(o))
+	for i, op := range o {
+		bps[i] = op.BytesPerSec().Float()
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(bps)))
+	res := make([]PercentileThroughput, len(p))
+	for i, pct := range p {
+		res[i] = PercentileThroughput{Percentile: pct, BPS: bps[percentileIndex(len(bps), pct)]}
+	}
+	return res
+}
+
+// percentileIndex returns the index of the percentile in n sorted values.
+func percentileIndex(n int, pct float64) int {
+	idx := int(float64(n)*pct/100 + 0.5)
+	if idx >= n {
+		idx = n - 1
+	}
+	return idx
+}
+
+// PercentileCmp is a comparison of operation durations at a percentile.
+type PercentileCmp struct {
+	Percentile    float64
+	Before, After time.Duration
+}
+
+// String returns a human readable version of the comparison.
+func (p PercentileCmp) String() string {
+	diff := p.After - p.Before
+	return fmt.Sprintf("%s: %s%v (%s%.f%%)", PercentileLabel(p.Percentile),
+		plusPositiveD(diff), diff, plusPositiveD(diff), 100*(float64(p.After)-float64(p.Before))/float64(p.Before))
+}
+
+// ComparePercentiles adds a comparison of the durations at the percentiles to c.
+func (c *CmpReqs) ComparePercentiles(before, after Operations, p Percentiles) {
+	b := before.DurationPercentiles(p)
+	a := after.DurationPercentiles(p)
+	if len(a) != len(b) {
+		return
+	}
+	c.Percentiles = make([]PercentileCmp, len(p))
+	for i := range b {
+		c.Percentiles[i] = PercentileCmp{Percentile: b[i].Percentile, Before: b[i].Duration, After: a[i].Duration}
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePercentiles(t *testing.T) {
+	got, err := ParsePercentiles("min, 50,99.9%,99.99,max")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Percentiles{0, 50, 99.9, 99.99, 100}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+	for _, bad := range []string{"", "101", "-1", "x", "50,"} {
+		if _, err := ParsePercentiles(bad); err == nil {
+			t.Errorf("%q: want error", bad)
+		}
+	}
+	labels := map[float64]string{0: "min", 50: "50%", 99.99: "99.99%", 100: "max"}
+	for p, want := range labels {
+		if got := PercentileLabel(p); got != want {
+			t.Errorf("label of %v: got %q, want %q", p, got, want)
+		}
+	}
+}
+
+func TestOperations_Percentiles(t *testing.T) {
+	start := time.Now()
+	var ops Operations
+	// Durations 1ms to 1000ms, in reverse order.
+	for i := 1000; i > 0; i-- {
+		ops = append(ops, Operation{Start: start, End: start.Add(time.Duration(i) * time.Millisecond), Size: 1000})
+	}
+	durs := ops.DurationPercentiles(Percentiles{0, 50, 99.9, 100})
+	want := []time.Duration{time.Millisecond, 501 * time.Millisecond, 1000 * time.Millisecond, 1000 * time.Millisecond}
+	for i, d := range durs {
+		if d.Duration != want[i] {
+			t.Errorf("%s: want %v", d, want[i])
+		}
+	}
+	if ops[0].Duration() != 1000*time.Millisecond {
+		t.Error("operations were modified")
+	}
+	bps := ops.ThroughputPercentiles(Percentiles{0, 100})
+	if bps[0].BPS != 1e6 || bps[1].BPS != 1000 {
+		t.Errorf("unexpected throughput %v", bps)
+	}
+}
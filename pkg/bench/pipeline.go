@@ -0,0 +1,231 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// PipelineTransport sends requests as HTTP/2 streams on a limited number of connections to each host,
+// so a worker keeps several requests in flight on one connection.
+// A connection is filled with Streams requests before another is opened.
+// When Conns connections are full, requests wait for a response body to be closed.
+// Without a TLS config HTTP/2 is used without TLS (h2c).
+type PipelineTransport struct {
+	// Conns is the maximum number of connections to each host.
+	Conns int
+	// Streams is the number of requests in flight on each connection.
+	Streams int
+
+	tls  *tls.Config
+	tr   *http2.Transport
+	dial func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	mu    sync.Mutex
+	hosts map[string][]*pipelineConn
+	// freed is closed and replaced when a stream is released.
+	freed chan struct{}
+}
+
+type pipelineConn struct {
+	// cc is nil while connecting.
+	cc     *http2.ClientConn
+	active int
+}
+
+// NewPipelineTransport returns a transport with conns connections to each host
+// and streams requests in flight on each.
+func NewPipelineTransport(conns, streams int, tlsConfig *tls.Config) *PipelineTransport {
+	return &PipelineTransport{
+		Conns:   conns,
+		Streams: streams,
+		tls:     tlsConfig,
+		tr: &http2.Transport{
+			AllowHTTP:          tlsConfig == nil,
+			DisableCompression: true,
+			ReadIdleTimeout:    30 * time.Second,
+		},
+		dial: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 10 * time.Second,
+		}).DialContext,
+		hosts: make(map[string][]*pipelineConn),
+		freed: make(chan struct{}),
+	}
+}
+
+// RoundTrip sends the request on a connection with a free stream.
+// The stream is released when the response body is closed.
+func (p *PipelineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	addr := req.URL.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		port := "80"
+		if req.URL.Scheme == "https" {
+			port = "443"
+		}
+		addr = net.JoinHostPort(req.URL.Hostname(), port)
+	}
+	c, err := p.get(req.Context(), addr)
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	resp, err := c.cc.RoundTrip(req)
+	if err != nil {
+		p.release(c)
+		return nil, err
+	}
+	resp.Body = &pipelineBody{ReadCloser: resp.Body, release: func() { p.release(c) }}
+	return resp, nil
+}
+
+// get returns a connection to addr with a free stream, connecting if all are full.
+func (p *PipelineTransport) get(ctx context.Context, addr string) (*pipelineConn, error) {
+	for {
+		p.mu.Lock()
+		conns := p.hosts[addr]
+		var best *pipelineConn
+		for i := 0; i < len(conns); i++ {
+			c := conns[i]
+			if c.cc == nil {
+				continue
+			}
+			if !c.cc.CanTakeNewRequest() {
+				// Closed or going away. Requests in flight finish on their own.
+				conns = append(conns[:i], conns[i+1:]...)
+				i--
+				continue
+			}
+			// Fill the busiest connection first, so no more connections than needed are used.
+			if c.active < p.Streams && (best == nil || c.active > best.active) {
+				best = c
+			}
+		}
+		p.hosts[addr] = conns
+		if best != nil {
+			best.active++
+			p.mu.Unlock()
+			return best, nil
+		}
+		if len(conns) < p.Conns {
+			c := &pipelineConn{active: 1}
+			p.hosts[addr] = append(conns, c)
+			p.mu.Unlock()
+			cc, err := p.connect(ctx, addr)
+			p.mu.Lock()
+			if err != nil {
+				p.remove(addr, c)
+				p.signal()
+				p.mu.Unlock()
+				return nil, err
+			}
+			c.cc = cc
+			// Other requests can use the connection now.
+			p.signal()
+			p.mu.Unlock()
+			return c, nil
+		}
+		freed := p.freed
+		p.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-freed:
+		}
+	}
+}
+
+// connect opens an HTTP/2 connection to addr.
+func (p *PipelineTransport) connect(ctx context.Context, addr string) (*http2.ClientConn, error) {
+	conn, err := p.dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if p.tls != nil {
+		cfg := p.tls.Clone()
+		cfg.NextProtos = []string{http2.NextProtoTLS}
+		if cfg.ServerName == "" {
+			cfg.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		tc := tls.Client(conn, cfg)
+		if err := tc.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if proto := tc.ConnectionState().NegotiatedProtocol; proto != http2.NextProtoTLS {
+			conn.Close()
+			return nil, fmt.Errorf("%s does not support HTTP/2, which is needed to pipeline requests", addr)
+		}
+		conn = tc
+	}
+	cc, err := p.tr.NewClientConn(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return cc, nil
+}
+
+// release frees a stream of the connection.
+func (p *PipelineTransport) release(c *pipelineConn) {
+	p.mu.Lock()
+	c.active--
+	p.signal()
+	p.mu.Unlock()
+}
+
+// remove removes the connection to addr. The lock must be held.
+func (p *PipelineTransport) remove(addr string, c *pipelineConn) {
+	conns := p.hosts[addr]
+	for i := range conns {
+		if conns[i] == c {
+			p.hosts[addr] = append(conns[:i], conns[i+1:]...)
+			return
+		}
+	}
+}
+
+// signal wakes requests waiting for a stream. The lock must be held.
+func (p *PipelineTransport) signal() {
+	close(p.freed)
+	p.freed = make(chan struct{})
+}
+
+// pipelineBody releases the stream when closed.
+type pipelineBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *pipelineBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,129 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestPipelineTransport(t *testing.T) {
+	const conns, streams = 2, 3
+	for _, secure := range []bool{false, true} {
+		var mu sync.Mutex
+		remotes := make(map[string]int)
+		arrived := make(chan struct{}, conns*streams+1)
+		unblock := make(chan struct{})
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ProtoMajor != 2 {
+				t.Errorf("got %s request", r.Proto)
+			}
+			mu.Lock()
+			remotes[r.RemoteAddr]++
+			mu.Unlock()
+			arrived <- struct{}{}
+			<-unblock
+			w.Write([]byte("ok"))
+		})
+		var srv *httptest.Server
+		var tr *PipelineTransport
+		if secure {
+			srv = httptest.NewUnstartedServer(h)
+			srv.EnableHTTP2 = true
+			srv.StartTLS()
+			pool := x509.NewCertPool()
+			pool.AddCert(srv.Certificate())
+			tr = NewPipelineTransport(conns, streams, &tls.Config{RootCAs: pool})
+		} else {
+			srv = httptest.NewServer(h2c.NewHandler(h, &http2.Server{}))
+			tr = NewPipelineTransport(conns, streams, nil)
+		}
+		cl := &http.Client{Transport: tr}
+
+		var wg sync.WaitGroup
+		bodies := make(chan *http.Response, conns*streams)
+		for i := 0; i < conns*streams; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := cl.Get(srv.URL)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				bodies <- resp
+			}()
+		}
+		for i := 0; i < conns*streams; i++ {
+			select {
+			case <-arrived:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("secure %v: %d of %d requests in flight", secure, i, conns*streams)
+			}
+		}
+		mu.Lock()
+		if len(remotes) != conns {
+			t.Errorf("secure %v: requests used %d connections, want %d", secure, len(remotes), conns)
+		}
+		for addr, n := range remotes {
+			if n != streams {
+				t.Errorf("secure %v: %s got %d requests, want %d", secure, addr, n, streams)
+			}
+		}
+		mu.Unlock()
+
+		// All streams are in use, so another request waits.
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if _, err := cl.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("secure %v: got %v, want deadline exceeded", secure, err)
+		}
+		cancel()
+
+		close(unblock)
+		wg.Wait()
+		close(bodies)
+		for resp := range bodies {
+			ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		// Released streams are reused.
+		resp, err := cl.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		mu.Lock()
+		if len(remotes) != conns {
+			t.Errorf("secure %v: %d connections after reuse, want %d", secure, len(remotes), conns)
+		}
+		mu.Unlock()
+		srv.Close()
+	}
+}
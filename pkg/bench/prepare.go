@@ -0,0 +1,58 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// PrepareStats contains the objects uploaded while preparing a benchmark.
+type PrepareStats struct {
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+	// Duration of the prepare stage.
+	Duration time.Duration `json:"duration"`
+}
+
+// String returns the uploaded objects and the throughput.
+func (p PrepareStats) String() string {
+	res := fmt.Sprintf("%d objects, %s in %v", p.Objects, humanize.IBytes(uint64(p.Bytes)), p.Duration.Round(time.Millisecond))
+	if secs := p.Duration.Seconds(); secs > 0 && p.Objects > 0 {
+		res += fmt.Sprintf(", %s/s, %.1f obj/s", humanize.IBytes(uint64(float64(p.Bytes)/secs)), float64(p.Objects)/secs)
+	}
+	return res
+}
+
+// PrepareStats returns the objects uploaded while preparing.
+// The duration is not set.
+func (c *Common) PrepareStats() PrepareStats {
+	return PrepareStats{
+		Objects: atomic.LoadInt64(&c.prepObjects),
+		Bytes:   atomic.LoadInt64(&c.prepBytes),
+	}
+}
+
+// countPrepared adds an object uploaded while preparing.
+func (c *Common) countPrepared(size int64) {
+	atomic.AddInt64(&c.prepObjects, 1)
+	atomic.AddInt64(&c.prepBytes, size)
+}
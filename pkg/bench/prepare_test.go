@@ -0,0 +1,48 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/warp/pkg/generator"
+)
+
+func TestPrepareStats(t *testing.T) {
+	var c Common
+	for i := 0; i < 2; i++ {
+		c.Manifest = nil
+		if i == 1 {
+			c.Manifest = &Manifest{}
+		}
+		obj := generator.Object{Name: "obj", Size: 1 << 20, Reader: bytes.NewReader(make([]byte, 1<<20))}
+		track := c.trackManifest(&obj)
+		track(minio.UploadInfo{Size: obj.Size})
+	}
+	st := c.PrepareStats()
+	if st.Objects != 2 || st.Bytes != 2<<20 {
+		t.Fatalf("got %+v", st)
+	}
+	st.Duration = 2 * time.Second
+	if got, want := st.String(), "2 objects, 2.0 MiB in 2s, 1.0 MiB/s, 1.0 obj/s"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
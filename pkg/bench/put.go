@@ -23,11 +23,21 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/minio/minio-go/v7"
 )
 
+// maxOverwriteNames is the maximum number of object names kept by each thread for overwrites.
+const maxOverwriteNames = 10000
+
 // Put benchmarks upload speed.
 type Put struct {
 	Common
+	// HeadFirst checks if each object exists with a HEAD request before uploading it.
+	HeadFirst bool
+	// Overwrite is the fraction of uploads that overwrite a previously uploaded object.
+	Overwrite float64
+
 	prefixes map[string]struct{}
 }
 
@@ -41,23 +51,27 @@ func (u *Put) Prepare(ctx context.Context) error {
 func (u *Put) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
 	var wg sync.WaitGroup
 	wg.Add(u.Concurrency)
-	c := NewCollector()
+	c := u.collector()
 	if u.AutoTermDur > 0 {
 		ctx = c.AutoTerm(ctx, http.MethodPut, u.AutoTermScale, autoTermCheck, autoTermSamples, u.AutoTermDur)
 	}
 	u.prefixes = make(map[string]struct{}, u.Concurrency)
 
-	// Non-terminating context.
-	nonTerm := context.Background()
+	// Operations in progress when ctx is done can finish.
+	nonTerm := u.opsContext(ctx)
 
 	for i := 0; i < u.Concurrency; i++ {
 		src := u.Source()
 		u.prefixes[src.Prefix()] = struct{}{}
 		go func(i int) {
+			rng := u.threadRng(i)
 			rcv := c.Receiver()
 			defer wg.Done()
+			opc := u.opContexts(nonTerm)
+			defer opc.stop()
 			opts := u.PutOpts
 			done := ctx.Done()
+			var uploaded []string
 
 			<-wait
 			for {
@@ -67,8 +81,19 @@ func (u *Put) Start(ctx context.Context, wait chan struct{}) (Operations, error)
 				default:
 				}
 				obj := src.Object()
-				opts.ContentType = obj.ContentType
-				client, cldone := u.Client()
+				if obj == nil {
+					// All objects of the source have been uploaded.
+					return
+				}
+				setObjectOpts(&opts, obj)
+				overwrite := len(uploaded) > 0 && rng.Float64() < u.Overwrite
+				if overwrite {
+					obj.Name = uploaded[rng.Intn(len(uploaded))]
+				}
+				if u.HeadFirst {
+					rcv <- u.headObject(opc.next(), uint16(i), obj.Name)
+				}
+				client, cldone := u.clientFor(obj.Name)
 				op := Operation{
 					OpType:   http.MethodPut,
 					Thread:   uint16(i),
@@ -77,9 +102,12 @@ func (u *Put) Start(ctx context.Context, wait chan struct{}) (Operations, error)
 					ObjPerOp: 1,
 					Endpoint: client.EndpointURL().String(),
 				}
+				tctx, lt := u.traceLatency(opc.next())
+				u.waitOp(ctx, u.Bucket, obj.Name)
 				op.Start = time.Now()
-				res, err := client.PutObject(nonTerm, u.Bucket, obj.Name, obj.Reader, obj.Size, opts)
+				res, err := client.PutObject(tctx, u.Bucket, obj.Name, obj.Reader, obj.Size, opts)
 				op.End = time.Now()
+				lt.record(&op)
 				if err != nil {
 					u.Error("upload error: ", err)
 					op.Err = err.Error()
@@ -93,9 +121,17 @@ func (u *Put) Start(ctx context.Context, wait chan struct{}) (Operations, error)
 					}
 					u.Error(err)
 				}
-				op.Size = res.Size
+				op.Size = uploadedSize(obj, res.Size)
 				cldone()
 				rcv <- op
+				if u.Overwrite > 0 && !overwrite && op.Err == "" {
+					// Keep a random sample of names to overwrite.
+					if len(uploaded) < maxOverwriteNames {
+						uploaded = append(uploaded, obj.Name)
+					} else {
+						uploaded[rng.Intn(len(uploaded))] = obj.Name
+					}
+				}
 			}
 		}(i)
 	}
@@ -103,6 +139,29 @@ func (u *Put) Start(ctx context.Context, wait chan struct{}) (Operations, error)
 	return c.Close(), nil
 }
 
+// headObject checks if the object exists before it is uploaded.
+// The object is expected not to exist, so only other errors are reported.
+func (u *Put) headObject(ctx context.Context, thread uint16, name string) Operation {
+	client, cldone := u.metaClient()
+	defer cldone()
+	op := Operation{
+		OpType:   "STAT",
+		Thread:   thread,
+		File:     name,
+		ObjPerOp: 1,
+		Endpoint: client.EndpointURL().String(),
+	}
+	u.waitOp(ctx, u.Bucket, name)
+	op.Start = time.Now()
+	_, err := client.StatObject(ctx, u.Bucket, name, minio.StatObjectOptions{ServerSideEncryption: u.PutOpts.ServerSideEncryption})
+	op.End = time.Now()
+	if err != nil && minio.ToErrorResponse(err).StatusCode != http.StatusNotFound {
+		u.Error("StatObject error: ", err)
+		op.Err = err.Error()
+	}
+	return op
+}
+
 // Cleanup deletes everything uploaded to the bucket.
 func (u *Put) Cleanup(ctx context.Context) {
 	var pf []string
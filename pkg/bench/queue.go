@@ -0,0 +1,345 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/warp/pkg/generator"
+)
+
+// OpQueue is the operation type of an object passing through the queue,
+// from the start of the upload until it has been deleted by a consumer.
+const OpQueue = "QUEUE"
+
+// queueEnqueuedMeta is the metadata key holding the upload start time.
+const queueEnqueuedMeta = "Warp-Enqueued"
+
+// Queue benchmarks using a prefix as a queue.
+// Producers upload objects into a single prefix,
+// while consumers list the prefix, download and delete the objects.
+type Queue struct {
+	// Consumers is the number of consumers.
+	// The remaining concurrency is used for producers.
+	Consumers int
+
+	// Poll is the time to wait before listing again when the queue is empty.
+	Poll time.Duration
+
+	prefix string
+
+	// created contains the keys uploaded by producers and not yet deleted.
+	// Consumers and cleanup only delete these keys.
+	createdMu sync.Mutex
+	created   map[string]struct{}
+	Common
+}
+
+// addCreated records that key is uploaded by a producer.
+func (q *Queue) addCreated(key string) {
+	q.createdMu.Lock()
+	q.created[key] = struct{}{}
+	q.createdMu.Unlock()
+}
+
+// isCreated returns whether key has been uploaded by a producer.
+func (q *Queue) isCreated(key string) bool {
+	q.createdMu.Lock()
+	defer q.createdMu.Unlock()
+	_, ok := q.created[key]
+	return ok
+}
+
+// removeCreated records that key has been deleted.
+func (q *Queue) removeCreated(key string) {
+	q.createdMu.Lock()
+	delete(q.created, key)
+	q.createdMu.Unlock()
+}
+
+// Prepare will create an empty bucket ot delete any content already there.
+func (q *Queue) Prepare(ctx context.Context) error {
+	return q.createEmptyBucket(ctx)
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+func (q *Queue) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(q.Concurrency)
+	c := q.collector()
+	if q.AutoTermDur > 0 {
+		ctx = c.AutoTerm(ctx, OpQueue, q.AutoTermScale, autoTermCheck, autoTermSamples, q.AutoTermDur)
+	}
+	producers := q.Concurrency - q.Consumers
+	q.created = make(map[string]struct{})
+
+	// Operations in progress when ctx is done can finish.
+	nonTerm := q.opsContext(ctx)
+
+	// All producers share the same prefix.
+	srcs := make([]generator.Source, producers)
+	for i := range srcs {
+		srcs[i] = q.Source()
+	}
+	if len(srcs) > 0 {
+		q.prefix = srcs[0].Prefix()
+	}
+
+	for i := 0; i < producers; i++ {
+		go func(i int) {
+			rcv := c.Receiver()
+			defer wg.Done()
+			opc := q.opContexts(nonTerm)
+			defer opc.stop()
+			opts := q.PutOpts
+			done := ctx.Done()
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				obj := srcs[i].Object()
+				if obj == nil {
+					return
+				}
+				obj.Name = path.Join(q.prefix, path.Base(obj.Name))
+				setObjectOpts(&opts, obj)
+				client, cldone := q.clientFor(obj.Name)
+				op := Operation{
+					OpType:   http.MethodPut,
+					Thread:   uint16(i),
+					Size:     obj.Size,
+					File:     obj.Name,
+					ObjPerOp: 1,
+					Endpoint: client.EndpointURL().String(),
+				}
+				// Recorded before the upload, so consumers can process it as soon as it is listed.
+				q.addCreated(obj.Name)
+				q.waitOp(ctx, q.Bucket, obj.Name)
+				op.Start = time.Now()
+				opts.UserMetadata = map[string]string{queueEnqueuedMeta: op.Start.Format(time.RFC3339Nano)}
+				res, err := client.PutObject(opc.next(), q.Bucket, obj.Name, obj.Reader, obj.Size, opts)
+				op.End = time.Now()
+				if err != nil {
+					q.Error("upload error: ", err)
+					op.Err = err.Error()
+				}
+				if res.Size != obj.Size && op.Err == "" {
+					err := fmt.Sprint("short upload. want:", obj.Size, ", got:", res.Size)
+					op.Err = err
+					q.Error(err)
+				}
+				op.Size = uploadedSize(obj, res.Size)
+				cldone()
+				rcv <- op
+			}
+		}(i)
+	}
+
+	listPrefix := q.prefix
+	if listPrefix != "" {
+		listPrefix += "/"
+	}
+	for i := 0; i < q.Consumers; i++ {
+		go func(consumer int) {
+			rcv := c.Receiver()
+			defer wg.Done()
+			opc := q.opContexts(nonTerm)
+			defer opc.stop()
+			done := ctx.Done()
+			thread := uint16(producers + consumer)
+
+			// mine returns whether this consumer should process the key.
+			mine := func(key string) bool {
+				h := fnv.New32a()
+				h.Write([]byte(key))
+				return int(h.Sum32()%uint32(q.Consumers)) == consumer
+			}
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				client, cldone := q.metaClient()
+				list := Operation{
+					OpType:   "LIST",
+					Thread:   thread,
+					File:     listPrefix,
+					Endpoint: client.EndpointURL().String(),
+				}
+				var keys []string
+				q.waitOp(ctx, q.Bucket, "")
+				list.Start = time.Now()
+				listCtx, cancel := context.WithCancel(opc.next())
+				for obj := range client.ListObjects(listCtx, q.Bucket, minio.ListObjectsOptions{Prefix: listPrefix, MaxKeys: 1000}) {
+					if obj.Err != nil {
+						q.Error("list error: ", obj.Err)
+						list.Err = obj.Err.Error()
+						break
+					}
+					list.ObjPerOp++
+					// Without a prefix the bucket may contain objects not created by warp.
+					if mine(obj.Key) && q.isCreated(obj.Key) {
+						keys = append(keys, obj.Key)
+					}
+					if list.ObjPerOp >= 1000 {
+						break
+					}
+				}
+				cancel()
+				list.End = time.Now()
+				// Empty listings are not counted.
+				if list.ObjPerOp > 0 || list.Err != "" {
+					rcv <- list
+				}
+				cldone()
+				if len(keys) == 0 {
+					select {
+					case <-done:
+						return
+					case <-time.After(q.Poll):
+					}
+					continue
+				}
+
+				for _, key := range keys {
+					if ctx.Err() != nil {
+						return
+					}
+					client, cldone := q.clientFor(key)
+					get := Operation{
+						OpType:   http.MethodGet,
+						Thread:   thread,
+						File:     key,
+						ObjPerOp: 1,
+						Endpoint: client.EndpointURL().String(),
+					}
+					q.waitOp(ctx, q.Bucket, key)
+					get.Start = time.Now()
+					fbr := firstByteRecorder{}
+					o, err := client.GetObject(opc.next(), q.Bucket, key, minio.GetObjectOptions{ServerSideEncryption: q.PutOpts.ServerSideEncryption})
+					var enqueued time.Time
+					if err == nil {
+						fbr.r = o
+						get.Size, err = io.Copy(ioutil.Discard, &fbr)
+						if err == nil {
+							var st minio.ObjectInfo
+							st, err = o.Stat()
+							enqueued, _ = time.Parse(time.RFC3339Nano, st.UserMetadata[queueEnqueuedMeta])
+						}
+						o.Close()
+					}
+					get.FirstByte = fbr.t
+					get.End = time.Now()
+					if err != nil {
+						q.Error("download error: ", err)
+						get.Err = err.Error()
+						rcv <- get
+						cldone()
+						continue
+					}
+					rcv <- get
+
+					del := Operation{
+						OpType:   http.MethodDelete,
+						Thread:   thread,
+						File:     key,
+						ObjPerOp: 1,
+						Endpoint: client.EndpointURL().String(),
+					}
+					q.waitOp(ctx, q.Bucket, key)
+					del.Start = time.Now()
+					err = client.RemoveObject(opc.next(), q.Bucket, key, minio.RemoveObjectOptions{})
+					del.End = time.Now()
+					cldone()
+					if err != nil {
+						q.Error("delete error: ", err)
+						del.Err = err.Error()
+					} else {
+						q.removeCreated(key)
+					}
+					rcv <- del
+					if enqueued.IsZero() || err != nil {
+						continue
+					}
+					rcv <- Operation{
+						OpType:   OpQueue,
+						Thread:   thread,
+						Size:     get.Size,
+						File:     key,
+						ObjPerOp: 1,
+						Start:    enqueued,
+						End:      del.End,
+						Endpoint: del.Endpoint,
+					}
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	return c.Close(), nil
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+// Without a prefix only objects uploaded by producers are deleted.
+func (q *Queue) Cleanup(ctx context.Context) {
+	if q.prefix != "" {
+		q.deleteAllInBucket(ctx, q.prefix)
+		return
+	}
+	cl, done := q.metaClient()
+	defer done()
+	objectsCh := make(chan minio.ObjectInfo)
+	go func() {
+		defer close(objectsCh)
+		q.createdMu.Lock()
+		keys := make([]string, 0, len(q.created))
+		for key := range q.created {
+			keys = append(keys, key)
+		}
+		q.createdMu.Unlock()
+		for _, key := range keys {
+			select {
+			case objectsCh <- minio.ObjectInfo{Key: key}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	for err := range cl.RemoveObjects(ctx, q.Bucket, objectsCh, minio.RemoveObjectsOptions{GovernanceBypass: true}) {
+		if err.Err != nil {
+			q.Error(err.Err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/minio/warp/pkg/generator"
+)
+
+// Offset patterns of ranged requests.
+const (
+	// RangeRandom requests ranges at random offsets of random objects.
+	RangeRandom = "random"
+	// RangeSequential reads objects from start to end in consecutive ranges.
+	RangeSequential = "sequential"
+	// RangeStrided reads ranges at a fixed distance from start to end of objects,
+	// like columnar readers reading a column from each row group.
+	RangeStrided = "strided"
+)
+
+// RangeOptions control the byte ranges of ranged requests.
+type RangeOptions struct {
+	// Pattern of offsets. Empty is RangeRandom.
+	Pattern string
+
+	// Size of each range.
+	// If 0 and Sizes is not set, the size is random up to the object size.
+	Size int64
+
+	// Sizes is a distribution of range sizes, used if set.
+	// See generator.WithSizeDistribution.
+	Sizes []int64
+
+	// Stride is the distance between the start of ranges with RangeStrided.
+	Stride int64
+}
+
+// Validate the range options.
+func (r RangeOptions) Validate() error {
+	switch r.Pattern {
+	case "", RangeRandom, RangeSequential:
+	case RangeStrided:
+		if r.Stride <= 0 {
+			return fmt.Errorf("range: stride must be > 0 for %s ranges", RangeStrided)
+		}
+	default:
+		return fmt.Errorf("range: unknown pattern %q. Supported: %s, %s, %s", r.Pattern, RangeRandom, RangeSequential, RangeStrided)
+	}
+	if r.Size < 0 {
+		return fmt.Errorf("range: size < 0")
+	}
+	return nil
+}
+
+// rangeCursor is the position of a thread reading through an object.
+type rangeCursor struct {
+	obj      int
+	offset   int64
+	scanning bool
+}
+
+// size returns the size of the next range of an object.
+func (r RangeOptions) size(rng *rand.Rand, objSize int64) int64 {
+	var size int64
+	switch {
+	case len(r.Sizes) > 0:
+		size = generator.GetDistributionSize(rng, r.Sizes)
+	case r.Size > 0:
+		size = r.Size
+	default:
+		size = generator.GetExpRandSize(rng, objSize)
+	}
+	if size > objSize {
+		size = objSize
+	}
+	if size < 1 {
+		size = 1
+	}
+	return size
+}
+
+// next returns the index of the object and the inclusive byte range of the next request.
+// ok is false if the full object should be requested.
+func (r RangeOptions) next(rng *rand.Rand, cur *rangeCursor, objs generator.Objects) (idx int, start, end int64, ok bool) {
+	switch r.Pattern {
+	case RangeSequential, RangeStrided:
+		if !cur.scanning {
+			*cur = rangeCursor{obj: rng.Intn(len(objs)), scanning: true}
+		}
+		idx = cur.obj
+		objSize := objs[idx].Size
+		if objSize <= 0 {
+			cur.scanning = false
+			return idx, 0, 0, false
+		}
+		size := r.size(rng, objSize)
+		start = cur.offset
+		if start+size > objSize {
+			size = objSize - start
+		}
+		step := size
+		if r.Pattern == RangeStrided {
+			step = r.Stride
+		}
+		cur.offset += step
+		if cur.offset >= objSize {
+			cur.scanning = false
+		}
+		return idx, start, start + size - 1, true
+	}
+
+	idx = rng.Intn(len(objs))
+	objSize := objs[idx].Size
+	if r.Size == 0 && len(r.Sizes) == 0 {
+		if objSize <= 2 {
+			return idx, 0, 0, false
+		}
+		// Randomize length similar to --obj.randsize
+		size := generator.GetExpRandSize(rng, objSize-2)
+		start = rng.Int63n(objSize - size)
+		return idx, start, start + size, true
+	}
+	if objSize <= 0 {
+		return idx, 0, 0, false
+	}
+	size := r.size(rng, objSize)
+	start = rng.Int63n(objSize - size + 1)
+	return idx, start, start + size - 1, true
+}
@@ -0,0 +1,92 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/minio/warp/pkg/generator"
+)
+
+func TestRangeOptions_next(t *testing.T) {
+	objs := generator.Objects{{Size: 10000}, {Size: 2500}, {Size: 1}}
+	rng := rand.New(rand.NewSource(0))
+
+	// Sequential ranges cover objects from start to end.
+	r := RangeOptions{Pattern: RangeSequential, Size: 1000}
+	var cur rangeCursor
+	for i := 0; i < 100; i++ {
+		idx, start, end, ok := r.next(rng, &cur, objs)
+		if !ok {
+			t.Fatal("range not requested")
+		}
+		if start != 0 {
+			t.Fatalf("scan of object %d started at %d", idx, start)
+		}
+		for cur.scanning {
+			idx2, start2, end2, _ := r.next(rng, &cur, objs)
+			if idx2 != idx || start2 != end+1 {
+				t.Fatalf("got object %d, range %d-%d after %d-%d of object %d", idx2, start2, end2, start, end, idx)
+			}
+			start, end = start2, end2
+		}
+		if end != objs[idx].Size-1 {
+			t.Fatalf("scan of object %d ended at %d", idx, end)
+		}
+	}
+
+	// Strided ranges start at multiples of the stride.
+	r = RangeOptions{Pattern: RangeStrided, Size: 100, Stride: 1000}
+	cur = rangeCursor{}
+	for i := 0; i < 1000; i++ {
+		idx, start, end, ok := r.next(rng, &cur, objs)
+		if !ok {
+			t.Fatal("range not requested")
+		}
+		if start%1000 != 0 || end >= objs[idx].Size || end-start+1 > 100 {
+			t.Fatalf("object %d: got range %d-%d", idx, start, end)
+		}
+	}
+
+	// Random ranges with a size distribution.
+	sizes := make([]int64, 100)
+	for i := range sizes {
+		sizes[i] = 10
+		if i >= 50 {
+			sizes[i] = 150
+		}
+	}
+	r = RangeOptions{Sizes: sizes}
+	for i := 0; i < 1000; i++ {
+		idx, start, end, ok := r.next(rng, &cur, objs)
+		if !ok {
+			t.Fatal("range not requested")
+		}
+		size := end - start + 1
+		if start < 0 || end >= objs[idx].Size || (size != 10 && size != 150 && size != objs[idx].Size) {
+			t.Fatalf("object %d: got range %d-%d", idx, start, end)
+		}
+	}
+
+	for _, bad := range []RangeOptions{{Pattern: "x"}, {Pattern: RangeStrided}, {Size: -1}} {
+		if bad.Validate() == nil {
+			t.Errorf("%+v: want error", bad)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Token arrival shapes.
+const (
+	// ArrivalFixed adds tokens at fixed intervals.
+	ArrivalFixed = "fixed"
+	// ArrivalPoisson adds tokens with exponentially distributed intervals.
+	ArrivalPoisson = "poisson"
+)
+
+// RateLimiter is a token bucket limiting the rate operations are started.
+// Tokens are added at the average rate and up to burst tokens can be saved,
+// which can be used by operations without waiting.
+type RateLimiter struct {
+	rate    float64
+	burst   int
+	arrival string
+
+	mu     sync.Mutex
+	tokens int
+	next   time.Time
+	rng    *rand.Rand
+}
+
+// NewRateLimiter returns a rate limiter allowing rate operations per second on average.
+// The bucket starts full.
+func NewRateLimiter(rate float64, burst int, arrival string) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	r := &RateLimiter{
+		rate:    rate,
+		burst:   burst,
+		arrival: arrival,
+		tokens:  burst,
+		rng:     rand.New(rand.NewSource(rand.Int63())),
+	}
+	r.next = time.Now().Add(r.interval())
+	return r
+}
+
+// String returns a description of the traffic shape.
+func (r *RateLimiter) String() string {
+	return fmt.Sprintf("%g ops/s, burst %d, %s arrival", r.rate, r.burst, r.arrival)
+}
+
+// interval returns the time until the next token is added.
+// Must be called with the lock held.
+func (r *RateLimiter) interval() time.Duration {
+	mean := float64(time.Second) / r.rate
+	if r.arrival == ArrivalPoisson {
+		return time.Duration(r.rng.ExpFloat64() * mean)
+	}
+	return time.Duration(mean)
+}
+
+// Wait blocks until a token is available or the context is canceled.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		now := time.Now()
+		for r.tokens < r.burst && !r.next.After(now) {
+			r.tokens++
+			r.next = r.next.Add(r.interval())
+		}
+		if r.tokens >= r.burst && r.next.Before(now) {
+			// Bucket is full, tokens are discarded.
+			r.next = now.Add(r.interval())
+		}
+		if r.tokens > 0 {
+			r.tokens--
+			r.mu.Unlock()
+			return nil
+		}
+		wait := r.next.Sub(now)
+		r.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// RedactName returns the name with each '/' separated part replaced by a salted hash.
+// Names sharing a prefix will also share the redacted prefix.
+func RedactName(salt, name string) string {
+	parts := strings.Split(name, "/")
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		h := sha256.Sum256([]byte(salt + "\x00" + p))
+		parts[i] = hex.EncodeToString(h[:8])
+	}
+	return strings.Join(parts, "/")
+}
+
+// Redact replaces object names of the operations with salted hashes.
+// Occurrences of the name and of the given bucket names in errors are replaced as well.
+// Annotations are left unchanged.
+func (o Operations) Redact(salt string, buckets ...string) {
+	redacted := make(map[string]string)
+	redact := func(name string) string {
+		res, ok := redacted[name]
+		if !ok {
+			res = RedactName(salt, name)
+			redacted[name] = res
+		}
+		return res
+	}
+	var bucketPairs []string
+	for _, b := range buckets {
+		if b != "" {
+			bucketPairs = append(bucketPairs, b, redact(b))
+		}
+	}
+	for i := range o {
+		op := &o[i]
+		if op.OpType == OpAnnotation {
+			continue
+		}
+		if op.Err != "" {
+			// Replaced in a single pass, so hashes are not replaced again.
+			pairs := bucketPairs
+			if op.File != "" {
+				pairs = append([]string{op.File, redact(op.File)}, bucketPairs...)
+			}
+			if len(pairs) > 0 {
+				op.Err = strings.NewReplacer(pairs...).Replace(op.Err)
+			}
+		}
+		if op.File != "" {
+			op.File = redact(op.File)
+		}
+	}
+}
@@ -0,0 +1,405 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+)
+
+// Replay re-issues the operations of a previous benchmark run.
+type Replay struct {
+	Common
+
+	// Ops is the operations to replay.
+	Ops Operations
+	// Timing starts each operation at the same offset from the start as in the original run.
+	// Otherwise each thread starts its next operation as soon as the previous is done.
+	Timing bool
+
+	// threads contains the operations of each original thread in start order.
+	threads  []Operations
+	prefixes map[string]struct{}
+}
+
+// ReplaySupported returns whether the operation can be replayed.
+// Operations without an object name or prefix, like multi-object deletes, cannot.
+func ReplaySupported(op Operation) bool {
+	switch {
+	case op.OpType == http.MethodPut, op.OpType == http.MethodGet, op.OpType == http.MethodDelete:
+		return op.File != ""
+	case op.OpType == "LIST", isReplayStat(op.OpType):
+		return true
+	}
+	return false
+}
+
+// isReplayStat returns whether the operation type is a single STAT request.
+func isReplayStat(opType string) bool {
+	return opType == "STAT" || strings.HasPrefix(opType, "STAT@")
+}
+
+// ReplayThreads returns the number of threads in the original run.
+func ReplayThreads(ops Operations) int {
+	threads := make(map[string]struct{})
+	for _, op := range ops {
+		if ReplaySupported(op) {
+			threads[fmt.Sprintf("%s:%d", op.ClientID, op.Thread)] = struct{}{}
+		}
+	}
+	return len(threads)
+}
+
+// Prepare will create an empty bucket or delete any content already there
+// and upload objects that are read before they are written by the replayed operations.
+func (r *Replay) Prepare(ctx context.Context) error {
+	if err := r.createEmptyBucket(ctx); err != nil {
+		return err
+	}
+	ops := make(Operations, 0, len(r.Ops))
+	for _, op := range r.Ops {
+		if ReplaySupported(op) {
+			ops = append(ops, op)
+		}
+	}
+	if len(ops) == 0 {
+		return errors.New("no operations to replay")
+	}
+	ops.SortByStartTime()
+
+	// Split into threads and find objects that existed before the run.
+	// Threads are not synchronized, so objects read before they are written
+	// by the same thread are created.
+	// Objects only checked with STAT before they are uploaded are not created,
+	// since they are likely checks for existence before uploading.
+	r.prefixes = make(map[string]struct{})
+	byThread := make(map[string]int)
+	written := make(map[string]struct{})
+	uploaded := make(map[string]struct{})
+	existing := make(map[string]int64)
+	for _, op := range ops {
+		if op.OpType == http.MethodPut {
+			uploaded[op.File] = struct{}{}
+		}
+	}
+	for _, op := range ops {
+		id := fmt.Sprintf("%s:%d", op.ClientID, op.Thread)
+		idx, ok := byThread[id]
+		if !ok {
+			idx = len(r.threads)
+			byThread[id] = idx
+			r.threads = append(r.threads, nil)
+		}
+		r.threads[idx] = append(r.threads[idx], op)
+		if op.File == "" || op.OpType == "LIST" {
+			continue
+		}
+		r.prefixes[replayPrefix(op.File)] = struct{}{}
+		key := id + "/" + op.File
+		if op.OpType == http.MethodPut {
+			written[key] = struct{}{}
+			continue
+		}
+		if _, ok := written[key]; ok || op.Err != "" {
+			continue
+		}
+		if op.OpType != http.MethodGet {
+			if _, ok := uploaded[op.File]; ok || !isReplayStat(op.OpType) || op.OpType == "STAT@miss" {
+				continue
+			}
+		}
+		if op.Size > existing[op.File] {
+			existing[op.File] = op.Size
+		} else if _, ok := existing[op.File]; !ok {
+			existing[op.File] = 0
+		}
+	}
+	if len(existing) == 0 {
+		return nil
+	}
+	console.Eraseline()
+	console.Info("\rUploading ", len(existing), " objects read before being written")
+
+	objs := make(chan Operation, len(existing))
+	for name, size := range existing {
+		objs <- Operation{File: name, Size: size}
+	}
+	close(objs)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var groupErr error
+	done := 0
+	wg.Add(r.Concurrency)
+	for i := 0; i < r.Concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			src := r.Source()
+			opts := r.PutOpts
+			for o := range objs {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				obj := src.Object()
+				if obj == nil {
+					return
+				}
+				if o.Size > obj.Size {
+					mu.Lock()
+					if groupErr == nil {
+						groupErr = fmt.Errorf("object %s is larger than obj.size (%d > %d)", o.File, o.Size, obj.Size)
+					}
+					mu.Unlock()
+					return
+				}
+				setObjectOpts(&opts, obj)
+				client, cldone := r.clientFor(o.File)
+				_, err := client.PutObject(ctx, r.Bucket, o.File, &limitSeeker{r: obj.Reader, n: o.Size}, o.Size, opts)
+				cldone()
+				if err != nil {
+					err := fmt.Errorf("upload error: %w", err)
+					r.Error(err)
+					mu.Lock()
+					if groupErr == nil {
+						groupErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				mu.Lock()
+				done++
+				r.prepareProgress(float64(done) / float64(len(existing)))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return groupErr
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+func (r *Replay) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var first time.Time
+	for _, ops := range r.threads {
+		if first.IsZero() || ops[0].Start.Before(first) {
+			first = ops[0].Start
+		}
+	}
+	var wg sync.WaitGroup
+	wg.Add(len(r.threads))
+	c := r.collector()
+
+	// Operations in progress when ctx is done can finish.
+	nonTerm := r.opsContext(ctx)
+
+	for i, ops := range r.threads {
+		go func(i int, ops Operations) {
+			rcv := c.Receiver()
+			defer wg.Done()
+			opc := r.opContexts(nonTerm)
+			defer opc.stop()
+			src := r.Source()
+			done := ctx.Done()
+
+			<-wait
+			start := time.Now()
+			for _, orig := range ops {
+				if r.Timing {
+					if d := time.Until(start.Add(orig.Start.Sub(first))); d > 0 {
+						select {
+						case <-done:
+							return
+						case <-time.After(d):
+						}
+					}
+				}
+				select {
+				case <-done:
+					return
+				default:
+				}
+				op := Operation{
+					OpType:   orig.OpType,
+					Thread:   uint16(i),
+					Size:     orig.Size,
+					File:     orig.File,
+					ObjPerOp: 1,
+				}
+				switch {
+				case op.OpType == http.MethodPut:
+					obj := src.Object()
+					if obj == nil {
+						continue
+					}
+					if op.Size > obj.Size {
+						op.Size = obj.Size
+					}
+					opts := r.PutOpts
+					setObjectOpts(&opts, obj)
+					client, cldone := r.clientFor(op.File)
+					op.Endpoint = client.EndpointURL().String()
+					r.waitOp(ctx, r.Bucket, op.File)
+					op.Start = time.Now()
+					res, err := client.PutObject(opc.next(), r.Bucket, op.File, &limitSeeker{r: obj.Reader, n: op.Size}, op.Size, opts)
+					op.End = time.Now()
+					cldone()
+					if err != nil {
+						r.Error("upload error: ", err)
+						op.Err = err.Error()
+					}
+					op.Size = res.Size
+				case op.OpType == http.MethodGet:
+					client, cldone := r.clientFor(op.File)
+					op.Endpoint = client.EndpointURL().String()
+					fbr := firstByteRecorder{}
+					r.waitOp(ctx, r.Bucket, op.File)
+					op.Start = time.Now()
+					o, err := client.GetObject(opc.next(), r.Bucket, op.File, minio.GetObjectOptions{ServerSideEncryption: r.PutOpts.ServerSideEncryption})
+					if err == nil {
+						fbr.r = o
+						op.Size, err = io.Copy(ioutil.Discard, &fbr)
+						o.Close()
+					}
+					op.FirstByte = fbr.t
+					op.End = time.Now()
+					cldone()
+					if err != nil {
+						r.Error("download error: ", err)
+						op.Err = err.Error()
+					}
+				case isReplayStat(op.OpType):
+					client, cldone := r.metaClient()
+					op.Endpoint = client.EndpointURL().String()
+					r.waitOp(ctx, r.Bucket, op.File)
+					op.Start = time.Now()
+					_, err := client.StatObject(opc.next(), r.Bucket, op.File, minio.StatObjectOptions{ServerSideEncryption: r.PutOpts.ServerSideEncryption})
+					op.End = time.Now()
+					cldone()
+					// Whether the object existed is not recorded, so missing objects are not errors.
+					if err != nil && minio.ToErrorResponse(err).StatusCode != http.StatusNotFound {
+						r.Error("StatObject error: ", err)
+						op.Err = err.Error()
+					}
+				case op.OpType == http.MethodDelete:
+					client, cldone := r.clientFor(op.File)
+					op.Endpoint = client.EndpointURL().String()
+					r.waitOp(ctx, r.Bucket, op.File)
+					op.Start = time.Now()
+					err := client.RemoveObject(opc.next(), r.Bucket, op.File, minio.RemoveObjectOptions{})
+					op.End = time.Now()
+					cldone()
+					if err != nil {
+						r.Error("delete error: ", err)
+						op.Err = err.Error()
+					}
+				case op.OpType == "LIST":
+					client, cldone := r.metaClient()
+					op.Endpoint = client.EndpointURL().String()
+					op.ObjPerOp = 0
+					r.waitOp(ctx, r.Bucket, "")
+					op.Start = time.Now()
+					listCh := client.ListObjects(opc.next(), r.Bucket, minio.ListObjectsOptions{Prefix: op.File, Recursive: true})
+					for obj := range listCh {
+						if obj.Err != nil {
+							r.Error(obj.Err)
+							op.Err = obj.Err.Error()
+						}
+						op.ObjPerOp++
+						if op.FirstByte == nil {
+							now := time.Now()
+							op.FirstByte = &now
+						}
+					}
+					op.End = time.Now()
+					cldone()
+				}
+				rcv <- op
+			}
+		}(i, ops)
+	}
+	wg.Wait()
+	return c.Close(), nil
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (r *Replay) Cleanup(ctx context.Context) {
+	var pf []string
+	for p := range r.prefixes {
+		pf = append(pf, p)
+	}
+	sort.Strings(pf)
+	r.deleteAllInBucket(ctx, pf...)
+}
+
+// replayPrefix returns the top level prefix of the object name,
+// or an empty string if the object is at the top level.
+func replayPrefix(name string) string {
+	if i := strings.IndexByte(name, '/'); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// limitSeeker returns the first n bytes of r.
+type limitSeeker struct {
+	r   io.ReadSeeker
+	n   int64
+	off int64
+}
+
+func (l *limitSeeker) Read(p []byte) (int, error) {
+	if l.off >= l.n {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > l.n-l.off {
+		p = p[:l.n-l.off]
+	}
+	n, err := l.r.Read(p)
+	l.off += int64(n)
+	return n, err
+}
+
+func (l *limitSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += l.off
+	case io.SeekEnd:
+		offset += l.n
+	}
+	if offset < 0 || offset > l.n {
+		return l.off, errors.New("limitSeeker: invalid offset")
+	}
+	if _, err := l.r.Seek(offset, io.SeekStart); err != nil {
+		return l.off, err
+	}
+	l.off = offset
+	return offset, nil
+}
@@ -0,0 +1,121 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/warp/pkg/bench"
+	"github.com/minio/warp/pkg/generator"
+	"github.com/minio/warp/pkg/loopback"
+)
+
+func TestReplay(t *testing.T) {
+	cl, err := minio.New("127.0.0.1:9000", &minio.Options{
+		Creds:     credentials.NewStaticV4("access", "secretsecret", ""),
+		Transport: loopback.New(1 << 20),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generator.NewFn(generator.WithRandomData().Apply(), generator.WithSize(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	op := func(thread uint16, typ, file string, size int64) bench.Operation {
+		start = start.Add(time.Millisecond)
+		return bench.Operation{OpType: typ, Thread: thread, File: file, Size: size, ObjPerOp: 1, Start: start, End: start}
+	}
+	ops := bench.Operations{
+		op(0, http.MethodPut, "p/1", 100),
+		op(0, http.MethodGet, "p/1", 100),
+		// Read before written.
+		op(0, http.MethodGet, "p/2", 50),
+		op(0, "STAT", "p/3", 0),
+		op(0, "STAT@miss", "p/missing", 0),
+		op(0, http.MethodDelete, "p/1", 0),
+		// Cannot be replayed.
+		op(0, http.MethodDelete, "", 0),
+		op(0, "STAT+GET", "p/2", 50),
+		op(1, "LIST", "p/", 0),
+	}
+	b := bench.Replay{
+		Common: bench.Common{
+			Client: func() (*minio.Client, func()) {
+				return cl, func() {}
+			},
+			Concurrency: bench.ReplayThreads(ops),
+			Source:      src,
+			Bucket:      "replay",
+			Error: func(data ...interface{}) {
+				t.Error(data...)
+			},
+		},
+		Ops: ops,
+	}
+	if b.Concurrency != 2 {
+		t.Fatalf("want 2 threads, got %d", b.Concurrency)
+	}
+	ctx := context.Background()
+	if err := b.Prepare(ctx); err != nil {
+		t.Fatal(err)
+	}
+	wait := make(chan struct{})
+	close(wait)
+	got, err := b.Start(ctx, wait)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.SortByStartTime()
+	var res []string
+	for _, op := range got {
+		if op.Err != "" {
+			t.Errorf("%s %s: %s", op.OpType, op.File, op.Err)
+		}
+		res = append(res, fmt.Sprintf("%d %s %s %d", op.Thread, op.OpType, op.File, op.Size))
+	}
+	want := []string{
+		"0 PUT p/1 100",
+		"0 GET p/1 100",
+		"0 GET p/2 50",
+		"0 STAT p/3 0",
+		"0 STAT@miss p/missing 0",
+		"0 DELETE p/1 0",
+	}
+	var gotList bool
+	for _, r := range res {
+		if r == "1 LIST p/ 0" {
+			gotList = true
+			continue
+		}
+		if len(want) == 0 || r != want[0] {
+			t.Fatalf("unexpected operation %q, got %q", r, res)
+		}
+		want = want[1:]
+	}
+	if len(want) > 0 || !gotList {
+		t.Errorf("missing operations %q, got %q", want, res)
+	}
+}
@@ -20,7 +20,6 @@ package bench
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"net/http"
 	"sync"
 	"time"
@@ -46,7 +45,7 @@ func (g *Retention) Prepare(ctx context.Context) error {
 	if err := g.createEmptyBucket(ctx); err != nil {
 		return err
 	}
-	cl, done := g.Client()
+	cl, done := g.metaClient()
 	if !g.Versioned {
 		err := cl.EnableVersioning(ctx, g.Bucket)
 		if err != nil {
@@ -61,7 +60,7 @@ func (g *Retention) Prepare(ctx context.Context) error {
 	console.Info("\rUploading ", g.CreateObjects, " objects with ", g.Versions, " versions each of ", src.String())
 	var wg sync.WaitGroup
 	wg.Add(g.Concurrency)
-	g.Collector = NewCollector()
+	g.Collector = g.collector()
 	obj := make(chan struct{}, g.CreateObjects)
 	for i := 0; i < g.CreateObjects; i++ {
 		obj <- struct{}{}
@@ -85,12 +84,18 @@ func (g *Retention) Prepare(ctx context.Context) error {
 				default:
 				}
 				obj := src.Object()
+				if obj == nil {
+					return
+				}
 				name := obj.Name
 				for ver := 0; ver < g.Versions; ver++ {
 					// New input for each version
 					obj := src.Object()
+					if obj == nil {
+						return
+					}
 					obj.Name = name
-					client, cldone := g.Client()
+					client, cldone := g.clientFor(obj.Name)
 					op := Operation{
 						OpType:   http.MethodPut,
 						Thread:   uint16(i),
@@ -99,7 +104,9 @@ func (g *Retention) Prepare(ctx context.Context) error {
 						ObjPerOp: 1,
 						Endpoint: client.EndpointURL().String(),
 					}
-					opts.ContentType = obj.ContentType
+					setObjectOpts(&opts, obj)
+					track := g.trackManifest(obj)
+					g.waitOp(ctx, g.Bucket, obj.Name)
 					op.Start = time.Now()
 					res, err := client.PutObject(ctx, g.Bucket, obj.Name, obj.Reader, obj.Size, opts)
 					op.End = time.Now()
@@ -126,6 +133,7 @@ func (g *Retention) Prepare(ctx context.Context) error {
 					}
 					cldone()
 					mu.Lock()
+					track(res)
 					obj.Reader = nil
 					g.objects = append(g.objects, *obj)
 					g.prepareProgress(float64(len(g.objects)) / float64(g.CreateObjects*g.Versions))
@@ -149,14 +157,16 @@ func (g *Retention) Start(ctx context.Context, wait chan struct{}) (Operations,
 		ctx = c.AutoTerm(ctx, http.MethodGet, g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
 	}
 
-	// Non-terminating context.
-	nonTerm := context.Background()
+	// Operations in progress when ctx is done can finish.
+	nonTerm := g.opsContext(ctx)
 
 	for i := 0; i < g.Concurrency; i++ {
 		go func(i int) {
-			rng := rand.New(rand.NewSource(int64(i)))
+			rng := g.indexRng(i)
 			rcv := c.Receiver()
 			defer wg.Done()
+			opc := g.opContexts(nonTerm)
+			defer opc.stop()
 			done := ctx.Done()
 			var opts minio.PutObjectRetentionOptions
 
@@ -169,7 +179,7 @@ func (g *Retention) Start(ctx context.Context, wait chan struct{}) (Operations,
 				default:
 				}
 				obj := g.objects[rng.Intn(len(g.objects))]
-				client, cldone := g.Client()
+				client, cldone := g.s3ClientFor(obj.Name)
 				op := Operation{
 					OpType:   "RETENTION",
 					Thread:   uint16(i),
@@ -179,13 +189,14 @@ func (g *Retention) Start(ctx context.Context, wait chan struct{}) (Operations,
 					Endpoint: client.EndpointURL().String(),
 				}
 
+				g.waitOp(ctx, g.Bucket, obj.Name)
 				op.Start = time.Now()
 				opts.VersionID = obj.VersionID
 				t := op.Start.Add(24 * time.Hour)
 				opts.RetainUntilDate = &t
 				opts.Mode = &mode
 				opts.GovernanceBypass = true
-				err := client.PutObjectRetention(nonTerm, g.Bucket, obj.Name, opts)
+				err := client.PutObjectRetention(opc.next(), g.Bucket, obj.Name, opts)
 				if err != nil {
 					g.Error("put retention error:", err)
 					op.Err = err.Error()
@@ -0,0 +1,287 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/generator"
+)
+
+// OpRMW is the operation type of a full read-modify-write round-trip.
+const OpRMW = "RMW"
+
+// ReadModifyWrite benchmarks downloading objects, modifying them and uploading them again.
+type ReadModifyWrite struct {
+	CreateObjects int
+	Collector     *Collector
+	objects       generator.Objects
+
+	// Versions will enable versioning, so each write creates a new version.
+	Versions bool
+
+	// Mutate is the fraction of bytes to modify.
+	Mutate float64
+
+	// Conflicts is the number of writes skipped because the object was
+	// modified by another writer after it was read.
+	// Updates is the number of writes attempted.
+	// Populated when Start returns.
+	Conflicts, Updates int64
+
+	Common
+}
+
+// Prepare will create an empty bucket or delete any content already there
+// and upload a number of objects.
+func (g *ReadModifyWrite) Prepare(ctx context.Context) error {
+	if err := g.createEmptyBucket(ctx); err != nil {
+		return err
+	}
+	if g.Versions && !g.Versioned {
+		cl, done := g.metaClient()
+		err := cl.EnableVersioning(ctx, g.Bucket)
+		done()
+		if err != nil {
+			return err
+		}
+		g.Versioned = true
+	}
+	console.Eraseline()
+	console.Info("\rUploading ", g.CreateObjects, " objects")
+
+	var wg sync.WaitGroup
+	wg.Add(g.Concurrency)
+	g.Collector = g.collector()
+	obj := make(chan struct{}, g.CreateObjects)
+	for i := 0; i < g.CreateObjects; i++ {
+		obj <- struct{}{}
+	}
+	rcv := g.Collector.rcv
+	close(obj)
+	var groupErr error
+	var mu sync.Mutex
+
+	for i := 0; i < g.Concurrency; i++ {
+		go func(i int) {
+			defer wg.Done()
+			src := g.Source()
+			opts := g.PutOpts
+
+			for range obj {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				obj := src.Object()
+				if obj == nil {
+					return
+				}
+				client, cldone := g.clientFor(obj.Name)
+				op := Operation{
+					OpType:   http.MethodPut,
+					Thread:   uint16(i),
+					Size:     obj.Size,
+					File:     obj.Name,
+					ObjPerOp: 1,
+					Endpoint: client.EndpointURL().String(),
+				}
+				setObjectOpts(&opts, obj)
+				track := g.trackManifest(obj)
+				g.waitOp(ctx, g.Bucket, obj.Name)
+				op.Start = time.Now()
+				res, err := client.PutObject(ctx, g.Bucket, obj.Name, obj.Reader, obj.Size, opts)
+				op.End = time.Now()
+				if err == nil && res.Size != obj.Size {
+					err = fmt.Errorf("short upload. want: %d, got %d", obj.Size, res.Size)
+				}
+				if err != nil {
+					err := fmt.Errorf("upload error: %w", err)
+					g.Error(err)
+					mu.Lock()
+					if groupErr == nil {
+						groupErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				cldone()
+				mu.Lock()
+				track(res)
+				obj.Reader = nil
+				g.objects = append(g.objects, *obj)
+				g.prepareProgress(float64(len(g.objects)) / float64(g.CreateObjects))
+				mu.Unlock()
+				rcv <- op
+			}
+		}(i)
+	}
+	wg.Wait()
+	return groupErr
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+func (g *ReadModifyWrite) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(g.Concurrency)
+	c := g.Collector
+	if g.AutoTermDur > 0 {
+		ctx = c.AutoTerm(ctx, OpRMW, g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
+	}
+	var conflicts, updates int64
+
+	// Operations in progress when ctx is done can finish.
+	nonTerm := g.opsContext(ctx)
+
+	for i := 0; i < g.Concurrency; i++ {
+		go func(i int) {
+			rng := g.threadRng(i)
+			rcv := c.Receiver()
+			defer wg.Done()
+			opc := g.opContexts(nonTerm)
+			defer opc.stop()
+			opts := g.PutOpts
+			done := ctx.Done()
+			var buf bytes.Buffer
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				obj := g.objects[rng.Intn(len(g.objects))]
+				client, cldone := g.clientFor(obj.Name)
+				rmw := Operation{
+					OpType:   OpRMW,
+					Thread:   uint16(i),
+					Size:     obj.Size,
+					File:     obj.Name,
+					ObjPerOp: 1,
+					Endpoint: client.EndpointURL().String(),
+				}
+				get := rmw
+				get.OpType = http.MethodGet
+				g.waitOp(ctx, g.Bucket, obj.Name)
+				rmw.Start = time.Now()
+				get.Start = rmw.Start
+
+				// Read
+				fbr := firstByteRecorder{}
+				buf.Reset()
+				o, err := client.GetObject(opc.next(), g.Bucket, obj.Name, minio.GetObjectOptions{ServerSideEncryption: opts.ServerSideEncryption})
+				var etag string
+				if err == nil {
+					fbr.r = o
+					_, err = io.Copy(&buf, &fbr)
+					if err == nil {
+						var st minio.ObjectInfo
+						st, err = o.Stat()
+						etag = st.ETag
+					}
+					o.Close()
+				}
+				get.FirstByte = fbr.t
+				get.End = time.Now()
+				if err == nil && int64(buf.Len()) != obj.Size {
+					err = fmt.Errorf("unexpected download size. want: %d, got: %d", obj.Size, buf.Len())
+				}
+				if err != nil {
+					g.Error("download error:", err)
+					get.Err = err.Error()
+					rmw.Err = get.Err
+					rmw.End = get.End
+					rcv <- get
+					rcv <- rmw
+					cldone()
+					continue
+				}
+				rcv <- get
+
+				// Modify
+				data := buf.Bytes()
+				if len(data) > 0 {
+					for n := int(float64(len(data)) * g.Mutate); n > 0; n-- {
+						data[rng.Intn(len(data))] = byte(rng.Intn(256))
+					}
+				}
+
+				// Check if another writer updated the object.
+				st, err := client.StatObject(opc.next(), g.Bucket, obj.Name, minio.StatObjectOptions{ServerSideEncryption: opts.ServerSideEncryption})
+				if err != nil {
+					g.Error("stat error:", err)
+					rmw.Err = err.Error()
+					rmw.End = time.Now()
+					rcv <- rmw
+					cldone()
+					continue
+				}
+				if st.ETag != etag {
+					atomic.AddInt64(&conflicts, 1)
+					rmw.End = time.Now()
+					rcv <- rmw
+					cldone()
+					continue
+				}
+
+				// Write
+				put := rmw
+				put.OpType = http.MethodPut
+				g.waitOp(ctx, g.Bucket, obj.Name)
+				put.Start = time.Now()
+				atomic.AddInt64(&updates, 1)
+				setObjectOpts(&opts, &obj)
+				res, err := client.PutObject(opc.next(), g.Bucket, obj.Name, bytes.NewReader(data), int64(len(data)), opts)
+				put.End = time.Now()
+				rmw.End = put.End
+				if err == nil && res.Size != obj.Size {
+					err = fmt.Errorf("short upload. want: %d, got %d", obj.Size, res.Size)
+				}
+				if err != nil {
+					g.Error("upload error:", err)
+					put.Err = err.Error()
+					rmw.Err = put.Err
+				}
+				rcv <- put
+				rcv <- rmw
+				cldone()
+			}
+		}(i)
+	}
+	wg.Wait()
+	g.Conflicts = atomic.LoadInt64(&conflicts)
+	g.Updates = atomic.LoadInt64(&updates)
+	return c.Close(), nil
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (g *ReadModifyWrite) Cleanup(ctx context.Context) {
+	g.deleteAllInBucket(ctx, g.objects.Prefixes()...)
+}
@@ -20,9 +20,11 @@ package bench
 import (
 	"archive/zip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
-	"math/rand"
 	"net/http"
 	"path"
 	"sync"
@@ -50,12 +52,12 @@ func (g *S3Zip) Prepare(ctx context.Context) error {
 		return err
 	}
 
-	g.Collector = NewCollector()
+	g.Collector = g.collector()
 	src := g.Source()
 	console.Eraseline()
 	console.Info("\rUploading", g.ZipObjName, "with ", g.CreateFiles, " files each of ", src.String())
 
-	client, cldone := g.Client()
+	client, cldone := g.s3ClientFor(g.ZipObjName)
 	defer cldone()
 	pr, pw := io.Pipe()
 	zw := zip.NewWriter(pw)
@@ -71,8 +73,11 @@ func (g *S3Zip) Prepare(ctx context.Context) error {
 			default:
 			}
 			obj := src.Object()
+			if obj == nil {
+				break
+			}
 
-			opts.ContentType = obj.ContentType
+			setObjectOpts(&opts, obj)
 			header := zip.FileHeader{
 				Name:   obj.Name,
 				Method: 0,
@@ -102,8 +107,22 @@ func (g *S3Zip) Prepare(ctx context.Context) error {
 
 	// TODO: Add header to index.
 	// g.PutOpts.Set("x-minio-extract", "true")
-	_, err := client.PutObject(ctx, g.Bucket, g.ZipObjName, pr, -1, g.PutOpts)
+	var r io.Reader = pr
+	h := sha256.New()
+	if g.recordsManifest() {
+		r = io.TeeReader(pr, h)
+	}
+	res, err := client.PutObject(ctx, g.Bucket, g.ZipObjName, r, -1, g.PutOpts)
 	pr.CloseWithError(err)
+	if err == nil {
+		g.countPrepared(res.Size)
+		if g.recordsManifest() {
+			g.addManifest(g.ZipObjName, hex.EncodeToString(h.Sum(nil)), res)
+		}
+	}
+	if err == nil && len(g.objects) == 0 {
+		err = errors.New("no files were added to the zip file")
+	}
 	if err == nil {
 		var opts minio.GetObjectOptions
 		opts.Set("x-minio-extract", "true")
@@ -133,14 +152,16 @@ func (g *S3Zip) Start(ctx context.Context, wait chan struct{}) (Operations, erro
 		ctx = c.AutoTerm(ctx, http.MethodGet, g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
 	}
 
-	// Non-terminating context.
-	nonTerm := context.Background()
+	// Operations in progress when ctx is done can finish.
+	nonTerm := g.opsContext(ctx)
 
 	for i := 0; i < g.Concurrency; i++ {
 		go func(i int) {
-			rng := rand.New(rand.NewSource(int64(i)))
+			rng := g.indexRng(i)
 			rcv := c.Receiver()
 			defer wg.Done()
+			opc := g.opContexts(nonTerm)
+			defer opc.stop()
 			done := ctx.Done()
 			var opts minio.GetObjectOptions
 
@@ -153,7 +174,7 @@ func (g *S3Zip) Start(ctx context.Context, wait chan struct{}) (Operations, erro
 				}
 				fbr := firstByteRecorder{}
 				obj := g.objects[rng.Intn(len(g.objects))]
-				client, cldone := g.Client()
+				client, cldone := g.s3ClientFor(g.ZipObjName)
 				op := Operation{
 					OpType:   "GET",
 					Thread:   uint16(i),
@@ -163,10 +184,11 @@ func (g *S3Zip) Start(ctx context.Context, wait chan struct{}) (Operations, erro
 					Endpoint: client.EndpointURL().String(),
 				}
 
+				g.waitOp(ctx, g.Bucket, op.File)
 				op.Start = time.Now()
 				opts.Set("x-minio-extract", "true")
 
-				o, err := client.GetObject(nonTerm, g.Bucket, op.File, opts)
+				o, err := client.GetObject(opc.next(), g.Bucket, op.File, opts)
 				if err != nil {
 					g.Error("download error:", err)
 					op.Err = err.Error()
@@ -22,7 +22,6 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"math/rand"
 	"net/http"
 	"sync"
 	"time"
@@ -54,7 +53,7 @@ func (g *Select) Prepare(ctx context.Context) error {
 	console.Info("\rUploading ", g.CreateObjects, " objects of ", src.String())
 	var wg sync.WaitGroup
 	wg.Add(g.Concurrency)
-	g.Collector = NewCollector()
+	g.Collector = g.collector()
 	obj := make(chan struct{}, g.CreateObjects)
 	for i := 0; i < g.CreateObjects; i++ {
 		obj <- struct{}{}
@@ -77,7 +76,10 @@ func (g *Select) Prepare(ctx context.Context) error {
 				default:
 				}
 				obj := src.Object()
-				client, cldone := g.Client()
+				if obj == nil {
+					return
+				}
+				client, cldone := g.s3ClientFor(obj.Name)
 				op := Operation{
 					OpType:   http.MethodPut,
 					Thread:   uint16(i),
@@ -86,7 +88,9 @@ func (g *Select) Prepare(ctx context.Context) error {
 					ObjPerOp: 1,
 					Endpoint: client.EndpointURL().String(),
 				}
-				opts.ContentType = obj.ContentType
+				setObjectOpts(&opts, obj)
+				track := g.trackManifest(obj)
+				g.waitOp(ctx, g.Bucket, obj.Name)
 				op.Start = time.Now()
 				res, err := client.PutObject(ctx, g.Bucket, obj.Name, obj.Reader, obj.Size, opts)
 				op.End = time.Now()
@@ -113,6 +117,7 @@ func (g *Select) Prepare(ctx context.Context) error {
 				}
 				cldone()
 				mu.Lock()
+				track(res)
 				obj.Reader = nil
 				g.objects = append(g.objects, *obj)
 				g.prepareProgress(float64(len(g.objects)) / float64(g.CreateObjects))
@@ -135,14 +140,16 @@ func (g *Select) Start(ctx context.Context, wait chan struct{}) (Operations, err
 		ctx = c.AutoTerm(ctx, "SELECT", g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
 	}
 
-	// Non-terminating context.
-	nonTerm := context.Background()
+	// Operations in progress when ctx is done can finish.
+	nonTerm := g.opsContext(ctx)
 
 	for i := 0; i < g.Concurrency; i++ {
 		go func(i int) {
-			rng := rand.New(rand.NewSource(int64(i)))
+			rng := g.indexRng(i)
 			rcv := c.Receiver()
 			defer wg.Done()
+			opc := g.opContexts(nonTerm)
+			defer opc.stop()
 			opts := g.SelectOpts
 			done := ctx.Done()
 
@@ -155,7 +162,7 @@ func (g *Select) Start(ctx context.Context, wait chan struct{}) (Operations, err
 				}
 				fbr := firstByteRecorder{}
 				obj := g.objects[rng.Intn(len(g.objects))]
-				client, cldone := g.Client()
+				client, cldone := g.s3ClientFor(obj.Name)
 				op := Operation{
 					OpType:   "SELECT",
 					Thread:   uint16(i),
@@ -164,9 +171,10 @@ func (g *Select) Start(ctx context.Context, wait chan struct{}) (Operations, err
 					ObjPerOp: 1,
 					Endpoint: client.EndpointURL().String(),
 				}
+				g.waitOp(ctx, g.Bucket, obj.Name)
 				op.Start = time.Now()
 				var err error
-				o, err := client.SelectObjectContent(nonTerm, g.Bucket, obj.Name, opts)
+				o, err := client.SelectObjectContent(opc.next(), g.Bucket, obj.Name, opts)
 				fbr.r = o
 				if err != nil {
 					g.Error("download error: ", err)
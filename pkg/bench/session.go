@@ -0,0 +1,412 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/s3utils"
+)
+
+const (
+	// SessionService is the default signing service of session requests.
+	SessionService = "s3express"
+
+	sessionTokenHeader = "X-Amz-S3session-Token"
+	sessionModeHeader  = "X-Amz-Create-Session-Mode"
+	unsignedPayload    = "UNSIGNED-PAYLOAD"
+	sessionMaxResponse = 1 << 20
+)
+
+// sessionBaseQueries are bucket subresources that are signed with the
+// base credentials, since they configure the bucket instead of accessing data.
+var sessionBaseQueries = []string{"location", "versioning", "object-lock", "policy", "lifecycle", "encryption", "replication", "notification", "tagging", "session"}
+
+// SessionAuth signs requests with bucket scoped session credentials,
+// like S3 Express One Zone. Credentials for a bucket are requested
+// with CreateSession on first use and refreshed in the background before they expire.
+// Requests without a bucket and requests configuring buckets are signed with the base credentials.
+type SessionAuth struct {
+	AccessKey string
+	SecretKey string
+	Region    string
+	// Service is the signing service. SessionService is used if empty.
+	Service string
+
+	mu       sync.Mutex
+	sessions map[string]*authSession
+	stats    SessionStats
+}
+
+// SessionStats contains the overhead of session authentication.
+type SessionStats struct {
+	// Created is the number of sessions created.
+	Created int
+	// Errors is the number of failed attempts to create a session.
+	Errors int
+	// Total and Max time spent creating sessions.
+	Total, Max time.Duration
+	// Requests signed with session credentials.
+	Requests int64
+	// Waited is the total time requests waited for a session.
+	Waited time.Duration
+}
+
+// Add adds the overhead of other to s.
+func (s *SessionStats) Add(other SessionStats) {
+	s.Created += other.Created
+	s.Errors += other.Errors
+	s.Total += other.Total
+	if other.Max > s.Max {
+		s.Max = other.Max
+	}
+	s.Requests += other.Requests
+	s.Waited += other.Waited
+}
+
+// String returns a human readable summary.
+func (s SessionStats) String() string {
+	if s.Created == 0 && s.Errors == 0 {
+		return "Session auth: No sessions created."
+	}
+	res := fmt.Sprintf("Session auth: %d sessions created", s.Created)
+	if s.Created == 1 {
+		res = "Session auth: 1 session created"
+	}
+	if s.Created > 0 {
+		res += fmt.Sprintf(", %v avg, %v max", (s.Total / time.Duration(s.Created)).Round(time.Microsecond), s.Max.Round(time.Microsecond))
+	}
+	if s.Errors > 0 {
+		res += fmt.Sprintf(", %d failed", s.Errors)
+	}
+	res += fmt.Sprintf(". %d requests waited %v in total for sessions.", s.Requests, s.Waited.Round(time.Microsecond))
+	return res
+}
+
+type authSession struct {
+	mu         sync.Mutex
+	creds      sessionCreds
+	refreshing bool
+}
+
+type sessionCreds struct {
+	AccessKey    string `xml:"AccessKeyId"`
+	SecretKey    string `xml:"SecretAccessKey"`
+	SessionToken string `xml:"SessionToken"`
+	Expiration   time.Time
+	refreshAt    time.Time
+}
+
+// NewSessionAuth returns session authentication using the base credentials supplied.
+func NewSessionAuth(accessKey, secretKey, region string) *SessionAuth {
+	return &SessionAuth{
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		Region:    region,
+		sessions:  make(map[string]*authSession),
+	}
+}
+
+// Stats returns the overhead of session authentication so far.
+func (s *SessionAuth) Stats() SessionStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Transport returns a transport that signs requests before sending them with tr.
+// Requests must not be signed by the client.
+// host is the endpoint of the client, used to find the bucket of virtual host style requests.
+// If host is empty all requests must be path style.
+func (s *SessionAuth) Transport(tr http.RoundTripper, host string) http.RoundTripper {
+	return sessionTransport{s: s, tr: tr, host: host}
+}
+
+type sessionTransport struct {
+	s    *SessionAuth
+	tr   http.RoundTripper
+	host string
+}
+
+func (t sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s := t.s
+	bucket, sessionURL := t.sessionBucket(req)
+	// The request must not be modified.
+	req = req.Clone(req.Context())
+	if req.Header.Get("X-Amz-Content-Sha256") == "" {
+		req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	}
+	if bucket == "" {
+		signV4(req, s.AccessKey, s.SecretKey, s.region(), "s3", time.Now())
+		return t.tr.RoundTrip(req)
+	}
+	start := time.Now()
+	creds, err := s.credentials(t.tr, sessionURL, bucket)
+	waited := time.Since(start)
+	s.mu.Lock()
+	s.stats.Requests++
+	s.stats.Waited += waited
+	s.mu.Unlock()
+	var serr *sessionError
+	if errors.As(err, &serr) {
+		// Return the error to the client like a response to the request.
+		return &http.Response{
+			Status:        http.StatusText(serr.status),
+			StatusCode:    serr.status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        serr.header,
+			Body:          io.NopCloser(bytes.NewReader(serr.body)),
+			ContentLength: int64(len(serr.body)),
+			Request:       req,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(sessionTokenHeader, creds.SessionToken)
+	signV4(req, creds.AccessKey, creds.SecretKey, s.region(), s.service(), time.Now())
+	return t.tr.RoundTrip(req)
+}
+
+// sessionBucket returns the bucket whose session must sign the request and the URL to create the session,
+// or an empty string if the base credentials must be used.
+func (t sessionTransport) sessionBucket(req *http.Request) (string, *url.URL) {
+	var bucket, key string
+	p := strings.TrimPrefix(req.URL.Path, "/")
+	sessionURL := &url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: "/", RawQuery: "session="}
+	if t.host != "" && req.URL.Host != t.host {
+		// Virtual host style. Bucket names with dots always use path style.
+		bucket, _, _ = strings.Cut(req.URL.Host, ".")
+		key = p
+	} else {
+		bucket, key, _ = strings.Cut(p, "/")
+		sessionURL.Path = "/" + bucket + "/"
+	}
+	if bucket == "" || key != "" {
+		return bucket, sessionURL
+	}
+	if req.Method == http.MethodPut || req.Method == http.MethodDelete {
+		return "", nil
+	}
+	q := req.URL.Query()
+	for _, k := range sessionBaseQueries {
+		if _, ok := q[k]; ok {
+			return "", nil
+		}
+	}
+	return bucket, sessionURL
+}
+
+func (s *SessionAuth) region() string {
+	if s.Region == "" {
+		return "us-east-1"
+	}
+	return s.Region
+}
+
+func (s *SessionAuth) service() string {
+	if s.Service == "" {
+		return SessionService
+	}
+	return s.Service
+}
+
+// credentials returns valid credentials of the bucket session.
+// Requests wait for the session to be created if there is no valid session.
+func (s *SessionAuth) credentials(tr http.RoundTripper, u *url.URL, bucket string) (sessionCreds, error) {
+	s.mu.Lock()
+	sess := s.sessions[bucket]
+	if sess == nil {
+		sess = &authSession{}
+		s.sessions[bucket] = sess
+	}
+	s.mu.Unlock()
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	now := time.Now()
+	if now.Before(sess.creds.Expiration) {
+		if now.After(sess.creds.refreshAt) && !sess.refreshing {
+			sess.refreshing = true
+			go func() {
+				creds, err := s.create(tr, u, bucket)
+				sess.mu.Lock()
+				defer sess.mu.Unlock()
+				if err == nil {
+					sess.creds = creds
+				}
+				sess.refreshing = false
+			}()
+		}
+		return sess.creds, nil
+	}
+	creds, err := s.create(tr, u, bucket)
+	if err != nil {
+		return creds, err
+	}
+	sess.creds = creds
+	return creds, nil
+}
+
+// create requests a session for the bucket with a CreateSession request to u.
+func (s *SessionAuth) create(tr http.RoundTripper, u *url.URL, bucket string) (sessionCreds, error) {
+	start := time.Now()
+	creds, err := s.createSession(tr, u)
+	d := time.Since(start)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.stats.Errors++
+		return creds, fmt.Errorf("CreateSession %s: %w", bucket, err)
+	}
+	s.stats.Created++
+	s.stats.Total += d
+	if d > s.stats.Max {
+		s.stats.Max = d
+	}
+	return creds, nil
+}
+
+func (s *SessionAuth) createSession(tr http.RoundTripper, u *url.URL) (sessionCreds, error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return sessionCreds{}, err
+	}
+	req.Header.Set(sessionModeHeader, "ReadWrite")
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256.New().Sum(nil)))
+	signV4(req, s.AccessKey, s.SecretKey, s.region(), s.service(), time.Now())
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		return sessionCreds{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, sessionMaxResponse))
+	if err != nil {
+		return sessionCreds{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return sessionCreds{}, &sessionError{status: resp.StatusCode, header: resp.Header, body: body}
+	}
+	var res struct {
+		Credentials sessionCreds
+	}
+	if err := xml.Unmarshal(body, &res); err != nil {
+		return sessionCreds{}, err
+	}
+	creds := res.Credentials
+	if creds.AccessKey == "" || creds.SecretKey == "" || creds.SessionToken == "" {
+		return sessionCreds{}, errors.New("no credentials returned")
+	}
+	now := time.Now()
+	if !creds.Expiration.After(now) {
+		return sessionCreds{}, fmt.Errorf("credentials expired at %v", creds.Expiration)
+	}
+	// Refresh when 80% of the lifetime has passed.
+	creds.refreshAt = now.Add(creds.Expiration.Sub(now) * 4 / 5)
+	return creds, nil
+}
+
+// sessionError is an error response to CreateSession.
+type sessionError struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+func (e *sessionError) Error() string {
+	var res struct {
+		Code    string
+		Message string
+	}
+	if xml.Unmarshal(e.body, &res) == nil && res.Code != "" {
+		return fmt.Sprintf("%s: %s", res.Code, res.Message)
+	}
+	return http.StatusText(e.status)
+}
+
+// signV4 adds an AWS signature version 4 authorization to the request.
+// The X-Amz-Content-Sha256 header must be set.
+func signV4(req *http.Request, accessKey, secretKey, region, service string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Del("Authorization")
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for k, v := range req.Header {
+		switch k = strings.ToLower(k); k {
+		case "authorization", "user-agent", "accept-encoding":
+			continue
+		}
+		vals := make([]string, len(v))
+		for i := range v {
+			vals[i] = strings.Join(strings.Fields(v[i]), " ")
+		}
+		headers[k] = strings.Join(vals, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonHeaders strings.Builder
+	for _, k := range names {
+		canonHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonRequest := strings.Join([]string{
+		req.Method,
+		s3utils.EncodePath(req.URL.Path),
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonHeaders.String(),
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	hash := sha256.Sum256([]byte(canonRequest))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := []byte("AWS4" + secretKey)
+	for _, s := range []string{date, region, service, "aws4_request", toSign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(s))
+		key = mac.Sum(nil)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, scope, signedHeaders, hex.EncodeToString(key)))
+}
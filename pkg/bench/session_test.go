@@ -0,0 +1,125 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/signer"
+)
+
+func TestSignV4(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://localhost:9000/bucket/some%20key?list-type=2&prefix=a+b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	req.Header.Set("X-Amz-Meta-Test", "  a   value ")
+	req.Header.Set("User-Agent", "warp")
+	want := signer.SignV4(*req, "access", "secret", "", "us-east-1")
+	tm, err := time.Parse("20060102T150405Z", want.Header.Get("X-Amz-Date"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signV4(req, "access", "secret", "us-east-1", "s3", tm)
+	if got, want := req.Header.Get("Authorization"), want.Header.Get("Authorization"); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+// sessionServer returns session credentials and checks requests are signed with them.
+type sessionServer struct {
+	mu       sync.Mutex
+	sessions int
+	tokens   map[string]bool
+	errs     []string
+}
+
+func (s *sessionServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	auth := req.Header.Get("Authorization")
+	resp := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Request: req}
+	if _, ok := req.URL.Query()["session"]; ok {
+		if !strings.Contains(auth, "Credential=base/") {
+			s.errs = append(s.errs, "session created with "+auth)
+		}
+		if req.URL.Host != "localhost" && req.URL.Path != "/" {
+			s.errs = append(s.errs, "virtual host session created at "+req.URL.Path)
+		}
+		s.sessions++
+		token := fmt.Sprint("token", s.sessions)
+		s.tokens[token] = true
+		exp := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		resp.Body = io.NopCloser(strings.NewReader(`<CreateSessionResult><Credentials><SessionToken>` + token + `</SessionToken><SecretAccessKey>s</SecretAccessKey><AccessKeyId>ASIA1</AccessKeyId><Expiration>` + exp + `</Expiration></Credentials></CreateSessionResult>`))
+		return resp, nil
+	}
+	token := req.Header.Get(sessionTokenHeader)
+	switch {
+	case req.URL.Host == "localhost" && (req.URL.Path == "/" || req.Method == http.MethodPut && strings.Count(req.URL.Path, "/") == 1):
+		if token != "" || !strings.Contains(auth, "Credential=base/") {
+			s.errs = append(s.errs, req.Method+" "+req.URL.Path+" not signed with base credentials")
+		}
+	case !s.tokens[token] || !strings.Contains(auth, "Credential=ASIA1/") || !strings.Contains(auth, "/s3express/"):
+		s.errs = append(s.errs, req.Method+" "+req.URL.Path+" not signed with session: "+auth)
+	}
+	return resp, nil
+}
+
+func TestSessionAuth(t *testing.T) {
+	srv := &sessionServer{tokens: make(map[string]bool)}
+	auth := NewSessionAuth("base", "secret", "")
+	tr := auth.Transport(srv, "localhost")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for _, u := range []string{"localhost/", "localhost/bucket", "localhost/bucket/key", "localhost/other/key", "localhost/bucket?list-type=2", "vhost.localhost/key", "vhost.localhost/?list-type=2"} {
+				method := http.MethodGet
+				if u == "localhost/bucket" {
+					method = http.MethodPut
+				}
+				req, _ := http.NewRequest(method, "http://"+u, nil)
+				resp, err := tr.RoundTrip(req)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				resp.Body.Close()
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, e := range srv.errs {
+		t.Error(e)
+	}
+	st := auth.Stats()
+	if st.Created != 3 || srv.sessions != 3 {
+		t.Errorf("want 3 sessions, got %d created, %d on server", st.Created, srv.sessions)
+	}
+	if st.Requests != 50 {
+		t.Errorf("want 50 session requests, got %d", st.Requests)
+	}
+}
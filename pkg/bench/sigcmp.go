@@ -0,0 +1,193 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/warp/pkg/generator"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Payload signing modes that can be compared.
+const (
+	// SignV4Chunked uses V4 streaming (chunked) payload signatures.
+	// Only used on non-TLS connections, otherwise the payload is unsigned.
+	SignV4Chunked = "v4-chunked"
+	// SignV4Single signs the payload SHA-256 in a single chunk.
+	SignV4Single = "v4-single"
+	// SignUnsigned sends the payload as UNSIGNED-PAYLOAD.
+	SignUnsigned = "unsigned"
+)
+
+// SignModes contains all payload signing modes in the order they are run.
+var SignModes = []string{SignV4Chunked, SignV4Single, SignUnsigned}
+
+// SigCompare benchmarks uploads using different payload signing modes back-to-back.
+type SigCompare struct {
+	Common
+
+	// PhaseDur is the duration each signing mode is run.
+	PhaseDur time.Duration
+
+	// CPU contains the client CPU time spent in each signing mode.
+	// Populated when Start returns.
+	CPU map[string]time.Duration
+
+	prefixes map[string]struct{}
+}
+
+// SigCompareOpType returns the operation type used for a signing mode.
+func SigCompareOpType(mode string) string {
+	return http.MethodPut + "-" + strings.ToUpper(mode)
+}
+
+// Prepare will create an empty bucket ot delete any content already there.
+func (u *SigCompare) Prepare(ctx context.Context) error {
+	return u.createEmptyBucket(ctx)
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+func (u *SigCompare) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	c := u.collector()
+	u.prefixes = make(map[string]struct{}, u.Concurrency)
+	u.CPU = make(map[string]time.Duration, len(SignModes))
+	proc, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return nil, err
+	}
+	cpuTime := func() time.Duration {
+		t, err := proc.Times()
+		if err != nil {
+			u.Error("unable to read process cpu time: ", err)
+			return 0
+		}
+		return time.Duration((t.User + t.System) * float64(time.Second))
+	}
+
+	// Operations in progress when ctx is done can finish.
+	nonTerm := u.opsContext(ctx)
+
+	// Sources are reused across phases.
+	srcs := make([]generator.Source, u.Concurrency)
+	for i := range srcs {
+		srcs[i] = u.Source()
+		u.prefixes[srcs[i].Prefix()] = struct{}{}
+	}
+
+	<-wait
+	for _, mode := range SignModes {
+		if ctx.Err() != nil {
+			break
+		}
+		pctx, cancel := context.WithTimeout(ctx, u.PhaseDur)
+		startCPU := cpuTime()
+		var wg sync.WaitGroup
+		wg.Add(u.Concurrency)
+		for i := 0; i < u.Concurrency; i++ {
+			go func(i int, mode string) {
+				rcv := c.Receiver()
+				defer wg.Done()
+				opc := u.opContexts(nonTerm)
+				defer opc.stop()
+				src := srcs[i]
+				opts := u.PutOpts
+				opts.DisableContentSha256 = mode != SignV4Chunked
+				done := pctx.Done()
+				for {
+					select {
+					case <-done:
+						return
+					default:
+					}
+					obj := src.Object()
+					if obj == nil {
+						return
+					}
+					setObjectOpts(&opts, obj)
+					client, cldone := u.s3ClientFor(obj.Name)
+					core := minio.Core{Client: client}
+					op := Operation{
+						OpType:   SigCompareOpType(mode),
+						Thread:   uint16(i),
+						Size:     obj.Size,
+						File:     obj.Name,
+						ObjPerOp: 1,
+						Endpoint: client.EndpointURL().String(),
+					}
+					// The payload is hashed before the operation is timed,
+					// so only the signing and sending of the request is compared.
+					var sha256Hex string
+					if mode == SignV4Single {
+						h := sha256.New()
+						_, err := io.Copy(h, obj.Reader)
+						if err == nil {
+							_, err = obj.Reader.Seek(0, io.SeekStart)
+						}
+						if err != nil {
+							u.Error("hashing error: ", err)
+							cldone()
+							continue
+						}
+						sha256Hex = hex.EncodeToString(h.Sum(nil))
+					}
+					u.waitOp(pctx, u.Bucket, obj.Name)
+					op.Start = time.Now()
+					res, err := core.PutObject(opc.next(), u.Bucket, obj.Name, obj.Reader, obj.Size, "", sha256Hex, opts)
+					op.End = time.Now()
+					if err != nil {
+						u.Error("upload error: ", err)
+						op.Err = err.Error()
+					}
+					if res.Size != obj.Size && op.Err == "" {
+						err := fmt.Sprint("short upload. want:", obj.Size, ", got:", res.Size)
+						op.Err = err
+						u.Error(err)
+					}
+					op.Size = uploadedSize(obj, res.Size)
+					cldone()
+					rcv <- op
+				}
+			}(i, mode)
+		}
+		wg.Wait()
+		cancel()
+		u.CPU[mode] = cpuTime() - startCPU
+	}
+	return c.Close(), nil
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (u *SigCompare) Cleanup(ctx context.Context) {
+	var pf []string
+	for p := range u.prefixes {
+		pf = append(pf, p)
+	}
+	u.deleteAllInBucket(ctx, pf...)
+}
@@ -20,8 +20,8 @@ package bench
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -36,6 +36,8 @@ type Stat struct {
 	Collector     *Collector
 	objects       generator.Objects
 	Versions      int
+	// MissRate is the fraction of requests for objects that do not exist.
+	MissRate float64
 
 	// Default Stat options.
 	StatOpts minio.StatObjectOptions
@@ -49,7 +51,7 @@ func (g *Stat) Prepare(ctx context.Context) error {
 		return err
 	}
 	if g.Versions > 1 {
-		cl, done := g.Client()
+		cl, done := g.metaClient()
 		if !g.Versioned {
 			err := cl.EnableVersioning(ctx, g.Bucket)
 			if err != nil {
@@ -68,7 +70,7 @@ func (g *Stat) Prepare(ctx context.Context) error {
 
 	var wg sync.WaitGroup
 	wg.Add(g.Concurrency)
-	g.Collector = NewCollector()
+	g.Collector = g.collector()
 	obj := make(chan struct{}, g.CreateObjects)
 	for i := 0; i < g.CreateObjects; i++ {
 		obj <- struct{}{}
@@ -91,13 +93,19 @@ func (g *Stat) Prepare(ctx context.Context) error {
 				default:
 				}
 				obj := src.Object()
+				if obj == nil {
+					return
+				}
 
 				name := obj.Name
 				for ver := 0; ver < g.Versions; ver++ {
 					// New input for each version
 					obj := src.Object()
+					if obj == nil {
+						return
+					}
 					obj.Name = name
-					client, cldone := g.Client()
+					client, cldone := g.clientFor(obj.Name)
 					op := Operation{
 						OpType:   http.MethodPut,
 						Thread:   uint16(i),
@@ -106,7 +114,9 @@ func (g *Stat) Prepare(ctx context.Context) error {
 						ObjPerOp: 1,
 						Endpoint: client.EndpointURL().String(),
 					}
-					opts.ContentType = obj.ContentType
+					setObjectOpts(&opts, obj)
+					track := g.trackManifest(obj)
+					g.waitOp(ctx, g.Bucket, obj.Name)
 					op.Start = time.Now()
 					res, err := client.PutObject(ctx, g.Bucket, obj.Name, obj.Reader, obj.Size, opts)
 					op.End = time.Now()
@@ -133,6 +143,7 @@ func (g *Stat) Prepare(ctx context.Context) error {
 					}
 					cldone()
 					mu.Lock()
+					track(res)
 					obj.Reader = nil
 					g.objects = append(g.objects, *obj)
 					g.prepareProgress(float64(len(g.objects)) / float64(g.CreateObjects*g.Versions))
@@ -155,14 +166,16 @@ func (g *Stat) Start(ctx context.Context, wait chan struct{}) (Operations, error
 	if g.AutoTermDur > 0 {
 		ctx = c.AutoTerm(ctx, "STAT", g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
 	}
-	// Non-terminating context.
-	nonTerm := context.Background()
+	// Operations in progress when ctx is done can finish.
+	nonTerm := g.opsContext(ctx)
 
 	for i := 0; i < g.Concurrency; i++ {
 		go func(i int) {
-			rng := rand.New(rand.NewSource(int64(i)))
+			rng := g.indexRng(i)
 			rcv := c.Receiver()
 			defer wg.Done()
+			opc := g.opContexts(nonTerm)
+			defer opc.stop()
 			opts := g.StatOpts
 			done := ctx.Done()
 
@@ -174,7 +187,11 @@ func (g *Stat) Start(ctx context.Context, wait chan struct{}) (Operations, error
 				default:
 				}
 				obj := g.objects[rng.Intn(len(g.objects))]
-				client, cldone := g.Client()
+				if g.MissRate > 0 && rng.Float64() < g.MissRate {
+					rcv <- g.statMissing(opc.next(), uint16(i), obj.Name+"."+strconv.FormatInt(rng.Int63(), 36))
+					continue
+				}
+				client, cldone := g.metaClient()
 				op := Operation{
 					OpType:   "STAT",
 					Thread:   uint16(i),
@@ -183,21 +200,23 @@ func (g *Stat) Start(ctx context.Context, wait chan struct{}) (Operations, error
 					ObjPerOp: 1,
 					Endpoint: client.EndpointURL().String(),
 				}
+				g.waitOp(ctx, g.Bucket, obj.Name)
 				op.Start = time.Now()
 				var err error
 				if g.Versions > 1 {
 					opts.VersionID = obj.VersionID
 				}
-				objI, err := client.StatObject(nonTerm, g.Bucket, obj.Name, opts)
+				tctx, lt := g.traceLatency(opc.next())
+				objI, err := client.StatObject(tctx, g.Bucket, obj.Name, opts)
+				op.End = time.Now()
+				lt.record(&op)
 				if err != nil {
 					g.Error("StatObject error: ", err)
 					op.Err = err.Error()
-					op.End = time.Now()
 					rcv <- op
 					cldone()
 					continue
 				}
-				op.End = time.Now()
 				if objI.Size != obj.Size && op.Err == "" {
 					op.Err = fmt.Sprint("unexpected file size. want:", obj.Size, ", got:", objI.Size)
 					g.Error(op.Err)
@@ -211,6 +230,35 @@ func (g *Stat) Start(ctx context.Context, wait chan struct{}) (Operations, error
 	return c.Close(), nil
 }
 
+// statMissing requests an object that does not exist.
+// Only responses other than "not found" are reported as errors.
+func (g *Stat) statMissing(ctx context.Context, thread uint16, name string) Operation {
+	client, cldone := g.metaClient()
+	defer cldone()
+	op := Operation{
+		OpType:   "STAT@miss",
+		Thread:   thread,
+		File:     name,
+		ObjPerOp: 1,
+		Endpoint: client.EndpointURL().String(),
+	}
+	opts := g.StatOpts
+	opts.VersionID = ""
+	g.waitOp(ctx, g.Bucket, name)
+	op.Start = time.Now()
+	_, err := client.StatObject(ctx, g.Bucket, name, opts)
+	op.End = time.Now()
+	switch {
+	case err == nil:
+		op.Err = "object exists: " + name
+		g.Error(op.Err)
+	case minio.ToErrorResponse(err).StatusCode != http.StatusNotFound:
+		g.Error("StatObject error: ", err)
+		op.Err = err.Error()
+	}
+	return op
+}
+
 // Cleanup deletes everything uploaded to the bucket.
 func (g *Stat) Cleanup(ctx context.Context) {
 	g.deleteAllInBucket(ctx, g.objects.Prefixes()...)
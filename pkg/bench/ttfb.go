@@ -0,0 +1,252 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+	"github.com/minio/warp/pkg/generator"
+)
+
+// FirstByte benchmarks metadata and first byte latency.
+// Each request stats an object and downloads its first byte with a 'bytes=0-0' range.
+type FirstByte struct {
+	CreateObjects int
+	Collector     *Collector
+	objects       generator.Objects
+
+	// NoHead will only send the first byte GETs.
+	NoHead bool
+
+	// Default Stat and Get options.
+	StatOpts minio.StatObjectOptions
+	GetOpts  minio.GetObjectOptions
+
+	Common
+}
+
+// Prepare will create an empty bucket or delete any content already there
+// and upload a number of objects.
+func (g *FirstByte) Prepare(ctx context.Context) error {
+	if err := g.createEmptyBucket(ctx); err != nil {
+		return err
+	}
+	console.Eraseline()
+	console.Info("\rUploading ", g.CreateObjects, " objects")
+
+	var wg sync.WaitGroup
+	wg.Add(g.Concurrency)
+	g.Collector = g.collector()
+	obj := make(chan struct{}, g.CreateObjects)
+	for i := 0; i < g.CreateObjects; i++ {
+		obj <- struct{}{}
+	}
+	rcv := g.Collector.rcv
+	close(obj)
+	var groupErr error
+	var mu sync.Mutex
+
+	for i := 0; i < g.Concurrency; i++ {
+		go func(i int) {
+			defer wg.Done()
+			src := g.Source()
+			opts := g.PutOpts
+
+			for range obj {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				obj := src.Object()
+				if obj == nil {
+					return
+				}
+				client, cldone := g.clientFor(obj.Name)
+				op := Operation{
+					OpType:   http.MethodPut,
+					Thread:   uint16(i),
+					Size:     obj.Size,
+					File:     obj.Name,
+					ObjPerOp: 1,
+					Endpoint: client.EndpointURL().String(),
+				}
+				setObjectOpts(&opts, obj)
+				track := g.trackManifest(obj)
+				g.waitOp(ctx, g.Bucket, obj.Name)
+				op.Start = time.Now()
+				res, err := client.PutObject(ctx, g.Bucket, obj.Name, obj.Reader, obj.Size, opts)
+				op.End = time.Now()
+				if err == nil && res.Size != obj.Size {
+					err = fmt.Errorf("short upload. want: %d, got %d", obj.Size, res.Size)
+				}
+				if err != nil {
+					err := fmt.Errorf("upload error: %w", err)
+					g.Error(err)
+					mu.Lock()
+					if groupErr == nil {
+						groupErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				cldone()
+				mu.Lock()
+				track(res)
+				obj.Reader = nil
+				g.objects = append(g.objects, *obj)
+				g.prepareProgress(float64(len(g.objects)) / float64(g.CreateObjects))
+				mu.Unlock()
+				rcv <- op
+			}
+		}(i)
+	}
+	wg.Wait()
+	return groupErr
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+func (g *FirstByte) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(g.Concurrency)
+	c := g.Collector
+	if g.AutoTermDur > 0 {
+		ctx = c.AutoTerm(ctx, http.MethodGet, g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
+	}
+	// Operations in progress when ctx is done can finish.
+	nonTerm := g.opsContext(ctx)
+
+	for i := 0; i < g.Concurrency; i++ {
+		go func(i int) {
+			rng := g.indexRng(i)
+			rcv := c.Receiver()
+			defer wg.Done()
+			opc := g.opContexts(nonTerm)
+			defer opc.stop()
+			getOpts := g.GetOpts
+			getOpts.SetRange(0, 0)
+			done := ctx.Done()
+			// Reused for all downloads.
+			var buf [2]byte
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				obj := g.objects[rng.Intn(len(g.objects))]
+				if !g.NoHead {
+					op, ok := g.stat(opc.next(), uint16(i), obj)
+					rcv <- op
+					if !ok {
+						continue
+					}
+				}
+
+				client, cldone := g.clientFor(obj.Name)
+				op := Operation{
+					OpType:   http.MethodGet,
+					Thread:   uint16(i),
+					Size:     1,
+					File:     obj.Name,
+					ObjPerOp: 1,
+					Endpoint: client.EndpointURL().String(),
+				}
+				g.waitOp(ctx, g.Bucket, obj.Name)
+				op.Start = time.Now()
+				tctx, lt := g.traceLatency(opc.next())
+				o, err := client.GetObject(tctx, g.Bucket, obj.Name, getOpts)
+				if err != nil {
+					g.Error("download error:", err)
+					op.Err = err.Error()
+					op.End = time.Now()
+					lt.record(&op)
+					rcv <- op
+					cldone()
+					continue
+				}
+				fbr := firstByteRecorder{r: o}
+				n, err := io.ReadFull(&fbr, buf[:])
+				if err == io.ErrUnexpectedEOF || err == io.EOF {
+					err = nil
+				}
+				if err != nil {
+					g.Error("download error:", err)
+					op.Err = err.Error()
+				}
+				op.FirstByte = fbr.t
+				op.End = time.Now()
+				lt.record(&op)
+				if int64(n) != op.Size && op.Err == "" {
+					op.Err = fmt.Sprint("unexpected download size. want:", op.Size, ", got:", n)
+					g.Error(op.Err)
+				}
+				rcv <- op
+				cldone()
+				o.Close()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return c.Close(), nil
+}
+
+// stat requests the metadata of an object.
+// ok is false if the request failed.
+func (g *FirstByte) stat(ctx context.Context, thread uint16, obj generator.Object) (op Operation, ok bool) {
+	client, cldone := g.metaClient()
+	defer cldone()
+	op = Operation{
+		OpType:   "STAT",
+		Thread:   thread,
+		File:     obj.Name,
+		ObjPerOp: 1,
+		Endpoint: client.EndpointURL().String(),
+	}
+	g.waitOp(ctx, g.Bucket, obj.Name)
+	op.Start = time.Now()
+	tctx, lt := g.traceLatency(ctx)
+	objI, err := client.StatObject(tctx, g.Bucket, obj.Name, g.StatOpts)
+	op.End = time.Now()
+	lt.record(&op)
+	if err != nil {
+		g.Error("StatObject error: ", err)
+		op.Err = err.Error()
+		return op, false
+	}
+	if objI.Size != obj.Size {
+		op.Err = fmt.Sprint("unexpected file size. want:", obj.Size, ", got:", objI.Size)
+		g.Error(op.Err)
+	}
+	return op, true
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (g *FirstByte) Cleanup(ctx context.Context) {
+	g.deleteAllInBucket(ctx, g.objects.Prefixes()...)
+}
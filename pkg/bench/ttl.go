@@ -0,0 +1,199 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// TTL distributions.
+const (
+	// TTLFixed uses the same TTL for all objects.
+	TTLFixed = "fixed"
+	// TTLUniform uses a TTL uniformly distributed between 0 and twice the TTL.
+	TTLUniform = "uniform"
+	// TTLExp uses an exponentially distributed TTL with the TTL as mean.
+	TTLExp = "exp"
+)
+
+// TTL benchmarks uploading objects and deleting each after a per-object TTL.
+type TTL struct {
+	// TTL is the mean time to live of each object.
+	TTL time.Duration
+	// Dist is the TTL distribution.
+	Dist string
+
+	prefixes map[string]struct{}
+	Common
+}
+
+// ttlObject is an object waiting for deletion.
+type ttlObject struct {
+	name    string
+	version string
+	expires time.Time
+}
+
+// ttlQueue is a min-heap of objects ordered by expiry.
+type ttlQueue []ttlObject
+
+func (q ttlQueue) Len() int            { return len(q) }
+func (q ttlQueue) Less(i, j int) bool  { return q[i].expires.Before(q[j].expires) }
+func (q ttlQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *ttlQueue) Push(x interface{}) { *q = append(*q, x.(ttlObject)) }
+func (q *ttlQueue) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
+
+// sample returns a TTL.
+func (t *TTL) sample(rng *rand.Rand) time.Duration {
+	switch t.Dist {
+	case TTLUniform:
+		return time.Duration(rng.Int63n(2*int64(t.TTL) + 1))
+	case TTLExp:
+		return time.Duration(rng.ExpFloat64() * float64(t.TTL))
+	}
+	return t.TTL
+}
+
+// Prepare will create an empty bucket ot delete any content already there.
+func (t *TTL) Prepare(ctx context.Context) error {
+	return t.createEmptyBucket(ctx)
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+func (t *TTL) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(t.Concurrency)
+	c := t.collector()
+	if t.AutoTermDur > 0 {
+		ctx = c.AutoTerm(ctx, http.MethodPut, t.AutoTermScale, autoTermCheck, autoTermSamples, t.AutoTermDur)
+	}
+	t.prefixes = make(map[string]struct{}, t.Concurrency)
+
+	// Operations in progress when ctx is done can finish.
+	nonTerm := t.opsContext(ctx)
+
+	for i := 0; i < t.Concurrency; i++ {
+		src := t.Source()
+		t.prefixes[src.Prefix()] = struct{}{}
+		go func(i int) {
+			rng := t.threadRng(i)
+			rcv := c.Receiver()
+			defer wg.Done()
+			opc := t.opContexts(nonTerm)
+			defer opc.stop()
+			opts := t.PutOpts
+			done := ctx.Done()
+			var expiring ttlQueue
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				// Delete all expired objects first.
+				if len(expiring) > 0 && !expiring[0].expires.After(time.Now()) {
+					obj := heap.Pop(&expiring).(ttlObject)
+					client, cldone := t.clientFor(obj.name)
+					op := Operation{
+						OpType:   http.MethodDelete,
+						Thread:   uint16(i),
+						File:     obj.name,
+						ObjPerOp: 1,
+						Endpoint: client.EndpointURL().String(),
+					}
+					t.waitOp(ctx, t.Bucket, obj.name)
+					op.Start = time.Now()
+					err := client.RemoveObject(opc.next(), t.Bucket, obj.name, minio.RemoveObjectOptions{VersionID: obj.version})
+					op.End = time.Now()
+					if err != nil {
+						t.Error("delete error: ", err)
+						op.Err = err.Error()
+					}
+					cldone()
+					rcv <- op
+					continue
+				}
+
+				obj := src.Object()
+				if obj == nil {
+					return
+				}
+				setObjectOpts(&opts, obj)
+				client, cldone := t.clientFor(obj.Name)
+				op := Operation{
+					OpType:   http.MethodPut,
+					Thread:   uint16(i),
+					Size:     obj.Size,
+					File:     obj.Name,
+					ObjPerOp: 1,
+					Endpoint: client.EndpointURL().String(),
+				}
+				t.waitOp(ctx, t.Bucket, obj.Name)
+				op.Start = time.Now()
+				res, err := client.PutObject(opc.next(), t.Bucket, obj.Name, obj.Reader, obj.Size, opts)
+				op.End = time.Now()
+				if err != nil {
+					t.Error("upload error: ", err)
+					op.Err = err.Error()
+				}
+				if res.Size != obj.Size && op.Err == "" {
+					err := fmt.Sprint("short upload. want:", obj.Size, ", got:", res.Size)
+					op.Err = err
+					t.Error(err)
+				}
+				op.Size = uploadedSize(obj, res.Size)
+				cldone()
+				rcv <- op
+				if op.Err == "" {
+					heap.Push(&expiring, ttlObject{
+						name:    obj.Name,
+						version: res.VersionID,
+						expires: op.End.Add(t.sample(rng)),
+					})
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	return c.Close(), nil
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (t *TTL) Cleanup(ctx context.Context) {
+	var pf []string
+	for p := range t.prefixes {
+		pf = append(pf, p)
+	}
+	t.deleteAllInBucket(ctx, pf...)
+}
@@ -0,0 +1,215 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvRequiredColumns are the columns benchmark data must have.
+var csvRequiredColumns = []string{"thread", "op", "n_objects", "bytes", "file", "error", "start", "first_byte", "end"}
+
+// ValidationError is a problem found in a file.
+type ValidationError struct {
+	// Line of the problem. 0 if it applies to the whole file.
+	Line int
+	// Column of the problem, if any.
+	Column string
+	Msg    string
+}
+
+func (e ValidationError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column != "":
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Column, e.Msg)
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return e.Msg
+}
+
+// Validation is the result of validating benchmark data.
+type Validation struct {
+	// Operations is the number of operations read.
+	Operations int
+	// Comments contains the provenance stored at the end of the data.
+	Comments []string
+	// Errors found. At most the maximum number requested are included.
+	Errors []ValidationError
+	// TotalErrors is the number of errors found.
+	TotalErrors int
+}
+
+func (v *Validation) add(maxErrors int, e ValidationError) {
+	v.TotalErrors++
+	if len(v.Errors) < maxErrors {
+		v.Errors = append(v.Errors, e)
+	}
+}
+
+// lastByteReader remembers the last byte read.
+type lastByteReader struct {
+	r    io.Reader
+	last byte
+	n    int64
+}
+
+func (l *lastByteReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	if n > 0 {
+		l.last = p[n-1]
+		l.n += int64(n)
+	}
+	return n, err
+}
+
+// ValidateCSV checks that benchmark data read from r has the expected columns
+// and that all values can be parsed and are consistent.
+// Reading stops at the first read error, which is reported as truncated data.
+// At most maxErrors errors are included in the result.
+func ValidateCSV(r io.Reader, maxErrors int) Validation {
+	var res Validation
+	lr := &lastByteReader{r: r}
+	comments := &csvCommentReader{r: lr}
+	cr := csv.NewReader(comments)
+	cr.Comma = '\t'
+	cr.ReuseRecord = true
+	cr.Comment = '#'
+
+	readErr := func(err error) bool {
+		var perr *csv.ParseError
+		if errors.As(err, &perr) && !errors.Is(perr.Err, io.ErrUnexpectedEOF) {
+			res.add(maxErrors, ValidationError{Line: perr.Line, Msg: perr.Err.Error()})
+			return false
+		}
+		res.add(maxErrors, ValidationError{Msg: fmt.Sprintf("data truncated after %d operations: %v", res.Operations, err)})
+		return true
+	}
+	header, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			res.add(maxErrors, ValidationError{Msg: "no data"})
+			return res
+		}
+		readErr(err)
+		return res
+	}
+	fieldIdx := make(map[string]int, len(header))
+	for i, s := range header {
+		if _, ok := fieldIdx[s]; ok {
+			res.add(maxErrors, ValidationError{Line: 1, Column: s, Msg: "duplicate column"})
+		}
+		fieldIdx[s] = i
+	}
+	for _, c := range csvRequiredColumns {
+		if _, ok := fieldIdx[c]; !ok {
+			res.add(maxErrors, ValidationError{Line: 1, Column: c, Msg: "missing column"})
+		}
+	}
+	if res.TotalErrors > 0 {
+		// Values cannot be checked without the columns.
+		return res
+	}
+	cr.FieldsPerRecord = len(header)
+
+	for {
+		values, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if readErr(err) {
+				return res
+			}
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		res.Operations++
+		check := func(col string, fn func(s string) error) {
+			idx, ok := fieldIdx[col]
+			if !ok {
+				return
+			}
+			if err := fn(values[idx]); err != nil {
+				res.add(maxErrors, ValidationError{Line: line, Column: col, Msg: err.Error()})
+			}
+		}
+		parseInt := func(optional bool, min int64, bits int) func(s string) error {
+			return func(s string) error {
+				if s == "" && optional {
+					return nil
+				}
+				v, err := strconv.ParseInt(s, 10, bits)
+				if err != nil {
+					return fmt.Errorf("invalid number %q", s)
+				}
+				if v < min {
+					return fmt.Errorf("%d is below %d", v, min)
+				}
+				return nil
+			}
+		}
+		times := make(map[string]time.Time, 3)
+		parseTime := func(col string, optional bool) func(s string) error {
+			return func(s string) error {
+				if s == "" && optional {
+					return nil
+				}
+				t, err := time.Parse(time.RFC3339Nano, s)
+				if err != nil {
+					return fmt.Errorf("invalid time %q", s)
+				}
+				times[col] = t
+				return nil
+			}
+		}
+		check("op", func(s string) error {
+			if s == "" {
+				return errors.New("empty operation type")
+			}
+			return nil
+		})
+		check("thread", parseInt(false, 0, 16))
+		check("n_objects", parseInt(false, 0, 64))
+		check("bytes", parseInt(false, 0, 64))
+		check("duration_ns", parseInt(true, 0, 64))
+		check("network_ns", parseInt(true, 0, 64))
+		check("server_ns", parseInt(true, 0, 64))
+		check("start", parseTime("start", false))
+		check("first_byte", parseTime("first_byte", true))
+		check("end", parseTime("end", false))
+		start, okStart := times["start"]
+		end, okEnd := times["end"]
+		if okStart && okEnd && end.Before(start) {
+			res.add(maxErrors, ValidationError{Line: line, Column: "end", Msg: "end is before start"})
+		}
+		if fb, ok := times["first_byte"]; ok && okStart && okEnd && (fb.Before(start) || fb.After(end)) {
+			res.add(maxErrors, ValidationError{Line: line, Column: "first_byte", Msg: "first byte is not between start and end"})
+		}
+	}
+	if lr.n > 0 && lr.last != '\n' {
+		res.add(maxErrors, ValidationError{Msg: "data does not end with a newline, it may be truncated"})
+	}
+	res.Comments = comments.comments()
+	return res
+}
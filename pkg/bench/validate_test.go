@@ -0,0 +1,73 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bench
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateCSV(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var ops Operations
+	for i := 0; i < 10; i++ {
+		fb := start.Add(time.Millisecond)
+		ops = append(ops, Operation{OpType: "GET", Thread: uint16(i), Size: 100, File: "obj", ObjPerOp: 1, Start: start, FirstByte: &fb, End: start.Add(2 * time.Millisecond)})
+	}
+	var buf bytes.Buffer
+	if err := ops.CSV(&buf, "warp get\nWarp: version"); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.String()
+
+	v := ValidateCSV(strings.NewReader(data), 10)
+	if v.TotalErrors != 0 || v.Operations != 10 || len(v.Comments) != 2 {
+		t.Fatalf("valid data: %+v", v)
+	}
+
+	lines := strings.Split(data, "\n")
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "empty", data: "", want: "no data"},
+		{name: "missing column", data: strings.Replace(data, "\tbytes\t", "\tsize\t", 1), want: "line 1: bytes: missing column"},
+		{name: "bad time", data: strings.Replace(data, "2024-01-02T03:04:05Z", "yesterday", 1), want: `line 2: start: invalid time "yesterday"`},
+		{name: "field count", data: strings.Replace(data, "\tGET\t", "\tGET\textra\t", 1), want: "line 2: wrong number of fields"},
+		{name: "end before start", data: strings.Replace(data, "2024-01-02T03:04:05.002Z", "2024-01-02T03:04:04Z", 1), want: "line 2: end: end is before start"},
+		{name: "truncated", data: strings.Join(lines[:5], "\n") + "\n" + lines[5][:20], want: "does not end with a newline"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := ValidateCSV(strings.NewReader(tt.data), 10)
+			if v.TotalErrors == 0 {
+				t.Fatal("no errors found")
+			}
+			var found bool
+			for _, e := range v.Errors {
+				found = found || strings.Contains(e.Error(), tt.want)
+			}
+			if !found {
+				t.Errorf("want %q, got %v", tt.want, v.Errors)
+			}
+		})
+	}
+}
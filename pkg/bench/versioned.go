@@ -55,7 +55,7 @@ func (g *Versioned) Prepare(ctx context.Context) error {
 		return err
 	}
 	if !g.Versioned {
-		cl, done := g.Client()
+		cl, done := g.metaClient()
 		err := cl.EnableVersioning(ctx, g.Bucket)
 		done()
 		if err != nil {
@@ -68,7 +68,7 @@ func (g *Versioned) Prepare(ctx context.Context) error {
 	console.Info("\rUploading ", g.CreateObjects, " objects of ", src.String())
 	var wg sync.WaitGroup
 	wg.Add(g.Concurrency)
-	g.Collector = NewCollector()
+	g.Collector = g.collector()
 	obj := make(chan struct{}, g.CreateObjects)
 	for i := 0; i < g.CreateObjects; i++ {
 		obj <- struct{}{}
@@ -90,8 +90,12 @@ func (g *Versioned) Prepare(ctx context.Context) error {
 				default:
 				}
 				obj := src.Object()
-				client, clDone := g.Client()
-				opts.ContentType = obj.ContentType
+				if obj == nil {
+					return
+				}
+				client, clDone := g.clientFor(obj.Name)
+				setObjectOpts(&opts, obj)
+				track := g.trackManifest(obj)
 				res, err := client.PutObject(ctx, g.Bucket, obj.Name, obj.Reader, obj.Size, opts)
 				if err != nil {
 					err := fmt.Errorf("upload error: %w", err)
@@ -115,6 +119,7 @@ func (g *Versioned) Prepare(ctx context.Context) error {
 					return
 				}
 				clDone()
+				track(res)
 				obj.Reader = nil
 				g.Dist.addObj(*obj)
 				g.prepareProgress(float64(len(g.Dist.objects)) / float64(g.CreateObjects))
@@ -134,12 +139,14 @@ func (g *Versioned) Start(ctx context.Context, wait chan struct{}) (Operations,
 	if g.AutoTermDur > 0 {
 		ctx = c.AutoTerm(ctx, "", g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
 	}
-	// Non-terminating context.
-	nonTerm := context.Background()
+	// Operations in progress when ctx is done can finish.
+	nonTerm := g.opsContext(ctx)
 	for i := 0; i < g.Concurrency; i++ {
 		go func(i int) {
 			rcv := c.Receiver()
 			defer wg.Done()
+			opc := g.opContexts(nonTerm)
+			defer opc.stop()
 			done := ctx.Done()
 			src := g.Source()
 			putOpts := g.PutOpts
@@ -158,7 +165,7 @@ func (g *Versioned) Start(ctx context.Context, wait chan struct{}) (Operations,
 				case http.MethodGet:
 					fbr := firstByteRecorder{}
 					obj, objDone := g.Dist.randomObjRead()
-					client, clDone := g.Client()
+					client, clDone := g.clientFor(obj.Name)
 					op := Operation{
 						OpType:   operation,
 						Thread:   uint16(i),
@@ -167,10 +174,11 @@ func (g *Versioned) Start(ctx context.Context, wait chan struct{}) (Operations,
 						ObjPerOp: 1,
 						Endpoint: client.EndpointURL().String(),
 					}
+					g.waitOp(ctx, g.Bucket, obj.Name)
 					op.Start = time.Now()
 					var err error
 					getOpts.VersionID = obj.VersionID
-					fbr.r, err = client.GetObject(nonTerm, g.Bucket, obj.Name, getOpts)
+					fbr.r, err = client.GetObject(opc.next(), g.Bucket, obj.Name, getOpts)
 					if err != nil {
 						g.Error("download error: ", err)
 						op.Err = err.Error()
@@ -195,9 +203,13 @@ func (g *Versioned) Start(ctx context.Context, wait chan struct{}) (Operations,
 					objDone()
 					clDone()
 				case http.MethodPut:
-					obj, objDone := g.Dist.newVersion(src.Object())
-					putOpts.ContentType = obj.ContentType
-					client, clDone := g.Client()
+					next := src.Object()
+					if next == nil {
+						continue
+					}
+					obj, objDone := g.Dist.newVersion(next)
+					setObjectOpts(&putOpts, &obj)
+					client, clDone := g.clientFor(obj.Name)
 					op := Operation{
 						OpType:   operation,
 						Thread:   uint16(i),
@@ -206,8 +218,9 @@ func (g *Versioned) Start(ctx context.Context, wait chan struct{}) (Operations,
 						ObjPerOp: 1,
 						Endpoint: client.EndpointURL().String(),
 					}
+					g.waitOp(ctx, g.Bucket, obj.Name)
 					op.Start = time.Now()
-					res, err := client.PutObject(nonTerm, g.Bucket, obj.Name, obj.Reader, obj.Size, putOpts)
+					res, err := client.PutObject(opc.next(), g.Bucket, obj.Name, obj.Reader, obj.Size, putOpts)
 					op.End = time.Now()
 					if err != nil {
 						g.Error("upload error: ", err)
@@ -222,7 +235,7 @@ func (g *Versioned) Start(ctx context.Context, wait chan struct{}) (Operations,
 						}
 						g.Error(err)
 					}
-					op.Size = res.Size
+					op.Size = uploadedSize(&obj, res.Size)
 					clDone()
 					if op.Err != "" {
 						// Don't add if error.
@@ -231,8 +244,8 @@ func (g *Versioned) Start(ctx context.Context, wait chan struct{}) (Operations,
 					objDone(res.VersionID)
 					rcv <- op
 				case http.MethodDelete:
-					client, clDone := g.Client()
 					obj := g.Dist.deleteRandomObj()
+					client, clDone := g.clientFor(obj.Name)
 					op := Operation{
 						OpType:   operation,
 						Thread:   uint16(i),
@@ -241,8 +254,9 @@ func (g *Versioned) Start(ctx context.Context, wait chan struct{}) (Operations,
 						ObjPerOp: 1,
 						Endpoint: client.EndpointURL().String(),
 					}
+					g.waitOp(ctx, g.Bucket, obj.Name)
 					op.Start = time.Now()
-					err := client.RemoveObject(nonTerm, g.Bucket, obj.Name, minio.RemoveObjectOptions{VersionID: obj.VersionID})
+					err := client.RemoveObject(opc.next(), g.Bucket, obj.Name, minio.RemoveObjectOptions{VersionID: obj.VersionID})
 					op.End = time.Now()
 					clDone()
 					if err != nil {
@@ -252,7 +266,7 @@ func (g *Versioned) Start(ctx context.Context, wait chan struct{}) (Operations,
 					rcv <- op
 				case "STAT":
 					obj, objDone := g.Dist.randomObjRead()
-					client, clDone := g.Client()
+					client, clDone := g.metaClient()
 					op := Operation{
 						OpType:   operation,
 						Thread:   uint16(i),
@@ -261,10 +275,11 @@ func (g *Versioned) Start(ctx context.Context, wait chan struct{}) (Operations,
 						ObjPerOp: 1,
 						Endpoint: client.EndpointURL().String(),
 					}
+					g.waitOp(ctx, g.Bucket, obj.Name)
 					op.Start = time.Now()
 					var err error
 					statOpts.VersionID = obj.VersionID
-					objI, err := client.StatObject(nonTerm, g.Bucket, obj.Name, statOpts)
+					objI, err := client.StatObject(opc.next(), g.Bucket, obj.Name, statOpts)
 					if err != nil {
 						g.Error("stat error:", err)
 						op.Err = err.Error()
@@ -301,9 +316,15 @@ type versionedObj struct {
 type VersionedDistribution struct {
 	// Operation -> distribution.
 	Distribution map[string]float64
-	ops          []string
-	objects      map[string]versionedObj
-	rng          *rand.Rand
+	// Deterministic seeds the operation order and object choices with Seed,
+	// otherwise a fixed order and random objects are used.
+	Deterministic bool
+	Seed          int64
+	ops           []string
+	objects       map[string]versionedObj
+	// keys contains the names of objects with versions in deterministic mode.
+	keys sortedKeys
+	rng  *rand.Rand
 
 	current int
 	mu      sync.Mutex
@@ -320,13 +341,23 @@ func (m *VersionedDistribution) Generate(allocObjs int) error {
 
 	const genOps = 1000
 	m.ops = make([]string, 0, genOps)
-	for op, dist := range m.Distribution {
-		add := int(0.5 + dist*genOps)
+	// Operations are added in sorted order, so the order only depends on the seed.
+	names := make([]string, 0, len(m.Distribution))
+	for op := range m.Distribution {
+		names = append(names, op)
+	}
+	sort.Strings(names)
+	for _, op := range names {
+		add := int(0.5 + m.Distribution[op]*genOps)
 		for i := 0; i < add; i++ {
 			m.ops = append(m.ops, op)
 		}
 	}
-	m.rng = rand.New(rand.NewSource(0xabad1dea))
+	seed := int64(0xabad1dea)
+	if m.Deterministic {
+		seed = m.Seed
+	}
+	m.rng = rand.New(rand.NewSource(seed))
 	sort.Slice(m.ops, func(i, j int) bool {
 		return m.rng.Int63()&1 == 0
 	})
@@ -358,46 +389,55 @@ func (m *VersionedDistribution) normalize() error {
 	return nil
 }
 
-func (m *VersionedDistribution) randomObjRead() (obj generator.Object, done func()) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// randomKey returns the name of a random object with versions.
+// The lock must be held.
+func (m *VersionedDistribution) randomKey() string {
+	if m.Deterministic {
+		// Map order differs between runs, so pick from the sorted names.
+		if len(m.keys) == 0 {
+			panic("ran out of objects")
+		}
+		return m.keys[m.rng.Intn(len(m.keys))]
+	}
 	// Use map randomness to select.
 	for k, o := range m.objects {
-		if len(o.objs) == 0 {
-			continue
-		}
-		// Remove it until we have read it so it isn't deleted.
-		n := m.rng.Intn(len(o.objs))
-		obj := o.objs[n]
-		o.objs = append(o.objs[:n], o.objs[n+1:]...)
-		m.objects[k] = o
-
-		return obj, func() {
-			m.mu.Lock()
-			defer m.mu.Unlock()
-			o := m.objects[k]
-			o.objs = append(o.objs, obj)
-			m.objects[k] = o
+		if len(o.objs) > 0 {
+			return k
 		}
 	}
 	panic("ran out of objects")
 }
 
-func (m *VersionedDistribution) deleteRandomObj() generator.Object {
+func (m *VersionedDistribution) randomObjRead() (obj generator.Object, done func()) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	// Use map randomness to select.
-	for k, o := range m.objects {
-		if len(o.objs) == 0 {
-			continue
-		}
-		n := m.rng.Intn(len(o.objs))
-		obj := o.objs[n]
-		o.objs = append(o.objs[:n], o.objs[n+1:]...)
-		m.objects[k] = o
-		return obj
+	k := m.randomKey()
+	o := m.objects[k]
+	// Remove it until we have read it so it isn't deleted.
+	n := m.rng.Intn(len(o.objs))
+	obj = o.objs[n]
+	o.objs = append(o.objs[:n], o.objs[n+1:]...)
+	m.set(k, o)
+
+	return obj, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		o := m.objects[k]
+		o.objs = append(o.objs, obj)
+		m.set(k, o)
 	}
-	panic("ran out of objects")
+}
+
+func (m *VersionedDistribution) deleteRandomObj() generator.Object {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := m.randomKey()
+	o := m.objects[k]
+	n := m.rng.Intn(len(o.objs))
+	obj := o.objs[n]
+	o.objs = append(o.objs[:n], o.objs[n+1:]...)
+	m.set(k, o)
+	return obj
 }
 
 // newVersion will modify the object to be a version of an existing object.
@@ -422,10 +462,24 @@ func (m *VersionedDistribution) addObj(o generator.Object) {
 	m.mu.Lock()
 	objs := m.objects[o.Name]
 	objs.objs = append(objs.objs, o)
-	m.objects[o.Name] = objs
+	m.set(o.Name, objs)
 	m.mu.Unlock()
 }
 
+// set stores the versions of the object with name k.
+// The lock must be held.
+func (m *VersionedDistribution) set(k string, o versionedObj) {
+	m.objects[k] = o
+	if !m.Deterministic {
+		return
+	}
+	if len(o.objs) > 0 {
+		m.keys.add(k)
+	} else {
+		m.keys.remove(k)
+	}
+}
+
 func (m *VersionedDistribution) getOp() string {
 	m.mu.Lock()
 	op := m.ops[m.current]
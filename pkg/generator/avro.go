@@ -0,0 +1,543 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Avro compression codecs.
+const (
+	AvroNull      = "null"
+	AvroDeflate   = "deflate"
+	AvroSnappy    = "snappy"
+	AvroZstandard = "zstandard"
+)
+
+const (
+	avroMagic = "Obj\x01"
+
+	// avroBlockRecords is the maximum number of records in each block.
+	avroBlockRecords = 1000
+
+	// avroMaxDepth is the nesting depth after which unions pick null
+	// and arrays and maps are empty, so recursive types end.
+	avroMaxDepth = 8
+)
+
+// avroDefaultSchema is used if no schema is given.
+const avroDefaultSchema = `{
+  "type": "record", "name": "Event", "namespace": "warp",
+  "fields": [
+    {"name": "id", "type": {"type": "string", "logicalType": "uuid"}},
+    {"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "level", "type": {"type": "enum", "name": "Level", "symbols": ["DEBUG", "INFO", "WARN", "ERROR"]}},
+    {"name": "host", "type": "string"},
+    {"name": "status", "type": "int"},
+    {"name": "latency", "type": "double"},
+    {"name": "tags", "type": {"type": "map", "values": "string"}},
+    {"name": "user", "type": ["null", "string"]}
+  ]
+}`
+
+// WithAvroData returns default Avro Opts.
+func WithAvroData() AvroOpts {
+	return avroOptsDefaults()
+}
+
+// Apply Avro data options.
+// The schema is parsed when the options are applied.
+func (o AvroOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		var err error
+		o.parsed, err = parseAvroSchema(o.schema)
+		if err != nil {
+			return fmt.Errorf("avro: %w", err)
+		}
+		opts.avro = o
+		opts.src = newAvro
+		return nil
+	}
+}
+
+// ValidateAvroSchema returns an error if the Avro schema cannot be used to generate records.
+func ValidateAvroSchema(schema string) error {
+	_, err := parseAvroSchema(schema)
+	return err
+}
+
+func (o AvroOpts) validate() error {
+	if o.records <= 0 {
+		return errors.New("avro: records <= 0")
+	}
+	switch o.codec {
+	case AvroNull, AvroDeflate, AvroSnappy, AvroZstandard:
+	default:
+		return fmt.Errorf("avro: unknown codec %q", o.codec)
+	}
+	return nil
+}
+
+// Schema sets the Avro schema of the records as JSON.
+func (o AvroOpts) Schema(schema string) AvroOpts {
+	o.schema = schema
+	return o
+}
+
+// Records sets the number of records in each file.
+func (o AvroOpts) Records(n int) AvroOpts {
+	o.records = n
+	return o
+}
+
+// Codec sets the compression codec of blocks.
+func (o AvroOpts) Codec(codec string) AvroOpts {
+	o.codec = codec
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make usage predictable.
+func (o AvroOpts) RngSeed(s int64) AvroOpts {
+	o.seed = &s
+	return o
+}
+
+// AvroOpts provides options for Avro generation.
+type AvroOpts struct {
+	seed    *int64
+	schema  string
+	records int
+	codec   string
+	parsed  *avroSchema
+}
+
+func avroOptsDefaults() AvroOpts {
+	return AvroOpts{
+		seed:    nil,
+		schema:  avroDefaultSchema,
+		records: 1000,
+		codec:   AvroNull,
+	}
+}
+
+// avroSchema is a parsed schema.
+type avroSchema struct {
+	// json is the compacted schema stored in files.
+	json []byte
+	root *avroType
+}
+
+// avroType is a type of an Avro schema.
+type avroType struct {
+	kind    string
+	logical string
+	// name is the full name of named types.
+	name     string
+	fields   []*avroType
+	symbols  []string
+	items    *avroType
+	branches []*avroType
+	size     int
+	// null is the index of the null branch of unions, or -1.
+	null int
+}
+
+// avroParser parses schemas, keeping track of named types.
+type avroParser struct {
+	named map[string]*avroType
+}
+
+func parseAvroSchema(schema string) (*avroSchema, error) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(schema), &v); err != nil {
+		return nil, fmt.Errorf("invalid schema JSON: %w", err)
+	}
+	p := avroParser{named: make(map[string]*avroType)}
+	root, err := p.parse(v, "")
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(schema)); err != nil {
+		return nil, err
+	}
+	return &avroSchema{json: buf.Bytes(), root: root}, nil
+}
+
+func (p *avroParser) parse(v interface{}, ns string) (*avroType, error) {
+	switch v := v.(type) {
+	case string:
+		switch v {
+		case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+			return &avroType{kind: v}, nil
+		}
+		if t, ok := p.named[avroFullName(v, ns)]; ok {
+			return t, nil
+		}
+		if t, ok := p.named[v]; ok {
+			return t, nil
+		}
+		return nil, fmt.Errorf("unknown type %q", v)
+	case []interface{}:
+		t := &avroType{kind: "union", null: -1}
+		for i, b := range v {
+			bt, err := p.parse(b, ns)
+			if err != nil {
+				return nil, err
+			}
+			if bt.kind == "null" {
+				t.null = i
+			}
+			t.branches = append(t.branches, bt)
+		}
+		if len(t.branches) == 0 {
+			return nil, errors.New("union without types")
+		}
+		return t, nil
+	case map[string]interface{}:
+		return p.parseComplex(v, ns)
+	}
+	return nil, fmt.Errorf("invalid type %v", v)
+}
+
+func (p *avroParser) parseComplex(v map[string]interface{}, ns string) (*avroType, error) {
+	kind, _ := v["type"].(string)
+	t := &avroType{kind: kind}
+	t.logical, _ = v["logicalType"].(string)
+	switch kind {
+	case "record", "error", "enum", "fixed":
+		name, _ := v["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("%s without name", kind)
+		}
+		if n, ok := v["namespace"].(string); ok && !strings.Contains(name, ".") {
+			ns = n
+		}
+		t.name = avroFullName(name, ns)
+		if i := strings.LastIndexByte(t.name, '.'); i >= 0 {
+			ns = t.name[:i]
+		}
+		// Register before the fields are parsed to allow recursive types.
+		p.named[t.name] = t
+	}
+	switch kind {
+	case "record", "error":
+		t.kind = "record"
+		fields, ok := v["fields"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("record %s without fields", t.name)
+		}
+		for _, f := range fields {
+			fm, ok := f.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("record %s: invalid field %v", t.name, f)
+			}
+			ft, err := p.parse(fm["type"], ns)
+			if err != nil {
+				return nil, fmt.Errorf("record %s, field %v: %w", t.name, fm["name"], err)
+			}
+			t.fields = append(t.fields, ft)
+		}
+	case "enum":
+		symbols, _ := v["symbols"].([]interface{})
+		for _, s := range symbols {
+			if s, ok := s.(string); ok {
+				t.symbols = append(t.symbols, s)
+			}
+		}
+		if len(t.symbols) == 0 {
+			return nil, fmt.Errorf("enum %s without symbols", t.name)
+		}
+	case "fixed":
+		size, _ := v["size"].(float64)
+		if size < 0 || size != math.Trunc(size) {
+			return nil, fmt.Errorf("fixed %s: invalid size %v", t.name, v["size"])
+		}
+		t.size = int(size)
+	case "array", "map":
+		key := "items"
+		if kind == "map" {
+			key = "values"
+		}
+		items, err := p.parse(v[key], ns)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", kind, err)
+		}
+		t.items = items
+	default:
+		// Primitive types with attributes, like logical types.
+		pt, err := p.parse(kind, ns)
+		if err != nil {
+			return nil, err
+		}
+		if pt.name != "" {
+			return pt, nil
+		}
+		pt.logical = t.logical
+		return pt, nil
+	}
+	return t, nil
+}
+
+// avroFullName returns the full name of a name in the namespace.
+func avroFullName(name, ns string) string {
+	if ns == "" || strings.Contains(name, ".") {
+		return name
+	}
+	return ns + "." + name
+}
+
+type avroSource struct {
+	counter uint64
+	o       Options
+	buf     *circularBuffer
+	rng     *rand.Rand
+	obj     Object
+	now     time.Time
+
+	block []byte
+	comp  []byte
+	flate *flate.Writer
+	zstd  *zstd.Encoder
+}
+
+func newAvro(o Options) (Source, error) {
+	rndSrc := rand.NewSource(int64(rand.Uint64()))
+	if o.avro.seed != nil {
+		rndSrc = rand.NewSource(*o.avro.seed)
+	}
+	a := avroSource{
+		o:   o,
+		rng: rand.New(rndSrc),
+		buf: newCircularBuffer(make([]byte, 0, o.totalSize), o.totalSize),
+		now: time.Now(),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "application/avro",
+			Size:        0,
+		},
+	}
+	switch o.avro.codec {
+	case AvroDeflate:
+		a.flate, _ = flate.NewWriter(nil, flate.DefaultCompression)
+	case AvroZstandard:
+		enc, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1), zstd.WithEncoderLevel(zstd.SpeedFastest))
+		if err != nil {
+			return nil, err
+		}
+		a.zstd = enc
+	}
+	a.obj.setPrefix(o)
+	return &a, nil
+}
+
+// Object returns an Avro object container file with the configured number of records.
+// The size of the object is the size of the file.
+func (a *avroSource) Object() *Object {
+	atomic.AddUint64(&a.counter, 1)
+	opts := a.o.avro
+
+	var sync [16]byte
+	a.rng.Read(sync[:])
+	dst := append(a.buf.data[:0], avroMagic...)
+	// File metadata is a map with the schema and codec.
+	dst = avroAppendLong(dst, 2)
+	dst = avroAppendBytes(dst, []byte("avro.schema"))
+	dst = avroAppendBytes(dst, opts.parsed.json)
+	dst = avroAppendBytes(dst, []byte("avro.codec"))
+	dst = avroAppendBytes(dst, []byte(opts.codec))
+	dst = avroAppendLong(dst, 0)
+	dst = append(dst, sync[:]...)
+
+	for done := 0; done < opts.records; done += avroBlockRecords {
+		n := opts.records - done
+		if n > avroBlockRecords {
+			n = avroBlockRecords
+		}
+		a.block = a.block[:0]
+		for i := 0; i < n; i++ {
+			a.block = a.appendValue(a.block, opts.parsed.root, 0)
+		}
+		data := a.compress(a.block)
+		dst = avroAppendLong(dst, int64(n))
+		dst = avroAppendLong(dst, int64(len(data)))
+		dst = append(dst, data...)
+		dst = append(dst, sync[:]...)
+	}
+	a.buf.data = dst
+	a.obj.Size = int64(len(dst))
+
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], a.rng)
+	a.obj.setName(fmt.Sprintf("%d.%s.avro", atomic.LoadUint64(&a.counter), string(nBuf[:])))
+
+	a.obj.Reader = a.buf.Reset(a.obj.Size)
+	return &a.obj
+}
+
+// appendValue appends a random binary encoded value of the type to dst.
+func (a *avroSource) appendValue(dst []byte, t *avroType, depth int) []byte {
+	rng := a.rng
+	switch t.kind {
+	case "null":
+		return dst
+	case "boolean":
+		if rng.Intn(2) == 0 {
+			return append(dst, 0)
+		}
+		return append(dst, 1)
+	case "int":
+		switch t.logical {
+		case "date":
+			return avroAppendLong(dst, a.now.Unix()/86400-int64(rng.Intn(365)))
+		case "time-millis":
+			return avroAppendLong(dst, int64(rng.Intn(86400000)))
+		}
+		return avroAppendLong(dst, int64(rng.Int31n(100000)))
+	case "long":
+		switch t.logical {
+		case "timestamp-millis", "local-timestamp-millis":
+			return avroAppendLong(dst, a.now.UnixMilli()-rng.Int63n(86400000))
+		case "timestamp-micros", "local-timestamp-micros":
+			return avroAppendLong(dst, a.now.UnixMicro()-rng.Int63n(86400000000))
+		case "time-micros":
+			return avroAppendLong(dst, rng.Int63n(86400000000))
+		}
+		return avroAppendLong(dst, rng.Int63n(1e12))
+	case "float":
+		return appendUint32(dst, math.Float32bits(rng.Float32()*1000))
+	case "double":
+		return appendUint64(dst, math.Float64bits(rng.Float64()*1000))
+	case "bytes":
+		var b [16]byte
+		n := 1 + rng.Intn(len(b))
+		rng.Read(b[:n])
+		return avroAppendBytes(dst, b[:n])
+	case "string":
+		if t.logical == "uuid" {
+			var u [16]byte
+			rng.Read(u[:])
+			s := fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+			return avroAppendBytes(dst, []byte(s))
+		}
+		var b [16]byte
+		n := 4 + rng.Intn(len(b)-3)
+		randASCIIBytes(b[:n], rng)
+		return avroAppendBytes(dst, b[:n])
+	case "record":
+		for _, f := range t.fields {
+			dst = a.appendValue(dst, f, depth+1)
+		}
+		return dst
+	case "enum":
+		return avroAppendLong(dst, int64(rng.Intn(len(t.symbols))))
+	case "fixed":
+		start := len(dst)
+		for i := 0; i < t.size; i++ {
+			dst = append(dst, 0)
+		}
+		rng.Read(dst[start:])
+		return dst
+	case "array", "map":
+		n := 0
+		if depth < avroMaxDepth {
+			n = rng.Intn(5)
+		}
+		if n > 0 {
+			dst = avroAppendLong(dst, int64(n))
+			for i := 0; i < n; i++ {
+				if t.kind == "map" {
+					var k [8]byte
+					randASCIIBytes(k[:], rng)
+					dst = avroAppendBytes(dst, k[:])
+				}
+				dst = a.appendValue(dst, t.items, depth+1)
+			}
+		}
+		return avroAppendLong(dst, 0)
+	case "union":
+		i := rng.Intn(len(t.branches))
+		if depth >= avroMaxDepth && t.null >= 0 {
+			i = t.null
+		}
+		dst = avroAppendLong(dst, int64(i))
+		return a.appendValue(dst, t.branches[i], depth+1)
+	}
+	return dst
+}
+
+// compress returns the block compressed with the configured codec.
+func (a *avroSource) compress(block []byte) []byte {
+	switch a.o.avro.codec {
+	case AvroDeflate:
+		buf := bytes.NewBuffer(a.comp[:0])
+		a.flate.Reset(buf)
+		a.flate.Write(block)
+		a.flate.Close()
+		a.comp = buf.Bytes()
+	case AvroSnappy:
+		// Snappy blocks are followed by the CRC32 of the uncompressed data.
+		a.comp = snappy.Encode(a.comp[:cap(a.comp)], block)
+		var crc [4]byte
+		binary.BigEndian.PutUint32(crc[:], crc32.ChecksumIEEE(block))
+		a.comp = append(a.comp, crc[:]...)
+	case AvroZstandard:
+		a.comp = a.zstd.EncodeAll(block, a.comp[:0])
+	default:
+		return block
+	}
+	return a.comp
+}
+
+// avroAppendLong appends a zig-zag encoded variable length integer.
+func avroAppendLong(dst []byte, v int64) []byte {
+	return appendUvarint(dst, uint64((v<<1)^(v>>63)))
+}
+
+// avroAppendBytes appends bytes or a string prefixed with the length.
+func avroAppendBytes(dst, b []byte) []byte {
+	dst = avroAppendLong(dst, int64(len(b)))
+	return append(dst, b...)
+}
+
+func (a *avroSource) String() string {
+	opts := a.o.avro
+	return fmt.Sprintf("Avro data; %d records, codec %s", opts.records, opts.codec)
+}
+
+func (a *avroSource) Prefix() string {
+	return a.obj.Prefix
+}
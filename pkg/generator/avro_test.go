@@ -0,0 +1,214 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestWithAvroData(t *testing.T) {
+	const recursive = `{"type": "record", "name": "Node", "namespace": "test", "fields": [
+		{"name": "value", "type": {"type": "fixed", "name": "Hash", "size": 8}},
+		{"name": "flag", "type": "boolean"},
+		{"name": "score", "type": "float"},
+		{"name": "day", "type": {"type": "int", "logicalType": "date"}},
+		{"name": "raw", "type": "bytes"},
+		{"name": "same", "type": "test.Hash"},
+		{"name": "children", "type": {"type": "array", "items": "Node"}},
+		{"name": "next", "type": ["null", "Node"]}
+	]}`
+	tests := []struct {
+		name string
+		opts AvroOpts
+	}{
+		{name: "default", opts: WithAvroData()},
+		{name: "blocks", opts: WithAvroData().Records(2500)},
+		{name: "recursive", opts: WithAvroData().Schema(recursive).Records(50)},
+		{name: "deflate", opts: WithAvroData().Codec(AvroDeflate)},
+		{name: "snappy", opts: WithAvroData().Codec(AvroSnappy)},
+		{name: "zstandard", opts: WithAvroData().Codec(AvroZstandard)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := New(tt.opts.RngSeed(1).Apply())
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := src.(*avroSource).o.avro
+			for i := 0; i < 3; i++ {
+				obj := src.Object()
+				if obj.ContentType != "application/avro" {
+					t.Errorf("content type %q", obj.ContentType)
+				}
+				b, err := io.ReadAll(obj.Reader)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if int64(len(b)) != obj.Size {
+					t.Fatalf("got size %d, want %d", len(b), obj.Size)
+				}
+				records, err := checkAvro(b, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if records != opts.records {
+					t.Fatalf("got %d records, want %d", records, opts.records)
+				}
+			}
+		})
+	}
+
+	for _, schema := range []string{`{`, `"unknown"`, `{"type": "record", "name": "r"}`, `{"type": "enum", "name": "e", "symbols": []}`, `[]`} {
+		if _, err := New(WithAvroData().Schema(schema).Apply()); err == nil {
+			t.Errorf("%s: want error", schema)
+		}
+	}
+	if _, err := New(WithAvroData().Codec("lz4").Apply()); err == nil {
+		t.Error("want error for unknown codec")
+	}
+}
+
+// checkAvro decodes an Avro container file and returns the number of records.
+func checkAvro(b []byte, opts AvroOpts) (int, error) {
+	r := avroReader{b: b}
+	if string(r.next(4)) != avroMagic {
+		return 0, errors.New("no magic")
+	}
+	meta := make(map[string]string)
+	for n := r.long(); n != 0; n = r.long() {
+		for i := int64(0); i < n; i++ {
+			k := r.next(int(r.long()))
+			meta[string(k)] = string(r.next(int(r.long())))
+		}
+	}
+	if meta["avro.codec"] != opts.codec || meta["avro.schema"] != string(opts.parsed.json) {
+		return 0, fmt.Errorf("unexpected metadata %v", meta)
+	}
+	sync := r.next(16)
+	records := 0
+	for r.err == nil && len(r.b) > 0 {
+		n := int(r.long())
+		data := r.next(int(r.long()))
+		if !bytes.Equal(r.next(16), sync) {
+			return 0, errors.New("sync marker mismatch")
+		}
+		var err error
+		switch opts.codec {
+		case AvroDeflate:
+			data, err = io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+		case AvroSnappy:
+			crc := binary.BigEndian.Uint32(data[len(data)-4:])
+			data, err = snappy.Decode(nil, data[:len(data)-4])
+			if err == nil && crc32.ChecksumIEEE(data) != crc {
+				err = errors.New("crc mismatch")
+			}
+		case AvroZstandard:
+			var dec *zstd.Decoder
+			dec, err = zstd.NewReader(nil)
+			if err == nil {
+				data, err = dec.DecodeAll(data, nil)
+				dec.Close()
+			}
+		}
+		if err != nil {
+			return 0, err
+		}
+		block := avroReader{b: data}
+		for i := 0; i < n; i++ {
+			block.skip(opts.parsed.root)
+		}
+		if block.err != nil || len(block.b) != 0 {
+			return 0, fmt.Errorf("block of %d records: %v, %d bytes left", n, block.err, len(block.b))
+		}
+		records += n
+	}
+	return records, r.err
+}
+
+type avroReader struct {
+	b   []byte
+	err error
+}
+
+func (r *avroReader) next(n int) []byte {
+	if r.err != nil || n < 0 || n > len(r.b) {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *avroReader) long() int64 {
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	r.b = r.b[n:]
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// skip reads a value of the type.
+func (r *avroReader) skip(t *avroType) {
+	switch t.kind {
+	case "boolean":
+		r.next(1)
+	case "int", "long", "enum":
+		r.long()
+	case "float":
+		r.next(4)
+	case "double":
+		r.next(8)
+	case "bytes", "string":
+		r.next(int(r.long()))
+	case "fixed":
+		r.next(t.size)
+	case "record":
+		for _, f := range t.fields {
+			r.skip(f)
+		}
+	case "array", "map":
+		for n := r.long(); n != 0 && r.err == nil; n = r.long() {
+			for i := int64(0); i < n; i++ {
+				if t.kind == "map" {
+					r.next(int(r.long()))
+				}
+				r.skip(t.items)
+			}
+		}
+	case "union":
+		i := r.long()
+		if i < 0 || int(i) >= len(t.branches) {
+			r.err = fmt.Errorf("union index %d", i)
+			return
+		}
+		r.skip(t.branches[i])
+	}
+}
@@ -74,6 +74,9 @@ func (c *circularBuffer) Seek(offset int64, whence int) (n int64, err error) {
 	if c.read < 0 {
 		return 0, errors.New("circularBuffer.Seek: negative position")
 	}
+	if len(c.data) > 0 {
+		c.left = c.data[c.read%int64(len(c.data)):]
+	}
 	return c.read, nil
 }
 
@@ -0,0 +1,201 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// compTargetSegment is the size of segments in data generated for a compression target.
+	// Each segment starts with random bytes, followed by copies of previous data.
+	compTargetSegment = 4096
+
+	// compTargetSample is the size of the sample compressed to find the number of random bytes.
+	compTargetSample = 1 << 20
+)
+
+// compressors contain the algorithms compression targets can be given for.
+var compressors = map[string]func([]byte) []byte{
+	"zstd": func(b []byte) []byte {
+		enc, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		defer enc.Close()
+		return enc.EncodeAll(b, nil)
+	},
+	"gzip": func(b []byte) []byte {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(b)
+		w.Close()
+		return buf.Bytes()
+	},
+	"zlib": func(b []byte) []byte {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		w.Write(b)
+		w.Close()
+		return buf.Bytes()
+	},
+	"snappy": func(b []byte) []byte {
+		return snappy.Encode(nil, b)
+	},
+	"s2": func(b []byte) []byte {
+		return s2.Encode(nil, b)
+	},
+}
+
+// CompressionAlgorithms returns the algorithms compression targets can be given for.
+func CompressionAlgorithms() []string {
+	res := make([]string, 0, len(compressors))
+	for algo := range compressors {
+		res = append(res, algo)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// CompressionTarget is a compression ratio of generated data with a specific algorithm.
+type CompressionTarget struct {
+	Algo  string
+	Ratio float64
+}
+
+// ParseCompressionTarget parses a compression target as 'algorithm:ratio'.
+// Example: "zstd:2.5".
+func ParseCompressionTarget(s string) (CompressionTarget, error) {
+	algo, ratio, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return CompressionTarget{}, fmt.Errorf("compression target %q: want algorithm:ratio", s)
+	}
+	t := CompressionTarget{Algo: strings.ToLower(algo)}
+	var err error
+	t.Ratio, err = strconv.ParseFloat(ratio, 64)
+	if err != nil {
+		return CompressionTarget{}, fmt.Errorf("compression target %q: %w", s, err)
+	}
+	return t, t.validate()
+}
+
+func (t CompressionTarget) validate() error {
+	if _, ok := compressors[t.Algo]; !ok {
+		return fmt.Errorf("compression target: unsupported algorithm %q; supported: %s", t.Algo, strings.Join(CompressionAlgorithms(), ", "))
+	}
+	if t.Ratio < 1 {
+		return fmt.Errorf("compression target: ratio %v < 1", t.Ratio)
+	}
+	return nil
+}
+
+// String returns the target as 'algorithm:ratio'.
+func (t CompressionTarget) String() string {
+	return t.Algo + ":" + strconv.FormatFloat(t.Ratio, 'f', -1, 64)
+}
+
+// compTarget is a compression target with the number of random bytes
+// in each segment found by compressing samples.
+type compTarget struct {
+	CompressionTarget
+	literals int
+	measured float64
+}
+
+// WithCompressionTarget makes generated text data compress with the target ratio using the target algorithm.
+// The data is adjusted by compressing samples, which may take a moment.
+// Objects smaller than a few kilobytes will compress less.
+func WithCompressionTarget(t CompressionTarget) Option {
+	return func(o *Options) error {
+		if t.Algo == "" {
+			return nil
+		}
+		if err := t.validate(); err != nil {
+			return err
+		}
+		ct, err := calibrateCompTarget(t)
+		if err != nil {
+			return err
+		}
+		o.compTarget = ct
+		return nil
+	}
+}
+
+// calibrateCompTarget finds the number of random bytes in each segment
+// that gives the ratio closest to the target.
+func calibrateCompTarget(t CompressionTarget) (compTarget, error) {
+	compress := compressors[t.Algo]
+	sample := make([]byte, compTargetSample)
+	measure := func(literals int) float64 {
+		genCompTargetData(sample, rand.New(rand.NewSource(int64(literals))), literals)
+		return float64(len(sample)) / float64(len(compress(sample)))
+	}
+
+	// The ratio decreases when the number of random bytes increases.
+	lo, hi := 1, compTargetSegment
+	loRatio, hiRatio := measure(lo), measure(hi)
+	if t.Ratio > loRatio {
+		return compTarget{}, fmt.Errorf("compression target %v: highest ratio possible is %.2f", t, loRatio)
+	}
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		r := measure(mid)
+		if r >= t.Ratio {
+			lo, loRatio = mid, r
+		} else {
+			hi, hiRatio = mid, r
+		}
+	}
+	if loRatio-t.Ratio > t.Ratio-hiRatio {
+		return compTarget{CompressionTarget: t, literals: hi, measured: hiRatio}, nil
+	}
+	return compTarget{CompressionTarget: t, literals: lo, measured: loRatio}, nil
+}
+
+// genCompTargetData fills dst with segments that start with the number of random bytes given.
+// The rest of each segment is copied from up to a segment back, which all algorithms can find.
+func genCompTargetData(dst []byte, rng *rand.Rand, literals int) {
+	for off := 0; off < len(dst); off += compTargetSegment {
+		end := off + compTargetSegment
+		if end > len(dst) {
+			end = len(dst)
+		}
+		pos := off + literals
+		if pos > end {
+			pos = end
+		}
+		rng.Read(dst[off:pos])
+		back := pos
+		if back > compTargetSegment {
+			back = compTargetSegment
+		}
+		dist := 1 + rng.Intn(back)
+		for pos < end {
+			pos += copy(dst[pos:end], dst[pos-dist:pos])
+		}
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"io"
+	"math"
+	"testing"
+)
+
+func TestWithCompressionTarget(t *testing.T) {
+	for _, target := range []string{"zstd:2.5", "gzip:4", "snappy:3", "s2:10", "zlib:1"} {
+		ct, err := ParseCompressionTarget(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src, err := New(WithTextData().Apply(), WithSize(4<<20), WithCompressionTarget(ct))
+		if err != nil {
+			t.Fatal(target, err)
+		}
+		b, err := io.ReadAll(src.Object().Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := float64(len(b)) / float64(len(compressors[ct.Algo](b)))
+		if math.Abs(got-ct.Ratio)/ct.Ratio > 0.1 {
+			t.Errorf("%s: got ratio %.2f", target, got)
+		}
+	}
+
+	for _, bad := range []string{"zstd", "lz4:2", "zstd:0.5", "zstd:x"} {
+		if _, err := ParseCompressionTarget(bad); err == nil {
+			t.Errorf("%q: want error", bad)
+		}
+	}
+	if _, err := New(WithTextData().Apply(), WithCompressionTarget(CompressionTarget{Algo: "snappy", Ratio: 1000})); err == nil {
+		t.Error("want error for unreachable ratio")
+	}
+}
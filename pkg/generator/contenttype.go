@@ -0,0 +1,111 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"mime"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentTypesFromExt sets the content type of objects from the extension of their names.
+const ContentTypesFromExt = "ext"
+
+// contentTypes picks content types of objects from a weighted list.
+type contentTypes struct {
+	types []string
+	// cum contains the cumulative weight of types.
+	cum []float64
+	// fromExt sets the content type from the extension of names instead.
+	fromExt bool
+}
+
+// WithContentTypes sets the content type of objects from a weighted list,
+// like '60% image/jpeg, 30% application/pdf, 10% video/mp4',
+// instead of the content type of the generator.
+// Weights are relative and do not need to add up to 100.
+// With ContentTypesFromExt the content type is found from the extension of object names,
+// and names with unknown extensions keep the content type of the generator.
+// An empty list keeps the content type of the generator.
+func WithContentTypes(s string) Option {
+	return func(o *Options) error {
+		switch strings.TrimSpace(s) {
+		case "":
+			o.contentTypes = nil
+			return nil
+		case ContentTypesFromExt:
+			o.contentTypes = &contentTypes{fromExt: true}
+			return nil
+		}
+		c, err := parseContentTypes(s)
+		if err != nil {
+			return err
+		}
+		o.contentTypes = c
+		return nil
+	}
+}
+
+func parseContentTypes(s string) (*contentTypes, error) {
+	var c contentTypes
+	total := 0.0
+	for _, entry := range strings.Split(s, ",") {
+		fields := strings.Fields(entry)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("content types: want weight and content type, got %q", strings.TrimSpace(entry))
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSuffix(fields[0], "%"), 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("content types: invalid weight %q", fields[0])
+		}
+		if typ, sub, ok := strings.Cut(fields[1], "/"); !ok || typ == "" || sub == "" {
+			return nil, fmt.Errorf("content types: invalid content type %q", fields[1])
+		}
+		total += weight
+		c.types = append(c.types, fields[1])
+		c.cum = append(c.cum, total)
+	}
+	if total <= 0 {
+		return nil, errors.New("content types: total weight is 0")
+	}
+	return &c, nil
+}
+
+// fromName returns the content type of the extension of the name,
+// or def if the extension is unknown.
+func (c *contentTypes) fromName(name, def string) string {
+	if typ := mime.TypeByExtension(path.Ext(name)); typ != "" {
+		return typ
+	}
+	return def
+}
+
+// pick returns a random content type.
+func (c *contentTypes) pick(rng *rand.Rand) string {
+	v := rng.Float64() * c.cum[len(c.cum)-1]
+	i := sort.Search(len(c.cum), func(i int) bool { return c.cum[i] > v })
+	if i >= len(c.types) {
+		i = len(c.types) - 1
+	}
+	return c.types[i]
+}
@@ -0,0 +1,79 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"testing"
+)
+
+func TestWithContentTypes(t *testing.T) {
+	src, err := New(WithRandomData().Apply(), WithSize(10), WithContentTypes("60% image/jpeg, 30% application/pdf,10% video/mp4, 0% text/plain"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 10000
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		counts[src.Object().ContentType]++
+	}
+	want := map[string]int{"image/jpeg": 6000, "application/pdf": 3000, "video/mp4": 1000}
+	if len(counts) != len(want) {
+		t.Fatalf("got content types %v", counts)
+	}
+	for typ, w := range want {
+		if got := counts[typ]; got < w*9/10 || got > w*11/10 {
+			t.Errorf("%s: got %d, want about %d", typ, got, w)
+		}
+	}
+
+	src, err = New(WithRandomData().Apply(), WithContentTypes(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ := src.Object().ContentType; typ != "application/octet-stream" {
+		t.Errorf("got default content type %q", typ)
+	}
+	for _, s := range []string{"image/jpeg", "50% image", "x% image/jpeg", "-1% image/jpeg", "0% image/jpeg", "50% /jpeg"} {
+		if _, err := New(WithRandomData().Apply(), WithContentTypes(s)); err == nil {
+			t.Errorf("%q: want error", s)
+		}
+	}
+}
+
+func TestContentTypesFromExt(t *testing.T) {
+	tests := []struct {
+		gen  Option
+		want string
+	}{
+		{gen: WithJSONData().Apply(), want: "application/json"},
+		{gen: WithXMLData().Apply(), want: "text/xml; charset=utf-8"},
+		// Unknown extensions keep the content type of the generator.
+		{gen: WithRandomData().Apply(), want: "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		src, err := New(tt.gen, WithSize(1000), WithContentTypes(ContentTypesFromExt))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			if obj := src.Object(); obj.ContentType != tt.want {
+				t.Errorf("%s: got content type %q, want %q", obj.Name, obj.ContentType, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,316 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// WithCorpusData returns options for serving the files in a local directory tree.
+// The relative path of each file is used as object name.
+func WithCorpusData(dir string) CorpusOpts {
+	return CorpusOpts{dir: dir}
+}
+
+// Apply corpus options.
+// The directory is read when the options are applied
+// and all sources created from the options share the files,
+// so each file is served once by one of the sources before files are served again.
+func (o CorpusOpts) Apply() Option {
+	return func(opts *Options) error {
+		files, err := readCorpus(o.dir)
+		if err != nil {
+			return err
+		}
+		if o.shuffle {
+			rng := rand.New(rand.NewSource(int64(rand.Uint64())))
+			if o.seed != nil {
+				rng = rand.New(rand.NewSource(*o.seed))
+			}
+			rng.Shuffle(len(files), func(i, j int) { files[i], files[j] = files[j], files[i] })
+		}
+		o.state = &corpusState{files: files}
+		opts.corpus = o
+		opts.src = newCorpus
+		return nil
+	}
+}
+
+// Shuffle serves the files in random order instead of sorted by path.
+func (o CorpusOpts) Shuffle(b bool) CorpusOpts {
+	o.shuffle = b
+	return o
+}
+
+// Loop serves the files again when all have been served.
+// Without looping Object returns nil when all files have been served.
+func (o CorpusOpts) Loop(b bool) CorpusOpts {
+	o.loop = b
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make the shuffled order predictable.
+func (o CorpusOpts) RngSeed(s int64) CorpusOpts {
+	o.seed = &s
+	return o
+}
+
+// CorpusOpts are the options for the corpus data source.
+type CorpusOpts struct {
+	dir     string
+	shuffle bool
+	loop    bool
+	seed    *int64
+	state   *corpusState
+}
+
+// corpusFile is a file in the corpus.
+type corpusFile struct {
+	// path is the file path, name is the slash separated path relative to the corpus directory.
+	path, name string
+	size       int64
+}
+
+// corpusState is shared by all sources of the corpus.
+type corpusState struct {
+	files []corpusFile
+	next  uint64
+}
+
+// readCorpus returns the regular files in the directory tree, sorted by path.
+func readCorpus(dir string) ([]corpusFile, error) {
+	if dir == "" {
+		return nil, errors.New("corpus: no directory")
+	}
+	var files []corpusFile
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, corpusFile{path: p, name: filepath.ToSlash(rel), size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("corpus: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("corpus: no files in %s", dir)
+	}
+	return files, nil
+}
+
+// CorpusNames returns the object names of the files in the corpus directory,
+// relative to the prefix of the objects.
+func CorpusNames(dir string) ([]string, error) {
+	files, err := readCorpus(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.name
+	}
+	return names, nil
+}
+
+type corpusSrc struct {
+	o     Options
+	state *corpusState
+	obj   Object
+	// r reads the file of the last object.
+	r *corpusReader
+}
+
+func newCorpus(o Options) (Source, error) {
+	c := corpusSrc{
+		o:     o,
+		state: o.corpus.state,
+	}
+	c.obj.setPrefix(o)
+	return &c, nil
+}
+
+// Object returns the next file of the corpus.
+// If the files cannot be read, an object returning the error when read is returned.
+// The file is closed when it has been read, or when the next object is returned.
+func (c *corpusSrc) Object() *Object {
+	if c.r != nil {
+		c.r.Close()
+		c.r = nil
+	}
+	idx := atomic.AddUint64(&c.state.next, 1) - 1
+	if idx >= uint64(len(c.state.files)) && !c.o.corpus.loop {
+		return nil
+	}
+	file := c.state.files[idx%uint64(len(c.state.files))]
+	c.obj.ContentType = mime.TypeByExtension(path.Ext(file.name))
+	if c.obj.ContentType == "" {
+		c.obj.ContentType = "application/octet-stream"
+	}
+	c.obj.setName(file.name)
+	c.obj.Size = file.size
+	f, err := os.Open(file.path)
+	if err != nil {
+		c.obj.Reader = errReader{err: err}
+		return &c.obj
+	}
+	c.r = &corpusReader{path: file.path, size: file.size, f: f}
+	c.obj.Reader = c.r
+	return &c.obj
+}
+
+// corpusReader reads a corpus file.
+// The file is closed when it has been read to the end,
+// and opened again if it is read after seeking back.
+type corpusReader struct {
+	mu   sync.Mutex
+	path string
+	size int64
+	off  int64
+	f    *os.File
+}
+
+// open the file if it has been closed.
+func (r *corpusReader) open() error {
+	if r.f != nil {
+		return nil
+	}
+	f, err := os.Open(r.path)
+	if err != nil {
+		return err
+	}
+	r.f = f
+	return nil
+}
+
+func (r *corpusReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.off >= r.size {
+		r.close()
+		return 0, io.EOF
+	}
+	if err := r.open(); err != nil {
+		return 0, err
+	}
+	if rem := r.size - r.off; int64(len(p)) > rem {
+		p = p[:rem]
+	}
+	n, err := r.f.ReadAt(p, r.off)
+	r.off += int64(n)
+	if err == io.EOF && r.off < r.size {
+		err = io.ErrUnexpectedEOF
+	}
+	if r.off >= r.size {
+		r.close()
+		if err == io.EOF {
+			err = nil
+		}
+	}
+	return n, err
+}
+
+func (r *corpusReader) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	if err := r.open(); err != nil {
+		return 0, err
+	}
+	return io.NewSectionReader(r.f, 0, r.size).ReadAt(p, off)
+}
+
+func (r *corpusReader) Seek(offset int64, whence int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.off
+	case io.SeekEnd:
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, errors.New("corpus: negative position")
+	}
+	r.off = offset
+	return offset, nil
+}
+
+// Close the file.
+func (r *corpusReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.close()
+}
+
+func (r *corpusReader) close() error {
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+func (c *corpusSrc) String() string {
+	var total int64
+	for _, f := range c.state.files {
+		total += f.size
+	}
+	return fmt.Sprintf("Corpus of %d files, %d bytes in %s", len(c.state.files), total, c.o.corpus.dir)
+}
+
+func (c *corpusSrc) Prefix() string {
+	return c.obj.Prefix
+}
+
+// errReader returns an error on all calls.
+type errReader struct {
+	err error
+}
+
+func (e errReader) Read([]byte) (int, error) {
+	return 0, e.err
+}
+
+func (e errReader) Seek(int64, int) (int64, error) {
+	return 0, e.err
+}
@@ -0,0 +1,99 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithCorpusData(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt":          "first file",
+		"sub/b.json":     `{"b":1}`,
+		"sub/deep/c.bin": "",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names, err := CorpusNames(dir)
+	if err != nil || len(names) != len(files) || names[0] != "a.txt" {
+		t.Fatalf("got names %v, %v", names, err)
+	}
+
+	for _, shuffle := range []bool{false, true} {
+		src, err := New(WithCorpusData(dir).Shuffle(shuffle).RngSeed(1).Apply(), WithPrefixSize(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := make(map[string]bool)
+		for obj := src.Object(); obj != nil; obj = src.Object() {
+			want, ok := files[obj.Name]
+			if !ok || seen[obj.Name] {
+				t.Fatalf("unexpected object %q", obj.Name)
+			}
+			seen[obj.Name] = true
+			b, err := io.ReadAll(obj.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != want || obj.Size != int64(len(want)) {
+				t.Errorf("%s: got %q, size %d", obj.Name, b, obj.Size)
+			}
+			if r := obj.Reader.(*corpusReader); r.f != nil {
+				t.Errorf("%s: file not closed after reading", obj.Name)
+			}
+			if _, err := obj.Reader.Seek(0, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+			if b, err := io.ReadAll(obj.Reader); err != nil || string(b) != want {
+				t.Errorf("%s: got %q, %v after seeking back", obj.Name, b, err)
+			}
+		}
+		if len(seen) != len(files) {
+			t.Errorf("got %d objects, want %d", len(seen), len(files))
+		}
+	}
+
+	src, err := New(WithCorpusData(dir).Loop(true).Apply(), WithPrefixSize(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2*len(files); i++ {
+		if obj := src.Object(); obj == nil {
+			t.Fatal("looping corpus returned nil")
+		}
+	}
+	if obj := src.Object(); obj.Name != "a.txt" || obj.ContentType != "text/plain; charset=utf-8" {
+		t.Errorf("got %q, content type %q", obj.Name, obj.ContentType)
+	}
+
+	if _, err := New(WithCorpusData(t.TempDir()).Apply()); err == nil {
+		t.Error("want error for empty directory")
+	}
+}
@@ -0,0 +1,232 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/secure-io/sio-go"
+)
+
+// ClientEncryptionKeySize is the size of client encryption keys.
+const ClientEncryptionKeySize = 32
+
+// cseChunk is the size of the chunks encrypted and authenticated separately.
+const cseChunk = sio.BufSize
+
+// ClientEncryption encrypts objects on the client with AES-256-GCM before they are uploaded.
+//
+// Encrypted objects start with the nonce of the stream.
+// With a static key all objects are encrypted with the key.
+// With per object keys each object is encrypted with a random key,
+// which is stored after the nonce, encrypted with the key.
+type ClientEncryption struct {
+	perObject bool
+	// key encrypts objects, or the keys of objects.
+	key   []byte
+	aead  cipher.AEAD
+	nonce int
+}
+
+// NewClientEncryption returns client encryption with the key.
+// If perObject is set, each object is encrypted with its own random key.
+func NewClientEncryption(key []byte, perObject bool) (*ClientEncryption, error) {
+	if len(key) != ClientEncryptionKeySize {
+		return nil, fmt.Errorf("client encryption: key must be %d bytes, got %d", ClientEncryptionKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	c := ClientEncryption{perObject: perObject, key: append([]byte{}, key...), aead: aead}
+	stream, _ := sio.AES_256_GCM.Stream(c.key)
+	c.nonce = stream.NonceSize()
+	return &c, nil
+}
+
+// headerSize returns the size of the data before the encrypted stream.
+func (c *ClientEncryption) headerSize() int64 {
+	if c.perObject {
+		return int64(c.nonce + c.aead.NonceSize() + ClientEncryptionKeySize + c.aead.Overhead())
+	}
+	return int64(c.nonce)
+}
+
+// EncryptedSize returns the size of an object of the size when encrypted.
+func (c *ClientEncryption) EncryptedSize(size int64) int64 {
+	chunks := (size + cseChunk - 1) / cseChunk
+	if chunks == 0 {
+		chunks = 1
+	}
+	return c.headerSize() + size + chunks*int64(c.aead.Overhead())
+}
+
+// PlainSize returns the size of the content of an encrypted object of the size.
+func (c *ClientEncryption) PlainSize(size int64) int64 {
+	size -= c.headerSize()
+	chunks := (size + cseChunk + int64(c.aead.Overhead()) - 1) / int64(cseChunk+c.aead.Overhead())
+	return size - chunks*int64(c.aead.Overhead())
+}
+
+// String returns a description of the encryption.
+func (c *ClientEncryption) String() string {
+	if c.perObject {
+		return "client encrypted with per object keys"
+	}
+	return "client encrypted with a static key"
+}
+
+// newStream returns the header of a new object and the stream to encrypt it with.
+func (c *ClientEncryption) newStream() ([]byte, *sio.Stream, error) {
+	header := make([]byte, c.headerSize())
+	if _, err := io.ReadFull(rand.Reader, header[:c.nonce]); err != nil {
+		return nil, nil, err
+	}
+	key := c.key
+	if c.perObject {
+		key = make([]byte, ClientEncryptionKeySize)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, nil, err
+		}
+		keyNonce := header[c.nonce : c.nonce+c.aead.NonceSize()]
+		if _, err := io.ReadFull(rand.Reader, keyNonce); err != nil {
+			return nil, nil, err
+		}
+		// The encrypted key is written to the header after its nonce.
+		c.aead.Seal(keyNonce[len(keyNonce):], keyNonce, key, nil)
+	}
+	stream, err := sio.AES_256_GCM.Stream(key)
+	return header, stream, err
+}
+
+// Decrypt returns the content of the encrypted object read from r.
+func (c *ClientEncryption) Decrypt(r io.Reader) (io.Reader, error) {
+	header := make([]byte, c.headerSize())
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	key := c.key
+	if c.perObject {
+		keyNonce := header[c.nonce : c.nonce+c.aead.NonceSize()]
+		var err error
+		key, err = c.aead.Open(nil, keyNonce, header[c.nonce+len(keyNonce):], nil)
+		if err != nil {
+			return nil, fmt.Errorf("client encryption: unable to decrypt object key: %w", err)
+		}
+	}
+	stream, err := sio.AES_256_GCM.Stream(key)
+	if err != nil {
+		return nil, err
+	}
+	return stream.DecryptReader(r, header[:c.nonce], nil), nil
+}
+
+// WithClientEncryption encrypts the content of objects on the client before they are uploaded.
+// Objects are encrypted while they are uploaded, and Size is the encrypted size.
+// A nil encryption uploads objects as generated.
+func WithClientEncryption(c *ClientEncryption) Option {
+	return func(o *Options) error {
+		o.cse = c
+		return nil
+	}
+}
+
+// encryptedSrc encrypts the objects of a source.
+type encryptedSrc struct {
+	Source
+	cse *ClientEncryption
+	rd  encryptReader
+}
+
+func (e *encryptedSrc) Object() *Object {
+	obj := e.Source.Object()
+	if obj == nil {
+		return nil
+	}
+	e.rd = encryptReader{src: obj.Reader, size: e.cse.EncryptedSize(obj.Size)}
+	e.rd.header, e.rd.stream, e.rd.err = e.cse.newStream()
+	e.rd.reset()
+	obj.Size = e.rd.size
+	obj.Reader = &e.rd
+	return obj
+}
+
+func (e *encryptedSrc) String() string {
+	return e.Source.String() + "; " + e.cse.String()
+}
+
+// encryptReader encrypts the content of an object while it is read.
+// It can only seek to the start or the current position, which is enough for retries.
+type encryptReader struct {
+	src    io.ReadSeeker
+	header []byte
+	stream *sio.Stream
+	enc    io.Reader
+	size   int64
+	read   int64
+	err    error
+}
+
+func (r *encryptReader) reset() {
+	if r.err != nil {
+		return
+	}
+	r.read = 0
+	_, r.err = r.src.Seek(0, io.SeekStart)
+	// The header starts with the nonce of the stream.
+	r.enc = io.MultiReader(bytes.NewReader(r.header), r.stream.EncryptReader(r.src, r.header[:r.stream.NonceSize()], nil))
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.enc.Read(p)
+	r.read += int64(n)
+	return n, err
+}
+
+func (r *encryptReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.read
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("encryptReader.Seek: invalid whence")
+	}
+	switch offset {
+	case r.read:
+	case 0:
+		r.reset()
+	default:
+		return r.read, errors.New("encryptReader.Seek: can only seek to the start")
+	}
+	return r.read, r.err
+}
@@ -0,0 +1,98 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestWithClientEncryption(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, ClientEncryptionKeySize)
+	for _, perObject := range []bool{false, true} {
+		cse, err := NewClientEncryption(key, perObject)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, size := range []int64{0, 1, cseChunk - 1, cseChunk, cseChunk + 1, 5 * cseChunk} {
+			if got := cse.PlainSize(cse.EncryptedSize(size)); got != size {
+				t.Errorf("per object %v: plain size of %d is %d", perObject, size, got)
+			}
+		}
+
+		const size = 100003
+		src, err := New(WithNamedData().Seed(1).Apply(), WithSize(size), WithClientEncryption(cse))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var previous []byte
+		for i := 0; i < 2; i++ {
+			obj := src.Object()
+			if obj.Size != cse.EncryptedSize(size) {
+				t.Fatalf("per object %v: size %d, want %d", perObject, obj.Size, cse.EncryptedSize(size))
+			}
+			enc, err := io.ReadAll(obj.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int64(len(enc)) != obj.Size {
+				t.Fatalf("per object %v: read %d bytes, want %d", perObject, len(enc), obj.Size)
+			}
+			if bytes.Equal(enc, previous) {
+				t.Fatal("objects encrypted the same")
+			}
+			previous = enc
+
+			// Retries read the same data again.
+			if _, err := obj.Reader.Seek(0, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+			again, err := io.ReadAll(obj.Reader)
+			if err != nil || !bytes.Equal(enc, again) {
+				t.Fatalf("per object %v: data differs after seek: %v", perObject, err)
+			}
+			if _, err := obj.Reader.Seek(10, io.SeekStart); err == nil {
+				t.Error("want error seeking into the object")
+			}
+
+			dec, err := cse.Decrypt(bytes.NewReader(enc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(dec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, _ := io.ReadAll(NamedContent(1, obj.Name, size))
+			if !bytes.Equal(got, want) {
+				t.Fatalf("per object %v: decrypted content does not match", perObject)
+			}
+
+			other, _ := NewClientEncryption(bytes.Repeat([]byte{8}, ClientEncryptionKeySize), perObject)
+			if dec, err := other.Decrypt(bytes.NewReader(enc)); err == nil {
+				if _, err := io.ReadAll(dec); err == nil {
+					t.Errorf("per object %v: decrypted with other key", perObject)
+				}
+			}
+		}
+	}
+	if _, err := NewClientEncryption([]byte("short"), false); err == nil {
+		t.Error("want error for short key")
+	}
+}
@@ -21,13 +21,21 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"strings"
 )
 
-// WithCSV returns default CSV Opts
-func WithCSV() CsvOpts {
+// WithCSVData returns default CSV Opts.
+func WithCSVData() CsvOpts {
 	return csvOptsDefaults()
 }
 
+// WithCSV returns default CSV Opts.
+//
+// Deprecated: Use WithCSVData.
+func WithCSV() CsvOpts {
+	return WithCSVData()
+}
+
 // Apply applies all the opts for CSVOpts
 func (o CsvOpts) Apply() Option {
 	return func(opts *Options) error {
@@ -41,33 +49,53 @@ func (o CsvOpts) Apply() Option {
 }
 
 func (o CsvOpts) validate() error {
-	if o.rows < 0 {
+	if o.rows <= 0 {
 		return errors.New("csv: rows <= 0")
 	}
-	if o.cols < 0 {
+	if o.cols <= 0 {
 		return errors.New("csv: cols <= 0")
 	}
+	if o.minLen <= 0 {
+		return errors.New("csv: field length <= 0")
+	}
 	if o.minLen > o.maxLen {
-		return fmt.Errorf("WithCSV.FieldLen: min:%d > max:%d", o.minLen, o.maxLen)
+		return fmt.Errorf("csv: field length min:%d > max:%d", o.minLen, o.maxLen)
+	}
+	if o.comma >= 0x80 || o.comma == '\n' || o.comma == '\r' || o.comma == '"' || strings.IndexByte(asciiLetters, o.comma) >= 0 {
+		return fmt.Errorf("csv: invalid delimiter %q", o.comma)
 	}
 
 	return nil
 }
 
-// Size sets the size of generated CSV.
+// Size sets the number of columns and rows of generated CSV.
+// The rows are repeated until the object size has been reached.
 func (o CsvOpts) Size(cols, rows int) CsvOpts {
 	o.rows = rows
 	o.cols = cols
 	return o
 }
 
-// Comma sets the comma character. Only ASCII values should be used.
+// Columns sets the number of columns.
+func (o CsvOpts) Columns(cols int) CsvOpts {
+	o.cols = cols
+	return o
+}
+
+// Rows sets the number of distinct rows.
+func (o CsvOpts) Rows(rows int) CsvOpts {
+	o.rows = rows
+	return o
+}
+
+// Comma sets the delimiter between fields.
+// Only ASCII values that cannot occur in fields are allowed.
 func (o CsvOpts) Comma(c byte) CsvOpts {
 	o.comma = c
 	return o
 }
 
-// FieldLen sets the length of each field.
+// FieldLen sets the minimum and maximum length of each field.
 func (o CsvOpts) FieldLen(min, max int) CsvOpts {
 	o.minLen = min
 	o.maxLen = max
@@ -115,8 +143,8 @@ func newCsv(o Options) (Source, error) {
 	c := csvSource{
 		o: o,
 	}
-	c.builder = make([]byte, 0, o.csv.maxLen+1)
-	c.buf = newCircularBuffer(make([]byte, o.csv.maxLen*(o.csv.cols+1)*(o.csv.rows+1)), o.totalSize)
+	c.builder = make([]byte, o.csv.maxLen+1)
+	c.buf = newCircularBuffer(make([]byte, (o.csv.maxLen+1)*o.csv.cols*o.csv.rows), o.totalSize)
 	rndSrc := rand.NewSource(int64(rand.Uint64()))
 	if o.csv.seed != nil {
 		rndSrc = rand.NewSource(*o.csv.seed)
@@ -137,7 +165,7 @@ func (c *csvSource) Object() *Object {
 		for j := 0; j < opts.cols; j++ {
 			fieldLen := 1 + opts.minLen
 			if opts.minLen != opts.maxLen {
-				fieldLen += c.rng.Intn(opts.maxLen - opts.minLen)
+				fieldLen += c.rng.Intn(opts.maxLen - opts.minLen + 1)
 			}
 			build := c.builder[:fieldLen]
 			randASCIIBytes(build[:fieldLen-1], c.rng)
@@ -157,7 +185,7 @@ func (c *csvSource) Object() *Object {
 }
 
 func (c *csvSource) String() string {
-	return fmt.Sprintf("CSV data. %d columns, %d rows.", c.o.csv.cols, c.o.csv.rows)
+	return fmt.Sprintf("CSV data. %d columns, %d rows, delimiter %q.", c.o.csv.cols, c.o.csv.rows, c.o.csv.comma)
 }
 
 func (c *csvSource) Prefix() string {
@@ -0,0 +1,256 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync/atomic"
+)
+
+// WithDedupData returns default deduplication data options.
+// Objects are built from chunks, where chunks are reused across objects
+// so the data deduplicates with the selected ratio.
+func WithDedupData() DedupOpts {
+	return dedupOptsDefaults()
+}
+
+// Apply deduplication data options.
+func (o DedupOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		opts.dedup = o
+		opts.src = newDedup
+		return nil
+	}
+}
+
+func (o DedupOpts) validate() error {
+	if o.ratio < 1 {
+		return errors.New("dedup: ratio < 1")
+	}
+	if o.chunkSize <= 0 {
+		return errors.New("dedup: chunk size <= 0")
+	}
+	return nil
+}
+
+// Ratio sets the deduplication ratio.
+// A ratio of 4 means that 4 chunks are written for every unique chunk.
+// A ratio of 1 gives data without duplicate chunks.
+func (o DedupOpts) Ratio(r float64) DedupOpts {
+	o.ratio = r
+	return o
+}
+
+// ChunkSize sets the size of the chunks objects are built from.
+// Chunks are aligned to the start of objects.
+func (o DedupOpts) ChunkSize(n int) DedupOpts {
+	o.chunkSize = n
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make usage predictable.
+// Sources with the same seed will generate the same chunks.
+func (o DedupOpts) RngSeed(s int64) DedupOpts {
+	o.seed = &s
+	return o
+}
+
+// DedupOpts are the options for the deduplication data source.
+type DedupOpts struct {
+	seed      *int64
+	ratio     float64
+	chunkSize int
+}
+
+func dedupOptsDefaults() DedupOpts {
+	return DedupOpts{
+		seed:      nil,
+		ratio:     4,
+		chunkSize: 64 << 10,
+	}
+}
+
+type dedupSrc struct {
+	counter uint64
+	o       Options
+	rng     *rand.Rand
+	obj     Object
+	rd      dedupReader
+	// chunks is the number of unique chunks generated.
+	chunks uint64
+}
+
+func newDedup(o Options) (Source, error) {
+	rndSrc := rand.NewSource(int64(rand.Uint64()))
+	if o.dedup.seed != nil {
+		rndSrc = rand.NewSource(*o.dedup.seed)
+	}
+	rng := rand.New(rndSrc)
+	unique := o.dedup.chunkSize
+	if o.compRatio > 0 {
+		unique /= o.compRatio
+		if unique < 1 {
+			unique = 1
+		}
+	}
+	d := dedupSrc{
+		o:   o,
+		rng: rng,
+		rd: dedupReader{
+			seed:   rng.Uint64(),
+			size:   o.dedup.chunkSize,
+			unique: unique,
+			buf:    make([]byte, o.dedup.chunkSize),
+		},
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "application/octet-stream",
+			Size:        0,
+		},
+	}
+	d.obj.setPrefix(o)
+	return &d, nil
+}
+
+func (d *dedupSrc) Object() *Object {
+	atomic.AddUint64(&d.counter, 1)
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], d.rng)
+	d.obj.Size = d.o.getSize(d.rng)
+	d.obj.setName(fmt.Sprintf("%d.%s.rnd", atomic.LoadUint64(&d.counter), string(nBuf[:])))
+
+	// Pick the chunks of the object.
+	// Each chunk is new with a probability of 1/ratio,
+	// otherwise a random previous chunk is reused.
+	n := int((d.obj.Size + int64(d.rd.size) - 1) / int64(d.rd.size))
+	d.rd.chunks = d.rd.chunks[:0]
+	for i := 0; i < n; i++ {
+		if d.chunks == 0 || d.rng.Float64()*d.o.dedup.ratio < 1 {
+			d.rd.chunks = append(d.rd.chunks, d.chunks)
+			d.chunks++
+			continue
+		}
+		d.rd.chunks = append(d.rd.chunks, uint64(d.rng.Int63n(int64(d.chunks))))
+	}
+	d.obj.Reader = d.rd.reset(d.obj.Size)
+	return &d.obj
+}
+
+func (d *dedupSrc) String() string {
+	if d.o.randSize {
+		return fmt.Sprintf("Dedup data; ratio %.1f:1, %d byte chunks; random size up to %d bytes", d.o.dedup.ratio, d.rd.size, d.o.totalSize)
+	}
+	return fmt.Sprintf("Dedup data; ratio %.1f:1, %d byte chunks; %d bytes total", d.o.dedup.ratio, d.rd.size, d.o.totalSize)
+}
+
+func (d *dedupSrc) Prefix() string {
+	return d.obj.Prefix
+}
+
+// dedupReader returns the chunks of an object.
+// The content of each chunk is generated from the chunk number.
+type dedupReader struct {
+	seed   uint64
+	size   int
+	unique int
+	chunks []uint64
+
+	// buf contains the chunk at index bufIdx.
+	buf    []byte
+	bufIdx int
+
+	want, read int64
+}
+
+func (r *dedupReader) reset(want int64) io.ReadSeeker {
+	r.want = want
+	r.read = 0
+	r.bufIdx = -1
+	return r
+}
+
+// fill generates the content of the chunk with the given number into r.buf.
+// With compression the first r.unique bytes are repeated.
+func (r *dedupReader) fill(chunk uint64) {
+	state := r.seed ^ (chunk * 0x9e3779b97f4a7c15)
+	var tmp [8]byte
+	for i := 0; i < r.unique; i += 8 {
+		// splitmix64
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		z ^= z >> 31
+		binary.LittleEndian.PutUint64(tmp[:], z)
+		copy(r.buf[i:r.unique], tmp[:])
+	}
+	for i := r.unique; i < len(r.buf); i += r.unique {
+		copy(r.buf[i:], r.buf[:r.unique])
+	}
+}
+
+func (r *dedupReader) Read(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		if r.read >= r.want {
+			return n, io.EOF
+		}
+		idx := int(r.read / int64(r.size))
+		if idx != r.bufIdx {
+			r.fill(r.chunks[idx])
+			r.bufIdx = idx
+		}
+		toDo := r.buf[r.read%int64(r.size):]
+		if remain := r.want - r.read; int64(len(toDo)) > remain {
+			toDo = toDo[:remain]
+		}
+		copied := copy(p, toDo)
+		p = p[copied:]
+		r.read += int64(copied)
+		n += copied
+	}
+	return n, nil
+}
+
+// Seek implements io.Seeker to allow retries.
+func (r *dedupReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	default:
+		return 0, errors.New("dedupReader.Seek: invalid whence")
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.read
+	case io.SeekEnd:
+		offset += r.want
+	}
+	if offset < 0 {
+		return 0, errors.New("dedupReader.Seek: negative position")
+	}
+	if offset > r.want {
+		return 0, io.EOF
+	}
+	r.read = offset
+	return r.read, nil
+}
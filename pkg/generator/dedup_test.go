@@ -0,0 +1,89 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestWithDedupData(t *testing.T) {
+	const chunkSize = 4 << 10
+	for _, ratio := range []float64{1, 2, 4, 10} {
+		src, err := New(WithDedupData().Ratio(ratio).ChunkSize(chunkSize).RngSeed(1).Apply(), WithSize(10*chunkSize+100))
+		if err != nil {
+			t.Fatal(err)
+		}
+		unique := make(map[[32]byte]struct{})
+		total := 0
+		for i := 0; i < 200; i++ {
+			obj := src.Object()
+			b, err := io.ReadAll(obj.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int64(len(b)) != obj.Size {
+				t.Fatalf("got %d bytes, want %d", len(b), obj.Size)
+			}
+			// Only count full chunks.
+			for len(b) >= chunkSize {
+				unique[sha256.Sum256(b[:chunkSize])] = struct{}{}
+				b = b[chunkSize:]
+				total++
+			}
+		}
+		got := float64(total) / float64(len(unique))
+		if math.Abs(got-ratio)/ratio > 0.15 {
+			t.Errorf("ratio %v: got dedup ratio %.2f", ratio, got)
+		}
+	}
+
+	for _, opts := range []DedupOpts{WithDedupData().Ratio(0.5), WithDedupData().ChunkSize(0)} {
+		if _, err := New(opts.Apply()); err == nil {
+			t.Errorf("%+v: want error", opts)
+		}
+	}
+}
+
+func TestDedupReader_Seek(t *testing.T) {
+	src, err := New(WithDedupData().ChunkSize(1000).Apply(), WithSize(3500), WithCompression(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := src.Object()
+	want, err := io.ReadAll(obj.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want[:250], want[250:500]) {
+		t.Error("compressed chunk does not repeat")
+	}
+	if _, err := obj.Reader.Seek(1234, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(obj.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want[1234:]) {
+		t.Error("data after seek does not match")
+	}
+}
@@ -0,0 +1,360 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// dicomPreamble is the size of the preamble before the "DICM" prefix.
+	dicomPreamble = 128
+
+	// dicomMaxPixels is the maximum size of pixel data.
+	dicomMaxPixels = 1 << 31
+
+	// dicomShapes is the number of ellipses drawn inside the body of each image.
+	dicomShapes = 6
+
+	// UIDs of the SOP class and transfer syntax.
+	dicomSecondaryCapture = "1.2.840.10008.5.1.4.1.1.7"
+	dicomExplicitLE       = "1.2.840.10008.1.2.1"
+	dicomImplClass        = "2.25.302231937203411830153466426425429880119"
+)
+
+// WithDICOMData returns default options for DICOM images.
+func WithDICOMData() DICOMOpts {
+	return dicomOptsDefaults()
+}
+
+// Apply DICOM data options.
+func (o DICOMOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		opts.dicom = o
+		opts.src = newDICOM
+		return nil
+	}
+}
+
+func (o DICOMOpts) validate() error {
+	if o.width <= 0 || o.height <= 0 || o.width > math.MaxUint16 || o.height > math.MaxUint16 {
+		return fmt.Errorf("dicom: width and height must be 1 to %d", math.MaxUint16)
+	}
+	if o.bits != 8 && o.bits != 16 {
+		return errors.New("dicom: bits must be 8 or 16")
+	}
+	if int64(o.width)*int64(o.height)*int64(o.bits/8) > dicomMaxPixels {
+		return fmt.Errorf("dicom: pixel data larger than %d bytes", dicomMaxPixels)
+	}
+	return nil
+}
+
+// Resolution sets the columns and rows of images in pixels.
+func (o DICOMOpts) Resolution(width, height int) DICOMOpts {
+	o.width, o.height = width, height
+	return o
+}
+
+// Bits sets the bits allocated for each pixel, 8 or 16.
+// 16 bit pixels store 12 bit values, like CT and MR images.
+func (o DICOMOpts) Bits(n int) DICOMOpts {
+	o.bits = n
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make usage predictable.
+func (o DICOMOpts) RngSeed(s int64) DICOMOpts {
+	o.seed = &s
+	return o
+}
+
+// DICOMOpts are the options for the DICOM data source.
+type DICOMOpts struct {
+	seed          *int64
+	width, height int
+	bits          int
+}
+
+func dicomOptsDefaults() DICOMOpts {
+	return DICOMOpts{
+		seed:   nil,
+		width:  512,
+		height: 512,
+		bits:   16,
+	}
+}
+
+// dicomSrc returns Secondary Capture images in DICOM files with explicit VR little endian encoding.
+// All images of a source are in the same study and series.
+type dicomSrc struct {
+	counter uint64
+	o       Options
+	rng     *rand.Rand
+	noise   *xoshiro256
+	buf     bytes.Buffer
+	pixels  []byte
+	meta    []byte
+	obj     Object
+
+	study, series string
+	patient       string
+	date          string
+}
+
+func newDICOM(o Options) (Source, error) {
+	rndSrc := rand.NewSource(int64(rand.Uint64()))
+	if o.dicom.seed != nil {
+		rndSrc = rand.NewSource(*o.dicom.seed)
+	}
+	d := dicomSrc{
+		o:   o,
+		rng: rand.New(rndSrc),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "application/dicom",
+			Size:        0,
+		},
+	}
+	d.noise = newXoshiro256(d.rng.Uint64())
+	n := o.dicom.width * o.dicom.height * o.dicom.bits / 8
+	// Pixel data must have an even length.
+	d.pixels = make([]byte, n+n&1)
+	d.study = d.uid()
+	d.series = d.uid()
+	d.patient = "WARP" + strconv.Itoa(d.rng.Intn(1000000))
+	d.date = time.Now().Format("20060102")
+	d.obj.setPrefix(o)
+	return &d, nil
+}
+
+// uid returns a random UID below the 2.25 root of UUID based UIDs.
+func (d *dicomSrc) uid() string {
+	return "2.25." + strconv.FormatUint(d.rng.Uint64()|1<<63, 10) + strconv.FormatUint(d.rng.Uint64()>>1, 10)
+}
+
+// Object returns a DICOM file with a new image.
+// The size of the object is the size of the header and pixel data.
+func (d *dicomSrc) Object() *Object {
+	n := atomic.AddUint64(&d.counter, 1)
+	opts := d.o.dicom
+	instance := d.uid()
+	d.draw()
+
+	d.meta = d.meta[:0]
+	d.meta = dicomAppend(d.meta, 0x0002, 0x0001, "OB", []byte{0, 1})
+	d.meta = dicomAppendString(d.meta, 0x0002, 0x0002, "UI", dicomSecondaryCapture)
+	d.meta = dicomAppendString(d.meta, 0x0002, 0x0003, "UI", instance)
+	d.meta = dicomAppendString(d.meta, 0x0002, 0x0010, "UI", dicomExplicitLE)
+	d.meta = dicomAppendString(d.meta, 0x0002, 0x0012, "UI", dicomImplClass)
+	d.meta = dicomAppendString(d.meta, 0x0002, 0x0013, "SH", "WARP")
+
+	d.buf.Reset()
+	d.buf.Write(make([]byte, dicomPreamble))
+	d.buf.WriteString("DICM")
+	var dst [12]byte
+	d.buf.Write(dicomAppend(dst[:0], 0x0002, 0x0000, "UL", dicomLE32(nil, uint32(len(d.meta)))))
+	d.buf.Write(d.meta)
+
+	// Elements must be in ascending order of their tags.
+	ds := d.meta[:0]
+	ds = dicomAppendString(ds, 0x0008, 0x0016, "UI", dicomSecondaryCapture)
+	ds = dicomAppendString(ds, 0x0008, 0x0018, "UI", instance)
+	ds = dicomAppendString(ds, 0x0008, 0x0020, "DA", d.date)
+	ds = dicomAppendString(ds, 0x0008, 0x0030, "TM", "")
+	ds = dicomAppendString(ds, 0x0008, 0x0050, "SH", "")
+	ds = dicomAppendString(ds, 0x0008, 0x0060, "CS", "OT")
+	ds = dicomAppendString(ds, 0x0008, 0x0064, "CS", "WSD")
+	ds = dicomAppendString(ds, 0x0008, 0x0090, "PN", "")
+	ds = dicomAppendString(ds, 0x0010, 0x0010, "PN", "WARP^BENCHMARK")
+	ds = dicomAppendString(ds, 0x0010, 0x0020, "LO", d.patient)
+	ds = dicomAppendString(ds, 0x0010, 0x0030, "DA", "")
+	ds = dicomAppendString(ds, 0x0010, 0x0040, "CS", "")
+	ds = dicomAppendString(ds, 0x0020, 0x000D, "UI", d.study)
+	ds = dicomAppendString(ds, 0x0020, 0x000E, "UI", d.series)
+	ds = dicomAppendString(ds, 0x0020, 0x0010, "SH", "1")
+	ds = dicomAppendString(ds, 0x0020, 0x0011, "IS", "1")
+	ds = dicomAppendString(ds, 0x0020, 0x0013, "IS", strconv.FormatUint(n, 10))
+	ds = dicomAppendString(ds, 0x0020, 0x0020, "CS", "")
+	ds = dicomAppendUint16(ds, 0x0028, 0x0002, 1)
+	ds = dicomAppendString(ds, 0x0028, 0x0004, "CS", "MONOCHROME2")
+	ds = dicomAppendUint16(ds, 0x0028, 0x0010, opts.height)
+	ds = dicomAppendUint16(ds, 0x0028, 0x0011, opts.width)
+	stored := opts.bits
+	if stored == 16 {
+		stored = 12
+	}
+	ds = dicomAppendUint16(ds, 0x0028, 0x0100, opts.bits)
+	ds = dicomAppendUint16(ds, 0x0028, 0x0101, stored)
+	ds = dicomAppendUint16(ds, 0x0028, 0x0102, stored-1)
+	ds = dicomAppendUint16(ds, 0x0028, 0x0103, 0)
+	d.meta = ds
+	d.buf.Write(ds)
+	vr := "OW"
+	if opts.bits == 8 {
+		vr = "OB"
+	}
+	d.buf.Write(dicomAppendHeader(dst[:0], 0x7FE0, 0x0010, vr, len(d.pixels)))
+	d.buf.Write(d.pixels)
+
+	d.obj.Size = int64(d.buf.Len())
+	d.obj.Reader = bytes.NewReader(d.buf.Bytes())
+
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], d.rng)
+	d.obj.setName(fmt.Sprintf("%d.%s.dcm", n, string(nBuf[:])))
+	return &d.obj
+}
+
+// draw draws a body with organs and a little noise on a black background,
+// which compresses roughly like a CT slice.
+func (d *dicomSrc) draw() {
+	type ellipse struct {
+		cx, cy, rx, ry int
+		v              int
+	}
+	w, h := d.o.dicom.width, d.o.dicom.height
+	body := ellipse{cx: w / 2, cy: h / 2, rx: w * 9 / 20, ry: h * 7 / 20, v: 900 + d.rng.Intn(200)}
+	var shapes [dicomShapes]ellipse
+	for s := range shapes {
+		shapes[s] = ellipse{
+			cx: w/4 + d.rng.Intn(1+w/2), cy: h/3 + d.rng.Intn(1+h/3),
+			rx: 1 + d.rng.Intn(1+w/8), ry: 1 + d.rng.Intn(1+h/8),
+			v: d.rng.Intn(1200) - 600,
+		}
+	}
+	row := make([]int, w)
+	noise := make([]byte, w)
+	for y := 0; y < h; y++ {
+		for x := range row {
+			row[x] = 0
+		}
+		span := func(s ellipse, add bool) {
+			dy := y - s.cy
+			if s.ry == 0 || dy < -s.ry || dy > s.ry {
+				return
+			}
+			half := int(float64(s.rx) * math.Sqrt(1-float64(dy*dy)/float64(s.ry*s.ry)))
+			from, to := s.cx-half, s.cx+half
+			if from < 0 {
+				from = 0
+			}
+			if to >= w {
+				to = w - 1
+			}
+			for x := from; x <= to; x++ {
+				if add {
+					row[x] += s.v
+				} else {
+					row[x] = s.v
+				}
+			}
+		}
+		span(body, false)
+		for _, s := range shapes {
+			span(s, true)
+		}
+		d.noise.Read(noise)
+		for x, v := range row {
+			if v > 0 {
+				v += int(noise[x]&63) - 32
+			}
+			if v < 0 {
+				v = 0
+			} else if v > 4095 {
+				v = 4095
+			}
+			if d.o.dicom.bits == 8 {
+				d.pixels[y*w+x] = uint8(v >> 4)
+			} else {
+				binary.LittleEndian.PutUint16(d.pixels[(y*w+x)*2:], uint16(v))
+			}
+		}
+	}
+}
+
+// dicomLE16 appends v in little endian byte order.
+func dicomLE16(dst []byte, v uint16) []byte {
+	return append(dst, byte(v), byte(v>>8))
+}
+
+// dicomLE32 appends v in little endian byte order.
+func dicomLE32(dst []byte, v uint32) []byte {
+	return append(dst, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+// dicomAppendHeader appends the tag, VR and length of an element in explicit VR little endian encoding.
+func dicomAppendHeader(dst []byte, group, element uint16, vr string, n int) []byte {
+	dst = dicomLE16(dst, group)
+	dst = dicomLE16(dst, element)
+	dst = append(dst, vr...)
+	switch vr {
+	case "OB", "OW", "OF", "SQ", "UT", "UN":
+		// Reserved bytes and a 32 bit length.
+		dst = append(dst, 0, 0)
+		return dicomLE32(dst, uint32(n))
+	}
+	return dicomLE16(dst, uint16(n))
+}
+
+// dicomAppend appends an element with the value, which must have an even length.
+func dicomAppend(dst []byte, group, element uint16, vr string, value []byte) []byte {
+	dst = dicomAppendHeader(dst, group, element, vr, len(value))
+	return append(dst, value...)
+}
+
+// dicomAppendString appends an element with a string value.
+// Values are padded to an even length with a zero byte for UIDs and a space otherwise.
+func dicomAppendString(dst []byte, group, element uint16, vr, value string) []byte {
+	n := len(value) + len(value)&1
+	dst = dicomAppendHeader(dst, group, element, vr, n)
+	dst = append(dst, value...)
+	if n > len(value) {
+		if vr == "UI" {
+			return append(dst, 0)
+		}
+		return append(dst, ' ')
+	}
+	return dst
+}
+
+// dicomAppendUint16 appends an element with a US value.
+func dicomAppendUint16(dst []byte, group, element uint16, v int) []byte {
+	dst = dicomAppendHeader(dst, group, element, "US", 2)
+	return dicomLE16(dst, uint16(v))
+}
+
+func (d *dicomSrc) String() string {
+	opts := d.o.dicom
+	return fmt.Sprintf("DICOM images; %dx%d pixels, %d bits", opts.width, opts.height, opts.bits)
+}
+
+func (d *dicomSrc) Prefix() string {
+	return d.obj.Prefix
+}
@@ -0,0 +1,101 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWithDICOMData(t *testing.T) {
+	for _, opts := range []DICOMOpts{WithDICOMData(), WithDICOMData().Resolution(3, 3).Bits(8), WithDICOMData().Resolution(640, 480).Bits(16)} {
+		src, err := New(opts.RngSeed(1).Apply())
+		if err != nil {
+			t.Fatal(err)
+		}
+		obj := src.Object()
+		if obj.ContentType != "application/dicom" || !strings.HasSuffix(obj.Name, ".dcm") {
+			t.Errorf("content type %q, name %q", obj.ContentType, obj.Name)
+		}
+		b, err := io.ReadAll(obj.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(b)) != obj.Size {
+			t.Fatalf("got size %d, want %d", len(b), obj.Size)
+		}
+		if string(b[dicomPreamble:dicomPreamble+4]) != "DICM" {
+			t.Fatal("DICM prefix missing")
+		}
+		// Parse the elements, which must be in ascending order.
+		elems := make(map[uint32][]byte)
+		var last uint32
+		metaEnd := -1
+		for p := dicomPreamble + 4; p < len(b); {
+			tag := uint32(binary.LittleEndian.Uint16(b[p:]))<<16 | uint32(binary.LittleEndian.Uint16(b[p+2:]))
+			vr := string(b[p+4 : p+6])
+			var n int
+			switch vr {
+			case "OB", "OW", "OF", "SQ", "UT", "UN":
+				n = int(binary.LittleEndian.Uint32(b[p+8:]))
+				p += 12
+			default:
+				n = int(binary.LittleEndian.Uint16(b[p+6:]))
+				p += 8
+			}
+			if tag <= last && last != 0 {
+				t.Fatalf("tag %08x after %08x", tag, last)
+			}
+			if n%2 != 0 || p+n > len(b) {
+				t.Fatalf("tag %08x: invalid length %d", tag, n)
+			}
+			if tag == 0x00020000 {
+				metaEnd = p + 4 + int(binary.LittleEndian.Uint32(b[p:]))
+			}
+			if tag>>16 != 2 && metaEnd != p-8 && last>>16 == 2 {
+				t.Fatalf("meta group length %d, dataset starts at %d", metaEnd, p-8)
+			}
+			last = tag
+			elems[tag] = b[p : p+n]
+			p += n
+		}
+		us := func(tag uint32) int { return int(binary.LittleEndian.Uint16(elems[tag])) }
+		rows, cols, bits := us(0x00280010), us(0x00280011), us(0x00280100)
+		if cols != opts.width || rows != opts.height || bits != opts.bits {
+			t.Fatalf("got %dx%d %d bits, want %dx%d %d bits", cols, rows, bits, opts.width, opts.height, opts.bits)
+		}
+		want := rows * cols * bits / 8
+		if got := len(elems[0x7FE00010]); got != want+want&1 {
+			t.Fatalf("got %d bytes of pixel data, want %d", got, want)
+		}
+		if ts := strings.TrimRight(string(elems[0x00020010]), "\x00"); ts != dicomExplicitLE {
+			t.Fatalf("transfer syntax %q", ts)
+		}
+		if string(elems[0x00020003]) != string(elems[0x00080018]) {
+			t.Fatal("SOP instance UIDs differ")
+		}
+	}
+
+	for _, opts := range []DICOMOpts{WithDICOMData().Resolution(0, 10), WithDICOMData().Resolution(70000, 10), WithDICOMData().Bits(12)} {
+		if _, err := New(opts.Apply()); err == nil {
+			t.Errorf("%+v: want error", opts)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content encodings objects can be compressed with.
+const (
+	EncodingGzip = "gzip"
+	EncodingZstd = "zstd"
+)
+
+// WithContentEncoding compresses the content of objects with the encoding before they are uploaded.
+// The Size of objects is the compressed size and DecodedSize is the size before compression.
+// Objects are compressed in memory, so each object is generated completely.
+// An empty encoding uploads objects as generated.
+func WithContentEncoding(enc string) Option {
+	return func(o *Options) error {
+		switch enc {
+		case "", EncodingGzip, EncodingZstd:
+		default:
+			return fmt.Errorf("unsupported content encoding %q; supported: %s, %s", enc, EncodingGzip, EncodingZstd)
+		}
+		o.encoding = enc
+		return nil
+	}
+}
+
+// encodedSrc compresses the objects of a source.
+type encodedSrc struct {
+	Source
+	enc  string
+	buf  bytes.Buffer
+	gz   *gzip.Writer
+	zstd *zstd.Encoder
+}
+
+func newEncodedSrc(src Source, enc string) (Source, error) {
+	e := encodedSrc{Source: src, enc: enc}
+	switch enc {
+	case EncodingGzip:
+		e.gz = gzip.NewWriter(&e.buf)
+	case EncodingZstd:
+		var err error
+		e.zstd, err = zstd.NewWriter(&e.buf, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &e, nil
+}
+
+func (e *encodedSrc) Object() *Object {
+	obj := e.Source.Object()
+	if obj == nil {
+		return nil
+	}
+	e.buf.Reset()
+	var w io.WriteCloser
+	if e.gz != nil {
+		e.gz.Reset(&e.buf)
+		w = e.gz
+	} else {
+		e.zstd.Reset(&e.buf)
+		w = e.zstd
+	}
+	// Writing to a buffer cannot fail, and generated readers return no errors.
+	io.Copy(w, obj.Reader)
+	w.Close()
+	obj.DecodedSize = obj.Size
+	obj.Size = int64(e.buf.Len())
+	obj.ContentEncoding = e.enc
+	obj.Reader = bytes.NewReader(e.buf.Bytes())
+	return obj
+}
+
+func (e *encodedSrc) String() string {
+	return e.Source.String() + "; " + e.enc + " encoded"
+}
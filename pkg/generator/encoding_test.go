@@ -0,0 +1,81 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestWithContentEncoding(t *testing.T) {
+	decoders := map[string]func(r io.Reader) ([]byte, error){
+		EncodingGzip: func(r io.Reader) ([]byte, error) {
+			gr, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return io.ReadAll(gr)
+		},
+		EncodingZstd: func(r io.Reader) ([]byte, error) {
+			zr, err := zstd.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			defer zr.Close()
+			return io.ReadAll(zr)
+		},
+	}
+	for enc, decode := range decoders {
+		const size = 100 << 10
+		src, err := New(WithPatternData().Pattern([]byte("warp")).Apply(), WithSize(size), WithContentEncoding(enc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(src.String(), enc+" encoded") {
+			t.Errorf("%s: got %q", enc, src.String())
+		}
+		for i := 0; i < 2; i++ {
+			obj := src.Object()
+			if obj.ContentEncoding != enc || obj.DecodedSize != size || obj.Size >= size {
+				t.Fatalf("%s: got encoding %q, size %d, decoded size %d", enc, obj.ContentEncoding, obj.Size, obj.DecodedSize)
+			}
+			b, err := io.ReadAll(obj.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int64(len(b)) != obj.Size {
+				t.Fatalf("%s: read %d bytes, want %d", enc, len(b), obj.Size)
+			}
+			got, err := decode(bytes.NewReader(b))
+			if err != nil {
+				t.Fatal(enc, err)
+			}
+			if !bytes.Equal(got, bytes.Repeat([]byte("warp"), size/4)) {
+				t.Fatalf("%s: decoded content does not match", enc)
+			}
+		}
+	}
+	if _, err := New(WithContentEncoding("br")); err == nil {
+		t.Error("want error for unsupported encoding")
+	}
+}
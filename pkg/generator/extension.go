@@ -0,0 +1,113 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// extensions picks extensions of object names from a weighted list.
+type extensions struct {
+	exts []string
+	// cum contains the cumulative weight of extensions.
+	cum []float64
+}
+
+// WithExtensions sets the extension of object names from a weighted list,
+// like '50% .log, 30% .parquet, 20% .jpg', instead of the extension of the generator.
+// Weights are relative and do not need to add up to 100.
+// The content is not changed, only the names.
+// An empty list keeps the extensions of the generator.
+func WithExtensions(s string) Option {
+	return func(o *Options) error {
+		if strings.TrimSpace(s) == "" {
+			o.extensions = nil
+			return nil
+		}
+		e, err := parseExtensions(s)
+		if err != nil {
+			return err
+		}
+		o.extensions = e
+		return nil
+	}
+}
+
+func parseExtensions(s string) (*extensions, error) {
+	var e extensions
+	total := 0.0
+	for _, entry := range strings.Split(s, ",") {
+		fields := strings.Fields(entry)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("extensions: want weight and extension, got %q", strings.TrimSpace(entry))
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSuffix(fields[0], "%"), 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("extensions: invalid weight %q", fields[0])
+		}
+		ext := "." + strings.TrimPrefix(fields[1], ".")
+		if !validExtension(ext) {
+			return nil, fmt.Errorf("extensions: invalid extension %q, use letters, digits and dots", fields[1])
+		}
+		total += weight
+		e.exts = append(e.exts, ext)
+		e.cum = append(e.cum, total)
+	}
+	if total <= 0 {
+		return nil, errors.New("extensions: total weight is 0")
+	}
+	return &e, nil
+}
+
+// validExtension returns whether ext is a dot followed by letters and digits,
+// optionally with more dots like '.tar.gz'.
+func validExtension(ext string) bool {
+	if len(ext) < 2 || strings.HasSuffix(ext, ".") || strings.Contains(ext, "..") {
+		return false
+	}
+	for _, c := range ext[1:] {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// pick returns a random extension.
+func (e *extensions) pick(rng *rand.Rand) string {
+	v := rng.Float64() * e.cum[len(e.cum)-1]
+	i := sort.Search(len(e.cum), func(i int) bool { return e.cum[i] > v })
+	if i >= len(e.exts) {
+		i = len(e.exts) - 1
+	}
+	return e.exts[i]
+}
+
+// replace returns the name with its extension replaced by a random extension.
+// Names without an extension get one added.
+func (e *extensions) replace(name string, rng *rand.Rand) string {
+	return strings.TrimSuffix(name, path.Ext(name)) + e.pick(rng)
+}
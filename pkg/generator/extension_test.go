@@ -0,0 +1,66 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWithExtensions(t *testing.T) {
+	src, err := New(WithRandomData().Apply(), WithSize(10), WithCustomPrefix("pfx"), WithExtensions("50% .log, 30% parquet,20% .tar.gz, 0% .jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	re := regexp.MustCompile(`^pfx/\d+\.[a-zA-Z0-9()]{16}(\.log|\.parquet|\.tar\.gz)$`)
+	const n = 10000
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		name := src.Object().Name
+		if !re.MatchString(name) {
+			t.Fatalf("name %q does not match %s", name, re)
+		}
+		counts["."+strings.SplitN(name, ".", 3)[2]]++
+	}
+	want := map[string]int{".log": 5000, ".parquet": 3000, ".tar.gz": 2000}
+	if len(counts) != len(want) {
+		t.Fatalf("got extensions %v", counts)
+	}
+	for ext, w := range want {
+		if got := counts[ext]; got < w*9/10 || got > w*11/10 {
+			t.Errorf("%s: got %d, want about %d", ext, got, w)
+		}
+	}
+
+	src, err = New(WithRandomData().Apply(), WithSize(10), WithExtensions("1 .json"),
+		WithContentTypes(ContentTypesFromExt), WithKeyTemplate("{prefix}/{uuid}.{ext}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := src.Object()
+	if !strings.HasSuffix(obj.Name, ".json") || obj.ContentType != "application/json" {
+		t.Errorf("got name %q, content type %q", obj.Name, obj.ContentType)
+	}
+
+	for _, s := range []string{"50% .log, .jpg", "x .log", "-1 .log", "1 .", "1 .a/b", "1 ..gz", "0 .log"} {
+		if _, err := New(WithRandomData().Apply(), WithExtensions(s)); err == nil {
+			t.Errorf("%q: want error", s)
+		}
+	}
+}
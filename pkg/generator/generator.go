@@ -34,6 +34,7 @@ type Source interface {
 	// Requesting a new reader will scramble data, so the new reader will not return the same data.
 	// Requesting a reader is designed to be as lightweight as possible.
 	// Only a single reader can be used concurrently.
+	// Sources that have a limited number of objects return nil when there are no more objects.
 	Object() *Object
 
 	// String returns a human readable description of the source.
@@ -60,6 +61,36 @@ type Object struct {
 	Prefix string
 
 	VersionID string
+
+	// ContentEncoding of the object, if the content is compressed.
+	// DecodedSize is the size of the content before compression.
+	ContentEncoding string
+	DecodedSize     int64
+
+	// UserMetadata and UserTags of the object, if any.
+	// New maps are created for each object.
+	UserMetadata map[string]string
+	UserTags     map[string]string
+
+	// tmpl creates names if set.
+	tmpl *keyTemplate
+	// parts adds time partitions to names if set.
+	parts *partitioner
+	// tree adds folders of a prefix tree to names if set.
+	tree *prefixTree
+	// hot places names below hot prefixes if set.
+	// namePrefix is the prefix of names that are not hot.
+	hot        *hotPrefixes
+	namePrefix string
+	// meta and tags create user metadata and tags if set.
+	meta, tags *objectAttrs
+	// types sets the content type if set.
+	// baseType is the content type of the generator.
+	types    *contentTypes
+	baseType string
+	// exts replaces the extension of names if set.
+	exts *extensions
+	rng  *rand.Rand
 }
 
 // Objects is a slice of objects.
@@ -94,22 +125,88 @@ func MergeObjectPrefixes(o []Objects) []string {
 }
 
 func (o *Object) setPrefix(opts Options) {
+	o.tmpl = opts.keyTemplate
+	o.parts = opts.partitions
+	o.tree = opts.prefixTree
+	o.hot = opts.hotPrefixes
+	o.meta, o.tags = opts.metadata, opts.tags
+	o.types = opts.contentTypes
+	o.exts = opts.extensions
+	o.baseType = o.ContentType
+	if o.tmpl != nil || o.parts != nil || o.tree != nil || o.hot != nil || o.meta != nil || o.tags != nil || o.types != nil || o.exts != nil {
+		o.rng = rand.New(rand.NewSource(int64(rand.Uint64())))
+	}
 	if opts.randomPrefix <= 0 {
 		o.Prefix = opts.customPrefix
+		o.namePrefix = o.Prefix
 		return
 	}
 	b := make([]byte, opts.randomPrefix)
 	rng := rand.New(rand.NewSource(int64(rand.Uint64())))
 	randASCIIBytes(b, rng)
 	o.Prefix = path.Join(opts.customPrefix, string(b))
+	o.namePrefix = o.Prefix
+	if o.hot != nil {
+		// Hot prefixes are shared, so names are only below the custom prefix.
+		o.Prefix = opts.customPrefix
+	}
 }
 
+// setName sets the name of the next object from the name s the source generated.
+// User metadata, tags and the content type of the object are set as well.
 func (o *Object) setName(s string) {
-	if len(o.Prefix) == 0 {
+	if o.types != nil {
+		if o.types.fromExt {
+			defer func() {
+				def := o.baseType
+				if def == "" {
+					// Sources may set the content type of each object.
+					def = o.ContentType
+				}
+				o.ContentType = o.types.fromName(o.Name, def)
+			}()
+		} else {
+			o.ContentType = o.types.pick(o.rng)
+		}
+	}
+	if o.meta != nil {
+		o.UserMetadata = o.meta.pick(o.rng)
+	}
+	if o.tags != nil {
+		o.UserTags = o.tags.pick(o.rng)
+	}
+	if o.exts != nil {
+		s = o.exts.replace(s, o.rng)
+	}
+	prefix := o.namePrefix
+	if o.hot != nil {
+		if hot := o.hot.pick(o.rng, o.Prefix); hot != "" {
+			prefix = hot
+		}
+	}
+	var partition string
+	if o.parts != nil {
+		partition = o.parts.pick(o.rng)
+	}
+	var tree string
+	if o.tree != nil {
+		tree = o.tree.pick(o.rng)
+	}
+	if o.tmpl != nil {
+		o.Name = o.tmpl.name(prefix, s, partition, tree, o.rng)
+		return
+	}
+	if partition != "" {
+		s = partition + "/" + s
+	}
+	if tree != "" {
+		s = tree + "/" + s
+	}
+	if len(prefix) == 0 {
 		o.Name = s
 		return
 	}
-	o.Name = o.Prefix + "/" + s
+	o.Name = prefix + "/" + s
 }
 
 // New return data source.
@@ -124,7 +221,10 @@ func New(opts ...Option) (Source, error) {
 	if options.src == nil {
 		return nil, errors.New("internal error: generator Source was nil")
 	}
-	return options.src(options)
+	if err := options.validateNames(); err != nil {
+		return nil, err
+	}
+	return options.source()
 }
 
 // NewFn return data source.
@@ -139,9 +239,12 @@ func NewFn(opts ...Option) (func() Source, error) {
 	if options.src == nil {
 		return nil, errors.New("internal error: generator Source was nil")
 	}
+	if err := options.validateNames(); err != nil {
+		return nil, err
+	}
 
 	return func() Source {
-		s, err := options.src(options)
+		s, err := options.source()
 		if err != nil {
 			panic(err)
 		}
@@ -149,6 +252,25 @@ func NewFn(opts ...Option) (func() Source, error) {
 	}, nil
 }
 
+// source returns a new source with the options.
+func (o Options) source() (Source, error) {
+	s, err := o.src(o)
+	if err != nil {
+		return nil, err
+	}
+	if o.encoding != "" {
+		s, err = newEncodedSrc(s, o.encoding)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if o.cse != nil {
+		// Encrypt after compression, encrypted data does not compress.
+		s = &encryptedSrc{Source: s, cse: o.cse}
+	}
+	return s, nil
+}
+
 const asciiLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890()"
 
 var asciiLetterBytes [len(asciiLetters)]byte
@@ -195,6 +317,27 @@ func GetExpRandSize(rng *rand.Rand, max int64) int64 {
 	return 1 + int64(random*math.Pow(2, logSizeMin+1))
 }
 
+// GetZipfSize will return a Zipf distributed size.
+// Sizes are multiples of min, where min is the most likely and max the least likely.
+func GetZipfSize(rng *rand.Rand, alpha float64, min, max int64) int64 {
+	z := rand.NewZipf(rng, alpha, 1, uint64(max/min-1))
+	return min * int64(1+z.Uint64())
+}
+
+// GetLogNormalSize will return a log-normal distributed size,
+// where mu and sigma are the mean and standard deviation of the logarithm of the size.
+// The size is clamped to min and max.
+func GetLogNormalSize(rng *rand.Rand, mu, sigma float64, min, max int64) int64 {
+	size := math.Exp(mu + sigma*rng.NormFloat64())
+	if size <= float64(min) {
+		return min
+	}
+	if size >= float64(max) {
+		return max
+	}
+	return int64(size)
+}
+
 // GetDistributionSize will pick a random value from the provided distribution list.
 func GetDistributionSize(rng *rand.Rand, dist []int64) int64 {
 	idx := 1 + rng.Int63n(int64(len(dist))) // generates a random value between [1, 100] inclusive.
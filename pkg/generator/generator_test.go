@@ -18,6 +18,9 @@
 package generator
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"io"
 	"io/ioutil"
 	"testing"
@@ -44,7 +47,23 @@ func TestNew(t *testing.T) {
 		{
 			name: "CSV",
 			args: args{
-				opts: []Option{WithCSV().Apply()},
+				opts: []Option{WithCSVData().Apply()},
+			},
+			wantErr:  false,
+			wantSize: 1 << 20,
+		},
+		{
+			name: "JSON",
+			args: args{
+				opts: []Option{WithJSONData().Apply()},
+			},
+			wantErr:  false,
+			wantSize: 1 << 20,
+		},
+		{
+			name: "Parquet",
+			args: args{
+				opts: []Option{WithParquetData().Apply()},
 			},
 			wantErr:  false,
 			wantSize: 1 << 20,
@@ -255,3 +274,225 @@ func BenchmarkWithRandomData(b *testing.B) {
 		})
 	}
 }
+
+func TestWithJSONData(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "byte", opts: []Option{WithSize(1), WithJSONData().Apply()}},
+		{name: "tiny", opts: []Option{WithSize(2), WithJSONData().Apply()}},
+		{name: "small", opts: []Option{WithSize(100), WithJSONData().Apply()}},
+		{name: "flat", opts: []Option{WithSize(10000), WithJSONData().Depth(1).Fields(3).Apply()}},
+		{name: "unique", opts: []Option{WithSize(1 << 16), WithJSONData().Cardinality(0).Apply()}},
+		{name: "compressed", opts: []Option{WithSize(1 << 16), WithJSONData().Apply(), WithCompression(4), WithCompressionWindow(1 << 14)}},
+		{name: "randsize", opts: []Option{WithRandomSize(true), WithSize(1 << 16), WithJSONData().Apply()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := New(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 10; i++ {
+				obj := src.Object()
+				if obj.ContentType != "application/json" {
+					t.Errorf("content type %q", obj.ContentType)
+				}
+				b, err := ioutil.ReadAll(obj.Reader)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if int64(len(b)) != obj.Size {
+					t.Fatalf("got size %d, want %d", len(b), obj.Size)
+				}
+				if !json.Valid(b) {
+					t.Fatalf("invalid JSON: %s", b)
+				}
+			}
+		})
+	}
+}
+
+func TestWithCSVData(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  CsvOpts
+		comma byte
+		cols  int
+	}{
+		{name: "default", opts: WithCSVData(), comma: ',', cols: 15},
+		{name: "tab", opts: WithCSVData().Columns(3).Rows(10).Comma('\t'), comma: '\t', cols: 3},
+		{name: "fixedlen", opts: WithCSVData().Size(1, 1).FieldLen(8, 8).Comma(';'), comma: ';', cols: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]byte
+			var names []string
+			for run := 0; run < 2; run++ {
+				src, err := New(WithSize(10000), WithPrefixSize(0), tt.opts.RngSeed(1).Apply())
+				if err != nil {
+					t.Fatal(err)
+				}
+				for i := 0; i < 3; i++ {
+					obj := src.Object()
+					if obj.ContentType != "text/csv" {
+						t.Errorf("content type %q", obj.ContentType)
+					}
+					b, err := ioutil.ReadAll(obj.Reader)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if int64(len(b)) != obj.Size {
+						t.Fatalf("got size %d, want %d", len(b), obj.Size)
+					}
+					if run == 0 {
+						got = append(got, b)
+						names = append(names, obj.Name)
+						continue
+					}
+					if !bytes.Equal(b, got[i]) || obj.Name != names[i] {
+						t.Fatalf("object %d not deterministic", i)
+					}
+				}
+			}
+			// Check complete lines of the first object.
+			b := got[0][:bytes.LastIndexByte(got[0], '\n')+1]
+			r := csv.NewReader(bytes.NewReader(b))
+			r.Comma = rune(tt.comma)
+			r.FieldsPerRecord = tt.cols
+			if _, err := r.ReadAll(); err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(got[0], got[1]) {
+				t.Error("objects are identical")
+			}
+		})
+	}
+	if _, err := New(WithCSVData().Comma('a').Apply()); err == nil {
+		t.Error("want error for delimiter in fields")
+	}
+	if _, err := New(WithCSVData().Rows(0).Apply()); err == nil {
+		t.Error("want error for no rows")
+	}
+}
+
+func TestWithZipfSize(t *testing.T) {
+	const min, max = 1 << 10, 1 << 20
+	src, err := New(WithZipfSize(1.2, min, max), WithRandomData().RngSeed(1).Apply())
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[int64]int)
+	for i := 0; i < 1000; i++ {
+		obj := src.Object()
+		if obj.Size < min || obj.Size > max || obj.Size%min != 0 {
+			t.Fatalf("unexpected size %d", obj.Size)
+		}
+		n, err := io.Copy(ioutil.Discard, obj.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != obj.Size {
+			t.Fatalf("got size %d, want %d", n, obj.Size)
+		}
+		counts[obj.Size]++
+	}
+	if counts[min] < counts[2*min] || counts[2*min] < counts[4*min] {
+		t.Errorf("sizes not long tailed: %v", counts)
+	}
+	if len(counts) < 10 {
+		t.Errorf("only %d distinct sizes", len(counts))
+	}
+
+	src, err = New(WithZipfSize(2, min, min))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj := src.Object(); obj.Size != min {
+		t.Errorf("got size %d, want %d", obj.Size, min)
+	}
+	if _, err := New(WithZipfSize(1, min, max)); err == nil {
+		t.Error("want error for alpha 1")
+	}
+}
+
+func TestWithLogNormalSize(t *testing.T) {
+	const mean, stddev, min, max = 1 << 20, 2 << 20, 1 << 10, 64 << 20
+	src, err := New(WithLogNormalSize(mean, stddev, min, max), WithRandomData().RngSeed(1).Apply())
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 5000
+	var total int64
+	below := 0
+	for i := 0; i < n; i++ {
+		obj := src.Object()
+		if obj.Size < min || obj.Size > max {
+			t.Fatalf("unexpected size %d", obj.Size)
+		}
+		if obj.Size < mean {
+			below++
+		}
+		total += obj.Size
+	}
+	// The mean of the clamped distribution should be close to the mean.
+	if avg := total / n; avg < mean*8/10 || avg > mean*12/10 {
+		t.Errorf("average size %d, want about %d", avg, mean)
+	}
+	// Log-normal distributions are skewed, with most values below the mean.
+	if below < n*6/10 {
+		t.Errorf("%d of %d sizes below mean", below, n)
+	}
+
+	src, err = New(WithLogNormalSize(mean, 0, min, max))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj := src.Object(); obj.Size < mean-1 || obj.Size > mean {
+		t.Errorf("got size %d, want %d", obj.Size, mean)
+	}
+	if _, err := New(WithLogNormalSize(mean, stddev, max, min)); err == nil {
+		t.Error("want error for min > max")
+	}
+}
+
+func TestRandomRepeat(t *testing.T) {
+	const size = 10000
+	for _, repeat := range []int{1, 3, 4} {
+		src, err := New(WithRandomData().Repeat(repeat).Apply(), WithSize(size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		obj := src.Object()
+		b, err := io.ReadAll(obj.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b) != size {
+			t.Fatalf("repeat %d: got %d bytes, want %d", repeat, len(b), size)
+		}
+		unique := (size + repeat - 1) / repeat
+		if repeat > 1 && !bytes.Equal(b[:size-unique], b[unique:]) {
+			t.Errorf("repeat %d: data does not repeat", repeat)
+		}
+		if repeat == 1 && bytes.Equal(b[:size/2], b[size/2:]) {
+			t.Error("repeat 1: data repeats")
+		}
+		if _, err := obj.Reader.Seek(1234, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		if repeat > 1 {
+			got, err := io.ReadAll(obj.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, b[1234:]) {
+				t.Errorf("repeat %d: data after seek does not match", repeat)
+			}
+		}
+	}
+	if _, err := New(WithRandomData().Repeat(0).Apply()); err == nil {
+		t.Error("want error for repeat 0")
+	}
+}
@@ -0,0 +1,304 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+)
+
+// Formats of genomics data.
+const (
+	// GenomicsFASTQ is reads with a quality line each.
+	GenomicsFASTQ = "fastq"
+	// GenomicsFASTA is sequences without quality lines.
+	GenomicsFASTA = "fasta"
+)
+
+const (
+	// genomicsMaxReadLen is the maximum length of reads.
+	genomicsMaxReadLen = 1 << 20
+
+	// genomicsLineWidth is the number of bases on each line of FASTA sequences.
+	genomicsLineWidth = 60
+
+	// genomicsNRate is the fraction of bases that could not be called.
+	genomicsNRate = 0.001
+
+	// genomicsBases are the bases of sequences, picked with 2 bits each.
+	genomicsBases = "ACGT"
+)
+
+// WithGenomicsData returns default options for genomics data.
+func WithGenomicsData() GenomicsOpts {
+	return genomicsOptsDefaults()
+}
+
+// Apply genomics data options.
+func (o GenomicsOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		opts.genomics = o
+		opts.src = newGenomics
+		return nil
+	}
+}
+
+func (o GenomicsOpts) validate() error {
+	switch o.format {
+	case GenomicsFASTQ, GenomicsFASTA:
+	default:
+		return fmt.Errorf("genomics: unknown format %q; supported: %s, %s", o.format, GenomicsFASTQ, GenomicsFASTA)
+	}
+	if o.minReadLen <= 0 {
+		return errors.New("genomics: read length <= 0")
+	}
+	if o.maxReadLen < o.minReadLen {
+		return errors.New("genomics: maximum read length < minimum")
+	}
+	if o.maxReadLen > genomicsMaxReadLen {
+		return fmt.Errorf("genomics: read length > %d", genomicsMaxReadLen)
+	}
+	return nil
+}
+
+// Format sets the format of objects.
+func (o GenomicsOpts) Format(f string) GenomicsOpts {
+	o.format = f
+	return o
+}
+
+// ReadLength sets the range of read lengths in bases.
+// Lengths are picked uniformly in the range.
+func (o GenomicsOpts) ReadLength(min, max int) GenomicsOpts {
+	o.minReadLen = min
+	o.maxReadLen = max
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make usage predictable.
+func (o GenomicsOpts) RngSeed(s int64) GenomicsOpts {
+	o.seed = &s
+	return o
+}
+
+// GenomicsOpts provides options for genomics data generation.
+type GenomicsOpts struct {
+	seed       *int64
+	format     string
+	minReadLen int
+	maxReadLen int
+}
+
+func genomicsOptsDefaults() GenomicsOpts {
+	return GenomicsOpts{
+		seed:       nil,
+		format:     GenomicsFASTQ,
+		minReadLen: 150,
+		maxReadLen: 150,
+	}
+}
+
+type genomicsSource struct {
+	counter uint64
+	o       Options
+	buf     *circularBuffer
+	rng     *rand.Rand
+	obj     Object
+	hdr     []byte
+}
+
+func newGenomics(o Options) (Source, error) {
+	rndSrc := rand.NewSource(int64(rand.Uint64()))
+	if o.genomics.seed != nil {
+		rndSrc = rand.NewSource(*o.genomics.seed)
+	}
+	g := genomicsSource{
+		o:   o,
+		rng: rand.New(rndSrc),
+		buf: newCircularBuffer(make([]byte, 0, o.totalSize), o.totalSize),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "text/plain",
+			Size:        0,
+		},
+	}
+	g.obj.setPrefix(o)
+	return &g, nil
+}
+
+// Object returns an object with reads until the object size.
+// The last read is shortened to fit, and the object is padded with newlines
+// if there is no room for another read.
+// Objects too small for a read are newlines only.
+func (g *genomicsSource) Object() *Object {
+	n := atomic.AddUint64(&g.counter, 1)
+	g.obj.Size = g.o.getSize(g.rng)
+	size := int(g.obj.Size)
+	opts := g.o.genomics
+
+	dst := g.buf.data[:0]
+	for read := 1; len(dst) < size; read++ {
+		g.hdr = g.appendHeader(g.hdr[:0], n, read)
+		length := opts.minReadLen
+		if opts.maxReadLen > opts.minReadLen {
+			length += g.rng.Intn(opts.maxReadLen - opts.minReadLen + 1)
+		}
+		if left := size - len(dst); g.recordSize(len(g.hdr), length) > left {
+			length = g.fitLength(len(g.hdr), left)
+			if length <= 0 {
+				break
+			}
+		}
+		dst = g.appendRecord(dst, length)
+	}
+	for len(dst) < size {
+		dst = append(dst, '\n')
+	}
+	g.buf.data = dst
+
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], g.rng)
+	g.obj.setName(fmt.Sprintf("%d.%s.%s", n, string(nBuf[:]), opts.format))
+
+	g.obj.Reader = g.buf.Reset(g.obj.Size)
+	return &g.obj
+}
+
+// appendHeader appends the header line of a read, without the newline.
+// FASTQ headers look like those of Illumina sequencers.
+func (g *genomicsSource) appendHeader(dst []byte, obj uint64, read int) []byte {
+	if g.o.genomics.format == GenomicsFASTA {
+		dst = append(dst, ">read_"...)
+		dst = strconv.AppendUint(dst, obj, 10)
+		dst = append(dst, '_')
+		return strconv.AppendInt(dst, int64(read), 10)
+	}
+	rng := g.rng
+	dst = append(dst, "@WARP:"...)
+	dst = strconv.AppendUint(dst, obj, 10)
+	dst = append(dst, ":FC0001:"...)
+	dst = strconv.AppendInt(dst, int64(1+rng.Intn(8)), 10)
+	dst = append(dst, ':')
+	dst = strconv.AppendInt(dst, int64(1101+rng.Intn(1200)), 10)
+	dst = append(dst, ':')
+	dst = strconv.AppendInt(dst, int64(rng.Intn(30000)), 10)
+	dst = append(dst, ':')
+	dst = strconv.AppendInt(dst, int64(read), 10)
+	return append(dst, " 1:N:0:1"...)
+}
+
+// recordSize returns the size of a record with the header size and read length.
+func (g *genomicsSource) recordSize(hdr, length int) int {
+	if g.o.genomics.format == GenomicsFASTA {
+		return hdr + 1 + length + (length+genomicsLineWidth-1)/genomicsLineWidth
+	}
+	// Header, sequence, separator and quality lines.
+	return hdr + 1 + length + 1 + 2 + length + 1
+}
+
+// fitLength returns the longest read length of a record with the header size that fits in left bytes.
+func (g *genomicsSource) fitLength(hdr, left int) int {
+	if g.o.genomics.format == GenomicsFASTA {
+		length := (left - hdr - 1) * genomicsLineWidth / (genomicsLineWidth + 1)
+		for g.recordSize(hdr, length+1) <= left {
+			length++
+		}
+		for length > 0 && g.recordSize(hdr, length) > left {
+			length--
+		}
+		return length
+	}
+	return (left - hdr - 5) / 2
+}
+
+// appendRecord appends a record with the header in g.hdr and a random read of the length.
+func (g *genomicsSource) appendRecord(dst []byte, length int) []byte {
+	rng := g.rng
+	dst = append(dst, g.hdr...)
+	dst = append(dst, '\n')
+	start := len(dst)
+	// Distance to the next base that could not be called.
+	nextN := int(rng.ExpFloat64() / genomicsNRate)
+	var bits uint64
+	for i := 0; i < length; i++ {
+		if g.o.genomics.format == GenomicsFASTA && i > 0 && i%genomicsLineWidth == 0 {
+			dst = append(dst, '\n')
+		}
+		if i%32 == 0 {
+			bits = rng.Uint64()
+		}
+		b := genomicsBases[bits&3]
+		bits >>= 2
+		if nextN--; nextN < 0 {
+			b = 'N'
+			nextN = int(rng.ExpFloat64() / genomicsNRate)
+		}
+		dst = append(dst, b)
+	}
+	dst = append(dst, '\n')
+	if g.o.genomics.format == GenomicsFASTA {
+		return dst
+	}
+	seq := dst[start : start+length]
+	dst = append(dst, '+', '\n')
+	// Quality drops along the read, like it does on sequencers.
+	for i := 0; i < length; i++ {
+		if i%10 == 0 {
+			bits = rng.Uint64()
+		}
+		// Noise from -7 to 7, with small values most common.
+		noise := int(bits&7) + int(bits>>3&7) - 7
+		bits >>= 6
+		q := 2
+		if seq[i] != 'N' {
+			q = 40 - 15*i/length + noise
+			if q < 2 {
+				q = 2
+			}
+			if q > 41 {
+				q = 41
+			}
+		}
+		// Phred+33 encoding.
+		dst = append(dst, byte('!'+q))
+	}
+	return append(dst, '\n')
+}
+
+func (g *genomicsSource) String() string {
+	opts := g.o.genomics
+	reads := strconv.Itoa(opts.minReadLen)
+	if opts.maxReadLen > opts.minReadLen {
+		reads += "-" + strconv.Itoa(opts.maxReadLen)
+	}
+	if g.o.randSize {
+		return fmt.Sprintf("Genomics data; %s, read length %s, random size up to %d bytes", opts.format, reads, g.o.totalSize)
+	}
+	return fmt.Sprintf("Genomics data; %s, read length %s, %d bytes total", opts.format, reads, g.o.totalSize)
+}
+
+func (g *genomicsSource) Prefix() string {
+	return g.obj.Prefix
+}
@@ -0,0 +1,103 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWithGenomicsData(t *testing.T) {
+	for _, format := range []string{GenomicsFASTQ, GenomicsFASTA} {
+		for _, size := range []int64{10, 1000, 1001, 100 << 10} {
+			src, err := New(WithGenomicsData().Format(format).ReadLength(100, 300).RngSeed(1).Apply(), WithSize(size))
+			if err != nil {
+				t.Fatal(err)
+			}
+			obj := src.Object()
+			if !strings.HasSuffix(obj.Name, "."+format) {
+				t.Errorf("name %q", obj.Name)
+			}
+			b, err := io.ReadAll(obj.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int64(len(b)) != size || obj.Size != size {
+				t.Fatalf("%s: got size %d, object size %d, want %d", format, len(b), obj.Size, size)
+			}
+			lines := bytes.Split(bytes.TrimRight(b, "\n"), []byte("\n"))
+			if size < 100 {
+				if len(bytes.TrimRight(b, "\n")) != 0 {
+					t.Fatalf("%s: want newlines only, got %q", format, b)
+				}
+				continue
+			}
+			reads := 0
+			if format == GenomicsFASTQ {
+				if len(lines)%4 != 0 {
+					t.Fatalf("got %d lines, want records of 4", len(lines))
+				}
+				for i := 0; i < len(lines); i += 4 {
+					seq, qual := lines[i+1], lines[i+3]
+					if lines[i][0] != '@' || string(lines[i+2]) != "+" || len(seq) != len(qual) {
+						t.Fatalf("invalid record: %q", lines[i:i+4])
+					}
+					checkBases(t, seq)
+					for _, q := range qual {
+						if q < '!'+2 || q > '!'+41 {
+							t.Fatalf("invalid quality %q", qual)
+						}
+					}
+					if i+4 < len(lines) && (len(seq) < 100 || len(seq) > 300) {
+						t.Fatalf("read length %d", len(seq))
+					}
+					reads++
+				}
+			} else {
+				for _, line := range lines {
+					if line[0] == '>' {
+						reads++
+						continue
+					}
+					if len(line) > genomicsLineWidth {
+						t.Fatalf("line of %d bases", len(line))
+					}
+					checkBases(t, line)
+				}
+			}
+			if reads < int(size)/(2*300+60) {
+				t.Errorf("%s: %d reads in %d bytes", format, reads, size)
+			}
+		}
+	}
+
+	for _, opts := range []GenomicsOpts{WithGenomicsData().ReadLength(0, 10), WithGenomicsData().ReadLength(10, 5), WithGenomicsData().Format("bam")} {
+		if _, err := New(opts.Apply()); err == nil {
+			t.Errorf("%+v: want error", opts)
+		}
+	}
+}
+
+func checkBases(t *testing.T, seq []byte) {
+	t.Helper()
+	if len(bytes.Trim(seq, "ACGTN")) != 0 {
+		t.Fatalf("invalid bases: %q", seq)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"path"
+	"sort"
+)
+
+// maxHotPrefixes is the maximum number of hot prefixes.
+const maxHotPrefixes = 10000
+
+// hotPrefixes picks a hot prefix like 'hot-0003' for a percentage of object names.
+// Hot prefixes are shared by all sources.
+type hotPrefixes struct {
+	n       int
+	percent float64
+	skew    float64
+	// cum contains the cumulative weight of prefixes, hottest first.
+	cum []float64
+}
+
+// WithHotPrefixes places percent of object names below n hot prefixes, shared by all sources.
+// Hot prefix i is picked with a weight of 1/(i+1)^skew, a Zipf distribution,
+// so a skew of 0 spreads hot names evenly over the hot prefixes.
+// Hot prefixes are below the custom prefix and replace the random prefix of the source.
+// The prefix of sources is the custom prefix, so all names are below it.
+// A count of 0 disables hot prefixes.
+func WithHotPrefixes(n int, percent, skew float64) Option {
+	return func(o *Options) error {
+		if n == 0 {
+			o.hotPrefixes = nil
+			return nil
+		}
+		if n < 0 || n > maxHotPrefixes {
+			return fmt.Errorf("hot prefixes: count must be 0 to %d", maxHotPrefixes)
+		}
+		if !(percent > 0 && percent <= 100) {
+			return errors.New("hot prefixes: percent must be > 0 and <= 100")
+		}
+		if skew < 0 || math.IsNaN(skew) {
+			return errors.New("hot prefixes: skew must be >= 0")
+		}
+		h := hotPrefixes{
+			n:       n,
+			percent: percent,
+			skew:    skew,
+			cum:     make([]float64, n),
+		}
+		total := 0.0
+		for i := range h.cum {
+			total += math.Pow(float64(i+1), -skew)
+			h.cum[i] = total
+		}
+		o.hotPrefixes = &h
+		return nil
+	}
+}
+
+// pick returns a hot prefix below base or an empty string if the name should not be hot.
+func (h *hotPrefixes) pick(rng *rand.Rand, base string) string {
+	if rng.Float64()*100 >= h.percent {
+		return ""
+	}
+	v := rng.Float64() * h.cum[len(h.cum)-1]
+	i := sort.SearchFloat64s(h.cum, v)
+	if i >= h.n {
+		i = h.n - 1
+	}
+	return path.Join(base, fmt.Sprintf("hot-%04d", i))
+}
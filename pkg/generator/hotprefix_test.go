@@ -0,0 +1,84 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithHotPrefixes(t *testing.T) {
+	const n = 20000
+	for _, skew := range []float64{0, 1.2} {
+		var prefixes []string
+		counts := make(map[string]int)
+		for s := 0; s < 4; s++ {
+			src, err := New(WithRandomData().Apply(), WithSize(10), WithCustomPrefix("pfx"), WithPrefixSize(8), WithHotPrefixes(10, 80, skew))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if src.Prefix() != "pfx" {
+				t.Fatalf("source prefix %q", src.Prefix())
+			}
+			for i := 0; i < n/4; i++ {
+				name := src.Object().Name
+				parts := strings.Split(name, "/")
+				if len(parts) != 3 || parts[0] != "pfx" {
+					t.Fatalf("unexpected name %q", name)
+				}
+				if !strings.HasPrefix(parts[1], "hot-") {
+					if len(parts[1]) != 8 {
+						t.Fatalf("name %q not below random prefix", name)
+					}
+					parts[1] = "cold"
+				}
+				if counts[parts[1]] == 0 {
+					prefixes = append(prefixes, parts[1])
+				}
+				counts[parts[1]]++
+			}
+		}
+		// Hot prefixes are shared by sources. Names that are not hot are counted as "cold".
+		if len(prefixes) != 11 {
+			t.Fatalf("skew %v: got prefixes %v", skew, prefixes)
+		}
+		if cold := counts["cold"]; cold < n*18/100 || cold > n*22/100 {
+			t.Errorf("skew %v: %d of %d names not hot", skew, cold, n)
+		}
+		hottest, coldest := counts["hot-0000"], counts["hot-0009"]
+		switch {
+		case skew == 0 && (hottest < 1400 || hottest > 1800):
+			t.Errorf("skew 0: %d in hottest prefix", hottest)
+		case skew > 0 && hottest < 10*coldest:
+			t.Errorf("skew %v: %d in hottest, %d in coldest", skew, hottest, coldest)
+		}
+	}
+
+	src, err := New(WithRandomData().Apply(), WithHotPrefixes(1, 100, 1), WithKeyTemplate("{prefix}/{uuid}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := src.Object().Name; !strings.HasPrefix(name, "hot-0000/") {
+		t.Errorf("name %q not below hot prefix", name)
+	}
+	for _, opt := range []Option{WithHotPrefixes(-1, 50, 1), WithHotPrefixes(10, 0, 1), WithHotPrefixes(10, 101, 1), WithHotPrefixes(10, 50, -1)} {
+		if _, err := New(WithRandomData().Apply(), opt); err == nil {
+			t.Error("want error")
+		}
+	}
+}
@@ -0,0 +1,267 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"math/rand"
+	"sync/atomic"
+)
+
+// imageShapes is the number of ellipses drawn on each image.
+const imageShapes = 8
+
+// Image formats.
+const (
+	ImageJPEG = "jpeg"
+	ImagePNG  = "png"
+)
+
+// WithImageData returns default options for images.
+func WithImageData() ImageOpts {
+	return imageOptsDefaults()
+}
+
+// Apply image data options.
+func (o ImageOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		opts.image = o
+		opts.src = newImage
+		return nil
+	}
+}
+
+func (o ImageOpts) validate() error {
+	switch o.format {
+	case ImageJPEG, ImagePNG:
+	default:
+		return fmt.Errorf("image: unknown format %q. Supported: %s, %s", o.format, ImageJPEG, ImagePNG)
+	}
+	if o.width <= 0 || o.height <= 0 {
+		return errors.New("image: width and height must be > 0")
+	}
+	if o.quality < 1 || o.quality > 100 {
+		return errors.New("image: quality must be between 1 and 100")
+	}
+	return nil
+}
+
+// Format sets the image format, ImageJPEG or ImagePNG.
+func (o ImageOpts) Format(f string) ImageOpts {
+	o.format = f
+	return o
+}
+
+// Resolution sets the width and height of images in pixels.
+func (o ImageOpts) Resolution(width, height int) ImageOpts {
+	o.width, o.height = width, height
+	return o
+}
+
+// Quality sets the JPEG quality between 1 and 100.
+// It is not used for PNG images.
+func (o ImageOpts) Quality(q int) ImageOpts {
+	o.quality = q
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make usage predictable.
+func (o ImageOpts) RngSeed(s int64) ImageOpts {
+	o.seed = &s
+	return o
+}
+
+// ImageOpts are the options for the image data source.
+type ImageOpts struct {
+	seed          *int64
+	format        string
+	width, height int
+	quality       int
+}
+
+func imageOptsDefaults() ImageOpts {
+	return ImageOpts{
+		seed:    nil,
+		format:  ImageJPEG,
+		width:   1024,
+		height:  768,
+		quality: 85,
+	}
+}
+
+type imageSrc struct {
+	counter uint64
+	o       Options
+	rng     *rand.Rand
+	noise   *xoshiro256
+	img     *image.RGBA
+	buf     bytes.Buffer
+	png     png.Encoder
+	obj     Object
+}
+
+func newImage(o Options) (Source, error) {
+	rndSrc := rand.NewSource(int64(rand.Uint64()))
+	if o.image.seed != nil {
+		rndSrc = rand.NewSource(*o.image.seed)
+	}
+	i := imageSrc{
+		o:   o,
+		rng: rand.New(rndSrc),
+		img: image.NewRGBA(image.Rect(0, 0, o.image.width, o.image.height)),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "image/" + o.image.format,
+			Size:        0,
+		},
+	}
+	i.noise = newXoshiro256(i.rng.Uint64())
+	i.obj.setPrefix(o)
+	return &i, nil
+}
+
+// Object returns an encoded image.
+// The size of the object is the size of the encoded image.
+func (i *imageSrc) Object() *Object {
+	atomic.AddUint64(&i.counter, 1)
+	i.draw()
+	i.buf.Reset()
+	var err error
+	ext := "jpg"
+	switch i.o.image.format {
+	case ImagePNG:
+		ext = "png"
+		err = i.png.Encode(&i.buf, i.img)
+	default:
+		err = jpeg.Encode(&i.buf, i.img, &jpeg.Options{Quality: i.o.image.quality})
+	}
+	if err != nil {
+		// Encoding an in-memory image cannot fail.
+		panic(err)
+	}
+	i.obj.Size = int64(i.buf.Len())
+	i.obj.Reader = bytes.NewReader(i.buf.Bytes())
+
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], i.rng)
+	i.obj.setName(fmt.Sprintf("%d.%s.%s", atomic.LoadUint64(&i.counter), string(nBuf[:]), ext))
+	return &i.obj
+}
+
+// draw draws a gradient background with ellipses and a little noise,
+// which compresses roughly like a photo.
+func (i *imageSrc) draw() {
+	type ellipse struct {
+		cx, cy, rx, ry int
+		c              [3]uint8
+	}
+	w, h := i.o.image.width, i.o.image.height
+	var corners [4][3]int
+	for c := range corners {
+		for ch := range corners[c] {
+			corners[c][ch] = i.rng.Intn(256)
+		}
+	}
+	var shapes [imageShapes]ellipse
+	for s := range shapes {
+		shapes[s] = ellipse{cx: i.rng.Intn(w), cy: i.rng.Intn(h), rx: 1 + i.rng.Intn(1+w/4), ry: 1 + i.rng.Intn(1+h/4)}
+		for ch := range shapes[s].c {
+			shapes[s].c[ch] = uint8(i.rng.Intn(256))
+		}
+	}
+	noise := make([]byte, w)
+	for y := 0; y < h; y++ {
+		row := i.img.Pix[y*i.img.Stride : y*i.img.Stride+w*4]
+
+		// Interpolate the corner colors in 16.16 fixed point.
+		var col, step [3]int
+		for ch := range col {
+			left := (corners[0][ch]*(h-y) + corners[2][ch]*y) << 16 / h
+			right := (corners[1][ch]*(h-y) + corners[3][ch]*y) << 16 / h
+			col[ch], step[ch] = left, (right-left)/w
+		}
+		for x := 0; x < w; x++ {
+			row[x*4] = uint8(col[0] >> 16)
+			row[x*4+1] = uint8(col[1] >> 16)
+			row[x*4+2] = uint8(col[2] >> 16)
+			row[x*4+3] = 255
+			col[0] += step[0]
+			col[1] += step[1]
+			col[2] += step[2]
+		}
+
+		// Blend half of the color of the ellipses covering the row.
+		for _, s := range shapes {
+			dy := y - s.cy
+			if dy < -s.ry || dy > s.ry {
+				continue
+			}
+			half := int(float64(s.rx) * math.Sqrt(1-float64(dy*dy)/float64(s.ry*s.ry)))
+			from, to := s.cx-half, s.cx+half
+			if from < 0 {
+				from = 0
+			}
+			if to >= w {
+				to = w - 1
+			}
+			for x := from; x <= to; x++ {
+				px := row[x*4 : x*4+3]
+				px[0] = uint8((int(px[0]) + int(s.c[0])) / 2)
+				px[1] = uint8((int(px[1]) + int(s.c[1])) / 2)
+				px[2] = uint8((int(px[2]) + int(s.c[2])) / 2)
+			}
+		}
+
+		i.noise.Read(noise)
+		for x, n := range noise {
+			n := int(n&15) - 8
+			px := row[x*4 : x*4+3]
+			for ch, v := range px {
+				v := int(v) + n
+				if v < 0 {
+					v = 0
+				} else if v > 255 {
+					v = 255
+				}
+				px[ch] = uint8(v)
+			}
+		}
+	}
+}
+
+func (i *imageSrc) String() string {
+	opts := i.o.image
+	if opts.format == ImageJPEG {
+		return fmt.Sprintf("JPEG images; %dx%d pixels, quality %d", opts.width, opts.height, opts.quality)
+	}
+	return fmt.Sprintf("PNG images; %dx%d pixels", opts.width, opts.height)
+}
+
+func (i *imageSrc) Prefix() string {
+	return i.obj.Prefix
+}
@@ -0,0 +1,62 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"testing"
+)
+
+func TestWithImageData(t *testing.T) {
+	for _, format := range []string{ImageJPEG, ImagePNG} {
+		src, err := New(WithImageData().Format(format).Resolution(320, 200).RngSeed(1).Apply())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			obj := src.Object()
+			if obj.ContentType != "image/"+format {
+				t.Errorf("got content type %q", obj.ContentType)
+			}
+			b, err := io.ReadAll(obj.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int64(len(b)) != obj.Size {
+				t.Fatalf("got %d bytes, want %d", len(b), obj.Size)
+			}
+			img, got, err := image.Decode(bytes.NewReader(b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size := img.Bounds().Size(); got != format || size.X != 320 || size.Y != 200 {
+				t.Errorf("got %s image of %v", got, size)
+			}
+		}
+	}
+
+	for _, opts := range []ImageOpts{WithImageData().Format("gif"), WithImageData().Resolution(0, 10), WithImageData().Quality(101)} {
+		if _, err := New(opts.Apply()); err == nil {
+			t.Errorf("%+v: want error", opts)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+)
+
+// WithJSONData returns default JSON Opts.
+func WithJSONData() JSONOpts {
+	return jsonOptsDefaults()
+}
+
+// Apply JSON data options.
+func (o JSONOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		opts.json = o
+		opts.src = newJSON
+		return nil
+	}
+}
+
+func (o JSONOpts) validate() error {
+	if o.fields <= 0 {
+		return errors.New("json: fields <= 0")
+	}
+	if o.depth <= 0 {
+		return errors.New("json: depth <= 0")
+	}
+	if o.cardinality < 0 {
+		return errors.New("json: cardinality < 0")
+	}
+	return nil
+}
+
+// Fields sets the number of fields in each object of a document.
+func (o JSONOpts) Fields(n int) JSONOpts {
+	o.fields = n
+	return o
+}
+
+// Depth sets the nesting depth of each document.
+// A depth of 1 gives documents without nested objects.
+func (o JSONOpts) Depth(n int) JSONOpts {
+	o.depth = n
+	return o
+}
+
+// Cardinality sets the number of distinct values of each field.
+// Use 0 for values that are all different.
+func (o JSONOpts) Cardinality(n int) JSONOpts {
+	o.cardinality = n
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make usage predictable.
+func (o JSONOpts) RngSeed(s int64) JSONOpts {
+	o.seed = &s
+	return o
+}
+
+// JSONOpts provides options for JSON generation.
+type JSONOpts struct {
+	seed        *int64
+	fields      int
+	depth       int
+	cardinality int
+}
+
+func jsonOptsDefaults() JSONOpts {
+	return JSONOpts{
+		seed:        nil,
+		fields:      10,
+		depth:       3,
+		cardinality: 1000,
+	}
+}
+
+type jsonSource struct {
+	counter uint64
+	o       Options
+	buf     *circularBuffer
+	rng     *rand.Rand
+	obj     Object
+	doc     []byte
+}
+
+func newJSON(o Options) (Source, error) {
+	rndSrc := rand.NewSource(int64(rand.Uint64()))
+	if o.json.seed != nil {
+		rndSrc = rand.NewSource(*o.json.seed)
+	}
+	j := jsonSource{
+		o:   o,
+		rng: rand.New(rndSrc),
+		buf: newCircularBuffer(make([]byte, 0, o.totalSize), o.totalSize),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "application/json",
+			Size:        0,
+		},
+	}
+	j.obj.setPrefix(o)
+	return &j, nil
+}
+
+// Object returns a JSON array of documents.
+// The array is padded with whitespace to the object size.
+// A 1 byte object is the number 0, since an array does not fit.
+func (j *jsonSource) Object() *Object {
+	atomic.AddUint64(&j.counter, 1)
+	j.obj.Size = j.o.getSize(j.rng)
+	size := int(j.obj.Size)
+
+	// With compression the first documents are repeated.
+	unique := size
+	if j.o.compRatio > 0 {
+		if int64(unique) > j.o.compWindow {
+			unique = int(j.o.compWindow)
+		}
+		unique /= j.o.compRatio
+	}
+
+	dst := j.buf.data[:0]
+	if size >= 2 {
+		dst = append(dst, '[')
+		// docs contains the start and end offset of each unique document.
+		var docs [][2]int
+		for len(dst) < unique || len(docs) == 0 {
+			j.doc = j.appendObject(j.doc[:0], j.o.json.depth)
+			if len(dst)+len(j.doc)+2 > size {
+				break
+			}
+			if len(docs) > 0 {
+				dst = append(dst, ',')
+			}
+			docs = append(docs, [2]int{len(dst), len(dst) + len(j.doc)})
+			dst = append(dst, j.doc...)
+		}
+		for i := 0; len(docs) > 0; i++ {
+			d := docs[i%len(docs)]
+			if len(dst)+d[1]-d[0]+2 > size {
+				break
+			}
+			dst = append(dst, ',')
+			dst = append(dst, dst[d[0]:d[1]]...)
+		}
+	}
+	for len(dst) < size-1 {
+		dst = append(dst, '\n')
+	}
+	if len(dst) < size {
+		if size >= 2 {
+			dst = append(dst, ']')
+		} else {
+			dst = append(dst, '0')
+		}
+	}
+	j.buf.data = dst
+
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], j.rng)
+	j.obj.setName(fmt.Sprintf("%d.%s.json", atomic.LoadUint64(&j.counter), string(nBuf[:])))
+
+	j.obj.Reader = j.buf.Reset(j.obj.Size)
+	return &j.obj
+}
+
+// appendObject appends an object with the configured number of fields.
+// The last field contains a nested object until depth is reached.
+func (j *jsonSource) appendObject(dst []byte, depth int) []byte {
+	opts := j.o.json
+	dst = append(dst, '{')
+	for i := 0; i < opts.fields; i++ {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, `"field`...)
+		dst = strconv.AppendInt(dst, int64(i), 10)
+		dst = append(dst, `":`...)
+		if i == opts.fields-1 && depth > 1 {
+			dst = j.appendObject(dst, depth-1)
+			continue
+		}
+		v := j.rng.Int63()
+		if opts.cardinality > 0 {
+			v = int64(j.rng.Intn(opts.cardinality))
+		}
+		switch i % 4 {
+		case 0:
+			dst = append(dst, `"value`...)
+			dst = strconv.AppendInt(dst, v, 36)
+			dst = append(dst, '"')
+		case 1:
+			dst = strconv.AppendInt(dst, v, 10)
+		case 2:
+			dst = strconv.AppendFloat(dst, float64(v)/100, 'f', 2, 64)
+		case 3:
+			dst = strconv.AppendBool(dst, v&1 == 1)
+		}
+	}
+	return append(dst, '}')
+}
+
+func (j *jsonSource) String() string {
+	opts := j.o.json
+	if j.o.randSize {
+		return fmt.Sprintf("JSON data; %d fields, depth %d, random size up to %d bytes", opts.fields, opts.depth, j.o.totalSize)
+	}
+	return fmt.Sprintf("JSON data; %d fields, depth %d, %d bytes total", opts.fields, opts.depth, j.o.totalSize)
+}
+
+func (j *jsonSource) Prefix() string {
+	return j.obj.Prefix
+}
@@ -0,0 +1,257 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Placeholders of key templates.
+const (
+	keyPrefix    = "prefix"
+	keyDate      = "date"
+	keyUUID      = "uuid"
+	keyCounter   = "counter"
+	keyRand      = "rand"
+	keyExt       = "ext"
+	keyName      = "name"
+	keyPartition = "partition"
+	keyTree      = "tree"
+)
+
+// keyTemplate creates object names from a template like '{prefix}/{date:2006/01/02}/{uuid}.{ext}'.
+type keyTemplate struct {
+	parts []keyPart
+	// hasPrefix is set if the template starts with the prefix.
+	// Otherwise names are created below the prefix.
+	hasPrefix bool
+	// hasPartition is set if the template contains the time partition.
+	hasPartition bool
+	// hasTree is set if the template contains the folders of the prefix tree.
+	hasTree bool
+	// counter is shared by all sources of the template.
+	counter *uint64
+}
+
+type keyPart struct {
+	literal     string
+	placeholder string
+	arg         string
+	n           int
+}
+
+// WithKeyTemplate sets a template for object names.
+// Placeholders are {prefix}, {date:layout}, {uuid}, {counter:format},
+// {rand:length}, {ext}, {name} for the name the generator would use,
+// {partition} for the time partition set with WithPartitions
+// and {tree} for the folders set with WithPrefixTree.
+// An empty template keeps the names of the generator.
+func WithKeyTemplate(tmpl string) Option {
+	return func(o *Options) error {
+		if tmpl == "" {
+			o.keyTemplate = nil
+			return nil
+		}
+		t, err := parseKeyTemplate(tmpl)
+		if err != nil {
+			return err
+		}
+		o.keyTemplate = t
+		return nil
+	}
+}
+
+func parseKeyTemplate(s string) (*keyTemplate, error) {
+	t := keyTemplate{counter: new(uint64)}
+	unique := false
+	for len(s) > 0 {
+		start := strings.IndexByte(s, '{')
+		if start < 0 {
+			t.parts = append(t.parts, keyPart{literal: s})
+			break
+		}
+		if start > 0 {
+			t.parts = append(t.parts, keyPart{literal: s[:start]})
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("key template: unclosed placeholder %q", s[start:])
+		}
+		name, arg, _ := strings.Cut(s[start+1:start+end], ":")
+		p := keyPart{placeholder: name, arg: arg}
+		switch name {
+		case keyPrefix:
+			if len(t.parts) > 0 {
+				return nil, errors.New("key template: {prefix} must be at the start")
+			}
+			t.hasPrefix = true
+		case keyDate:
+			if p.arg == "" {
+				p.arg = "2006-01-02"
+			}
+		case keyCounter:
+			if p.arg == "" {
+				p.arg = "%d"
+			}
+			if v := fmt.Sprintf(p.arg, uint64(1)); strings.Contains(v, "%!") || !strings.Contains(v, "1") {
+				return nil, fmt.Errorf("key template: invalid counter format %q", p.arg)
+			}
+			unique = true
+		case keyRand:
+			p.n = 16
+			if p.arg != "" {
+				n, err := strconv.Atoi(p.arg)
+				if err != nil || n < 1 || n > 64 {
+					return nil, fmt.Errorf("key template: random length %q must be 1 to 64", p.arg)
+				}
+				p.n = n
+			}
+			unique = true
+		case keyUUID, keyName:
+			unique = true
+		case keyPartition:
+			t.hasPartition = true
+		case keyTree:
+			t.hasTree = true
+		case keyExt:
+		default:
+			return nil, fmt.Errorf("key template: unknown placeholder {%s}", name)
+		}
+		t.parts = append(t.parts, p)
+		s = s[start+end+1:]
+	}
+	if !unique {
+		return nil, errors.New("key template: must contain {uuid}, {counter}, {rand} or {name} to create unique names")
+	}
+	return &t, nil
+}
+
+// counterVerbs match the verbs of counter formats.
+var counterVerbs = regexp.MustCompile(`%[-+# 0-9]*[a-zA-Z]`)
+
+// KeyTemplateMatcher returns a function reporting whether an object name
+// could have been created by the key template, with any prefix.
+func KeyTemplateMatcher(tmpl string) (func(name string) bool, error) {
+	t, err := parseKeyTemplate(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !t.hasPrefix {
+		// Names are created below the prefix.
+		sb.WriteString("(.+/)?")
+	}
+	for _, p := range t.parts {
+		switch p.placeholder {
+		case "":
+			sb.WriteString(regexp.QuoteMeta(p.literal))
+		case keyPrefix:
+			sb.WriteString(".*")
+		case keyUUID:
+			sb.WriteString("[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}")
+		case keyRand:
+			fmt.Fprintf(&sb, "[%s]{%d}", regexp.QuoteMeta(asciiLetters), p.n)
+		case keyCounter:
+			// Literals of the format are kept, the number can have any base.
+			verbs := counterVerbs.FindAllStringIndex(p.arg, -1)
+			last := 0
+			for _, v := range verbs {
+				sb.WriteString(regexp.QuoteMeta(p.arg[last:v[0]]))
+				sb.WriteString("[0-9a-fA-Fx ]+")
+				last = v[1]
+			}
+			sb.WriteString(regexp.QuoteMeta(p.arg[last:]))
+		case keyExt:
+			sb.WriteString("[^/]*")
+		default:
+			sb.WriteString(".+")
+		}
+	}
+	sb.WriteString("$")
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, err
+	}
+	return func(name string) bool {
+		if t.hasPrefix {
+			// Names with an empty prefix have the leading slash removed.
+			return re.MatchString(name) || re.MatchString("/"+name)
+		}
+		return re.MatchString(name)
+	}, nil
+}
+
+// name returns the object name from the prefix, the name the generator would use, the partition and the tree folders.
+func (t *keyTemplate) name(prefix, generated, partition, tree string, rng *rand.Rand) string {
+	var sb strings.Builder
+	var now time.Time
+	for _, p := range t.parts {
+		switch p.placeholder {
+		case "":
+			sb.WriteString(p.literal)
+		case keyPrefix:
+			sb.WriteString(prefix)
+		case keyDate:
+			if now.IsZero() {
+				now = time.Now().UTC()
+			}
+			sb.WriteString(now.Format(p.arg))
+		case keyUUID:
+			var b [16]byte
+			rng.Read(b[:])
+			// Version 4, variant 10.
+			b[6] = (b[6] & 0x0f) | 0x40
+			b[8] = (b[8] & 0x3f) | 0x80
+			h := hex.EncodeToString(b[:])
+			sb.WriteString(h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:])
+		case keyCounter:
+			fmt.Fprintf(&sb, p.arg, atomic.AddUint64(t.counter, 1))
+		case keyRand:
+			b := make([]byte, p.n)
+			randASCIIBytes(b, rng)
+			sb.Write(b)
+		case keyExt:
+			if i := strings.LastIndexByte(generated, '.'); i >= 0 && !strings.ContainsRune(generated[i:], '/') {
+				sb.WriteString(generated[i+1:])
+			}
+		case keyName:
+			sb.WriteString(generated)
+		case keyPartition:
+			sb.WriteString(partition)
+		case keyTree:
+			sb.WriteString(tree)
+		}
+	}
+	name := sb.String()
+	if t.hasPrefix {
+		return strings.TrimPrefix(name, "/")
+	}
+	if prefix == "" {
+		return name
+	}
+	return prefix + "/" + name
+}
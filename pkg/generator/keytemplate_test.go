@@ -0,0 +1,68 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestWithKeyTemplate(t *testing.T) {
+	date := time.Now().UTC().Format("2006/01/02")
+	tests := []struct {
+		tmpl   string
+		prefix int
+		want   string
+	}{
+		{tmpl: "{prefix}/{date:2006/01/02}/{uuid}.{ext}", prefix: 8, want: `^[^/]{8}/` + date + `/[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}\.rnd$`},
+		{tmpl: "{prefix}/{counter:%08d}", prefix: 0, want: `^0000000[12]$`},
+		{tmpl: "logs/{rand:4}-{name}", prefix: 3, want: `^[^/]{3}/logs/.{4}-\d+\..{16}\.rnd$`},
+		{tmpl: "{counter:%x}.{ext}", prefix: 0, want: `^[12]\.rnd$`},
+	}
+	for _, tt := range tests {
+		newSrc, err := NewFn(WithRandomData().Apply(), WithSize(10), WithPrefixSize(tt.prefix), WithKeyTemplate(tt.tmpl))
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := newSrc()
+		re := regexp.MustCompile(tt.want)
+		match, err := KeyTemplateMatcher(tt.tmpl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			name := src.Object().Name
+			if !re.MatchString(name) {
+				t.Errorf("%s: name %q does not match %s", tt.tmpl, name, tt.want)
+			}
+			if !match(name) {
+				t.Errorf("%s: name %q not matched by template", tt.tmpl, name)
+			}
+		}
+		if match("data/report.pdf") {
+			t.Errorf("%s: matched other name", tt.tmpl)
+		}
+	}
+
+	for _, tmpl := range []string{"{date}", "{uuid", "{foo}", "a/{prefix}/{uuid}", "{counter:%s}", "{rand:100}"} {
+		if _, err := New(WithRandomData().Apply(), WithKeyTemplate(tmpl)); err == nil {
+			t.Errorf("%s: want error", tmpl)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Formats of log lines.
+const (
+	// LogFormatNginx is the nginx combined format, with referer and user agent.
+	LogFormatNginx = "nginx"
+	// LogFormatApache is the Apache common log format.
+	LogFormatApache = "apache"
+	// LogFormatJSON is a JSON object on each line.
+	LogFormatJSON = "json"
+)
+
+const (
+	// logMaxLines is the maximum number of lines in each object.
+	logMaxLines = 10_000_000
+
+	// logClients is the number of distinct client addresses.
+	logClients = 5000
+
+	// logPaths is the number of distinct request paths.
+	logPaths = 2000
+
+	// logZipf is the exponent of the frequency of clients and paths.
+	logZipf = 1.2
+
+	// logInterval is the average time between lines.
+	logInterval = 5 * time.Millisecond
+)
+
+// Parts of generated log lines.
+var (
+	logMethods  = []string{"GET", "POST", "HEAD", "PUT", "DELETE"}
+	logMethodsW = []int{85, 9, 3, 2, 1}
+	logStatus   = []int{200, 304, 404, 301, 302, 201, 204, 403, 500, 503}
+	logStatusW  = []int{78, 7, 5, 2, 1, 2, 1, 2, 1, 1}
+	logAgents   = []string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1",
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+		"curl/8.4.0",
+		"python-requests/2.31.0",
+	}
+	logAgentsW   = []int{30, 15, 8, 20, 12, 7, 4, 4}
+	logSections  = []string{"api/v1", "api/v2", "static", "images", "blog", "products", "account", "search", "docs", "assets"}
+	logResources = []string{"index", "users", "orders", "items", "login", "cart", "checkout", "profile", "settings", "feed", "comments", "report"}
+	logExts      = []string{"", "", "", ".html", ".css", ".js", ".png", ".jpg", ".json", ".svg"}
+	logReferers  = []string{"-", "-", "-", "https://www.google.com/", "https://www.bing.com/", "https://example.com/", "https://example.com/blog/"}
+)
+
+// WithLogData returns default options for web server logs.
+func WithLogData() LogOpts {
+	return logOptsDefaults()
+}
+
+// Apply log data options.
+func (o LogOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		opts.logs = o
+		opts.src = newLogs
+		return nil
+	}
+}
+
+func (o LogOpts) validate() error {
+	if o.lines <= 0 || o.lines > logMaxLines {
+		return fmt.Errorf("logs: lines must be 1 to %d", logMaxLines)
+	}
+	switch o.format {
+	case LogFormatNginx, LogFormatApache, LogFormatJSON:
+	default:
+		return fmt.Errorf("logs: unknown format %q; supported: %s, %s, %s", o.format, LogFormatNginx, LogFormatApache, LogFormatJSON)
+	}
+	return nil
+}
+
+// Format sets the format of the lines.
+func (o LogOpts) Format(f string) LogOpts {
+	o.format = f
+	return o
+}
+
+// Lines sets the number of lines in each object.
+func (o LogOpts) Lines(n int) LogOpts {
+	o.lines = n
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make usage predictable.
+// The timestamps still start at the current time.
+func (o LogOpts) RngSeed(s int64) LogOpts {
+	o.seed = &s
+	return o
+}
+
+// LogOpts provides options for web server log generation.
+type LogOpts struct {
+	seed   *int64
+	format string
+	lines  int
+}
+
+func logOptsDefaults() LogOpts {
+	return LogOpts{
+		seed:   nil,
+		format: LogFormatNginx,
+		lines:  10000,
+	}
+}
+
+type logSource struct {
+	counter uint64
+	o       Options
+	buf     *circularBuffer
+	rng     *rand.Rand
+	obj     Object
+
+	// now is the time of the last line.
+	// Times increase over all objects of the source.
+	now     time.Time
+	clients []string
+	paths   []string
+	// clientIdx and pathIdx pick clients and paths, the first ones most often.
+	clientIdx, pathIdx *rand.Zipf
+}
+
+func newLogs(o Options) (Source, error) {
+	rndSrc := rand.NewSource(int64(rand.Uint64()))
+	if o.logs.seed != nil {
+		rndSrc = rand.NewSource(*o.logs.seed)
+	}
+	rng := rand.New(rndSrc)
+	l := logSource{
+		o:   o,
+		rng: rng,
+		buf: newCircularBuffer(make([]byte, 0, o.totalSize), o.totalSize),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "text/plain",
+			Size:        0,
+		},
+		now:       time.Now().UTC(),
+		clients:   make([]string, logClients),
+		paths:     make([]string, logPaths),
+		clientIdx: rand.NewZipf(rng, logZipf, 1, logClients-1),
+		pathIdx:   rand.NewZipf(rng, logZipf, 1, logPaths-1),
+	}
+	if o.logs.format == LogFormatJSON {
+		l.obj.ContentType = "application/x-ndjson"
+	}
+	for i := range l.clients {
+		l.clients[i] = fmt.Sprintf("%d.%d.%d.%d", 1+rng.Intn(223), rng.Intn(256), rng.Intn(256), 1+rng.Intn(254))
+	}
+	for i := range l.paths {
+		p := "/" + logSections[rng.Intn(len(logSections))] + "/" + logResources[rng.Intn(len(logResources))]
+		if rng.Intn(3) == 0 {
+			p += "/" + strconv.Itoa(rng.Intn(100000))
+		}
+		p += logExts[rng.Intn(len(logExts))]
+		if rng.Intn(5) == 0 {
+			p += "?page=" + strconv.Itoa(1+rng.Intn(20))
+		}
+		l.paths[i] = p
+	}
+	l.obj.setPrefix(o)
+	return &l, nil
+}
+
+// Object returns an object with a log line for each request.
+func (l *logSource) Object() *Object {
+	atomic.AddUint64(&l.counter, 1)
+	dst := l.buf.data[:0]
+	for i := 0; i < l.o.logs.lines; i++ {
+		dst = l.appendLine(dst)
+		dst = append(dst, '\n')
+	}
+	l.buf.data = dst
+	l.obj.Size = int64(len(dst))
+
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], l.rng)
+	l.obj.setName(fmt.Sprintf("%d.%s.log", atomic.LoadUint64(&l.counter), string(nBuf[:])))
+
+	l.obj.Reader = l.buf.Reset(l.obj.Size)
+	return &l.obj
+}
+
+// appendLine appends a line for a random request.
+func (l *logSource) appendLine(dst []byte) []byte {
+	rng := l.rng
+	l.now = l.now.Add(time.Duration(rng.ExpFloat64() * float64(logInterval)))
+	client := l.clients[l.clientIdx.Uint64()]
+	path := l.paths[l.pathIdx.Uint64()]
+	method := logMethods[pickWeighted(rng, logMethodsW)]
+	status := logStatus[pickWeighted(rng, logStatusW)]
+	var size int64
+	if status != 304 && status != 204 && method != "HEAD" {
+		// Most responses are small, with a long tail of large ones.
+		size = int64(math.Exp(7 + 1.5*rng.NormFloat64()))
+	}
+	agent := logAgents[pickWeighted(rng, logAgentsW)]
+	referer := logReferers[rng.Intn(len(logReferers))]
+
+	switch l.o.logs.format {
+	case LogFormatJSON:
+		dst = append(dst, `{"time":"`...)
+		dst = l.now.AppendFormat(dst, "2006-01-02T15:04:05.000Z07:00")
+		dst = append(dst, `","remote_addr":"`...)
+		dst = append(dst, client...)
+		dst = append(dst, `","method":"`...)
+		dst = append(dst, method...)
+		dst = append(dst, `","path":"`...)
+		dst = append(dst, path...)
+		dst = append(dst, `","status":`...)
+		dst = strconv.AppendInt(dst, int64(status), 10)
+		dst = append(dst, `,"bytes":`...)
+		dst = strconv.AppendInt(dst, size, 10)
+		dst = append(dst, `,"duration_ms":`...)
+		dst = strconv.AppendFloat(dst, rng.ExpFloat64()*20, 'f', 3, 64)
+		dst = append(dst, `,"referer":"`...)
+		dst = append(dst, referer...)
+		dst = append(dst, `","user_agent":"`...)
+		dst = append(dst, agent...)
+		return append(dst, `"}`...)
+	}
+	dst = append(dst, client...)
+	dst = append(dst, " - - ["...)
+	dst = l.now.AppendFormat(dst, "02/Jan/2006:15:04:05 -0700")
+	dst = append(dst, `] "`...)
+	dst = append(dst, method...)
+	dst = append(dst, ' ')
+	dst = append(dst, path...)
+	dst = append(dst, ` HTTP/1.1" `...)
+	dst = strconv.AppendInt(dst, int64(status), 10)
+	dst = append(dst, ' ')
+	if size == 0 && l.o.logs.format == LogFormatApache {
+		dst = append(dst, '-')
+	} else {
+		dst = strconv.AppendInt(dst, size, 10)
+	}
+	if l.o.logs.format == LogFormatApache {
+		return dst
+	}
+	dst = append(dst, ` "`...)
+	dst = append(dst, referer...)
+	dst = append(dst, `" "`...)
+	dst = append(dst, agent...)
+	return append(dst, '"')
+}
+
+// pickWeighted returns a random index of the weights.
+func pickWeighted(rng *rand.Rand, weights []int) int {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	v := rng.Intn(total)
+	for i, w := range weights {
+		if v < w {
+			return i
+		}
+		v -= w
+	}
+	return len(weights) - 1
+}
+
+func (l *logSource) String() string {
+	return fmt.Sprintf("Log data; %s format, %d lines", l.o.logs.format, l.o.logs.lines)
+}
+
+func (l *logSource) Prefix() string {
+	return l.obj.Prefix
+}
@@ -0,0 +1,92 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestWithLogData(t *testing.T) {
+	lineRe := map[string]*regexp.Regexp{
+		LogFormatNginx:  regexp.MustCompile(`^\d+\.\d+\.\d+\.\d+ - - \[(\d\d/\w{3}/\d{4}:\d\d:\d\d:\d\d \+0000)\] "[A-Z]+ /\S+ HTTP/1\.1" \d{3} \d+ "[^"]+" "[^"]+"$`),
+		LogFormatApache: regexp.MustCompile(`^\d+\.\d+\.\d+\.\d+ - - \[(\d\d/\w{3}/\d{4}:\d\d:\d\d:\d\d \+0000)\] "[A-Z]+ /\S+ HTTP/1\.1" \d{3} (\d+|-)$`),
+	}
+	for _, format := range []string{LogFormatNginx, LogFormatApache, LogFormatJSON} {
+		src, err := New(WithLogData().Format(format).Lines(500).RngSeed(1).Apply())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var last time.Time
+		for i := 0; i < 2; i++ {
+			obj := src.Object()
+			b, err := io.ReadAll(obj.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int64(len(b)) != obj.Size {
+				t.Fatalf("got size %d, want %d", len(b), obj.Size)
+			}
+			lines := bytes.Split(bytes.TrimSuffix(b, []byte("\n")), []byte("\n"))
+			if len(lines) != 500 {
+				t.Fatalf("%s: got %d lines, want 500", format, len(lines))
+			}
+			for _, line := range lines {
+				var ts time.Time
+				if format == LogFormatJSON {
+					var rec struct {
+						Time   time.Time `json:"time"`
+						Status int       `json:"status"`
+						Path   string    `json:"path"`
+					}
+					if err := json.Unmarshal(line, &rec); err != nil {
+						t.Fatalf("%s: %v: %s", format, err, line)
+					}
+					if rec.Status < 200 || rec.Path == "" {
+						t.Fatalf("%s: invalid line: %s", format, line)
+					}
+					ts = rec.Time
+				} else {
+					m := lineRe[format].FindSubmatch(line)
+					if m == nil {
+						t.Fatalf("%s: invalid line: %s", format, line)
+					}
+					ts, err = time.Parse("02/Jan/2006:15:04:05 -0700", string(m[1]))
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+				// Lines without milliseconds may have the same time.
+				if ts.Before(last.Truncate(time.Second)) {
+					t.Fatalf("%s: time %v before %v", format, ts, last)
+				}
+				last = ts
+			}
+		}
+	}
+
+	for _, opts := range []LogOpts{WithLogData().Lines(0), WithLogData().Format("syslog")} {
+		if _, err := New(opts.Apply()); err == nil {
+			t.Errorf("%+v: want error", opts)
+		}
+	}
+}
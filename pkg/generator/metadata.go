@@ -0,0 +1,142 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+)
+
+// Limits of object tags in S3.
+const (
+	maxTags        = 10
+	maxTagKey      = 128
+	maxTagValue    = 256
+	maxMetadataLen = 2048
+
+	// maxCardinality is the maximum number of distinct values of each key.
+	maxCardinality = 1 << 20
+)
+
+// attrLetters are the characters of keys and values.
+// They are valid in both metadata headers and tags.
+const attrLetters = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// objectAttrs creates user metadata or tags with a fixed set of keys.
+type objectAttrs struct {
+	keys     []string
+	valueLen int
+	// values contains the possible values of each key.
+	// Values are random for each object if nil.
+	values [][]string
+}
+
+// WithMetadata adds n user metadata entries to each object.
+// Keys are keyLen long and shared by all objects.
+// Values are valueLen long and each key has cardinality distinct values,
+// or random values for each object with a cardinality of 0.
+// A count of 0 disables user metadata.
+func WithMetadata(n, keyLen, valueLen, cardinality int) Option {
+	return func(o *Options) error {
+		if n == 0 {
+			o.metadata = nil
+			return nil
+		}
+		if n < 0 || n*(keyLen+valueLen) > maxMetadataLen {
+			return fmt.Errorf("metadata: count must be >= 0 and all keys and values at most %d bytes", maxMetadataLen)
+		}
+		a, err := newObjectAttrs("metadata", n, keyLen, valueLen, cardinality)
+		o.metadata = a
+		return err
+	}
+}
+
+// WithTags adds n tags to each object.
+// Keys are keyLen long and shared by all objects.
+// Values are valueLen long and each key has cardinality distinct values,
+// or random values for each object with a cardinality of 0.
+// A count of 0 disables tags.
+func WithTags(n, keyLen, valueLen, cardinality int) Option {
+	return func(o *Options) error {
+		if n == 0 {
+			o.tags = nil
+			return nil
+		}
+		if n < 0 || n > maxTags {
+			return fmt.Errorf("tags: count must be 0 to %d", maxTags)
+		}
+		if keyLen > maxTagKey || valueLen > maxTagValue {
+			return fmt.Errorf("tags: keys can be at most %d and values %d long", maxTagKey, maxTagValue)
+		}
+		a, err := newObjectAttrs("tags", n, keyLen, valueLen, cardinality)
+		o.tags = a
+		return err
+	}
+}
+
+func newObjectAttrs(kind string, n, keyLen, valueLen, cardinality int) (*objectAttrs, error) {
+	// Keys are unique by their number.
+	if minLen := len(strconv.Itoa(n - 1)); keyLen < minLen {
+		return nil, fmt.Errorf("%s: keys must be at least %d long", kind, minLen)
+	}
+	if valueLen < 1 {
+		return nil, fmt.Errorf("%s: values must be at least 1 long", kind)
+	}
+	if cardinality < 0 || cardinality > maxCardinality {
+		return nil, fmt.Errorf("%s: cardinality must be 0 to %d", kind, maxCardinality)
+	}
+	rng := rand.New(rand.NewSource(int64(rand.Uint64())))
+	a := objectAttrs{keys: make([]string, n), valueLen: valueLen}
+	for i := range a.keys {
+		num := strconv.Itoa(i)
+		a.keys[i] = randAttr(rng, keyLen-len(num)) + num
+	}
+	if cardinality > 0 {
+		a.values = make([][]string, n)
+		for i := range a.values {
+			a.values[i] = make([]string, cardinality)
+			for j := range a.values[i] {
+				a.values[i][j] = randAttr(rng, valueLen)
+			}
+		}
+	}
+	return &a, nil
+}
+
+// pick returns keys and values for an object.
+func (a *objectAttrs) pick(rng *rand.Rand) map[string]string {
+	m := make(map[string]string, len(a.keys))
+	for i, k := range a.keys {
+		if a.values == nil {
+			m[k] = randAttr(rng, a.valueLen)
+			continue
+		}
+		m[k] = a.values[i][rng.Intn(len(a.values[i]))]
+	}
+	return m
+}
+
+// randAttr returns n random characters of attrLetters.
+func randAttr(rng *rand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = attrLetters[rng.Intn(len(attrLetters))]
+	}
+	return string(b)
+}
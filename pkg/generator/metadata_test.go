@@ -0,0 +1,70 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"testing"
+)
+
+func TestWithMetadataAndTags(t *testing.T) {
+	src, err := New(WithRandomData().Apply(), WithSize(10), WithMetadata(5, 12, 20, 3), WithTags(10, 8, 30, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]map[string]struct{})
+	tagValues := make(map[string]struct{})
+	for i := 0; i < 200; i++ {
+		obj := src.Object()
+		if len(obj.UserMetadata) != 5 || len(obj.UserTags) != 10 {
+			t.Fatalf("got %d metadata, %d tags", len(obj.UserMetadata), len(obj.UserTags))
+		}
+		for k, v := range obj.UserMetadata {
+			if len(k) != 12 || len(v) != 20 {
+				t.Fatalf("metadata %q: %q", k, v)
+			}
+			if values[k] == nil {
+				values[k] = make(map[string]struct{})
+			}
+			values[k][v] = struct{}{}
+		}
+		for k, v := range obj.UserTags {
+			if len(k) != 8 || len(v) != 30 {
+				t.Fatalf("tag %q: %q", k, v)
+			}
+			tagValues[v] = struct{}{}
+		}
+	}
+	if len(values) != 5 {
+		t.Errorf("got %d metadata keys", len(values))
+	}
+	for k, v := range values {
+		if len(v) != 3 {
+			t.Errorf("metadata %q: got %d values, want 3", k, len(v))
+		}
+	}
+	if len(tagValues) < 1900 {
+		t.Errorf("got %d distinct tag values", len(tagValues))
+	}
+
+	for _, opt := range []Option{WithMetadata(-1, 10, 10, 1), WithMetadata(100, 20, 20, 1), WithMetadata(20, 1, 10, 1),
+		WithMetadata(2, 10, 0, 1), WithTags(11, 10, 10, 1), WithTags(1, 129, 10, 1), WithTags(1, 10, 10, -1)} {
+		if _, err := New(WithRandomData().Apply(), opt); err == nil {
+			t.Error("want error")
+		}
+	}
+}
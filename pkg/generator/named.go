@@ -0,0 +1,128 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"sync/atomic"
+)
+
+// WithNamedData returns default named data options.
+// The content of each object is derived from the seed, the object name and the size,
+// so it can be generated again with NamedContent to verify the object.
+func WithNamedData() NamedOpts {
+	return namedOptsDefaults()
+}
+
+// Apply named data options.
+func (o NamedOpts) Apply() Option {
+	return func(opts *Options) error {
+		opts.named = o
+		opts.src = newNamed
+		return nil
+	}
+}
+
+// Seed sets the seed the content is derived from.
+// Object names are random regardless of the seed.
+func (o NamedOpts) Seed(s int64) NamedOpts {
+	o.seed = s
+	return o
+}
+
+// NamedOpts are the options for the named data source.
+type NamedOpts struct {
+	seed int64
+}
+
+func namedOptsDefaults() NamedOpts {
+	return NamedOpts{seed: 0}
+}
+
+// NamedContent returns the content of an object generated by the named data source.
+func NamedContent(seed int64, name string, size int64) io.ReadSeeker {
+	bs := int64(streamBlockSize)
+	if size < bs {
+		bs = size
+	}
+	if bs < 1 {
+		bs = 1
+	}
+	return newNamedStream(int(bs)).Reset(namedSeed(seed, name, size), size)
+}
+
+// namedSeed returns the seed of the content of an object.
+func namedSeed(seed int64, name string, size int64) int64 {
+	h := fnv.New64a()
+	io.WriteString(h, name)
+	return int64(h.Sum64() ^ uint64(seed)*0x2545f4914f6cdd1d ^ uint64(size)*0x9e3779b97f4a7c15)
+}
+
+func newNamedStream(blockSize int) *blockStream {
+	return newBlockStream(blockSize, func(dst []byte, rng *rand.Rand) {
+		rng.Read(dst)
+	})
+}
+
+type namedSrc struct {
+	counter uint64
+	o       Options
+	rng     *rand.Rand
+	stream  *blockStream
+	obj     Object
+}
+
+func newNamed(o Options) (Source, error) {
+	n := namedSrc{
+		o:      o,
+		rng:    rand.New(rand.NewSource(int64(rand.Uint64()))),
+		stream: newNamedStream(streamBlockSize),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "application/octet-stream",
+			Size:        0,
+		},
+	}
+	n.obj.setPrefix(o)
+	return &n, nil
+}
+
+func (n *namedSrc) Object() *Object {
+	atomic.AddUint64(&n.counter, 1)
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], n.rng)
+	n.obj.Size = n.o.getSize(n.rng)
+	n.obj.setName(fmt.Sprintf("%d.%s.nmd", atomic.LoadUint64(&n.counter), string(nBuf[:])))
+	n.obj.Reader = n.stream.Reset(namedSeed(n.o.named.seed, n.obj.Name, n.obj.Size), n.obj.Size)
+	return &n.obj
+}
+
+func (n *namedSrc) String() string {
+	if n.o.randSize {
+		return fmt.Sprintf("Named data, seed %d; random size up to %d bytes", n.o.named.seed, n.o.totalSize)
+	}
+	return fmt.Sprintf("Named data, seed %d; %d bytes total", n.o.named.seed, n.o.totalSize)
+}
+
+func (n *namedSrc) Prefix() string {
+	return n.obj.Prefix
+}
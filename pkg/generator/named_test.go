@@ -0,0 +1,62 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestNamedContent(t *testing.T) {
+	src, err := New(WithNamedData().Seed(42).Apply(), WithSize(3<<20), WithRandomSize(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		obj := src.Object()
+		got, err := io.ReadAll(obj.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(got)) != obj.Size {
+			t.Fatalf("got %d bytes, want %d", len(got), obj.Size)
+		}
+		want, err := io.ReadAll(NamedContent(42, obj.Name, obj.Size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("%s: content does not match", obj.Name)
+		}
+		other, _ := io.ReadAll(NamedContent(43, obj.Name, obj.Size))
+		if obj.Size > 8 && bytes.Equal(got, other) {
+			t.Fatalf("%s: content does not depend on seed", obj.Name)
+		}
+
+		rd := NamedContent(42, obj.Name, obj.Size)
+		off := obj.Size / 3
+		if _, err := rd.Seek(off, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		tail, _ := io.ReadAll(rd)
+		if !bytes.Equal(tail, want[off:]) {
+			t.Fatalf("%s: content after seek does not match", obj.Name)
+		}
+	}
+}
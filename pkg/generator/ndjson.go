@@ -0,0 +1,255 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+)
+
+// Distributions of the length of NDJSON string values.
+const (
+	// NDJSONLengthUniform picks lengths with equal probability.
+	NDJSONLengthUniform = "uniform"
+	// NDJSONLengthExp makes short values more common than long values.
+	NDJSONLengthExp = "exp"
+	// NDJSONLengthNormal makes lengths close to the middle of the range most common.
+	NDJSONLengthNormal = "normal"
+)
+
+// ndjsonMaxLength is the maximum length of string values.
+const ndjsonMaxLength = 1 << 20
+
+// WithNDJSONData returns default NDJSON Opts.
+func WithNDJSONData() NDJSONOpts {
+	return ndjsonOptsDefaults()
+}
+
+// Apply NDJSON data options.
+func (o NDJSONOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		opts.ndjson = o
+		opts.src = newNDJSON
+		return nil
+	}
+}
+
+func (o NDJSONOpts) validate() error {
+	if o.records <= 0 {
+		return errors.New("ndjson: records <= 0")
+	}
+	if o.fields <= 0 {
+		return errors.New("ndjson: fields <= 0")
+	}
+	if o.minLen < 0 || o.maxLen < o.minLen {
+		return fmt.Errorf("ndjson: invalid length range %d-%d", o.minLen, o.maxLen)
+	}
+	if o.maxLen > ndjsonMaxLength {
+		return fmt.Errorf("ndjson: length %d > %d", o.maxLen, ndjsonMaxLength)
+	}
+	switch o.lengthDist {
+	case NDJSONLengthUniform, NDJSONLengthExp, NDJSONLengthNormal:
+	default:
+		return fmt.Errorf("ndjson: unknown length distribution %q", o.lengthDist)
+	}
+	return nil
+}
+
+// Records sets the number of records in each object.
+func (o NDJSONOpts) Records(n int) NDJSONOpts {
+	o.records = n
+	return o
+}
+
+// Fields sets the number of fields in each record.
+func (o NDJSONOpts) Fields(n int) NDJSONOpts {
+	o.fields = n
+	return o
+}
+
+// FieldLength sets the range of the length of string values.
+func (o NDJSONOpts) FieldLength(minLen, maxLen int) NDJSONOpts {
+	o.minLen = minLen
+	o.maxLen = maxLen
+	return o
+}
+
+// FieldLengthDist sets the distribution of the length of string values.
+func (o NDJSONOpts) FieldLengthDist(dist string) NDJSONOpts {
+	o.lengthDist = dist
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make usage predictable.
+func (o NDJSONOpts) RngSeed(s int64) NDJSONOpts {
+	o.seed = &s
+	return o
+}
+
+// NDJSONOpts provides options for NDJSON generation.
+type NDJSONOpts struct {
+	seed       *int64
+	records    int
+	fields     int
+	minLen     int
+	maxLen     int
+	lengthDist string
+}
+
+func ndjsonOptsDefaults() NDJSONOpts {
+	return NDJSONOpts{
+		seed:       nil,
+		records:    1000,
+		fields:     10,
+		minLen:     4,
+		maxLen:     32,
+		lengthDist: NDJSONLengthUniform,
+	}
+}
+
+type ndjsonSource struct {
+	counter uint64
+	o       Options
+	buf     *circularBuffer
+	rng     *rand.Rand
+	obj     Object
+
+	// chars contains random characters string values are copied from.
+	chars []byte
+}
+
+func newNDJSON(o Options) (Source, error) {
+	rndSrc := rand.NewSource(int64(rand.Uint64()))
+	if o.ndjson.seed != nil {
+		rndSrc = rand.NewSource(*o.ndjson.seed)
+	}
+	n := ndjsonSource{
+		o:   o,
+		rng: rand.New(rndSrc),
+		buf: newCircularBuffer(make([]byte, 0, o.totalSize), o.totalSize),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "application/x-ndjson",
+			Size:        0,
+		},
+	}
+	const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 "
+	n.chars = make([]byte, 2*o.ndjson.maxLen+4096)
+	for i := range n.chars {
+		n.chars[i] = alphabet[n.rng.Intn(len(alphabet))]
+	}
+	n.obj.setPrefix(o)
+	return &n, nil
+}
+
+// Object returns an object with a JSON record on each line.
+func (n *ndjsonSource) Object() *Object {
+	atomic.AddUint64(&n.counter, 1)
+	opts := n.o.ndjson
+	dst := n.buf.data[:0]
+	for i := 0; i < opts.records; i++ {
+		dst = n.appendRecord(dst)
+		dst = append(dst, '\n')
+	}
+	n.buf.data = dst
+	n.obj.Size = int64(len(dst))
+
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], n.rng)
+	n.obj.setName(fmt.Sprintf("%d.%s.ndjson", atomic.LoadUint64(&n.counter), string(nBuf[:])))
+
+	n.obj.Reader = n.buf.Reset(n.obj.Size)
+	return &n.obj
+}
+
+// appendRecord appends a record with the configured number of fields.
+// Fields are strings, integers, floats and booleans in turn.
+func (n *ndjsonSource) appendRecord(dst []byte) []byte {
+	rng := n.rng
+	dst = append(dst, '{')
+	for i := 0; i < n.o.ndjson.fields; i++ {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, `"field`...)
+		dst = strconv.AppendInt(dst, int64(i), 10)
+		dst = append(dst, `":`...)
+		switch i % 4 {
+		case 0:
+			l := n.length()
+			off := rng.Intn(len(n.chars) - l + 1)
+			dst = append(dst, '"')
+			dst = append(dst, n.chars[off:off+l]...)
+			dst = append(dst, '"')
+		case 1:
+			dst = strconv.AppendInt(dst, rng.Int63n(1e9), 10)
+		case 2:
+			dst = strconv.AppendFloat(dst, rng.Float64()*1000, 'f', 3, 64)
+		case 3:
+			dst = strconv.AppendBool(dst, rng.Intn(2) == 1)
+		}
+	}
+	return append(dst, '}')
+}
+
+// length returns the length of a string value.
+func (n *ndjsonSource) length() int {
+	opts := n.o.ndjson
+	return randLength(n.rng, opts.lengthDist, opts.minLen, opts.maxLen)
+}
+
+// randLength returns a length in the range picked with the NDJSONLength distribution.
+func randLength(rng *rand.Rand, dist string, minLen, maxLen int) int {
+	span := maxLen - minLen
+	if span == 0 {
+		return minLen
+	}
+	var l int
+	switch dist {
+	case NDJSONLengthExp:
+		// The average is a quarter into the range.
+		l = minLen + int(rng.ExpFloat64()*float64(span)/4)
+	case NDJSONLengthNormal:
+		l = minLen + span/2 + int(rng.NormFloat64()*float64(span)/6)
+	default:
+		l = minLen + rng.Intn(span+1)
+	}
+	if l < minLen {
+		return minLen
+	}
+	if l > maxLen {
+		return maxLen
+	}
+	return l
+}
+
+func (n *ndjsonSource) String() string {
+	opts := n.o.ndjson
+	return fmt.Sprintf("NDJSON data; %d records, %d fields, %s string length %d-%d", opts.records, opts.fields, opts.lengthDist, opts.minLen, opts.maxLen)
+}
+
+func (n *ndjsonSource) Prefix() string {
+	return n.obj.Prefix
+}
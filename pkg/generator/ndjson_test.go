@@ -0,0 +1,78 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestWithNDJSONData(t *testing.T) {
+	for _, dist := range []string{NDJSONLengthUniform, NDJSONLengthExp, NDJSONLengthNormal} {
+		opts := WithNDJSONData().Records(200).Fields(6).FieldLength(5, 50).FieldLengthDist(dist)
+		src, err := New(opts.RngSeed(1).Apply())
+		if err != nil {
+			t.Fatal(err)
+		}
+		obj := src.Object()
+		if obj.ContentType != "application/x-ndjson" {
+			t.Errorf("content type %q", obj.ContentType)
+		}
+		b, err := io.ReadAll(obj.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(b)) != obj.Size {
+			t.Fatalf("got size %d, want %d", len(b), obj.Size)
+		}
+		lines := bytes.Split(bytes.TrimSuffix(b, []byte("\n")), []byte("\n"))
+		if len(lines) != 200 {
+			t.Fatalf("%s: got %d records, want 200", dist, len(lines))
+		}
+		var total int
+		for _, line := range lines {
+			var rec map[string]interface{}
+			if err := json.Unmarshal(line, &rec); err != nil {
+				t.Fatalf("%s: %v: %s", dist, err, line)
+			}
+			if len(rec) != 6 {
+				t.Fatalf("%s: got %d fields, want 6", dist, len(rec))
+			}
+			s, ok := rec["field0"].(string)
+			if !ok || len(s) < 5 || len(s) > 50 {
+				t.Fatalf("%s: field0 %v", dist, rec["field0"])
+			}
+			total += len(s)
+		}
+		avg := float64(total) / float64(len(lines))
+		if dist == NDJSONLengthExp && avg > 27.5 {
+			t.Errorf("%s: average length %.1f, want short values", dist, avg)
+		}
+		if dist != NDJSONLengthExp && (avg < 22 || avg > 33) {
+			t.Errorf("%s: average length %.1f, want about 27.5", dist, avg)
+		}
+	}
+
+	for _, opts := range []NDJSONOpts{WithNDJSONData().Records(0), WithNDJSONData().FieldLength(10, 5), WithNDJSONData().FieldLengthDist("zipf")} {
+		if _, err := New(opts.Apply()); err == nil {
+			t.Errorf("%+v: want error", opts)
+		}
+	}
+}
@@ -19,6 +19,7 @@ package generator
 
 import (
 	"errors"
+	"math"
 	"math/rand"
 )
 
@@ -29,13 +30,44 @@ type Options struct {
 	totalSize    int64
 	randSize     bool
 	dist         []int64
+	zipf         zipfSize
+	logNormal    logNormalSize
 	customPrefix string
+	keyTemplate  *keyTemplate
+	partitions   *partitioner
+	prefixTree   *prefixTree
+	hotPrefixes  *hotPrefixes
+	metadata     *objectAttrs
+	tags         *objectAttrs
+	contentTypes *contentTypes
+	extensions   *extensions
 	csv          CsvOpts
 	random       RandomOpts
 	text         TextOpts
+	json         JSONOpts
+	parquet      ParquetOpts
+	orc          ORCOpts
+	dedup        DedupOpts
+	named        NamedOpts
+	corpus       CorpusOpts
+	prose        ProseOpts
+	image        ImageOpts
+	avro         AvroOpts
+	protobuf     ProtobufOpts
+	ndjson       NDJSONOpts
+	logs         LogOpts
+	genomics     GenomicsOpts
+	dicom        DICOMOpts
+	xml          XMLOpts
+	tar          TarOpts
+	pattern      PatternOpts
 	randomPrefix int
 	compRatio    int
 	compWindow   int64
+	compTarget   compTarget
+	stream       bool
+	encoding     string
+	cse          *ClientEncryption
 }
 
 // OptionApplier allows to abstract generator options.
@@ -49,6 +81,10 @@ func (o Options) getSize(rng *rand.Rand) int64 {
 		return GetExpRandSize(rng, o.totalSize)
 	} else if len(o.dist) > 0 {
 		return GetDistributionSize(rng, o.dist)
+	} else if o.zipf.alpha > 0 {
+		return GetZipfSize(rng, o.zipf.alpha, o.zipf.min, o.zipf.max)
+	} else if o.logNormal.mean > 0 {
+		return GetLogNormalSize(rng, o.logNormal.mu, o.logNormal.sigma, o.logNormal.min, o.logNormal.max)
 	}
 	return o.totalSize
 }
@@ -60,6 +96,10 @@ func defaultOptions() Options {
 		csv:          csvOptsDefaults(),
 		random:       randomOptsDefaults(),
 		text:         textOptsDefaults(),
+		json:         jsonOptsDefaults(),
+		parquet:      parquetOptsDefaults(),
+		dedup:        dedupOptsDefaults(),
+		named:        namedOptsDefaults(),
 		randomPrefix: 0,
 	}
 	return o
@@ -101,6 +141,61 @@ func WithSizeDistribution(dist []int64) Option {
 	}
 }
 
+// zipfSize contains the parameters of Zipf distributed sizes.
+type zipfSize struct {
+	alpha    float64
+	min, max int64
+}
+
+// WithZipfSize will pick sizes from a Zipf distribution in multiples of min up to max.
+// Alpha must be > 1. Higher values make large objects less likely.
+// The size of the generated data is set to max.
+func WithZipfSize(alpha float64, min, max int64) Option {
+	return func(o *Options) error {
+		if alpha <= 1 {
+			return errors.New("WithZipfSize: alpha must be > 1")
+		}
+		if min <= 0 || max < min {
+			return errors.New("WithZipfSize: sizes must be 0 < min <= max")
+		}
+		o.zipf = zipfSize{alpha: alpha, min: min, max: max}
+		o.totalSize = max
+		return nil
+	}
+}
+
+// logNormalSize contains the parameters of log-normal distributed sizes.
+type logNormalSize struct {
+	mean      int64
+	mu, sigma float64
+	min, max  int64
+}
+
+// WithLogNormalSize will pick sizes from a log-normal distribution with the mean and
+// standard deviation given in bytes. Sizes are clamped to min and max.
+// The size of the generated data is set to max.
+func WithLogNormalSize(mean, stddev, min, max int64) Option {
+	return func(o *Options) error {
+		if mean <= 0 || stddev < 0 {
+			return errors.New("WithLogNormalSize: mean must be > 0 and stddev >= 0")
+		}
+		if min <= 0 || max < min {
+			return errors.New("WithLogNormalSize: sizes must be 0 < min <= max")
+		}
+		m, v := float64(mean), float64(stddev)*float64(stddev)
+		sigma2 := math.Log1p(v / (m * m))
+		o.logNormal = logNormalSize{
+			mean:  mean,
+			mu:    math.Log(m) - sigma2/2,
+			sigma: math.Sqrt(sigma2),
+			min:   min,
+			max:   max,
+		}
+		o.totalSize = max
+		return nil
+	}
+}
+
 // WithCustomPrefix adds custom prefix under bucket where all warp content is created.
 func WithCustomPrefix(prefix string) Option {
 	return func(o *Options) error {
@@ -138,3 +233,23 @@ func WithCompressionWindow(compWindow int64) Option {
 		return nil
 	}
 }
+
+// validateNames checks that the key template, partitions and the prefix tree can be used together.
+func (o Options) validateNames() error {
+	if o.keyTemplate == nil {
+		return nil
+	}
+	if o.keyTemplate.hasPartition && o.partitions == nil {
+		return errors.New("key template: {partition} requires partitions")
+	}
+	if !o.keyTemplate.hasPartition && o.partitions != nil {
+		return errors.New("key template: must contain {partition} when partitions are used")
+	}
+	if o.keyTemplate.hasTree && o.prefixTree == nil {
+		return errors.New("key template: {tree} requires a prefix tree")
+	}
+	if !o.keyTemplate.hasTree && o.prefixTree != nil {
+		return errors.New("key template: must contain {tree} when a prefix tree is used")
+	}
+	return nil
+}
@@ -0,0 +1,589 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ORC column types, named as in Hive.
+const (
+	OrcBoolean   = "boolean"
+	OrcInt       = "int"
+	OrcBigint    = "bigint"
+	OrcFloat     = "float"
+	OrcDouble    = "double"
+	OrcString    = "string"
+	OrcTimestamp = "timestamp"
+)
+
+// ORC compression codecs.
+const (
+	OrcUncompressed = "none"
+	OrcZlib         = "zlib"
+	OrcSnappy       = "snappy"
+	OrcZstd         = "zstd"
+)
+
+// WithORCData returns default ORC Opts.
+func WithORCData() ORCOpts {
+	return orcOptsDefaults()
+}
+
+// Apply ORC data options.
+func (o ORCOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		opts.orc = o
+		opts.src = newORC
+		return nil
+	}
+}
+
+func (o ORCOpts) validate() error {
+	if o.columns <= 0 {
+		return errors.New("orc: columns <= 0")
+	}
+	if len(o.types) == 0 {
+		return errors.New("orc: no column types")
+	}
+	for _, t := range o.types {
+		if _, ok := orcTypes[t]; !ok {
+			return fmt.Errorf("orc: unknown column type %q", t)
+		}
+	}
+	if o.stripeSize <= 0 {
+		return errors.New("orc: stripe size <= 0")
+	}
+	if _, ok := orcCodecs[o.codec]; !ok {
+		return fmt.Errorf("orc: unknown codec %q", o.codec)
+	}
+	return nil
+}
+
+// Columns sets the number of columns.
+func (o ORCOpts) Columns(n int) ORCOpts {
+	o.columns = n
+	return o
+}
+
+// Types sets the column types.
+// If there are more columns than types, the types are repeated.
+func (o ORCOpts) Types(types ...string) ORCOpts {
+	o.types = types
+	return o
+}
+
+// StripeSize sets the approximate size of the uncompressed data of each stripe.
+func (o ORCOpts) StripeSize(n int64) ORCOpts {
+	o.stripeSize = n
+	return o
+}
+
+// Codec sets the compression codec of streams.
+func (o ORCOpts) Codec(codec string) ORCOpts {
+	o.codec = codec
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make usage predictable.
+func (o ORCOpts) RngSeed(s int64) ORCOpts {
+	o.seed = &s
+	return o
+}
+
+// ORCOpts provides options for ORC generation.
+type ORCOpts struct {
+	seed       *int64
+	columns    int
+	types      []string
+	stripeSize int64
+	codec      string
+}
+
+func orcOptsDefaults() ORCOpts {
+	return ORCOpts{
+		seed:       nil,
+		columns:    8,
+		types:      []string{OrcBigint, OrcString, OrcDouble, OrcBoolean},
+		stripeSize: 64 << 20,
+		codec:      OrcZlib,
+	}
+}
+
+// Type kinds and compression kinds of the ORC format.
+var (
+	orcTypes = map[string]uint64{
+		OrcBoolean:   0,
+		OrcInt:       3,
+		OrcBigint:    4,
+		OrcFloat:     5,
+		OrcDouble:    6,
+		OrcString:    7,
+		OrcTimestamp: 9,
+	}
+	orcCodecs = map[string]uint64{
+		OrcUncompressed: 0,
+		OrcZlib:         1,
+		OrcSnappy:       2,
+		OrcZstd:         5,
+	}
+)
+
+const (
+	orcMagic      = "ORC"
+	orcKindStruct = 12
+	orcStreamData = 1
+	orcStreamLen  = 2
+	orcStreamSec  = 5
+	orcEncDirect  = 0
+	orcWriterHive = 1
+	orcBlockSize  = 256 << 10
+	// orcTimestampBase is the start of 2015 in Unix seconds, which ORC timestamps are relative to.
+	orcTimestampBase = 1420070400
+	// orcTimestampStart is the start of 2023, the earliest generated timestamp.
+	orcTimestampStart = 1672531200
+)
+
+type orcSource struct {
+	counter uint64
+	o       Options
+	buf     *circularBuffer
+	rng     *rand.Rand
+	obj     Object
+
+	// rowBytes is the size of a row in the previous object.
+	rowBytes float64
+	// stripeRows is the number of rows in each stripe.
+	stripeRows int
+	stream     []byte
+	comp       []byte
+	ints       []int64
+	nanos      []int64
+	flate      *flate.Writer
+	zstd       *zstd.Encoder
+	stripes    []orcStripe
+}
+
+// orcStripe is a stripe written.
+type orcStripe struct {
+	offset       uint64
+	dataLength   uint64
+	footerLength uint64
+	rows         uint64
+}
+
+func newORC(o Options) (Source, error) {
+	rndSrc := rand.NewSource(int64(rand.Uint64()))
+	if o.orc.seed != nil {
+		rndSrc = rand.NewSource(*o.orc.seed)
+	}
+	r := orcSource{
+		o:   o,
+		rng: rand.New(rndSrc),
+		buf: newCircularBuffer(make([]byte, 0, o.totalSize), o.totalSize),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "application/vnd.apache.orc",
+			Size:        0,
+		},
+	}
+	switch o.orc.codec {
+	case OrcZlib:
+		r.flate, _ = flate.NewWriter(nil, flate.DefaultCompression)
+	case OrcZstd:
+		enc, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1), zstd.WithEncoderLevel(zstd.SpeedFastest))
+		if err != nil {
+			return nil, err
+		}
+		r.zstd = enc
+	}
+	r.stripeRows = int(float64(o.orc.stripeSize) / r.typeRowBytes())
+	if r.stripeRows < 1 {
+		r.stripeRows = 1
+	}
+	r.obj.setPrefix(o)
+	return &r, nil
+}
+
+// Object returns an ORC file with as many rows as fit the object size.
+// Unused space is left between the last stripe and the footer.
+// Objects smaller than a file without rows contain only zeros.
+func (r *orcSource) Object() *Object {
+	atomic.AddUint64(&r.counter, 1)
+	r.obj.Size = r.o.getSize(r.rng)
+	size := int(r.obj.Size)
+
+	dst := r.buf.data[:0]
+	rows := r.estimateRows(size)
+	best, built := -1, -1
+	for i := 0; ; i++ {
+		var total int
+		dst, total = r.build(dst[:0], rows)
+		built = rows
+		if rows > 0 {
+			r.rowBytes = float64(len(dst)) / float64(rows)
+		}
+		fits := total <= size
+		if fits && rows > best {
+			best = rows
+		}
+		// Stop when most of the object is filled or after a few attempts.
+		if (fits && total >= size-size/10) || (i >= 3 && best >= 0) || (!fits && rows == 0) {
+			break
+		}
+		next := int(float64(rows) * float64(size) / float64(total) * 0.98)
+		if !fits && next >= rows {
+			next = rows - 1
+		}
+		if fits && next <= rows {
+			break
+		}
+		rows = next
+	}
+	if best >= 0 && built != best {
+		dst, _ = r.build(dst[:0], best)
+	}
+	if best < 0 {
+		// Too small for a file.
+		dst = dst[:0]
+		for len(dst) < size {
+			dst = append(dst, 0)
+		}
+	} else {
+		// The content length excludes the padding.
+		tail := r.tail(best, len(dst))
+		for len(dst)+len(tail) < size {
+			dst = append(dst, 0)
+		}
+		dst = append(dst, tail...)
+	}
+	r.buf.data = dst
+
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], r.rng)
+	r.obj.setName(fmt.Sprintf("%d.%s.orc", atomic.LoadUint64(&r.counter), string(nBuf[:])))
+
+	r.obj.Reader = r.buf.Reset(r.obj.Size)
+	return &r.obj
+}
+
+// typeRowBytes returns the approximate uncompressed size of a row.
+func (r *orcSource) typeRowBytes() float64 {
+	var n float64
+	opts := r.o.orc
+	for i := 0; i < opts.columns; i++ {
+		switch opts.types[i%len(opts.types)] {
+		case OrcBoolean:
+			n += 1.0 / 8
+		case OrcInt, OrcFloat:
+			n += 4
+		case OrcBigint, OrcDouble:
+			n += 8
+		case OrcString:
+			n += 12
+		case OrcTimestamp:
+			n += 9
+		}
+	}
+	return n
+}
+
+// estimateRows returns the number of rows expected to fit in size bytes.
+func (r *orcSource) estimateRows(size int) int {
+	if r.rowBytes <= 0 {
+		r.rowBytes = r.typeRowBytes()
+	}
+	return int(float64(size) / r.rowBytes * 0.98)
+}
+
+// build appends the magic and stripes of a file with the number of rows to dst.
+// The returned total is the size of the file including the tail.
+func (r *orcSource) build(dst []byte, rows int) ([]byte, int) {
+	opts := r.o.orc
+	dst = append(dst, orcMagic...)
+	r.stripes = r.stripes[:0]
+	var streams protoWriter
+	for done := 0; done < rows; done += r.stripeRows {
+		n := rows - done
+		if n > r.stripeRows {
+			n = r.stripeRows
+		}
+		st := orcStripe{offset: uint64(len(dst)), rows: uint64(n)}
+		streams.reset()
+		addStream := func(kind, column int) {
+			start := len(dst)
+			dst = r.compress(dst, r.stream)
+			var s protoWriter
+			s.uint(1, uint64(kind))
+			s.uint(2, uint64(column))
+			s.uint(3, uint64(len(dst)-start))
+			streams.message(1, &s)
+		}
+		for c := 0; c < opts.columns; c++ {
+			col := c + 1
+			switch opts.types[c%len(opts.types)] {
+			case OrcBoolean:
+				r.stream = r.appendBooleans(r.stream[:0], n)
+				addStream(orcStreamData, col)
+			case OrcInt:
+				r.ints = r.ints[:0]
+				for i := 0; i < n; i++ {
+					r.ints = append(r.ints, int64(r.rng.Int31n(1000000)))
+				}
+				r.stream = orcAppendInts(r.stream[:0], r.ints, true)
+				addStream(orcStreamData, col)
+			case OrcBigint:
+				r.ints = r.ints[:0]
+				for i := 0; i < n; i++ {
+					r.ints = append(r.ints, r.rng.Int63n(1<<40))
+				}
+				r.stream = orcAppendInts(r.stream[:0], r.ints, true)
+				addStream(orcStreamData, col)
+			case OrcFloat:
+				r.stream = r.stream[:0]
+				for i := 0; i < n; i++ {
+					r.stream = appendUint32(r.stream, math.Float32bits(float32(r.rng.Intn(100000))/100))
+				}
+				addStream(orcStreamData, col)
+			case OrcDouble:
+				r.stream = r.stream[:0]
+				for i := 0; i < n; i++ {
+					r.stream = appendUint64(r.stream, math.Float64bits(r.rng.Float64()*1000))
+				}
+				addStream(orcStreamData, col)
+			case OrcString:
+				r.stream, r.ints = r.stream[:0], r.ints[:0]
+				for i := 0; i < n; i++ {
+					l := len(r.stream)
+					r.stream = append(r.stream, "value"...)
+					r.stream = strconv.AppendInt(r.stream, r.rng.Int63n(1<<30), 36)
+					r.ints = append(r.ints, int64(len(r.stream)-l))
+				}
+				addStream(orcStreamData, col)
+				r.stream = orcAppendInts(r.stream[:0], r.ints, false)
+				addStream(orcStreamLen, col)
+			case OrcTimestamp:
+				// Seconds and nanoseconds are written to separate streams.
+				r.ints = r.ints[:0]
+				for i := 0; i < n; i++ {
+					r.ints = append(r.ints, r.rng.Int63n(365*24*3600*1000))
+				}
+				r.nanos = r.nanos[:0]
+				for i, ms := range r.ints {
+					r.ints[i] = orcTimestampStart - orcTimestampBase + ms/1000
+					r.nanos = append(r.nanos, orcNanos(ms%1000*1e6))
+				}
+				r.stream = orcAppendInts(r.stream[:0], r.ints, true)
+				addStream(orcStreamData, col)
+				r.stream = orcAppendInts(r.stream[:0], r.nanos, false)
+				addStream(orcStreamSec, col)
+			}
+		}
+		st.dataLength = uint64(len(dst)) - st.offset
+
+		// The stripe footer has the streams and a direct encoding of each column.
+		footer := streams
+		for c := 0; c <= opts.columns; c++ {
+			var enc protoWriter
+			enc.uint(1, orcEncDirect)
+			footer.message(2, &enc)
+		}
+		footer.str(3, "UTC")
+		start := len(dst)
+		dst = r.compress(dst, footer.b)
+		st.footerLength = uint64(len(dst) - start)
+		r.stripes = append(r.stripes, st)
+	}
+	return dst, len(dst) + len(r.tail(rows, len(dst)))
+}
+
+// appendBooleans appends n random booleans as a byte run length encoded bit stream to dst.
+func (r *orcSource) appendBooleans(dst []byte, n int) []byte {
+	size := (n + 7) / 8
+	for i := 0; i < size; i += 128 {
+		lits := size - i
+		if lits > 128 {
+			lits = 128
+		}
+		dst = append(dst, byte(-lits))
+		for j := 0; j < lits; j++ {
+			dst = append(dst, byte(r.rng.Uint32()))
+		}
+	}
+	if rem := n % 8; rem != 0 {
+		// Bits are written from the most significant bit.
+		dst[len(dst)-1] &^= byte(1<<(8-rem) - 1)
+	}
+	return dst
+}
+
+// orcAppendInts appends the values as literals of integer run length encoding version 1 to dst.
+// Signed values are zigzag encoded.
+func orcAppendInts(dst []byte, vals []int64, signed bool) []byte {
+	for len(vals) > 0 {
+		lits := len(vals)
+		if lits > 128 {
+			lits = 128
+		}
+		dst = append(dst, byte(-lits))
+		for _, v := range vals[:lits] {
+			u := uint64(v)
+			if signed {
+				u = uint64(v<<1) ^ uint64(v>>63)
+			}
+			dst = appendUvarint(dst, u)
+		}
+		vals = vals[lits:]
+	}
+	return dst
+}
+
+// orcNanos returns the nanoseconds of a timestamp encoded with the number of trailing zeros.
+func orcNanos(n int64) int64 {
+	if n == 0 || n%100 != 0 {
+		return n << 3
+	}
+	n /= 100
+	zeros := int64(1)
+	for n%10 == 0 && zeros < 7 {
+		n /= 10
+		zeros++
+	}
+	return n<<3 | zeros
+}
+
+// compress appends data compressed with the configured codec to dst.
+// Compressed data is split into blocks, which are stored uncompressed if compression does not reduce their size.
+func (r *orcSource) compress(dst, data []byte) []byte {
+	if r.o.orc.codec == OrcUncompressed {
+		return append(dst, data...)
+	}
+	for len(data) > 0 {
+		block := data
+		if len(block) > orcBlockSize {
+			block = block[:orcBlockSize]
+		}
+		data = data[len(block):]
+		switch r.o.orc.codec {
+		case OrcZlib:
+			buf := bytes.NewBuffer(r.comp[:0])
+			r.flate.Reset(buf)
+			r.flate.Write(block)
+			r.flate.Close()
+			r.comp = buf.Bytes()
+		case OrcSnappy:
+			r.comp = s2.EncodeSnappy(r.comp[:cap(r.comp)], block)
+		case OrcZstd:
+			r.comp = r.zstd.EncodeAll(block, r.comp[:0])
+		}
+		header, body := uint32(len(r.comp))<<1, r.comp
+		if len(r.comp) >= len(block) {
+			header, body = uint32(len(block))<<1|1, block
+		}
+		dst = append(dst, byte(header), byte(header>>8), byte(header>>16))
+		dst = append(dst, body...)
+	}
+	return dst
+}
+
+// tail returns the footer, postscript and postscript length of a file
+// with the stripes written for the number of rows and content of the length.
+func (r *orcSource) tail(rows, contentLength int) []byte {
+	opts := r.o.orc
+	var w protoWriter
+	w.uint(1, uint64(len(orcMagic)))
+	w.uint(2, uint64(contentLength))
+	for _, st := range r.stripes {
+		var s protoWriter
+		s.uint(1, st.offset)
+		s.uint(2, 0)
+		s.uint(3, st.dataLength)
+		s.uint(4, st.footerLength)
+		s.uint(5, st.rows)
+		w.message(3, &s)
+	}
+
+	// A struct with a field for each column is the root.
+	var root protoWriter
+	root.uint(1, orcKindStruct)
+	subtypes := make([]uint64, opts.columns)
+	for c := range subtypes {
+		subtypes[c] = uint64(c + 1)
+	}
+	root.packed(2, subtypes...)
+	for c := 0; c < opts.columns; c++ {
+		root.str(3, orcColumnName(c))
+	}
+	w.message(4, &root)
+	for c := 0; c < opts.columns; c++ {
+		var t protoWriter
+		t.uint(1, orcTypes[opts.types[c%len(opts.types)]])
+		w.message(4, &t)
+	}
+	w.uint(6, uint64(rows))
+	for c := 0; c <= opts.columns; c++ {
+		var stats protoWriter
+		stats.uint(1, uint64(rows))
+		w.message(7, &stats)
+	}
+	w.uint(8, 0)
+
+	dst := r.compress(nil, w.b)
+	var ps protoWriter
+	ps.uint(1, uint64(len(dst)))
+	ps.uint(2, orcCodecs[opts.codec])
+	ps.uint(3, orcBlockSize)
+	ps.packed(4, 0, 12)
+	ps.uint(5, 0)
+	ps.uint(6, orcWriterHive)
+	ps.str(8000, orcMagic)
+	dst = append(dst, ps.b...)
+	return append(dst, byte(len(ps.b)))
+}
+
+func orcColumnName(c int) string {
+	return "column" + strconv.Itoa(c)
+}
+
+func (r *orcSource) String() string {
+	opts := r.o.orc
+	desc := fmt.Sprintf("ORC data; %d columns (%s), %d rows per stripe, %s compressed", opts.columns, strings.Join(opts.types, ", "), r.stripeRows, opts.codec)
+	if r.o.randSize {
+		return fmt.Sprintf("%s, random size up to %d bytes", desc, r.o.totalSize)
+	}
+	return fmt.Sprintf("%s, %d bytes total", desc, r.o.totalSize)
+}
+
+func (r *orcSource) Prefix() string {
+	return r.obj.Prefix
+}
@@ -0,0 +1,291 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestWithORCData(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		// Smaller objects are not valid files.
+		valid bool
+	}{
+		{name: "tiny", opts: []Option{WithSize(10), WithORCData().Apply()}},
+		{name: "norows", opts: []Option{WithSize(200), WithORCData().Columns(2).Apply()}, valid: true},
+		{name: "default", opts: []Option{WithSize(1 << 16), WithORCData().Apply()}, valid: true},
+		{name: "types", opts: []Option{WithSize(1 << 16), WithORCData().Columns(15).Types(OrcBoolean, OrcInt, OrcBigint, OrcFloat, OrcDouble, OrcString, OrcTimestamp).Apply()}, valid: true},
+		{name: "stripes", opts: []Option{WithSize(1 << 17), WithORCData().StripeSize(4 << 10).Apply()}, valid: true},
+		{name: "uncompressed", opts: []Option{WithSize(1 << 16), WithORCData().Codec(OrcUncompressed).Apply()}, valid: true},
+		{name: "snappy", opts: []Option{WithSize(1 << 16), WithORCData().Codec(OrcSnappy).Apply()}, valid: true},
+		{name: "zstd", opts: []Option{WithSize(1 << 16), WithORCData().Codec(OrcZstd).Apply()}, valid: true},
+		{name: "blocks", opts: []Option{WithSize(1 << 20), WithORCData().Columns(1).Types(OrcString).Apply()}, valid: true},
+		{name: "randsize", opts: []Option{WithRandomSize(true), WithSize(1 << 16), WithORCData().Apply()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := New(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := src.(*orcSource).o.orc
+			for i := 0; i < 10; i++ {
+				obj := src.Object()
+				if obj.ContentType != "application/vnd.apache.orc" {
+					t.Errorf("content type %q", obj.ContentType)
+				}
+				b, err := ioutil.ReadAll(obj.Reader)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if int64(len(b)) != obj.Size {
+					t.Fatalf("got size %d, want %d", len(b), obj.Size)
+				}
+				if !bytes.HasPrefix(b, []byte(orcMagic)) {
+					if tt.valid {
+						t.Fatal("not an ORC file")
+					}
+					continue
+				}
+				rows, err := checkORC(b, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if obj.Size >= 1<<16 && rows == 0 {
+					t.Fatal("no rows")
+				}
+			}
+		})
+	}
+}
+
+func TestWithORCDataValidate(t *testing.T) {
+	for _, o := range []ORCOpts{
+		WithORCData().Columns(0),
+		WithORCData().Types(),
+		WithORCData().Types("decimal"),
+		WithORCData().StripeSize(0),
+		WithORCData().Codec("lzo"),
+	} {
+		if _, err := New(o.Apply()); err == nil {
+			t.Errorf("%+v: no error", o)
+		}
+	}
+}
+
+func TestORCNanos(t *testing.T) {
+	for _, n := range []int64{0, 1, 100, 123000000, 999999999, 500000000} {
+		v := orcNanos(n)
+		got := v >> 3
+		if zeros := v & 7; zeros != 0 {
+			for i := int64(0); i <= zeros; i++ {
+				got *= 10
+			}
+		}
+		if got != n {
+			t.Errorf("nanos %d decoded as %d", n, got)
+		}
+	}
+}
+
+// checkORC reads the tail and streams of a file and returns the number of rows.
+func checkORC(b []byte, opts ORCOpts) (uint64, error) {
+	psLen := int(b[len(b)-1])
+	if psLen+1 > len(b)-len(orcMagic) {
+		return 0, errors.New("postscript too long")
+	}
+	ps, err := protoFields(b[len(b)-1-psLen : len(b)-1])
+	if err != nil {
+		return 0, err
+	}
+	if string(ps[8000][0].([]byte)) != orcMagic || ps[2][0].(uint64) != orcCodecs[opts.codec] {
+		return 0, errors.New("invalid postscript")
+	}
+	footerLen := int(ps[1][0].(uint64))
+	footerData, err := decompressORC(opts.codec, b[len(b)-1-psLen-footerLen:len(b)-1-psLen])
+	if err != nil {
+		return 0, err
+	}
+	footer, err := protoFields(footerData)
+	if err != nil {
+		return 0, err
+	}
+	types := footer[4]
+	if len(types) != opts.columns+1 {
+		return 0, errors.New("invalid type count")
+	}
+	root, err := protoFields(types[0].([]byte))
+	if err != nil || root[1][0].(uint64) != orcKindStruct || len(root[3]) != opts.columns {
+		return 0, errors.New("invalid root type")
+	}
+	rows := footer[6][0].(uint64)
+	var total uint64
+	for _, s := range footer[3] {
+		st, err := protoFields(s.([]byte))
+		if err != nil {
+			return 0, err
+		}
+		offset, dataLen, stripeRows := st[1][0].(uint64), st[3][0].(uint64), st[5][0].(uint64)
+		total += stripeRows
+		sfData, err := decompressORC(opts.codec, b[offset+dataLen:offset+dataLen+st[4][0].(uint64)])
+		if err != nil {
+			return 0, err
+		}
+		sf, err := protoFields(sfData)
+		if err != nil {
+			return 0, err
+		}
+		if len(sf[2]) != opts.columns+1 {
+			return 0, errors.New("invalid encoding count")
+		}
+		pos := offset
+		for _, s := range sf[1] {
+			stream, err := protoFields(s.([]byte))
+			if err != nil {
+				return 0, err
+			}
+			kind, col, length := stream[1][0].(uint64), int(stream[2][0].(uint64)), stream[3][0].(uint64)
+			data, err := decompressORC(opts.codec, b[pos:pos+length])
+			if err != nil {
+				return 0, err
+			}
+			pos += length
+			typ := opts.types[(col-1)%len(opts.types)]
+			if kind == orcStreamData && typ == OrcString {
+				// The lengths are checked instead.
+				continue
+			}
+			want := stripeRows
+			if typ == OrcBoolean {
+				// Booleans are counted in bytes of 8 values.
+				want = (stripeRows + 7) / 8
+			}
+			if got := countORC(data, typ); got != want {
+				return 0, fmt.Errorf("column %d (%s) stream %d has %d values, want %d", col, typ, kind, got, want)
+			}
+		}
+		if pos != offset+dataLen {
+			return 0, errors.New("stream length mismatch")
+		}
+	}
+	if total != rows {
+		return 0, errors.New("row count mismatch")
+	}
+	return rows, nil
+}
+
+// decompressORC returns the content of a compressed stream.
+func decompressORC(codec string, b []byte) ([]byte, error) {
+	if codec == OrcUncompressed {
+		return b, nil
+	}
+	var res []byte
+	for len(b) > 0 {
+		if len(b) < 3 {
+			return nil, errors.New("short block header")
+		}
+		header := int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+		n := header >> 1
+		if n > len(b)-3 || n > orcBlockSize {
+			return nil, errors.New("invalid block length")
+		}
+		block := b[3 : 3+n]
+		b = b[3+n:]
+		if header&1 == 1 {
+			res = append(res, block...)
+			continue
+		}
+		var dec []byte
+		var err error
+		switch codec {
+		case OrcZlib:
+			dec, err = ioutil.ReadAll(flate.NewReader(bytes.NewReader(block)))
+		case OrcSnappy:
+			dec, err = s2.Decode(nil, block)
+		case OrcZstd:
+			var d *zstd.Decoder
+			d, err = zstd.NewReader(nil)
+			if err == nil {
+				dec, err = d.DecodeAll(block, nil)
+				d.Close()
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(dec) > orcBlockSize {
+			return nil, errors.New("block too large")
+		}
+		res = append(res, dec...)
+	}
+	return res, nil
+}
+
+// countORC returns the number of values in a stream of the type, or 0 if it has unexpected content.
+// Booleans are counted in bytes.
+func countORC(data []byte, typ string) uint64 {
+	switch typ {
+	case OrcFloat:
+		if len(data)%4 != 0 {
+			return 0
+		}
+		return uint64(len(data) / 4)
+	case OrcDouble:
+		if len(data)%8 != 0 {
+			return 0
+		}
+		return uint64(len(data) / 8)
+	}
+	// Run length encoded literals.
+	var n uint64
+	for len(data) > 0 {
+		lits := -int(int8(data[0]))
+		if lits <= 0 {
+			return 0
+		}
+		data = data[1:]
+		for i := 0; i < lits; i++ {
+			if typ == OrcBoolean {
+				if len(data) == 0 {
+					return 0
+				}
+				data = data[1:]
+				n++
+				continue
+			}
+			_, l := binary.Uvarint(data)
+			if l <= 0 {
+				return 0
+			}
+			data = data[l:]
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,588 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Parquet column types.
+const (
+	ParquetBoolean = "boolean"
+	ParquetInt32   = "int32"
+	ParquetInt64   = "int64"
+	ParquetFloat   = "float"
+	ParquetDouble  = "double"
+	ParquetString  = "string"
+)
+
+// Parquet compression codecs.
+const (
+	ParquetUncompressed = "none"
+	ParquetSnappy       = "snappy"
+	ParquetGzip         = "gzip"
+	ParquetZstd         = "zstd"
+)
+
+// WithParquetData returns default Parquet Opts.
+func WithParquetData() ParquetOpts {
+	return parquetOptsDefaults()
+}
+
+// Apply Parquet data options.
+func (o ParquetOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		opts.parquet = o
+		opts.src = newParquet
+		return nil
+	}
+}
+
+func (o ParquetOpts) validate() error {
+	if o.columns <= 0 {
+		return errors.New("parquet: columns <= 0")
+	}
+	if len(o.types) == 0 {
+		return errors.New("parquet: no column types")
+	}
+	for _, t := range o.types {
+		if _, ok := parquetTypes[t]; !ok {
+			return fmt.Errorf("parquet: unknown column type %q", t)
+		}
+	}
+	if o.rowGroupRows <= 0 {
+		return errors.New("parquet: row group rows <= 0")
+	}
+	if _, ok := parquetCodecs[o.codec]; !ok {
+		return fmt.Errorf("parquet: unknown codec %q", o.codec)
+	}
+	return nil
+}
+
+// Columns sets the number of columns.
+func (o ParquetOpts) Columns(n int) ParquetOpts {
+	o.columns = n
+	return o
+}
+
+// Types sets the column types.
+// If there are more columns than types, the types are repeated.
+func (o ParquetOpts) Types(types ...string) ParquetOpts {
+	o.types = types
+	return o
+}
+
+// RowGroupRows sets the maximum number of rows in each row group.
+func (o ParquetOpts) RowGroupRows(n int) ParquetOpts {
+	o.rowGroupRows = n
+	return o
+}
+
+// Codec sets the compression codec of pages.
+func (o ParquetOpts) Codec(codec string) ParquetOpts {
+	o.codec = codec
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make usage predictable.
+func (o ParquetOpts) RngSeed(s int64) ParquetOpts {
+	o.seed = &s
+	return o
+}
+
+// ParquetOpts provides options for Parquet generation.
+type ParquetOpts struct {
+	seed         *int64
+	columns      int
+	types        []string
+	rowGroupRows int
+	codec        string
+}
+
+func parquetOptsDefaults() ParquetOpts {
+	return ParquetOpts{
+		seed:         nil,
+		columns:      8,
+		types:        []string{ParquetInt64, ParquetString, ParquetDouble, ParquetBoolean},
+		rowGroupRows: 10000,
+		codec:        ParquetSnappy,
+	}
+}
+
+// Physical types, converted types, codecs and encodings of the Parquet format.
+var (
+	parquetTypes = map[string]int32{
+		ParquetBoolean: 0,
+		ParquetInt32:   1,
+		ParquetInt64:   2,
+		ParquetFloat:   4,
+		ParquetDouble:  5,
+		ParquetString:  6,
+	}
+	parquetCodecs = map[string]int32{
+		ParquetUncompressed: 0,
+		ParquetSnappy:       1,
+		ParquetGzip:         2,
+		ParquetZstd:         6,
+	}
+)
+
+const (
+	parquetMagic          = "PAR1"
+	parquetConvertedUTF8  = 0
+	parquetEncodingPlain  = 0
+	parquetEncodingRLE    = 3
+	parquetPageData       = 0
+	parquetRepRequired    = 0
+	parquetFooterOverhead = 8
+)
+
+type parquetSource struct {
+	counter uint64
+	o       Options
+	buf     *circularBuffer
+	rng     *rand.Rand
+	obj     Object
+
+	// rowBytes is the size of a row in the previous object.
+	rowBytes float64
+	page     []byte
+	comp     []byte
+	gz       *gzip.Writer
+	zstd     *zstd.Encoder
+	chunks   []parquetChunk
+}
+
+// parquetChunk is a column chunk written.
+type parquetChunk struct {
+	offset       int64
+	uncompressed int64
+	compressed   int64
+	rows         int64
+}
+
+func newParquet(o Options) (Source, error) {
+	rndSrc := rand.NewSource(int64(rand.Uint64()))
+	if o.parquet.seed != nil {
+		rndSrc = rand.NewSource(*o.parquet.seed)
+	}
+	p := parquetSource{
+		o:   o,
+		rng: rand.New(rndSrc),
+		buf: newCircularBuffer(make([]byte, 0, o.totalSize), o.totalSize),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "application/vnd.apache.parquet",
+			Size:        0,
+		},
+	}
+	switch o.parquet.codec {
+	case ParquetGzip:
+		p.gz = gzip.NewWriter(nil)
+	case ParquetZstd:
+		enc, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1), zstd.WithEncoderLevel(zstd.SpeedFastest))
+		if err != nil {
+			return nil, err
+		}
+		p.zstd = enc
+	}
+	p.obj.setPrefix(o)
+	return &p, nil
+}
+
+// Object returns a Parquet file with as many rows as fit the object size.
+// Unused space is left between the last column chunk and the footer.
+// Objects smaller than a file without rows contain only zeros.
+func (p *parquetSource) Object() *Object {
+	atomic.AddUint64(&p.counter, 1)
+	p.obj.Size = p.o.getSize(p.rng)
+	size := int(p.obj.Size)
+
+	dst := p.buf.data[:0]
+	rows := p.estimateRows(size)
+	best, built := -1, -1
+	for i := 0; ; i++ {
+		var total int
+		dst, total = p.build(dst[:0], rows)
+		built = rows
+		if rows > 0 {
+			p.rowBytes = float64(len(dst)) / float64(rows)
+		}
+		fits := total <= size
+		if fits && rows > best {
+			best = rows
+		}
+		// Stop when most of the object is filled or after a few attempts.
+		if (fits && total >= size-size/10) || (i >= 3 && best >= 0) || (!fits && rows == 0) {
+			break
+		}
+		next := int(float64(rows) * float64(size) / float64(total) * 0.98)
+		if !fits && next >= rows {
+			next = rows - 1
+		}
+		if fits && next <= rows {
+			break
+		}
+		rows = next
+	}
+	if best >= 0 && built != best {
+		dst, _ = p.build(dst[:0], best)
+	}
+	if best < 0 {
+		// Too small for a file.
+		dst = dst[:0]
+		for len(dst) < size {
+			dst = append(dst, 0)
+		}
+	} else {
+		footer := p.footer(best)
+		for len(dst)+len(footer)+parquetFooterOverhead < size {
+			dst = append(dst, 0)
+		}
+		dst = append(dst, footer...)
+		dst = appendUint32(dst, uint32(len(footer)))
+		dst = append(dst, parquetMagic...)
+	}
+	p.buf.data = dst
+
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], p.rng)
+	p.obj.setName(fmt.Sprintf("%d.%s.parquet", atomic.LoadUint64(&p.counter), string(nBuf[:])))
+
+	p.obj.Reader = p.buf.Reset(p.obj.Size)
+	return &p.obj
+}
+
+// estimateRows returns the number of rows expected to fit in size bytes.
+func (p *parquetSource) estimateRows(size int) int {
+	if p.rowBytes <= 0 {
+		opts := p.o.parquet
+		for i := 0; i < opts.columns; i++ {
+			switch opts.types[i%len(opts.types)] {
+			case ParquetBoolean:
+				p.rowBytes += 1.0 / 8
+			case ParquetInt32, ParquetFloat:
+				p.rowBytes += 4
+			case ParquetInt64, ParquetDouble:
+				p.rowBytes += 8
+			case ParquetString:
+				p.rowBytes += 14
+			}
+		}
+	}
+	return int(float64(size) / p.rowBytes * 0.98)
+}
+
+// build appends the magic and column chunks of a file with the number of rows to dst.
+// The returned total is the size of the file including the footer.
+func (p *parquetSource) build(dst []byte, rows int) ([]byte, int) {
+	opts := p.o.parquet
+	dst = append(dst, parquetMagic...)
+	p.chunks = p.chunks[:0]
+	for done := 0; done < rows; done += opts.rowGroupRows {
+		n := rows - done
+		if n > opts.rowGroupRows {
+			n = opts.rowGroupRows
+		}
+		for c := 0; c < opts.columns; c++ {
+			p.page = p.appendValues(p.page[:0], opts.types[c%len(opts.types)], n)
+			data := p.compress(p.page)
+
+			var w thriftWriter
+			w.i32(1, parquetPageData)
+			w.i32(2, int32(len(p.page)))
+			w.i32(3, int32(len(data)))
+			w.structBegin(5)
+			w.i32(1, int32(n))
+			w.i32(2, parquetEncodingPlain)
+			w.i32(3, parquetEncodingRLE)
+			w.i32(4, parquetEncodingRLE)
+			w.structEnd()
+			w.stop()
+
+			p.chunks = append(p.chunks, parquetChunk{
+				offset:       int64(len(dst)),
+				uncompressed: int64(len(w.b) + len(p.page)),
+				compressed:   int64(len(w.b) + len(data)),
+				rows:         int64(n),
+			})
+			dst = append(dst, w.b...)
+			dst = append(dst, data...)
+		}
+	}
+	return dst, len(dst) + len(p.footer(rows)) + parquetFooterOverhead
+}
+
+// appendValues appends n random PLAIN encoded values of the type to dst.
+func (p *parquetSource) appendValues(dst []byte, typ string, n int) []byte {
+	switch typ {
+	case ParquetBoolean:
+		for i := 0; i < n; i += 8 {
+			dst = append(dst, byte(p.rng.Uint32()))
+		}
+		if rem := n % 8; rem != 0 {
+			dst[len(dst)-1] &= byte(1<<rem - 1)
+		}
+	case ParquetInt32:
+		for i := 0; i < n; i++ {
+			dst = appendUint32(dst, uint32(p.rng.Int31n(1000000)))
+		}
+	case ParquetInt64:
+		for i := 0; i < n; i++ {
+			dst = appendUint64(dst, uint64(p.rng.Int63n(1<<40)))
+		}
+	case ParquetFloat:
+		for i := 0; i < n; i++ {
+			dst = appendUint32(dst, math.Float32bits(float32(p.rng.Intn(100000))/100))
+		}
+	case ParquetDouble:
+		for i := 0; i < n; i++ {
+			dst = appendUint64(dst, math.Float64bits(p.rng.Float64()*1000))
+		}
+	case ParquetString:
+		var tmp [20]byte
+		for i := 0; i < n; i++ {
+			v := append(tmp[:0], "value"...)
+			v = strconv.AppendInt(v, p.rng.Int63n(1<<30), 36)
+			dst = appendUint32(dst, uint32(len(v)))
+			dst = append(dst, v...)
+		}
+	}
+	return dst
+}
+
+// compress returns the page compressed with the configured codec.
+func (p *parquetSource) compress(page []byte) []byte {
+	switch p.o.parquet.codec {
+	case ParquetSnappy:
+		p.comp = s2.EncodeSnappy(p.comp[:cap(p.comp)], page)
+	case ParquetGzip:
+		buf := bytes.NewBuffer(p.comp[:0])
+		p.gz.Reset(buf)
+		p.gz.Write(page)
+		p.gz.Close()
+		p.comp = buf.Bytes()
+	case ParquetZstd:
+		p.comp = p.zstd.EncodeAll(page, p.comp[:0])
+	default:
+		return page
+	}
+	return p.comp
+}
+
+// footer returns the file metadata of the chunks written for the number of rows.
+func (p *parquetSource) footer(rows int) []byte {
+	opts := p.o.parquet
+	var w thriftWriter
+	w.i32(1, 1)
+
+	// Schema with a root and a required column for each column.
+	w.listBegin(2, thriftStruct, opts.columns+1)
+	w.elemBegin()
+	w.str(4, "schema")
+	w.i32(5, int32(opts.columns))
+	w.elemEnd()
+	for c := 0; c < opts.columns; c++ {
+		typ := opts.types[c%len(opts.types)]
+		w.elemBegin()
+		w.i32(1, parquetTypes[typ])
+		w.i32(3, parquetRepRequired)
+		w.str(4, parquetColumnName(c))
+		if typ == ParquetString {
+			w.i32(6, parquetConvertedUTF8)
+		}
+		w.elemEnd()
+	}
+	w.i64(3, int64(rows))
+
+	groups := len(p.chunks) / opts.columns
+	w.listBegin(4, thriftStruct, groups)
+	for g := 0; g < groups; g++ {
+		chunks := p.chunks[g*opts.columns : (g+1)*opts.columns]
+		var total int64
+		for _, ch := range chunks {
+			total += ch.uncompressed
+		}
+		w.elemBegin()
+		w.listBegin(1, thriftStruct, len(chunks))
+		for c, ch := range chunks {
+			w.elemBegin()
+			w.i64(2, ch.offset)
+			w.structBegin(3)
+			w.i32(1, parquetTypes[opts.types[c%len(opts.types)]])
+			w.listBegin(2, thriftI32, 1)
+			w.varint(parquetEncodingPlain)
+			w.listBegin(3, thriftBinary, 1)
+			w.binary([]byte(parquetColumnName(c)))
+			w.i32(4, parquetCodecs[opts.codec])
+			w.i64(5, ch.rows)
+			w.i64(6, ch.uncompressed)
+			w.i64(7, ch.compressed)
+			w.i64(9, ch.offset)
+			w.structEnd()
+			w.elemEnd()
+		}
+		w.i64(2, total)
+		w.i64(3, chunks[0].rows)
+		w.elemEnd()
+	}
+	w.str(6, "warp")
+	w.stop()
+	return w.b
+}
+
+func parquetColumnName(c int) string {
+	return "column" + strconv.Itoa(c)
+}
+
+func (p *parquetSource) String() string {
+	opts := p.o.parquet
+	desc := fmt.Sprintf("Parquet data; %d columns (%s), %d rows per row group, %s compressed", opts.columns, strings.Join(opts.types, ", "), opts.rowGroupRows, opts.codec)
+	if p.o.randSize {
+		return fmt.Sprintf("%s, random size up to %d bytes", desc, p.o.totalSize)
+	}
+	return fmt.Sprintf("%s, %d bytes total", desc, p.o.totalSize)
+}
+
+func (p *parquetSource) Prefix() string {
+	return p.obj.Prefix
+}
+
+func appendUint32(dst []byte, v uint32) []byte {
+	var tmp [4]byte
+	binary.LittleEndian.PutUint32(tmp[:], v)
+	return append(dst, tmp[:]...)
+}
+
+func appendUint64(dst []byte, v uint64) []byte {
+	var tmp [8]byte
+	binary.LittleEndian.PutUint64(tmp[:], v)
+	return append(dst, tmp[:]...)
+}
+
+func appendUvarint(dst []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(dst, tmp[:n]...)
+}
+
+// Thrift compact protocol types.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter writes structs in the Thrift compact protocol used by Parquet metadata.
+type thriftWriter struct {
+	b []byte
+	// Last field id of the current and enclosing structs.
+	last  int16
+	stack []int16
+}
+
+func (w *thriftWriter) field(id int16, typ byte) {
+	if delta := id - w.last; delta > 0 && delta <= 15 {
+		w.b = append(w.b, byte(delta)<<4|typ)
+	} else {
+		w.b = append(w.b, typ)
+		w.varint(int64(id))
+	}
+	w.last = id
+}
+
+// varint writes a zigzag encoded integer.
+func (w *thriftWriter) varint(v int64) {
+	w.b = appendUvarint(w.b, uint64(v<<1)^uint64(v>>63))
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.varint(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.varint(v)
+}
+
+func (w *thriftWriter) binary(v []byte) {
+	w.b = appendUvarint(w.b, uint64(len(v)))
+	w.b = append(w.b, v...)
+}
+
+func (w *thriftWriter) str(id int16, v string) {
+	w.field(id, thriftBinary)
+	w.binary([]byte(v))
+}
+
+// listBegin writes the header of a list with n elements of the type.
+func (w *thriftWriter) listBegin(id int16, typ byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.b = append(w.b, byte(n)<<4|typ)
+		return
+	}
+	w.b = append(w.b, 0xf0|typ)
+	w.b = appendUvarint(w.b, uint64(n))
+}
+
+// structBegin starts a struct field.
+func (w *thriftWriter) structBegin(id int16) {
+	w.field(id, thriftStruct)
+	w.elemBegin()
+}
+
+func (w *thriftWriter) structEnd() {
+	w.elemEnd()
+}
+
+// elemBegin starts a struct list element.
+func (w *thriftWriter) elemBegin() {
+	w.stack = append(w.stack, w.last)
+	w.last = 0
+}
+
+func (w *thriftWriter) elemEnd() {
+	w.stop()
+	w.last = w.stack[len(w.stack)-1]
+	w.stack = w.stack[:len(w.stack)-1]
+}
+
+// stop ends a struct.
+func (w *thriftWriter) stop() {
+	w.b = append(w.b, 0)
+}
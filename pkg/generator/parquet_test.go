@@ -0,0 +1,284 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestWithParquetData(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		// Smaller objects are not valid files.
+		valid bool
+	}{
+		{name: "tiny", opts: []Option{WithSize(10), WithParquetData().Apply()}},
+		{name: "norows", opts: []Option{WithSize(200), WithParquetData().Columns(2).Apply()}, valid: true},
+		{name: "default", opts: []Option{WithSize(1 << 16), WithParquetData().Apply()}, valid: true},
+		{name: "types", opts: []Option{WithSize(1 << 16), WithParquetData().Columns(13).Types(ParquetBoolean, ParquetInt32, ParquetInt64, ParquetFloat, ParquetDouble, ParquetString).Apply()}, valid: true},
+		{name: "rowgroups", opts: []Option{WithSize(1 << 17), WithParquetData().RowGroupRows(100).Apply()}, valid: true},
+		{name: "uncompressed", opts: []Option{WithSize(1 << 16), WithParquetData().Codec(ParquetUncompressed).Apply()}, valid: true},
+		{name: "gzip", opts: []Option{WithSize(1 << 16), WithParquetData().Codec(ParquetGzip).Apply()}, valid: true},
+		{name: "zstd", opts: []Option{WithSize(1 << 16), WithParquetData().Codec(ParquetZstd).Apply()}, valid: true},
+		{name: "randsize", opts: []Option{WithRandomSize(true), WithSize(1 << 16), WithParquetData().Apply()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := New(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := src.(*parquetSource).o.parquet
+			for i := 0; i < 10; i++ {
+				obj := src.Object()
+				if obj.ContentType != "application/vnd.apache.parquet" {
+					t.Errorf("content type %q", obj.ContentType)
+				}
+				b, err := ioutil.ReadAll(obj.Reader)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if int64(len(b)) != obj.Size {
+					t.Fatalf("got size %d, want %d", len(b), obj.Size)
+				}
+				if !bytes.HasSuffix(b, []byte(parquetMagic)) {
+					if tt.valid {
+						t.Fatal("not a parquet file")
+					}
+					continue
+				}
+				rows, err := checkParquet(b, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if obj.Size >= 1<<16 && rows == 0 {
+					t.Fatal("no rows")
+				}
+			}
+		})
+	}
+}
+
+// checkParquet reads the footer and pages of a file and returns the number of rows.
+func checkParquet(b []byte, opts ParquetOpts) (int64, error) {
+	if !bytes.HasPrefix(b, []byte(parquetMagic)) || len(b) < 12 {
+		return 0, errors.New("missing magic")
+	}
+	n := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	if n > len(b)-12 {
+		return 0, errors.New("footer too long")
+	}
+	r := thriftReader{b: b[len(b)-8-n : len(b)-8]}
+	meta := r.readStruct()
+	if r.err != nil || r.off != n {
+		return 0, errors.New("invalid footer")
+	}
+	schema := meta[2].([]interface{})
+	if len(schema) != opts.columns+1 || schema[0].(map[int16]interface{})[5].(int64) != int64(opts.columns) {
+		return 0, errors.New("invalid schema")
+	}
+	rows := meta[3].(int64)
+	var total int64
+	for _, rg := range meta[4].([]interface{}) {
+		rg := rg.(map[int16]interface{})
+		cols := rg[1].([]interface{})
+		if len(cols) != opts.columns {
+			return 0, errors.New("invalid column count")
+		}
+		groupRows := rg[3].(int64)
+		if groupRows > int64(opts.rowGroupRows) {
+			return 0, errors.New("row group too large")
+		}
+		total += groupRows
+		for c, col := range cols {
+			md := col.(map[int16]interface{})[3].(map[int16]interface{})
+			if md[5].(int64) != groupRows {
+				return 0, errors.New("invalid value count")
+			}
+			off := md[9].(int64)
+			r := thriftReader{b: b[off : off+md[7].(int64)]}
+			hdr := r.readStruct()
+			if r.err != nil {
+				return 0, r.err
+			}
+			page := r.b[r.off:]
+			if int64(len(page)) != hdr[3].(int64) {
+				return 0, errors.New("invalid page size")
+			}
+			page, err := decompressPage(opts.codec, page)
+			if err != nil {
+				return 0, err
+			}
+			if int64(len(page)) != hdr[2].(int64) {
+				return 0, errors.New("invalid uncompressed page size")
+			}
+			if got := countPlain(page, opts.types[c%len(opts.types)], int(groupRows)); got != groupRows {
+				return 0, errors.New("invalid page values")
+			}
+		}
+	}
+	if total != rows {
+		return 0, errors.New("row count mismatch")
+	}
+	return rows, nil
+}
+
+func decompressPage(codec string, page []byte) ([]byte, error) {
+	switch codec {
+	case ParquetSnappy:
+		return s2.Decode(nil, page)
+	case ParquetGzip:
+		r, err := gzip.NewReader(bytes.NewReader(page))
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(r)
+	case ParquetZstd:
+		dec, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer dec.Close()
+		return dec.DecodeAll(page, nil)
+	}
+	return page, nil
+}
+
+// countPlain returns the number of PLAIN encoded values in the page,
+// or -1 if the page has unexpected content.
+func countPlain(page []byte, typ string, rows int) int64 {
+	switch typ {
+	case ParquetBoolean:
+		if len(page) != (rows+7)/8 {
+			return -1
+		}
+		return int64(rows)
+	case ParquetInt32, ParquetFloat:
+		if len(page)%4 != 0 {
+			return -1
+		}
+		return int64(len(page) / 4)
+	case ParquetInt64, ParquetDouble:
+		if len(page)%8 != 0 {
+			return -1
+		}
+		return int64(len(page) / 8)
+	}
+	var n int64
+	for len(page) >= 4 {
+		l := int(binary.LittleEndian.Uint32(page))
+		if l > len(page)-4 {
+			return -1
+		}
+		page = page[4+l:]
+		n++
+	}
+	if len(page) != 0 {
+		return -1
+	}
+	return n
+}
+
+// thriftReader reads the Thrift compact protocol types written by thriftWriter.
+type thriftReader struct {
+	b   []byte
+	off int
+	err error
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b[r.off:])
+	if n <= 0 {
+		r.err = errors.New("invalid varint")
+		r.off = len(r.b)
+		return 0
+	}
+	r.off += n
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) byte() byte {
+	if r.off >= len(r.b) {
+		r.err = errors.New("unexpected end")
+		return 0
+	}
+	r.off++
+	return r.b[r.off-1]
+}
+
+func (r *thriftReader) readStruct() map[int16]interface{} {
+	res := make(map[int16]interface{})
+	var last int16
+	for r.err == nil {
+		h := r.byte()
+		if h == 0 {
+			break
+		}
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			id = int16(r.varint())
+		}
+		last = id
+		res[id] = r.readValue(h & 0xf)
+	}
+	return res
+}
+
+func (r *thriftReader) readValue(typ byte) interface{} {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		n := int(r.uvarint())
+		if n > len(r.b)-r.off {
+			r.err = errors.New("binary too long")
+			return nil
+		}
+		r.off += n
+		return string(r.b[r.off-n : r.off])
+	case thriftList:
+		h := r.byte()
+		n := int(h >> 4)
+		if n == 15 {
+			n = int(r.uvarint())
+		}
+		res := make([]interface{}, 0, n)
+		for i := 0; i < n && r.err == nil; i++ {
+			res = append(res, r.readValue(h&0xf))
+		}
+		return res
+	case thriftStruct:
+		return r.readStruct()
+	}
+	r.err = errors.New("unexpected type")
+	return nil
+}
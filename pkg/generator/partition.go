@@ -0,0 +1,85 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// maxPartitions is the maximum number of hourly partitions, about 10 years.
+const maxPartitions = 24 * 366 * 10
+
+// partitioner picks time partitions like 'year=2024/month=01/day=02/hour=03'
+// for object names. Recent partitions are picked more often with a skew > 0.
+type partitioner struct {
+	n    int
+	skew float64
+	// newest is the hour of the most recent partition.
+	newest time.Time
+	// cum contains the cumulative weight of partitions, newest first.
+	cum []float64
+}
+
+// WithPartitions spreads object names over n hourly partitions ending at the current hour.
+// The partition that is h hours old is picked with a weight of 1/(h+1)^skew,
+// so a skew of 0 spreads names evenly and higher skews favor recent partitions.
+// Names are below the prefix, or where {partition} is in the key template.
+// A count of 0 disables partitions.
+func WithPartitions(n int, skew float64) Option {
+	return func(o *Options) error {
+		if n == 0 {
+			o.partitions = nil
+			return nil
+		}
+		if n < 0 || n > maxPartitions {
+			return fmt.Errorf("partitions: count must be 0 to %d", maxPartitions)
+		}
+		if skew < 0 || math.IsNaN(skew) {
+			return errors.New("partitions: skew must be >= 0")
+		}
+		p := partitioner{
+			n:      n,
+			skew:   skew,
+			newest: time.Now().UTC().Truncate(time.Hour),
+			cum:    make([]float64, n),
+		}
+		total := 0.0
+		for h := range p.cum {
+			total += math.Pow(float64(h+1), -skew)
+			p.cum[h] = total
+		}
+		o.partitions = &p
+		return nil
+	}
+}
+
+// pick returns a random partition.
+func (p *partitioner) pick(rng *rand.Rand) string {
+	v := rng.Float64() * p.cum[len(p.cum)-1]
+	h := sort.SearchFloat64s(p.cum, v)
+	if h >= p.n {
+		h = p.n - 1
+	}
+	t := p.newest.Add(-time.Duration(h) * time.Hour)
+	return fmt.Sprintf("year=%04d/month=%02d/day=%02d/hour=%02d", t.Year(), t.Month(), t.Day(), t.Hour())
+}
@@ -0,0 +1,79 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithPartitions(t *testing.T) {
+	re := regexp.MustCompile(`^pfx/year=\d{4}/month=\d{2}/day=\d{2}/hour=\d{2}/\d+\..{16}\.rnd$`)
+	newest := time.Now().UTC().Truncate(time.Hour)
+	for _, skew := range []float64{0, 1.5} {
+		src, err := New(WithRandomData().Apply(), WithSize(10), WithCustomPrefix("pfx"), WithPartitions(48, skew))
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts := make(map[string]int)
+		const n = 48000
+		for i := 0; i < n; i++ {
+			name := src.Object().Name
+			if !re.MatchString(name) {
+				t.Fatalf("name %q does not match %s", name, re)
+			}
+			part := strings.Join(strings.Split(name, "/")[1:5], "/")
+			tm, err := time.Parse("year=2006/month=01/day=02/hour=15", part)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if age := newest.Sub(tm); age < 0 || age >= 48*time.Hour {
+				t.Fatalf("partition %s outside range", part)
+			}
+			counts[part]++
+		}
+		newestN := counts[newest.Format("year=2006/month=01/day=02/hour=15")]
+		oldestN := counts[newest.Add(-47*time.Hour).Format("year=2006/month=01/day=02/hour=15")]
+		switch {
+		case skew == 0 && (len(counts) != 48 || newestN < 800 || newestN > 1200):
+			t.Errorf("skew 0: %d partitions, %d in newest", len(counts), newestN)
+		case skew > 0 && newestN < 50*oldestN:
+			t.Errorf("skew %v: %d in newest, %d in oldest", skew, newestN, oldestN)
+		}
+	}
+
+	src, err := New(WithRandomData().Apply(), WithPartitions(10, 1), WithKeyTemplate("{prefix}/dt/{partition}/{uuid}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := src.Object().Name; !strings.HasPrefix(name, "dt/year=") {
+		t.Errorf("name %q has no partition", name)
+	}
+	for _, opts := range [][]Option{
+		{WithPartitions(-1, 1)},
+		{WithPartitions(10, -1)},
+		{WithPartitions(10, 1), WithKeyTemplate("{uuid}")},
+		{WithKeyTemplate("{partition}/{uuid}")},
+	} {
+		if _, err := New(append(opts, WithRandomData().Apply())...); err == nil {
+			t.Errorf("%d options: want error", len(opts))
+		}
+	}
+}
@@ -0,0 +1,146 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+)
+
+// patternBufSize is the minimum size of the buffer the pattern is repeated in.
+const patternBufSize = 64 << 10
+
+// WithPatternData returns default pattern data options.
+// By default objects are filled with zero bytes.
+func WithPatternData() PatternOpts {
+	return PatternOpts{pattern: []byte{0}}
+}
+
+// Apply pattern data options.
+func (o PatternOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		opts.pattern = o
+		opts.src = newPattern
+		return nil
+	}
+}
+
+func (o PatternOpts) validate() error {
+	if len(o.pattern) == 0 {
+		return errors.New("pattern: empty pattern")
+	}
+	return nil
+}
+
+// Pattern sets the bytes repeated in objects.
+// The pattern starts at the beginning of each object,
+// so all objects of the same size have the same content.
+func (o PatternOpts) Pattern(b []byte) PatternOpts {
+	o.pattern = append([]byte{}, b...)
+	return o
+}
+
+// PatternOpts are the options for the pattern data source.
+type PatternOpts struct {
+	pattern []byte
+}
+
+// ParsePattern parses a pattern given as hex bytes prefixed with '0x', like "0xdeadbeef",
+// or as text repeated as is.
+func ParsePattern(s string) ([]byte, error) {
+	if h := strings.TrimPrefix(strings.ToLower(s), "0x"); len(h) != len(s) {
+		b, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", s, err)
+		}
+		return b, nil
+	}
+	return []byte(s), nil
+}
+
+// isZero returns whether the pattern only contains zero bytes.
+func (o PatternOpts) isZero() bool {
+	for _, b := range o.pattern {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+type patternSrc struct {
+	counter uint64
+	o       Options
+	rng     *rand.Rand
+	buf     *circularBuffer
+	obj     Object
+}
+
+func newPattern(o Options) (Source, error) {
+	// Repeat the pattern in a buffer of whole patterns, so reads are not tiny.
+	p := o.pattern.pattern
+	data := make([]byte, 0, patternBufSize+len(p))
+	for len(data) < patternBufSize {
+		data = append(data, p...)
+	}
+	s := patternSrc{
+		o:   o,
+		rng: rand.New(rand.NewSource(int64(rand.Uint64()))),
+		buf: newCircularBuffer(data, o.totalSize),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "application/octet-stream",
+			Size:        0,
+		},
+	}
+	s.obj.setPrefix(o)
+	return &s, nil
+}
+
+func (s *patternSrc) Object() *Object {
+	atomic.AddUint64(&s.counter, 1)
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], s.rng)
+	s.obj.Size = s.o.getSize(s.rng)
+	s.obj.setName(fmt.Sprintf("%d.%s.pat", atomic.LoadUint64(&s.counter), string(nBuf[:])))
+	s.obj.Reader = s.buf.Reset(s.obj.Size)
+	return &s.obj
+}
+
+func (s *patternSrc) String() string {
+	data := "Zero data"
+	if !s.o.pattern.isZero() {
+		data = fmt.Sprintf("Pattern data; %d byte pattern 0x%x", len(s.o.pattern.pattern), s.o.pattern.pattern)
+	}
+	if s.o.randSize {
+		return fmt.Sprintf("%s; random size up to %d bytes", data, s.o.totalSize)
+	}
+	return fmt.Sprintf("%s; %d bytes total", data, s.o.totalSize)
+}
+
+func (s *patternSrc) Prefix() string {
+	return s.obj.Prefix
+}
@@ -0,0 +1,82 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestWithPatternData(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []byte
+	}{
+		{pattern: "", want: []byte{0}},
+		{pattern: "0xDEADbeef", want: []byte{0xde, 0xad, 0xbe, 0xef}},
+		{pattern: "warp", want: []byte("warp")},
+		{pattern: "0x00000000000000000000000000000000000000000000000000000001", want: append(make([]byte, 27), 1)},
+	}
+	for _, tt := range tests {
+		g := WithPatternData()
+		if tt.pattern != "" {
+			p, err := ParsePattern(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g = g.Pattern(p)
+		}
+		const size = 100003
+		src, err := New(g.Apply(), WithSize(size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			obj := src.Object()
+			b, err := io.ReadAll(obj.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := bytes.Repeat(tt.want, size/len(tt.want)+1)[:size]
+			if !bytes.Equal(b, want) {
+				t.Fatalf("%q: object %d does not repeat the pattern", tt.pattern, i)
+			}
+		}
+
+		// Seeking for retries keeps the pattern aligned.
+		obj := src.Object()
+		if _, err := obj.Reader.Seek(5, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(obj.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := bytes.Repeat(tt.want, size/len(tt.want)+2)[5:size]; !bytes.Equal(b, want) {
+			t.Errorf("%q: data after seek does not match", tt.pattern)
+		}
+	}
+
+	if _, err := ParsePattern("0xabc"); err == nil {
+		t.Error("want error for odd number of hex digits")
+	}
+	if _, err := New(WithPatternData().Pattern(nil).Apply()); err == nil {
+		t.Error("want error for empty pattern")
+	}
+}
@@ -0,0 +1,275 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+)
+
+const (
+	// proseSuccessors is the number of likely successors of each word.
+	proseSuccessors = 8
+
+	// proseFollow is the probability that the next word is one of the likely successors.
+	proseFollow = 0.6
+
+	// proseZipf is the exponent of the word frequencies.
+	proseZipf = 1.07
+)
+
+// proseCommon are common English words used for the most frequent words of the vocabulary.
+var proseCommon = []string{
+	"the", "of", "and", "to", "a", "in", "is", "that", "for", "it",
+	"as", "was", "with", "be", "by", "on", "not", "he", "this", "are",
+	"or", "his", "from", "at", "which", "but", "have", "an", "had", "they",
+	"you", "were", "their", "one", "all", "we", "can", "her", "has", "there",
+	"been", "if", "more", "when", "will", "would", "who", "so", "no", "she",
+	"other", "its", "may", "these", "what", "them", "than", "some", "him", "time",
+	"into", "only", "do", "could", "new", "about", "two", "first", "then", "any",
+	"like", "our", "over", "such", "out", "also", "most", "made", "after", "many",
+	"year", "people", "state", "data", "between", "system", "under", "world", "well", "work",
+	"through", "where", "should", "each", "those", "because", "while", "same", "day", "part",
+}
+
+// Syllable parts of generated words.
+var (
+	proseOnsets = []string{"b", "c", "d", "f", "g", "h", "l", "m", "n", "p", "r", "s", "t", "v", "w", "br", "ch", "cl", "cr", "dr", "fl", "gr", "pl", "pr", "sh", "sl", "sp", "st", "str", "th", "tr", ""}
+	proseVowels = []string{"a", "e", "i", "o", "u", "ai", "ea", "ee", "io", "ou", "oa", "y"}
+	proseCodas  = []string{"", "", "", "n", "r", "s", "t", "l", "m", "nd", "nt", "st", "ck", "ng", "rd", "ss"}
+)
+
+// WithProseData returns default options for natural-language text.
+func WithProseData() ProseOpts {
+	return proseOptsDefaults()
+}
+
+// Apply prose data options.
+// The vocabulary and the successors of each word are created when the options are applied.
+func (o ProseOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		seed := int64(rand.Uint64())
+		if o.seed != nil {
+			seed = *o.seed
+		}
+		o.model = newProseModel(rand.New(rand.NewSource(seed)), o.vocabulary)
+		opts.prose = o
+		opts.src = newProse
+		return nil
+	}
+}
+
+func (o ProseOpts) validate() error {
+	if o.vocabulary < 2 {
+		return errors.New("prose: vocabulary < 2")
+	}
+	return nil
+}
+
+// Vocabulary sets the number of distinct words.
+// The most frequent words are common English words,
+// the rest are pronounceable made up words.
+func (o ProseOpts) Vocabulary(n int) ProseOpts {
+	o.vocabulary = n
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make usage predictable.
+// The seed also selects the vocabulary.
+func (o ProseOpts) RngSeed(s int64) ProseOpts {
+	o.seed = &s
+	return o
+}
+
+// ProseOpts are the options for the prose data source.
+type ProseOpts struct {
+	seed       *int64
+	vocabulary int
+	model      *proseModel
+}
+
+func proseOptsDefaults() ProseOpts {
+	return ProseOpts{
+		seed:       nil,
+		vocabulary: 10000,
+	}
+}
+
+// proseModel is a first order Markov chain of words.
+// Words are ordered by frequency, which follows Zipf's law.
+// Each word has a number of likely successors, also picked by frequency.
+type proseModel struct {
+	words      []string
+	successors [][proseSuccessors]uint32
+}
+
+func newProseModel(rng *rand.Rand, n int) *proseModel {
+	m := proseModel{words: make([]string, 0, n)}
+	seen := make(map[string]struct{}, n)
+	for _, w := range proseCommon {
+		if len(m.words) == n {
+			break
+		}
+		m.words = append(m.words, w)
+		seen[w] = struct{}{}
+	}
+	for len(m.words) < n {
+		// Less frequent words are longer.
+		syllables := 1 + rng.Intn(2)
+		if len(m.words) > 1000 {
+			syllables += rng.Intn(2)
+		}
+		var w []byte
+		for i := 0; i < syllables; i++ {
+			w = append(w, proseOnsets[rng.Intn(len(proseOnsets))]...)
+			w = append(w, proseVowels[rng.Intn(len(proseVowels))]...)
+		}
+		w = append(w, proseCodas[rng.Intn(len(proseCodas))]...)
+		if _, ok := seen[string(w)]; ok {
+			continue
+		}
+		seen[string(w)] = struct{}{}
+		m.words = append(m.words, string(w))
+	}
+	m.successors = make([][proseSuccessors]uint32, n)
+	zipf := m.zipf(rng)
+	for i := range m.successors {
+		for j := range m.successors[i] {
+			m.successors[i][j] = uint32(zipf.Uint64())
+		}
+	}
+	return &m
+}
+
+// zipf returns a generator of word indexes by frequency.
+func (m *proseModel) zipf(rng *rand.Rand) *rand.Zipf {
+	return rand.NewZipf(rng, proseZipf, 1, uint64(len(m.words)-1))
+}
+
+type proseSrc struct {
+	counter uint64
+	o       Options
+	model   *proseModel
+	buf     *circularBuffer
+	rng     *rand.Rand
+	zipf    *rand.Zipf
+	obj     Object
+}
+
+func newProse(o Options) (Source, error) {
+	rndSrc := rand.NewSource(int64(rand.Uint64()))
+	if o.prose.seed != nil {
+		rndSrc = rand.NewSource(*o.prose.seed)
+	}
+	p := proseSrc{
+		o:     o,
+		model: o.prose.model,
+		rng:   rand.New(rndSrc),
+		buf:   newCircularBuffer(make([]byte, 0, o.totalSize), o.totalSize),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "text/plain; charset=utf-8",
+			Size:        0,
+		},
+	}
+	p.zipf = p.model.zipf(p.rng)
+	p.obj.setPrefix(o)
+	return &p, nil
+}
+
+// Object returns paragraphs of sentences, cut at the object size.
+func (p *proseSrc) Object() *Object {
+	atomic.AddUint64(&p.counter, 1)
+	p.obj.Size = p.o.getSize(p.rng)
+	size := int(p.obj.Size)
+
+	dst := p.buf.data[:0]
+	for len(dst) < size {
+		dst = p.appendParagraph(dst, size)
+	}
+	p.buf.data = dst[:size]
+
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], p.rng)
+	p.obj.setName(fmt.Sprintf("%d.%s.txt", atomic.LoadUint64(&p.counter), string(nBuf[:])))
+
+	p.obj.Reader = p.buf.Reset(p.obj.Size)
+	return &p.obj
+}
+
+// appendParagraph appends a paragraph of 3 to 8 sentences,
+// or until the size has been reached.
+func (p *proseSrc) appendParagraph(dst []byte, size int) []byte {
+	n := 3 + p.rng.Intn(6)
+	for i := 0; i < n && len(dst) < size; i++ {
+		if i > 0 {
+			dst = append(dst, ' ')
+		}
+		dst = p.appendSentence(dst)
+	}
+	return append(dst, '\n', '\n')
+}
+
+// appendSentence appends a sentence of 4 to 24 words.
+func (p *proseSrc) appendSentence(dst []byte) []byte {
+	words := p.model.words
+	n := 4 + p.rng.Intn(21)
+	w := p.zipf.Uint64()
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			if p.rng.Intn(12) == 0 && i < n-1 {
+				dst = append(dst, ',')
+			}
+			dst = append(dst, ' ')
+			if p.rng.Float64() < proseFollow {
+				w = uint64(p.model.successors[w][p.rng.Intn(proseSuccessors)])
+			} else {
+				w = p.zipf.Uint64()
+			}
+		}
+		start := len(dst)
+		dst = append(dst, words[w]...)
+		if i == 0 && dst[start] >= 'a' && dst[start] <= 'z' {
+			dst[start] -= 'a' - 'A'
+		}
+	}
+	switch p.rng.Intn(20) {
+	case 0:
+		return append(dst, '?')
+	case 1:
+		return append(dst, '!')
+	}
+	return append(dst, '.')
+}
+
+func (p *proseSrc) String() string {
+	if p.o.randSize {
+		return fmt.Sprintf("Prose text with %d words; random size up to %d bytes", len(p.model.words), p.o.totalSize)
+	}
+	return fmt.Sprintf("Prose text with %d words; %d bytes total", len(p.model.words), p.o.totalSize)
+}
+
+func (p *proseSrc) Prefix() string {
+	return p.obj.Prefix
+}
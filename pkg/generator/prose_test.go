@@ -0,0 +1,59 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestWithProseData(t *testing.T) {
+	for _, vocab := range []int{50, 1000, 20000} {
+		src, err := New(WithProseData().Vocabulary(vocab).RngSeed(1).Apply(), WithSize(256<<10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		obj := src.Object()
+		b, err := io.ReadAll(obj.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(b)) != obj.Size {
+			t.Fatalf("got %d bytes, want %d", len(b), obj.Size)
+		}
+		words := make(map[string]struct{})
+		fields := bytes.Fields(b)
+		// The last word may be cut.
+		for _, w := range fields[:len(fields)-1] {
+			w = bytes.ToLower(bytes.TrimRight(w, ",.?!"))
+			words[string(w)] = struct{}{}
+		}
+		if len(words) > vocab || len(words) < vocab/10 {
+			t.Errorf("vocabulary %d: got %d distinct words", vocab, len(words))
+		}
+		ratio := float64(len(b)) / float64(len(compressors["zstd"](b)))
+		if ratio < 2 || ratio > 6 {
+			t.Errorf("vocabulary %d: got compression ratio %.2f", vocab, ratio)
+		}
+	}
+
+	if _, err := New(WithProseData().Vocabulary(1).Apply()); err == nil {
+		t.Error("want error for vocabulary < 2")
+	}
+}
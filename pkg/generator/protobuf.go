@@ -0,0 +1,765 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+)
+
+// Distributions of the size of Protobuf messages.
+const (
+	ProtobufSizeUniform = NDJSONLengthUniform
+	ProtobufSizeExp     = NDJSONLengthExp
+	ProtobufSizeNormal  = NDJSONLengthNormal
+)
+
+const (
+	// protoMaxSize is the maximum message size, the default limit of Protobuf parsers.
+	protoMaxSize = 64 << 20
+
+	// protoMaxDepth is the nesting depth after which message fields are left out,
+	// so recursive messages end.
+	protoMaxDepth = 8
+
+	// protoMaxRepeated is the maximum number of values of repeated fields.
+	protoMaxRepeated = 4
+)
+
+// Field types of descriptors.
+const (
+	protoTypeDouble   = 1
+	protoTypeFloat    = 2
+	protoTypeInt64    = 3
+	protoTypeUint64   = 4
+	protoTypeInt32    = 5
+	protoTypeFixed64  = 6
+	protoTypeFixed32  = 7
+	protoTypeBool     = 8
+	protoTypeString   = 9
+	protoTypeGroup    = 10
+	protoTypeMessage  = 11
+	protoTypeBytes    = 12
+	protoTypeUint32   = 13
+	protoTypeEnum     = 14
+	protoTypeSfixed32 = 15
+	protoTypeSfixed64 = 16
+	protoTypeSint32   = 17
+	protoTypeSint64   = 18
+)
+
+// WithProtobufData returns default Protobuf Opts.
+func WithProtobufData() ProtobufOpts {
+	return protobufOptsDefaults()
+}
+
+// Apply Protobuf data options.
+// The descriptor is parsed when the options are applied.
+func (o ProtobufOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		o.parsed = protoDefaultMessage()
+		if o.descriptor != nil {
+			var err error
+			o.parsed, err = parseProtoDescriptor(o.descriptor, o.message)
+			if err != nil {
+				return fmt.Errorf("protobuf: %w", err)
+			}
+		}
+		opts.protobuf = o
+		opts.src = newProtobuf
+		return nil
+	}
+}
+
+func (o ProtobufOpts) validate() error {
+	if o.messages <= 0 {
+		return errors.New("protobuf: messages <= 0")
+	}
+	if o.minSize < 0 || o.maxSize < o.minSize {
+		return fmt.Errorf("protobuf: invalid size range %d-%d", o.minSize, o.maxSize)
+	}
+	if o.maxSize > protoMaxSize {
+		return fmt.Errorf("protobuf: size %d > %d", o.maxSize, protoMaxSize)
+	}
+	switch o.sizeDist {
+	case ProtobufSizeUniform, ProtobufSizeExp, ProtobufSizeNormal:
+	default:
+		return fmt.Errorf("protobuf: unknown size distribution %q", o.sizeDist)
+	}
+	return nil
+}
+
+// Descriptor sets the message type from a serialized FileDescriptorSet,
+// as written by 'protoc --descriptor_set_out --include_imports'.
+// The message is the full name of the message type.
+// If it is empty, the first message of the last file is used.
+func (o ProtobufOpts) Descriptor(set []byte, message string) ProtobufOpts {
+	o.descriptor = set
+	o.message = message
+	return o
+}
+
+// Messages sets the number of messages in each object.
+func (o ProtobufOpts) Messages(n int) ProtobufOpts {
+	o.messages = n
+	return o
+}
+
+// MessageSize sets the range of the size of messages.
+func (o ProtobufOpts) MessageSize(minSize, maxSize int) ProtobufOpts {
+	o.minSize = minSize
+	o.maxSize = maxSize
+	return o
+}
+
+// MessageSizeDist sets the distribution of the size of messages.
+func (o ProtobufOpts) MessageSizeDist(dist string) ProtobufOpts {
+	o.sizeDist = dist
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make usage predictable.
+func (o ProtobufOpts) RngSeed(s int64) ProtobufOpts {
+	o.seed = &s
+	return o
+}
+
+// ProtobufOpts provides options for Protobuf generation.
+type ProtobufOpts struct {
+	seed       *int64
+	descriptor []byte
+	message    string
+	messages   int
+	minSize    int
+	maxSize    int
+	sizeDist   string
+	parsed     *protoMessage
+}
+
+func protobufOptsDefaults() ProtobufOpts {
+	return ProtobufOpts{
+		seed:     nil,
+		messages: 1000,
+		minSize:  64,
+		maxSize:  1024,
+		sizeDist: ProtobufSizeUniform,
+	}
+}
+
+// protoMessage is a message type.
+type protoMessage struct {
+	name   string
+	fields []*protoField
+	// oneofs are the fields of each oneof, of which one is set.
+	oneofs [][]*protoField
+	// filler is the last string or bytes field outside oneofs,
+	// which is set last with the length needed for the message size.
+	filler *protoField
+}
+
+// protoField is a field of a message type.
+type protoField struct {
+	name     string
+	number   int
+	kind     int
+	repeated bool
+	packed   bool
+	message  *protoMessage
+	enum     []int32
+	// typeName is the full name of message and enum types, until they are resolved.
+	typeName string
+}
+
+// protoDefaultMessage returns the message used if no descriptor is given:
+//
+//	message Event {
+//	  string id = 1;
+//	  int64 timestamp = 2;
+//	  Level level = 3;
+//	  string host = 4;
+//	  uint32 status = 5;
+//	  double latency = 6;
+//	  map<string, string> attributes = 7;
+//	  repeated string tags = 8;
+//	  bytes payload = 9;
+//	}
+func protoDefaultMessage() *protoMessage {
+	entry := &protoMessage{name: ".warp.Event.AttributesEntry", fields: []*protoField{
+		{name: "key", number: 1, kind: protoTypeString},
+		{name: "value", number: 2, kind: protoTypeString},
+	}}
+	m := &protoMessage{name: ".warp.Event", fields: []*protoField{
+		{name: "id", number: 1, kind: protoTypeString},
+		{name: "timestamp", number: 2, kind: protoTypeInt64},
+		{name: "level", number: 3, kind: protoTypeEnum, enum: []int32{0, 1, 2, 3}},
+		{name: "host", number: 4, kind: protoTypeString},
+		{name: "status", number: 5, kind: protoTypeUint32},
+		{name: "latency", number: 6, kind: protoTypeDouble},
+		{name: "attributes", number: 7, kind: protoTypeMessage, repeated: true, message: entry},
+		{name: "tags", number: 8, kind: protoTypeString, repeated: true},
+		{name: "payload", number: 9, kind: protoTypeBytes},
+	}}
+	m.setFiller()
+	return m
+}
+
+// setFiller sets the field used to reach the message size.
+func (m *protoMessage) setFiller() {
+	for _, f := range m.fields {
+		if f.kind == protoTypeString || f.kind == protoTypeBytes {
+			m.filler = f
+		}
+	}
+}
+
+// protoParser collects the types of a FileDescriptorSet.
+type protoParser struct {
+	messages map[string]*protoMessage
+	enums    map[string][]int32
+	fields   []*protoField
+	// first is the first message of the last file.
+	first *protoMessage
+}
+
+// parseProtoDescriptor returns the message type with the name from the FileDescriptorSet.
+func parseProtoDescriptor(set []byte, name string) (*protoMessage, error) {
+	fds, err := protoFields(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	if len(fds[1]) == 0 {
+		return nil, errors.New("descriptor set without files")
+	}
+	p := protoParser{messages: make(map[string]*protoMessage), enums: make(map[string][]int32)}
+	for _, fd := range fds[1] {
+		if err := p.file(protoBytesOf(fd)); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range p.fields {
+		switch f.kind {
+		case protoTypeMessage:
+			f.message = p.messages[f.typeName]
+			if f.message == nil {
+				return nil, fmt.Errorf("field %s: unknown message type %s", f.name, f.typeName)
+			}
+		case protoTypeEnum:
+			f.enum = p.enums[f.typeName]
+			if f.enum == nil {
+				return nil, fmt.Errorf("field %s: unknown enum type %s", f.name, f.typeName)
+			}
+		}
+	}
+	m := p.first
+	if name != "" {
+		m = p.messages["."+strings.TrimPrefix(name, ".")]
+		if m == nil {
+			return nil, fmt.Errorf("message %s not found in descriptor set", name)
+		}
+	}
+	if m == nil {
+		return nil, errors.New("descriptor set without messages")
+	}
+	m.setFiller()
+	return m, nil
+}
+
+// file adds the types of a FileDescriptorProto.
+func (p *protoParser) file(b []byte) error {
+	fd, err := protoFields(b)
+	if err != nil {
+		return fmt.Errorf("invalid file descriptor: %w", err)
+	}
+	scope := ""
+	if pkg := protoStringOf(fd, 2); pkg != "" {
+		scope = "." + pkg
+	}
+	proto3 := protoStringOf(fd, 12) == "proto3"
+	for i, md := range fd[4] {
+		m, err := p.message(protoBytesOf(md), scope, proto3)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			p.first = m
+		}
+	}
+	for _, ed := range fd[5] {
+		if err := p.enum(protoBytesOf(ed), scope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// message adds the DescriptorProto and its nested types in the scope.
+func (p *protoParser) message(b []byte, scope string, proto3 bool) (*protoMessage, error) {
+	md, err := protoFields(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message descriptor: %w", err)
+	}
+	m := &protoMessage{name: scope + "." + protoStringOf(md, 1)}
+	p.messages[m.name] = m
+	for _, nd := range md[3] {
+		if _, err := p.message(protoBytesOf(nd), m.name, proto3); err != nil {
+			return nil, err
+		}
+	}
+	for _, ed := range md[4] {
+		if err := p.enum(protoBytesOf(ed), m.name); err != nil {
+			return nil, err
+		}
+	}
+	for _, fdb := range md[2] {
+		fd, err := protoFields(protoBytesOf(fdb))
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid field descriptor: %w", m.name, err)
+		}
+		f := &protoField{
+			name:     m.name + "." + protoStringOf(fd, 1),
+			number:   int(protoUintOf(fd, 3)),
+			kind:     int(protoUintOf(fd, 5)),
+			repeated: protoUintOf(fd, 4) == 3,
+			typeName: protoStringOf(fd, 6),
+		}
+		switch {
+		case f.number <= 0:
+			return nil, fmt.Errorf("field %s: invalid number %d", f.name, f.number)
+		case f.kind == protoTypeGroup:
+			return nil, fmt.Errorf("field %s: groups are not supported", f.name)
+		case f.kind < protoTypeDouble || f.kind > protoTypeSint64:
+			return nil, fmt.Errorf("field %s: unknown type %d", f.name, f.kind)
+		}
+		if f.repeated && protoWireType(f.kind) != protoBytes {
+			// Repeated scalars are packed by default in proto3.
+			f.packed = proto3
+			if opts, ok := fd[8]; ok {
+				if fo, err := protoFields(protoBytesOf(opts[len(opts)-1])); err == nil && len(fo[2]) > 0 {
+					f.packed = protoUintOf(fo, 2) != 0
+				}
+			}
+		}
+		if f.kind == protoTypeMessage || f.kind == protoTypeEnum {
+			p.fields = append(p.fields, f)
+		}
+		// Optional proto3 fields are in a oneof of their own, and are always set.
+		if idx, ok := fd[9]; ok && protoUintOf(fd, 17) == 0 {
+			i := int(idx[len(idx)-1].(uint64))
+			for len(m.oneofs) <= i {
+				m.oneofs = append(m.oneofs, nil)
+			}
+			m.oneofs[i] = append(m.oneofs[i], f)
+			continue
+		}
+		m.fields = append(m.fields, f)
+	}
+	return m, nil
+}
+
+// enum adds the values of the EnumDescriptorProto in the scope.
+func (p *protoParser) enum(b []byte, scope string) error {
+	ed, err := protoFields(b)
+	if err != nil {
+		return fmt.Errorf("invalid enum descriptor: %w", err)
+	}
+	name := scope + "." + protoStringOf(ed, 1)
+	values := []int32{}
+	for _, vd := range ed[2] {
+		v, err := protoFields(protoBytesOf(vd))
+		if err != nil {
+			return fmt.Errorf("%s: invalid enum value: %w", name, err)
+		}
+		values = append(values, int32(protoUintOf(v, 2)))
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("enum %s without values", name)
+	}
+	p.enums[name] = values
+	return nil
+}
+
+type protobufSource struct {
+	counter uint64
+	o       Options
+	buf     *circularBuffer
+	rng     *rand.Rand
+	obj     Object
+
+	// chars contains random characters string values are copied from.
+	chars []byte
+	// random contains random bytes bytes values are copied from.
+	random []byte
+	// nested are the buffers of nested messages at each depth.
+	nested [protoMaxDepth + 1][]byte
+	packed []byte
+}
+
+func newProtobuf(o Options) (Source, error) {
+	rndSrc := rand.NewSource(int64(rand.Uint64()))
+	if o.protobuf.seed != nil {
+		rndSrc = rand.NewSource(*o.protobuf.seed)
+	}
+	p := protobufSource{
+		o:   o,
+		rng: rand.New(rndSrc),
+		buf: newCircularBuffer(make([]byte, 0, o.totalSize), o.totalSize),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "application/x-protobuf",
+			Size:        0,
+		},
+	}
+	const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 "
+	p.chars = make([]byte, o.protobuf.maxSize+4096)
+	for i := range p.chars {
+		p.chars[i] = alphabet[p.rng.Intn(len(alphabet))]
+	}
+	p.random = make([]byte, o.protobuf.maxSize+4096)
+	p.rng.Read(p.random)
+	p.obj.setPrefix(o)
+	return &p, nil
+}
+
+// Object returns an object with messages, each preceded by its size as a varint.
+func (p *protobufSource) Object() *Object {
+	atomic.AddUint64(&p.counter, 1)
+	opts := p.o.protobuf
+	dst := p.buf.data[:0]
+	for i := 0; i < opts.messages; i++ {
+		msg := p.appendSized(p.nested[0][:0], opts.parsed)
+		p.nested[0] = msg
+		dst = appendUvarint(dst, uint64(len(msg)))
+		dst = append(dst, msg...)
+	}
+	p.buf.data = dst
+	p.obj.Size = int64(len(dst))
+
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], p.rng)
+	p.obj.setName(fmt.Sprintf("%d.%s.pb", atomic.LoadUint64(&p.counter), string(nBuf[:])))
+
+	p.obj.Reader = p.buf.Reset(p.obj.Size)
+	return &p.obj
+}
+
+// appendSized appends a message of a random size to dst.
+// The filler field is written last with the length needed for the size.
+// Messages without a filler field, or larger than the size without it, have their natural size.
+func (p *protobufSource) appendSized(dst []byte, m *protoMessage) []byte {
+	opts := p.o.protobuf
+	size := randLength(p.rng, opts.sizeDist, opts.minSize, opts.maxSize)
+	start := len(dst)
+	dst = p.appendMessage(dst, m, 0)
+	f := m.filler
+	if f == nil {
+		return dst
+	}
+	keyLen := uvarintLen(uint64(f.number) << 3)
+	n := size - (len(dst) - start) - keyLen - 1
+	for n > 0 && uvarintLen(uint64(n))+n > size-(len(dst)-start)-keyLen {
+		n--
+	}
+	if n < 0 {
+		n = 0
+	}
+	dst = appendUvarint(dst, uint64(f.number)<<3|protoBytes)
+	dst = appendUvarint(dst, uint64(n))
+	return p.appendFill(dst, f, n)
+}
+
+// appendMessage appends the fields of a message to dst.
+// One field of each oneof is set.
+// The filler field of the top level message is left out.
+func (p *protobufSource) appendMessage(dst []byte, m *protoMessage, depth int) []byte {
+	for _, f := range m.fields {
+		if depth == 0 && f == m.filler {
+			continue
+		}
+		dst = p.appendField(dst, f, depth)
+	}
+	for _, fields := range m.oneofs {
+		if len(fields) > 0 {
+			dst = p.appendField(dst, fields[p.rng.Intn(len(fields))], depth)
+		}
+	}
+	return dst
+}
+
+// appendField appends the values of a field to dst.
+func (p *protobufSource) appendField(dst []byte, f *protoField, depth int) []byte {
+	if f.kind == protoTypeMessage && depth >= protoMaxDepth {
+		return dst
+	}
+	if !f.repeated {
+		return p.appendValue(dst, f, depth)
+	}
+	most := protoMaxRepeated
+	if f.kind == protoTypeMessage {
+		// Fewer nested messages deeper down, so recursive messages stay small.
+		most >>= depth
+	}
+	n := p.rng.Intn(most + 1)
+	if f.packed {
+		if n == 0 {
+			return dst
+		}
+		p.packed = p.packed[:0]
+		for i := 0; i < n; i++ {
+			p.packed = p.appendScalar(p.packed, f)
+		}
+		dst = appendUvarint(dst, uint64(f.number)<<3|protoBytes)
+		dst = appendUvarint(dst, uint64(len(p.packed)))
+		return append(dst, p.packed...)
+	}
+	for i := 0; i < n; i++ {
+		dst = p.appendValue(dst, f, depth)
+	}
+	return dst
+}
+
+// appendValue appends the key and a random value of the field to dst.
+func (p *protobufSource) appendValue(dst []byte, f *protoField, depth int) []byte {
+	wt := protoWireType(f.kind)
+	dst = appendUvarint(dst, uint64(f.number)<<3|uint64(wt))
+	switch f.kind {
+	case protoTypeMessage:
+		msg := p.appendMessage(p.nested[depth+1][:0], f.message, depth+1)
+		p.nested[depth+1] = msg
+		dst = appendUvarint(dst, uint64(len(msg)))
+		return append(dst, msg...)
+	case protoTypeString, protoTypeBytes:
+		n := 4 + p.rng.Intn(13)
+		dst = appendUvarint(dst, uint64(n))
+		return p.appendFill(dst, f, n)
+	}
+	return p.appendScalar(dst, f)
+}
+
+// appendScalar appends a random value of a field with a numeric type to dst, without key.
+func (p *protobufSource) appendScalar(dst []byte, f *protoField) []byte {
+	rng := p.rng
+	switch f.kind {
+	case protoTypeDouble:
+		return appendUint64(dst, math.Float64bits(rng.Float64()*1000))
+	case protoTypeFloat:
+		return appendUint32(dst, math.Float32bits(float32(rng.Intn(100000))/100))
+	case protoTypeInt64, protoTypeUint64:
+		return appendUvarint(dst, uint64(rng.Int63n(1<<40)))
+	case protoTypeInt32, protoTypeUint32:
+		return appendUvarint(dst, uint64(rng.Int31n(1000000)))
+	case protoTypeFixed64, protoTypeSfixed64:
+		return appendUint64(dst, uint64(rng.Int63n(1<<40)))
+	case protoTypeFixed32, protoTypeSfixed32:
+		return appendUint32(dst, uint32(rng.Int31n(1000000)))
+	case protoTypeBool:
+		return append(dst, byte(rng.Intn(2)))
+	case protoTypeEnum:
+		v := f.enum[rng.Intn(len(f.enum))]
+		// Negative values are sign extended to 64 bits.
+		return appendUvarint(dst, uint64(int64(v)))
+	case protoTypeSint32, protoTypeSint64:
+		v := rng.Int63n(2000000) - 1000000
+		return appendUvarint(dst, uint64(v<<1)^uint64(v>>63))
+	}
+	return dst
+}
+
+// appendFill appends n random characters for strings or random bytes to dst.
+func (p *protobufSource) appendFill(dst []byte, f *protoField, n int) []byte {
+	src := p.random
+	if f.kind == protoTypeString {
+		src = p.chars
+	}
+	for n > 0 {
+		l := n
+		if l > len(src)/2 {
+			l = len(src) / 2
+		}
+		off := p.rng.Intn(len(src) - l + 1)
+		dst = append(dst, src[off:off+l]...)
+		n -= l
+	}
+	return dst
+}
+
+func (p *protobufSource) String() string {
+	opts := p.o.protobuf
+	return fmt.Sprintf("Protobuf data; %d %s messages, %s size %d-%d", opts.messages, strings.TrimPrefix(opts.parsed.name, "."), opts.sizeDist, opts.minSize, opts.maxSize)
+}
+
+func (p *protobufSource) Prefix() string {
+	return p.obj.Prefix
+}
+
+// Protocol buffer wire types.
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+// protoWireType returns the wire type of a field type.
+func protoWireType(kind int) int {
+	switch kind {
+	case protoTypeDouble, protoTypeFixed64, protoTypeSfixed64:
+		return protoFixed64
+	case protoTypeFloat, protoTypeFixed32, protoTypeSfixed32:
+		return protoFixed32
+	case protoTypeString, protoTypeBytes, protoTypeMessage:
+		return protoBytes
+	}
+	return protoVarint
+}
+
+// protoWriter writes protocol buffer messages.
+type protoWriter struct {
+	b []byte
+}
+
+func (w *protoWriter) reset() {
+	w.b = w.b[:0]
+}
+
+func (w *protoWriter) key(field int, wireType int) {
+	w.b = appendUvarint(w.b, uint64(field)<<3|uint64(wireType))
+}
+
+func (w *protoWriter) uint(field int, v uint64) {
+	w.key(field, protoVarint)
+	w.b = appendUvarint(w.b, v)
+}
+
+func (w *protoWriter) bytes(field int, v []byte) {
+	w.key(field, protoBytes)
+	w.b = appendUvarint(w.b, uint64(len(v)))
+	w.b = append(w.b, v...)
+}
+
+func (w *protoWriter) str(field int, v string) {
+	w.key(field, protoBytes)
+	w.b = appendUvarint(w.b, uint64(len(v)))
+	w.b = append(w.b, v...)
+}
+
+// message writes the message as an embedded message field.
+func (w *protoWriter) message(field int, m *protoWriter) {
+	w.bytes(field, m.b)
+}
+
+// packed writes the values as a packed repeated field.
+func (w *protoWriter) packed(field int, vals ...uint64) {
+	var n int
+	for _, v := range vals {
+		n += uvarintLen(v)
+	}
+	w.key(field, protoBytes)
+	w.b = appendUvarint(w.b, uint64(n))
+	for _, v := range vals {
+		w.b = appendUvarint(w.b, v)
+	}
+}
+
+// uvarintLen returns the size of the value encoded as a varint.
+func uvarintLen(v uint64) int {
+	n := 1
+	for v >= 0x80 {
+		v >>= 7
+		n++
+	}
+	return n
+}
+
+// protoFields returns the fields of a protocol buffer message by number.
+// Varints and fixed size values are returned as uint64, and length delimited fields as []byte.
+func protoFields(b []byte) (map[int][]interface{}, error) {
+	fields := make(map[int][]interface{})
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errors.New("invalid key")
+		}
+		b = b[n:]
+		field := int(key >> 3)
+		switch key & 7 {
+		case protoVarint:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return nil, errors.New("invalid varint")
+			}
+			b = b[n:]
+			fields[field] = append(fields[field], v)
+		case protoFixed64:
+			if len(b) < 8 {
+				return nil, errors.New("short fixed64")
+			}
+			fields[field] = append(fields[field], binary.LittleEndian.Uint64(b))
+			b = b[8:]
+		case protoBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || l > uint64(len(b)-n) {
+				return nil, errors.New("invalid length")
+			}
+			fields[field] = append(fields[field], b[n:n+int(l)])
+			b = b[n+int(l):]
+		case protoFixed32:
+			if len(b) < 4 {
+				return nil, errors.New("short fixed32")
+			}
+			fields[field] = append(fields[field], uint64(binary.LittleEndian.Uint32(b)))
+			b = b[4:]
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d", key&7)
+		}
+	}
+	return fields, nil
+}
+
+// protoBytesOf returns a length delimited value, or nil if it is another type.
+func protoBytesOf(v interface{}) []byte {
+	b, _ := v.([]byte)
+	return b
+}
+
+// protoStringOf returns the last value of a string field.
+func protoStringOf(fields map[int][]interface{}, field int) string {
+	vals := fields[field]
+	if len(vals) == 0 {
+		return ""
+	}
+	return string(protoBytesOf(vals[len(vals)-1]))
+}
+
+// protoUintOf returns the last value of a varint field.
+func protoUintOf(fields map[int][]interface{}, field int) uint64 {
+	vals := fields[field]
+	if len(vals) == 0 {
+		return 0
+	}
+	v, _ := vals[len(vals)-1].(uint64)
+	return v
+}
@@ -0,0 +1,267 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestWithProtobufData(t *testing.T) {
+	for _, dist := range []string{ProtobufSizeUniform, ProtobufSizeExp, ProtobufSizeNormal} {
+		opts := WithProtobufData().Messages(200).MessageSize(512, 4096).MessageSizeDist(dist)
+		src, err := New(opts.RngSeed(1).Apply())
+		if err != nil {
+			t.Fatal(err)
+		}
+		obj := src.Object()
+		if obj.ContentType != "application/x-protobuf" {
+			t.Errorf("content type %q", obj.ContentType)
+		}
+		msgs := readDelimited(t, obj)
+		if len(msgs) != 200 {
+			t.Fatalf("%s: got %d messages, want 200", dist, len(msgs))
+		}
+		var total int
+		for _, msg := range msgs {
+			if len(msg) < 512 || len(msg) > 4096 {
+				t.Fatalf("%s: message size %d", dist, len(msg))
+			}
+			total += len(msg)
+			if err := checkProtoMessage(msg, protoDefaultMessage(), 0); err != nil {
+				t.Fatalf("%s: %v", dist, err)
+			}
+		}
+		avg := float64(total) / float64(len(msgs))
+		if dist == ProtobufSizeExp && avg > 2304 {
+			t.Errorf("%s: average size %.1f, want small messages", dist, avg)
+		}
+		if dist != ProtobufSizeExp && (avg < 1900 || avg > 2700) {
+			t.Errorf("%s: average size %.1f, want about 2304", dist, avg)
+		}
+	}
+
+	for _, opts := range []ProtobufOpts{
+		WithProtobufData().Messages(0),
+		WithProtobufData().MessageSize(10, 5),
+		WithProtobufData().MessageSize(0, protoMaxSize+1),
+		WithProtobufData().MessageSizeDist("zipf"),
+		WithProtobufData().Descriptor([]byte{0xff}, ""),
+		WithProtobufData().Descriptor(testProtoDescriptor(), "test.Missing"),
+	} {
+		if _, err := New(opts.Apply()); err == nil {
+			t.Errorf("%+v: want error", opts)
+		}
+	}
+}
+
+func TestWithProtobufDataDescriptor(t *testing.T) {
+	set := testProtoDescriptor()
+	for _, name := range []string{"", "test.Outer", ".test.Outer", "test.Outer.Inner"} {
+		src, err := New(WithProtobufData().Descriptor(set, name).Messages(100).MessageSize(0, 2000).RngSeed(1).Apply())
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := src.(*protobufSource).o.protobuf.parsed
+		for _, msg := range readDelimited(t, src.Object()) {
+			if err := checkProtoMessage(msg, m, 0); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+	}
+}
+
+// testProtoDescriptor returns a FileDescriptorSet of:
+//
+//	syntax = "proto3";
+//	package test;
+//	message Outer {
+//	  message Inner {
+//	    bytes data = 1;
+//	    repeated uint32 nums = 2 [packed = false];
+//	  }
+//	  enum Kind { A = 0; B = 1; C = -1; }
+//	  int32 a = 1;
+//	  repeated sint64 b = 2;
+//	  Inner inner = 3;
+//	  Kind kind = 4;
+//	  oneof choice {
+//	    string s = 5;
+//	    fixed32 f = 6;
+//	  }
+//	  repeated Outer children = 7;
+//	  float g = 8;
+//	  string note = 9;
+//	  map<string, sfixed64> values = 10;
+//	}
+func testProtoDescriptor() []byte {
+	field := func(name string, number, label, kind int, typeName string) *protoWriter {
+		var f protoWriter
+		f.str(1, name)
+		f.uint(3, uint64(number))
+		f.uint(4, uint64(label))
+		f.uint(5, uint64(kind))
+		if typeName != "" {
+			f.str(6, typeName)
+		}
+		return &f
+	}
+	const optional, repeated = 1, 3
+
+	var inner protoWriter
+	inner.str(1, "Inner")
+	inner.message(2, field("data", 1, optional, protoTypeBytes, ""))
+	nums := field("nums", 2, repeated, protoTypeUint32, "")
+	var notPacked protoWriter
+	notPacked.uint(2, 0)
+	nums.message(8, &notPacked)
+	inner.message(2, nums)
+
+	var kind protoWriter
+	kind.str(1, "Kind")
+	for i, v := range []int64{0, 1, -1} {
+		var ev protoWriter
+		ev.str(1, string(rune('A'+i)))
+		ev.uint(2, uint64(v))
+		kind.message(2, &ev)
+	}
+
+	var entry protoWriter
+	entry.str(1, "ValuesEntry")
+	entry.message(2, field("key", 1, optional, protoTypeString, ""))
+	entry.message(2, field("value", 2, optional, protoTypeSfixed64, ""))
+	var mapEntry protoWriter
+	mapEntry.uint(7, 1)
+	entry.message(7, &mapEntry)
+
+	var outer protoWriter
+	outer.str(1, "Outer")
+	outer.message(2, field("a", 1, optional, protoTypeInt32, ""))
+	outer.message(2, field("b", 2, repeated, protoTypeSint64, ""))
+	outer.message(2, field("inner", 3, optional, protoTypeMessage, ".test.Outer.Inner"))
+	outer.message(2, field("kind", 4, optional, protoTypeEnum, ".test.Outer.Kind"))
+	for _, f := range []*protoWriter{field("s", 5, optional, protoTypeString, ""), field("f", 6, optional, protoTypeFixed32, "")} {
+		f.uint(9, 0)
+		outer.message(2, f)
+	}
+	outer.message(2, field("children", 7, repeated, protoTypeMessage, ".test.Outer"))
+	outer.message(2, field("g", 8, optional, protoTypeFloat, ""))
+	outer.message(2, field("note", 9, optional, protoTypeString, ""))
+	outer.message(2, field("values", 10, repeated, protoTypeMessage, ".test.Outer.ValuesEntry"))
+	outer.message(3, &inner)
+	outer.message(3, &entry)
+	outer.message(4, &kind)
+	var oneof protoWriter
+	oneof.str(1, "choice")
+	outer.message(8, &oneof)
+
+	var file protoWriter
+	file.str(1, "test.proto")
+	file.str(2, "test")
+	file.message(4, &outer)
+	file.str(12, "proto3")
+	var set protoWriter
+	set.message(1, &file)
+	return set.b
+}
+
+// readDelimited returns the length delimited messages of the object.
+func readDelimited(t *testing.T, obj *Object) [][]byte {
+	t.Helper()
+	b, err := io.ReadAll(obj.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(b)) != obj.Size {
+		t.Fatalf("got size %d, want %d", len(b), obj.Size)
+	}
+	var msgs [][]byte
+	for len(b) > 0 {
+		l, n := binary.Uvarint(b)
+		if n <= 0 || l > uint64(len(b)-n) {
+			t.Fatal("invalid message length")
+		}
+		msgs = append(msgs, b[n:n+int(l)])
+		b = b[n+int(l):]
+	}
+	return msgs
+}
+
+// checkProtoMessage checks that the fields of the message have the wire types of the message type,
+// and that singular fields and oneofs are set once.
+func checkProtoMessage(b []byte, m *protoMessage, depth int) error {
+	fields, err := protoFields(b)
+	if err != nil {
+		return err
+	}
+	types := make(map[int]*protoField)
+	for _, f := range m.fields {
+		types[f.number] = f
+	}
+	for _, oneof := range m.oneofs {
+		set := 0
+		for _, f := range oneof {
+			types[f.number] = f
+			set += len(fields[f.number])
+		}
+		if set != 1 {
+			return fmt.Errorf("%s: %d oneof fields set", m.name, set)
+		}
+	}
+	for number, vals := range fields {
+		f := types[number]
+		if f == nil {
+			return fmt.Errorf("%s: unknown field %d", m.name, number)
+		}
+		if !f.repeated && len(vals) != 1 {
+			return fmt.Errorf("%s: singular field set %d times", f.name, len(vals))
+		}
+		if f.packed {
+			if len(vals) != 1 {
+				return fmt.Errorf("%s: packed field written %d times", f.name, len(vals))
+			}
+			if _, ok := vals[0].([]byte); !ok {
+				return fmt.Errorf("%s: not packed", f.name)
+			}
+			continue
+		}
+		for _, v := range vals {
+			_, isBytes := v.([]byte)
+			if isBytes != (protoWireType(f.kind) == protoBytes) {
+				return fmt.Errorf("%s: wrong wire type", f.name)
+			}
+			if f.kind == protoTypeMessage {
+				if depth >= protoMaxDepth {
+					return errors.New("nested too deep")
+				}
+				if err := checkProtoMessage(v.([]byte), f.message, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, f := range m.fields {
+		if !f.repeated && !(f.kind == protoTypeMessage && depth >= protoMaxDepth) && len(fields[f.number]) == 0 {
+			return fmt.Errorf("%s: not set", f.name)
+		}
+	}
+	return nil
+}
@@ -45,6 +45,9 @@ func (o RandomOpts) validate() error {
 	if o.size <= 0 {
 		return errors.New("random: size <= 0")
 	}
+	if o.repeat < 1 {
+		return errors.New("random: repeat < 1")
+	}
 	return nil
 }
 
@@ -61,17 +64,28 @@ func (o RandomOpts) Size(s int) RandomOpts {
 	return o
 }
 
+// Repeat sets how many times random data is repeated in each object.
+// With the default of 1 there is no repetition and each object has as many bytes of entropy as its size.
+// With n > 1 the first size/n bytes (rounded up) of each object are random
+// and repeated until the object size is reached, so an object has about size/n bytes of entropy.
+func (o RandomOpts) Repeat(n int) RandomOpts {
+	o.repeat = n
+	return o
+}
+
 // RandomOpts are the options for the random data source.
 type RandomOpts struct {
-	seed *int64
-	size int
+	seed   *int64
+	size   int
+	repeat int
 }
 
 func randomOptsDefaults() RandomOpts {
 	return RandomOpts{
 		seed: nil,
 		// Use 128KB as base.
-		size: 128 << 10,
+		size:   128 << 10,
+		repeat: 1,
 	}
 }
 
@@ -81,6 +95,8 @@ type randomSrc struct {
 	buf     *scrambler
 	rng     *rand.Rand
 	obj     Object
+	// repeated contains the data repeated in objects when repeat > 1.
+	repeated *circularBuffer
 }
 
 func newRandom(o Options) (Source, error) {
@@ -128,14 +144,33 @@ func (r *randomSrc) Object() *Object {
 
 	// Reset scrambler
 	r.obj.Reader = r.buf.Reset(r.obj.Size)
+	if n := int64(r.o.random.repeat); n > 1 && r.obj.Size > 0 {
+		r.obj.Reader = r.repeat((r.obj.Size + n - 1) / n)
+	}
 	return &r.obj
 }
 
+// repeat returns a reader that repeats the first unique bytes of the scrambler.
+func (r *randomSrc) repeat(unique int64) io.ReadSeeker {
+	if r.repeated == nil || int64(cap(r.repeated.data)) < unique {
+		r.repeated = newCircularBuffer(make([]byte, unique), r.obj.Size)
+	}
+	data := r.repeated.data[:unique]
+	// Reading less than the object size from the scrambler cannot fail.
+	io.ReadFull(r.obj.Reader, data)
+	r.repeated.data = data
+	return r.repeated.Reset(r.obj.Size)
+}
+
 func (r *randomSrc) String() string {
+	repeat := ""
+	if r.o.random.repeat > 1 {
+		repeat = fmt.Sprintf("; repeated %d times", r.o.random.repeat)
+	}
 	if r.o.randSize {
-		return fmt.Sprintf("Random data; random size up to %d bytes", r.o.totalSize)
+		return fmt.Sprintf("Random data; random size up to %d bytes%s", r.o.totalSize, repeat)
 	}
-	return fmt.Sprintf("Random data; %d bytes total", r.buf.want)
+	return fmt.Sprintf("Random data; %d bytes total%s", r.buf.want, repeat)
 }
 
 func (r *randomSrc) Prefix() string {
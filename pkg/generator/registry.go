@@ -0,0 +1,121 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// SourceFactory creates a data source with the options.
+// args is a source specific configuration string given by the user, which may be empty.
+//
+// Objects returned by the source should be set up with Object.Init once
+// and named with Object.SetName, so prefixes and naming options apply.
+// Sizes should be picked with Options.ObjectSize.
+type SourceFactory func(o Options, args string) (Source, error)
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = map[string]SourceFactory{}
+)
+
+// RegisterSource makes a data source available by name.
+// It is intended to be called from init functions
+// of programs that run warp with custom payload formats.
+// RegisterSource panics if the name is empty, the factory is nil or the name is already registered.
+func RegisterSource(name string, factory SourceFactory) {
+	if name == "" || factory == nil {
+		panic("generator: RegisterSource with empty name or nil factory")
+	}
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if _, ok := sources[name]; ok {
+		panic("generator: RegisterSource called twice for source " + name)
+	}
+	sources[name] = factory
+}
+
+// RegisteredSources returns the sorted names of registered sources.
+func RegisteredSources() []string {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	res := make([]string, 0, len(sources))
+	for name := range sources {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// WithSource returns options for the registered source with the name.
+func WithSource(name string) SourceOpts {
+	return SourceOpts{name: name}
+}
+
+// SourceOpts are the options for a registered source.
+type SourceOpts struct {
+	name string
+	args string
+}
+
+// Args sets the configuration string passed to the source factory.
+func (o SourceOpts) Args(s string) SourceOpts {
+	o.args = s
+	return o
+}
+
+// Apply registered source options.
+func (o SourceOpts) Apply() Option {
+	return func(opts *Options) error {
+		sourcesMu.RLock()
+		factory := sources[o.name]
+		sourcesMu.RUnlock()
+		if factory == nil {
+			return fmt.Errorf("unknown source %q", o.name)
+		}
+		opts.src = func(opts Options) (Source, error) {
+			return factory(opts, o.args)
+		}
+		return nil
+	}
+}
+
+// ObjectSize returns a size for an object with the size options.
+func (o Options) ObjectSize(rng *rand.Rand) int64 {
+	return o.getSize(rng)
+}
+
+// TotalSize returns the maximum size of objects.
+func (o Options) TotalSize() int64 {
+	return o.totalSize
+}
+
+// Init sets up the prefix and naming options of an object.
+// Sources reusing an object for all objects they return call it once.
+func (o *Object) Init(opts Options) {
+	o.setPrefix(opts)
+}
+
+// SetName sets the name of the object below its prefix,
+// and picks content type, user metadata and tags if set in the options.
+func (o *Object) SetName(s string) {
+	o.setName(s)
+}
@@ -0,0 +1,99 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// fillSource is a registered source returning objects filled with a byte.
+type fillSource struct {
+	o    Options
+	fill byte
+	rng  *rand.Rand
+	obj  Object
+	n    int
+}
+
+func (f *fillSource) Object() *Object {
+	f.n++
+	f.obj.Size = f.o.ObjectSize(f.rng)
+	f.obj.Reader = bytes.NewReader(bytes.Repeat([]byte{f.fill}, int(f.obj.Size)))
+	f.obj.SetName(strings.Repeat("x", f.n) + ".fill")
+	return &f.obj
+}
+
+func (f *fillSource) String() string { return "fill" }
+func (f *fillSource) Prefix() string { return f.obj.Prefix }
+
+func TestRegisterSource(t *testing.T) {
+	RegisterSource("test-fill", func(o Options, args string) (Source, error) {
+		f := fillSource{o: o, fill: 'a', rng: rand.New(rand.NewSource(1))}
+		if args != "" {
+			f.fill = args[0]
+		}
+		f.obj.ContentType = "application/x-fill"
+		f.obj.Init(o)
+		return &f, nil
+	})
+	found := false
+	for _, name := range RegisteredSources() {
+		found = found || name == "test-fill"
+	}
+	if !found {
+		t.Fatalf("source not listed: %v", RegisteredSources())
+	}
+
+	src, err := New(WithSource("test-fill").Args("z").Apply(), WithSize(100), WithPrefixSize(8), WithMetadata(1, 4, 4, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.Prefix() == "" {
+		t.Fatal("no prefix")
+	}
+	for i := 0; i < 3; i++ {
+		obj := src.Object()
+		b, err := io.ReadAll(obj.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b) != 100 || !bytes.Equal(b, bytes.Repeat([]byte("z"), 100)) {
+			t.Fatalf("got %q", b)
+		}
+		if !strings.HasPrefix(obj.Name, src.Prefix()+"/") || !strings.HasSuffix(obj.Name, ".fill") {
+			t.Errorf("name %q not below prefix %q", obj.Name, src.Prefix())
+		}
+		if obj.ContentType != "application/x-fill" || len(obj.UserMetadata) != 1 {
+			t.Errorf("got content type %q, metadata %v", obj.ContentType, obj.UserMetadata)
+		}
+	}
+
+	if _, err := New(WithSource("test-missing").Apply()); err == nil {
+		t.Error("want error for unknown source")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("want panic registering twice")
+		}
+	}()
+	RegisterSource("test-fill", func(Options, string) (Source, error) { return nil, nil })
+}
@@ -0,0 +1,139 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+)
+
+// streamBlockSize is the size of blocks generated by streams without a compression window.
+const streamBlockSize = 1 << 20
+
+// WithStreaming will generate text data in blocks while it is read,
+// instead of generating the complete object before it is returned.
+// This keeps memory use bounded for large objects.
+// Random data is always streamed.
+func WithStreaming(b bool) Option {
+	return func(o *Options) error {
+		o.stream = b
+		return nil
+	}
+}
+
+// blockStream generates data in blocks while it is read.
+// Each block is generated from a RNG seeded from the stream seed and the block number,
+// so the same data is returned after seeking.
+type blockStream struct {
+	seed int64
+	fill func(dst []byte, rng *rand.Rand)
+
+	// buf contains the block with number bufIdx.
+	buf    []byte
+	bufIdx int64
+
+	want, read int64
+}
+
+func newBlockStream(blockSize int, fill func(dst []byte, rng *rand.Rand)) *blockStream {
+	return &blockStream{buf: make([]byte, blockSize), fill: fill, bufIdx: -1}
+}
+
+// Reset the stream to return want bytes generated from the seed.
+func (b *blockStream) Reset(seed, want int64) io.ReadSeeker {
+	b.seed = seed
+	b.want = want
+	b.read = 0
+	b.bufIdx = -1
+	return b
+}
+
+func (b *blockStream) Read(p []byte) (n int, err error) {
+	bs := int64(len(b.buf))
+	for len(p) > 0 {
+		if b.read >= b.want {
+			return n, io.EOF
+		}
+		idx := b.read / bs
+		if idx != b.bufIdx {
+			block := b.buf
+			if remain := b.want - idx*bs; remain < bs {
+				block = block[:remain]
+			}
+			b.fill(block, rand.New(rand.NewSource(b.seed^(idx*0x5851f42d4c957f2d))))
+			b.bufIdx = idx
+		}
+		toDo := b.buf[b.read%bs:]
+		if remain := b.want - b.read; int64(len(toDo)) > remain {
+			toDo = toDo[:remain]
+		}
+		copied := copy(p, toDo)
+		p = p[copied:]
+		b.read += int64(copied)
+		n += copied
+	}
+	return n, nil
+}
+
+// Seek implements io.Seeker to allow retries.
+func (b *blockStream) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	default:
+		return 0, errors.New("blockStream.Seek: invalid whence")
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += b.read
+	case io.SeekEnd:
+		offset += b.want
+	}
+	if offset < 0 {
+		return 0, errors.New("blockStream.Seek: negative position")
+	}
+	if offset > b.want {
+		return 0, io.EOF
+	}
+	b.read = offset
+	return b.read, nil
+}
+
+// newTextStream returns a stream of text data with the compression settings of the options.
+func newTextStream(o Options) *blockStream {
+	switch {
+	case o.compTarget.literals > 0:
+		// Blocks are a multiple of the segment size, so all segments have the same ratio.
+		return newBlockStream(streamBlockSize, func(dst []byte, rng *rand.Rand) {
+			genCompTargetData(dst, rng, o.compTarget.literals)
+		})
+	case o.compRatio > 0 && o.compWindow > 0:
+		// Each compression window has its own data, like genData.
+		return newBlockStream(int(o.compWindow), func(dst []byte, rng *rand.Rand) {
+			unique := len(dst) / o.compRatio
+			if unique == 0 {
+				unique = 1
+			}
+			rng.Read(dst[:unique])
+			for i := unique; i < len(dst); i += unique {
+				copy(dst[i:], dst[:unique])
+			}
+		})
+	}
+	return newBlockStream(streamBlockSize, func(dst []byte, rng *rand.Rand) {
+		rng.Read(dst)
+	})
+}
@@ -0,0 +1,80 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestWithStreaming(t *testing.T) {
+	const size = 64<<20 + 123
+	src, err := New(WithTextData().Apply(), WithSize(size), WithStreaming(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := src.Object()
+	first := make([]byte, 3<<20)
+	if _, err := io.ReadFull(obj.Reader, first); err != nil {
+		t.Fatal(err)
+	}
+	n, err := io.Copy(io.Discard, obj.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n+int64(len(first)) != size {
+		t.Fatalf("got %d bytes, want %d", n+int64(len(first)), size)
+	}
+	// Data must be the same after seeking.
+	if _, err := obj.Reader.Seek(1000, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	again := make([]byte, len(first)-1000)
+	if _, err := io.ReadFull(obj.Reader, again); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, first[1000:]) {
+		t.Error("data after seek does not match")
+	}
+	// New objects must have different data.
+	obj = src.Object()
+	next := make([]byte, 1000)
+	if _, err := io.ReadFull(obj.Reader, next); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(next, first[:1000]) {
+		t.Error("objects have the same data")
+	}
+
+	// Compressed data repeats within each window.
+	src, err = New(WithTextData().Apply(), WithSize(1<<20), WithStreaming(true), WithCompression(4), WithCompressionWindow(64<<10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(src.Object().Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 1<<20 {
+		t.Fatalf("got %d bytes", len(b))
+	}
+	if !bytes.Equal(b[:16<<10], b[16<<10:32<<10]) || bytes.Equal(b[:16<<10], b[64<<10:80<<10]) {
+		t.Error("unexpected repetition of compressible data")
+	}
+}
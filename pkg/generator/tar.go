@@ -0,0 +1,199 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// tarMaxSize is the maximum size of the member files of an archive.
+	tarMaxSize = 1 << 30
+
+	// tarFilesPerDir is the number of member files in each directory of an archive.
+	tarFilesPerDir = 100
+
+	// tarMaxBlock is the maximum size of the random data member files are copied from.
+	tarMaxBlock = 1 << 20
+)
+
+// WithTarData returns default TAR Opts.
+func WithTarData() TarOpts {
+	return tarOptsDefaults()
+}
+
+// Apply TAR data options.
+func (o TarOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		opts.tar = o
+		opts.src = newTar
+		return nil
+	}
+}
+
+func (o TarOpts) validate() error {
+	if o.files <= 0 {
+		return errors.New("tar: files <= 0")
+	}
+	if o.minSize < 0 || o.maxSize < o.minSize {
+		return fmt.Errorf("tar: invalid file size range %d-%d", o.minSize, o.maxSize)
+	}
+	if int64(o.files)*o.maxSize > tarMaxSize {
+		return fmt.Errorf("tar: %d files of up to %d bytes is more than %d bytes", o.files, o.maxSize, tarMaxSize)
+	}
+	return nil
+}
+
+// Files sets the number of member files in each archive.
+func (o TarOpts) Files(n int) TarOpts {
+	o.files = n
+	return o
+}
+
+// FileSize sets the range of the size of member files.
+func (o TarOpts) FileSize(minSize, maxSize int64) TarOpts {
+	o.minSize = minSize
+	o.maxSize = maxSize
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make usage predictable.
+func (o TarOpts) RngSeed(s int64) TarOpts {
+	o.seed = &s
+	return o
+}
+
+// TarOpts provides options for TAR archive generation.
+type TarOpts struct {
+	seed    *int64
+	files   int
+	minSize int64
+	maxSize int64
+}
+
+func tarOptsDefaults() TarOpts {
+	return TarOpts{
+		seed:    nil,
+		files:   100,
+		minSize: 1 << 10,
+		maxSize: 64 << 10,
+	}
+}
+
+type tarSource struct {
+	counter uint64
+	o       Options
+	buf     *circularBuffer
+	rng     *rand.Rand
+	obj     Object
+	out     bytes.Buffer
+	modTime time.Time
+
+	// block contains random data member files are copied from.
+	block []byte
+}
+
+func newTar(o Options) (Source, error) {
+	rndSrc := rand.NewSource(int64(rand.Uint64()))
+	if o.tar.seed != nil {
+		rndSrc = rand.NewSource(*o.tar.seed)
+	}
+	t := tarSource{
+		o:       o,
+		rng:     rand.New(rndSrc),
+		buf:     newCircularBuffer(nil, 0),
+		modTime: time.Now().Truncate(time.Second),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "application/x-tar",
+			Size:        0,
+		},
+	}
+	blockSize := o.tar.maxSize
+	if blockSize > tarMaxBlock {
+		blockSize = tarMaxBlock
+	}
+	t.block = make([]byte, blockSize+1)
+	t.rng.Read(t.block)
+	t.obj.setPrefix(o)
+	return &t, nil
+}
+
+// Object returns a tar archive of member files with random content.
+// Member files are named after the object, so archives extracted
+// by the server are stored below the object prefix.
+func (t *tarSource) Object() *Object {
+	atomic.AddUint64(&t.counter, 1)
+	opts := t.o.tar
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], t.rng)
+	t.obj.setName(fmt.Sprintf("%d.%s.tar", atomic.LoadUint64(&t.counter), string(nBuf[:])))
+	base := strings.TrimSuffix(t.obj.Name, ".tar")
+
+	t.out.Reset()
+	tw := tar.NewWriter(&t.out)
+	for i := 0; i < opts.files; i++ {
+		size := opts.minSize
+		if opts.maxSize > opts.minSize {
+			size += t.rng.Int63n(opts.maxSize - opts.minSize + 1)
+		}
+		hdr := tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     fmt.Sprintf("%s/d%03d/f%06d.bin", base, i/tarFilesPerDir, i),
+			Mode:     0o644,
+			Size:     size,
+			ModTime:  t.modTime,
+		}
+		// Writing to a bytes.Buffer with a correct header cannot fail.
+		_ = tw.WriteHeader(&hdr)
+		for size > 0 {
+			n := int64(len(t.block) - 1)
+			if n > size {
+				n = size
+			}
+			off := t.rng.Int63n(int64(len(t.block)) - n)
+			_, _ = tw.Write(t.block[off : off+n])
+			size -= n
+		}
+	}
+	_ = tw.Close()
+	t.buf.data = t.out.Bytes()
+	t.obj.Size = int64(t.out.Len())
+	t.obj.Reader = t.buf.Reset(t.obj.Size)
+	return &t.obj
+}
+
+func (t *tarSource) String() string {
+	opts := t.o.tar
+	return fmt.Sprintf("TAR archives; %d files of %d-%d bytes", opts.files, opts.minSize, opts.maxSize)
+}
+
+func (t *tarSource) Prefix() string {
+	return t.obj.Prefix
+}
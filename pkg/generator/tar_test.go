@@ -0,0 +1,93 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"archive/tar"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWithTarData(t *testing.T) {
+	tests := []TarOpts{
+		WithTarData(),
+		WithTarData().Files(1).FileSize(0, 0),
+		WithTarData().Files(250).FileSize(100, 100),
+		WithTarData().Files(3).FileSize(1<<20, 3<<20),
+	}
+	for _, opts := range tests {
+		src, err := New(opts.RngSeed(1).Apply(), WithPrefixSize(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			obj := src.Object()
+			if obj.ContentType != "application/x-tar" {
+				t.Errorf("content type %q", obj.ContentType)
+			}
+			base := strings.TrimSuffix(obj.Name, ".tar")
+			var read int64
+			tr := tar.NewReader(&countReader{r: obj.Reader, n: &read})
+			files := 0
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%+v: %v", opts, err)
+				}
+				if !strings.HasPrefix(hdr.Name, base+"/") {
+					t.Errorf("member %q not below %q", hdr.Name, base)
+				}
+				if hdr.Size < opts.minSize || hdr.Size > opts.maxSize {
+					t.Errorf("member size %d outside %d-%d", hdr.Size, opts.minSize, opts.maxSize)
+				}
+				n, err := io.Copy(io.Discard, tr)
+				if err != nil || n != hdr.Size {
+					t.Fatalf("read %d of %d bytes: %v", n, hdr.Size, err)
+				}
+				files++
+			}
+			if files != opts.files {
+				t.Errorf("got %d files, want %d", files, opts.files)
+			}
+			if n, _ := io.Copy(io.Discard, obj.Reader); read+n != obj.Size {
+				t.Errorf("got size %d, want %d", read+n, obj.Size)
+			}
+		}
+	}
+
+	for _, opts := range []TarOpts{WithTarData().Files(0), WithTarData().FileSize(10, 5), WithTarData().Files(1000).FileSize(0, 2<<20)} {
+		if _, err := New(opts.Apply()); err == nil {
+			t.Errorf("%+v: want error", opts)
+		}
+	}
+}
+
+type countReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	*c.n += int64(n)
+	return n, err
+}
@@ -18,7 +18,6 @@
 package generator
 
 import (
-	cRand "crypto/rand"
 	"errors"
 	"fmt"
 	"io"
@@ -80,8 +79,11 @@ type textSrc struct {
 	counter uint64
 	o       Options
 	buf     *circularBuffer
+	stream  *blockStream
 	rng     *rand.Rand
-	obj     Object
+	// fill generates the random data of objects.
+	fill *xoshiro256
+	obj  Object
 }
 
 func newText(o Options) (Source, error) {
@@ -107,9 +109,10 @@ func newText(o Options) (Source, error) {
 	}
 
 	t := textSrc{
-		o:   o,
-		rng: rng,
-		buf: newCircularBuffer(data, int64(size)),
+		o:    o,
+		rng:  rng,
+		fill: newRandomXoshiro256(),
+		buf:  newCircularBuffer(data, int64(size)),
 		obj: Object{
 			Reader:      nil,
 			Name:        "",
@@ -117,6 +120,12 @@ func newText(o Options) (Source, error) {
 			Size:        0,
 		},
 	}
+	if o.text.seed != nil {
+		t.fill = newXoshiro256(uint64(rng.Int63()))
+	}
+	if o.stream {
+		t.stream = newTextStream(o)
+	}
 	t.obj.setPrefix(o)
 	return &t, nil
 }
@@ -126,11 +135,27 @@ func (t *textSrc) Object() *Object {
 
 	t.obj.Size = t.o.getSize(t.rng)
 
+	if t.stream != nil {
+		var nBuf [16]byte
+		randASCIIBytes(nBuf[:], t.rng)
+		t.obj.setName(fmt.Sprintf("%d.%s.txt", atomic.LoadUint64(&t.counter), string(nBuf[:])))
+		t.obj.Reader = t.stream.Reset(t.rng.Int63(), t.obj.Size)
+		return &t.obj
+	}
+
 	// build data until the desired size.
-	builder := make([]byte, 0)
+	// The buffer of the previous object is reused.
+	builder := t.buf.data[:0]
+	if int64(cap(builder)) < t.obj.Size {
+		builder = make([]byte, 0, t.obj.Size)
+	}
+	if t.o.compTarget.literals > 0 {
+		builder = builder[:t.obj.Size]
+		genCompTargetData(builder, t.rng, t.o.compTarget.literals)
+	}
 	for int64(len(builder)) < t.obj.Size {
 		reqSize := t.obj.Size - int64(len(builder))
-		builder = append(builder, genData(reqSize, t.o.compRatio, t.o.compWindow)...)
+		builder = genData(builder, t.fill, reqSize, t.o.compRatio, t.o.compWindow)
 	}
 
 	t.buf.data = builder
@@ -144,8 +169,8 @@ func (t *textSrc) Object() *Object {
 	return &t.obj
 }
 
-// generates compressible data with the provided compression ratio.
-func genData(reqSize int64, compRatio int, compWindow int64) []byte {
+// genData appends compressible data with the provided compression ratio to dst.
+func genData(dst []byte, rng *xoshiro256, reqSize int64, compRatio int, compWindow int64) []byte {
 	var uniqueStrLen int64
 	var remStrLen int
 	var repeatUniqueStrLen int64
@@ -172,33 +197,40 @@ func genData(reqSize int64, compRatio int, compWindow int64) []byte {
 		repeatUniqueStrLen = uniqueStrLen
 	}
 
-	// build unique slice with random data; data will be incompressible
-	uniqueStr := make([]byte, uniqueStrLen)
-	_, err := cRand.Read(uniqueStr)
-	if err != nil {
-		fmt.Println("error:", err)
-		return nil
+	if uniqueStrLen == 0 {
+		return dst
 	}
-
-	builder := make([]byte, 0)
-
-	// repeat full unique string
-	for int64(len(builder)) != repeatUniqueStrLen {
-		builder = append(builder, uniqueStr...)
+	n := repeatUniqueStrLen + int64(remStrLen)
+	if int64(cap(dst)-len(dst)) < n {
+		grown := make([]byte, len(dst), int64(len(dst))+n)
+		copy(grown, dst)
+		dst = grown
 	}
+	start := len(dst)
+	dst = dst[:int64(start)+n]
+	builder := dst[start:]
 
-	// fill remaining length with part of unique string
-	for i := 0; i < remStrLen; i++ {
-		builder = append(builder, builder[i])
-	}
+	// build unique slice with random data; data will be incompressible
+	uniqueStr := builder[:uniqueStrLen]
+	rng.Read(uniqueStr)
 
-	return builder
+	// repeat full unique string, and fill remaining length with part of unique string
+	for i := uniqueStrLen; i < n; i += uniqueStrLen {
+		copy(builder[i:], uniqueStr)
+	}
+	return dst
 }
 
 func (t *textSrc) String() string {
+	if ct := t.o.compTarget; ct.literals > 0 {
+		return fmt.Sprintf("Text data; %s compression ratio %.2f (target %v); %d bytes total", ct.Algo, ct.measured, ct.Ratio, t.buf.want)
+	}
 	if t.o.randSize {
 		return fmt.Sprintf("Text data; random size up to %d bytes", t.o.totalSize)
 	}
+	if t.stream != nil {
+		return fmt.Sprintf("Text data, streamed; %d bytes total", t.buf.want)
+	}
 	return fmt.Sprintf("Text data; %d bytes total", t.buf.want)
 }
 
@@ -0,0 +1,73 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Limits of prefix trees.
+const (
+	maxTreeDepth  = 16
+	maxTreeFanout = 10000
+)
+
+// prefixTree picks folders like 'd03/d11/d07' in a tree of a fixed depth and fanout.
+type prefixTree struct {
+	depth, fanout int
+	// width is the number of digits of folder names.
+	width int
+}
+
+// WithPrefixTree places object names in a folder hierarchy depth levels deep,
+// where each folder has fanout subfolders, so names are spread over fanout^depth leaf folders.
+// Folders are named 'd' and a number, like 'd03/d11/d07'.
+// Names are below the prefix, or where {tree} is in the key template.
+// A depth of 0 disables the tree.
+func WithPrefixTree(depth, fanout int) Option {
+	return func(o *Options) error {
+		if depth == 0 {
+			o.prefixTree = nil
+			return nil
+		}
+		if depth < 0 || depth > maxTreeDepth {
+			return fmt.Errorf("prefix tree: depth must be 0 to %d", maxTreeDepth)
+		}
+		if fanout < 1 || fanout > maxTreeFanout {
+			return fmt.Errorf("prefix tree: fanout must be 1 to %d", maxTreeFanout)
+		}
+		o.prefixTree = &prefixTree{depth: depth, fanout: fanout, width: len(strconv.Itoa(fanout - 1))}
+		return nil
+	}
+}
+
+// pick returns a random leaf folder.
+func (t *prefixTree) pick(rng *rand.Rand) string {
+	var sb strings.Builder
+	sb.Grow(t.depth * (t.width + 2))
+	for i := 0; i < t.depth; i++ {
+		if i > 0 {
+			sb.WriteByte('/')
+		}
+		fmt.Fprintf(&sb, "d%0*d", t.width, rng.Intn(t.fanout))
+	}
+	return sb.String()
+}
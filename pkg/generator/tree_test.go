@@ -0,0 +1,71 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWithPrefixTree(t *testing.T) {
+	re := regexp.MustCompile(`^pfx/d\d{2}/d\d{2}/d\d{2}/\d+\..{16}\.rnd$`)
+	src, err := New(WithRandomData().Apply(), WithSize(10), WithCustomPrefix("pfx"), WithPrefixTree(3, 12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	folders := make(map[string]int)
+	const n = 20000
+	for i := 0; i < n; i++ {
+		name := src.Object().Name
+		if !re.MatchString(name) {
+			t.Fatalf("name %q does not match %s", name, re)
+		}
+		for _, f := range strings.Split(path.Dir(name), "/")[1:] {
+			if f > "d11" {
+				t.Fatalf("folder %s of %q outside fanout", f, name)
+			}
+		}
+		folders[path.Dir(name)]++
+	}
+	// 1728 leaf folders, most should be used.
+	if len(folders) < 1500 {
+		t.Errorf("%d leaf folders used, want about 1728", len(folders))
+	}
+
+	src, err = New(WithRandomData().Apply(), WithPrefixTree(2, 4), WithPartitions(10, 1),
+		WithKeyTemplate("{prefix}/{tree}/x/{partition}/{uuid}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := src.Object().Name; !regexp.MustCompile(`^d\d/d\d/x/year=`).MatchString(name) {
+		t.Errorf("name %q has no tree", name)
+	}
+	for _, opts := range [][]Option{
+		{WithPrefixTree(-1, 4)},
+		{WithPrefixTree(maxTreeDepth+1, 4)},
+		{WithPrefixTree(2, 0)},
+		{WithPrefixTree(2, 4), WithKeyTemplate("{uuid}")},
+		{WithKeyTemplate("{tree}/{uuid}")},
+	} {
+		if _, err := New(append(opts, WithRandomData().Apply())...); err == nil {
+			t.Errorf("%d options: want error", len(opts))
+		}
+	}
+}
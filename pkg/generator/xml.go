@@ -0,0 +1,283 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+)
+
+const (
+	xmlHeader   = "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<records>\n"
+	xmlTrailer  = "</records>\n"
+	xmlMaxDepth = 32
+
+	// xmlMaxElements is the maximum number of elements at the deepest level of a record.
+	xmlMaxElements = 1 << 20
+)
+
+// Element and attribute names and text of generated documents.
+var (
+	xmlElements   = []string{"customer", "order", "item", "address", "payment", "product", "entry", "header", "body", "detail", "line", "party"}
+	xmlAttributes = []string{"id", "type", "ref", "status", "code", "lang", "version", "currency", "unit", "region"}
+	xmlWords      = []string{"standard", "express", "pending", "approved", "north", "south", "retail", "wholesale", "primary", "secondary", "active", "closed", "invoice", "credit", "debit", "priority"}
+)
+
+// WithXMLData returns default XML Opts.
+func WithXMLData() XMLOpts {
+	return xmlOptsDefaults()
+}
+
+// Apply XML data options.
+func (o XMLOpts) Apply() Option {
+	return func(opts *Options) error {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		opts.xml = o
+		opts.src = newXML
+		return nil
+	}
+}
+
+func (o XMLOpts) validate() error {
+	if o.depth <= 0 {
+		return errors.New("xml: depth <= 0")
+	}
+	if o.depth > xmlMaxDepth {
+		return fmt.Errorf("xml: depth > %d", xmlMaxDepth)
+	}
+	if o.children <= 0 {
+		return errors.New("xml: children <= 0")
+	}
+	if o.attributes < 0 {
+		return errors.New("xml: attributes < 0")
+	}
+	elements := 1
+	for i := 1; i < o.depth; i++ {
+		elements *= o.children
+		if elements > xmlMaxElements {
+			return fmt.Errorf("xml: more than %d elements in each record", xmlMaxElements)
+		}
+	}
+	return nil
+}
+
+// Depth sets the nesting depth of elements in each record.
+// A depth of 1 gives records with text only.
+func (o XMLOpts) Depth(n int) XMLOpts {
+	o.depth = n
+	return o
+}
+
+// Children sets the number of child elements of each element above the deepest level.
+func (o XMLOpts) Children(n int) XMLOpts {
+	o.children = n
+	return o
+}
+
+// Attributes sets the average number of attributes of each element.
+func (o XMLOpts) Attributes(avg float64) XMLOpts {
+	o.attributes = avg
+	return o
+}
+
+// RngSeed will which to a fixed RNG seed to make usage predictable.
+func (o XMLOpts) RngSeed(s int64) XMLOpts {
+	o.seed = &s
+	return o
+}
+
+// XMLOpts provides options for XML generation.
+type XMLOpts struct {
+	seed       *int64
+	depth      int
+	children   int
+	attributes float64
+}
+
+func xmlOptsDefaults() XMLOpts {
+	return XMLOpts{
+		seed:       nil,
+		depth:      3,
+		children:   3,
+		attributes: 1.5,
+	}
+}
+
+type xmlSource struct {
+	counter uint64
+	o       Options
+	buf     *circularBuffer
+	rng     *rand.Rand
+	obj     Object
+	rec     []byte
+}
+
+func newXML(o Options) (Source, error) {
+	rndSrc := rand.NewSource(int64(rand.Uint64()))
+	if o.xml.seed != nil {
+		rndSrc = rand.NewSource(*o.xml.seed)
+	}
+	x := xmlSource{
+		o:   o,
+		rng: rand.New(rndSrc),
+		buf: newCircularBuffer(make([]byte, 0, o.totalSize), o.totalSize),
+		obj: Object{
+			Reader:      nil,
+			Name:        "",
+			ContentType: "application/xml",
+			Size:        0,
+		},
+	}
+	x.obj.setPrefix(o)
+	return &x, nil
+}
+
+// Object returns an XML document with record elements in a records element.
+// The document is padded with whitespace to the object size.
+// Objects too small for the root element are whitespace only and not valid XML.
+func (x *xmlSource) Object() *Object {
+	atomic.AddUint64(&x.counter, 1)
+	x.obj.Size = x.o.getSize(x.rng)
+	size := int(x.obj.Size)
+
+	// With compression the first records are repeated.
+	unique := size
+	if x.o.compRatio > 0 {
+		if int64(unique) > x.o.compWindow {
+			unique = int(x.o.compWindow)
+		}
+		unique /= x.o.compRatio
+	}
+
+	dst := x.buf.data[:0]
+	if size >= len(xmlHeader)+len(xmlTrailer) {
+		dst = append(dst, xmlHeader...)
+		end := size - len(xmlTrailer)
+		// recs contains the start and end offset of each unique record.
+		var recs [][2]int
+		for len(dst) < unique || len(recs) == 0 {
+			x.rec = x.appendElement(x.rec[:0], "record", x.o.xml.depth, 0)
+			x.rec = append(x.rec, '\n')
+			if len(dst)+len(x.rec) > end {
+				break
+			}
+			recs = append(recs, [2]int{len(dst), len(dst) + len(x.rec)})
+			dst = append(dst, x.rec...)
+		}
+		for i := 0; len(recs) > 0; i++ {
+			r := recs[i%len(recs)]
+			if len(dst)+r[1]-r[0] > end {
+				break
+			}
+			dst = append(dst, dst[r[0]:r[1]]...)
+		}
+		for len(dst) < end {
+			dst = append(dst, '\n')
+		}
+		dst = append(dst, xmlTrailer...)
+	}
+	for len(dst) < size {
+		dst = append(dst, '\n')
+	}
+	x.buf.data = dst
+
+	var nBuf [16]byte
+	randASCIIBytes(nBuf[:], x.rng)
+	x.obj.setName(fmt.Sprintf("%d.%s.xml", atomic.LoadUint64(&x.counter), string(nBuf[:])))
+
+	x.obj.Reader = x.buf.Reset(x.obj.Size)
+	return &x.obj
+}
+
+// appendElement appends an element with attributes and either children or text.
+func (x *xmlSource) appendElement(dst []byte, name string, depth, indent int) []byte {
+	rng := x.rng
+	opts := x.o.xml
+	for i := 0; i < indent; i++ {
+		dst = append(dst, ' ', ' ')
+	}
+	dst = append(dst, '<')
+	dst = append(dst, name...)
+	// Pick attributes so the average is the configured density.
+	n := int(opts.attributes)
+	if rng.Float64() < opts.attributes-float64(n) {
+		n++
+	}
+	if n > len(xmlAttributes) {
+		n = len(xmlAttributes)
+	}
+	first := rng.Intn(len(xmlAttributes))
+	for i := 0; i < n; i++ {
+		dst = append(dst, ' ')
+		dst = append(dst, xmlAttributes[(first+i)%len(xmlAttributes)]...)
+		dst = append(dst, `="`...)
+		if rng.Intn(2) == 0 {
+			dst = strconv.AppendInt(dst, int64(rng.Intn(100000)), 10)
+		} else {
+			dst = append(dst, xmlWords[rng.Intn(len(xmlWords))]...)
+		}
+		dst = append(dst, '"')
+	}
+	dst = append(dst, '>')
+	if depth <= 1 {
+		switch rng.Intn(3) {
+		case 0:
+			dst = strconv.AppendFloat(dst, rng.Float64()*10000, 'f', 2, 64)
+		case 1:
+			dst = append(dst, xmlWords[rng.Intn(len(xmlWords))]...)
+			dst = append(dst, " &amp; "...)
+			dst = append(dst, xmlWords[rng.Intn(len(xmlWords))]...)
+		default:
+			dst = append(dst, xmlWords[rng.Intn(len(xmlWords))]...)
+			dst = append(dst, ' ')
+			dst = strconv.AppendInt(dst, int64(rng.Intn(1000)), 10)
+		}
+	} else {
+		dst = append(dst, '\n')
+		for i := 0; i < opts.children; i++ {
+			dst = x.appendElement(dst, xmlElements[rng.Intn(len(xmlElements))], depth-1, indent+1)
+		}
+		for i := 0; i < indent; i++ {
+			dst = append(dst, ' ', ' ')
+		}
+	}
+	dst = append(dst, "</"...)
+	dst = append(dst, name...)
+	dst = append(dst, '>')
+	if indent > 0 {
+		dst = append(dst, '\n')
+	}
+	return dst
+}
+
+func (x *xmlSource) String() string {
+	opts := x.o.xml
+	if x.o.randSize {
+		return fmt.Sprintf("XML data; depth %d, %d children, %.1f attributes, random size up to %d bytes", opts.depth, opts.children, opts.attributes, x.o.totalSize)
+	}
+	return fmt.Sprintf("XML data; depth %d, %d children, %.1f attributes, %d bytes total", opts.depth, opts.children, opts.attributes, x.o.totalSize)
+}
+
+func (x *xmlSource) Prefix() string {
+	return x.obj.Prefix
+}
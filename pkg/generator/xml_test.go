@@ -0,0 +1,91 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestWithXMLData(t *testing.T) {
+	tests := []struct {
+		opts XMLOpts
+		size int64
+	}{
+		{opts: WithXMLData(), size: 100 << 10},
+		{opts: WithXMLData().Depth(1).Attributes(0), size: 1000},
+		{opts: WithXMLData().Depth(5).Children(2).Attributes(3.5), size: 1 << 20},
+	}
+	for _, tt := range tests {
+		src, err := New(tt.opts.RngSeed(1).Apply(), WithSize(tt.size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		obj := src.Object()
+		if obj.ContentType != "application/xml" {
+			t.Errorf("content type %q", obj.ContentType)
+		}
+		b, err := io.ReadAll(obj.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(b)) != tt.size {
+			t.Fatalf("got size %d, want %d", len(b), tt.size)
+		}
+		dec := xml.NewDecoder(bytes.NewReader(b))
+		depth, maxDepth, elements, attrs := 0, 0, 0, 0
+		for {
+			tok, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%+v: %v", tt.opts, err)
+			}
+			switch tok := tok.(type) {
+			case xml.StartElement:
+				depth++
+				if depth > maxDepth {
+					maxDepth = depth
+				}
+				if depth > 1 {
+					elements++
+					attrs += len(tok.Attr)
+				}
+			case xml.EndElement:
+				depth--
+			}
+		}
+		// The records element is the root.
+		if maxDepth != tt.opts.depth+1 {
+			t.Errorf("%+v: got depth %d", tt.opts, maxDepth-1)
+		}
+		if avg := float64(attrs) / float64(elements); math.Abs(avg-tt.opts.attributes) > 0.1 {
+			t.Errorf("%+v: got %.2f attributes per element", tt.opts, avg)
+		}
+	}
+
+	for _, opts := range []XMLOpts{WithXMLData().Depth(0), WithXMLData().Children(0), WithXMLData().Attributes(-1), WithXMLData().Depth(30).Children(10)} {
+		if _, err := New(opts.Apply()); err == nil {
+			t.Errorf("%+v: want error", opts)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	cRand "crypto/rand"
+	"encoding/binary"
+	"math/bits"
+)
+
+// xoshiro256 is a xoshiro256** pseudo random generator.
+// It fills buffers many times faster than crypto/rand and math/rand,
+// but must never be used where random data must be unpredictable.
+type xoshiro256 [4]uint64
+
+// newXoshiro256 returns a generator seeded with the seed.
+func newXoshiro256(seed uint64) *xoshiro256 {
+	var x xoshiro256
+	// Expand the seed with splitmix64, which never gives an all zero state.
+	for i := range x {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		x[i] = z ^ (z >> 31)
+	}
+	return &x
+}
+
+// newRandomXoshiro256 returns a generator seeded from crypto/rand.
+func newRandomXoshiro256() *xoshiro256 {
+	var seed [8]byte
+	if _, err := cRand.Read(seed[:]); err != nil {
+		panic(err)
+	}
+	return newXoshiro256(binary.LittleEndian.Uint64(seed[:]))
+}
+
+// Uint64 returns the next pseudo random number.
+func (x *xoshiro256) Uint64() uint64 {
+	res := bits.RotateLeft64(x[1]*5, 7) * 9
+	t := x[1] << 17
+	x[2] ^= x[0]
+	x[3] ^= x[1]
+	x[1] ^= x[2]
+	x[0] ^= x[3]
+	x[2] ^= t
+	x[3] = bits.RotateLeft64(x[3], 45)
+	return res
+}
+
+// Read fills p with pseudo random data. It never returns an error.
+func (x *xoshiro256) Read(p []byte) (int, error) {
+	n := len(p)
+	// Keeping the state in local variables is much faster.
+	s0, s1, s2, s3 := x[0], x[1], x[2], x[3]
+	for ; len(p) >= 8; p = p[8:] {
+		binary.LittleEndian.PutUint64(p, bits.RotateLeft64(s1*5, 7)*9)
+		t := s1 << 17
+		s2 ^= s0
+		s3 ^= s1
+		s1 ^= s2
+		s0 ^= s3
+		s2 ^= t
+		s3 = bits.RotateLeft64(s3, 45)
+	}
+	*x = xoshiro256{s0, s1, s2, s3}
+	if len(p) > 0 {
+		var tmp [8]byte
+		binary.LittleEndian.PutUint64(tmp[:], x.Uint64())
+		copy(p, tmp[:])
+	}
+	return n, nil
+}
@@ -0,0 +1,99 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package generator
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestXoshiro256(t *testing.T) {
+	a, b := make([]byte, 1001), make([]byte, 1001)
+	newXoshiro256(1).Read(a)
+	newXoshiro256(1).Read(b)
+	if !bytes.Equal(a, b) {
+		t.Error("same seed gives different data")
+	}
+	newXoshiro256(2).Read(b)
+	if bytes.Equal(a, b) {
+		t.Error("different seeds give the same data")
+	}
+}
+
+// TestTextDataThroughput checks that text data is generated at several GB/s on one core.
+// Timing depends on the machine, so the test only runs when WARP_TEST_THROUGHPUT is set.
+// Use BenchmarkTextData for actual numbers.
+func TestTextDataThroughput(t *testing.T) {
+	if os.Getenv("WARP_TEST_THROUGHPUT") == "" {
+		t.Skip("WARP_TEST_THROUGHPUT not set")
+	}
+	if raceEnabled {
+		t.Skip("skipping throughput test with race detector")
+	}
+	const size = 16 << 20
+	const minBytesPerSec = 2 << 30
+	src, err := New(WithTextData().Apply(), WithSize(size))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The fastest of several runs is used, so pauses only fail the test if they affect every run.
+	var best time.Duration
+	for i := 0; i < 8; i++ {
+		start := time.Now()
+		if _, err := io.Copy(ioutil.Discard, src.Object().Reader); err != nil {
+			t.Fatal(err)
+		}
+		if d := time.Since(start); i == 0 || d < best {
+			best = d
+		}
+	}
+	got := float64(size) / best.Seconds()
+	t.Logf("text data: %.2f GiB/s", got/(1<<30))
+	if got < minBytesPerSec {
+		t.Errorf("text data generated at %.2f GiB/s, want at least %d GiB/s", got/(1<<30), minBytesPerSec>>30)
+	}
+}
+
+func BenchmarkTextData(b *testing.B) {
+	const size = 1 << 20
+	src, err := New(WithTextData().Apply(), WithSize(size))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(size)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := io.Copy(ioutil.Discard, src.Object().Reader); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkXoshiro256(b *testing.B) {
+	buf := make([]byte, 1<<20)
+	x := newXoshiro256(0)
+	b.SetBytes(int64(len(buf)))
+	for i := 0; i < b.N; i++ {
+		x.Read(buf)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package loopback
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// errMalformedChunk is returned when aws-chunked content cannot be parsed.
+var errMalformedChunk = errors.New("malformed aws-chunked content")
+
+// chunkedReader decodes aws-chunked content.
+// Chunk signatures are not verified and trailers are ignored.
+type chunkedReader struct {
+	r *bufio.Reader
+	// n is the remaining bytes of the current chunk.
+	n int64
+	// started is set when the first chunk header has been read.
+	started bool
+	done    bool
+}
+
+func newChunkedReader(r io.Reader) *chunkedReader {
+	return &chunkedReader{r: bufio.NewReader(r)}
+}
+
+// Read implements io.Reader.
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	if c.done {
+		return 0, io.EOF
+	}
+	if c.n == 0 {
+		if c.started {
+			// Skip CRLF after chunk data.
+			if err := c.readCRLF(); err != nil {
+				return 0, err
+			}
+		}
+		c.started = true
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return 0, errMalformedChunk
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if i := strings.IndexByte(line, ';'); i >= 0 {
+			line = line[:i]
+		}
+		n, err := strconv.ParseInt(line, 16, 64)
+		if err != nil || n < 0 {
+			return 0, errMalformedChunk
+		}
+		if n == 0 {
+			c.done = true
+			return 0, io.EOF
+		}
+		c.n = n
+	}
+	if int64(len(p)) > c.n {
+		p = p[:c.n]
+	}
+	n, err := c.r.Read(p)
+	c.n -= int64(n)
+	if err == io.EOF {
+		if c.n > 0 {
+			return n, io.ErrUnexpectedEOF
+		}
+		err = nil
+	}
+	return n, err
+}
+
+func (c *chunkedReader) readCRLF() error {
+	var b [2]byte
+	if _, err := io.ReadFull(c.r, b[:]); err != nil || b != [2]byte{'\r', '\n'} {
+		return errMalformedChunk
+	}
+	return nil
+}
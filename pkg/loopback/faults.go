@@ -0,0 +1,193 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package loopback
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Distributions of the latency added to responses.
+const (
+	LatencyFixed       = "fixed"
+	LatencyUniform     = "uniform"
+	LatencyExponential = "exponential"
+	LatencyLogNormal   = "lognormal"
+)
+
+// faultStatus is the HTTP status of the error codes that can be injected.
+var faultStatus = map[string]int{
+	"InternalError":      http.StatusInternalServerError,
+	"SlowDown":           http.StatusServiceUnavailable,
+	"ServiceUnavailable": http.StatusServiceUnavailable,
+	"AccessDenied":       http.StatusForbidden,
+	"RequestTimeout":     http.StatusBadRequest,
+}
+
+// Faults emulate the think time and failures of a real server.
+type Faults struct {
+	// Latency is the average time added before each response.
+	Latency time.Duration
+	// Dist is the distribution of the latency. LatencyFixed is used if empty.
+	// Uniform latency is from 0 to twice the average,
+	// and log-normal latency has a sigma of 1, so a few responses are much slower.
+	Dist string
+	// ErrorRate is the fraction of requests, 0 to 1, answered with an error.
+	ErrorRate float64
+	// Errors is a weighted list of the S3 error codes returned,
+	// like '80% SlowDown, 20% InternalError'. InternalError is used if empty.
+	Errors string
+}
+
+// faults are the parsed faults of a server.
+type faults struct {
+	Faults
+	codes []string
+	// cum contains the cumulative weight of codes.
+	cum []float64
+}
+
+// SetFaults makes the server add latency to responses and answer a part of the requests with errors.
+// It must be called before requests are sent.
+func (s *Server) SetFaults(f Faults) error {
+	if f.Dist == "" {
+		f.Dist = LatencyFixed
+	}
+	switch f.Dist {
+	case LatencyFixed, LatencyUniform, LatencyExponential, LatencyLogNormal:
+	default:
+		return fmt.Errorf("unknown latency distribution %q; supported: %s, %s, %s, %s", f.Dist, LatencyFixed, LatencyUniform, LatencyExponential, LatencyLogNormal)
+	}
+	if f.Latency < 0 {
+		return errors.New("latency must be >= 0")
+	}
+	if f.ErrorRate < 0 || f.ErrorRate > 1 || math.IsNaN(f.ErrorRate) {
+		return errors.New("error rate must be 0 to 1")
+	}
+	if strings.TrimSpace(f.Errors) == "" {
+		f.Errors = "InternalError"
+	}
+	p := faults{Faults: f}
+	total := 0.0
+	for _, entry := range strings.Split(f.Errors, ",") {
+		fields := strings.Fields(entry)
+		weight := 1.0
+		switch len(fields) {
+		case 1:
+		case 2:
+			var err error
+			weight, err = strconv.ParseFloat(strings.TrimSuffix(fields[0], "%"), 64)
+			if err != nil || weight < 0 {
+				return fmt.Errorf("errors: invalid weight %q", fields[0])
+			}
+			fields = fields[1:]
+		default:
+			return fmt.Errorf("errors: want weight and error code, got %q", strings.TrimSpace(entry))
+		}
+		if _, ok := faultStatus[fields[0]]; !ok {
+			return fmt.Errorf("errors: unsupported error code %q", fields[0])
+		}
+		total += weight
+		p.codes = append(p.codes, fields[0])
+		p.cum = append(p.cum, total)
+	}
+	if total <= 0 {
+		return errors.New("errors: total weight is 0")
+	}
+	if f.Latency == 0 && f.ErrorRate == 0 {
+		s.faults = nil
+		return nil
+	}
+	s.faults = &p
+	return nil
+}
+
+// String returns a description of the faults.
+func (f Faults) String() string {
+	var res []string
+	if f.Latency > 0 {
+		dist := f.Dist
+		if dist == "" {
+			dist = LatencyFixed
+		}
+		res = append(res, fmt.Sprintf("%v %s latency", f.Latency, dist))
+	}
+	if f.ErrorRate > 0 {
+		errs := f.Errors
+		if strings.TrimSpace(errs) == "" {
+			errs = "InternalError"
+		}
+		res = append(res, fmt.Sprintf("%g%% errors (%s)", 100*f.ErrorRate, errs))
+	}
+	return strings.Join(res, ", ")
+}
+
+// delay returns a random latency from the distribution.
+func (f *faults) delay() time.Duration {
+	mean := float64(f.Latency)
+	switch f.Dist {
+	case LatencyUniform:
+		return time.Duration(rand.Float64() * 2 * mean)
+	case LatencyExponential:
+		return time.Duration(rand.ExpFloat64() * mean)
+	case LatencyLogNormal:
+		// With sigma 1 the mean is exp(mu + 1/2).
+		return time.Duration(math.Exp(rand.NormFloat64()-0.5) * mean)
+	}
+	return f.Latency
+}
+
+// code returns a random error code, or an empty string if the request should succeed.
+func (f *faults) code() string {
+	if f.ErrorRate == 0 || rand.Float64() >= f.ErrorRate {
+		return ""
+	}
+	v := rand.Float64() * f.cum[len(f.cum)-1]
+	i := sort.SearchFloat64s(f.cum, v)
+	if i >= len(f.codes) {
+		i = len(f.codes) - 1
+	}
+	return f.codes[i]
+}
+
+// applyFaults waits for the latency of the request and returns an error response if one was picked.
+// The response is nil if the request should be answered normally.
+func (s *Server) applyFaults(req *http.Request) (*http.Response, error) {
+	f := s.faults
+	if d := f.delay(); d > 0 {
+		t := time.NewTimer(d)
+		select {
+		case <-t.C:
+		case <-req.Context().Done():
+			t.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+	code := f.code()
+	if code == "" {
+		return nil, nil
+	}
+	return s.errorResponse(req, faultStatus[code], code, "Injected by the loopback server", "", "")
+}
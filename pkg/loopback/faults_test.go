@@ -0,0 +1,102 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package loopback
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+func TestFaults(t *testing.T) {
+	srv := New(1 << 20)
+	cl, err := minio.New("127.0.0.1:9000", &minio.Options{
+		Creds:     credentials.NewStaticV4("access", "secretsecret", ""),
+		Transport: srv,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := srv.SetFaults(Faults{Latency: 20 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := cl.MakeBucket(ctx, "faults", minio.MakeBucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if took := time.Since(start); took < 20*time.Millisecond {
+		t.Errorf("request took %v, want at least 20ms", took)
+	}
+
+	// AccessDenied is not retried by the client.
+	if err := srv.SetFaults(Faults{ErrorRate: 1, Errors: "AccessDenied"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = cl.StatObject(ctx, "faults", "object", minio.StatObjectOptions{})
+	if code := minio.ToErrorResponse(err).StatusCode; code != 403 {
+		t.Errorf("got %v, want status 403", err)
+	}
+	if err := srv.SetFaults(Faults{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cl.BucketExists(ctx, "faults"); err != nil {
+		t.Errorf("faults not removed: %v", err)
+	}
+
+	for _, dist := range []string{LatencyFixed, LatencyUniform, LatencyExponential, LatencyLogNormal} {
+		f := faults{Faults: Faults{Latency: time.Millisecond, Dist: dist}}
+		var total time.Duration
+		const n = 100000
+		for i := 0; i < n; i++ {
+			d := f.delay()
+			if d < 0 {
+				t.Fatalf("%s: negative delay %v", dist, d)
+			}
+			total += d
+		}
+		if mean := total / n; mean < 950*time.Microsecond || mean > 1050*time.Microsecond {
+			t.Errorf("%s: mean %v, want 1ms", dist, mean)
+		}
+	}
+
+	f := faults{Faults: Faults{ErrorRate: 0.5}, codes: []string{"SlowDown", "InternalError"}, cum: []float64{3, 4}}
+	counts := make(map[string]int)
+	for i := 0; i < 40000; i++ {
+		counts[f.code()]++
+	}
+	if counts[""] < 19000 || counts[""] > 21000 || counts["SlowDown"] < 14000 || counts["SlowDown"] > 16000 {
+		t.Errorf("got codes %v", counts)
+	}
+
+	for _, bad := range []Faults{
+		{Latency: -1},
+		{Dist: "normal"},
+		{ErrorRate: 2},
+		{ErrorRate: 0.1, Errors: "NoSuchKey"},
+		{ErrorRate: 0.1, Errors: "0% SlowDown"},
+		{ErrorRate: 0.1, Errors: "x SlowDown"},
+	} {
+		if err := srv.SetFaults(bad); err == nil {
+			t.Errorf("%+v: want error", bad)
+		}
+	}
+}
@@ -0,0 +1,1087 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package loopback contains an in-memory S3 server used in place of the network.
+// Requests are answered by the http.RoundTripper directly,
+// so benchmarks against it measure the overhead of the client.
+//
+// Only the operations used by benchmarks are supported. Other operations
+// return NotImplemented. Signatures are not verified.
+package loopback
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Server is an in-memory S3 server.
+type Server struct {
+	maxStored int64
+	stored    int64
+	seq       uint64
+
+	mu      sync.RWMutex
+	buckets map[string]*bucket
+
+	// faults are added to responses if set.
+	faults *faults
+}
+
+type bucket struct {
+	versioning string
+	// objects contains the versions of each key, oldest first.
+	objects map[string][]*object
+	uploads map[string]*upload
+	// keys is objects keys in sorted order if !dirty.
+	keys  []string
+	dirty bool
+}
+
+type object struct {
+	key          string
+	versionID    string
+	deleteMarker bool
+	modTime      time.Time
+	etag         string
+	size         int64
+	// data is the content of the object.
+	// If nil the content is size zeros.
+	data        []byte
+	contentType string
+	meta        http.Header
+	parts       []int64
+}
+
+type upload struct {
+	key         string
+	contentType string
+	meta        http.Header
+	parts       map[int]*object
+}
+
+// New returns a server.
+// Up to maxStored bytes of object content is kept.
+// Content uploaded beyond the limit is discarded and read back as zeros.
+func New(maxStored int64) *Server {
+	return &Server{
+		maxStored: maxStored,
+		buckets:   make(map[string]*bucket),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (s *Server) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	if s.faults != nil {
+		if resp, err := s.applyFaults(req); resp != nil || err != nil {
+			return resp, err
+		}
+	}
+	path := strings.TrimPrefix(req.URL.Path, "/")
+	bucketName, key := path, ""
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		bucketName, key = path[:i], path[i+1:]
+	}
+	q := req.URL.Query()
+	switch {
+	case bucketName == "", bucketName == "minio":
+		return s.notImplemented(req)
+	case key == "":
+		return s.bucketRequest(req, bucketName, q)
+	}
+	return s.objectRequest(req, bucketName, key, q)
+}
+
+// bucketRequest handles requests on a bucket.
+func (s *Server) bucketRequest(req *http.Request, name string, q map[string][]string) (*http.Response, error) {
+	has := func(k string) bool { _, ok := q[k]; return ok }
+	get := func(k string) string {
+		if v := q[k]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	switch req.Method {
+	case http.MethodHead:
+		s.mu.RLock()
+		_, ok := s.buckets[name]
+		s.mu.RUnlock()
+		if !ok {
+			return s.errorResponse(req, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist", name, "")
+		}
+		return s.response(req, http.StatusOK, nil, nil, 0)
+	case http.MethodPut:
+		if has("versioning") {
+			var cfg struct {
+				Status string
+			}
+			if err := xml.NewDecoder(req.Body).Decode(&cfg); err != nil {
+				return s.errorResponse(req, http.StatusBadRequest, "MalformedXML", err.Error(), name, "")
+			}
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			b, ok := s.buckets[name]
+			if !ok {
+				return s.errorResponse(req, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist", name, "")
+			}
+			b.versioning = cfg.Status
+			return s.response(req, http.StatusOK, nil, nil, 0)
+		}
+		if len(q) > 0 || req.Header.Get("x-amz-bucket-object-lock-enabled") == "true" {
+			return s.notImplemented(req)
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.buckets[name]; ok {
+			return s.errorResponse(req, http.StatusConflict, "BucketAlreadyOwnedByYou", "Your previous request to create the named bucket succeeded and you already own it", name, "")
+		}
+		s.buckets[name] = &bucket{
+			objects: make(map[string][]*object),
+			uploads: make(map[string]*upload),
+		}
+		return s.response(req, http.StatusOK, nil, nil, 0)
+	case http.MethodDelete:
+		if len(q) > 0 {
+			return s.notImplemented(req)
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		b, ok := s.buckets[name]
+		if !ok {
+			return s.errorResponse(req, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist", name, "")
+		}
+		if len(b.objects) > 0 {
+			return s.errorResponse(req, http.StatusConflict, "BucketNotEmpty", "The bucket you tried to delete is not empty", name, "")
+		}
+		for _, u := range b.uploads {
+			for _, p := range u.parts {
+				s.release(p)
+			}
+		}
+		delete(s.buckets, name)
+		return s.response(req, http.StatusNoContent, nil, nil, 0)
+	case http.MethodGet:
+		switch {
+		case has("location"):
+			return s.xmlResponse(req, struct {
+				XMLName xml.Name `xml:"LocationConstraint"`
+			}{})
+		case has("versioning"):
+			s.mu.RLock()
+			b, ok := s.buckets[name]
+			var status string
+			if ok {
+				status = b.versioning
+			}
+			s.mu.RUnlock()
+			if !ok {
+				return s.errorResponse(req, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist", name, "")
+			}
+			return s.xmlResponse(req, struct {
+				XMLName xml.Name `xml:"VersioningConfiguration"`
+				Status  string   `xml:",omitempty"`
+			}{Status: status})
+		case has("session"):
+			return s.createSession(req, name)
+		case has("versions"):
+			return s.listVersions(req, name, get("prefix"), get("delimiter"), get("key-marker"), maxKeys(get("max-keys")))
+		case get("list-type") == "2":
+			marker := get("continuation-token")
+			if marker == "" {
+				marker = get("start-after")
+			}
+			return s.listObjects(req, name, get("prefix"), get("delimiter"), marker, maxKeys(get("max-keys")))
+		}
+	case http.MethodPost:
+		if has("delete") {
+			return s.deleteObjects(req, name)
+		}
+	}
+	return s.notImplemented(req)
+}
+
+// sessionDuration is how long credentials returned by CreateSession are valid.
+const sessionDuration = 5 * time.Minute
+
+// createSession returns bucket scoped session credentials.
+func (s *Server) createSession(req *http.Request, name string) (*http.Response, error) {
+	s.mu.RLock()
+	_, ok := s.buckets[name]
+	s.mu.RUnlock()
+	if !ok {
+		return s.errorResponse(req, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist", name, "")
+	}
+	type credentials struct {
+		SessionToken    string
+		SecretAccessKey string
+		AccessKeyID     string `xml:"AccessKeyId"`
+		Expiration      time.Time
+	}
+	return s.xmlResponse(req, struct {
+		XMLName     xml.Name `xml:"CreateSessionResult"`
+		Credentials credentials
+	}{Credentials: credentials{
+		SessionToken:    "session-" + s.newID(),
+		SecretAccessKey: s.newID(),
+		AccessKeyID:     "ASIA" + strings.ToUpper(s.newID()),
+		Expiration:      time.Now().Add(sessionDuration).UTC().Truncate(time.Second),
+	}})
+}
+
+// maxKeyLength is the longest object key accepted, in bytes.
+const maxKeyLength = 1024
+
+// objectRequest handles requests on an object.
+func (s *Server) objectRequest(req *http.Request, bucketName, key string, q map[string][]string) (*http.Response, error) {
+	has := func(k string) bool { _, ok := q[k]; return ok }
+	get := func(k string) string {
+		if v := q[k]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	for _, sub := range []string{"select", "tagging", "retention", "legal-hold", "acl", "attributes", "restore", "torrent"} {
+		if has(sub) {
+			return s.notImplemented(req)
+		}
+	}
+	if len(key) > maxKeyLength {
+		return s.errorResponse(req, http.StatusBadRequest, "KeyTooLongError", "Your key is too long", bucketName, "")
+	}
+	versionID := get("versionId")
+	if versionID == "null" {
+		versionID = ""
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return s.getObject(req, bucketName, key, versionID, has("versionId"), get("partNumber"))
+	case http.MethodPut:
+		if req.Header.Get("x-amz-copy-source") != "" {
+			return s.notImplemented(req)
+		}
+		if has("uploadId") {
+			return s.putPart(req, bucketName, key, get("uploadId"), get("partNumber"))
+		}
+		return s.putObject(req, bucketName, key)
+	case http.MethodPost:
+		switch {
+		case has("uploads"):
+			return s.newUpload(req, bucketName, key)
+		case has("uploadId"):
+			return s.completeUpload(req, bucketName, key, get("uploadId"))
+		}
+	case http.MethodDelete:
+		if has("uploadId") {
+			return s.abortUpload(req, bucketName, key, get("uploadId"))
+		}
+		return s.deleteObject(req, bucketName, key, versionID, has("versionId"))
+	}
+	return s.notImplemented(req)
+}
+
+// getObject returns the content or metadata of an object.
+func (s *Server) getObject(req *http.Request, bucketName, key, versionID string, hasVersion bool, partNumber string) (*http.Response, error) {
+	s.mu.RLock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		s.mu.RUnlock()
+		return s.errorResponse(req, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist", bucketName, "")
+	}
+	obj := b.find(key, versionID, hasVersion)
+	versioned := b.versioning != ""
+	s.mu.RUnlock()
+	if obj == nil {
+		if hasVersion {
+			return s.errorResponse(req, http.StatusNotFound, "NoSuchVersion", "The specified version does not exist", bucketName, key)
+		}
+		return s.errorResponse(req, http.StatusNotFound, "NoSuchKey", "The specified key does not exist", bucketName, key)
+	}
+	h := obj.header(versioned)
+	if obj.deleteMarker {
+		return s.errorResponse(req, http.StatusNotFound, "NoSuchKey", "The specified key does not exist", bucketName, key, h)
+	}
+
+	start, length := int64(0), obj.size
+	status := http.StatusOK
+	if partNumber != "" {
+		n, err := strconv.Atoi(partNumber)
+		parts := obj.parts
+		if len(parts) == 0 {
+			parts = []int64{obj.size}
+		}
+		if err != nil || n < 1 || n > len(parts) {
+			return s.errorResponse(req, http.StatusRequestedRangeNotSatisfiable, "InvalidPartNumber", "The requested partnumber is not satisfiable", bucketName, key)
+		}
+		for _, p := range parts[:n-1] {
+			start += p
+		}
+		length = parts[n-1]
+		h.Set("x-amz-mp-parts-count", strconv.Itoa(len(parts)))
+		status = http.StatusPartialContent
+	} else if rng := req.Header.Get("Range"); rng != "" {
+		var ok bool
+		start, length, ok = parseRange(rng, obj.size)
+		if !ok {
+			return s.errorResponse(req, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable", bucketName, key)
+		}
+		status = http.StatusPartialContent
+	}
+	if status == http.StatusPartialContent {
+		h.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+length-1, obj.size))
+	}
+	var body io.Reader
+	if obj.data != nil {
+		body = bytes.NewReader(obj.data[start : start+length])
+	} else {
+		body = io.LimitReader(zeroReader{}, length)
+	}
+	return s.response(req, status, h, body, length)
+}
+
+// putObject stores an object.
+func (s *Server) putObject(req *http.Request, bucketName, key string) (*http.Response, error) {
+	data, size, err := s.readBody(req)
+	if err != nil {
+		return s.errorResponse(req, http.StatusBadRequest, "IncompleteBody", err.Error(), bucketName, key)
+	}
+	obj := &object{
+		key:         key,
+		modTime:     time.Now().UTC(),
+		etag:        s.newID(),
+		size:        size,
+		data:        data,
+		contentType: req.Header.Get("Content-Type"),
+		meta:        userMeta(req.Header),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		s.release(obj)
+		return s.errorResponse(req, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist", bucketName, "")
+	}
+	s.add(b, obj)
+	h := make(http.Header)
+	h.Set("ETag", `"`+obj.etag+`"`)
+	if obj.versionID != "" {
+		h.Set("x-amz-version-id", obj.versionID)
+	}
+	return s.response(req, http.StatusOK, h, nil, 0)
+}
+
+// deleteObject removes an object or version.
+func (s *Server) deleteObject(req *http.Request, bucketName, key, versionID string, hasVersion bool) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return s.errorResponse(req, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist", bucketName, "")
+	}
+	marker := s.remove(b, key, versionID, hasVersion)
+	h := make(http.Header)
+	if marker != nil {
+		h.Set("x-amz-delete-marker", "true")
+		h.Set("x-amz-version-id", marker.versionID)
+	}
+	return s.response(req, http.StatusNoContent, h, nil, 0)
+}
+
+// deleteObjects removes multiple objects or versions.
+func (s *Server) deleteObjects(req *http.Request, bucketName string) (*http.Response, error) {
+	var in struct {
+		Quiet   bool
+		Objects []struct {
+			Key       string
+			VersionID *string `xml:"VersionId"`
+		} `xml:"Object"`
+	}
+	if err := xml.NewDecoder(req.Body).Decode(&in); err != nil {
+		return s.errorResponse(req, http.StatusBadRequest, "MalformedXML", err.Error(), bucketName, "")
+	}
+	type deleted struct {
+		Key                   string
+		VersionID             string `xml:"VersionId,omitempty"`
+		DeleteMarker          bool   `xml:",omitempty"`
+		DeleteMarkerVersionID string `xml:"DeleteMarkerVersionId,omitempty"`
+	}
+	var out struct {
+		XMLName xml.Name  `xml:"DeleteResult"`
+		Deleted []deleted `xml:"Deleted"`
+	}
+	s.mu.Lock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		s.mu.Unlock()
+		return s.errorResponse(req, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist", bucketName, "")
+	}
+	for _, o := range in.Objects {
+		var versionID string
+		if o.VersionID != nil && *o.VersionID != "null" {
+			versionID = *o.VersionID
+		}
+		marker := s.remove(b, o.Key, versionID, o.VersionID != nil)
+		if in.Quiet {
+			continue
+		}
+		d := deleted{Key: o.Key, VersionID: versionID}
+		if marker != nil {
+			d.DeleteMarker = true
+			d.DeleteMarkerVersionID = marker.versionID
+		}
+		out.Deleted = append(out.Deleted, d)
+	}
+	s.mu.Unlock()
+	return s.xmlResponse(req, out)
+}
+
+// newUpload starts a multipart upload.
+func (s *Server) newUpload(req *http.Request, bucketName, key string) (*http.Response, error) {
+	id := s.newID()
+	s.mu.Lock()
+	b, ok := s.buckets[bucketName]
+	if ok {
+		b.uploads[id] = &upload{
+			key:         key,
+			contentType: req.Header.Get("Content-Type"),
+			meta:        userMeta(req.Header),
+			parts:       make(map[int]*object),
+		}
+	}
+	s.mu.Unlock()
+	if !ok {
+		return s.errorResponse(req, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist", bucketName, "")
+	}
+	return s.xmlResponse(req, struct {
+		XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+		Bucket   string
+		Key      string
+		UploadID string `xml:"UploadId"`
+	}{Bucket: bucketName, Key: key, UploadID: id})
+}
+
+// putPart stores a part of a multipart upload.
+func (s *Server) putPart(req *http.Request, bucketName, key, uploadID, partNumber string) (*http.Response, error) {
+	n, err := strconv.Atoi(partNumber)
+	if err != nil || n < 1 || n > 10000 {
+		return s.errorResponse(req, http.StatusBadRequest, "InvalidArgument", "Part number must be an integer between 1 and 10000, inclusive", bucketName, key)
+	}
+	data, size, err := s.readBody(req)
+	if err != nil {
+		return s.errorResponse(req, http.StatusBadRequest, "IncompleteBody", err.Error(), bucketName, key)
+	}
+	part := &object{etag: s.newID(), size: size, data: data}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.findUpload(bucketName, key, uploadID)
+	if u == nil {
+		s.release(part)
+		return s.errorResponse(req, http.StatusNotFound, "NoSuchUpload", "The specified multipart upload does not exist", bucketName, key)
+	}
+	if old := u.parts[n]; old != nil {
+		s.release(old)
+	}
+	u.parts[n] = part
+	h := make(http.Header)
+	h.Set("ETag", `"`+part.etag+`"`)
+	return s.response(req, http.StatusOK, h, nil, 0)
+}
+
+// completeUpload combines the parts of a multipart upload into an object.
+func (s *Server) completeUpload(req *http.Request, bucketName, key, uploadID string) (*http.Response, error) {
+	var in struct {
+		Parts []struct {
+			PartNumber int
+			ETag       string
+		} `xml:"Part"`
+	}
+	if err := xml.NewDecoder(req.Body).Decode(&in); err != nil {
+		return s.errorResponse(req, http.StatusBadRequest, "MalformedXML", err.Error(), bucketName, key)
+	}
+	if len(in.Parts) == 0 {
+		return s.errorResponse(req, http.StatusBadRequest, "MalformedXML", "No parts specified", bucketName, key)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.buckets[bucketName]
+	u := s.findUpload(bucketName, key, uploadID)
+	if u == nil {
+		return s.errorResponse(req, http.StatusNotFound, "NoSuchUpload", "The specified multipart upload does not exist", bucketName, key)
+	}
+	obj := &object{
+		key:         key,
+		modTime:     time.Now().UTC(),
+		etag:        fmt.Sprintf("%s-%d", s.newID(), len(in.Parts)),
+		contentType: u.contentType,
+		meta:        u.meta,
+	}
+	keepData := true
+	last := 0
+	for _, p := range in.Parts {
+		part := u.parts[p.PartNumber]
+		if p.PartNumber <= last {
+			return s.errorResponse(req, http.StatusBadRequest, "InvalidPartOrder", "The list of parts was not in ascending order", bucketName, key)
+		}
+		if part == nil || strings.Trim(p.ETag, `"`) != part.etag {
+			return s.errorResponse(req, http.StatusBadRequest, "InvalidPart", "One or more of the specified parts could not be found", bucketName, key)
+		}
+		last = p.PartNumber
+		obj.size += part.size
+		obj.parts = append(obj.parts, part.size)
+		keepData = keepData && (part.data != nil || part.size == 0)
+	}
+	if keepData {
+		obj.data = make([]byte, 0, obj.size)
+		for _, p := range in.Parts {
+			obj.data = append(obj.data, u.parts[p.PartNumber].data...)
+		}
+		atomic.AddInt64(&s.stored, obj.size)
+	}
+	for _, p := range u.parts {
+		s.release(p)
+	}
+	delete(b.uploads, uploadID)
+	s.add(b, obj)
+
+	h := make(http.Header)
+	if obj.versionID != "" {
+		h.Set("x-amz-version-id", obj.versionID)
+	}
+	resp, err := s.xmlResponse(req, struct {
+		XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+		Location string
+		Bucket   string
+		Key      string
+		ETag     string
+	}{Location: "/" + bucketName + "/" + key, Bucket: bucketName, Key: key, ETag: `"` + obj.etag + `"`})
+	for k, v := range h {
+		resp.Header[k] = v
+	}
+	return resp, err
+}
+
+// abortUpload removes a multipart upload.
+func (s *Server) abortUpload(req *http.Request, bucketName, key, uploadID string) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.findUpload(bucketName, key, uploadID)
+	if u == nil {
+		return s.errorResponse(req, http.StatusNotFound, "NoSuchUpload", "The specified multipart upload does not exist", bucketName, key)
+	}
+	for _, p := range u.parts {
+		s.release(p)
+	}
+	delete(s.buckets[bucketName].uploads, uploadID)
+	return s.response(req, http.StatusNoContent, nil, nil, 0)
+}
+
+// listObjects lists the latest version of objects.
+func (s *Server) listObjects(req *http.Request, bucketName, prefix, delimiter, marker string, limit int) (*http.Response, error) {
+	type content struct {
+		Key          string
+		LastModified time.Time
+		ETag         string
+		Size         int64
+		StorageClass string
+	}
+	type commonPrefix struct {
+		Prefix string
+	}
+	out := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Name                  string
+		Prefix                string
+		Delimiter             string `xml:",omitempty"`
+		MaxKeys               int
+		KeyCount              int
+		IsTruncated           bool
+		ContinuationToken     string `xml:",omitempty"`
+		NextContinuationToken string `xml:",omitempty"`
+		Contents              []content
+		CommonPrefixes        []commonPrefix
+	}{Name: bucketName, Prefix: prefix, Delimiter: delimiter, MaxKeys: limit, ContinuationToken: marker}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return s.errorResponse(req, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist", bucketName, "")
+	}
+	b.walk(prefix, delimiter, marker, func(key string, prefix bool) bool {
+		if out.KeyCount == limit {
+			out.IsTruncated = true
+			return false
+		}
+		if prefix {
+			out.CommonPrefixes = append(out.CommonPrefixes, commonPrefix{Prefix: key})
+		} else {
+			versions := b.objects[key]
+			obj := versions[len(versions)-1]
+			if obj.deleteMarker {
+				return true
+			}
+			out.Contents = append(out.Contents, content{Key: key, LastModified: obj.modTime, ETag: `"` + obj.etag + `"`, Size: obj.size, StorageClass: "STANDARD"})
+		}
+		out.KeyCount++
+		out.NextContinuationToken = key
+		return true
+	})
+	if !out.IsTruncated {
+		out.NextContinuationToken = ""
+	}
+	return s.xmlResponse(req, out)
+}
+
+// listVersions lists all versions of objects.
+// All versions of a key are returned in the same response.
+func (s *Server) listVersions(req *http.Request, bucketName, prefix, delimiter, marker string, limit int) (*http.Response, error) {
+	type version struct {
+		XMLName      xml.Name
+		Key          string
+		VersionID    string `xml:"VersionId"`
+		IsLatest     bool
+		LastModified time.Time
+		ETag         string `xml:",omitempty"`
+		Size         int64
+		StorageClass string `xml:",omitempty"`
+	}
+	type commonPrefix struct {
+		Prefix string
+	}
+	out := struct {
+		XMLName        xml.Name `xml:"ListVersionsResult"`
+		Name           string
+		Prefix         string
+		Delimiter      string `xml:",omitempty"`
+		MaxKeys        int
+		IsTruncated    bool
+		KeyMarker      string
+		NextKeyMarker  string `xml:",omitempty"`
+		Versions       []version
+		CommonPrefixes []commonPrefix
+	}{Name: bucketName, Prefix: prefix, Delimiter: delimiter, MaxKeys: limit, KeyMarker: marker}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return s.errorResponse(req, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist", bucketName, "")
+	}
+	n := 0
+	b.walk(prefix, delimiter, marker, func(key string, prefix bool) bool {
+		if n >= limit {
+			out.IsTruncated = true
+			return false
+		}
+		out.NextKeyMarker = key
+		if prefix {
+			out.CommonPrefixes = append(out.CommonPrefixes, commonPrefix{Prefix: key})
+			n++
+			return true
+		}
+		versions := b.objects[key]
+		for i := len(versions) - 1; i >= 0; i-- {
+			obj := versions[i]
+			v := version{
+				XMLName:      xml.Name{Local: "Version"},
+				Key:          key,
+				VersionID:    obj.versionID,
+				IsLatest:     i == len(versions)-1,
+				LastModified: obj.modTime,
+			}
+			if v.VersionID == "" {
+				v.VersionID = "null"
+			}
+			if obj.deleteMarker {
+				v.XMLName.Local = "DeleteMarker"
+			} else {
+				v.ETag = `"` + obj.etag + `"`
+				v.Size = obj.size
+				v.StorageClass = "STANDARD"
+			}
+			out.Versions = append(out.Versions, v)
+			n++
+		}
+		return true
+	})
+	if !out.IsTruncated {
+		out.NextKeyMarker = ""
+	}
+	return s.xmlResponse(req, out)
+}
+
+// walk calls fn with the keys and common prefixes after marker in sorted order,
+// until fn returns false. Must be called with the write lock held.
+func (b *bucket) walk(prefix, delimiter, marker string, fn func(key string, prefix bool) bool) {
+	if b.dirty {
+		b.keys = b.keys[:0]
+		for k := range b.objects {
+			b.keys = append(b.keys, k)
+		}
+		sort.Strings(b.keys)
+		b.dirty = false
+	}
+	start := prefix
+	if marker > start {
+		start = marker
+	}
+	lastPrefix := ""
+	for i := sort.SearchStrings(b.keys, start); i < len(b.keys); i++ {
+		key := b.keys[i]
+		if !strings.HasPrefix(key, prefix) {
+			break
+		}
+		if key == marker {
+			continue
+		}
+		if delimiter != "" {
+			if j := strings.Index(key[len(prefix):], delimiter); j >= 0 {
+				cp := key[:len(prefix)+j+len(delimiter)]
+				if cp == lastPrefix || cp == marker {
+					continue
+				}
+				lastPrefix = cp
+				if !fn(cp, true) {
+					return
+				}
+				continue
+			}
+		}
+		if !fn(key, false) {
+			return
+		}
+	}
+}
+
+// find returns the latest version of the key or the version with the ID.
+func (b *bucket) find(key, versionID string, hasVersion bool) *object {
+	versions := b.objects[key]
+	if len(versions) == 0 {
+		return nil
+	}
+	if !hasVersion {
+		return versions[len(versions)-1]
+	}
+	for _, v := range versions {
+		if v.versionID == versionID {
+			return v
+		}
+	}
+	return nil
+}
+
+// add adds the object as the latest version of its key.
+// Must be called with the write lock held.
+func (s *Server) add(b *bucket, obj *object) {
+	versions := b.objects[obj.key]
+	if len(versions) == 0 {
+		b.dirty = true
+	}
+	if b.versioning == "Enabled" {
+		obj.versionID = s.newVersionID()
+	} else {
+		// Replace the null version.
+		for i, v := range versions {
+			if v.versionID == "" {
+				s.release(v)
+				versions = append(versions[:i], versions[i+1:]...)
+				break
+			}
+		}
+	}
+	b.objects[obj.key] = append(versions, obj)
+}
+
+// remove removes the version of the key.
+// Without a version a delete marker is added if versioning is enabled, which is returned.
+// Must be called with the write lock held.
+func (s *Server) remove(b *bucket, key, versionID string, hasVersion bool) *object {
+	if !hasVersion && b.versioning == "Enabled" {
+		if len(b.objects[key]) == 0 {
+			return nil
+		}
+		marker := &object{key: key, deleteMarker: true, modTime: time.Now().UTC()}
+		s.add(b, marker)
+		return marker
+	}
+	versions := b.objects[key]
+	for i, v := range versions {
+		if v.versionID == versionID {
+			s.release(v)
+			versions = append(versions[:i], versions[i+1:]...)
+			break
+		}
+	}
+	if len(versions) == 0 {
+		delete(b.objects, key)
+		b.dirty = true
+		return nil
+	}
+	b.objects[key] = versions
+	return nil
+}
+
+// findUpload returns the multipart upload or nil if not found.
+// Must be called with the lock held.
+func (s *Server) findUpload(bucketName, key, uploadID string) *upload {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil
+	}
+	u := b.uploads[uploadID]
+	if u == nil || u.key != key {
+		return nil
+	}
+	return u
+}
+
+// readBody reads the request content.
+// The content is returned if it fits within the storage limit.
+func (s *Server) readBody(req *http.Request) ([]byte, int64, error) {
+	var r io.Reader = http.NoBody
+	if req.Body != nil {
+		r = req.Body
+	}
+	size := req.ContentLength
+	if strings.HasPrefix(req.Header.Get("x-amz-content-sha256"), "STREAMING-") {
+		r = newChunkedReader(r)
+		var err error
+		size, err = strconv.ParseInt(req.Header.Get("x-amz-decoded-content-length"), 10, 64)
+		if err != nil {
+			size = -1
+		}
+	}
+	if size >= 0 && atomic.AddInt64(&s.stored, size) <= s.maxStored {
+		data := make([]byte, size)
+		_, err := io.ReadFull(r, data)
+		if err == nil {
+			_, err = io.Copy(io.Discard, r)
+		}
+		if err != nil {
+			atomic.AddInt64(&s.stored, -size)
+			return nil, 0, err
+		}
+		return data, size, nil
+	} else if size >= 0 {
+		atomic.AddInt64(&s.stored, -size)
+	}
+	n, err := io.Copy(io.Discard, r)
+	if err != nil {
+		return nil, 0, err
+	}
+	if size >= 0 && n != size {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	return nil, n, nil
+}
+
+// release releases the storage of the object content.
+func (s *Server) release(obj *object) {
+	if obj.data != nil {
+		atomic.AddInt64(&s.stored, -int64(len(obj.data)))
+		obj.data = nil
+	}
+}
+
+// newID returns a unique ID in the form of an MD5 ETag.
+func (s *Server) newID() string {
+	return fmt.Sprintf("%032x", atomic.AddUint64(&s.seq, 1))
+}
+
+// newVersionID returns a unique version ID.
+func (s *Server) newVersionID() string {
+	id := s.newID()
+	return id[:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:]
+}
+
+// header returns the response headers describing the object.
+func (o *object) header(versioned bool) http.Header {
+	h := make(http.Header, len(o.meta)+5)
+	for k, v := range o.meta {
+		h[k] = v
+	}
+	h.Set("Last-Modified", o.modTime.Format(http.TimeFormat))
+	if versioned {
+		id := o.versionID
+		if id == "" {
+			id = "null"
+		}
+		h.Set("x-amz-version-id", id)
+	}
+	if o.deleteMarker {
+		h.Set("x-amz-delete-marker", "true")
+		return h
+	}
+	h.Set("ETag", `"`+o.etag+`"`)
+	h.Set("Accept-Ranges", "bytes")
+	if o.contentType != "" {
+		h.Set("Content-Type", o.contentType)
+	} else {
+		h.Set("Content-Type", "application/octet-stream")
+	}
+	return h
+}
+
+// userMeta returns the user metadata headers.
+func userMeta(h http.Header) http.Header {
+	var res http.Header
+	for k, v := range h {
+		if strings.HasPrefix(strings.ToLower(k), "x-amz-meta-") {
+			if res == nil {
+				res = make(http.Header)
+			}
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// maxKeys returns the maximum number of keys to list.
+func maxKeys(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 || n > 1000 {
+		return 1000
+	}
+	return n
+}
+
+// parseRange returns the start and length of the range header value.
+func parseRange(s string, size int64) (start, length int64, ok bool) {
+	s = strings.TrimPrefix(s, "bytes=")
+	i := strings.IndexByte(s, '-')
+	if i < 0 || strings.Contains(s, ",") {
+		return 0, 0, false
+	}
+	from, to := s[:i], s[i+1:]
+	if from == "" {
+		n, err := strconv.ParseInt(to, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, false
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, n, size > 0
+	}
+	start, err := strconv.ParseInt(from, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false
+	}
+	end := size - 1
+	if to != "" {
+		end, err = strconv.ParseInt(to, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end - start + 1, true
+}
+
+// response returns a response with the headers and content.
+func (s *Server) response(req *http.Request, status int, h http.Header, body io.Reader, size int64) (*http.Response, error) {
+	if h == nil {
+		h = make(http.Header)
+	}
+	h.Set("Content-Length", strconv.FormatInt(size, 10))
+	h.Set("x-amz-request-id", strconv.FormatUint(atomic.AddUint64(&s.seq, 1), 16))
+	h.Set("Server", "warp-loopback")
+	if body == nil || req.Method == http.MethodHead {
+		body = http.NoBody
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(body),
+		ContentLength: size,
+		Request:       req,
+	}, nil
+}
+
+// xmlResponse returns the value encoded as XML.
+func (s *Server) xmlResponse(req *http.Request, v interface{}) (*http.Response, error) {
+	b, err := xml.Marshal(v)
+	if err != nil {
+		return s.errorResponse(req, http.StatusInternalServerError, "InternalError", err.Error(), "", "")
+	}
+	b = append([]byte(xml.Header), b...)
+	h := make(http.Header)
+	h.Set("Content-Type", "application/xml")
+	return s.response(req, http.StatusOK, h, bytes.NewReader(b), int64(len(b)))
+}
+
+// errorResponse returns an S3 error response.
+// Headers are added to the response if specified.
+func (s *Server) errorResponse(req *http.Request, status int, code, msg, bucketName, key string, h ...http.Header) (*http.Response, error) {
+	b, err := xml.Marshal(struct {
+		XMLName    xml.Name `xml:"Error"`
+		Code       string
+		Message    string
+		BucketName string `xml:",omitempty"`
+		Key        string `xml:",omitempty"`
+	}{Code: code, Message: msg, BucketName: bucketName, Key: key})
+	if err != nil {
+		return nil, err
+	}
+	b = append([]byte(xml.Header), b...)
+	hdr := make(http.Header)
+	for _, extra := range h {
+		for k, v := range extra {
+			hdr[k] = v
+		}
+	}
+	hdr.Set("Content-Type", "application/xml")
+	size := int64(len(b))
+	if req.Method == http.MethodHead {
+		size = 0
+	}
+	return s.response(req, status, hdr, bytes.NewReader(b), size)
+}
+
+// notImplemented returns a NotImplemented error response.
+func (s *Server) notImplemented(req *http.Request) (*http.Response, error) {
+	return s.errorResponse(req, http.StatusNotImplemented, "NotImplemented", "A header you provided implies functionality that is not implemented", "", "")
+}
+
+// zeroReader returns zeros.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
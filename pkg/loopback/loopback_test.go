@@ -0,0 +1,83 @@
+/*
+ * Warp (C) 2019-2023 MinIO, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package loopback
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/warp/pkg/bench"
+	"github.com/minio/warp/pkg/bench/backendtest"
+)
+
+func TestConformance(t *testing.T) {
+	for _, secure := range []bool{false, true} {
+		cl, err := minio.New("127.0.0.1:9000", &minio.Options{
+			Creds:     credentials.NewStaticV4("access", "secretsecret", ""),
+			Secure:    secure,
+			Transport: New(1 << 30),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := "http"
+		if secure {
+			name = "https"
+		}
+		t.Run(name, func(t *testing.T) {
+			backendtest.Run(t, bench.NewS3Backend(cl), "warp-loopback-test", backendtest.Options{
+				Versioning: true,
+				Multipart:  true,
+			})
+		})
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	cl, err := minio.New("127.0.0.1:9000", &minio.Options{
+		Creds:     credentials.NewStaticV4("access", "secretsecret", ""),
+		Transport: New(10),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := cl.MakeBucket(ctx, "bucket", minio.MakeBucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// Content beyond the limit must read back as zeros of the uploaded size.
+	content := bytes.Repeat([]byte("x"), 100)
+	if _, err := cl.PutObject(ctx, "bucket", "object", bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	o, err := cl.GetObject(ctx, "bucket", "object", minio.GetObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, make([]byte, len(content))) {
+		t.Errorf("got %q, want %d zeros", got, len(content))
+	}
+}